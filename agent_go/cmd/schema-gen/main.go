@@ -38,6 +38,7 @@ type UnifiedEvent struct {
 
 	LargeToolOutputDetectedEvent    events.LargeToolOutputDetectedEvent    `json:"large_tool_output_detected"`
 	LargeToolOutputFileWrittenEvent events.LargeToolOutputFileWrittenEvent `json:"large_tool_output_file_written"`
+	LargeToolOutputHandledEvent     events.LargeToolOutputHandledEvent     `json:"large_tool_output_handled"`
 	FallbackModelUsedEvent          events.FallbackModelUsedEvent          `json:"fallback_model_used"`
 	ThrottlingDetectedEvent         events.ThrottlingDetectedEvent         `json:"throttling_detected"`
 	TokenLimitExceededEvent         events.TokenLimitExceededEvent         `json:"token_limit_exceeded"`
@@ -64,6 +65,32 @@ type UnifiedEvent struct {
 	SmartRoutingStartEvent events.SmartRoutingStartEvent `json:"smart_routing_start"`
 	SmartRoutingEndEvent   events.SmartRoutingEndEvent   `json:"smart_routing_end"`
 
+	// Automatic agent mode selection (agent_mode="auto")
+	AgentModeSelectedEvent events.AgentModeSelectedEvent `json:"agent_mode_selected"`
+
+	// Server memory pressure
+	MemoryPressureEvent events.MemoryPressureEvent `json:"memory_pressure"`
+
+	// Multi-step tool transactions
+	ToolTransactionBeginEvent    events.ToolTransactionEvent      `json:"tool_transaction_begin"`
+	ToolTransactionCommitEvent   events.ToolTransactionEvent      `json:"tool_transaction_commit"`
+	ToolTransactionRollbackEvent events.ToolTransactionEvent      `json:"tool_transaction_rollback"`
+	ToolAlternateUsedEvent       events.ToolAlternateUsedEvent    `json:"tool_alternate_used"`
+	ToolPermissionDeniedEvent    events.ToolPermissionDeniedEvent `json:"tool_permission_denied"`
+	ToolCallDeduplicatedEvent    events.ToolCallDeduplicatedEvent `json:"tool_call_deduplicated"`
+
+	// Structured output re-ask attempts
+	StructuredOutputAttemptEvent events.StructuredOutputAttemptEvent `json:"structured_output_attempt"`
+
+	// Expired LLM credentials refreshed before retrying
+	CredentialRefreshEvent events.CredentialRefreshEvent `json:"credential_refresh"`
+
+	// Oldest turns dropped to fit the context window
+	HistoryCompactedEvent events.HistoryCompactedEvent `json:"history_compacted"`
+
+	// Token and cost roll-up at the end of a conversation or orchestrator run
+	ConversationCostSummaryEvent events.ConversationCostSummaryEvent `json:"conversation_cost_summary"`
+
 	// Orchestrator Events - now handled by unified events system
 	OrchestratorStartEvent      events.OrchestratorStartEvent      `json:"orchestrator_start"`
 	OrchestratorEndEvent        events.OrchestratorEndEvent        `json:"orchestrator_end"`
@@ -109,6 +136,7 @@ type EventData struct {
 
 	LargeToolOutputDetected    *events.LargeToolOutputDetectedEvent    `json:"large_tool_output_detected,omitempty"`
 	LargeToolOutputFileWritten *events.LargeToolOutputFileWrittenEvent `json:"large_tool_output_file_written,omitempty"`
+	LargeToolOutputHandled     *events.LargeToolOutputHandledEvent     `json:"large_tool_output_handled,omitempty"`
 	FallbackModelUsed          *events.FallbackModelUsedEvent          `json:"fallback_model_used,omitempty"`
 	ThrottlingDetected         *events.ThrottlingDetectedEvent         `json:"throttling_detected,omitempty"`
 	TokenLimitExceeded         *events.TokenLimitExceededEvent         `json:"token_limit_exceeded,omitempty"`
@@ -136,6 +164,32 @@ type EventData struct {
 	SmartRoutingStart *events.SmartRoutingStartEvent `json:"smart_routing_start,omitempty"`
 	SmartRoutingEnd   *events.SmartRoutingEndEvent   `json:"smart_routing_end,omitempty"`
 
+	// Automatic agent mode selection (agent_mode="auto")
+	AgentModeSelected *events.AgentModeSelectedEvent `json:"agent_mode_selected,omitempty"`
+
+	// Server memory pressure
+	MemoryPressure *events.MemoryPressureEvent `json:"memory_pressure,omitempty"`
+
+	// Multi-step tool transactions
+	ToolTransactionBegin    *events.ToolTransactionEvent      `json:"tool_transaction_begin,omitempty"`
+	ToolTransactionCommit   *events.ToolTransactionEvent      `json:"tool_transaction_commit,omitempty"`
+	ToolTransactionRollback *events.ToolTransactionEvent      `json:"tool_transaction_rollback,omitempty"`
+	ToolAlternateUsed       *events.ToolAlternateUsedEvent    `json:"tool_alternate_used,omitempty"`
+	ToolPermissionDenied    *events.ToolPermissionDeniedEvent `json:"tool_permission_denied,omitempty"`
+	ToolCallDeduplicated    *events.ToolCallDeduplicatedEvent `json:"tool_call_deduplicated,omitempty"`
+
+	// Structured output re-ask attempts
+	StructuredOutputAttempt *events.StructuredOutputAttemptEvent `json:"structured_output_attempt,omitempty"`
+
+	// Expired LLM credentials refreshed before retrying
+	CredentialRefresh *events.CredentialRefreshEvent `json:"credential_refresh,omitempty"`
+
+	// Oldest turns dropped to fit the context window
+	HistoryCompacted *events.HistoryCompactedEvent `json:"history_compacted,omitempty"`
+
+	// Token and cost roll-up at the end of a conversation or orchestrator run
+	ConversationCostSummary *events.ConversationCostSummaryEvent `json:"conversation_cost_summary,omitempty"`
+
 	// Orchestrator Events (from unified events system)
 	OrchestratorStart      *events.OrchestratorStartEvent      `json:"orchestrator_start,omitempty"`
 	OrchestratorEnd        *events.OrchestratorEndEvent        `json:"orchestrator_end,omitempty"`
@@ -148,7 +202,12 @@ type EventData struct {
 	RequestHumanFeedbackEvent *events.RequestHumanFeedbackEvent `json:"request_human_feedback,omitempty"`
 
 	// Todo Creation Events
-	TodoStepsExtracted *events.TodoStepsExtractedEvent `json:"todo_steps_extracted,omitempty"`
+	TodoStepsExtracted       *events.TodoStepsExtractedEvent       `json:"todo_steps_extracted,omitempty"`
+	TodoStepsHeldForRevision *events.TodoStepsHeldForRevisionEvent `json:"todo_steps_held_for_revision,omitempty"`
+
+	// Multi-model consensus events
+	ConsensusModelOutput *events.ConsensusModelOutputEvent `json:"consensus_model_output,omitempty"`
+	ConsensusResolved    *events.ConsensusResolvedEvent    `json:"consensus_resolved,omitempty"`
 }
 
 func writeSchema(filename string, v any) error {
@@ -0,0 +1,327 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// maxBatchQueries caps how many sub-queries a single batch may submit
+const maxBatchQueries = 50
+
+// BatchQueryRequest submits several queries at once
+type BatchQueryRequest struct {
+	Queries []QueryRequest `json:"queries"`
+	// Stream keeps the response open as server-sent events and emits a completion record
+	// per sub-query as it finishes, instead of returning the IDs immediately
+	Stream bool `json:"stream,omitempty"`
+}
+
+// BatchSubQuery identifies one submitted sub-query of a batch
+type BatchSubQuery struct {
+	Index      int    `json:"index"`
+	QueryID    string `json:"query_id,omitempty"`
+	SessionID  string `json:"session_id"`
+	ObserverID string `json:"observer_id"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+}
+
+// BatchQueryResponse is returned for non-streaming batches
+type BatchQueryResponse struct {
+	BatchID string          `json:"batch_id"`
+	Queries []BatchSubQuery `json:"queries"`
+}
+
+// BatchCompletionRecord is streamed when a sub-query finishes
+type BatchCompletionRecord struct {
+	BatchID    string        `json:"batch_id"`
+	Index      int           `json:"index"`
+	QueryID    string        `json:"query_id,omitempty"`
+	SessionID  string        `json:"session_id"`
+	ObserverID string        `json:"observer_id"`
+	Status     string        `json:"status"` // "completed", "error", "stopped"
+	Result     string        `json:"result,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// batchDispatcher starts a single sub-query; it returns once the query is accepted, not when it finishes
+type batchDispatcher func(sub *BatchSubQuery, req QueryRequest) error
+
+// handleBatchQuery handles POST /api/batch
+func (api *StreamingAPI) handleBatchQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var req BatchQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.Queries) == 0 {
+		http.Error(w, "At least one query is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Queries) > maxBatchQueries {
+		http.Error(w, fmt.Sprintf("Batch exceeds maximum of %d queries", maxBatchQueries), http.StatusBadRequest)
+		return
+	}
+	for i, q := range req.Queries {
+		if q.Query == "" {
+			http.Error(w, fmt.Sprintf("Query %d is empty", i), http.StatusBadRequest)
+			return
+		}
+	}
+	stream := req.Stream || r.URL.Query().Get("stream") == "true"
+
+	var flusher http.Flusher
+	if stream {
+		var ok bool
+		if flusher, ok = w.(http.Flusher); !ok {
+			http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	dispatch := api.batchDispatch
+	if dispatch == nil {
+		dispatch = api.dispatchBatchQuery
+	}
+
+	batchID := fmt.Sprintf("batch_%d", time.Now().UnixNano())
+	startTime := time.Now()
+	subQueries := make([]BatchSubQuery, len(req.Queries))
+	done := make(chan BatchCompletionRecord, len(req.Queries))
+
+	for i, q := range req.Queries {
+		sessionID := fmt.Sprintf("%s_%d", batchID, i)
+		observer := api.observerManager.RegisterObserver(sessionID)
+		sub := &subQueries[i]
+		*sub = BatchSubQuery{
+			Index:      i,
+			SessionID:  sessionID,
+			ObserverID: observer.ID,
+			Status:     "started",
+		}
+
+		// Register for completion before dispatching so fast queries are not missed
+		statusCh := api.waitForSessionDone(sub.SessionID)
+		if err := dispatch(sub, q); err != nil {
+			api.cancelSessionDoneWait(sub.SessionID, statusCh)
+			sub.Status = "error"
+			sub.Message = err.Error()
+			log.Printf("[BATCH] Sub-query %d of %s failed to start: %v", i, batchID, err)
+			done <- BatchCompletionRecord{
+				BatchID:    batchID,
+				Index:      i,
+				SessionID:  sub.SessionID,
+				ObserverID: sub.ObserverID,
+				Status:     "error",
+				Error:      err.Error(),
+			}
+			continue
+		}
+
+		go func(sub BatchSubQuery) {
+			status, ok := <-statusCh
+			if !ok {
+				return
+			}
+			done <- api.batchCompletionRecord(batchID, sub, status, time.Since(startTime))
+		}(*sub)
+	}
+
+	log.Printf("[BATCH] Submitted batch %s with %d queries (stream: %v)", batchID, len(req.Queries), stream)
+
+	if !stream {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(BatchQueryResponse{BatchID: batchID, Queries: subQueries}); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	writeSSE(w, "batch", BatchQueryResponse{BatchID: batchID, Queries: subQueries})
+	flusher.Flush()
+
+	for remaining := len(req.Queries); remaining > 0; remaining-- {
+		select {
+		case record := <-done:
+			writeSSE(w, "completion", record)
+			flusher.Flush()
+		case <-r.Context().Done():
+			// Client went away; the sub-queries keep running and remain observable via their observers
+			log.Printf("[BATCH] Client disconnected from batch %s with %d queries outstanding", batchID, remaining)
+			for _, sub := range subQueries {
+				api.cancelSessionDoneWait(sub.SessionID, nil)
+			}
+			return
+		}
+	}
+
+	writeSSE(w, "done", map[string]interface{}{
+		"batch_id": batchID,
+		"total":    len(req.Queries),
+		"duration": time.Since(startTime),
+	})
+	flusher.Flush()
+}
+
+// dispatchBatchQuery submits a sub-query through the regular query handler
+func (api *StreamingAPI) dispatchBatchQuery(sub *BatchSubQuery, req QueryRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, "/api/query", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build query request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Session-ID", sub.SessionID)
+	httpReq.Header.Set("X-Observer-ID", sub.ObserverID)
+
+	recorder := &batchResponseRecorder{header: make(http.Header)}
+	api.handleQuery(recorder, httpReq)
+
+	if recorder.status >= http.StatusBadRequest {
+		return fmt.Errorf("query rejected (%d): %s", recorder.status, strings.TrimSpace(recorder.body.String()))
+	}
+
+	var resp QueryResponse
+	if err := json.Unmarshal(recorder.body.Bytes(), &resp); err == nil {
+		sub.QueryID = resp.QueryID
+		if resp.Status != "" {
+			sub.Status = resp.Status
+		}
+		sub.Message = resp.Message
+	}
+	return nil
+}
+
+// batchCompletionRecord builds the completion record for a finished sub-query,
+// using the last assistant message in the session history as the result
+func (api *StreamingAPI) batchCompletionRecord(batchID string, sub BatchSubQuery, status string, duration time.Duration) BatchCompletionRecord {
+	record := BatchCompletionRecord{
+		BatchID:    batchID,
+		Index:      sub.Index,
+		QueryID:    sub.QueryID,
+		SessionID:  sub.SessionID,
+		ObserverID: sub.ObserverID,
+		Status:     status,
+		Duration:   duration,
+	}
+
+	api.conversationMux.RLock()
+	history := api.conversationHistory[sub.SessionID]
+	api.conversationMux.RUnlock()
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role != llmtypes.ChatMessageTypeAI {
+			continue
+		}
+		for _, part := range history[i].Parts {
+			if text, ok := part.(llmtypes.TextContent); ok {
+				record.Result += text.Text
+			}
+		}
+		break
+	}
+
+	if status != "completed" {
+		record.Error = fmt.Sprintf("query finished with status %s", status)
+	}
+	return record
+}
+
+// waitForSessionDone returns a channel that receives the session's terminal status
+func (api *StreamingAPI) waitForSessionDone(sessionID string) chan string {
+	ch := make(chan string, 1)
+	api.sessionDoneMux.Lock()
+	defer api.sessionDoneMux.Unlock()
+	if api.sessionDoneWaiters == nil {
+		api.sessionDoneWaiters = make(map[string][]chan string)
+	}
+	api.sessionDoneWaiters[sessionID] = append(api.sessionDoneWaiters[sessionID], ch)
+	return ch
+}
+
+// cancelSessionDoneWait drops a waiter (or all waiters when ch is nil) and closes the channels
+func (api *StreamingAPI) cancelSessionDoneWait(sessionID string, ch chan string) {
+	api.sessionDoneMux.Lock()
+	defer api.sessionDoneMux.Unlock()
+	waiters := api.sessionDoneWaiters[sessionID]
+	remaining := waiters[:0]
+	for _, w := range waiters {
+		if ch == nil || w == ch {
+			close(w)
+			continue
+		}
+		remaining = append(remaining, w)
+	}
+	if len(remaining) == 0 {
+		delete(api.sessionDoneWaiters, sessionID)
+	} else {
+		api.sessionDoneWaiters[sessionID] = remaining
+	}
+}
+
+// notifySessionDone delivers a terminal session status to anyone waiting on it
+func (api *StreamingAPI) notifySessionDone(sessionID, status string) {
+	api.sessionDoneMux.Lock()
+	waiters := api.sessionDoneWaiters[sessionID]
+	delete(api.sessionDoneWaiters, sessionID)
+	api.sessionDoneMux.Unlock()
+
+	for _, ch := range waiters {
+		ch <- status
+		close(ch)
+	}
+}
+
+// writeSSE writes a single server-sent event with a JSON payload
+func writeSSE(w http.ResponseWriter, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[BATCH] Failed to encode %s event: %v", event, err)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
+
+// batchResponseRecorder captures the query handler's response for a dispatched sub-query
+type batchResponseRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func (r *batchResponseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *batchResponseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+func (r *batchResponseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
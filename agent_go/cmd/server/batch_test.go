@@ -0,0 +1,231 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// readSSEEvent reads the next "event:"/"data:" pair from a server-sent event stream
+func readSSEEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+	var event, data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read event stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && event != "":
+			return event, data
+		}
+	}
+}
+
+func TestBatchQueryStreamsCompletionPerSubQuery(t *testing.T) {
+	eventStore := events.NewEventStore(100)
+	defer eventStore.Stop()
+
+	api := &StreamingAPI{
+		eventStore:          eventStore,
+		observerManager:     events.NewObserverManager(eventStore),
+		conversationHistory: make(map[string][]llmtypes.MessageContent),
+	}
+
+	// Each mock query finishes when the test releases it
+	release := map[string]chan struct{}{
+		"slow query": make(chan struct{}),
+		"fast query": make(chan struct{}),
+	}
+	api.batchDispatch = func(sub *BatchSubQuery, req QueryRequest) error {
+		sub.QueryID = fmt.Sprintf("query_%d", sub.Index)
+		go func(sessionID string) {
+			<-release[req.Query]
+			api.conversationMux.Lock()
+			api.conversationHistory[sessionID] = []llmtypes.MessageContent{{
+				Role:  llmtypes.ChatMessageTypeAI,
+				Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "answer to " + req.Query}},
+			}}
+			api.conversationMux.Unlock()
+			api.notifySessionDone(sessionID, "completed")
+		}(sub.SessionID)
+		return nil
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(api.handleBatchQuery))
+	defer ts.Close()
+
+	body := `{"stream": true, "queries": [{"query": "slow query"}, {"query": "fast query"}]}`
+	resp, err := http.Post(ts.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("batch request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected event stream, got %q", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+
+	event, data := readSSEEvent(t, reader)
+	var submitted BatchQueryResponse
+	if event != "batch" || json.Unmarshal([]byte(data), &submitted) != nil || len(submitted.Queries) != 2 {
+		t.Fatalf("expected batch submission record, got %s: %s", event, data)
+	}
+
+	// The fast query's record arrives while the slow query is still running
+	for i, query := range []string{"fast query", "slow query"} {
+		close(release[query])
+
+		recordCh := make(chan BatchCompletionRecord, 1)
+		go func() {
+			event, data := readSSEEvent(t, reader)
+			var record BatchCompletionRecord
+			if event != "completion" || json.Unmarshal([]byte(data), &record) != nil {
+				t.Errorf("expected completion record, got %s: %s", event, data)
+			}
+			recordCh <- record
+		}()
+
+		select {
+		case record := <-recordCh:
+			wantIndex := 1 - i
+			if record.Index != wantIndex || record.Status != "completed" || record.Result != "answer to "+query {
+				t.Fatalf("unexpected completion record for %q: %+v", query, record)
+			}
+			if record.QueryID != fmt.Sprintf("query_%d", wantIndex) || record.BatchID != submitted.BatchID {
+				t.Fatalf("completion record not linked to its sub-query: %+v", record)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for completion of %q", query)
+		}
+	}
+
+	if event, _ := readSSEEvent(t, reader); event != "done" {
+		t.Fatalf("expected done event, got %s", event)
+	}
+}
+
+func TestBatchQueryWithoutStreamReturnsIDs(t *testing.T) {
+	eventStore := events.NewEventStore(100)
+	defer eventStore.Stop()
+
+	api := &StreamingAPI{
+		eventStore:      eventStore,
+		observerManager: events.NewObserverManager(eventStore),
+	}
+	api.batchDispatch = func(sub *BatchSubQuery, req QueryRequest) error {
+		if req.Query == "bad" {
+			return fmt.Errorf("rejected")
+		}
+		sub.QueryID = "query_ok"
+		return nil
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/batch", strings.NewReader(`{"queries": [{"query": "good"}, {"query": "bad"}]}`))
+	api.handleBatchQuery(rec, req)
+
+	var resp BatchQueryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Queries) != 2 || resp.Queries[0].QueryID != "query_ok" || resp.Queries[0].ObserverID == "" {
+		t.Fatalf("unexpected batch response: %+v", resp)
+	}
+	if resp.Queries[1].Status != "error" || resp.Queries[1].Message != "rejected" {
+		t.Fatalf("expected rejected sub-query reported as error, got %+v", resp.Queries[1])
+	}
+}
+
+func TestBatchQueryStreamCompletesWorkflowSubQueries(t *testing.T) {
+	eventStore := events.NewEventStore(100)
+	defer eventStore.Stop()
+
+	api := &StreamingAPI{
+		chatDB:              &stoppedSessionDB{},
+		eventStore:          eventStore,
+		observerManager:     events.NewObserverManager(eventStore),
+		conversationHistory: make(map[string][]llmtypes.MessageContent),
+		activeSessions:      make(map[string]*ActiveSessionInfo),
+	}
+
+	// Sub-queries run in workflow mode, which finishes through the workflow completion path
+	api.batchDispatch = func(sub *BatchSubQuery, req QueryRequest) error {
+		if req.AgentMode != "workflow" {
+			t.Errorf("expected workflow sub-query, got mode %q", req.AgentMode)
+		}
+		observer, ok := api.observerManager.GetObserver(sub.ObserverID)
+		if !ok || observer.SessionID != sub.SessionID {
+			t.Errorf("observer %s not registered for sub-session %s", sub.ObserverID, sub.SessionID)
+		}
+		sub.QueryID = fmt.Sprintf("query_%d", sub.Index)
+		api.activeSessionsMux.Lock()
+		api.activeSessions[sub.SessionID] = &ActiveSessionInfo{SessionID: sub.SessionID, ObserverID: sub.ObserverID, AgentMode: req.AgentMode, Status: "running"}
+		api.activeSessionsMux.Unlock()
+		go func(sub BatchSubQuery) {
+			var err error
+			if req.Query == "failing workflow" {
+				err = fmt.Errorf("phase failed")
+			}
+			api.finishWorkflowSession(sub.SessionID, sub.ObserverID, sub.QueryID, err)
+		}(*sub)
+		return nil
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(api.handleBatchQuery))
+	defer ts.Close()
+
+	body := `{"stream": true, "queries": [{"query": "workflow", "agent_mode": "workflow"}, {"query": "failing workflow", "agent_mode": "workflow"}]}`
+	resp, err := http.Post(ts.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("batch request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	eventsCh := make(chan [2]string)
+	go func() {
+		reader := bufio.NewReader(resp.Body)
+		for {
+			event, data := readSSEEvent(t, reader)
+			eventsCh <- [2]string{event, data}
+			if event == "done" {
+				return
+			}
+		}
+	}()
+
+	statuses := make(map[int]string)
+	for {
+		select {
+		case ev := <-eventsCh:
+			switch ev[0] {
+			case "completion":
+				var record BatchCompletionRecord
+				if err := json.Unmarshal([]byte(ev[1]), &record); err != nil {
+					t.Fatalf("failed to decode completion record: %v", err)
+				}
+				statuses[record.Index] = record.Status
+			case "done":
+				if statuses[0] != "completed" || statuses[1] != "error" {
+					t.Fatalf("unexpected workflow sub-query statuses: %v", statuses)
+				}
+				return
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("batch stream did not complete, statuses so far: %v", statuses)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/internal/llmtypes"
+	mcpagent "mcp-agent/agent_go/pkg/mcpagent"
+)
+
+const (
+	defaultBugReportMaxBundles       = 20
+	defaultBugReportMaxArtifactBytes = 256 << 10 // 256KB of workspace files per bundle
+)
+
+// BugReportBundle is everything needed to reproduce a failed run, with credentials redacted
+type BugReportBundle struct {
+	SessionID          string              `json:"session_id"`
+	QueryID            string              `json:"query_id"`
+	CreatedAt          time.Time           `json:"created_at"`
+	Error              string              `json:"error"`
+	Request            *QueryRequest       `json:"request"`                  // The request that started the run
+	Messages           json.RawMessage     `json:"messages,omitempty"`       // Conversation history at failure
+	Events             []events.Event      `json:"events"`                   // The session's ordered event timeline
+	EventsTruncated    bool                `json:"events_truncated"`         // Earlier events were trimmed from the buffer
+	WorkspacePath      string              `json:"workspace_path,omitempty"` // Workflow/orchestrator workspace the artifacts come from
+	Artifacts          []BugReportArtifact `json:"artifacts,omitempty"`      // Workspace files, up to the size limit
+	ArtifactsTruncated bool                `json:"artifacts_truncated"`      // Some workspace files were left out
+	Redactions         int                 `json:"redactions"`               // Number of values masked by the redaction policy
+}
+
+// BugReportArtifact is one workspace file included in a bug report
+type BugReportArtifact struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Content string `json:"content"`
+}
+
+// bugReportRun is what the server remembers about a running session to build its bug report on failure
+type bugReportRun struct {
+	queryID    string
+	observerID string
+	request    QueryRequest
+}
+
+// bugReportStore keeps the bug reports of the most recent failed runs
+type bugReportStore struct {
+	enabled          bool
+	maxBundles       int
+	maxArtifactBytes int64
+	redaction        *mcpagent.RedactionPolicy
+
+	mu      sync.Mutex
+	runs    map[string]*bugReportRun // sessionID -> running session
+	bundles map[string][]byte        // sessionID -> redacted bundle JSON
+	order   []string                 // Session IDs of stored bundles, oldest first
+}
+
+// bugReportStoreFromEnv reads BUG_REPORTS_ENABLED (default true), BUG_REPORT_MAX_BUNDLES and
+// BUG_REPORT_MAX_ARTIFACT_BYTES
+func bugReportStoreFromEnv() *bugReportStore {
+	store := &bugReportStore{
+		enabled:          os.Getenv("BUG_REPORTS_ENABLED") != "false",
+		maxBundles:       defaultBugReportMaxBundles,
+		maxArtifactBytes: defaultBugReportMaxArtifactBytes,
+		redaction:        mcpagent.DefaultRedactionPolicy(),
+		runs:             make(map[string]*bugReportRun),
+		bundles:          make(map[string][]byte),
+	}
+	if envMax := os.Getenv("BUG_REPORT_MAX_BUNDLES"); envMax != "" {
+		if maxBundles, err := strconv.Atoi(envMax); err == nil && maxBundles > 0 {
+			store.maxBundles = maxBundles
+		}
+	}
+	if envMax := os.Getenv("BUG_REPORT_MAX_ARTIFACT_BYTES"); envMax != "" {
+		if maxBytes, err := strconv.ParseInt(envMax, 10, 64); err == nil && maxBytes >= 0 {
+			store.maxArtifactBytes = maxBytes
+		}
+	}
+	return store
+}
+
+// registerBugReportRun remembers the request of a starting run so a failure can be reproduced
+func (api *StreamingAPI) registerBugReportRun(sessionID, queryID, observerID string, req QueryRequest) {
+	store := api.bugReports
+	if store == nil || !store.enabled {
+		return
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.runs[sessionID] = &bugReportRun{queryID: queryID, observerID: observerID, request: req}
+}
+
+// forgetBugReportRun drops a run that ended without failing
+func (api *StreamingAPI) forgetBugReportRun(sessionID string) {
+	if api.bugReports == nil {
+		return
+	}
+	api.bugReports.mu.Lock()
+	defer api.bugReports.mu.Unlock()
+	delete(api.bugReports.runs, sessionID)
+}
+
+// captureBugReport assembles and stores the bug report bundle of a failed run
+func (api *StreamingAPI) captureBugReport(sessionID, errorMsg string) {
+	store := api.bugReports
+	if store == nil || !store.enabled {
+		return
+	}
+	store.mu.Lock()
+	run := store.runs[sessionID]
+	delete(store.runs, sessionID)
+	store.mu.Unlock()
+	if run == nil {
+		return
+	}
+
+	request := run.request
+	bundle := &BugReportBundle{
+		SessionID: sessionID,
+		QueryID:   run.queryID,
+		CreatedAt: time.Now(),
+		Error:     errorMsg,
+		Request:   &request,
+	}
+
+	buffered, trimmed := api.eventStore.Snapshot(run.observerID)
+	bundle.Events = make([]events.Event, 0, len(buffered))
+	for _, event := range buffered {
+		if event.SessionID == "" || event.SessionID == sessionID || event.SessionID == run.observerID {
+			bundle.Events = append(bundle.Events, event)
+		}
+	}
+	bundle.EventsTruncated = trimmed > 0
+
+	api.conversationMux.RLock()
+	history := append([]llmtypes.MessageContent(nil), api.conversationHistory[sessionID]...)
+	api.conversationMux.RUnlock()
+	if len(history) > 0 {
+		if encoded, err := encodeConversationHistory(history); err == nil {
+			bundle.Messages = json.RawMessage(encoded)
+		} else {
+			log.Printf("[BUG_REPORT] Failed to encode conversation history for session %s: %v", sessionID, err)
+		}
+	}
+
+	if workspacePath := extractWorkspacePathFromObjective(request.Query); workspacePath != "" {
+		bundle.WorkspacePath = workspacePath
+		bundle.Artifacts, bundle.ArtifactsTruncated = collectBugReportArtifacts(filepath.Join(api.workspaceRoot, workspacePath), store.maxArtifactBytes)
+	}
+
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		log.Printf("[BUG_REPORT] Failed to encode bug report for session %s: %v", sessionID, err)
+		return
+	}
+	// Redact the serialized bundle so credentials in the request, messages, events and files are all masked
+	redacted, count := store.redaction.Redact(string(data))
+	if count > 0 {
+		if err := json.Unmarshal([]byte(redacted), bundle); err != nil {
+			log.Printf("[BUG_REPORT] Redaction broke the bug report for session %s: %v", sessionID, err)
+			return
+		}
+		bundle.Redactions = count
+		if data, err = json.Marshal(bundle); err != nil {
+			log.Printf("[BUG_REPORT] Failed to encode bug report for session %s: %v", sessionID, err)
+			return
+		}
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if _, exists := store.bundles[sessionID]; !exists {
+		store.order = append(store.order, sessionID)
+	}
+	store.bundles[sessionID] = data
+	for len(store.order) > store.maxBundles {
+		delete(store.bundles, store.order[0])
+		store.order = store.order[1:]
+	}
+	log.Printf("[BUG_REPORT] Captured bug report for failed session %s (%d events, %d artifacts)", sessionID, len(bundle.Events), len(bundle.Artifacts))
+}
+
+// collectBugReportArtifacts reads the workspace's files in path order until maxBytes is reached
+func collectBugReportArtifacts(root string, maxBytes int64) ([]BugReportArtifact, bool) {
+	var artifacts []BugReportArtifact
+	truncated := false
+	var total int64
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if total+info.Size() > maxBytes {
+			truncated = true
+			return nil
+		}
+		//nolint:gosec // G304: path comes from walking the run's workspace folder
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		total += info.Size()
+		artifacts = append(artifacts, BugReportArtifact{Path: filepath.ToSlash(rel), Size: info.Size(), Content: string(content)})
+		return nil
+	})
+	if err != nil {
+		log.Printf("[BUG_REPORT] Failed to read workspace %s: %v", root, err)
+	}
+	return artifacts, truncated
+}
+
+// handleGetBugReport downloads the bug report bundle of a failed session
+func (api *StreamingAPI) handleGetBugReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	sessionID := mux.Vars(r)["session_id"]
+	if api.bugReports == nil || !api.bugReports.enabled {
+		http.Error(w, "Bug reports are disabled (BUG_REPORTS_ENABLED=false)", http.StatusNotFound)
+		return
+	}
+	api.bugReports.mu.Lock()
+	data, exists := api.bugReports.bundles[sessionID]
+	api.bugReports.mu.Unlock()
+	if !exists {
+		http.Error(w, fmt.Sprintf("No bug report for session %s", sessionID), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "bug-report-"+strings.ReplaceAll(sessionID, `"`, "")+".json"))
+	w.Write(data)
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/mcpagent"
+)
+
+func newBugReportTestAPI(t *testing.T) *StreamingAPI {
+	t.Helper()
+	eventStore := events.NewEventStore(1000)
+	t.Cleanup(eventStore.Stop)
+
+	workspaceRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspaceRoot, "Workflow", "Demo"), 0755); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workspaceRoot, "Workflow", "Demo", "todo_final.md"), []byte("# Plan\n1. Collect URLs"), 0600); err != nil {
+		t.Fatalf("failed to write workspace file: %v", err)
+	}
+
+	return &StreamingAPI{
+		eventStore:          eventStore,
+		workspaceRoot:       workspaceRoot,
+		conversationHistory: make(map[string][]llmtypes.MessageContent),
+		bugReports: &bugReportStore{
+			enabled:          true,
+			maxBundles:       defaultBugReportMaxBundles,
+			maxArtifactBytes: defaultBugReportMaxArtifactBytes,
+			redaction:        mcpagent.DefaultRedactionPolicy(),
+			runs:             make(map[string]*bugReportRun),
+			bundles:          make(map[string][]byte),
+		},
+	}
+}
+
+func getBugReport(api *StreamingAPI, sessionID string) *httptest.ResponseRecorder {
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/sessions/"+sessionID+"/bug-report", nil), map[string]string{"session_id": sessionID})
+	rec := httptest.NewRecorder()
+	api.handleGetBugReport(rec, req)
+	return rec
+}
+
+func TestFailedRunProducesBugReportBundle(t *testing.T) {
+	api := newBugReportTestAPI(t)
+	api.registerBugReportRun("session-1", "query-1", "observer-1", QueryRequest{
+		Query:     "Audit the links\n📁 Files in context: Workflow/Demo",
+		AgentMode: "workflow",
+		Provider:  "openai",
+	})
+	for i := 0; i < 3; i++ {
+		api.eventStore.AddEvent("observer-1", events.Event{ID: fmt.Sprintf("evt-%d", i), Type: "tool_call_start", Timestamp: time.Now(), SessionID: "observer-1"})
+	}
+	api.conversationHistory["session-1"] = []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "use api_key=sk-abcdefghijklmnopqrstuvwx to fetch the links"),
+	}
+
+	api.captureBugReport("session-1", "Orchestrator execution failed: step 2 timed out")
+
+	rec := getBugReport(api, "session-1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Header().Get("Content-Disposition"), "bug-report-session-1.json") {
+		t.Fatalf("expected a downloadable bundle, got Content-Disposition %q", rec.Header().Get("Content-Disposition"))
+	}
+
+	var bundle BugReportBundle
+	if err := json.Unmarshal(rec.Body.Bytes(), &bundle); err != nil {
+		t.Fatalf("failed to decode bundle: %v", err)
+	}
+	if bundle.Error != "Orchestrator execution failed: step 2 timed out" || bundle.QueryID != "query-1" {
+		t.Fatalf("unexpected bundle error/query: %+v", bundle)
+	}
+	if bundle.Request == nil || bundle.Request.AgentMode != "workflow" {
+		t.Fatalf("expected the run's request in the bundle, got %+v", bundle.Request)
+	}
+	if len(bundle.Events) != 3 {
+		t.Fatalf("expected the 3-event timeline, got %d events", len(bundle.Events))
+	}
+	for i, event := range bundle.Events {
+		if event.ID != fmt.Sprintf("evt-%d", i) {
+			t.Fatalf("event %d out of order: got %s", i, event.ID)
+		}
+	}
+	if len(bundle.Artifacts) != 1 || bundle.Artifacts[0].Path != "todo_final.md" || !strings.Contains(bundle.Artifacts[0].Content, "Collect URLs") {
+		t.Fatalf("expected the workspace plan as an artifact, got %+v", bundle.Artifacts)
+	}
+	if strings.Contains(rec.Body.String(), "sk-abcdefghijklmnopqrstuvwx") || bundle.Redactions == 0 || !strings.Contains(string(bundle.Messages), "[REDACTED]") {
+		t.Fatalf("expected the API key redacted from the messages, got %s", bundle.Messages)
+	}
+}
+
+func TestCompletedRunHasNoBugReport(t *testing.T) {
+	api := newBugReportTestAPI(t)
+	api.registerBugReportRun("session-1", "query-1", "observer-1", QueryRequest{Query: "hello"})
+	api.forgetBugReportRun("session-1")
+	api.captureBugReport("session-1", "late failure after completion")
+
+	if rec := getBugReport(api, "session-1"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a completed run, got %d", rec.Code)
+	}
+}
@@ -218,6 +218,9 @@ func searchEvents(db database.Database) gin.HandlerFunc {
 			filter.EventType = events.EventType(eventType)
 		}
 
+		// Content search over tool names, messages and results
+		filter.TextQuery = c.Query("query")
+
 		if fromDateStr := c.Query("from_date"); fromDateStr != "" {
 			if fromDate, err := time.Parse(time.RFC3339, fromDateStr); err == nil {
 				filter.FromDate = fromDate
@@ -255,6 +258,7 @@ func searchEvents(db database.Database) gin.HandlerFunc {
 			ToDate:    filter.ToDate,
 			Limit:     filter.Limit,
 			Offset:    filter.Offset,
+			TextQuery: filter.TextQuery,
 		}
 
 		response, err := db.GetEvents(c.Request.Context(), req)
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"mcp-agent/agent_go/pkg/orchestrator"
+)
+
+// checkpointer is implemented by orchestrators that support named checkpoints
+type checkpointer interface {
+	CreateNamedCheckpoint(name string) (*orchestrator.NamedCheckpoint, error)
+	RestoreCheckpoint(name string) (*orchestrator.NamedCheckpoint, error)
+	ListCheckpoints() []*orchestrator.NamedCheckpoint
+}
+
+// CheckpointRequest names the checkpoint to create
+type CheckpointRequest struct {
+	Name string `json:"name"`
+}
+
+// CheckpointResponse reports a created or restored checkpoint, or lists a session's checkpoints
+type CheckpointResponse struct {
+	SessionID   string                          `json:"session_id"`
+	Checkpoint  *orchestrator.NamedCheckpoint   `json:"checkpoint,omitempty"`
+	Checkpoints []*orchestrator.NamedCheckpoint `json:"checkpoints,omitempty"`
+}
+
+// checkpointMaxBytesFromEnv reads CHECKPOINT_MAX_BYTES (0 keeps the orchestrator default)
+func checkpointMaxBytesFromEnv() int64 {
+	if envMax := os.Getenv("CHECKPOINT_MAX_BYTES"); envMax != "" {
+		if maxBytes, err := strconv.ParseInt(envMax, 10, 64); err == nil && maxBytes > 0 {
+			return maxBytes
+		}
+	}
+	return 0
+}
+
+// sessionCheckpointer returns the session's orchestrator if it supports named checkpoints
+func (api *StreamingAPI) sessionCheckpointer(sessionID string) (checkpointer, bool) {
+	api.orchestratorMux.RLock()
+	defer api.orchestratorMux.RUnlock()
+
+	orch, exists := api.workflowOrchestrators[sessionID]
+	if !exists {
+		orch, exists = api.plannerOrchestrators[sessionID]
+	}
+	if !exists {
+		return nil, false
+	}
+	cp, ok := orch.(checkpointer)
+	return cp, ok
+}
+
+// handleCheckpoints lists (GET) or creates (POST) the named checkpoints of a session's orchestrator
+func (api *StreamingAPI) handleCheckpoints(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	sessionID := mux.Vars(r)["session_id"]
+	cp, exists := api.sessionCheckpointer(sessionID)
+	if !exists {
+		http.Error(w, fmt.Sprintf("No orchestrator for session %s", sessionID), http.StatusNotFound)
+		return
+	}
+
+	response := CheckpointResponse{SessionID: sessionID}
+	if r.Method == "GET" {
+		response.Checkpoints = cp.ListCheckpoints()
+	} else {
+		var req CheckpointRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		checkpoint, err := cp.CreateNamedCheckpoint(req.Name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response.Checkpoint = checkpoint
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleRestoreCheckpoint restores a session's orchestrator to a named checkpoint.
+// Restoring is refused while the session is running so the run doesn't race the restored files.
+func (api *StreamingAPI) handleRestoreCheckpoint(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	vars := mux.Vars(r)
+	sessionID := vars["session_id"]
+	cp, exists := api.sessionCheckpointer(sessionID)
+	if !exists {
+		http.Error(w, fmt.Sprintf("No orchestrator for session %s", sessionID), http.StatusNotFound)
+		return
+	}
+
+	api.activeSessionsMux.RLock()
+	session, tracked := api.activeSessions[sessionID]
+	running := tracked && session.Status == "running"
+	api.activeSessionsMux.RUnlock()
+	if running {
+		http.Error(w, "Stop the session before restoring a checkpoint", http.StatusConflict)
+		return
+	}
+
+	checkpoint, err := cp.RestoreCheckpoint(vars["name"])
+	if errors.Is(err, orchestrator.ErrCheckpointNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CheckpointResponse{SessionID: sessionID, Checkpoint: checkpoint})
+}
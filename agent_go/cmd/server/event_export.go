@@ -0,0 +1,263 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+)
+
+const (
+	eventExportFormatJSON   = "json"
+	eventExportFormatNDJSON = "ndjson"
+
+	defaultEventExportMaxEvents  = 500
+	defaultEventExportMaxBytes   = 1 << 20 // 1MB per request body
+	defaultEventExportMaxRetries = 3
+	defaultEventExportBackoff    = 500 * time.Millisecond
+)
+
+// EventExportRequest asks for a session's full event timeline to be POSTed to a URL when it completes
+type EventExportRequest struct {
+	URL    string `json:"url"`
+	Format string `json:"format,omitempty"` // "json" (default) or "ndjson"
+}
+
+// eventExportConfig holds the webhook settings used to export one session's events
+type eventExportConfig struct {
+	URL        string
+	Format     string
+	Secret     string // HMAC-SHA256 signing key; empty disables signing
+	MaxEvents  int    // Maximum events per request
+	MaxBytes   int    // Maximum request body size
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+// EventExportPage is the JSON body of one export request
+type EventExportPage struct {
+	SessionID  string         `json:"session_id"`
+	Status     string         `json:"status"`
+	Page       int            `json:"page"`
+	TotalPages int            `json:"total_pages"`
+	Truncated  bool           `json:"truncated"` // Earlier events were trimmed from the buffer before export
+	Events     []events.Event `json:"events"`
+}
+
+// eventExportDefaultsFromEnv reads the server-wide event export settings.
+// EVENT_EXPORT_WEBHOOK_URL exports every session; requests may still set their own URL.
+func eventExportDefaultsFromEnv() eventExportConfig {
+	config := eventExportConfig{
+		URL:        os.Getenv("EVENT_EXPORT_WEBHOOK_URL"),
+		Format:     os.Getenv("EVENT_EXPORT_FORMAT"),
+		Secret:     os.Getenv("EVENT_EXPORT_WEBHOOK_SECRET"),
+		MaxEvents:  defaultEventExportMaxEvents,
+		MaxBytes:   defaultEventExportMaxBytes,
+		MaxRetries: defaultEventExportMaxRetries,
+		Backoff:    defaultEventExportBackoff,
+	}
+	if envMax := os.Getenv("EVENT_EXPORT_MAX_EVENTS"); envMax != "" {
+		if maxEvents, err := strconv.Atoi(envMax); err == nil && maxEvents > 0 {
+			config.MaxEvents = maxEvents
+		}
+	}
+	if envMax := os.Getenv("EVENT_EXPORT_MAX_BYTES"); envMax != "" {
+		if maxBytes, err := strconv.Atoi(envMax); err == nil && maxBytes > 0 {
+			config.MaxBytes = maxBytes
+		}
+	}
+	return config
+}
+
+// registerEventExport records where the session's events go on completion; the request
+// overrides the server default URL and format
+func (api *StreamingAPI) registerEventExport(sessionID string, req *EventExportRequest) {
+	config := api.eventExportDefaults
+	if req != nil && req.URL != "" {
+		config.URL = req.URL
+		if req.Format != "" {
+			config.Format = req.Format
+		}
+	}
+	if config.URL == "" {
+		return
+	}
+	if config.Format != eventExportFormatNDJSON {
+		config.Format = eventExportFormatJSON
+	}
+
+	api.eventExportMux.Lock()
+	defer api.eventExportMux.Unlock()
+	if api.eventExports == nil {
+		api.eventExports = make(map[string]*eventExportConfig)
+	}
+	api.eventExports[sessionID] = &config
+}
+
+// takeEventExport removes and returns the session's export config so each session exports once
+func (api *StreamingAPI) takeEventExport(sessionID string) *eventExportConfig {
+	api.eventExportMux.Lock()
+	defer api.eventExportMux.Unlock()
+	config := api.eventExports[sessionID]
+	delete(api.eventExports, sessionID)
+	return config
+}
+
+// exportSessionEvents POSTs the session's ordered events to its export webhook, paginated by size
+func (api *StreamingAPI) exportSessionEvents(sessionID, observerID, status string) error {
+	config := api.takeEventExport(sessionID)
+	if config == nil {
+		return nil
+	}
+
+	buffered, trimmed := api.eventStore.Snapshot(observerID)
+	sessionEvents := make([]events.Event, 0, len(buffered))
+	for _, event := range buffered {
+		if event.SessionID == "" || event.SessionID == sessionID {
+			sessionEvents = append(sessionEvents, event)
+		}
+	}
+
+	pages, err := paginateExportEvents(sessionEvents, config.MaxEvents, config.MaxBytes)
+	if err != nil {
+		return fmt.Errorf("failed to paginate events for session %s: %w", sessionID, err)
+	}
+
+	for i, pageEvents := range pages {
+		page := EventExportPage{
+			SessionID:  sessionID,
+			Status:     status,
+			Page:       i + 1,
+			TotalPages: len(pages),
+			Truncated:  trimmed > 0,
+			Events:     pageEvents,
+		}
+		if err := api.postEventExportPage(config, page); err != nil {
+			log.Printf("[EVENT_EXPORT] Failed to export page %d/%d for session %s: %v", page.Page, page.TotalPages, sessionID, err)
+			return err
+		}
+	}
+
+	log.Printf("[EVENT_EXPORT] Exported %d events for session %s in %d page(s)", len(sessionEvents), sessionID, len(pages))
+	return nil
+}
+
+// paginateExportEvents splits events into ordered pages of at most maxEvents events and
+// roughly maxBytes of encoded events. A single oversized event gets a page of its own.
+func paginateExportEvents(eventList []events.Event, maxEvents, maxBytes int) ([][]events.Event, error) {
+	if maxEvents <= 0 {
+		maxEvents = defaultEventExportMaxEvents
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultEventExportMaxBytes
+	}
+
+	pages := [][]events.Event{}
+	current := []events.Event{}
+	currentBytes := 0
+	for _, event := range eventList {
+		encoded, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		if len(current) > 0 && (len(current) >= maxEvents || currentBytes+len(encoded) > maxBytes) {
+			pages = append(pages, current)
+			current, currentBytes = []events.Event{}, 0
+		}
+		current = append(current, event)
+		currentBytes += len(encoded) + 1
+	}
+	// Always send at least one page so the receiver learns the session completed
+	if len(current) > 0 || len(pages) == 0 {
+		pages = append(pages, current)
+	}
+	return pages, nil
+}
+
+// encodeEventExportPage renders a page as a JSON document or one event per NDJSON line
+func encodeEventExportPage(format string, page EventExportPage) ([]byte, string, error) {
+	if format != eventExportFormatNDJSON {
+		body, err := json.Marshal(page)
+		return body, "application/json", err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, event := range page.Events {
+		if err := encoder.Encode(event); err != nil {
+			return nil, "", err
+		}
+	}
+	return buf.Bytes(), "application/x-ndjson", nil
+}
+
+// signEventExport returns the hex HMAC-SHA256 of the body
+func signEventExport(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// postEventExportPage delivers one page, retrying with exponential backoff on network errors,
+// 429 and 5xx responses
+func (api *StreamingAPI) postEventExportPage(config *eventExportConfig, page EventExportPage) error {
+	body, contentType, err := encodeEventExportPage(config.Format, page)
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+
+	client := api.eventExportClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	backoff := config.Backoff
+	var lastErr error
+	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, config.URL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create export request: %w", err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Session-ID", page.SessionID)
+		req.Header.Set("X-Session-Status", page.Status)
+		req.Header.Set("X-Export-Page", strconv.Itoa(page.Page))
+		req.Header.Set("X-Export-Total-Pages", strconv.Itoa(page.TotalPages))
+		if config.Secret != "" {
+			req.Header.Set("X-Signature-256", "sha256="+signEventExport(config.Secret, body))
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return lastErr
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", config.MaxRetries+1, lastErr)
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+)
+
+// exportReceiver records the event IDs of every successfully received export page
+type exportReceiver struct {
+	mu        sync.Mutex
+	pages     []EventExportPage
+	failFirst bool
+	requests  int
+}
+
+func (rcv *exportReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.requests++
+	if rcv.failFirst && rcv.requests == 1 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	if r.Header.Get("X-Signature-256") != "sha256="+signEventExport("s3cret", body) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	page := EventExportPage{SessionID: r.Header.Get("X-Session-ID"), Status: r.Header.Get("X-Session-Status")}
+	page.Page, _ = strconv.Atoi(r.Header.Get("X-Export-Page"))
+	page.TotalPages, _ = strconv.Atoi(r.Header.Get("X-Export-Total-Pages"))
+	if r.Header.Get("Content-Type") == "application/x-ndjson" {
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			var event map[string]interface{}
+			json.Unmarshal(scanner.Bytes(), &event)
+			page.Events = append(page.Events, events.Event{ID: event["id"].(string)})
+		}
+	} else {
+		var decoded struct {
+			Events []struct {
+				ID string `json:"id"`
+			} `json:"events"`
+		}
+		json.Unmarshal(body, &decoded)
+		for _, event := range decoded.Events {
+			page.Events = append(page.Events, events.Event{ID: event.ID})
+		}
+	}
+	rcv.pages = append(rcv.pages, page)
+}
+
+func newExportTestAPI(t *testing.T, eventCount int) *StreamingAPI {
+	t.Helper()
+	eventStore := events.NewEventStore(1000)
+	t.Cleanup(eventStore.Stop)
+
+	for i := 0; i < eventCount; i++ {
+		eventStore.AddEvent("observer-1", events.Event{ID: fmt.Sprintf("evt-%03d", i), Type: "tool_call_start", Timestamp: time.Now(), SessionID: "session-1"})
+	}
+	// Another session on the same observer must not leak into the export
+	eventStore.AddEvent("observer-1", events.Event{ID: "other", Type: "tool_call_start", SessionID: "session-2"})
+
+	return &StreamingAPI{
+		eventStore: eventStore,
+		eventExportDefaults: eventExportConfig{
+			Secret:     "s3cret",
+			MaxEvents:  4,
+			MaxRetries: 2,
+			Backoff:    time.Millisecond,
+		},
+	}
+}
+
+func assertFullOrderedExport(t *testing.T, rcv *exportReceiver, eventCount, wantPages int) {
+	t.Helper()
+	if len(rcv.pages) != wantPages {
+		t.Fatalf("expected %d pages, got %d", wantPages, len(rcv.pages))
+	}
+	var ids []string
+	for i, page := range rcv.pages {
+		if page.Page != i+1 || page.TotalPages != wantPages || page.SessionID != "session-1" || page.Status != "completed" {
+			t.Fatalf("unexpected page metadata: %+v", page)
+		}
+		for _, event := range page.Events {
+			ids = append(ids, event.ID)
+		}
+	}
+	if len(ids) != eventCount {
+		t.Fatalf("expected %d events, got %d: %v", eventCount, len(ids), ids)
+	}
+	for i, id := range ids {
+		if id != fmt.Sprintf("evt-%03d", i) {
+			t.Fatalf("event %d out of order: got %s", i, id)
+		}
+	}
+}
+
+func TestEventExportPostsFullOrderedEventSet(t *testing.T) {
+	rcv := &exportReceiver{failFirst: true}
+	ts := httptest.NewServer(rcv)
+	defer ts.Close()
+
+	api := newExportTestAPI(t, 10)
+	api.registerEventExport("session-1", &EventExportRequest{URL: ts.URL})
+
+	if err := api.exportSessionEvents("session-1", "observer-1", "completed"); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	// The first attempt fails with 503 and is retried
+	assertFullOrderedExport(t, rcv, 10, 3)
+
+	// A session exports only once
+	if err := api.exportSessionEvents("session-1", "observer-1", "completed"); err != nil || len(rcv.pages) != 3 {
+		t.Fatalf("expected no second export, got %d pages (err %v)", len(rcv.pages), err)
+	}
+}
+
+func TestEventExportNDJSON(t *testing.T) {
+	rcv := &exportReceiver{}
+	ts := httptest.NewServer(rcv)
+	defer ts.Close()
+
+	api := newExportTestAPI(t, 7)
+	api.registerEventExport("session-1", &EventExportRequest{URL: ts.URL, Format: "ndjson"})
+
+	if err := api.exportSessionEvents("session-1", "observer-1", "completed"); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	assertFullOrderedExport(t, rcv, 7, 2)
+}
+
+func TestPaginateExportEventsRespectsByteLimit(t *testing.T) {
+	eventList := []events.Event{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	encoded, _ := json.Marshal(eventList[0])
+
+	pages, err := paginateExportEvents(eventList, 100, 2*len(encoded)+2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pages) != 2 || len(pages[0]) != 2 || len(pages[1]) != 1 {
+		t.Fatalf("expected pages of 2 and 1 events, got %v", pages)
+	}
+}
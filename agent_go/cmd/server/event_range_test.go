@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/pkg/database"
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+// sessionEventsDB serves persisted session events; other Database methods are not used
+type sessionEventsDB struct {
+	database.Database
+	events map[string][]database.Event
+}
+
+func (db *sessionEventsDB) GetEventsBySession(ctx context.Context, sessionID string, limit, offset int) ([]database.Event, error) {
+	stored := db.events[sessionID]
+	if offset >= len(stored) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(stored) {
+		end = len(stored)
+	}
+	return stored[offset:end], nil
+}
+
+func newEventRangeTestAPI(t *testing.T, count int) (*StreamingAPI, string) {
+	t.Helper()
+	eventStore := events.NewEventStore(1000)
+	t.Cleanup(eventStore.Stop)
+	observerManager := events.NewObserverManager(eventStore)
+	observer := observerManager.RegisterObserver("session-1")
+
+	db := &sessionEventsDB{events: make(map[string][]database.Event)}
+	for i := 0; i < count; i++ {
+		id := fmt.Sprintf("event-%d", i)
+		eventStore.AddEvent(observer.ID, events.Event{ID: id, Type: "tool_call_start", Timestamp: time.Now()})
+		db.events["session-1"] = append(db.events["session-1"], database.Event{
+			ID: id, SessionID: "session-1", EventType: "tool_call_start", Timestamp: time.Now(), EventData: json.RawMessage(`{"tool_name":"list_buckets"}`),
+		})
+	}
+
+	return &StreamingAPI{eventStore: eventStore, observerManager: observerManager, chatDB: db}, observer.ID
+}
+
+func getEventRange(api *StreamingAPI, observerID, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/observer/"+observerID+"/events?"+query, nil)
+	req = mux.SetURLVars(req, map[string]string{"observer_id": observerID})
+	rec := httptest.NewRecorder()
+	api.handleGetEvents(rec, req)
+	return rec
+}
+
+// eventRangeResult is GetEventRangeResponse with event data left undecoded
+type eventRangeResult struct {
+	Events []struct {
+		ID   string                 `json:"id"`
+		Data map[string]interface{} `json:"data"`
+	} `json:"events"`
+	From           int    `json:"from"`
+	To             int    `json:"to"`
+	LastEventIndex int    `json:"last_event_index"`
+	Source         string `json:"source"`
+}
+
+func decodeEventRange(t *testing.T, rec *httptest.ResponseRecorder) eventRangeResult {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var response eventRangeResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return response
+}
+
+func eventIDs(response eventRangeResult) []string {
+	ids := make([]string, len(response.Events))
+	for i, event := range response.Events {
+		ids[i] = event.ID
+	}
+	return ids
+}
+
+func TestEventRangeReturnsExactlyTheRequestedEvents(t *testing.T) {
+	api, observerID := newEventRangeTestAPI(t, 10)
+
+	response := decodeEventRange(t, getEventRange(api, observerID, "from=3&to=6"))
+	if got, want := fmt.Sprint(eventIDs(response)), "[event-3 event-4 event-5 event-6]"; got != want {
+		t.Fatalf("events = %s, want %s", got, want)
+	}
+	if response.From != 3 || response.To != 6 || response.LastEventIndex != 9 || response.Source != "memory" {
+		t.Errorf("unexpected range metadata: %+v", response)
+	}
+
+	// A range request does not move the polling cursor
+	polled, _, _ := api.eventStore.GetEvents(observerID, -1)
+	if len(polled) != 10 {
+		t.Errorf("expected the cursor poll to still return all 10 events, got %d", len(polled))
+	}
+}
+
+func TestEventRangeClipsToLastEvent(t *testing.T) {
+	api, observerID := newEventRangeTestAPI(t, 5)
+
+	response := decodeEventRange(t, getEventRange(api, observerID, "from=3&to=50"))
+	if got, want := fmt.Sprint(eventIDs(response)), "[event-3 event-4]"; got != want {
+		t.Fatalf("events = %s, want %s", got, want)
+	}
+	if response.To != 4 {
+		t.Errorf("to = %d, want it clipped to 4", response.To)
+	}
+}
+
+func TestEventRangeReadsEvictedEventsFromDatabase(t *testing.T) {
+	api, observerID := newEventRangeTestAPI(t, 6)
+	api.eventStore.EvictBuffer(observerID)
+	api.eventStore.AddEvent(observerID, events.Event{ID: "event-6", Type: "tool_call_end"})
+
+	response := decodeEventRange(t, getEventRange(api, observerID, "from=4&to=6"))
+	if got, want := fmt.Sprint(eventIDs(response)), "[event-4 event-5 event-6]"; got != want {
+		t.Fatalf("events = %s, want %s", got, want)
+	}
+	if response.Source != "mixed" {
+		t.Errorf("source = %q, want mixed", response.Source)
+	}
+	if data := response.Events[0].Data; data == nil || data["type"] != "tool_call_start" {
+		t.Errorf("expected persisted event data, got %+v", response.Events[0].Data)
+	}
+}
+
+func TestEventRangeEvictedWithoutDatabase(t *testing.T) {
+	api, observerID := newEventRangeTestAPI(t, 4)
+	api.chatDB = nil
+	api.eventStore.EvictBuffer(observerID)
+
+	if rec := getEventRange(api, observerID, "from=0&to=2"); rec.Code != http.StatusGone {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusGone)
+	}
+}
+
+func TestEventRangeRejectsInvalidRanges(t *testing.T) {
+	api, observerID := newEventRangeTestAPI(t, 3)
+
+	for _, query := range []string{"from=2", "from=a&to=3", "from=5&to=2", "from=-1&to=2", fmt.Sprintf("from=0&to=%d", maxEventRange)} {
+		if rec := getEventRange(api, observerID, query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if rec := getEventRange(api, "unknown-observer", "from=0&to=1"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown observer: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestGetEventsFiltersByTypeAndLevel(t *testing.T) {
+	eventStore := events.NewEventStore(1000)
+	t.Cleanup(eventStore.Stop)
+	observerManager := events.NewObserverManager(eventStore)
+	observer := observerManager.RegisterObserver("session-1")
+	for i, eventType := range []string{"conversation_start", "tool_call_start", "tool_call_end", "tool_call_start"} {
+		eventStore.AddEvent(observer.ID, events.Event{
+			ID: fmt.Sprintf("event-%d", i), Type: eventType, Timestamp: time.Now(),
+			Data: &unifiedevents.AgentEvent{Type: unifiedevents.EventType(eventType), HierarchyLevel: i},
+		})
+	}
+	testLogger := logger.CreateDiscardLogger("error")
+	api := &StreamingAPI{eventStore: eventStore, observerManager: observerManager, logger: testLogger}
+
+	rec := getEventRange(api, observer.ID, "types=tool_call_start,tool_call_end&max_level=2")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Events []struct {
+			ID string `json:"id"`
+		} `json:"events"`
+		LastEventIndex int `json:"last_event_index"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Events) != 2 || response.Events[0].ID != "event-1" || response.Events[1].ID != "event-2" || response.LastEventIndex != 3 {
+		t.Fatalf("unexpected filtered events %+v", response)
+	}
+
+	for _, query := range []string{"min_level=x", "min_level=3&max_level=1"} {
+		if rec := getEventRange(api, observer.ID, query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
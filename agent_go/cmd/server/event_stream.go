@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// eventStreamHeartbeat is how often an idle event stream sends a keep-alive comment
+var eventStreamHeartbeat = 15 * time.Second
+
+// handleStreamEvents streams an observer's events as Server-Sent Events as they arrive.
+// It accepts the polling filters (types, min_level, max_level) and resumes after the
+// since cursor or the Last-Event-ID header of a reconnecting client.
+func (api *StreamingAPI) handleStreamEvents(w http.ResponseWriter, r *http.Request) {
+	observerID := mux.Vars(r)["observer_id"]
+	if observerID == "" {
+		http.Error(w, "Observer ID is required", http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	filter, err := parseEventFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cursor := -1
+	resumeFrom := r.Header.Get("Last-Event-ID")
+	if resumeFrom == "" {
+		resumeFrom = r.URL.Query().Get("since")
+	}
+	if resumeFrom != "" {
+		if since, err := strconv.Atoi(resumeFrom); err == nil {
+			cursor = since
+		}
+	}
+
+	// Subscribe before the first read so no event slips in between
+	notify, unsubscribe := api.eventStore.Subscribe(observerID)
+	defer unsubscribe()
+
+	page, exists := api.eventStore.GetEventsPage(observerID, cursor, filter)
+	if !exists {
+		http.Error(w, "Observer not found", http.StatusNotFound)
+		return
+	}
+
+	// The stream outlives the server's write timeout; heartbeats detect dead clients instead
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		for i, event := range page.Events {
+			payload, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("[SSE] Failed to encode %s event for observer %s: %v", event.Type, observerID, err)
+				continue
+			}
+			// The last event of a batch carries the cursor a reconnecting client resumes from
+			if i == len(page.Events)-1 {
+				fmt.Fprintf(w, "id: %d\n", page.NextCursor)
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, payload)
+		}
+		if len(page.Events) > 0 {
+			flusher.Flush()
+		}
+		cursor = page.NextCursor
+		api.observerManager.UpdateObserverActivity(observerID)
+
+		select {
+		case _, open := <-notify:
+			if !open {
+				// The observer was removed
+				return
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+
+		if page, exists = api.eventStore.GetEventsPage(observerID, cursor, filter); !exists {
+			return
+		}
+	}
+}
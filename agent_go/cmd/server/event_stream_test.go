@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+func TestStreamEventsPushesEventsAsTheyArrive(t *testing.T) {
+	eventStore := events.NewEventStore(1000)
+	t.Cleanup(eventStore.Stop)
+	observerManager := events.NewObserverManager(eventStore)
+	observer := observerManager.RegisterObserver("session-1")
+	testLogger := logger.CreateDiscardLogger("error")
+	api := &StreamingAPI{eventStore: eventStore, observerManager: observerManager, logger: testLogger}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/observer/{observer_id}/stream", api.handleStreamEvents).Methods("GET")
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	eventStore.AddEvent(observer.ID, events.Event{ID: "event-0", Type: "conversation_start", Timestamp: time.Now()})
+
+	resp, err := http.Get(server.URL + "/api/observer/" + observer.ID + "/stream")
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	readEvent := func() (string, string) {
+		t.Helper()
+		var id, data string
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatal("stream closed before an event arrived")
+				}
+				switch {
+				case strings.HasPrefix(line, "id: "):
+					id = strings.TrimPrefix(line, "id: ")
+				case strings.HasPrefix(line, "data: "):
+					data = strings.TrimPrefix(line, "data: ")
+				case line == "" && data != "":
+					return id, data
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("timed out waiting for a streamed event")
+			}
+		}
+	}
+
+	// The buffered event is replayed first, then new events are pushed as they are added
+	if id, data := readEvent(); id != "0" || !strings.Contains(data, `"id":"event-0"`) {
+		t.Fatalf("unexpected first event id=%q data=%s", id, data)
+	}
+	eventStore.AddEvent(observer.ID, events.Event{ID: "event-1", Type: "tool_call_start", Timestamp: time.Now()})
+	if id, data := readEvent(); id != "1" || !strings.Contains(data, `"id":"event-1"`) {
+		t.Fatalf("unexpected pushed event id=%q data=%s", id, data)
+	}
+
+	// Removing the observer ends the stream
+	observerManager.RemoveObserver(observer.ID)
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-lines:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("stream stayed open after the observer was removed")
+		}
+	}
+}
+
+func TestStreamEventsUnknownObserver(t *testing.T) {
+	eventStore := events.NewEventStore(1000)
+	t.Cleanup(eventStore.Stop)
+	api := &StreamingAPI{eventStore: eventStore, observerManager: events.NewObserverManager(eventStore)}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/observer/missing/stream", nil)
+	req = mux.SetURLVars(req, map[string]string{"observer_id": "missing"})
+	rec := httptest.NewRecorder()
+	api.handleStreamEvents(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	virtualtools "mcp-agent/agent_go/cmd/server/virtual-tools"
+	"mcp-agent/agent_go/internal/events"
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+)
+
+// eventWebSocketWriteTimeout bounds each write so a stalled client cannot block the stream
+const eventWebSocketWriteTimeout = 10 * time.Second
+
+// Control message types a WebSocket client may send
+const (
+	wsControlStopSession   = "stop_session"
+	wsControlHumanFeedback = "human_feedback"
+)
+
+// wsControlMessage is an inbound control message. stop_session stops SessionID (the observer's
+// session when empty); human_feedback answers the request with UniqueID.
+type wsControlMessage struct {
+	Type      string `json:"type"`
+	SessionID string `json:"session_id,omitempty"`
+	UniqueID  string `json:"unique_id,omitempty"`
+	Response  string `json:"response,omitempty"`
+}
+
+// wsServerMessage is an outbound message: a streamed event, or the ack or error of a control message
+type wsServerMessage struct {
+	Type    string        `json:"type"` // "event", "ack" or "error"
+	Cursor  *int          `json:"cursor,omitempty"`
+	Event   *events.Event `json:"event,omitempty"`
+	Control string        `json:"control,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
+// eventWebSocketConn serializes writes; gorilla/websocket allows one concurrent writer
+type eventWebSocketConn struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+func (c *eventWebSocketConn) writeJSON(message wsServerMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(eventWebSocketWriteTimeout))
+	return c.conn.WriteJSON(message)
+}
+
+func (c *eventWebSocketConn) writeControl(messageType int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteControl(messageType, data, time.Now().Add(eventWebSocketWriteTimeout))
+}
+
+// close sends a close frame with the given reason and closes the connection
+func (c *eventWebSocketConn) close(reason string) {
+	_ = c.writeControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason))
+	c.conn.Close()
+}
+
+// checkWebSocketOrigin applies the CORS origins to the upgrade request. Non-browser clients send no Origin.
+func (api *StreamingAPI) checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range api.config.CORSOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// handleEventWebSocket streams an observer's events over a WebSocket, with the same filters and
+// since cursor as the polling API. Clients can send stop_session and human_feedback control
+// messages on the same socket. The socket is closed when the session completes or the observer
+// is removed.
+func (api *StreamingAPI) handleEventWebSocket(w http.ResponseWriter, r *http.Request) {
+	observerID := mux.Vars(r)["observer_id"]
+	if observerID == "" {
+		http.Error(w, "Observer ID is required", http.StatusBadRequest)
+		return
+	}
+	filter, err := parseEventFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cursor := -1
+	if since := r.URL.Query().Get("since"); since != "" {
+		if cursor, err = strconv.Atoi(since); err != nil {
+			http.Error(w, "since must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Subscribe before the first read so no event slips in between
+	notify, unsubscribe := api.eventStore.Subscribe(observerID)
+	defer unsubscribe()
+
+	page, exists := api.eventStore.GetEventsPage(observerID, cursor, filter)
+	if !exists {
+		http.Error(w, "Observer not found", http.StatusNotFound)
+		return
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: api.checkWebSocketOrigin}
+	rawConn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied to the client
+		log.Printf("[WS] Failed to upgrade connection for observer %s: %v", observerID, err)
+		return
+	}
+	conn := &eventWebSocketConn{conn: rawConn}
+
+	// The reader handles control messages and notices when the client goes away
+	clientGone := make(chan struct{})
+	go func() {
+		defer close(clientGone)
+		api.readEventWebSocketControls(conn, observerID)
+	}()
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		for i := range page.Events {
+			event := page.Events[i]
+			message := wsServerMessage{Type: "event", Event: &event}
+			// The last event of a batch carries the cursor a reconnecting client resumes from
+			if i == len(page.Events)-1 {
+				message.Cursor = &page.NextCursor
+			}
+			if err := conn.writeJSON(message); err != nil {
+				log.Printf("[WS] Failed to send %s event to observer %s: %v", event.Type, observerID, err)
+				conn.conn.Close()
+				return
+			}
+			if isSessionCompletion(event) {
+				conn.close("session completed")
+				return
+			}
+		}
+		cursor = page.NextCursor
+		api.observerManager.UpdateObserverActivity(observerID)
+
+		select {
+		case _, open := <-notify:
+			if !open {
+				conn.close("observer removed")
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.writeControl(websocket.PingMessage, nil); err != nil {
+				conn.conn.Close()
+				return
+			}
+		case <-clientGone:
+			conn.conn.Close()
+			return
+		}
+
+		if page, exists = api.eventStore.GetEventsPage(observerID, cursor, filter); !exists {
+			conn.close("observer removed")
+			return
+		}
+	}
+}
+
+// readEventWebSocketControls applies control messages until the connection fails or is closed
+func (api *StreamingAPI) readEventWebSocketControls(conn *eventWebSocketConn, observerID string) {
+	for {
+		_, payload, err := conn.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var control wsControlMessage
+		if err := json.Unmarshal(payload, &control); err != nil {
+			_ = conn.writeJSON(wsServerMessage{Type: "error", Message: "invalid control message: " + err.Error()})
+			continue
+		}
+
+		switch control.Type {
+		case wsControlStopSession:
+			sessionID := control.SessionID
+			if sessionID == "" {
+				if observer, exists := api.observerManager.GetObserver(observerID); exists {
+					sessionID = observer.SessionID
+				}
+			}
+			if sessionID == "" {
+				_ = conn.writeJSON(wsServerMessage{Type: "error", Control: control.Type, Message: "session_id is required"})
+				continue
+			}
+			api.stopSession(sessionID)
+			_ = conn.writeJSON(wsServerMessage{Type: "ack", Control: control.Type, Message: "Session stopped"})
+		case wsControlHumanFeedback:
+			if control.UniqueID == "" || control.Response == "" {
+				_ = conn.writeJSON(wsServerMessage{Type: "error", Control: control.Type, Message: "unique_id and response are required"})
+				continue
+			}
+			var feedbackStore virtualtools.HumanFeedbackStore = virtualtools.GetHumanFeedbackStore()
+			if err := feedbackStore.Submit(control.UniqueID, control.Response); err != nil {
+				_ = conn.writeJSON(wsServerMessage{Type: "error", Control: control.Type, Message: err.Error()})
+				continue
+			}
+			log.Printf("[HUMAN_FEEDBACK] Submitted response over WebSocket for unique_id %s", control.UniqueID)
+			_ = conn.writeJSON(wsServerMessage{Type: "ack", Control: control.Type, Message: "Human feedback submitted successfully"})
+		default:
+			_ = conn.writeJSON(wsServerMessage{Type: "error", Control: control.Type, Message: "unknown control message type"})
+		}
+	}
+}
+
+// isSessionCompletion reports whether event is the top-level completion of a run
+func isSessionCompletion(event events.Event) bool {
+	if event.Type != string(unifiedevents.EventTypeUnifiedCompletion) {
+		return false
+	}
+	return event.Data == nil || event.Data.HierarchyLevel == 0
+}
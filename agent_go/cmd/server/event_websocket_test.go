@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	virtualtools "mcp-agent/agent_go/cmd/server/virtual-tools"
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+func TestEventWebSocketStreamsEventsAndAcceptsFeedback(t *testing.T) {
+	eventStore := events.NewEventStore(1000)
+	t.Cleanup(eventStore.Stop)
+	observerManager := events.NewObserverManager(eventStore)
+	observer := observerManager.RegisterObserver("session-1")
+	testLogger := logger.CreateDiscardLogger("error")
+	api := &StreamingAPI{eventStore: eventStore, observerManager: observerManager, logger: testLogger}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/observer/{observer_id}/ws", api.handleEventWebSocket).Methods("GET")
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	eventStore.AddEvent(observer.ID, events.Event{ID: "event-0", Type: "request_human_feedback", Timestamp: time.Now()})
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/observer/" + observer.ID + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var message wsServerMessage
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("failed to read event: %v", err)
+	}
+	if message.Type != "event" || message.Event == nil || message.Event.ID != "event-0" || message.Cursor == nil || *message.Cursor != 0 {
+		t.Fatalf("unexpected first message %+v", message)
+	}
+
+	// Feedback sent over the socket reaches the shared store
+	uniqueID := "ws-feedback-" + observer.ID
+	store := virtualtools.GetHumanFeedbackStore()
+	if err := store.CreateRequest(uniqueID, "Continue?"); err != nil {
+		t.Fatalf("failed to create feedback request: %v", err)
+	}
+	if err := conn.WriteJSON(wsControlMessage{Type: wsControlHumanFeedback, UniqueID: uniqueID, Response: "yes"}); err != nil {
+		t.Fatalf("failed to send feedback: %v", err)
+	}
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("failed to read ack: %v", err)
+	}
+	if message.Type != "ack" || message.Control != wsControlHumanFeedback {
+		t.Fatalf("unexpected ack %+v", message)
+	}
+	if response, err := store.WaitForResponse(uniqueID, time.Second); err != nil || response != "yes" {
+		t.Fatalf("store response = %q, %v; want yes", response, err)
+	}
+
+	// A top-level completion closes the socket cleanly
+	eventStore.AddEvent(observer.ID, events.Event{ID: "event-1", Type: "unified_completion", Timestamp: time.Now()})
+	if err := conn.ReadJSON(&message); err != nil || message.Event == nil || message.Event.ID != "event-1" {
+		t.Fatalf("unexpected completion message %+v: %v", message, err)
+	}
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Fatalf("expected a normal close after completion, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Event metadata keys tagging the events of a request served by an experiment variant
+const (
+	experimentTagKey = "experiment"
+	variantTagKey    = "experiment_variant"
+)
+
+// ExperimentVariant is a named agent configuration receiving Weight out of the experiment's total weight.
+// Empty fields keep the request's own value.
+type ExperimentVariant struct {
+	Name        string  `json:"name"`
+	Weight      float64 `json:"weight"`
+	Provider    string  `json:"provider,omitempty"`
+	ModelID     string  `json:"model_id,omitempty"`
+	Temperature float64 `json:"temperature,omitempty"`
+	MaxTurns    int     `json:"max_turns,omitempty"`
+}
+
+// Experiment splits traffic between variants. Requests are assigned by a hash of their session
+// (or tenant, see KeyBy), so follow-up queries of a session stay on the same variant.
+type Experiment struct {
+	Name     string              `json:"name"`
+	KeyBy    string              `json:"key_by,omitempty"` // "session" (default) or "tenant" (X-Tenant-ID header)
+	Variants []ExperimentVariant `json:"variants"`
+}
+
+// experimentFromEnv loads the experiment defined in the JSON file at EXPERIMENT_CONFIG_PATH;
+// returns nil when unset or invalid
+func experimentFromEnv() *Experiment {
+	path := os.Getenv("EXPERIMENT_CONFIG_PATH")
+	if path == "" {
+		return nil
+	}
+	experiment, err := loadExperiment(path)
+	if err != nil {
+		log.Printf("[EXPERIMENT] Disabled: %v", err)
+		return nil
+	}
+	log.Printf("[EXPERIMENT] Running %q with %d variants keyed by %s", experiment.Name, len(experiment.Variants), experiment.KeyBy)
+	return experiment
+}
+
+func loadExperiment(path string) (*Experiment, error) {
+	//nolint:gosec // G304: experiment path comes from server configuration
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read experiment config: %w", err)
+	}
+	var experiment Experiment
+	if err := json.Unmarshal(data, &experiment); err != nil {
+		return nil, fmt.Errorf("invalid experiment config: %w", err)
+	}
+	if err := experiment.validate(); err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// validate checks the experiment has a name and uniquely named variants with a positive total weight
+func (e *Experiment) validate() error {
+	if e.Name == "" {
+		return fmt.Errorf("experiment name is required")
+	}
+	if e.KeyBy == "" {
+		e.KeyBy = "session"
+	}
+	if e.KeyBy != "session" && e.KeyBy != "tenant" {
+		return fmt.Errorf("experiment key_by must be \"session\" or \"tenant\", got %q", e.KeyBy)
+	}
+	names := make(map[string]bool)
+	total := 0.0
+	for _, variant := range e.Variants {
+		if variant.Name == "" || names[variant.Name] {
+			return fmt.Errorf("experiment variants need unique names, got %q", variant.Name)
+		}
+		if variant.Weight < 0 {
+			return fmt.Errorf("variant %q has a negative weight", variant.Name)
+		}
+		names[variant.Name] = true
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return fmt.Errorf("experiment %q has no variant with a positive weight", e.Name)
+	}
+	return nil
+}
+
+// assign returns the variant for key. The same key always gets the same variant and keys
+// spread over variants in proportion to their weights.
+func (e *Experiment) assign(key string) *ExperimentVariant {
+	total := 0.0
+	for _, variant := range e.Variants {
+		total += variant.Weight
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(e.Name + ":" + key))
+	point := float64(hash.Sum64()%1_000_000) / 1_000_000 * total
+
+	for i := range e.Variants {
+		point -= e.Variants[i].Weight
+		if point < 0 {
+			return &e.Variants[i]
+		}
+	}
+	// Rounding: the point fell on the upper edge of the last weighted variant
+	for i := len(e.Variants) - 1; i >= 0; i-- {
+		if e.Variants[i].Weight > 0 {
+			return &e.Variants[i]
+		}
+	}
+	return nil
+}
+
+// experimentKey returns the session or tenant the request is assigned by
+func (e *Experiment) experimentKey(r *http.Request, sessionID string) string {
+	if e.KeyBy == "tenant" {
+		if tenant := strings.TrimSpace(r.Header.Get("X-Tenant-ID")); tenant != "" {
+			return tenant
+		}
+	}
+	return sessionID
+}
+
+// apply overrides the request's agent configuration with the variant's
+func (v *ExperimentVariant) apply(req *QueryRequest) {
+	if v.Provider != "" {
+		req.Provider = v.Provider
+		if req.LLMConfig != nil {
+			req.LLMConfig.Provider = v.Provider
+		}
+	}
+	if v.ModelID != "" {
+		req.ModelID = v.ModelID
+		if req.LLMConfig != nil {
+			req.LLMConfig.ModelID = v.ModelID
+		}
+	}
+	if v.Temperature > 0 {
+		req.Temperature = v.Temperature
+	}
+	if v.MaxTurns > 0 {
+		req.MaxTurns = v.MaxTurns
+	}
+}
+
+// assignExperimentVariant routes the request to an experiment variant, applies its configuration
+// and returns the tags its events carry (nil when no experiment is running)
+func (api *StreamingAPI) assignExperimentVariant(r *http.Request, req *QueryRequest, sessionID string) map[string]string {
+	if api.experiment == nil {
+		return nil
+	}
+	variant := api.experiment.assign(api.experiment.experimentKey(r, sessionID))
+	if variant == nil {
+		return nil
+	}
+	variant.apply(req)
+	log.Printf("[EXPERIMENT] Session %s assigned to variant %q of %q", sessionID, variant.Name, api.experiment.Name)
+	return map[string]string{experimentTagKey: api.experiment.Name, variantTagKey: variant.Name}
+}
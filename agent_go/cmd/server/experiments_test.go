@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/orchestrator"
+)
+
+func newTestExperiment(t *testing.T, keyBy string) *Experiment {
+	t.Helper()
+	experiment := &Experiment{
+		Name:  "model-swap",
+		KeyBy: keyBy,
+		Variants: []ExperimentVariant{
+			{Name: "control", Weight: 80},
+			{Name: "candidate", Weight: 20, Provider: "openai", ModelID: "gpt-4.1-mini", MaxTurns: 12},
+		},
+	}
+	if err := experiment.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	return experiment
+}
+
+func TestExperimentSplitMatchesWeights(t *testing.T) {
+	experiment := newTestExperiment(t, "")
+
+	const sessions = 20000
+	counts := make(map[string]int)
+	for i := 0; i < sessions; i++ {
+		counts[experiment.assign(fmt.Sprintf("session-%d", i)).Name]++
+	}
+
+	for _, variant := range experiment.Variants {
+		share := float64(counts[variant.Name]) / sessions
+		want := variant.Weight / 100
+		if math.Abs(share-want) > 0.02 {
+			t.Errorf("variant %q got %.3f of traffic, want %.2f±0.02", variant.Name, share, want)
+		}
+	}
+}
+
+func TestExperimentAssignmentIsStablePerKey(t *testing.T) {
+	experiment := newTestExperiment(t, "")
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("session-%d", i)
+		first := experiment.assign(key).Name
+		for j := 0; j < 5; j++ {
+			if got := experiment.assign(key).Name; got != first {
+				t.Fatalf("key %q moved from %q to %q", key, first, got)
+			}
+		}
+	}
+}
+
+func TestExperimentKeyByTenant(t *testing.T) {
+	experiment := newTestExperiment(t, "tenant")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/query", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	if got := experiment.experimentKey(req, "session-1"); got != "acme" {
+		t.Errorf("key = %q, want tenant %q", got, "acme")
+	}
+
+	// Requests without a tenant fall back to the session
+	if got := experiment.experimentKey(httptest.NewRequest(http.MethodPost, "/api/query", nil), "session-1"); got != "session-1" {
+		t.Errorf("key = %q, want session fallback", got)
+	}
+}
+
+func TestAssignExperimentVariantAppliesConfigAndTagsEvents(t *testing.T) {
+	experiment := newTestExperiment(t, "")
+	api := &StreamingAPI{experiment: experiment}
+
+	// Find a session landing on the candidate variant
+	sessionID := ""
+	for i := 0; i < 1000 && sessionID == ""; i++ {
+		if key := fmt.Sprintf("session-%d", i); experiment.assign(key).Name == "candidate" {
+			sessionID = key
+		}
+	}
+	if sessionID == "" {
+		t.Fatal("no session assigned to the candidate variant")
+	}
+
+	req := QueryRequest{Provider: "bedrock", ModelID: "claude", MaxTurns: 5, LLMConfig: &orchestrator.LLMConfig{Provider: "bedrock", ModelID: "claude"}}
+	tags := api.assignExperimentVariant(httptest.NewRequest(http.MethodPost, "/api/query", nil), &req, sessionID)
+
+	if req.Provider != "openai" || req.ModelID != "gpt-4.1-mini" || req.MaxTurns != 12 {
+		t.Errorf("variant not applied: provider=%q model=%q max_turns=%d", req.Provider, req.ModelID, req.MaxTurns)
+	}
+	if req.LLMConfig.Provider != "openai" || req.LLMConfig.ModelID != "gpt-4.1-mini" {
+		t.Errorf("variant not applied to llm config: %+v", req.LLMConfig)
+	}
+
+	ctx := unifiedevents.WithEventTags(context.Background(), tags)
+	event := unifiedevents.NewAgentStartEvent("react", req.ModelID, req.Provider)
+	unifiedevents.ApplyEventTags(ctx, event)
+
+	if event.Metadata[experimentTagKey] != "model-swap" || event.Metadata[variantTagKey] != "candidate" {
+		t.Errorf("event metadata = %v, want experiment and variant tags", event.Metadata)
+	}
+}
+
+func TestAssignExperimentVariantWithoutExperiment(t *testing.T) {
+	api := &StreamingAPI{}
+	req := QueryRequest{ModelID: "claude"}
+
+	if tags := api.assignExperimentVariant(httptest.NewRequest(http.MethodPost, "/api/query", nil), &req, "session-1"); tags != nil {
+		t.Errorf("tags = %v, want none", tags)
+	}
+	if req.ModelID != "claude" {
+		t.Errorf("request changed without an experiment: %q", req.ModelID)
+	}
+}
+
+func TestLoadExperimentRejectsInvalidConfigs(t *testing.T) {
+	configs := map[string]string{
+		"missing name":    `{"variants": [{"name": "a", "weight": 1}]}`,
+		"bad key_by":      `{"name": "x", "key_by": "user", "variants": [{"name": "a", "weight": 1}]}`,
+		"duplicate names": `{"name": "x", "variants": [{"name": "a", "weight": 1}, {"name": "a", "weight": 1}]}`,
+		"negative weight": `{"name": "x", "variants": [{"name": "a", "weight": -1}, {"name": "b", "weight": 2}]}`,
+		"no weight":       `{"name": "x", "variants": [{"name": "a", "weight": 0}]}`,
+		"not json":        `name: x`,
+	}
+	dir := t.TempDir()
+	for name, config := range configs {
+		path := filepath.Join(dir, "experiment.json")
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadExperiment(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/internal/llmtypes"
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+)
+
+const (
+	defaultMemoryCheckInterval = 10 * time.Second
+	defaultSessionIdleEvict    = 5 * time.Minute
+	// Pressure clears once usage drops below this percentage of the limit, so the server
+	// does not flap between accepting and rejecting runs around the threshold
+	defaultMemoryResumePercent = 90
+)
+
+// memoryPressureMonitor tracks heap usage against a configured maximum. Above the limit idle
+// session state is evicted to the database and new runs are rejected with 503 until usage
+// drops back below the resume threshold.
+type memoryPressureMonitor struct {
+	limitBytes    uint64
+	resumeBytes   uint64
+	checkInterval time.Duration
+	idleAfter     time.Duration // Sessions without activity for this long are evictable
+	readUsage     func() uint64 // nil reads the Go heap in use
+
+	mu            sync.RWMutex
+	underPressure bool
+}
+
+// memoryPressureMonitorFromEnv builds the monitor from MAX_MEMORY_MB; returns nil when unset (disabled)
+func memoryPressureMonitorFromEnv() *memoryPressureMonitor {
+	maxMB, err := strconv.Atoi(os.Getenv("MAX_MEMORY_MB"))
+	if err != nil || maxMB <= 0 {
+		return nil
+	}
+	monitor := newMemoryPressureMonitor(uint64(maxMB) << 20)
+	if envInterval := os.Getenv("MEMORY_CHECK_INTERVAL_SECONDS"); envInterval != "" {
+		if seconds, err := strconv.Atoi(envInterval); err == nil && seconds > 0 {
+			monitor.checkInterval = time.Duration(seconds) * time.Second
+		}
+	}
+	if envIdle := os.Getenv("SESSION_IDLE_EVICT_SECONDS"); envIdle != "" {
+		if seconds, err := strconv.Atoi(envIdle); err == nil && seconds >= 0 {
+			monitor.idleAfter = time.Duration(seconds) * time.Second
+		}
+	}
+	return monitor
+}
+
+func newMemoryPressureMonitor(limitBytes uint64) *memoryPressureMonitor {
+	return &memoryPressureMonitor{
+		limitBytes:    limitBytes,
+		resumeBytes:   limitBytes / 100 * defaultMemoryResumePercent,
+		checkInterval: defaultMemoryCheckInterval,
+		idleAfter:     defaultSessionIdleEvict,
+	}
+}
+
+func (m *memoryPressureMonitor) usage() uint64 {
+	if m.readUsage != nil {
+		return m.readUsage()
+	}
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// isUnderPressure reports whether new runs are currently being rejected
+func (m *memoryPressureMonitor) isUnderPressure() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.underPressure
+}
+
+// update records the latest usage and reports the pressure state and whether it changed
+func (m *memoryPressureMonitor) update(usage uint64) (bool, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previous := m.underPressure
+	if usage >= m.limitBytes {
+		m.underPressure = true
+	} else if usage < m.resumeBytes {
+		m.underPressure = false
+	}
+	return m.underPressure, m.underPressure != previous
+}
+
+// runMemoryPressureMonitor checks memory usage on the monitor's interval until ctx is done
+func (api *StreamingAPI) runMemoryPressureMonitor(ctx context.Context) {
+	ticker := time.NewTicker(api.memoryMonitor.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			api.checkMemoryPressure()
+		}
+	}
+}
+
+// checkMemoryPressure sheds idle session state while usage is above the limit and
+// notifies active observers when the server enters or leaves memory pressure
+func (api *StreamingAPI) checkMemoryPressure() {
+	monitor := api.memoryMonitor
+	if monitor == nil {
+		return
+	}
+	usage := monitor.usage()
+	underPressure, changed := monitor.update(usage)
+
+	evicted := 0
+	if underPressure {
+		evicted = api.evictIdleSessions(monitor.idleAfter)
+		// Give back what eviction freed before the next check measures again
+		runtime.GC()
+	}
+	if !changed {
+		return
+	}
+
+	if underPressure {
+		log.Printf("[MEMORY] Memory pressure: %d MB in use, limit %d MB; evicted %d idle sessions, rejecting new runs",
+			usage>>20, monitor.limitBytes>>20, evicted)
+	} else {
+		log.Printf("[MEMORY] Memory pressure cleared: %d MB in use, accepting new runs", usage>>20)
+	}
+	api.emitMemoryPressure(underPressure, usage, monitor.limitBytes, evicted)
+}
+
+// emitMemoryPressure publishes the pressure state change to every active observer
+func (api *StreamingAPI) emitMemoryPressure(underPressure bool, usage, limit uint64, evicted int) {
+	eventData := unifiedevents.NewMemoryPressureEvent(underPressure, usage, limit, evicted)
+	for _, observerID := range api.eventStore.GetActiveObservers() {
+		agentEvent := unifiedevents.NewAgentEvent(eventData)
+		agentEvent.SessionID = observerID
+
+		api.eventStore.AddEvent(observerID, events.Event{
+			ID:        fmt.Sprintf("memory_pressure_%s_%d", observerID, time.Now().UnixNano()),
+			Type:      string(unifiedevents.MemoryPressure),
+			Timestamp: time.Now(),
+			Data:      agentEvent,
+			SessionID: observerID,
+		})
+	}
+}
+
+// evictIdleSessions moves the conversation history of sessions that are not running and have been
+// idle for idleAfter to the database, and frees their buffered events (already persisted).
+// Returns how many sessions were evicted.
+func (api *StreamingAPI) evictIdleSessions(idleAfter time.Duration) int {
+	now := time.Now()
+	busy := make(map[string]bool)
+	var idleObservers []string
+
+	api.activeSessionsMux.RLock()
+	for sessionID, session := range api.activeSessions {
+		if session.Status == "running" || now.Sub(session.LastActivity) < idleAfter {
+			busy[sessionID] = true
+			continue
+		}
+		idleObservers = append(idleObservers, session.ObserverID)
+	}
+	api.activeSessionsMux.RUnlock()
+
+	api.conversationMux.RLock()
+	var candidates []string
+	for sessionID := range api.conversationHistory {
+		if !busy[sessionID] {
+			candidates = append(candidates, sessionID)
+		}
+	}
+	api.conversationMux.RUnlock()
+
+	evicted := 0
+	for _, sessionID := range candidates {
+		if api.evictConversationHistory(sessionID) {
+			evicted++
+		}
+	}
+	for _, observerID := range idleObservers {
+		api.eventStore.EvictBuffer(observerID)
+	}
+	return evicted
+}
+
+// evictConversationHistory saves a session's history to the database and drops it from memory
+func (api *StreamingAPI) evictConversationHistory(sessionID string) bool {
+	api.conversationMux.Lock()
+	defer api.conversationMux.Unlock()
+
+	history, exists := api.conversationHistory[sessionID]
+	if !exists {
+		return false
+	}
+	encoded, err := encodeConversationHistory(history)
+	if err != nil {
+		log.Printf("[MEMORY] Failed to encode conversation history for session %s: %v", sessionID, err)
+		return false
+	}
+	if err := api.chatDB.SaveConversationSnapshot(context.Background(), sessionID, encoded); err != nil {
+		log.Printf("[MEMORY] Failed to evict conversation history for session %s: %v", sessionID, err)
+		return false
+	}
+
+	delete(api.conversationHistory, sessionID)
+	api.evictedHistories[sessionID] = true
+	return true
+}
+
+// restoreConversationHistory loads a session's history back into memory if it was evicted
+func (api *StreamingAPI) restoreConversationHistory(ctx context.Context, sessionID string) {
+	api.conversationMux.Lock()
+	defer api.conversationMux.Unlock()
+
+	if !api.evictedHistories[sessionID] {
+		return
+	}
+	encoded, err := api.chatDB.GetConversationSnapshot(ctx, sessionID)
+	if err != nil {
+		log.Printf("[MEMORY] Failed to load evicted conversation history for session %s: %v", sessionID, err)
+		return
+	}
+	history, err := decodeConversationHistory(encoded)
+	if err != nil {
+		log.Printf("[MEMORY] Failed to decode evicted conversation history for session %s: %v", sessionID, err)
+		return
+	}
+
+	api.conversationHistory[sessionID] = history
+	delete(api.evictedHistories, sessionID)
+	if err := api.chatDB.DeleteConversationSnapshot(ctx, sessionID); err != nil {
+		log.Printf("[MEMORY] Failed to delete conversation snapshot for session %s: %v", sessionID, err)
+	}
+	log.Printf("[MEMORY] Restored %d evicted messages for session %s", len(history), sessionID)
+}
+
+// rejectUnderMemoryPressure wraps a handler that starts new runs so it answers 503 while under pressure
+func (api *StreamingAPI) rejectUnderMemoryPressure(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions && api.memoryMonitor.isUnderPressure() {
+			w.Header().Set("Retry-After", strconv.Itoa(int(api.memoryMonitor.checkInterval.Seconds())))
+			http.Error(w, "Server is under memory pressure, retry later", http.StatusServiceUnavailable)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func encodeConversationHistory(history []llmtypes.MessageContent) (string, error) {
+	data, err := llmtypes.MarshalMessages(history)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func decodeConversationHistory(encoded string) ([]llmtypes.MessageContent, error) {
+	return llmtypes.UnmarshalMessages([]byte(encoded))
+}
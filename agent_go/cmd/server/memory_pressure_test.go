@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/database"
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+)
+
+// snapshotDB keeps conversation snapshots in memory; other Database methods are not used
+type snapshotDB struct {
+	database.Database
+	snapshots map[string]string
+}
+
+func (db *snapshotDB) SaveConversationSnapshot(ctx context.Context, sessionID string, history string) error {
+	db.snapshots[sessionID] = history
+	return nil
+}
+
+func (db *snapshotDB) GetConversationSnapshot(ctx context.Context, sessionID string) (string, error) {
+	return db.snapshots[sessionID], nil
+}
+
+func (db *snapshotDB) DeleteConversationSnapshot(ctx context.Context, sessionID string) error {
+	delete(db.snapshots, sessionID)
+	return nil
+}
+
+func TestMemoryPressureEvictsIdleSessionsAndRejectsRuns(t *testing.T) {
+	eventStore := events.NewEventStore(100)
+	defer eventStore.Stop()
+
+	var usage atomic.Uint64
+	monitor := newMemoryPressureMonitor(100 << 20)
+	monitor.readUsage = usage.Load
+	monitor.idleAfter = time.Minute
+
+	db := &snapshotDB{snapshots: make(map[string]string)}
+	api := &StreamingAPI{
+		chatDB:              db,
+		eventStore:          eventStore,
+		conversationHistory: make(map[string][]llmtypes.MessageContent),
+		evictedHistories:    make(map[string]bool),
+		activeSessions:      make(map[string]*ActiveSessionInfo),
+		memoryMonitor:       monitor,
+	}
+
+	idleHistory := []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "list the buckets"}}},
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{llmtypes.ToolCall{ID: "call-1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "list_buckets", Arguments: "{}"}}}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: "call-1", Name: "list_buckets", Content: "logs, backups"}}},
+	}
+	api.conversationHistory["idle-session"] = idleHistory
+	api.conversationHistory["running-session"] = idleHistory[:1]
+	api.activeSessions["idle-session"] = &ActiveSessionInfo{SessionID: "idle-session", ObserverID: "idle-observer", Status: "completed", LastActivity: time.Now().Add(-time.Hour)}
+	api.activeSessions["running-session"] = &ActiveSessionInfo{SessionID: "running-session", ObserverID: "running-observer", Status: "running", LastActivity: time.Now().Add(-time.Hour)}
+	eventStore.AddEvent("idle-observer", events.Event{ID: "old-event", Type: "tool_call_end"})
+	eventStore.AddEvent("running-observer", events.Event{ID: "live-event", Type: "tool_call_start"})
+
+	var handled int
+	handler := httptest.NewServer(api.rejectUnderMemoryPressure(func(w http.ResponseWriter, r *http.Request) {
+		handled++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer handler.Close()
+	postQuery := func() int {
+		resp, err := http.Post(handler.URL, "application/json", nil)
+		if err != nil {
+			t.Fatalf("query request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Above the limit: idle state is shed and new runs are rejected
+	usage.Store(120 << 20)
+	api.checkMemoryPressure()
+
+	if _, exists := api.conversationHistory["idle-session"]; exists {
+		t.Fatalf("expected idle session history to be evicted from memory")
+	}
+	if db.snapshots["idle-session"] == "" {
+		t.Fatalf("expected idle session history to be saved to the database")
+	}
+	if _, exists := api.conversationHistory["running-session"]; !exists {
+		t.Fatalf("expected running session history to stay in memory")
+	}
+	if buffered, trimmed := eventStore.Snapshot("idle-observer"); trimmed != 1 || buffered[0].ID == "old-event" {
+		t.Fatalf("expected idle observer's buffered events to be freed, got %d trimmed", trimmed)
+	}
+	if status := postQuery(); status != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 under memory pressure, got %d", status)
+	}
+
+	buffered, _ := eventStore.Snapshot("running-observer")
+	last := buffered[len(buffered)-1]
+	if last.Type != string(unifiedevents.MemoryPressure) {
+		t.Fatalf("expected a memory pressure event for the running session, got %s", last.Type)
+	}
+	if pressure := last.Data.Data.(*unifiedevents.MemoryPressureEvent); !pressure.UnderPressure || pressure.EvictedSessions != 1 {
+		t.Fatalf("unexpected pressure event: %+v", pressure)
+	}
+
+	// Below the limit but above the resume threshold: still rejecting
+	usage.Store(95 << 20)
+	api.checkMemoryPressure()
+	if status := postQuery(); status != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 until usage drops below the resume threshold, got %d", status)
+	}
+
+	// Pressure subsides: runs are accepted and evicted history comes back on the next query
+	usage.Store(50 << 20)
+	api.checkMemoryPressure()
+	if status := postQuery(); status != http.StatusOK || handled != 1 {
+		t.Fatalf("expected query accepted after pressure cleared, got %d (handled %d)", status, handled)
+	}
+
+	api.restoreConversationHistory(context.Background(), "idle-session")
+	restored := api.conversationHistory["idle-session"]
+	if len(restored) != len(idleHistory) {
+		t.Fatalf("expected %d restored messages, got %d", len(idleHistory), len(restored))
+	}
+	if call, ok := restored[1].Parts[0].(llmtypes.ToolCall); !ok || call.FunctionCall.Name != "list_buckets" {
+		t.Fatalf("expected restored tool call, got %+v", restored[1].Parts[0])
+	}
+	if response, ok := restored[2].Parts[0].(llmtypes.ToolCallResponse); !ok || response.Content != "logs, backups" {
+		t.Fatalf("expected restored tool response, got %+v", restored[2].Parts[0])
+	}
+	if _, exists := db.snapshots["idle-session"]; exists {
+		t.Fatalf("expected snapshot removed after restore")
+	}
+}
@@ -0,0 +1,317 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+)
+
+// defaultQueryDurationBuckets are the upper bounds (seconds) of the query duration histogram
+var defaultQueryDurationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800}
+
+// toolCallDurationBuckets and llmGenerationDurationBuckets are the upper bounds (seconds) of the
+// histograms fed from tool call and LLM generation events
+var (
+	toolCallDurationBuckets      = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300}
+	llmGenerationDurationBuckets = []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120}
+)
+
+const (
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	prometheusContentType  = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// metricsExemplar links a histogram bucket to the trace of a request it counted
+type metricsExemplar struct {
+	traceID   string
+	value     float64
+	timestamp time.Time
+}
+
+// histogramSeries is one labelled series of a histogram
+type histogramSeries struct {
+	counts    []uint64 // Per bucket (not cumulative), plus +Inf as the last entry
+	exemplars []*metricsExemplar
+	sum       float64
+	count     uint64
+}
+
+func newHistogramSeries(buckets []float64) *histogramSeries {
+	return &histogramSeries{
+		counts:    make([]uint64, len(buckets)+1),
+		exemplars: make([]*metricsExemplar, len(buckets)+1),
+	}
+}
+
+// observe counts a value; traceID, when set, becomes the exemplar of its bucket
+func (s *histogramSeries) observe(buckets []float64, seconds float64, traceID string) {
+	bucket := sort.SearchFloat64s(buckets, seconds) // first bound >= seconds, len(buckets) for +Inf
+	s.counts[bucket]++
+	s.sum += seconds
+	s.count++
+	if traceID != "" {
+		s.exemplars[bucket] = &metricsExemplar{traceID: traceID, value: seconds, timestamp: time.Now()}
+	}
+}
+
+// serverMetrics records query durations and the agent events of every session and exposes them at
+// /metrics. In the OpenMetrics format each query duration bucket carries an exemplar with the trace
+// ID of the latest query it counted, so a slow-request alert can jump straight to the trace.
+type serverMetrics struct {
+	mu      sync.Mutex
+	buckets []float64
+	series  map[string]*histogramSeries // agent mode -> query duration histogram
+
+	// Fed from the event stream (see observeEvent)
+	toolDurations  map[string]*histogramSeries // tool -> call duration histogram
+	toolErrors     map[string]uint64           // tool -> failed calls
+	llmDurations   *histogramSeries
+	tokens         map[string]uint64 // "prompt" or "completion" -> tokens
+	throttles      map[string]uint64 // provider -> throttling events
+	fallbacks      map[string]uint64 // provider -> fallback model uses
+	activeSessions int
+}
+
+// metricsFromConfig returns the server metrics when enabled by the --metrics flag or
+// METRICS_ENABLED=true; METRICS_QUERY_DURATION_BUCKETS overrides the query histogram buckets with
+// comma-separated upper bounds in seconds
+func metricsFromConfig(enabled bool) *serverMetrics {
+	if !enabled && os.Getenv("METRICS_ENABLED") != "true" {
+		return nil
+	}
+	buckets := defaultQueryDurationBuckets
+	if env := os.Getenv("METRICS_QUERY_DURATION_BUCKETS"); env != "" {
+		parsed, err := parseMetricsBuckets(env)
+		if err != nil {
+			log.Printf("[METRICS] Ignoring METRICS_QUERY_DURATION_BUCKETS: %v", err)
+		} else {
+			buckets = parsed
+		}
+	}
+	log.Printf("[METRICS] Exposing agent metrics at /metrics (query duration buckets %v)", buckets)
+	return newServerMetrics(buckets)
+}
+
+func newServerMetrics(buckets []float64) *serverMetrics {
+	return &serverMetrics{
+		buckets:       buckets,
+		series:        make(map[string]*histogramSeries),
+		toolDurations: make(map[string]*histogramSeries),
+		toolErrors:    make(map[string]uint64),
+		llmDurations:  newHistogramSeries(llmGenerationDurationBuckets),
+		tokens:        make(map[string]uint64),
+		throttles:     make(map[string]uint64),
+		fallbacks:     make(map[string]uint64),
+	}
+}
+
+// parseMetricsBuckets parses increasing, positive bucket upper bounds
+func parseMetricsBuckets(value string) ([]float64, error) {
+	var buckets []float64
+	for _, field := range strings.Split(value, ",") {
+		bound, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || bound <= 0 {
+			return nil, fmt.Errorf("invalid bucket %q", field)
+		}
+		if len(buckets) > 0 && bound <= buckets[len(buckets)-1] {
+			return nil, fmt.Errorf("buckets must be increasing, got %v after %v", bound, buckets[len(buckets)-1])
+		}
+		buckets = append(buckets, bound)
+	}
+	return buckets, nil
+}
+
+// observeQuery records a query's duration; traceID becomes the exemplar of its bucket
+func (m *serverMetrics) observeQuery(mode string, duration time.Duration, traceID string) {
+	if m == nil {
+		return
+	}
+	if mode == "" {
+		mode = "react"
+	}
+	seconds := duration.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	series, exists := m.series[mode]
+	if !exists {
+		series = newHistogramSeries(m.buckets)
+		m.series[mode] = series
+	}
+	series.observe(m.buckets, seconds, traceID)
+}
+
+// sessionStarted and sessionEnded track the sessions with a query in progress
+func (m *serverMetrics) sessionStarted() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activeSessions++
+}
+
+func (m *serverMetrics) sessionEnded() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activeSessions--
+}
+
+// observeEvent feeds the tool, LLM, throttling and fallback metrics; it is an event store hook
+// (see EventStore.SetEventHook)
+func (m *serverMetrics) observeEvent(observerID string, event events.Event) {
+	if m == nil || event.Data == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch data := event.Data.Data.(type) {
+	case *unifiedevents.ToolCallEndEvent:
+		series, exists := m.toolDurations[data.ToolName]
+		if !exists {
+			series = newHistogramSeries(toolCallDurationBuckets)
+			m.toolDurations[data.ToolName] = series
+		}
+		series.observe(toolCallDurationBuckets, data.Duration.Seconds(), "")
+	case *unifiedevents.ToolCallErrorEvent:
+		m.toolErrors[data.ToolName]++
+	case *unifiedevents.LLMGenerationEndEvent:
+		if data.TurnSummary {
+			// Repeats the usage of the turn's LLM call, already observed
+			break
+		}
+		m.llmDurations.observe(llmGenerationDurationBuckets, data.Duration.Seconds(), "")
+		m.tokens["prompt"] += uint64(data.UsageMetrics.PromptTokens)
+		m.tokens["completion"] += uint64(data.UsageMetrics.CompletionTokens)
+	case *unifiedevents.ThrottlingDetectedEvent:
+		m.throttles[data.Provider]++
+	case *unifiedevents.FallbackModelUsedEvent:
+		m.fallbacks[data.Provider]++
+	}
+}
+
+// write renders the metrics in the OpenMetrics format (with exemplars) or the Prometheus text format
+func (m *serverMetrics) write(b *strings.Builder, openMetrics bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeHistogram(b, "agent_query_duration_seconds", "Duration of agent queries from start to completion.", "mode", m.buckets, m.series, openMetrics)
+	writeHistogram(b, "agent_tool_call_duration_seconds", "Duration of tool calls.", "tool", toolCallDurationBuckets, m.toolDurations, openMetrics)
+	writeCounter(b, "agent_tool_call_errors", "Tool calls that failed, timed out or were blocked.", "tool", m.toolErrors, openMetrics)
+	writeHistogram(b, "agent_llm_generation_duration_seconds", "Duration of LLM generations.", "", llmGenerationDurationBuckets, map[string]*histogramSeries{"": m.llmDurations}, openMetrics)
+	writeCounter(b, "agent_llm_tokens", "Tokens used by LLM generations.", "type", m.tokens, openMetrics)
+	writeCounter(b, "agent_llm_throttling_events", "LLM calls throttled by the provider.", "provider", m.throttles, openMetrics)
+	writeCounter(b, "agent_llm_fallbacks", "LLM calls answered by a fallback model.", "provider", m.fallbacks, openMetrics)
+
+	b.WriteString("# HELP agent_active_sessions Sessions with a query in progress.\n")
+	b.WriteString("# TYPE agent_active_sessions gauge\n")
+	fmt.Fprintf(b, "agent_active_sessions %d\n", m.activeSessions)
+
+	if openMetrics {
+		b.WriteString("# EOF\n")
+	}
+}
+
+// writeHistogram renders a histogram with one series per label value; an empty label renders the
+// series unlabelled. Exemplars are only written in the OpenMetrics format.
+func writeHistogram(b *strings.Builder, name, help, label string, buckets []float64, series map[string]*histogramSeries, openMetrics bool) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s histogram\n", name)
+	if openMetrics {
+		fmt.Fprintf(b, "# UNIT %s seconds\n", name)
+	}
+
+	for _, value := range sortedMetricLabels(series) {
+		s := series[value]
+		labels := ""
+		if label != "" {
+			labels = fmt.Sprintf("%s=%q,", label, value)
+		}
+		var cumulative uint64
+		for i, count := range s.counts {
+			cumulative += count
+			le := "+Inf"
+			if i < len(buckets) {
+				le = formatMetricFloat(buckets[i])
+			}
+			fmt.Fprintf(b, "%s_bucket{%sle=%q} %d", name, labels, le, cumulative)
+			if exemplar := s.exemplars[i]; openMetrics && exemplar != nil {
+				fmt.Fprintf(b, " # {trace_id=%q} %s %s", exemplar.traceID, formatMetricFloat(exemplar.value), formatMetricTimestamp(exemplar.timestamp))
+			}
+			b.WriteString("\n")
+		}
+		labels = strings.TrimSuffix(labels, ",")
+		if labels != "" {
+			labels = "{" + labels + "}"
+		}
+		fmt.Fprintf(b, "%s_sum%s %s\n", name, labels, formatMetricFloat(s.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", name, labels, s.count)
+	}
+}
+
+// writeCounter renders a counter with one sample per label value. OpenMetrics names the metric
+// family without the _total suffix its samples carry.
+func writeCounter(b *strings.Builder, name, help, label string, values map[string]uint64, openMetrics bool) {
+	family := name + "_total"
+	if openMetrics {
+		family = name
+	}
+	fmt.Fprintf(b, "# HELP %s %s\n", family, help)
+	fmt.Fprintf(b, "# TYPE %s counter\n", family)
+	for _, value := range sortedMetricLabels(values) {
+		fmt.Fprintf(b, "%s_total{%s=%q} %d\n", name, label, value, values[value])
+	}
+}
+
+func sortedMetricLabels[V any](series map[string]V) []string {
+	labels := make([]string, 0, len(series))
+	for label := range series {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+func formatMetricFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func formatMetricTimestamp(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', 3, 64)
+}
+
+// acceptsOpenMetrics reports whether the scraper negotiated the OpenMetrics format
+func acceptsOpenMetrics(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+}
+
+// handleMetrics serves the metrics in the format the scraper accepts; exemplars are only part of
+// the OpenMetrics format
+func (api *StreamingAPI) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if api.metrics == nil {
+		http.Error(w, "metrics are disabled (start the server with --metrics or set METRICS_ENABLED=true)", http.StatusNotFound)
+		return
+	}
+	openMetrics := acceptsOpenMetrics(r)
+	var b strings.Builder
+	api.metrics.write(&b, openMetrics)
+	if openMetrics {
+		w.Header().Set("Content-Type", openMetricsContentType)
+	} else {
+		w.Header().Set("Content-Type", prometheusContentType)
+	}
+	w.Write([]byte(b.String()))
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/internal/llmtypes"
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+	"mcp-agent/agent_go/pkg/mcpagent"
+)
+
+func scrapeMetrics(t *testing.T, api *StreamingAPI, accept string) (string, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	api.handleMetrics(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	return rec.Header().Get("Content-Type"), rec.Body.String()
+}
+
+func TestMetricsOpenMetricsExemplars(t *testing.T) {
+	api := &StreamingAPI{metrics: newServerMetrics([]float64{1, 10})}
+	api.metrics.observeQuery("simple", 500*time.Millisecond, "trace-fast-1")
+	api.metrics.observeQuery("simple", 700*time.Millisecond, "trace-fast-2")
+	api.metrics.observeQuery("simple", 4*time.Second, "trace-slow")
+	api.metrics.observeQuery("simple", 30*time.Second, "") // No trace: counted without an exemplar
+
+	contentType, body := scrapeMetrics(t, api, "application/openmetrics-text; version=1.0.0,text/plain;q=0.5")
+	if contentType != openMetricsContentType {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	if !strings.HasSuffix(body, "# EOF\n") {
+		t.Errorf("expected the exposition to end with # EOF:\n%s", body)
+	}
+
+	exemplar := regexp.MustCompile(`^agent_query_duration_seconds_bucket\{mode="simple",le="([^"]+)"\} (\d+)(?: # \{trace_id="([^"]+)"\} ([0-9.e+-]+) (\d+\.\d{3}))?$`)
+	want := map[string]struct{ count, traceID string }{
+		"1":    {"2", "trace-fast-2"}, // The latest query in the bucket
+		"10":   {"3", "trace-slow"},   // Cumulative count
+		"+Inf": {"4", ""},
+	}
+	found := 0
+	for _, line := range strings.Split(body, "\n") {
+		if !strings.HasPrefix(line, "agent_query_duration_seconds_bucket") {
+			continue
+		}
+		match := exemplar.FindStringSubmatch(line)
+		if match == nil {
+			t.Fatalf("malformed bucket line %q", line)
+		}
+		expected, ok := want[match[1]]
+		if !ok {
+			t.Fatalf("unexpected bucket %q", match[1])
+		}
+		found++
+		if match[2] != expected.count || match[3] != expected.traceID {
+			t.Errorf("bucket le=%s: expected count %s and trace %q, got %q", match[1], expected.count, expected.traceID, line)
+		}
+	}
+	if found != len(want) {
+		t.Errorf("expected %d bucket lines, got %d:\n%s", len(want), found, body)
+	}
+	if !strings.Contains(body, `agent_query_duration_seconds_count{mode="simple"} 4`) {
+		t.Errorf("expected the total count of 4 queries:\n%s", body)
+	}
+}
+
+func TestMetricsPrometheusFormatAndDisabled(t *testing.T) {
+	api := &StreamingAPI{metrics: newServerMetrics(defaultQueryDurationBuckets)}
+	api.metrics.observeQuery("", 2*time.Second, "trace-1")
+
+	contentType, body := scrapeMetrics(t, api, "")
+	if contentType != prometheusContentType {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	if strings.Contains(body, "trace_id") || strings.Contains(body, "# EOF") {
+		t.Errorf("expected plain Prometheus text without exemplars:\n%s", body)
+	}
+	if !strings.Contains(body, `agent_query_duration_seconds_bucket{mode="react",le="5"} 1`) {
+		t.Errorf("expected the query counted under the default mode:\n%s", body)
+	}
+
+	disabled := &StreamingAPI{}
+	disabled.metrics.observeQuery("simple", time.Second, "trace-1") // nil-safe
+	rec := httptest.NewRecorder()
+	disabled.handleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 when metrics are disabled, got %d", rec.Code)
+	}
+}
+
+func TestParseMetricsBuckets(t *testing.T) {
+	if buckets, err := parseMetricsBuckets("0.5, 2,10"); err != nil || len(buckets) != 3 || buckets[0] != 0.5 {
+		t.Errorf("unexpected buckets %v (%v)", buckets, err)
+	}
+	for _, invalid := range []string{"5,1", "1,x", "0,1"} {
+		if _, err := parseMetricsBuckets(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+// toolThenAnswerLLM calls get_weather once, then answers
+type toolThenAnswerLLM struct {
+	calls int
+}
+
+func (l *toolThenAnswerLLM) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	l.calls++
+	input, output, total := 120, 30, 150
+	usage := &llmtypes.GenerationInfo{InputTokens: &input, OutputTokens: &output, TotalTokens: &total}
+	if l.calls == 1 {
+		return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{
+			ToolCalls:      []llmtypes.ToolCall{{ID: "call-1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city": "Paris"}`}}},
+			GenerationInfo: usage,
+		}}}, nil
+	}
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: "It is sunny in Paris.", GenerationInfo: usage}}}, nil
+}
+
+func TestMetricsFedFromQueryEvents(t *testing.T) {
+	api := &StreamingAPI{metrics: newServerMetrics(defaultQueryDurationBuckets)}
+	store := events.NewEventStore(1000)
+	t.Cleanup(store.Stop)
+	store.SetEventHook(api.metrics.observeEvent)
+
+	testLogger := logger.CreateDiscardLogger("error")
+	agent := &mcpagent.Agent{
+		LLM:       &toolThenAnswerLLM{},
+		ModelID:   "test-model",
+		TraceID:   "trace-1",
+		Logger:    testLogger,
+		AgentMode: mcpagent.SimpleAgent,
+		MaxTurns:  3,
+	}
+	agent.RegisterCustomTool("get_weather", "Get the weather", map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return "sunny", nil
+	})
+	agent.AddEventListener(events.NewEventObserverWithLogger(store, "observer-1", "session-1", testLogger))
+
+	api.metrics.sessionStarted()
+	_, body := scrapeMetrics(t, api, "")
+	if !strings.Contains(body, "agent_active_sessions 1\n") {
+		t.Errorf("expected one active session during the query:\n%s", body)
+	}
+	if _, err := agent.Ask(context.Background(), "What is the weather in Paris?"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	api.metrics.sessionEnded()
+	store.AddEvent("observer-1", events.Event{Type: string(unifiedevents.ThrottlingDetected), Timestamp: time.Now(), Data: &unifiedevents.AgentEvent{
+		Type: unifiedevents.ThrottlingDetected,
+		Data: &unifiedevents.ThrottlingDetectedEvent{Provider: "bedrock", ModelID: "test-model", Attempt: 1, MaxAttempts: 3},
+	}})
+
+	_, body = scrapeMetrics(t, api, "")
+	for _, expected := range []string{
+		"# TYPE agent_tool_call_duration_seconds histogram",
+		`agent_tool_call_duration_seconds_count{tool="get_weather"} 1`,
+		"# TYPE agent_llm_generation_duration_seconds histogram",
+		"agent_llm_generation_duration_seconds_count 2",
+		"# TYPE agent_llm_tokens_total counter",
+		`agent_llm_tokens_total{type="prompt"} 240`,
+		`agent_llm_throttling_events_total{provider="bedrock"} 1`,
+		"# TYPE agent_llm_fallbacks_total counter",
+		"agent_active_sessions 0",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in the scrape:\n%s", expected, body)
+		}
+	}
+
+	_, body = scrapeMetrics(t, api, "application/openmetrics-text")
+	if !strings.Contains(body, "# TYPE agent_llm_tokens counter") || !strings.Contains(body, `agent_llm_tokens_total{type="completion"}`) {
+		t.Errorf("expected OpenMetrics counter families without the _total suffix:\n%s", body)
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/internal/llmtypes"
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+)
+
+// Query classes returned by the mode classifier
+const (
+	QueryClassChat    = "chat"
+	QueryClassToolUse = "tool-use"
+	QueryClassComplex = "complex"
+	defaultQueryClass = QueryClassToolUse // Used when classification fails
+)
+
+// queryClassModes maps each query class to the agent mode that handles it best
+var queryClassModes = map[string]string{
+	QueryClassChat:    "simple",
+	QueryClassToolUse: "react",
+	QueryClassComplex: "orchestrator",
+}
+
+// ModeClassification is the result of classifying a query for agent_mode="auto"
+type ModeClassification struct {
+	QueryClass   string
+	SelectedMode string
+	Reasoning    string
+	Fallback     bool
+	Error        string
+	Duration     time.Duration
+}
+
+const modeClassifierPrompt = `Classify the user's query so it can be routed to the right agent.
+
+Classes:
+- "chat": conversation, general knowledge or writing that needs no tools
+- "tool-use": needs a few tool calls (search, read files, query an API) to answer
+- "complex": a multi-step objective that needs planning, several phases or many tools
+
+Respond with JSON only: {"class": "chat" | "tool-use" | "complex", "reasoning": "<one short sentence>"}
+
+Query:
+%s`
+
+// classifyAgentMode runs a lightweight LLM call to choose the agent mode for a query.
+// It never fails: on any error it falls back to the tool-use (react) mode and reports why.
+func classifyAgentMode(ctx context.Context, model llmtypes.Model, query string) ModeClassification {
+	startTime := time.Now()
+	fallback := func(err error) ModeClassification {
+		return ModeClassification{
+			QueryClass:   defaultQueryClass,
+			SelectedMode: queryClassModes[defaultQueryClass],
+			Fallback:     true,
+			Error:        err.Error(),
+			Duration:     time.Since(startTime),
+		}
+	}
+
+	if model == nil {
+		return fallback(fmt.Errorf("no classifier LLM configured"))
+	}
+
+	classifyCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	messages := []llmtypes.MessageContent{
+		{
+			Role:  llmtypes.ChatMessageTypeHuman,
+			Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: fmt.Sprintf(modeClassifierPrompt, query)}},
+		},
+	}
+	resp, err := model.GenerateContent(classifyCtx, messages, llmtypes.WithTemperature(0), llmtypes.WithMaxTokens(200))
+	if err != nil {
+		return fallback(fmt.Errorf("classifier call failed: %w", err))
+	}
+	if resp == nil || len(resp.Choices) == 0 {
+		return fallback(fmt.Errorf("classifier returned no choices"))
+	}
+
+	queryClass, reasoning := parseModeClassification(resp.Choices[0].Content)
+	if queryClass == "" {
+		return fallback(fmt.Errorf("could not parse classifier response: %q", resp.Choices[0].Content))
+	}
+
+	return ModeClassification{
+		QueryClass:   queryClass,
+		SelectedMode: queryClassModes[queryClass],
+		Reasoning:    reasoning,
+		Duration:     time.Since(startTime),
+	}
+}
+
+// parseModeClassification extracts the query class from the classifier output,
+// accepting either the requested JSON or a bare class name
+func parseModeClassification(content string) (string, string) {
+	content = strings.TrimSpace(content)
+
+	if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+		var parsed struct {
+			Class     string `json:"class"`
+			Reasoning string `json:"reasoning"`
+		}
+		if err := json.Unmarshal([]byte(content[start:end+1]), &parsed); err == nil {
+			class := strings.ToLower(strings.TrimSpace(parsed.Class))
+			if _, ok := queryClassModes[class]; ok {
+				return class, parsed.Reasoning
+			}
+		}
+	}
+
+	lower := strings.ToLower(content)
+	for _, class := range []string{QueryClassComplex, QueryClassToolUse, QueryClassChat} {
+		if strings.Contains(lower, class) {
+			return class, ""
+		}
+	}
+	return "", ""
+}
+
+// emitAgentModeSelected publishes the auto mode decision to the observer
+func (api *StreamingAPI) emitAgentModeSelected(observerID, queryID string, classification ModeClassification) {
+	eventData := unifiedevents.NewAgentModeSelectedEvent(
+		classification.SelectedMode,
+		classification.QueryClass,
+		classification.Reasoning,
+		classification.Fallback,
+		classification.Error,
+		classification.Duration,
+	)
+	agentEvent := unifiedevents.NewAgentEvent(eventData)
+	agentEvent.SessionID = observerID
+
+	api.eventStore.AddEvent(observerID, events.Event{
+		ID:        fmt.Sprintf("agent_mode_selected_%s_%d", queryID, time.Now().UnixNano()),
+		Type:      string(unifiedevents.AgentModeSelected),
+		Timestamp: time.Now(),
+		Data:      agentEvent,
+		SessionID: observerID,
+	})
+}
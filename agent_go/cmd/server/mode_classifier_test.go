@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// stubClassifierLLM returns a canned classifier response chosen by a keyword in the prompt
+type stubClassifierLLM struct {
+	responses map[string]string
+	err       error
+}
+
+func (s *stubClassifierLLM) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	prompt := messages[0].Parts[0].(llmtypes.TextContent).Text
+	for keyword, response := range s.responses {
+		if strings.Contains(prompt, keyword) {
+			return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: response}}}, nil
+		}
+	}
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: "unknown"}}}, nil
+}
+
+func TestClassifyAgentModeRoutesRepresentativeQueries(t *testing.T) {
+	model := &stubClassifierLLM{responses: map[string]string{
+		"joke":      `{"class": "chat", "reasoning": "No tools needed"}`,
+		"weather":   "```json\n{\"class\": \"tool-use\", \"reasoning\": \"Needs a weather lookup\"}\n```",
+		"migration": `complex`,
+	}}
+
+	tests := []struct {
+		query        string
+		expectedMode string
+	}{
+		{"Tell me a joke about databases", "simple"},
+		{"What's the weather in Berlin right now?", "react"},
+		{"Plan and execute the migration of all our services to Kubernetes", "orchestrator"},
+	}
+
+	for _, tt := range tests {
+		classification := classifyAgentMode(context.Background(), model, tt.query)
+		if classification.SelectedMode != tt.expectedMode {
+			t.Errorf("query %q routed to %q, want %q", tt.query, classification.SelectedMode, tt.expectedMode)
+		}
+		if classification.Fallback {
+			t.Errorf("query %q unexpectedly used fallback: %s", tt.query, classification.Error)
+		}
+	}
+}
+
+func TestClassifyAgentModeFallsBackOnError(t *testing.T) {
+	classification := classifyAgentMode(context.Background(), &stubClassifierLLM{err: errors.New("provider down")}, "anything")
+	if !classification.Fallback || classification.SelectedMode != "react" {
+		t.Errorf("expected fallback to react, got %+v", classification)
+	}
+
+	classification = classifyAgentMode(context.Background(), &stubClassifierLLM{}, "gibberish")
+	if !classification.Fallback || classification.SelectedMode != "react" {
+		t.Errorf("expected fallback to react for unparseable response, got %+v", classification)
+	}
+}
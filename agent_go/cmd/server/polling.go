@@ -1,13 +1,16 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"mcp-agent/agent_go/internal/events"
+	unifiedevents "mcp-agent/agent_go/pkg/events"
 
 	"github.com/gorilla/mux"
 )
@@ -30,10 +33,24 @@ type RegisterObserverResponse struct {
 type GetEventsResponse struct {
 	Events         []events.Event `json:"events"`
 	LastEventIndex int            `json:"last_event_index"`
+	NextCursor     int            `json:"next_cursor"` // Pass as since on the next poll; -1 while there are no events
 	HasMore        bool           `json:"has_more"`
 	ObserverID     string         `json:"observer_id"`
 }
 
+// maxEventRange bounds how many events a single range request returns
+const maxEventRange = 1000
+
+// GetEventRangeResponse represents the response for an event range request
+type GetEventRangeResponse struct {
+	Events         []events.Event `json:"events"`
+	From           int            `json:"from"`
+	To             int            `json:"to"` // Clipped to the last event index
+	LastEventIndex int            `json:"last_event_index"`
+	Source         string         `json:"source"` // "memory", "database" or "mixed"
+	ObserverID     string         `json:"observer_id"`
+}
+
 // ObserverStatusResponse represents the response for observer status
 type ObserverStatusResponse struct {
 	ObserverID   string    `json:"observer_id"`
@@ -88,20 +105,34 @@ func (api *StreamingAPI) handleGetEvents(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Get since parameter (optional)
+	// A from/to range re-fetches events a client knows it missed, without moving its cursor
+	if query := r.URL.Query(); query.Has("from") || query.Has("to") {
+		api.handleGetEventRange(w, r, observerID)
+		return
+	}
+
+	// Get since cursor (optional): only events after it are returned; without it the whole buffer is
 	sinceStr := r.URL.Query().Get("since")
-	sinceIndex := 0
+	sinceIndex := -1
 	if sinceStr != "" {
 		if since, err := strconv.Atoi(sinceStr); err == nil {
 			sinceIndex = since
 		}
 	}
 
+	// Optional filters: types=tool_call_start,tool_call_end and min_level/max_level (hierarchy)
+	filter, err := parseEventFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Update observer activity
 	api.observerManager.UpdateObserverActivity(observerID)
 
 	// Get events for observer
-	events, totalEvents, exists := api.eventStore.GetEvents(observerID, sinceIndex)
+	page, exists := api.eventStore.GetEventsPage(observerID, sinceIndex, filter)
+	events := page.Events
 
 	if !exists {
 		http.Error(w, "Observer not found", http.StatusNotFound)
@@ -114,7 +145,8 @@ func (api *StreamingAPI) handleGetEvents(w http.ResponseWriter, r *http.Request)
 
 	response := GetEventsResponse{
 		Events:         events,
-		LastEventIndex: totalEvents,
+		LastEventIndex: page.LastEventIndex,
+		NextCursor:     page.NextCursor,
 		HasMore:        len(events) > 0,
 		ObserverID:     observerID,
 	}
@@ -125,6 +157,133 @@ func (api *StreamingAPI) handleGetEvents(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// parseEventFilter reads the types, min_level and max_level query parameters
+func parseEventFilter(r *http.Request) (events.EventFilter, error) {
+	query := r.URL.Query()
+	var filter events.EventFilter
+	for _, eventType := range strings.Split(query.Get("types"), ",") {
+		if eventType = strings.TrimSpace(eventType); eventType != "" {
+			filter.Types = append(filter.Types, eventType)
+		}
+	}
+	for name, bound := range map[string]**int{"min_level": &filter.MinLevel, "max_level": &filter.MaxLevel} {
+		if value := query.Get(name); value != "" {
+			level, err := strconv.Atoi(value)
+			if err != nil {
+				return events.EventFilter{}, fmt.Errorf("%s must be an integer hierarchy level", name)
+			}
+			*bound = &level
+		}
+	}
+	if filter.MinLevel != nil && filter.MaxLevel != nil && *filter.MinLevel > *filter.MaxLevel {
+		return events.EventFilter{}, fmt.Errorf("min_level must not exceed max_level")
+	}
+	return filter, nil
+}
+
+// handleGetEventRange returns exactly the observer's events with indices from..to (inclusive).
+// Events evicted from memory are read from the database when the observer belongs to a session.
+func (api *StreamingAPI) handleGetEventRange(w http.ResponseWriter, r *http.Request, observerID string) {
+	from, fromErr := strconv.Atoi(r.URL.Query().Get("from"))
+	to, toErr := strconv.Atoi(r.URL.Query().Get("to"))
+	if fromErr != nil || toErr != nil {
+		http.Error(w, "Both from and to must be integer event indices", http.StatusBadRequest)
+		return
+	}
+	if from < 0 || to < from {
+		http.Error(w, "Event range must satisfy 0 <= from <= to", http.StatusBadRequest)
+		return
+	}
+	if to-from+1 > maxEventRange {
+		http.Error(w, fmt.Sprintf("Event range is limited to %d events", maxEventRange), http.StatusBadRequest)
+		return
+	}
+
+	api.observerManager.UpdateObserverActivity(observerID)
+
+	buffered, firstBuffered, lastIndex, exists := api.eventStore.GetEventRange(observerID, from, to)
+	if !exists {
+		http.Error(w, "Observer not found", http.StatusNotFound)
+		return
+	}
+	if to > lastIndex {
+		to = lastIndex
+	}
+
+	response := GetEventRangeResponse{
+		Events:         buffered,
+		From:           from,
+		To:             to,
+		LastEventIndex: lastIndex,
+		Source:         "memory",
+		ObserverID:     observerID,
+	}
+
+	// The start of the range was evicted from memory
+	if from < firstBuffered && from <= to {
+		evictedTo := firstBuffered - 1
+		if evictedTo > to {
+			evictedTo = to
+		}
+		persisted, err := api.eventRangeFromDB(r.Context(), observerID, from, evictedTo-from+1)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Events %d-%d are no longer in memory: %v", from, evictedTo, err), http.StatusGone)
+			return
+		}
+		response.Events = append(persisted, buffered...)
+		response.Source = "database"
+		if len(buffered) > 0 {
+			response.Source = "mixed"
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// eventRangeFromDB reads count events of the observer's session starting at index offset. The database
+// holds the session's events in emission order, so evicted indices map to the same offsets.
+func (api *StreamingAPI) eventRangeFromDB(ctx context.Context, observerID string, offset, count int) ([]events.Event, error) {
+	if api.chatDB == nil {
+		return nil, fmt.Errorf("no database configured")
+	}
+	observer, exists := api.observerManager.GetObserver(observerID)
+	if !exists || observer.SessionID == "" {
+		return nil, fmt.Errorf("observer has no session to read persisted events from")
+	}
+
+	persisted, err := api.chatDB.GetEventsBySession(ctx, observer.SessionID, count, offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(persisted) != count {
+		return nil, fmt.Errorf("database holds %d of the %d requested events", len(persisted), count)
+	}
+
+	rangeEvents := make([]events.Event, 0, len(persisted))
+	for _, event := range persisted {
+		var data map[string]interface{}
+		if err := json.Unmarshal(event.EventData, &data); err != nil {
+			return nil, fmt.Errorf("failed to decode persisted event %s: %w", event.ID, err)
+		}
+		rangeEvents = append(rangeEvents, events.Event{
+			ID:        event.ID,
+			Type:      event.EventType,
+			Timestamp: event.Timestamp,
+			SessionID: observerID,
+			Data: &unifiedevents.AgentEvent{
+				Type:      unifiedevents.EventType(event.EventType),
+				Timestamp: event.Timestamp,
+				SessionID: event.SessionID,
+				Data:      &unifiedevents.GenericEventData{Data: data},
+			},
+		})
+	}
+	return rangeEvents, nil
+}
+
 // handleGetObserverStatus handles observer status requests
 func (api *StreamingAPI) handleGetObserverStatus(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -219,11 +378,12 @@ type GetActiveSessionsResponse struct {
 
 // ReconnectSessionResponse represents the response for reconnecting to a session
 type ReconnectSessionResponse struct {
-	ObserverID string `json:"observer_id"`
-	SessionID  string `json:"session_id"`
-	Status     string `json:"status"`
-	AgentMode  string `json:"agent_mode"`
-	Message    string `json:"message"`
+	ObserverID   string   `json:"observer_id"`
+	SessionID    string   `json:"session_id"`
+	Status       string   `json:"status"`
+	AgentMode    string   `json:"agent_mode"`
+	Message      string   `json:"message"`
+	EnabledTools []string `json:"enabled_tools,omitempty"` // Restored so the client can show the session's tool selection
 }
 
 // handleGetActiveSessions handles requests to get all active sessions
@@ -275,11 +435,12 @@ func (api *StreamingAPI) handleReconnectSession(w http.ResponseWriter, r *http.R
 	observer := api.observerManager.RegisterObserver(sessionID)
 
 	response := ReconnectSessionResponse{
-		ObserverID: observer.ID,
-		SessionID:  sessionID,
-		Status:     "reconnected",
-		AgentMode:  activeSession.AgentMode,
-		Message:    "Successfully reconnected to active session",
+		ObserverID:   observer.ID,
+		SessionID:    sessionID,
+		Status:       "reconnected",
+		AgentMode:    activeSession.AgentMode,
+		Message:      "Successfully reconnected to active session",
+		EnabledTools: api.sessionEnabledTools(r.Context(), sessionID),
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+// pollResult is GetEventsResponse with only event IDs decoded
+type pollResult struct {
+	Events []struct {
+		ID string `json:"id"`
+	} `json:"events"`
+	LastEventIndex int `json:"last_event_index"`
+	NextCursor     int `json:"next_cursor"`
+}
+
+func poll(t *testing.T, api *StreamingAPI, observerID, query string) ([]string, pollResult) {
+	t.Helper()
+	rec := getEventRange(api, observerID, query)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result pollResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	ids := make([]string, len(result.Events))
+	for i, event := range result.Events {
+		ids[i] = event.ID
+	}
+	return ids, result
+}
+
+func TestPollingWithCursorReturnsOnlyTheDelta(t *testing.T) {
+	eventStore := events.NewEventStore(1000)
+	t.Cleanup(eventStore.Stop)
+	observerManager := events.NewObserverManager(eventStore)
+	observer := observerManager.RegisterObserver("session-1")
+	testLogger := logger.CreateDiscardLogger("error")
+	api := &StreamingAPI{eventStore: eventStore, observerManager: observerManager, logger: testLogger}
+
+	addBatch := func(from, to int) {
+		for i := from; i < to; i++ {
+			eventStore.AddEvent(observer.ID, events.Event{ID: fmt.Sprintf("event-%d", i), Type: "tool_call_start", Timestamp: time.Now()})
+		}
+	}
+
+	// Nothing buffered yet: the cursor stays before the first event
+	ids, result := poll(t, api, observer.ID, "since=-1")
+	if len(ids) != 0 || result.NextCursor != -1 {
+		t.Fatalf("expected an empty poll with cursor -1, got %v (cursor %d)", ids, result.NextCursor)
+	}
+
+	cursor := result.NextCursor
+	for _, batch := range [][2]int{{0, 3}, {3, 5}, {5, 5}, {5, 9}} {
+		addBatch(batch[0], batch[1])
+		ids, result = poll(t, api, observer.ID, "since="+strconv.Itoa(cursor))
+		var want []string
+		for i := batch[0]; i < batch[1]; i++ {
+			want = append(want, fmt.Sprintf("event-%d", i))
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(want) {
+			t.Fatalf("batch %v: got %v, want %v", batch, ids, want)
+		}
+		if result.NextCursor != batch[1]-1 {
+			t.Fatalf("batch %v: expected cursor %d, got %d", batch, batch[1]-1, result.NextCursor)
+		}
+		cursor = result.NextCursor
+	}
+
+	// Without since the whole buffer is returned, first event included
+	ids, result = poll(t, api, observer.ID, "")
+	if len(ids) != 9 || ids[0] != "event-0" || result.LastEventIndex != 8 {
+		t.Fatalf("expected all 9 events without a cursor, got %v (last index %d)", ids, result.LastEventIndex)
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"mcp-agent/agent_go/pkg/database"
+)
+
+// retentionInterval is how often the chat history database is pruned
+var retentionInterval = time.Hour
+
+// runRetention prunes completed sessions and events older than retention until ctx is cancelled,
+// starting with an immediate pass
+func (api *StreamingAPI) runRetention(ctx context.Context, retention time.Duration) {
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+	for {
+		api.pruneChatHistory(ctx, time.Now().Add(-retention))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pruneChatHistory removes inactive sessions and events older than cutoff. Active sessions are
+// kept by the database, which only prunes sessions whose status is not "active".
+func (api *StreamingAPI) pruneChatHistory(ctx context.Context, cutoff time.Time) {
+	sessions, err := api.chatDB.PruneSessions(ctx, cutoff)
+	if err != nil {
+		log.Printf("[RETENTION] Failed to prune sessions older than %s: %v", cutoff.Format(time.RFC3339), err)
+	}
+	prunedEvents, err := api.chatDB.PruneEvents(ctx, cutoff)
+	if err != nil {
+		log.Printf("[RETENTION] Failed to prune events older than %s: %v", cutoff.Format(time.RFC3339), err)
+	}
+	if sessions > 0 || prunedEvents > 0 {
+		log.Printf("[RETENTION] Pruned %d sessions and %d events older than %s", sessions, prunedEvents, cutoff.Format(time.RFC3339))
+	}
+}
+
+// markChatSessionActive flips a resumed session back to "active" in the database so retention
+// leaves it alone while it runs; its final status is written by updateSessionStatus
+func (api *StreamingAPI) markChatSessionActive(sessionID string) {
+	if api.chatDB == nil {
+		return
+	}
+	go func() {
+		ctx := context.Background()
+		session, err := api.chatDB.GetChatSession(ctx, sessionID)
+		if err != nil || session.Status == "active" {
+			// New sessions are created as active
+			return
+		}
+		// An empty preset_query_id clears the link, so pass the current one through
+		presetQueryID := ""
+		if session.PresetQueryID != nil {
+			presetQueryID = *session.PresetQueryID
+		}
+		if _, err := api.chatDB.UpdateChatSession(ctx, sessionID, &database.UpdateChatSessionRequest{
+			Status:        "active",
+			PresetQueryID: presetQueryID,
+		}); err != nil {
+			log.Printf("[RETENTION] Failed to mark resumed session %s active: %v", sessionID, err)
+		}
+	}()
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/pkg/logger"
+	mcpagent "mcp-agent/agent_go/pkg/mcpagent"
+)
+
+func TestStoreToolStatusInvalidatesRoutingCacheOnToolChange(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+	cache, err := mcpagent.NewRoutingCache(time.Hour, 0, "")
+	if err != nil {
+		t.Fatalf("NewRoutingCache: %v", err)
+	}
+	api := &StreamingAPI{toolStatus: make(map[string]ToolStatus), routingCache: cache, logger: testLogger}
+	cache.Store("aws-query", mcpagent.RoutingDecision{Servers: []string{"aws"}, Confidence: 0.9, ToolServers: []string{"aws"}})
+
+	api.storeToolStatus("aws", ToolStatus{Name: "aws", FunctionNames: []string{"list_buckets", "get_object"}})
+	api.storeToolStatus("aws", ToolStatus{Name: "aws", FunctionNames: []string{"get_object", "list_buckets"}})
+	if cache.Len() != 1 {
+		t.Fatalf("refreshing an unchanged tool set invalidated the routing cache")
+	}
+
+	api.storeToolStatus("aws", ToolStatus{Name: "aws", FunctionNames: []string{"get_object", "list_buckets", "put_object"}})
+	if cache.Len() != 0 {
+		t.Fatalf("routing cache holds %d decisions after the aws tools changed, want 0", cache.Len())
+	}
+}
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+)
+
+const (
+	defaultEventSchemaPath       = "schemas/polling-event.schema.json"
+	defaultSchemaDriftSampleRate = 0.1
+	maxSchemaDriftDepth          = 8
+	maxSchemaDriftArrayItems     = 5
+	maxSchemaDriftRecords        = 200
+)
+
+// SchemaDrift is one mismatch between an emitted event and the generated frontend schema
+type SchemaDrift struct {
+	EventType string    `json:"event_type"`
+	Path      string    `json:"path"`
+	Issue     string    `json:"issue"`
+	FirstSeen time.Time `json:"first_seen"`
+	Count     int       `json:"count"`
+}
+
+// schemaNode is the subset of JSON Schema emitted by cmd/schema-gen
+type schemaNode struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 json.RawMessage        `json:"type"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Required             []string               `json:"required"`
+	Items                *schemaNode            `json:"items"`
+}
+
+// UnmarshalJSON accepts boolean schemas, which the generator emits for interface{} fields; they are not checked
+func (n *schemaNode) UnmarshalJSON(data []byte) error {
+	if trimmed := strings.TrimSpace(string(data)); trimmed == "true" || trimmed == "false" {
+		*n = schemaNode{}
+		return nil
+	}
+	type plainSchemaNode schemaNode
+	return json.Unmarshal(data, (*plainSchemaNode)(n))
+}
+
+// schemaDriftChecker validates a sample of emitted events against the generated polling event schema
+// and reports fields or types the frontend contract does not know about. Dev-only (EVENT_SCHEMA_DRIFT_CHECK).
+type schemaDriftChecker struct {
+	defs       map[string]*schemaNode
+	eventDefs  map[string]string // event type -> $defs name of its data
+	sampleRate float64
+
+	mu         sync.Mutex
+	seenTypes  map[string]bool // The first event of each type is always checked
+	drifts     map[string]*SchemaDrift
+	driftOrder []string
+	random     *rand.Rand
+}
+
+// schemaDriftCheckerFromEnv reads EVENT_SCHEMA_DRIFT_CHECK, EVENT_SCHEMA_PATH and
+// EVENT_SCHEMA_DRIFT_SAMPLE_RATE; returns nil when the check is disabled or the schema cannot be loaded
+func schemaDriftCheckerFromEnv() *schemaDriftChecker {
+	if os.Getenv("EVENT_SCHEMA_DRIFT_CHECK") != "true" {
+		return nil
+	}
+	path := os.Getenv("EVENT_SCHEMA_PATH")
+	if path == "" {
+		path = defaultEventSchemaPath
+	}
+	sampleRate := defaultSchemaDriftSampleRate
+	if rate, err := strconv.ParseFloat(os.Getenv("EVENT_SCHEMA_DRIFT_SAMPLE_RATE"), 64); err == nil && rate > 0 && rate <= 1 {
+		sampleRate = rate
+	}
+	checker, err := newSchemaDriftChecker(path, sampleRate)
+	if err != nil {
+		log.Printf("[SCHEMA DRIFT] Disabled: %v", err)
+		return nil
+	}
+	log.Printf("[SCHEMA DRIFT] Checking %.0f%% of emitted events against %s (%d event types)", sampleRate*100, path, len(checker.eventDefs))
+	return checker
+}
+
+func newSchemaDriftChecker(path string, sampleRate float64) (*schemaDriftChecker, error) {
+	//nolint:gosec // G304: schema path comes from server configuration
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event schema: %w", err)
+	}
+	var schema struct {
+		Defs map[string]*schemaNode `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse event schema: %w", err)
+	}
+	eventData, exists := schema.Defs["EventData"]
+	if !exists {
+		return nil, fmt.Errorf("event schema %s has no EventData definition", path)
+	}
+
+	checker := &schemaDriftChecker{
+		defs:       schema.Defs,
+		eventDefs:  make(map[string]string),
+		sampleRate: sampleRate,
+		seenTypes:  make(map[string]bool),
+		drifts:     make(map[string]*SchemaDrift),
+		random:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for eventType, property := range eventData.Properties {
+		checker.eventDefs[eventType] = strings.TrimPrefix(property.Ref, "#/$defs/")
+	}
+	return checker, nil
+}
+
+// observe is the event store hook: it checks sampled events and logs new drift
+func (c *schemaDriftChecker) observe(observerID string, event events.Event) {
+	if event.Data == nil || event.Data.Data == nil {
+		return
+	}
+	eventType := string(event.Data.Type)
+
+	c.mu.Lock()
+	sampled := !c.seenTypes[eventType] || c.random.Float64() < c.sampleRate
+	c.seenTypes[eventType] = true
+	c.mu.Unlock()
+	if !sampled {
+		return
+	}
+
+	encoded, err := json.Marshal(event.Data.Data)
+	if err != nil {
+		return
+	}
+	var payload interface{}
+	if err := json.Unmarshal(encoded, &payload); err != nil {
+		return
+	}
+	for _, drift := range c.check(eventType, payload) {
+		c.record(drift)
+	}
+}
+
+// check validates an event's data payload against the schema for its type
+func (c *schemaDriftChecker) check(eventType string, payload interface{}) []SchemaDrift {
+	defName, exists := c.eventDefs[eventType]
+	if !exists {
+		return []SchemaDrift{{EventType: eventType, Path: "data", Issue: "event type not in generated schema"}}
+	}
+	var issues []SchemaDrift
+	c.validate("data", payload, &schemaNode{Ref: "#/$defs/" + defName}, 0, func(path, issue string) {
+		issues = append(issues, SchemaDrift{EventType: eventType, Path: path, Issue: issue})
+	})
+	return issues
+}
+
+func (c *schemaDriftChecker) validate(path string, value interface{}, node *schemaNode, depth int, report func(path, issue string)) {
+	if node == nil || depth > maxSchemaDriftDepth {
+		return
+	}
+	if node.Ref != "" {
+		resolved, exists := c.defs[strings.TrimPrefix(node.Ref, "#/$defs/")]
+		if !exists {
+			report(path, fmt.Sprintf("unresolved schema reference %s", node.Ref))
+			return
+		}
+		node = resolved
+	}
+	// Go encodes nil slices, maps and pointers as null; the generated schema does not model that
+	if value == nil {
+		return
+	}
+
+	if expected := schemaTypes(node.Type); len(expected) > 0 && !matchesSchemaType(value, expected) {
+		report(path, fmt.Sprintf("type %s, schema expects %s", jsonValueType(value), strings.Join(expected, "|")))
+		return
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if node.Properties == nil {
+			return
+		}
+		closed := strings.TrimSpace(string(node.AdditionalProperties)) == "false"
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, known := node.Properties[key]
+			if !known {
+				if closed {
+					report(path+"."+key, "field not in schema")
+				}
+				continue
+			}
+			c.validate(path+"."+key, typed[key], property, depth+1, report)
+		}
+		for _, required := range node.Required {
+			if _, present := typed[required]; !present {
+				report(path+"."+required, "required field missing")
+			}
+		}
+	case []interface{}:
+		for i, item := range typed {
+			if i >= maxSchemaDriftArrayItems {
+				break
+			}
+			c.validate(fmt.Sprintf("%s[%d]", path, i), item, node.Items, depth+1, report)
+		}
+	}
+}
+
+// record stores a drift and logs it the first time it is seen
+func (c *schemaDriftChecker) record(drift SchemaDrift) {
+	key := drift.EventType + "|" + drift.Path + "|" + drift.Issue
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if existing, exists := c.drifts[key]; exists {
+		existing.Count++
+		return
+	}
+	if len(c.drifts) >= maxSchemaDriftRecords {
+		return
+	}
+	drift.FirstSeen = time.Now()
+	drift.Count = 1
+	c.drifts[key] = &drift
+	c.driftOrder = append(c.driftOrder, key)
+	log.Printf("[SCHEMA DRIFT] ⚠️ %s event %s: %s (regenerate schemas with cmd/schema-gen or fix the event)", drift.EventType, drift.Path, drift.Issue)
+}
+
+// report returns the drift found so far in the order it was first seen
+func (c *schemaDriftChecker) report() []SchemaDrift {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	report := make([]SchemaDrift, 0, len(c.driftOrder))
+	for _, key := range c.driftOrder {
+		report = append(report, *c.drifts[key])
+	}
+	return report
+}
+
+// handleSchemaDrift reports schema drift detected by the dev-mode event check
+func (api *StreamingAPI) handleSchemaDrift(w http.ResponseWriter, r *http.Request) {
+	if api.schemaDrift == nil {
+		http.Error(w, "Schema drift check is disabled (set EVENT_SCHEMA_DRIFT_CHECK=true)", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"drifts": api.schemaDrift.report(),
+	})
+}
+
+func schemaTypes(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}
+	}
+	var multiple []string
+	json.Unmarshal(raw, &multiple)
+	return multiple
+}
+
+func matchesSchemaType(value interface{}, expected []string) bool {
+	actual := jsonValueType(value)
+	for _, schemaType := range expected {
+		if schemaType == actual || (schemaType == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonValueType(value interface{}) string {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if typed == math.Trunc(typed) {
+			return "integer"
+		}
+		return "number"
+	}
+	return "null"
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+)
+
+func newDriftTestStore(t *testing.T) (*events.EventStore, *schemaDriftChecker) {
+	t.Helper()
+	checker, err := newSchemaDriftChecker("../../schemas/polling-event.schema.json", 1)
+	if err != nil {
+		t.Fatalf("failed to load generated schema: %v", err)
+	}
+	store := events.NewEventStore(100)
+	t.Cleanup(store.Stop)
+	store.SetEventHook(checker.observe)
+	return store, checker
+}
+
+func storeEvent(store *events.EventStore, eventType unifiedevents.EventType, data unifiedevents.EventData) {
+	store.AddEvent("observer-1", events.Event{
+		ID:        "event-1",
+		Type:      string(eventType),
+		Timestamp: time.Now(),
+		Data:      &unifiedevents.AgentEvent{Type: eventType, Timestamp: time.Now(), Data: data},
+	})
+}
+
+func TestSchemaDriftIgnoresConformingEvents(t *testing.T) {
+	store, checker := newDriftTestStore(t)
+
+	params := unifiedevents.ToolParams{Arguments: `{"bucket": "logs"}`}
+	storeEvent(store, unifiedevents.ToolCallStart, unifiedevents.NewToolCallStartEvent(1, "list_objects", params, "aws", "span-1"))
+	storeEvent(store, unifiedevents.ToolCallEnd, unifiedevents.NewToolCallEndEvent(1, "list_objects", "3 objects", "aws", time.Second, "span-1"))
+
+	if drifts := checker.report(); len(drifts) != 0 {
+		t.Fatalf("expected no drift for events matching the schema, got %+v", drifts)
+	}
+}
+
+func TestSchemaDriftWarnsOnMismatchedEvent(t *testing.T) {
+	store, checker := newDriftTestStore(t)
+
+	// A tool_call_start event carrying the end event's payload has fields the frontend schema does not know
+	mismatched := unifiedevents.NewToolCallEndEvent(1, "list_objects", "3 objects", "aws", time.Second, "span-1")
+	storeEvent(store, unifiedevents.ToolCallStart, mismatched)
+	storeEvent(store, unifiedevents.ToolCallStart, mismatched)
+
+	drifts := checker.report()
+	found := map[string]SchemaDrift{}
+	for _, drift := range drifts {
+		found[drift.Path] = drift
+	}
+	for _, path := range []string{"data.result", "data.duration"} {
+		drift, exists := found[path]
+		if !exists || drift.EventType != "tool_call_start" || drift.Issue != "field not in schema" {
+			t.Fatalf("expected drift warning for %s, got %+v", path, drifts)
+		}
+		if drift.Count != 2 {
+			t.Fatalf("expected repeated drift counted once per event, got %+v", drift)
+		}
+	}
+
+	// Types the generated schema has never seen are drift too
+	storeEvent(store, unifiedevents.EventType("brand_new_event"), mismatched)
+	if last := checker.report()[len(checker.report())-1]; last.EventType != "brand_new_event" || last.Issue != "event type not in generated schema" {
+		t.Fatalf("expected drift for an unknown event type, got %+v", last)
+	}
+
+	api := &StreamingAPI{schemaDrift: checker}
+	rec := httptest.NewRecorder()
+	api.handleSchemaDrift(rec, httptest.NewRequest(http.MethodGet, "/api/debug/schema-drift", nil))
+	var body struct {
+		Drifts []SchemaDrift `json:"drifts"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || len(body.Drifts) != len(checker.report()) {
+		t.Fatalf("expected drift report from the endpoint, got %d drifts (err %v)", len(body.Drifts), err)
+	}
+}
+
+func TestSchemaDriftTypeMismatch(t *testing.T) {
+	_, checker := newDriftTestStore(t)
+
+	drifts := checker.check("tool_call_end", map[string]interface{}{"tool_name": 42.0, "turn": 1.5})
+	if len(drifts) != 2 {
+		t.Fatalf("expected two type mismatches, got %+v", drifts)
+	}
+	if drifts[0].Path != "data.tool_name" || drifts[0].Issue != "type integer, schema expects string" {
+		t.Fatalf("unexpected drift: %+v", drifts[0])
+	}
+	if drifts[1].Path != "data.turn" || drifts[1].Issue != "type number, schema expects integer" {
+		t.Fatalf("unexpected drift: %+v", drifts[1])
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -15,13 +16,13 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	_ "github.com/jackc/pgx/v5/stdlib" // Postgres driver for --db-driver postgres
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"mcp-agent/agent_go/internal/events"
 	"mcp-agent/agent_go/internal/llm"
 	"mcp-agent/agent_go/internal/llmtypes"
-	"mcp-agent/agent_go/internal/observability"
 	"mcp-agent/agent_go/internal/utils"
 	agent "mcp-agent/agent_go/pkg/agentwrapper"
 	"mcp-agent/agent_go/pkg/database"
@@ -30,6 +31,7 @@ import (
 	"mcp-agent/agent_go/pkg/orchestrator"
 	"mcp-agent/agent_go/pkg/orchestrator/agents"
 	orchtypes "mcp-agent/agent_go/pkg/orchestrator/types"
+	"mcp-agent/agent_go/pkg/pricing"
 
 	"mcp-agent/agent_go/pkg/logger"
 
@@ -188,6 +190,10 @@ type StreamingAPI struct {
 	workflowOrchestrators map[string]orchestrator.Orchestrator
 	plannerOrchestrators  map[string]orchestrator.Orchestrator
 
+	// Components holding MCP connections per session, shut down when the session is stopped
+	sessionResources    map[string][]orchestrator.Shutdowner
+	sessionResourcesMux sync.Mutex
+
 	toolStatus    map[string]ToolStatus
 	enabledTools  map[string][]string // queryID/sessionID -> enabled tool names
 	toolStatusMux sync.RWMutex
@@ -201,6 +207,63 @@ type StreamingAPI struct {
 
 	// Logger for structured logging
 	logger utils.ExtendedLogger
+
+	// Batch queries: sessionID -> channels waiting for the session's terminal status
+	sessionDoneWaiters map[string][]chan string
+	sessionDoneMux     sync.Mutex
+	batchDispatch      batchDispatcher // nil uses dispatchBatchQuery
+
+	// Event export webhooks: sessionID -> where to POST the session's events on completion
+	eventExports        map[string]*eventExportConfig
+	eventExportMux      sync.Mutex
+	eventExportDefaults eventExportConfig
+	eventExportClient   *http.Client // nil uses a client with a 30s timeout
+
+	// Memory pressure: nil disables the limit. Evicted histories live in the database until the session's next query
+	memoryMonitor    *memoryPressureMonitor
+	evictedHistories map[string]bool // guarded by conversationMux
+
+	// Rollup of advertised vs invoked tools across runs (TOOL_USAGE_ANALYTICS); nil disables tracking
+	toolUsage *mcpagent.ToolUsageRollup
+
+	// Smart routing decisions reused across runs (ROUTING_CACHE_TTL_SECONDS); nil disables the cache
+	routingCache *mcpagent.RoutingCache
+
+	// Agent mode per session: sessionID -> mode of its latest query or mid-session switch
+	sessionAgentModes map[string]string
+	sessionModeMux    sync.Mutex
+
+	// Per-session query rate limit and token budget (SESSION_RATE_LIMIT, SESSION_TOKEN_BUDGET); nil disables
+	sessionLimits *sessionLimits
+
+	// Per-session tracing destination overrides, accepted only when TRACING_OVERRIDE_ENABLED=true
+	tracingOverrideEnabled bool
+	sessionTracing         map[string]*TracingOverride
+	sessionTracingMux      sync.Mutex
+
+	// Dev-mode validation of emitted events against the generated schema (EVENT_SCHEMA_DRIFT_CHECK); nil disables
+	schemaDrift *schemaDriftChecker
+
+	// Query, tool and LLM metrics served at /metrics (--metrics or METRICS_ENABLED); nil disables
+	metrics *serverMetrics
+
+	// Reproducible bundles of failed runs, downloadable per session (BUG_REPORTS_ENABLED); nil disables
+	bugReports *bugReportStore
+
+	// Workspace content cap for named orchestrator checkpoints (CHECKPOINT_MAX_BYTES; 0 uses the default)
+	checkpointMaxBytes int64
+
+	// Default dollar budget for orchestrator/workflow runs (ORCHESTRATOR_COST_BUDGET_USD); 0 disables
+	defaultCostBudgetUSD float64
+
+	// Handling of configured servers exposing identical tool sets (MCP_SERVER_DEDUP_POLICY)
+	serverDedupPolicy mcpagent.ServerDedupPolicy
+
+	// Attach a structured-LLM recap of the run to completion events (RUN_SUMMARY_ENABLED)
+	runSummaryEnabled bool
+
+	// A/B experiment routing requests to weighted agent configurations (EXPERIMENT_CONFIG_PATH); nil disables
+	experiment *Experiment
 }
 
 // QueryRequest represents an agent query request
@@ -217,8 +280,41 @@ type QueryRequest struct {
 	LLMConfig      *orchestrator.LLMConfig `json:"llm_config,omitempty"`
 	PresetQueryID  string                  `json:"preset_query_id,omitempty"`
 	LLMGuidance    string                  `json:"llm_guidance,omitempty"` // LLM guidance message
+	MaxServers     int                     `json:"max_servers,omitempty"`  // Maximum MCP servers to connect (0 = no limit)
+	// Inject one-shot tool usage examples into the system prompt, optionally with curated examples keyed by tool name
+	IncludeToolExamples bool              `json:"include_tool_examples,omitempty"`
+	ToolExamples        map[string]string `json:"tool_examples,omitempty"`
+	// Context window (tokens) per model ID; oversized prompts switch to a larger-context model up front
+	ContextWindowModels map[string]int `json:"context_window_models,omitempty"`
+	// Inline the content of workspace files referenced by tool results, up to the per-file byte limit (0 = default)
+	InlineWorkspaceFiles  bool `json:"inline_workspace_files,omitempty"`
+	WorkspaceFileMaxBytes int  `json:"workspace_file_max_bytes,omitempty"`
+	// Workflow mode: escalate a step to human feedback only after N automated failures (0 = always ask)
+	HumanEscalationAfterFailures int `json:"human_escalation_after_failures,omitempty"`
+	// Workflow mode: default validation score (0.0-1.0) a step must reach to pass, for steps without their own success_threshold (0 = disabled)
+	StepSuccessThreshold float64 `json:"step_success_threshold,omitempty"`
+	// Workflow mode: word-overlap similarity (0.0-1.0, at least 0.6) at which a plan step repeating an earlier one is offered for merging (0 = disabled)
+	StepDedupThreshold float64 `json:"step_dedup_threshold,omitempty"`
+	// Workflow mode: identical plan feedback count that triggers the change-approach/abort prompt (0 = default 2, negative disables)
+	RepeatedFeedbackLimit int `json:"repeated_feedback_limit,omitempty"`
+	// Workflow mode: execute independent plan steps concurrently with up to this many workers (0 or 1 = one by one)
+	ParallelStepWorkers int `json:"parallel_step_workers,omitempty"`
+	// Workflow mode: stop injecting the recorded outputs of a step's context dependencies into its execution prompt
+	DisableDependencyOutputs bool `json:"disable_dependency_outputs,omitempty"`
+	// Workflow mode: plan and extract the steps, then return the plan without executing any step
+	DryRun bool `json:"dry_run,omitempty"`
+	// POST the session's full ordered event timeline to a webhook when it completes
+	EventExport *EventExportRequest `json:"event_export,omitempty"`
+	// Send this session's traces to another destination than the server's TRACING_PROVIDER
+	Tracing *TracingOverride `json:"tracing,omitempty"`
 	// Orchestrator execution mode selection
 	OrchestratorExecutionMode orchtypes.ExecutionMode `json:"orchestrator_execution_mode,omitempty"`
+	// Orchestrator/workflow mode: stop the run once its estimated LLM cost crosses this many USD (0 = ORCHESTRATOR_COST_BUDGET_USD)
+	CostBudgetUSD float64 `json:"cost_budget_usd,omitempty"`
+	// Workflow mode: validate the designated steps with several models and use their agreed verdict
+	Consensus *orchestrator.ConsensusConfig `json:"consensus,omitempty"`
+	// Orchestrator/workflow mode: extract structured output with this provider/model instead of the execution LLM
+	StructuredOutputLLM *agents.StructuredOutputLLMConfig `json:"structured_output_llm,omitempty"`
 }
 
 // CrossProviderFallback represents cross-provider fallback configuration
@@ -233,6 +329,9 @@ type QueryResponse struct {
 	ObserverID string `json:"observer_id"`
 	Status     string `json:"status"`
 	Message    string `json:"message,omitempty"`
+	// Experiment variant serving the query, when an experiment is running
+	Experiment        string `json:"experiment,omitempty"`
+	ExperimentVariant string `json:"experiment_variant,omitempty"`
 }
 
 // LLMGuidanceRequest represents a request to set LLM guidance for a session
@@ -283,6 +382,19 @@ func init() {
 
 	// Chat History Database flags
 	ServerCmd.Flags().String("db-path", "/app/chat_history.db", "SQLite database path for chat history")
+	ServerCmd.Flags().String("db-driver", "sqlite", "Chat history database backend (sqlite, postgres)")
+	ServerCmd.Flags().String("db-dsn", "", "Postgres connection string when --db-driver is postgres")
+	ServerCmd.Flags().Int("retention-days", 0, "Prune completed chat sessions and events older than this many days (0 keeps everything)")
+
+	// Cost estimation flags
+	ServerCmd.Flags().String("pricing-config", "", "JSON price table (model -> input_per_1k/output_per_1k USD) overriding the built-in prices")
+
+	// Tool policy flags
+	ServerCmd.Flags().String("tool-denylist", "", "Comma-separated tool name globs (or server:tool) blocked for every agent")
+	ServerCmd.Flags().String("tool-allowlist", "", "Comma-separated tool name globs (or server:tool); when set, every other tool is blocked")
+
+	// Observability flags
+	ServerCmd.Flags().Bool("metrics", false, "Expose Prometheus metrics (tool/LLM latency, tokens, throttling, fallbacks, active sessions) at /metrics")
 
 	// Bind flags to viper
 	viper.BindPFlags(ServerCmd.Flags())
@@ -426,21 +538,82 @@ func runServer(cmd *cobra.Command, args []string) {
 
 	// Initialize polling system
 	eventStore := events.NewEventStore(10000) // Max 10000 events per observer
+	if envGrace := os.Getenv("OBSERVER_RETENTION_GRACE_SECONDS"); envGrace != "" {
+		if graceSeconds, err := strconv.Atoi(envGrace); err == nil {
+			eventStore.SetRetentionGrace(time.Duration(graceSeconds)*time.Second, events.DefaultGraceMultiplier)
+		}
+	}
+	// Completed-session buffer pruning is opt-in; see COMPLETED_SESSION_PRUNE_SECONDS in env.example
+	if envPrune := os.Getenv("COMPLETED_SESSION_PRUNE_SECONDS"); envPrune != "" {
+		if pruneSeconds, err := strconv.Atoi(envPrune); err == nil {
+			eventStore.SetCompletedPruneGrace(time.Duration(pruneSeconds) * time.Second)
+		}
+	}
+	if envLag := os.Getenv("EVENT_THROTTLE_LAG_THRESHOLD"); envLag != "" {
+		if lagThreshold, err := strconv.Atoi(envLag); err == nil && lagThreshold > 0 {
+			interval := time.Second
+			if envInterval := os.Getenv("EVENT_THROTTLE_INTERVAL_MS"); envInterval != "" {
+				if intervalMs, err := strconv.Atoi(envInterval); err == nil && intervalMs >= 0 {
+					interval = time.Duration(intervalMs) * time.Millisecond
+				}
+			}
+			eventStore.SetEmissionThrottle(lagThreshold, interval)
+		}
+	}
 	observerManager := events.NewObserverManager(eventStore)
 
 	// Initialize chat history database
-	dbPath := viper.GetString("db-path")
-	if dbPath == "" {
-		dbPath = "/app/chat_history.db" // Default SQLite database path
+	var chatDB database.Database
+	switch dbDriver := strings.ToLower(viper.GetString("db-driver")); dbDriver {
+	case "", "sqlite", "sqlite3":
+		dbPath := viper.GetString("db-path")
+		if dbPath == "" {
+			dbPath = "/app/chat_history.db" // Default SQLite database path
+		}
+		sqliteDB, err := database.NewSQLiteDB(dbPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize chat history database: %w", err)
+		}
+		chatDB = sqliteDB
+		fmt.Printf("💾 Chat History Database: %s\n", dbPath)
+	case "postgres", "postgresql":
+		dsn := viper.GetString("db-dsn")
+		if dsn == "" {
+			log.Fatalf("--db-dsn is required when --db-driver is postgres")
+		}
+		postgresDB, err := database.NewPostgresDB(dsn)
+		if err != nil {
+			log.Fatalf("Failed to initialize chat history database: %v", err)
+		}
+		chatDB = postgresDB
+		fmt.Printf("💾 Chat History Database: postgres\n")
+	default:
+		log.Fatalf("Unsupported --db-driver %q (supported: sqlite, postgres)", dbDriver)
 	}
+	defer chatDB.Close()
 
-	chatDB, err := database.NewSQLiteDB(dbPath)
-	if err != nil {
-		log.Fatalf("Failed to initialize chat history database: %w", err)
+	// Load the price table used for cost summaries and budgets
+	if pricingPath := viper.GetString("pricing-config"); pricingPath != "" {
+		priceTable, err := pricing.LoadPriceTable(pricingPath)
+		if err != nil {
+			log.Fatalf("Failed to load pricing config: %v", err)
+		}
+		pricing.SetDefault(priceTable)
+		fmt.Printf("💲 Pricing Config: %s\n", pricingPath)
 	}
-	defer chatDB.Close()
 
-	fmt.Printf("💾 Chat History Database: %s\n", dbPath)
+	// Block dangerous tools for every agent, independently of server selection
+	toolPolicy := mcpagent.ToolPolicy{
+		Allowlist: mcpagent.ParseToolPolicyPatterns(viper.GetString("tool-allowlist")),
+		Denylist:  mcpagent.ParseToolPolicyPatterns(viper.GetString("tool-denylist")),
+	}
+	if err := toolPolicy.Validate(); err != nil {
+		log.Fatalf("Invalid tool policy: %v", err)
+	}
+	if !toolPolicy.IsEmpty() {
+		mcpagent.SetDefaultToolPolicy(toolPolicy)
+		fmt.Printf("🛡️ Tool Policy: allowlist=%v denylist=%v\n", toolPolicy.Allowlist, toolPolicy.Denylist)
+	}
 
 	// Create internal LLM instance for workflow orchestrator
 	internalLLMProvider, err := llm.ValidateProvider(config.Provider)
@@ -489,6 +662,51 @@ func runServer(cmd *cobra.Command, args []string) {
 		// Initialize orchestrator storage
 		workflowOrchestrators: make(map[string]orchestrator.Orchestrator),
 		plannerOrchestrators:  make(map[string]orchestrator.Orchestrator),
+		sessionResources:      make(map[string][]orchestrator.Shutdowner),
+		// Initialize event export webhooks
+		eventExports:        make(map[string]*eventExportConfig),
+		eventExportDefaults: eventExportDefaultsFromEnv(),
+		// Initialize bug report capture for failed runs
+		bugReports: bugReportStoreFromEnv(),
+		// Initialize named checkpoint limits
+		checkpointMaxBytes: checkpointMaxBytesFromEnv(),
+		// Initialize the default orchestrator cost budget
+		defaultCostBudgetUSD: costBudgetFromEnv(),
+		// Initialize the redundant server policy
+		serverDedupPolicy: mcpagent.ParseServerDedupPolicy(os.Getenv("MCP_SERVER_DEDUP_POLICY")),
+		// Initialize run summaries on completion
+		runSummaryEnabled: os.Getenv("RUN_SUMMARY_ENABLED") == "true",
+		// Initialize the A/B experiment
+		experiment: experimentFromEnv(),
+		// Initialize memory pressure shedding (MAX_MEMORY_MB)
+		memoryMonitor:    memoryPressureMonitorFromEnv(),
+		evictedHistories: make(map[string]bool),
+		// Initialize unused tool analytics
+		toolUsage: toolUsageRollupFromEnv(),
+		// Initialize the smart routing decision cache
+		routingCache: routingCacheFromEnv(),
+		// Initialize per-session agent modes
+		sessionAgentModes: make(map[string]string),
+		// Initialize per-session rate limit and token budget
+		sessionLimits: sessionLimitsFromEnv(),
+		// Initialize per-session tracing overrides
+		tracingOverrideEnabled: os.Getenv("TRACING_OVERRIDE_ENABLED") == "true",
+		sessionTracing:         make(map[string]*TracingOverride),
+		// Initialize dev-mode event schema drift check
+		schemaDrift: schemaDriftCheckerFromEnv(),
+		// Initialize Prometheus metrics
+		metrics: metricsFromConfig(viper.GetBool("metrics")),
+	}
+	switch {
+	case api.schemaDrift != nil && api.metrics != nil:
+		eventStore.SetEventHook(func(observerID string, event events.Event) {
+			api.schemaDrift.observe(observerID, event)
+			api.metrics.observeEvent(observerID, event)
+		})
+	case api.schemaDrift != nil:
+		eventStore.SetEventHook(api.schemaDrift.observe)
+	case api.metrics != nil:
+		eventStore.SetEventHook(api.metrics.observeEvent)
 	}
 
 	// Setup routes
@@ -499,9 +717,11 @@ func runServer(cmd *cobra.Command, args []string) {
 
 	// API routes
 	apiRouter := router.PathPrefix("/api").Subrouter()
-	apiRouter.HandleFunc("/query", api.handleQuery).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc("/query", api.rejectUnderMemoryPressure(api.enforceSessionLimits(api.handleQuery))).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc("/batch", api.rejectUnderMemoryPressure(api.handleBatchQuery)).Methods("POST", "OPTIONS")
 	apiRouter.HandleFunc("/health", api.handleHealth).Methods("GET")
 	apiRouter.HandleFunc("/capabilities", api.handleCapabilities).Methods("GET")
+	apiRouter.HandleFunc("/debug/schema-drift", api.handleSchemaDrift).Methods("GET")
 	apiRouter.HandleFunc("/llm-config/defaults", api.handleGetLLMDefaults).Methods("GET")
 	apiRouter.HandleFunc("/llm-config/validate-key", api.handleValidateAPIKey).Methods("POST")
 	apiRouter.HandleFunc("/session/stop", api.handleStopSession).Methods("POST")
@@ -510,6 +730,8 @@ func runServer(cmd *cobra.Command, args []string) {
 	// Tool management routes (from tools.go)
 	apiRouter.HandleFunc("/tools", api.handleGetTools).Methods("GET")
 	apiRouter.HandleFunc("/tools/detail", api.handleGetToolDetail).Methods("GET")
+	apiRouter.HandleFunc("/tools/discovery-metrics", api.handleGetDiscoveryMetrics).Methods("GET")
+	apiRouter.HandleFunc("/tools/usage", api.handleGetToolUsage).Methods("GET")
 	apiRouter.HandleFunc("/tools/enabled", api.handleSetEnabledTools).Methods("POST")
 	apiRouter.HandleFunc("/tools/add", api.handleAddServer).Methods("POST")
 	apiRouter.HandleFunc("/tools/edit", api.handleEditServer).Methods("POST")
@@ -529,6 +751,8 @@ func runServer(cmd *cobra.Command, args []string) {
 	// Polling API routes (from polling.go)
 	apiRouter.HandleFunc("/observer/register", api.handleRegisterObserver).Methods("POST", "OPTIONS")
 	apiRouter.HandleFunc("/observer/{observer_id}/events", api.handleGetEvents).Methods("GET")
+	apiRouter.HandleFunc("/observer/{observer_id}/stream", api.handleStreamEvents).Methods("GET")
+	apiRouter.HandleFunc("/observer/{observer_id}/ws", api.handleEventWebSocket).Methods("GET")
 	apiRouter.HandleFunc("/observer/{observer_id}/status", api.handleGetObserverStatus).Methods("GET")
 	apiRouter.HandleFunc("/observer/{observer_id}", api.handleRemoveObserver).Methods("DELETE")
 
@@ -536,6 +760,11 @@ func runServer(cmd *cobra.Command, args []string) {
 	apiRouter.HandleFunc("/sessions/active", api.handleGetActiveSessions).Methods("GET")
 	apiRouter.HandleFunc("/sessions/{session_id}/reconnect", api.handleReconnectSession).Methods("POST")
 	apiRouter.HandleFunc("/sessions/{session_id}/status", api.handleGetSessionStatus).Methods("GET")
+	apiRouter.HandleFunc("/sessions/{session_id}/mode", api.handleSwitchSessionMode).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc("/sessions/{session_id}/enabled-tools", api.handleSessionEnabledTools).Methods("GET", "PUT", "OPTIONS")
+	apiRouter.HandleFunc("/sessions/{session_id}/bug-report", api.handleGetBugReport).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/sessions/{session_id}/checkpoints", api.handleCheckpoints).Methods("GET", "POST", "OPTIONS")
+	apiRouter.HandleFunc("/sessions/{session_id}/checkpoints/{name}/restore", api.handleRestoreCheckpoint).Methods("POST", "OPTIONS")
 
 	// LLM Guidance API routes
 	apiRouter.HandleFunc("/sessions/{session_id}/llm-guidance", api.handleSetLLMGuidance).Methods("POST", "OPTIONS")
@@ -550,6 +779,8 @@ func runServer(cmd *cobra.Command, args []string) {
 	apiRouter.HandleFunc("/chat-history/sessions/{session_id}", updateChatSessionHandler(chatDB)).Methods("PUT")
 	apiRouter.HandleFunc("/chat-history/sessions/{session_id}", deleteChatSessionHandler(chatDB)).Methods("DELETE")
 	apiRouter.HandleFunc("/chat-history/sessions/{session_id}/events", getSessionEventsHandler(chatDB)).Methods("GET")
+	apiRouter.HandleFunc("/chat-history/sessions/{session_id}/export", exportSessionHandler(chatDB)).Methods("GET")
+	apiRouter.HandleFunc("/chat-history/sessions/{session_id}/replay", api.handleReplaySession).Methods("POST", "OPTIONS")
 	apiRouter.HandleFunc("/chat-history/events", searchEventsHandler(chatDB)).Methods("GET")
 	apiRouter.HandleFunc("/chat-history/health", chatHistoryHealthCheckHandler(chatDB)).Methods("GET")
 
@@ -561,6 +792,10 @@ func runServer(cmd *cobra.Command, args []string) {
 	apiRouter.HandleFunc("/workflow/status", api.handleGetWorkflowStatus).Methods("GET")
 	apiRouter.HandleFunc("/workflow/update", api.handleUpdateWorkflow).Methods("POST", "OPTIONS")
 	apiRouter.HandleFunc("/workflow/constants", orchtypes.HandleWorkflowConstants).Methods("GET")
+	apiRouter.HandleFunc("/workflow/plan-graph", api.handleWorkflowPlanGraph).Methods("POST", "OPTIONS")
+
+	// Metrics endpoint (Prometheus text, or OpenMetrics with exemplars when negotiated)
+	router.HandleFunc("/metrics", api.handleMetrics).Methods("GET")
 
 	// Static file serving (for frontend)
 	router.PathPrefix("/").Handler(http.FileServer(http.Dir("./static/")))
@@ -589,6 +824,20 @@ func runServer(cmd *cobra.Command, args []string) {
 	fmt.Printf("🔄 Initializing tool cache on server startup...\n")
 	api.initializeToolCache()
 
+	// Shed idle session state and reject new runs when memory usage exceeds MAX_MEMORY_MB
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
+	if api.memoryMonitor != nil {
+		fmt.Printf("🧠 Memory limit: %d MB\n", api.memoryMonitor.limitBytes>>20)
+		go api.runMemoryPressureMonitor(monitorCtx)
+	}
+
+	// Prune old chat history in the background
+	if retentionDays := viper.GetInt("retention-days"); retentionDays > 0 {
+		fmt.Printf("🧹 Chat history retention: %d days\n", retentionDays)
+		go api.runRetention(monitorCtx, time.Duration(retentionDays)*24*time.Hour)
+	}
+
 	// Wait for interrupt signal to gracefully shutdown
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
@@ -626,6 +875,7 @@ func (api *StreamingAPI) corsMiddleware(next http.Handler) http.Handler {
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Session-ID, X-Observer-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, X-Token-Budget-Remaining")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if r.Method == "OPTIONS" {
@@ -658,6 +908,7 @@ func (api *StreamingAPI) handleHealth(w http.ResponseWriter, r *http.Request) {
 			"max_turns":        api.config.MaxTurns,
 			"tracing_provider": tracingProvider,
 		},
+		"providers": mcpagent.ProviderHealth(),
 	})
 }
 
@@ -673,11 +924,16 @@ func (api *StreamingAPI) handleCapabilities(w http.ResponseWriter, r *http.Reque
 		tracingProvider = "noop"
 	}
 
+	providers := make([]string, 0, len(llm.SupportedProviders()))
+	for _, provider := range llm.SupportedProviders() {
+		providers = append(providers, string(provider))
+	}
+
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"providers":   []string{"bedrock", "openai", "anthropic"},
+		"providers":   providers,
 		"streaming":   true,
 		"sse":         true,
-		"agent_modes": []string{"simple", "react", "orchestrator", "workflow"},
+		"agent_modes": append([]string{"simple", "react", "orchestrator", "workflow", database.AgentModeAuto}, mcpagent.RegisteredAgentModes()...),
 		"tracing": map[string]interface{}{
 			"enabled":  tracingProvider != "noop",
 			"provider": tracingProvider,
@@ -743,13 +999,27 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 	// Generate query ID
 	queryID := fmt.Sprintf("query_%d", time.Now().UnixNano())
 
+	// Route the request to an experiment variant before its configuration is resolved;
+	// the run context tags every event with the variant
+	experimentSessionID := r.Header.Get("X-Session-ID")
+	if experimentSessionID == "" {
+		experimentSessionID = queryID
+	}
+	experimentTags := api.assignExperimentVariant(r, &req, experimentSessionID)
+	runCtx := unifiedevents.WithEventTags(context.Background(), experimentTags)
+
 	// Initialize Langfuse tracing - single trace for entire conversation
-	// Read tracing provider from environment variable, default to "noop"
-	tracingProvider := os.Getenv("TRACING_PROVIDER")
-	if tracingProvider == "" {
-		tracingProvider = "noop"
+	// Uses the session's tracing override if any, otherwise TRACING_PROVIDER (default "noop")
+	tracer, err := api.selectTracer(r.Header.Get("X-Session-ID"), req.Tracing)
+	if err != nil {
+		var overrideErr *tracingOverrideError
+		if errors.As(err, &overrideErr) && overrideErr.disabled {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	tracer := observability.GetTracer(tracingProvider)
 	traceName := fmt.Sprintf("agent-conversation: %s", r.Header.Get("X-Session-ID"))
 	if traceName == "agent-conversation: " {
 		traceName = fmt.Sprintf("agent-conversation: %s", queryID)
@@ -826,6 +1096,9 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 		sessionID = queryID // fallback: use queryID as sessionID if not provided
 	}
 
+	// Follow-up queries without an agent_mode continue in the session's mode (see /sessions/{id}/mode)
+	req.AgentMode = api.resolveSessionAgentMode(sessionID, req.AgentMode)
+
 	// Create or get chat session for this query
 	// The agent will modify the session ID to agent-init-{sessionID}-{timestamp}
 	// So we need to create the chat session with the original sessionID
@@ -864,8 +1137,18 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Resolve agent_mode="auto" to simple/react/orchestrator by classifying the query
+	if req.AgentMode == database.AgentModeAuto {
+		classification := classifyAgentMode(r.Context(), api.internalLLM, req.Query)
+		log.Printf("[AUTO MODE DEBUG] Query %s classified as '%s' -> agent mode '%s' (fallback: %v)", queryID, classification.QueryClass, classification.SelectedMode, classification.Fallback)
+		req.AgentMode = classification.SelectedMode
+		api.emitAgentModeSelected(observerID, queryID, classification)
+	}
+
 	// Track active session for page refresh recovery
 	api.trackActiveSession(sessionID, observerID, req.AgentMode, req.Query)
+	api.registerEventExport(sessionID, req.EventExport)
+	api.registerBugReportRun(sessionID, queryID, observerID, req)
 
 	// Create a fresh agent for each request
 	log.Printf("[LLM CONFIG DEBUG] Creating fresh agent for each request")
@@ -876,6 +1159,7 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 	var finalModelID string
 	var fallbackModels []string
 	var crossProviderFallback *agent.CrossProviderFallback
+	var fallbackExclusions mcpagent.FallbackExclusions
 
 	if req.LLMConfig != nil {
 		// Use LLM configuration from frontend
@@ -890,6 +1174,7 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 				Models:   req.LLMConfig.CrossProviderFallback.Models,
 			}
 		}
+		fallbackExclusions = mcpagent.FallbackExclusions{Providers: req.LLMConfig.ExcludeProviders, Models: req.LLMConfig.ExcludeModels}
 		log.Printf("[LLM CONFIG DEBUG] Using detailed LLM config from request - Provider: %s, Model: %s, Fallbacks: %v, CrossProvider: %+v",
 			finalProvider, finalModelID, fallbackModels, crossProviderFallback)
 	} else {
@@ -987,6 +1272,9 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 			log.Printf("[TOOLS] No tool selection specified - will use ALL tools from selected servers")
 		}
 
+		// Fall back to the tools enabled for this session (persisted across restarts)
+		selectedTools = api.applySessionEnabledTools(context.Background(), sessionID, selectedTools)
+
 		// Create workflow orchestrator for this request
 		workflowOrchestrator, err := orchtypes.NewWorkflowOrchestrator(
 			req.Provider,        // provider
@@ -1011,13 +1299,21 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 		}
 
 		log.Printf("[WORKFLOW DEBUG] Created workflow orchestrator with %d custom tools", len(allTools))
+		workflowOrchestrator.SetWorkspaceRoot(api.workspaceRoot)
+		workflowOrchestrator.SetCheckpointMaxBytes(api.checkpointMaxBytes)
+		workflowOrchestrator.SetCostBudget(api.orchestratorCostBudget(req.CostBudgetUSD))
+		workflowOrchestrator.SetStructuredOutputLLM(req.StructuredOutputLLM)
+		if err := workflowOrchestrator.SetConsensus(req.Consensus); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid consensus configuration: %v", err), http.StatusBadRequest)
+			return
+		}
 
 		// Store workflow orchestrator for guidance injection
 		api.storeWorkflowOrchestrator(sessionID, workflowOrchestrator)
 
 		// Create a cancellable context for workflow execution using background context
 		// This prevents the workflow from being cancelled when the HTTP request ends
-		workflowCtx, workflowCancel := context.WithCancel(context.Background())
+		workflowCtx, workflowCancel := context.WithCancel(runCtx)
 
 		// Add debug logging for context creation
 		log.Printf("[WORKFLOW DEBUG] Created workflow context: %p, parent: %p", workflowCtx, context.Background())
@@ -1034,6 +1330,9 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 			ObserverID: observerID, // Include observer ID in response
 			Status:     "started",
 			Message:    "Query processing started. Use polling API to get real-time updates.",
+
+			Experiment:        experimentTags[experimentTagKey],
+			ExperimentVariant: experimentTags[variantTagKey],
 		}
 
 		if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -1101,8 +1400,15 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 
 			// Prepare options for the Execute method
 			workflowOptions := map[string]interface{}{
-				"workflowStatus":  workflowStatus,  // Current workflow status
-				"selectedOptions": selectedOptions, // Pass selected options from database
+				"workflowStatus":               workflowStatus,                   // Current workflow status
+				"selectedOptions":              selectedOptions,                  // Pass selected options from database
+				"humanEscalationAfterFailures": req.HumanEscalationAfterFailures, // Per-run human escalation policy
+				"stepSuccessThreshold":         req.StepSuccessThreshold,         // Per-run default validation score threshold
+				"stepDedupThreshold":           req.StepDedupThreshold,           // Per-run lexical duplicate step merging
+				"repeatedFeedbackLimit":        req.RepeatedFeedbackLimit,        // Per-run repeated feedback detection
+				"parallelStepWorkers":          req.ParallelStepWorkers,          // Per-run parallel execution of independent steps
+				"disableDependencyOutputs":     req.DisableDependencyOutputs,     // Per-run dependency output injection
+				"dryRun":                       req.DryRun,                       // Plan only, no step execution
 			}
 
 			log.Printf("[WORKFLOW EXECUTION DEBUG] About to call workflowOrchestrator.Execute")
@@ -1121,30 +1427,8 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 				workflowWorkspacePath,
 				workflowOptions,
 			)
-			if err != nil {
-				log.Printf("[WORKFLOW ERROR] Workflow execution failed for query %s: %v", queryID, err)
-				// Send error event
-				errorData := map[string]interface{}{
-					"error":    err.Error(),
-					"query_id": queryID,
-				}
-				api.eventStore.AddEvent(observerID, events.Event{
-					ID:        fmt.Sprintf("workflow_error_%s_%d", queryID, time.Now().UnixNano()),
-					Type:      "workflow_error",
-					Timestamp: time.Now(),
-					Data: &unifiedevents.AgentEvent{
-						Type:      "workflow_error",
-						Timestamp: time.Now(),
-						Data: &unifiedevents.GenericEventData{
-							Data: errorData,
-						},
-					},
-					SessionID: observerID,
-				})
-			} else {
-				log.Printf("[WORKFLOW DEBUG] Workflow execution completed for query %s", queryID)
-				// Workflow completion events are now handled by the workflow orchestrator itself
-			}
+			api.releaseSessionResource(context.Background(), sessionID, workflowOrchestrator)
+			api.finishWorkflowSession(sessionID, observerID, queryID, err)
 		}()
 		return
 	}
@@ -1155,6 +1439,9 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 		ObserverID: observerID, // Include observer ID in response
 		Status:     "started",
 		Message:    "Query processing started. Use polling API to get real-time updates.",
+
+		Experiment:        experimentTags[experimentTagKey],
+		ExperimentVariant: experimentTags[variantTagKey],
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -1167,6 +1454,13 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 
 	// Process the query in the background
 	go func() {
+		queryStart := time.Now()
+		api.metrics.sessionStarted()
+		defer func() {
+			api.metrics.sessionEnded()
+			api.metrics.observeQuery(req.AgentMode, time.Since(queryStart), string(traceID))
+		}()
+
 		// Helper function to send error and continue (not terminate)
 		sendError := func(errorMsg string, shouldTerminate bool) {
 			if shouldTerminate {
@@ -1212,6 +1506,8 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 				}
 				api.eventStore.AddEvent(observerID, serverErrorEvent)
 				log.Printf("[SERVER DEBUG] Emitted server error completion event for query %s", queryID)
+
+				api.captureBugReport(sessionID, errorMsg)
 			}
 		}
 
@@ -1226,7 +1522,7 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 		_ = llmProvider // Use provider variable to avoid unused variable error
 
 		// Create context with timeout for the entire streaming operation
-		streamCtx, cancel := context.WithTimeout(context.Background(), 60*3*time.Minute)
+		streamCtx, cancel := context.WithTimeout(runCtx, 60*3*time.Minute)
 		defer cancel()
 
 		// Handle orchestrator mode first to avoid unnecessary agent creation
@@ -1339,6 +1635,9 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 					Provider:       req.LLMConfig.Provider,
 					ModelID:        req.LLMConfig.ModelID,
 					FallbackModels: req.LLMConfig.FallbackModels,
+					// Per-request providers/models the fallback chain must skip
+					ExcludeProviders: req.LLMConfig.ExcludeProviders,
+					ExcludeModels:    req.LLMConfig.ExcludeModels,
 				}
 
 				// Only set cross-provider fallback if it's not nil
@@ -1397,6 +1696,9 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 				log.Printf("[TOOLS] No tool selection specified - will use ALL tools from selected servers")
 			}
 
+			// Fall back to the tools enabled for this session (persisted across restarts)
+			selectedTools = api.applySessionEnabledTools(context.Background(), sessionID, selectedTools)
+
 			// Create standardized orchestrator instance with full configuration
 			var err error
 			planOrch, err := orchtypes.NewPlannerOrchestrator(
@@ -1460,11 +1762,14 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 			}
 
 			// Store planner orchestrator for guidance injection
+			planOrch.SetCheckpointMaxBytes(api.checkpointMaxBytes)
+			planOrch.SetCostBudget(api.orchestratorCostBudget(req.CostBudgetUSD))
+			planOrch.SetStructuredOutputLLM(req.StructuredOutputLLM)
 			api.storePlannerOrchestrator(sessionID, planOrch)
 
 			// Create a cancellable context for orchestrator execution using background context
 			// This prevents the orchestrator from being cancelled when the HTTP request ends
-			orchestratorCtx, orchestratorCancel := context.WithCancel(context.Background())
+			orchestratorCtx, orchestratorCancel := context.WithCancel(runCtx)
 
 			// Store the cancel function for potential cancellation
 			api.orchestratorContextMux.Lock()
@@ -1493,7 +1798,14 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 				// Execute orchestrator flow with conversation history using cancellable context
 				// The orchestrator will automatically continue from restored state if available
 				log.Printf("[ORCHESTRATOR DEBUG] Starting orchestrator execution for query %s with workspace: %s", queryID, workspacePath)
-				result, err := planOrch.Execute(orchestratorCtx, req.Query, workspacePath, nil)
+				// Earlier turns (e.g. from before a switch out of simple mode) carry over into the plan
+				history := api.sessionConversationHistory(orchestratorCtx, sessionID)
+				var executeOptions map[string]interface{}
+				if len(history) > 0 {
+					executeOptions = map[string]interface{}{"conversationHistory": history}
+				}
+				result, err := planOrch.Execute(orchestratorCtx, req.Query, workspacePath, executeOptions)
+				api.releaseSessionResource(context.Background(), sessionID, planOrch)
 
 				// Check for orchestrator execution error
 				if err != nil {
@@ -1545,6 +1857,7 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 					}
 
 					// Update conversation history
+					api.restoreConversationHistory(context.Background(), sessionID)
 					api.conversationMux.Lock()
 					if existingHistory, exists := api.conversationHistory[sessionID]; exists {
 						// Append to existing history
@@ -1619,6 +1932,9 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 			log.Printf("[TOOLS] No tool selection specified - will use ALL tools from selected servers")
 		}
 
+		// Fall back to the tools enabled for this session (persisted across restarts)
+		selectedTools = api.applySessionEnabledTools(context.Background(), sessionID, selectedTools)
+
 		// Create new agent with streamCtx instead of r.Context()
 		agentConfig := agent.LLMAgentConfig{
 			Name:               sessionID,
@@ -1639,19 +1955,51 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 			SmartRoutingMaxTools:   20, // Enable when more than 20 tools
 			SmartRoutingMaxServers: 4,  // Enable when more than 4 servers
 
+			// Per-request cap on connected servers, prioritized by the query
+			MaxServers:           req.MaxServers,
+			ServerSelectionQuery: req.Query,
+
+			// Skip configured servers that expose identical tool sets (MCP_SERVER_DEDUP_POLICY)
+			ServerDedupPolicy: api.serverDedupPolicy,
+
+			// Recap the run in the completion event (RUN_SUMMARY_ENABLED)
+			RunSummary: api.runSummaryEnabled,
+
+			// Per-request tool usage examples in the system prompt
+			IncludeToolExamples: req.IncludeToolExamples,
+			ToolExamples:        req.ToolExamples,
+
+			// Per-request context-size model selection
+			ContextWindowModels: req.ContextWindowModels,
+
+			// Per-request inlining of referenced workspace files
+			WorkspaceFileMaxBytes: req.WorkspaceFileMaxBytes,
+
 			// Detailed LLM configuration from frontend
 			FallbackModels:        fallbackModels,
 			CrossProviderFallback: crossProviderFallback,
+			FallbackExclusions:    fallbackExclusions,
+		}
+		if req.InlineWorkspaceFiles {
+			agentConfig.WorkspaceFileRoot = api.workspaceRoot
+		}
+		if api.toolUsage != nil {
+			agentConfig.ToolUsageRecorder = api.toolUsage
+		}
+		if api.routingCache != nil {
+			agentConfig.RoutingCache = api.routingCache
 		}
 
-		// Set agent mode based on request
-		switch req.AgentMode {
-		case "simple":
+		// Set agent mode based on request, custom registered modes first
+		switch {
+		case mcpagent.IsRegisteredAgentMode(mcpagent.AgentMode(req.AgentMode)):
+			agentConfig.AgentMode = mcpagent.AgentMode(req.AgentMode)
+		case req.AgentMode == "simple":
 			agentConfig.AgentMode = mcpagent.SimpleAgent
-		case "orchestrator":
+		case req.AgentMode == "orchestrator":
 			// For orchestrator mode, we'll handle it differently
 			agentConfig.AgentMode = mcpagent.SimpleAgent // Use Simple as base for orchestrator
-		case "workflow":
+		case req.AgentMode == "workflow":
 			// For workflow mode, we'll handle it differently
 			agentConfig.AgentMode = mcpagent.SimpleAgent // Use Simple as base for workflow
 		default:
@@ -1668,6 +2016,8 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 			sendError(fmt.Sprintf("Failed to create agent: %w", err), true)
 			return
 		}
+		api.trackSessionResource(sessionID, llmAgent)
+		defer api.releaseSessionResource(context.Background(), sessionID, llmAgent)
 
 		// Add custom agent instructions based on agent mode
 		if underlyingAgent := llmAgent.GetUnderlyingAgent(); underlyingAgent != nil {
@@ -1703,6 +2053,10 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 			underlyingAgent.AddEventListener(eventObserver)
 			log.Printf("[DATABASE DEBUG] Added in-memory event observer for session %s", sessionID)
 			underlyingAgent.AddEventListener(dbEventObserver)
+			// Charge LLM tokens to the session's token budget (sessions named by X-Session-ID only)
+			if api.sessionLimits != nil && sessionID != queryID {
+				underlyingAgent.AddEventListener(&sessionTokenMeter{limits: api.sessionLimits, sessionID: sessionID})
+			}
 			log.Printf("[DATABASE DEBUG] Added database event observer for session %s", sessionID)
 		} else {
 			log.Printf("[DATABASE DEBUG] ERROR: Underlying MCP agent is nil for session %s", sessionID)
@@ -1710,6 +2064,7 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 
 		// --- BEGIN: Load conversation history and accumulate for streaming ---
 		// Load conversation history for this session
+		api.restoreConversationHistory(context.Background(), sessionID)
 		api.conversationMux.RLock()
 		history, exists := api.conversationHistory[sessionID]
 		api.conversationMux.RUnlock()
@@ -1733,7 +2088,7 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 
 		// Create a cancellable context for agent execution using background context
 		// This prevents the agent from being cancelled when the HTTP request ends
-		agentCtx, agentCancel := context.WithCancel(context.Background())
+		agentCtx, agentCancel := context.WithCancel(runCtx)
 
 		// Store the cancel function for potential cancellation
 		api.agentCancelMux.Lock()
@@ -1814,6 +2169,8 @@ func (api *StreamingAPI) handleQuery(w http.ResponseWriter, r *http.Request) {
 				}
 				api.eventStore.AddEvent(observerID, serverTimeoutEvent)
 				log.Printf("[SERVER DEBUG] Emitted server timeout completion event for query %s", queryID)
+
+				api.captureBugReport(sessionID, "context timeout")
 				return
 			default:
 			}
@@ -1875,6 +2232,18 @@ func (api *StreamingAPI) handleStopSession(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	api.stopSession(sessionID)
+
+	// Note: Conversation history and orchestrator state are preserved to allow resuming the conversation
+	// Use /api/session/clear if you want to clear conversation history
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Session stopped (conversation history and orchestrator state preserved)"))
+}
+
+// stopSession cancels the session's running agent, orchestrators and workflow, marks it stopped and
+// releases its MCP connections. Conversation history and orchestrator state are kept.
+func (api *StreamingAPI) stopSession(sessionID string) {
 	// Cancel agent execution context if it exists
 	api.agentCancelMux.Lock()
 	if cancelFunc, exists := api.agentCancelFuncs[sessionID]; exists {
@@ -1924,11 +2293,12 @@ func (api *StreamingAPI) handleStopSession(w http.ResponseWriter, r *http.Reques
 	}
 	api.workflowObjectiveMux.Unlock()
 
-	// Note: Conversation history and orchestrator state are preserved to allow resuming the conversation
-	// Use /api/session/clear if you want to clear conversation history
-
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Session stopped (conversation history and orchestrator state preserved)"))
+	// Terminate the MCP connections of the cancelled agents and orchestrators
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), sessionShutdownTimeout)
+	if released := api.shutdownSessionResources(shutdownCtx, sessionID); released > 0 {
+		log.Printf("[SESSION DEBUG] Shut down MCP connections of %d components for session %s", released, sessionID)
+	}
+	cancelShutdown()
 }
 
 // Add endpoint to clear conversation history for a session
@@ -1945,8 +2315,19 @@ func (api *StreamingAPI) handleClearSession(w http.ResponseWriter, r *http.Reque
 		delete(api.conversationHistory, sessionID)
 		log.Printf("[SESSION DEBUG] Cleared conversation history for session %s", sessionID)
 	}
+	if api.evictedHistories[sessionID] {
+		delete(api.evictedHistories, sessionID)
+		if err := api.chatDB.DeleteConversationSnapshot(r.Context(), sessionID); err != nil {
+			log.Printf("[SESSION DEBUG] Failed to delete evicted conversation history for session %s: %v", sessionID, err)
+		}
+	}
 	api.conversationMux.Unlock()
 
+	// A cleared session starts over with fresh rate-limit and token allowances
+	if api.sessionLimits != nil {
+		api.sessionLimits.forget(sessionID)
+	}
+
 	// Clear orchestrator state (removed - now stateless)
 
 	// Clear orchestrator instance (legacy removed)
@@ -1960,6 +2341,8 @@ func (api *StreamingAPI) handleClearSession(w http.ResponseWriter, r *http.Reque
 	}
 	api.workflowObjectiveMux.Unlock()
 
+	api.clearSessionTracing(sessionID)
+
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Session cleared (conversation history and orchestrator state removed)"))
 }
@@ -2143,6 +2526,42 @@ func getSessionEventsHandler(db database.Database) http.HandlerFunc {
 	}
 }
 
+// exportSessionHandler downloads a session transcript as Markdown (format=md, the default) or JSON
+func exportSessionHandler(db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		sessionID := vars["session_id"]
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = database.ExportFormatMarkdown
+		}
+
+		if _, err := db.GetChatSession(r.Context(), sessionID); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		transcript, err := db.ExportSession(r.Context(), sessionID, format)
+		if err != nil {
+			if errors.Is(err, database.ErrUnsupportedExportFormat) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		contentType := "text/markdown; charset=utf-8"
+		if format == database.ExportFormatJSON {
+			contentType = "application/json"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "session-"+sessionID+"."+format))
+		w.Write(transcript)
+	}
+}
+
 // searchEventsHandler searches events with filters
 func searchEventsHandler(db database.Database) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -2157,6 +2576,9 @@ func searchEventsHandler(db database.Database) http.HandlerFunc {
 			filter.EventType = unifiedevents.EventType(eventType)
 		}
 
+		// Content search over tool names, messages and results
+		filter.TextQuery = r.URL.Query().Get("query")
+
 		if fromDateStr := r.URL.Query().Get("from_date"); fromDateStr != "" {
 			if fromDate, err := time.Parse(time.RFC3339, fromDateStr); err == nil {
 				filter.FromDate = fromDate
@@ -2197,6 +2619,7 @@ func searchEventsHandler(db database.Database) http.HandlerFunc {
 			ToDate:    filter.ToDate,
 			Limit:     filter.Limit,
 			Offset:    filter.Offset,
+			TextQuery: filter.TextQuery,
 		}
 
 		response, err := db.GetEvents(r.Context(), req)
@@ -2248,9 +2671,44 @@ func (api *StreamingAPI) trackActiveSession(sessionID, observerID, agentMode, qu
 		Query:        query,
 	}
 
+	// New work on this observer cancels any pending prune of its event buffer
+	api.eventStore.MarkActive(observerID)
+	api.markChatSessionActive(sessionID)
+
 	log.Printf("[ACTIVE_SESSION] Tracked active session: %s (observer: %s, mode: %s)", sessionID, observerID, agentMode)
 }
 
+// finishWorkflowSession reports the outcome of a workflow run and marks its session completed or failed
+func (api *StreamingAPI) finishWorkflowSession(sessionID, observerID, queryID string, err error) {
+	if err != nil {
+		log.Printf("[WORKFLOW ERROR] Workflow execution failed for query %s: %v", queryID, err)
+		// Send error event
+		errorData := map[string]interface{}{
+			"error":    err.Error(),
+			"query_id": queryID,
+		}
+		api.eventStore.AddEvent(observerID, events.Event{
+			ID:        fmt.Sprintf("workflow_error_%s_%d", queryID, time.Now().UnixNano()),
+			Type:      "workflow_error",
+			Timestamp: time.Now(),
+			Data: &unifiedevents.AgentEvent{
+				Type:      "workflow_error",
+				Timestamp: time.Now(),
+				Data: &unifiedevents.GenericEventData{
+					Data: errorData,
+				},
+			},
+			SessionID: observerID,
+		})
+		api.updateSessionStatus(sessionID, "error")
+		return
+	}
+
+	log.Printf("[WORKFLOW DEBUG] Workflow execution completed for query %s", queryID)
+	// Workflow completion events are now handled by the workflow orchestrator itself
+	api.updateSessionStatus(sessionID, "completed")
+}
+
 // updateSessionStatus updates the status of an active session
 func (api *StreamingAPI) updateSessionStatus(sessionID, status string) {
 	api.activeSessionsMux.Lock()
@@ -2260,10 +2718,35 @@ func (api *StreamingAPI) updateSessionStatus(sessionID, status string) {
 		session.Status = status
 		session.LastActivity = time.Now()
 		log.Printf("[ACTIVE_SESSION] Updated session %s status to: %s", sessionID, status)
+
+		// Push the full event timeline to the session's export webhook, if any
+		switch status {
+		case "completed", "error", "stopped":
+			go api.exportSessionEvents(sessionID, session.ObserverID, status)
+		}
+
+		// Only failed runs keep what is needed for a bug report
+		if status == "completed" || status == "stopped" {
+			api.forgetBugReportRun(sessionID)
+		}
+
+		// Free the in-memory event buffer after a grace period for final polls
+		if status == "completed" {
+			api.eventStore.MarkCompleted(session.ObserverID)
+		}
 	} else {
 		log.Printf("[ACTIVE_SESSION] Session %s not found in activeSessions, updating database only", sessionID)
 	}
 
+	// Wake batch requests waiting for this session to finish
+	switch status {
+	case "completed", "error", "stopped":
+		api.notifySessionDone(sessionID, status)
+		if api.sessionLimits != nil {
+			api.sessionLimits.sessionEnded(sessionID)
+		}
+	}
+
 	// Always update the database, regardless of whether session is in activeSessions
 	go func() {
 		ctx := context.Background()
@@ -2321,6 +2804,9 @@ func (api *StreamingAPI) storeWorkflowOrchestrator(sessionID string, orchestrato
 	api.orchestratorMux.Lock()
 	defer api.orchestratorMux.Unlock()
 	api.workflowOrchestrators[sessionID] = orchestrator
+	if resource, ok := orchestrator.(interface{ Shutdown(context.Context) error }); ok {
+		api.trackSessionResource(sessionID, resource)
+	}
 	log.Printf("[ORCHESTRATOR] Stored workflow orchestrator for session %s", sessionID)
 }
 
@@ -2329,6 +2815,9 @@ func (api *StreamingAPI) storePlannerOrchestrator(sessionID string, orchestrator
 	api.orchestratorMux.Lock()
 	defer api.orchestratorMux.Unlock()
 	api.plannerOrchestrators[sessionID] = orchestrator
+	if resource, ok := orchestrator.(interface{ Shutdown(context.Context) error }); ok {
+		api.trackSessionResource(sessionID, resource)
+	}
 	log.Printf("[ORCHESTRATOR] Stored planner orchestrator for session %s", sessionID)
 }
 
@@ -2406,9 +2895,9 @@ func (api *StreamingAPI) handleSubmitHumanFeedback(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Get human feedback store and submit response
-	feedbackStore := virtualtools.GetHumanFeedbackStore()
-	if err := feedbackStore.SubmitResponse(req.UniqueID, req.Response); err != nil {
+	// Submit through the same API in-process embedders use
+	var feedbackStore virtualtools.HumanFeedbackStore = virtualtools.GetHumanFeedbackStore()
+	if err := feedbackStore.Submit(req.UniqueID, req.Response); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+)
+
+const defaultSessionRateLimitWindow = time.Minute
+
+// sessionLimits enforces a per-session query rate limit and LLM token budget and reports the
+// remaining allowance in response headers. A zero limit disables that check.
+type sessionLimits struct {
+	rateLimit   int           // Queries per window per session
+	window      time.Duration // Fixed rate-limit window
+	tokenBudget int           // Total LLM tokens per session
+
+	mu       sync.Mutex
+	sessions map[string]*sessionLimitState
+	now      func() time.Time // nil uses time.Now
+}
+
+type sessionLimitState struct {
+	windowStart time.Time
+	requests    int
+	tokensUsed  int
+	endedAt     time.Time // When the session's last query ended; zero while one runs
+}
+
+// sessionLimitsFromEnv reads SESSION_RATE_LIMIT, SESSION_RATE_LIMIT_WINDOW_SECONDS and
+// SESSION_TOKEN_BUDGET; returns nil when neither limit is set
+func sessionLimitsFromEnv() *sessionLimits {
+	rateLimit, _ := strconv.Atoi(os.Getenv("SESSION_RATE_LIMIT"))
+	tokenBudget, _ := strconv.Atoi(os.Getenv("SESSION_TOKEN_BUDGET"))
+	if rateLimit <= 0 && tokenBudget <= 0 {
+		return nil
+	}
+	window := defaultSessionRateLimitWindow
+	if seconds, err := strconv.Atoi(os.Getenv("SESSION_RATE_LIMIT_WINDOW_SECONDS")); err == nil && seconds > 0 {
+		window = time.Duration(seconds) * time.Second
+	}
+	return newSessionLimits(rateLimit, window, tokenBudget)
+}
+
+func newSessionLimits(rateLimit int, window time.Duration, tokenBudget int) *sessionLimits {
+	return &sessionLimits{
+		rateLimit:   rateLimit,
+		window:      window,
+		tokenBudget: tokenBudget,
+		sessions:    make(map[string]*sessionLimitState),
+	}
+}
+
+func (l *sessionLimits) currentTime() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+	return time.Now()
+}
+
+// state returns the session's limit state with its rate-limit window rolled forward; callers must hold l.mu
+func (l *sessionLimits) state(sessionID string) *sessionLimitState {
+	now := l.currentTime()
+	state, exists := l.sessions[sessionID]
+	if !exists {
+		state = &sessionLimitState{windowStart: now}
+		l.sessions[sessionID] = state
+	}
+	if now.Sub(state.windowStart) >= l.window {
+		state.windowStart = now
+		state.requests = 0
+	}
+	return state
+}
+
+// admit counts a query against the session's limits, writes the remaining allowance headers and
+// reports whether the query may run, with the reason when it may not
+func (l *sessionLimits) admit(w http.ResponseWriter, sessionID string) (bool, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.evictEndedLocked()
+	state := l.state(sessionID)
+	state.endedAt = time.Time{}
+	allowed, reason := true, ""
+	if l.tokenBudget > 0 && state.tokensUsed >= l.tokenBudget {
+		allowed, reason = false, "Session token budget exhausted"
+	} else if l.rateLimit > 0 && state.requests >= l.rateLimit {
+		allowed, reason = false, "Session rate limit exceeded"
+	}
+	if allowed {
+		state.requests++
+	}
+
+	if l.rateLimit > 0 {
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(l.rateLimit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(max(l.rateLimit-state.requests, 0)))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(state.windowStart.Add(l.window).Unix(), 10))
+	}
+	if l.tokenBudget > 0 {
+		w.Header().Set("X-Token-Budget-Remaining", strconv.Itoa(max(l.tokenBudget-state.tokensUsed, 0)))
+	}
+	return allowed, reason
+}
+
+// recordTokens charges LLM tokens used by the session against its budget
+func (l *sessionLimits) recordTokens(sessionID string, tokens int) {
+	if tokens <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.state(sessionID).tokensUsed += tokens
+}
+
+// sessionEnded marks the session's query as finished. Its entry is dropped once the rate-limit
+// window has passed without a new query, so finished sessions do not accumulate.
+func (l *sessionLimits) sessionEnded(sessionID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if state, exists := l.sessions[sessionID]; exists {
+		state.endedAt = l.currentTime()
+	}
+}
+
+// forget drops the session's entry right away, e.g. when its conversation is cleared
+func (l *sessionLimits) forget(sessionID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.sessions, sessionID)
+}
+
+// evictEndedLocked drops sessions that ended at least one rate-limit window ago; callers must hold l.mu
+func (l *sessionLimits) evictEndedLocked() {
+	now := l.currentTime()
+	for sessionID, state := range l.sessions {
+		if !state.endedAt.IsZero() && now.Sub(state.endedAt) >= l.window {
+			delete(l.sessions, sessionID)
+		}
+	}
+}
+
+// enforceSessionLimits wraps the query handler with the per-session rate limit and token budget.
+// Requests without X-Session-ID are not limited since each of them starts a new session.
+func (api *StreamingAPI) enforceSessionLimits(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Header.Get("X-Session-ID")
+		if api.sessionLimits == nil || r.Method == http.MethodOptions || sessionID == "" {
+			next(w, r)
+			return
+		}
+		if allowed, reason := api.sessionLimits.admit(w, sessionID); !allowed {
+			http.Error(w, reason, http.StatusTooManyRequests)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// sessionTokenMeter charges the tokens of each LLM generation to the session's token budget
+type sessionTokenMeter struct {
+	limits    *sessionLimits
+	sessionID string
+}
+
+func (m *sessionTokenMeter) HandleEvent(ctx context.Context, event *unifiedevents.AgentEvent) error {
+	if end, ok := event.Data.(*unifiedevents.LLMGenerationEndEvent); ok && !end.TurnSummary {
+		m.limits.recordTokens(m.sessionID, end.UsageMetrics.TotalTokens)
+	}
+	return nil
+}
+
+func (m *sessionTokenMeter) Name() string {
+	return fmt.Sprintf("session-token-meter-%s", m.sessionID)
+}
+
+// costBudgetFromEnv reads the default orchestrator run budget in USD from ORCHESTRATOR_COST_BUDGET_USD
+func costBudgetFromEnv() float64 {
+	budget, err := strconv.ParseFloat(os.Getenv("ORCHESTRATOR_COST_BUDGET_USD"), 64)
+	if err != nil || budget < 0 {
+		return 0
+	}
+	return budget
+}
+
+// orchestratorCostBudget returns the dollar budget of an orchestrator run: the request's, else the server default
+func (api *StreamingAPI) orchestratorCostBudget(requested float64) float64 {
+	if requested > 0 {
+		return requested
+	}
+	return api.defaultCostBudgetUSD
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+)
+
+func TestSessionLimitHeadersDecrementAcrossRequests(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limits := newSessionLimits(3, time.Minute, 1000)
+	limits.now = func() time.Time { return now }
+	api := &StreamingAPI{sessionLimits: limits}
+
+	handler := api.enforceSessionLimits(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	query := func(sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/query", nil)
+		req.Header.Set("X-Session-ID", sessionID)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+	meter := &sessionTokenMeter{limits: limits, sessionID: "session-1"}
+	useTokens := func(tokens int) {
+		meter.HandleEvent(context.Background(), unifiedevents.NewAgentEvent(&unifiedevents.LLMGenerationEndEvent{
+			UsageMetrics: unifiedevents.UsageMetrics{TotalTokens: tokens},
+		}))
+	}
+
+	reset := strconv.FormatInt(now.Add(time.Minute).Unix(), 10)
+	for i, want := range []struct{ rate, budget string }{{"2", "1000"}, {"1", "600"}, {"0", "350"}} {
+		rec := query("session-1")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i+1, rec.Code)
+		}
+		if got := rec.Header().Get("X-RateLimit-Remaining"); got != want.rate {
+			t.Fatalf("request %d: expected X-RateLimit-Remaining %s, got %s", i+1, want.rate, got)
+		}
+		if got := rec.Header().Get("X-Token-Budget-Remaining"); got != want.budget {
+			t.Fatalf("request %d: expected X-Token-Budget-Remaining %s, got %s", i+1, want.budget, got)
+		}
+		if got := rec.Header().Get("X-RateLimit-Reset"); got != reset {
+			t.Fatalf("request %d: expected X-RateLimit-Reset %s, got %s", i+1, reset, got)
+		}
+		useTokens([]int{400, 250, 0}[i])
+	}
+
+	// Fourth request in the window is rejected; other sessions have their own allowance
+	if rec := query("session-1"); rec.Code != http.StatusTooManyRequests || rec.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("expected 429 with no remaining requests, got %d (%s)", rec.Code, rec.Header().Get("X-RateLimit-Remaining"))
+	}
+	if rec := query("session-2"); rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Remaining") != "2" {
+		t.Fatalf("expected a fresh allowance for another session, got %d", rec.Code)
+	}
+
+	// A new window restores the rate limit but not the spent token budget
+	now = now.Add(time.Minute)
+	rec := query("session-1")
+	if rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Remaining") != "2" || rec.Header().Get("X-Token-Budget-Remaining") != "350" {
+		t.Fatalf("expected rate limit reset with budget kept, got %d %v", rec.Code, rec.Header())
+	}
+
+	useTokens(500)
+	if rec := query("session-1"); rec.Code != http.StatusTooManyRequests || rec.Header().Get("X-Token-Budget-Remaining") != "0" {
+		t.Fatalf("expected 429 once the token budget is spent, got %d (%s)", rec.Code, rec.Header().Get("X-Token-Budget-Remaining"))
+	}
+}
+
+func TestSessionTokenMeterSkipsTurnSummaries(t *testing.T) {
+	limits := newSessionLimits(0, time.Minute, 1000)
+	meter := &sessionTokenMeter{limits: limits, sessionID: "session-1"}
+
+	// One LLM call is reported per call and again in the turn summary
+	for _, turnSummary := range []bool{false, true} {
+		meter.HandleEvent(context.Background(), unifiedevents.NewAgentEvent(&unifiedevents.LLMGenerationEndEvent{
+			UsageMetrics: unifiedevents.UsageMetrics{TotalTokens: 300},
+			TurnSummary:  turnSummary,
+		}))
+	}
+	if used := limits.sessions["session-1"].tokensUsed; used != 300 {
+		t.Fatalf("expected the call charged once (300 tokens), got %d", used)
+	}
+}
+
+func TestSessionLimitsEvictEndedSessions(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limits := newSessionLimits(3, time.Minute, 1000)
+	limits.now = func() time.Time { return now }
+	admit := func(sessionID string) {
+		if allowed, reason := limits.admit(httptest.NewRecorder(), sessionID); !allowed {
+			t.Fatalf("expected %s to be admitted: %s", sessionID, reason)
+		}
+	}
+
+	admit("finished")
+	admit("resumed")
+	admit("running")
+	limits.sessionEnded("finished")
+	limits.sessionEnded("resumed")
+
+	// A query within the window keeps counting against the session
+	now = now.Add(30 * time.Second)
+	admit("resumed")
+	if limits.sessions["resumed"].requests != 2 {
+		t.Fatalf("expected the resumed session to keep its request count, got %d", limits.sessions["resumed"].requests)
+	}
+
+	now = now.Add(time.Minute)
+	admit("other")
+	if _, exists := limits.sessions["finished"]; exists {
+		t.Errorf("expected the finished session to be evicted after its window")
+	}
+	for _, sessionID := range []string{"resumed", "running", "other"} {
+		if _, exists := limits.sessions[sessionID]; !exists {
+			t.Errorf("expected %s to be kept", sessionID)
+		}
+	}
+
+	limits.forget("running")
+	if _, exists := limits.sessions["running"]; exists {
+		t.Errorf("expected a cleared session to be dropped")
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/database"
+	"mcp-agent/agent_go/pkg/mcpagent"
+)
+
+// SessionModeRequest switches the agent mode of an existing session
+type SessionModeRequest struct {
+	AgentMode string `json:"agent_mode"`
+}
+
+// SessionModeResponse reports a session's agent mode after a switch
+type SessionModeResponse struct {
+	SessionID       string `json:"session_id"`
+	PreviousMode    string `json:"previous_mode,omitempty"`
+	AgentMode       string `json:"agent_mode"`
+	HistoryMessages int    `json:"history_messages"` // Conversation messages carried over to the new mode
+}
+
+// switchableAgentModes are the modes a session can be converted to mid-session.
+// Workflow sessions are driven by presets and cannot be switched into.
+var switchableAgentModes = map[string]bool{
+	database.AgentModeSimple:       true,
+	database.AgentModeReAct:        true,
+	database.AgentModeOrchestrator: true,
+	database.AgentModeAuto:         true,
+}
+
+// resolveSessionAgentMode returns the mode for a session's next query. An explicit request mode
+// becomes the session's mode; a query without one continues in the session's current mode.
+func (api *StreamingAPI) resolveSessionAgentMode(sessionID, requested string) string {
+	api.sessionModeMux.Lock()
+	defer api.sessionModeMux.Unlock()
+
+	if requested != "" {
+		api.sessionAgentModes[sessionID] = requested
+		return requested
+	}
+	return api.sessionAgentModes[sessionID]
+}
+
+// sessionConversationHistory returns a copy of the session's conversation, restoring it if it was evicted
+func (api *StreamingAPI) sessionConversationHistory(ctx context.Context, sessionID string) []llmtypes.MessageContent {
+	api.restoreConversationHistory(ctx, sessionID)
+
+	api.conversationMux.RLock()
+	defer api.conversationMux.RUnlock()
+	history := api.conversationHistory[sessionID]
+	return append([]llmtypes.MessageContent(nil), history...)
+}
+
+// handleSwitchSessionMode converts a session to another agent mode, keeping its conversation history.
+// The agent or orchestrator for the old mode is dropped and the next query creates one for the new mode.
+func (api *StreamingAPI) handleSwitchSessionMode(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	sessionID := mux.Vars(r)["session_id"]
+	if sessionID == "" {
+		http.Error(w, "Session ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req SessionModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !switchableAgentModes[req.AgentMode] && !mcpagent.IsRegisteredAgentMode(mcpagent.AgentMode(req.AgentMode)) {
+		http.Error(w, fmt.Sprintf("Invalid agent mode %q, must be one of: simple, ReAct, orchestrator, auto", req.AgentMode), http.StatusBadRequest)
+		return
+	}
+
+	// The running agent keeps its mode; switch once the current query has finished
+	api.activeSessionsMux.Lock()
+	if session, exists := api.activeSessions[sessionID]; exists {
+		if session.Status == "running" {
+			api.activeSessionsMux.Unlock()
+			http.Error(w, "Session is running, stop it or wait for it to finish before switching mode", http.StatusConflict)
+			return
+		}
+		session.AgentMode = req.AgentMode
+		session.LastActivity = time.Now()
+	}
+	api.activeSessionsMux.Unlock()
+
+	api.sessionModeMux.Lock()
+	previousMode := api.sessionAgentModes[sessionID]
+	api.sessionAgentModes[sessionID] = req.AgentMode
+	api.sessionModeMux.Unlock()
+
+	// Recreated for the new mode on the next query
+	api.orchestratorMux.Lock()
+	delete(api.plannerOrchestrators, sessionID)
+	api.orchestratorMux.Unlock()
+
+	if api.chatDB != nil {
+		if chatSession, err := api.chatDB.GetChatSession(r.Context(), sessionID); err == nil {
+			if previousMode == "" {
+				previousMode = chatSession.AgentMode
+			}
+			presetQueryID := ""
+			if chatSession.PresetQueryID != nil {
+				presetQueryID = *chatSession.PresetQueryID
+			}
+			if _, err := api.chatDB.UpdateChatSession(r.Context(), sessionID, &database.UpdateChatSessionRequest{
+				Title:         chatSession.Title,
+				AgentMode:     req.AgentMode,
+				PresetQueryID: presetQueryID,
+				Status:        chatSession.Status,
+			}); err != nil {
+				log.Printf("[SESSION MODE] Failed to update agent mode in database for session %s: %v", sessionID, err)
+			}
+		}
+	}
+
+	history := api.sessionConversationHistory(r.Context(), sessionID)
+	log.Printf("[SESSION MODE] Switched session %s from %q to %q, carrying over %d messages", sessionID, previousMode, req.AgentMode, len(history))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SessionModeResponse{
+		SessionID:       sessionID,
+		PreviousMode:    previousMode,
+		AgentMode:       req.AgentMode,
+		HistoryMessages: len(history),
+	})
+}
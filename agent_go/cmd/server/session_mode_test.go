@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/database"
+	"mcp-agent/agent_go/pkg/orchestrator"
+)
+
+// modeSessionDB records chat session updates; other Database methods are not used
+type modeSessionDB struct {
+	database.Database
+	session *database.ChatSession
+}
+
+func (db *modeSessionDB) GetChatSession(ctx context.Context, sessionID string) (*database.ChatSession, error) {
+	return db.session, nil
+}
+
+func (db *modeSessionDB) UpdateChatSession(ctx context.Context, sessionID string, req *database.UpdateChatSessionRequest) (*database.ChatSession, error) {
+	db.session.AgentMode = req.AgentMode
+	db.session.Title = req.Title
+	return db.session, nil
+}
+
+func switchSessionMode(t *testing.T, router http.Handler, sessionID, mode string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID+"/mode", strings.NewReader(`{"agent_mode": "`+mode+`"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSwitchSessionFromSimpleToOrchestratorPreservesHistory(t *testing.T) {
+	db := &modeSessionDB{session: &database.ChatSession{SessionID: "session-1", Title: "List buckets", AgentMode: database.AgentModeSimple, Status: "completed"}}
+	api := &StreamingAPI{
+		chatDB:               db,
+		conversationHistory:  make(map[string][]llmtypes.MessageContent),
+		evictedHistories:     make(map[string]bool),
+		activeSessions:       make(map[string]*ActiveSessionInfo),
+		plannerOrchestrators: make(map[string]orchestrator.Orchestrator),
+		sessionAgentModes:    make(map[string]string),
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/sessions/{session_id}/mode", api.handleSwitchSessionMode).Methods("POST", "OPTIONS")
+
+	// A simple-mode query has run in this session
+	if mode := api.resolveSessionAgentMode("session-1", database.AgentModeSimple); mode != database.AgentModeSimple {
+		t.Fatalf("expected simple mode for the first query, got %q", mode)
+	}
+	history := []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "list my buckets"}}},
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "logs, backups"}}},
+	}
+	api.conversationHistory["session-1"] = history
+	api.activeSessions["session-1"] = &ActiveSessionInfo{SessionID: "session-1", AgentMode: database.AgentModeSimple, Status: "running", LastActivity: time.Now()}
+
+	// The running query keeps its mode
+	if rec := switchSessionMode(t, router, "session-1", database.AgentModeOrchestrator); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while the session is running, got %d", rec.Code)
+	}
+	api.activeSessions["session-1"].Status = "completed"
+
+	if rec := switchSessionMode(t, router, "session-1", database.AgentModeWorkflow); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected workflow mode to be rejected, got %d", rec.Code)
+	}
+
+	rec := switchSessionMode(t, router, "session-1", database.AgentModeOrchestrator)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp SessionModeResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.PreviousMode != database.AgentModeSimple || resp.AgentMode != database.AgentModeOrchestrator || resp.HistoryMessages != 2 {
+		t.Fatalf("unexpected switch response: %+v", resp)
+	}
+	if db.session.AgentMode != database.AgentModeOrchestrator || db.session.Title != "List buckets" {
+		t.Fatalf("expected stored session mode updated without losing its title, got %+v", db.session)
+	}
+
+	// The follow-up query runs in orchestrator mode and receives the simple-mode conversation
+	if mode := api.resolveSessionAgentMode("session-1", ""); mode != database.AgentModeOrchestrator {
+		t.Fatalf("expected follow-up query to run in orchestrator mode, got %q", mode)
+	}
+	if carried := api.sessionConversationHistory(context.Background(), "session-1"); !reflect.DeepEqual(carried, history) {
+		t.Fatalf("expected conversation history preserved across the switch, got %+v", carried)
+	}
+	if api.activeSessions["session-1"].AgentMode != database.AgentModeOrchestrator {
+		t.Fatalf("expected active session mode updated, got %q", api.activeSessions["session-1"].AgentMode)
+	}
+}
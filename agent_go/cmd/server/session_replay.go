@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/pkg/database"
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+)
+
+const (
+	replaySpeedInstant  = "instant"
+	replaySpeedRealtime = "realtime"
+
+	// replayPageSize is how many stored events are read from the database per query
+	replayPageSize = 500
+	// maxReplayGap caps the pause between two events of a realtime replay
+	maxReplayGap = 5 * time.Second
+)
+
+// SessionReplayResponse tells the client which observer to poll for the replayed events
+type SessionReplayResponse struct {
+	SessionID   string `json:"session_id"`
+	ObserverID  string `json:"observer_id"`
+	Speed       string `json:"speed"`
+	TotalEvents int    `json:"total_events"`
+}
+
+// replayedEventData carries a stored event payload unchanged, so a replayed event serializes
+// exactly like the original
+type replayedEventData struct {
+	eventType unifiedevents.EventType
+	raw       json.RawMessage
+}
+
+func (d *replayedEventData) GetEventType() unifiedevents.EventType {
+	return d.eventType
+}
+
+func (d *replayedEventData) MarshalJSON() ([]byte, error) {
+	if len(d.raw) == 0 {
+		return []byte("null"), nil
+	}
+	return d.raw, nil
+}
+
+// storedAgentEvent is an AgentEvent as StoreEvent serializes it, with its payload left undecoded
+type storedAgentEvent struct {
+	Type           unifiedevents.EventType `json:"type"`
+	Timestamp      time.Time               `json:"timestamp"`
+	EventIndex     int                     `json:"event_index"`
+	TraceID        string                  `json:"trace_id,omitempty"`
+	SpanID         string                  `json:"span_id,omitempty"`
+	ParentID       string                  `json:"parent_id,omitempty"`
+	CorrelationID  string                  `json:"correlation_id,omitempty"`
+	Data           json.RawMessage         `json:"data"`
+	HierarchyLevel int                     `json:"hierarchy_level"`
+	SessionID      string                  `json:"session_id,omitempty"`
+	Component      string                  `json:"component,omitempty"`
+}
+
+// parseReplaySpeed turns the speed query parameter into a playback factor: "instant" (the
+// default) is 0, "realtime" is 1 and a number plays the session that many times faster
+func parseReplaySpeed(speed string) (float64, error) {
+	switch speed {
+	case "", replaySpeedInstant:
+		return 0, nil
+	case replaySpeedRealtime:
+		return 1, nil
+	}
+	factor, err := strconv.ParseFloat(speed, 64)
+	if err != nil || factor <= 0 {
+		return 0, fmt.Errorf("invalid speed %q, must be %s, %s or a positive factor", speed, replaySpeedInstant, replaySpeedRealtime)
+	}
+	return factor, nil
+}
+
+// loadReplayEvents reads a session's stored events in emission order
+func loadReplayEvents(ctx context.Context, db database.Database, sessionID string) ([]*unifiedevents.AgentEvent, error) {
+	var stored []storedAgentEvent
+	for offset := 0; ; offset += replayPageSize {
+		page, err := db.GetEventsBySession(ctx, sessionID, replayPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range page {
+			var envelope storedAgentEvent
+			if err := json.Unmarshal(event.EventData, &envelope); err != nil {
+				log.Printf("[REPLAY] Skipping undecodable event %s of session %s: %v", event.ID, sessionID, err)
+				continue
+			}
+			if envelope.Type == "" {
+				envelope.Type = unifiedevents.EventType(event.EventType)
+			}
+			if envelope.Timestamp.IsZero() {
+				envelope.Timestamp = event.Timestamp
+			}
+			stored = append(stored, envelope)
+		}
+		if len(page) < replayPageSize {
+			break
+		}
+	}
+	sort.SliceStable(stored, func(i, j int) bool {
+		if !stored[i].Timestamp.Equal(stored[j].Timestamp) {
+			return stored[i].Timestamp.Before(stored[j].Timestamp)
+		}
+		return stored[i].EventIndex < stored[j].EventIndex
+	})
+
+	replayed := make([]*unifiedevents.AgentEvent, 0, len(stored))
+	for _, envelope := range stored {
+		replayed = append(replayed, &unifiedevents.AgentEvent{
+			Type:           envelope.Type,
+			Timestamp:      envelope.Timestamp,
+			EventIndex:     envelope.EventIndex,
+			TraceID:        envelope.TraceID,
+			SpanID:         envelope.SpanID,
+			ParentID:       envelope.ParentID,
+			CorrelationID:  envelope.CorrelationID,
+			Data:           &replayedEventData{eventType: envelope.Type, raw: envelope.Data},
+			HierarchyLevel: envelope.HierarchyLevel,
+			SessionID:      envelope.SessionID,
+			Component:      envelope.Component,
+		})
+	}
+	return replayed, nil
+}
+
+// replaySessionEvents re-emits stored events to the observer through the event store, pausing
+// between events by their original spacing divided by factor (0 replays instantly). No LLM or
+// MCP tool is called. The replay stops early when the observer is removed.
+func (api *StreamingAPI) replaySessionEvents(ctx context.Context, observerID, sessionID string, replayed []*unifiedevents.AgentEvent, factor float64) {
+	observer := events.NewEventObserverWithLogger(api.eventStore, observerID, sessionID, api.logger)
+	for i, event := range replayed {
+		if factor > 0 && i > 0 {
+			gap := time.Duration(float64(event.Timestamp.Sub(replayed[i-1].Timestamp)) / factor)
+			if gap > maxReplayGap {
+				gap = maxReplayGap
+			}
+			if gap > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(gap):
+				}
+			}
+		}
+		if _, exists := api.observerManager.GetObserver(observerID); !exists {
+			log.Printf("[REPLAY] Observer %s removed, stopping replay of session %s after %d events", observerID, sessionID, i)
+			return
+		}
+		observer.HandleEvent(ctx, event)
+	}
+	api.eventStore.MarkCompleted(observerID)
+	log.Printf("[REPLAY] Replayed %d events of session %s to observer %s", len(replayed), sessionID, observerID)
+}
+
+// handleReplaySession re-emits a stored session's events to a new observer so the frontend can
+// render a past run by polling it. speed is "instant" (default), "realtime" or a playback factor.
+func (api *StreamingAPI) handleReplaySession(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if api.chatDB == nil {
+		http.Error(w, "Chat history database is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	sessionID := mux.Vars(r)["session_id"]
+	speed := r.URL.Query().Get("speed")
+	factor, err := parseReplaySpeed(speed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if speed == "" {
+		speed = replaySpeedInstant
+	}
+
+	if _, err := api.chatDB.GetChatSession(r.Context(), sessionID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	replayed, err := loadReplayEvents(r.Context(), api.chatDB, sessionID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load session events: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	observer := api.observerManager.RegisterObserver(sessionID)
+	go api.replaySessionEvents(context.Background(), observer.ID, sessionID, replayed, factor)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SessionReplayResponse{
+		SessionID:   sessionID,
+		ObserverID:  observer.ID,
+		Speed:       speed,
+		TotalEvents: len(replayed),
+	})
+}
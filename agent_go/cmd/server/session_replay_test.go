@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"mcp-agent/agent_go/internal/events"
+	"mcp-agent/agent_go/pkg/database"
+	unifiedevents "mcp-agent/agent_go/pkg/events"
+)
+
+// replayDB serves a stored chat session and its events; other Database methods are not used
+type replayDB struct {
+	sessionEventsDB
+}
+
+func (db *replayDB) GetChatSession(ctx context.Context, sessionID string) (*database.ChatSession, error) {
+	if _, exists := db.events[sessionID]; !exists {
+		return nil, fmt.Errorf("chat session not found: %s", sessionID)
+	}
+	return &database.ChatSession{SessionID: sessionID, Status: "completed"}, nil
+}
+
+// storeReplayEvents serializes agent events the way StoreEvent does, returned out of order to
+// check the replay restores emission order
+func storeReplayEvents(t *testing.T, agentEvents []*unifiedevents.AgentEvent) []database.Event {
+	t.Helper()
+	stored := make([]database.Event, len(agentEvents))
+	for i, event := range agentEvents {
+		data, err := json.Marshal(event)
+		if err != nil {
+			t.Fatalf("marshal event: %v", err)
+		}
+		stored[len(agentEvents)-1-i] = database.Event{
+			ID: fmt.Sprintf("event-%d", i), SessionID: "session-1", EventType: string(event.Type), Timestamp: event.Timestamp, EventData: data,
+		}
+	}
+	return stored
+}
+
+func newReplayTestAPI(t *testing.T) (*StreamingAPI, []*unifiedevents.AgentEvent) {
+	t.Helper()
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	original := []*unifiedevents.AgentEvent{
+		{Type: unifiedevents.UserMessageEventType, Timestamp: start, EventIndex: 0, SessionID: "session-1", Component: "agent",
+			Data: &unifiedevents.UserMessageEvent{BaseEventData: unifiedevents.BaseEventData{Timestamp: start}, Turn: 1, Content: "list my buckets", Role: "user"}},
+		{Type: unifiedevents.ToolCallStart, Timestamp: start.Add(20 * time.Millisecond), EventIndex: 1, SessionID: "session-1", HierarchyLevel: 1, Component: "tool",
+			Data: unifiedevents.NewToolCallStartEvent(1, "list_buckets", unifiedevents.ToolParams{Arguments: `{"region":"eu-west-1"}`}, "aws", "span-1")},
+		{Type: unifiedevents.ToolCallEnd, Timestamp: start.Add(40 * time.Millisecond), EventIndex: 2, SessionID: "session-1", HierarchyLevel: 1, Component: "tool",
+			Data: unifiedevents.NewToolCallEndEvent(1, "list_buckets", "bucket-a, bucket-b", "aws", 20*time.Millisecond, "span-1")},
+	}
+
+	eventStore := events.NewEventStore(1000)
+	t.Cleanup(eventStore.Stop)
+	db := &replayDB{sessionEventsDB{events: map[string][]database.Event{"session-1": storeReplayEvents(t, original)}}}
+	return &StreamingAPI{eventStore: eventStore, observerManager: events.NewObserverManager(eventStore), chatDB: db}, original
+}
+
+func startReplay(t *testing.T, api *StreamingAPI, sessionID, speed string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/chat-history/sessions/"+sessionID+"/replay?speed="+speed, nil)
+	req = mux.SetURLVars(req, map[string]string{"session_id": sessionID})
+	rec := httptest.NewRecorder()
+	api.handleReplaySession(rec, req)
+	return rec
+}
+
+// waitForReplay polls the observer until count events arrived
+func waitForReplay(t *testing.T, api *StreamingAPI, observerID string, count int) []events.Event {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		replayed, _, _ := api.eventStore.GetEvents(observerID, -1)
+		if len(replayed) >= count {
+			return replayed
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d replayed events, got %d", count, len(replayed))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReplaySessionReemitsStoredEventsInOrder(t *testing.T) {
+	api, original := newReplayTestAPI(t)
+
+	rec := startReplay(t, api, "session-1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp SessionReplayResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.TotalEvents != len(original) || resp.Speed != replaySpeedInstant || resp.ObserverID == "" {
+		t.Fatalf("unexpected replay response %+v", resp)
+	}
+
+	replayed := waitForReplay(t, api, resp.ObserverID, len(original))
+	if len(replayed) != len(original) {
+		t.Fatalf("expected %d replayed events, got %d", len(original), len(replayed))
+	}
+	for i, event := range replayed {
+		want, _ := json.Marshal(original[i])
+		got, _ := json.Marshal(event.Data)
+		if event.Type != string(original[i].Type) || string(got) != string(want) {
+			t.Fatalf("replayed event %d differs:\n got %s\nwant %s", i, got, want)
+		}
+	}
+}
+
+func TestReplaySessionRealtimeKeepsOriginalSpacing(t *testing.T) {
+	api, original := newReplayTestAPI(t)
+
+	started := time.Now()
+	rec := startReplay(t, api, "session-1", replaySpeedRealtime)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp SessionReplayResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+
+	waitForReplay(t, api, resp.ObserverID, len(original))
+	if elapsed := time.Since(started); elapsed < 40*time.Millisecond {
+		t.Fatalf("expected a realtime replay to take the original 40ms, took %s", elapsed)
+	}
+}
+
+func TestReplaySessionRejectsUnknownSessionAndBadSpeed(t *testing.T) {
+	api, _ := newReplayTestAPI(t)
+
+	if rec := startReplay(t, api, "session-2", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown session, got %d", rec.Code)
+	}
+	if rec := startReplay(t, api, "session-1", "warp"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid speed, got %d", rec.Code)
+	}
+	if factor, err := parseReplaySpeed("4"); err != nil || factor != 4 {
+		t.Fatalf("expected a numeric speed factor, got %v, %v", factor, err)
+	}
+}
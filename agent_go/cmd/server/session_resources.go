@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"mcp-agent/agent_go/pkg/orchestrator"
+)
+
+// sessionShutdownTimeout bounds how long stopping a session waits for its MCP connections to close
+const sessionShutdownTimeout = 10 * time.Second
+
+// trackSessionResource registers a component holding MCP connections for a session (agent wrapper,
+// orchestrator) so that stopping the session terminates them instead of leaving orphaned servers
+func (api *StreamingAPI) trackSessionResource(sessionID string, resource orchestrator.Shutdowner) {
+	if resource == nil {
+		return
+	}
+	api.sessionResourcesMux.Lock()
+	defer api.sessionResourcesMux.Unlock()
+	api.sessionResources[sessionID] = append(api.sessionResources[sessionID], resource)
+}
+
+// releaseSessionResource shuts a resource down once its run is over and stops tracking it. It is a
+// no-op when the session was stopped in the meantime, since the stop already shut it down.
+func (api *StreamingAPI) releaseSessionResource(ctx context.Context, sessionID string, resource orchestrator.Shutdowner) {
+	api.sessionResourcesMux.Lock()
+	resources := api.sessionResources[sessionID]
+	found := false
+	for i, tracked := range resources {
+		if tracked == resource {
+			resources = append(resources[:i:i], resources[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if len(resources) == 0 {
+		delete(api.sessionResources, sessionID)
+	} else {
+		api.sessionResources[sessionID] = resources
+	}
+	api.sessionResourcesMux.Unlock()
+
+	if !found {
+		return
+	}
+	if err := resource.Shutdown(ctx); err != nil {
+		log.Printf("[SESSION DEBUG] Failed to release MCP connections for session %s: %v", sessionID, err)
+	}
+}
+
+// shutdownSessionResources terminates the MCP connections of everything tracked for the session and
+// returns how many resources were shut down
+func (api *StreamingAPI) shutdownSessionResources(ctx context.Context, sessionID string) int {
+	api.sessionResourcesMux.Lock()
+	resources := api.sessionResources[sessionID]
+	delete(api.sessionResources, sessionID)
+	api.sessionResourcesMux.Unlock()
+
+	for _, resource := range resources {
+		if err := resource.Shutdown(ctx); err != nil {
+			log.Printf("[SESSION DEBUG] Failed to shut down MCP connections for session %s: %v", sessionID, err)
+		}
+	}
+	return len(resources)
+}
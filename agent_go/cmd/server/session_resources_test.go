@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"mcp-agent/agent_go/pkg/database"
+	"mcp-agent/agent_go/pkg/logger"
+	"mcp-agent/agent_go/pkg/mcpclient"
+	"mcp-agent/agent_go/pkg/orchestrator"
+)
+
+// sessionAgent stands in for an agent wrapper holding MCP connections
+type sessionAgent struct {
+	clients []*mcpclient.Client
+}
+
+func (a *sessionAgent) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, client := range a.clients {
+		errs = append(errs, client.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// stoppedSessionDB accepts the status update of a stopped session; other Database methods are not used
+type stoppedSessionDB struct {
+	database.Database
+}
+
+func (db *stoppedSessionDB) UpdateChatSession(ctx context.Context, sessionID string, req *database.UpdateChatSessionRequest) (*database.ChatSession, error) {
+	return &database.ChatSession{SessionID: sessionID, Status: req.Status}, nil
+}
+
+func TestStopSessionLeavesNoLiveMCPConnections(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+	mcpServer := mcpserver.NewMCPServer("leak", "1.0.0", mcpserver.WithToolCapabilities(false))
+	mcpServer.AddTool(mcp.NewTool("echo", mcp.WithDescription("mock tool")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	ts := mcpserver.NewTestStreamableHTTPServer(mcpServer)
+	defer ts.Close()
+
+	api := &StreamingAPI{chatDB: &stoppedSessionDB{}, sessionResources: make(map[string][]orchestrator.Shutdowner)}
+	baseline := mcpclient.LiveConnections()
+
+	const sessions = 3
+	for i := 0; i < sessions; i++ {
+		agent := &sessionAgent{}
+		for j := 0; j < 2; j++ {
+			client := mcpclient.New(mcpclient.MCPServerConfig{URL: ts.URL + "/mcp", Protocol: mcpclient.ProtocolHTTP}, testLogger)
+			if err := client.Connect(context.Background()); err != nil {
+				t.Fatalf("connect failed: %v", err)
+			}
+			agent.clients = append(agent.clients, client)
+		}
+		api.trackSessionResource(fmt.Sprintf("session-%d", i), agent)
+	}
+	if got := mcpclient.LiveConnections(); got != baseline+sessions*2 {
+		t.Fatalf("expected %d live connections, got %d", baseline+sessions*2, got)
+	}
+
+	for i := 0; i < sessions; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/session/stop", nil)
+		req.Header.Set("X-Session-ID", fmt.Sprintf("session-%d", i))
+		rec := httptest.NewRecorder()
+		api.handleStopSession(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("stop session %d: status %d", i, rec.Code)
+		}
+	}
+
+	if got := mcpclient.LiveConnections(); got != baseline {
+		t.Fatalf("expected %d live connections after stopping all sessions, got %d", baseline, got)
+	}
+	if len(api.sessionResources) != 0 {
+		t.Fatalf("expected no tracked resources, got %v", api.sessionResources)
+	}
+}
+
+func TestReleaseSessionResourceAfterStopIsNoop(t *testing.T) {
+	api := &StreamingAPI{sessionResources: make(map[string][]orchestrator.Shutdowner)}
+	resource := &countingShutdowner{}
+	api.trackSessionResource("s", resource)
+
+	if n := api.shutdownSessionResources(context.Background(), "s"); n != 1 {
+		t.Fatalf("expected 1 resource shut down, got %d", n)
+	}
+	api.releaseSessionResource(context.Background(), "s", resource)
+	if resource.calls != 1 {
+		t.Fatalf("expected a single shutdown, got %d", resource.calls)
+	}
+}
+
+type countingShutdowner struct{ calls int }
+
+func (c *countingShutdowner) Shutdown(ctx context.Context) error {
+	c.calls++
+	return nil
+}
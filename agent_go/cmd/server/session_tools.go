@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// SessionEnabledToolsRequest replaces the tools enabled for a session
+type SessionEnabledToolsRequest struct {
+	Enabled []string `json:"enabled_tools"`
+}
+
+// SessionEnabledToolsResponse reports the tools enabled for a session.
+// An empty list means all tools of the selected servers are enabled.
+type SessionEnabledToolsResponse struct {
+	SessionID string   `json:"session_id"`
+	Enabled   []string `json:"enabled_tools"`
+}
+
+// sessionEnabledTools returns the tools enabled for a session, restoring the persisted selection
+// after a restart. It returns nil when the session never had a selection.
+func (api *StreamingAPI) sessionEnabledTools(ctx context.Context, sessionID string) []string {
+	api.toolStatusMux.RLock()
+	enabled, exists := api.enabledTools[sessionID]
+	api.toolStatusMux.RUnlock()
+	if exists {
+		return append([]string{}, enabled...)
+	}
+	if api.chatDB == nil {
+		return nil
+	}
+
+	stored, err := api.chatDB.GetSessionEnabledTools(ctx, sessionID)
+	if err != nil {
+		log.Printf("[TOOLS] Failed to restore enabled tools for session %s: %v", sessionID, err)
+		return nil
+	}
+	if stored == nil {
+		return nil
+	}
+
+	api.toolStatusMux.Lock()
+	if _, exists := api.enabledTools[sessionID]; !exists {
+		api.enabledTools[sessionID] = stored
+	}
+	api.toolStatusMux.Unlock()
+	log.Printf("[TOOLS] Restored %d enabled tools for session %s", len(stored), sessionID)
+	return append([]string{}, stored...)
+}
+
+// setSessionEnabledTools records the tools enabled for a session in memory and in the database
+func (api *StreamingAPI) setSessionEnabledTools(ctx context.Context, sessionID string, enabled []string) error {
+	if enabled == nil {
+		enabled = []string{}
+	}
+
+	api.toolStatusMux.Lock()
+	api.enabledTools[sessionID] = enabled
+	api.toolStatusMux.Unlock()
+
+	if api.chatDB == nil {
+		return nil
+	}
+	if err := api.chatDB.SaveSessionEnabledTools(ctx, sessionID, enabled); err != nil {
+		return fmt.Errorf("failed to persist enabled tools: %w", err)
+	}
+	return nil
+}
+
+// applySessionEnabledTools falls back to the session's enabled tools when neither the preset nor
+// the request selected specific tools
+func (api *StreamingAPI) applySessionEnabledTools(ctx context.Context, sessionID string, selectedTools []string) []string {
+	if len(selectedTools) > 0 || sessionID == "" {
+		return selectedTools
+	}
+	if enabled := api.sessionEnabledTools(ctx, sessionID); len(enabled) > 0 {
+		log.Printf("[TOOLS] Using %d enabled tools of session %s", len(enabled), sessionID)
+		return enabled
+	}
+	return selectedTools
+}
+
+// handleSessionEnabledTools reads (GET) or replaces (PUT) the persisted tool selection of a session
+func (api *StreamingAPI) handleSessionEnabledTools(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	sessionID := mux.Vars(r)["session_id"]
+	if sessionID == "" {
+		http.Error(w, "Session ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		var req SessionEnabledToolsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := api.setSessionEnabledTools(r.Context(), sessionID, req.Enabled); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	enabled := api.sessionEnabledTools(r.Context(), sessionID)
+	if enabled == nil {
+		enabled = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SessionEnabledToolsResponse{SessionID: sessionID, Enabled: enabled})
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"mcp-agent/agent_go/pkg/database"
+)
+
+// enabledToolsDB stores enabled tool selections in memory; other Database methods are not used
+type enabledToolsDB struct {
+	database.Database
+	mu    sync.Mutex
+	tools map[string][]string
+}
+
+func (db *enabledToolsDB) SaveSessionEnabledTools(ctx context.Context, sessionID string, enabledTools []string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.tools[sessionID] = append([]string{}, enabledTools...)
+	return nil
+}
+
+func (db *enabledToolsDB) GetSessionEnabledTools(ctx context.Context, sessionID string) ([]string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	tools, exists := db.tools[sessionID]
+	if !exists {
+		return nil, nil
+	}
+	return append([]string{}, tools...), nil
+}
+
+func newEnabledToolsTestAPI(db database.Database) (*StreamingAPI, http.Handler) {
+	api := &StreamingAPI{
+		chatDB:       db,
+		enabledTools: make(map[string][]string),
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/sessions/{session_id}/enabled-tools", api.handleSessionEnabledTools).Methods("GET", "PUT", "OPTIONS")
+	return api, router
+}
+
+func TestSessionEnabledToolsSurviveRestart(t *testing.T) {
+	db := &enabledToolsDB{tools: make(map[string][]string)}
+	_, router := newEnabledToolsTestAPI(db)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/sessions/session-1/enabled-tools",
+		strings.NewReader(`{"enabled_tools": ["aws:list_buckets", "github:get_issue"]}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// A new StreamingAPI on the same database stands in for a server restart
+	restarted, restartedRouter := newEnabledToolsTestAPI(db)
+
+	rec = httptest.NewRecorder()
+	restartedRouter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions/session-1/enabled-tools", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp SessionEnabledToolsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expected := []string{"aws:list_buckets", "github:get_issue"}
+	if resp.SessionID != "session-1" || !reflect.DeepEqual(resp.Enabled, expected) {
+		t.Fatalf("expected restored selection %v, got %+v", expected, resp)
+	}
+
+	// The restored selection is what the session's next agent is built with
+	ctx := context.Background()
+	if got := restarted.applySessionEnabledTools(ctx, "session-1", nil); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected the agent to get the restored tools %v, got %v", expected, got)
+	}
+	// A preset or request selection still takes precedence
+	if got := restarted.applySessionEnabledTools(ctx, "session-1", []string{"aws:get_object"}); !reflect.DeepEqual(got, []string{"aws:get_object"}) {
+		t.Fatalf("expected the request selection to win, got %v", got)
+	}
+	// Sessions without a selection keep all tools
+	if got := restarted.applySessionEnabledTools(ctx, "session-2", nil); got != nil {
+		t.Fatalf("expected no selection for an unknown session, got %v", got)
+	}
+}
+
+func TestClearingSessionEnabledToolsRestoresAllTools(t *testing.T) {
+	db := &enabledToolsDB{tools: make(map[string][]string)}
+	api, router := newEnabledToolsTestAPI(db)
+	ctx := context.Background()
+
+	if err := api.setSessionEnabledTools(ctx, "session-1", []string{"aws:list_buckets"}); err != nil {
+		t.Fatalf("setSessionEnabledTools: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/sessions/session-1/enabled-tools", strings.NewReader(`{"enabled_tools": []}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	restarted, _ := newEnabledToolsTestAPI(db)
+	if got := restarted.applySessionEnabledTools(ctx, "session-1", nil); len(got) != 0 {
+		t.Fatalf("expected a cleared selection to enable all tools, got %v", got)
+	}
+}
@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
+	"slices"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
 	"mcp-agent/agent_go/internal/llmtypes"
+	mcpagent "mcp-agent/agent_go/pkg/mcpagent"
 	"mcp-agent/agent_go/pkg/mcpcache"
 	"mcp-agent/agent_go/pkg/mcpclient"
 )
@@ -161,6 +165,95 @@ func (api *StreamingAPI) discoverServerToolsDetailed(ctx context.Context, server
 
 // --- TOOL MANAGEMENT API HANDLERS ---
 
+// handleGetDiscoveryMetrics returns per-server MCP discovery timing rollups
+func (api *StreamingAPI) handleGetDiscoveryMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled": mcpclient.DiscoveryMetricsEnabled(),
+		"servers": mcpclient.GetDiscoveryMetrics().Snapshot(),
+	})
+}
+
+// toolUsageRollupFromEnv enables unused tool analytics when TOOL_USAGE_ANALYTICS is "true"
+func toolUsageRollupFromEnv() *mcpagent.ToolUsageRollup {
+	if os.Getenv("TOOL_USAGE_ANALYTICS") != "true" {
+		return nil
+	}
+	return mcpagent.NewToolUsageRollup()
+}
+
+// routingCacheFromEnv returns the smart routing decision cache when ROUTING_CACHE_TTL_SECONDS is set.
+// ROUTING_CACHE_MIN_CONFIDENCE sets the confidence decisions need to be reused, ROUTING_CACHE_MAX_ENTRIES
+// bounds the cache size and ROUTING_CACHE_PATH persists the decisions to a JSON file across restarts.
+func routingCacheFromEnv() *mcpagent.RoutingCache {
+	ttlSeconds, err := strconv.Atoi(os.Getenv("ROUTING_CACHE_TTL_SECONDS"))
+	if err != nil || ttlSeconds <= 0 {
+		return nil
+	}
+	minConfidence, _ := strconv.ParseFloat(os.Getenv("ROUTING_CACHE_MIN_CONFIDENCE"), 64)
+	cache, err := mcpagent.NewRoutingCache(time.Duration(ttlSeconds)*time.Second, minConfidence, os.Getenv("ROUTING_CACHE_PATH"))
+	if err != nil {
+		log.Printf("[ROUTING CACHE] Disabled: %v", err)
+		return nil
+	}
+	if maxEntries, err := strconv.Atoi(os.Getenv("ROUTING_CACHE_MAX_ENTRIES")); err == nil {
+		cache.SetMaxEntries(maxEntries)
+	}
+	log.Printf("[ROUTING CACHE] Reusing smart routing decisions for %ds (%d cached)", ttlSeconds, cache.Len())
+	return cache
+}
+
+// invalidateRoutingCache drops the cached smart routing decisions made against serverName's tools
+func (api *StreamingAPI) invalidateRoutingCache(serverName string) {
+	if api.routingCache == nil {
+		return
+	}
+	if removed := api.routingCache.InvalidateServer(serverName); removed > 0 {
+		api.logger.Infof("🎯 Invalidated %d cached routing decisions for server: %s", removed, serverName)
+	}
+}
+
+// storeToolStatus updates the in-memory tool status of serverName and invalidates cached routing
+// decisions when its tool set changed
+func (api *StreamingAPI) storeToolStatus(serverName string, status ToolStatus) {
+	api.toolStatusMux.Lock()
+	previous, existed := api.toolStatus[serverName]
+	api.toolStatus[serverName] = status
+	api.toolStatusMux.Unlock()
+
+	if existed && !slices.Equal(toolStatusNames(previous), toolStatusNames(status)) {
+		api.invalidateRoutingCache(serverName)
+	}
+}
+
+// toolStatusNames returns the sorted tool names of a server
+func toolStatusNames(status ToolStatus) []string {
+	names := slices.Clone(status.FunctionNames)
+	if len(names) == 0 {
+		for _, tool := range status.Tools {
+			names = append(names, tool.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handleGetToolUsage returns the rollup of advertised vs invoked tools, including the
+// tools that were advertised but never used
+func (api *StreamingAPI) handleGetToolUsage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if api.toolUsage == nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"enabled": false})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled": true,
+		"runs":    api.toolUsage.Runs(),
+		"unused":  api.toolUsage.UnusedTools(),
+		"tools":   api.toolUsage.Stats(),
+	})
+}
+
 // handleGetTools handles GET requests to retrieve all tools
 func (api *StreamingAPI) handleGetTools(w http.ResponseWriter, r *http.Request) {
 	// Return cached results immediately if available
@@ -284,9 +377,7 @@ func (api *StreamingAPI) handleGetToolDetail(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Also update in-memory cache for immediate API responses
-	api.toolStatusMux.Lock()
-	api.toolStatus[serverName] = *result
-	api.toolStatusMux.Unlock()
+	api.storeToolStatus(serverName, *result)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
@@ -303,9 +394,10 @@ func (api *StreamingAPI) handleSetEnabledTools(w http.ResponseWriter, r *http.Re
 		http.Error(w, "Missing query_id", http.StatusBadRequest)
 		return
 	}
-	api.toolStatusMux.Lock()
-	api.enabledTools[req.QueryID] = req.Enabled
-	api.toolStatusMux.Unlock()
+	if err := api.setSessionEnabledTools(r.Context(), req.QueryID, req.Enabled); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
 }
@@ -321,6 +413,7 @@ func (api *StreamingAPI) handleAddServer(w http.ResponseWriter, r *http.Request)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	api.invalidateRoutingCache(req.Name)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
 }
@@ -336,6 +429,7 @@ func (api *StreamingAPI) handleEditServer(w http.ResponseWriter, r *http.Request
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	api.invalidateRoutingCache(req.Name)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
 }
@@ -351,6 +445,7 @@ func (api *StreamingAPI) handleRemoveServer(w http.ResponseWriter, r *http.Reque
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	api.invalidateRoutingCache(req.Name)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
 }
@@ -607,9 +702,7 @@ func (api *StreamingAPI) runBackgroundDiscovery() {
 		}
 
 		// Update in-memory cache for immediate API responses
-		api.toolStatusMux.Lock()
-		api.toolStatus[serverName] = *result
-		api.toolStatusMux.Unlock()
+		api.storeToolStatus(serverName, *result)
 
 		discoveredServers++
 	}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"mcp-agent/agent_go/internal/observability"
+)
+
+// TracingOverride sends a session's traces to a different destination than the server's TRACING_PROVIDER.
+// It applies to the request that sets it and to later queries in the same session.
+type TracingOverride struct {
+	Provider  string `json:"provider"`             // "langfuse" or "noop"
+	Host      string `json:"host,omitempty"`       // Langfuse host; empty uses Langfuse cloud
+	PublicKey string `json:"public_key,omitempty"` // Langfuse public key
+	SecretKey string `json:"secret_key,omitempty"` // Langfuse secret key
+}
+
+func (o *TracingOverride) destination() observability.TracingDestination {
+	return observability.TracingDestination{
+		Provider:  o.Provider,
+		Host:      o.Host,
+		PublicKey: o.PublicKey,
+		SecretKey: o.SecretKey,
+	}
+}
+
+// tracingOverrideError is returned for an override that is disabled, invalid or fails to authenticate
+type tracingOverrideError struct {
+	disabled bool
+	err      error
+}
+
+func (e *tracingOverrideError) Error() string {
+	if e.disabled {
+		return "tracing overrides are disabled on this server (set TRACING_OVERRIDE_ENABLED=true)"
+	}
+	return fmt.Sprintf("invalid tracing override: %v", e.err)
+}
+
+// defaultTracer returns the server-wide tracer configured by TRACING_PROVIDER
+func defaultTracer() observability.Tracer {
+	tracingProvider := os.Getenv("TRACING_PROVIDER")
+	if tracingProvider == "" {
+		tracingProvider = "noop"
+	}
+	return observability.GetTracer(tracingProvider)
+}
+
+// selectTracer returns the tracer for a query. A request override is validated and remembered for the
+// session; a query without one uses the session's earlier override, or the server default.
+func (api *StreamingAPI) selectTracer(sessionID string, override *TracingOverride) (observability.Tracer, error) {
+	if override != nil {
+		if !api.tracingOverrideEnabled {
+			return nil, &tracingOverrideError{disabled: true}
+		}
+		tracer, err := observability.GetTracerForDestination(override.destination(), api.logger)
+		if err != nil {
+			return nil, &tracingOverrideError{err: err}
+		}
+		if sessionID != "" {
+			api.sessionTracingMux.Lock()
+			api.sessionTracing[sessionID] = override
+			api.sessionTracingMux.Unlock()
+		}
+		log.Printf("[TRACING] Session %s traces to %s override (host: %q)", sessionID, override.Provider, override.Host)
+		return tracer, nil
+	}
+
+	api.sessionTracingMux.Lock()
+	stored := api.sessionTracing[sessionID]
+	api.sessionTracingMux.Unlock()
+	if stored != nil {
+		tracer, err := observability.GetTracerForDestination(stored.destination(), api.logger)
+		if err == nil {
+			return tracer, nil
+		}
+		log.Printf("[TRACING] Session %s tracing override unavailable, using server default: %v", sessionID, err)
+	}
+	return defaultTracer(), nil
+}
+
+// clearSessionTracing forgets the session's tracing override
+func (api *StreamingAPI) clearSessionTracing(sessionID string) {
+	api.sessionTracingMux.Lock()
+	defer api.sessionTracingMux.Unlock()
+	delete(api.sessionTracing, sessionID)
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/observability"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+// fakeLangfuse accepts one set of credentials and records the trace names it ingests
+type fakeLangfuse struct {
+	publicKey string
+	mu        sync.Mutex
+	traces    []string
+}
+
+func (f *fakeLangfuse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, _, ok := r.BasicAuth(); !ok || user != f.publicKey {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.URL.Path != "/api/public/ingestion" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	var payload struct {
+		Batch []struct {
+			Type string `json:"type"`
+			Body struct {
+				Name string `json:"name"`
+			} `json:"body"`
+		} `json:"batch"`
+	}
+	json.NewDecoder(r.Body).Decode(&payload)
+	f.mu.Lock()
+	for _, event := range payload.Batch {
+		if event.Type == "trace-create" {
+			f.traces = append(f.traces, event.Body.Name)
+		}
+	}
+	f.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (f *fakeLangfuse) traceNames() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.traces...)
+}
+
+func newTracingTestAPI(t *testing.T) *StreamingAPI {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+	t.Setenv("TRACING_PROVIDER", "")
+	return &StreamingAPI{logger: testLogger, tracingOverrideEnabled: true, sessionTracing: make(map[string]*TracingOverride)}
+}
+
+func TestTracingOverrideRoutesSessionSpansToDestination(t *testing.T) {
+	tenant := &fakeLangfuse{publicKey: "pk-tenant-a"}
+	server := httptest.NewServer(tenant)
+	defer server.Close()
+
+	api := newTracingTestAPI(t)
+	override := &TracingOverride{Provider: "langfuse", Host: server.URL, PublicKey: "pk-tenant-a", SecretKey: "sk-tenant-a"}
+	tracer, err := api.selectTracer("tenant-session", override)
+	if err != nil {
+		t.Fatalf("expected override accepted, got %v", err)
+	}
+	tracer.StartTrace("agent-conversation: tenant-session", map[string]interface{}{"query": "list buckets"})
+
+	// Follow-up queries in the session keep tracing to the override
+	followUp, err := api.selectTracer("tenant-session", nil)
+	if err != nil || followUp != tracer {
+		t.Fatalf("expected follow-up query to reuse the session's tracer, got %v (err %v)", followUp, err)
+	}
+	followUp.StartTrace("agent-conversation: tenant-session follow-up", nil)
+
+	// Other sessions use the server default
+	if other, _ := api.selectTracer("other-session", nil); other != (observability.NoopTracer{}) {
+		t.Fatalf("expected other sessions on the default noop tracer, got %T", other)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(tenant.traceNames()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	names := tenant.traceNames()
+	if len(names) != 2 || names[0] != "agent-conversation: tenant-session" {
+		t.Fatalf("expected both session traces at the override destination, got %v", names)
+	}
+
+	api.clearSessionTracing("tenant-session")
+	if cleared, _ := api.selectTracer("tenant-session", nil); cleared != (observability.NoopTracer{}) {
+		t.Fatalf("expected cleared session back on the default tracer, got %T", cleared)
+	}
+}
+
+func TestTracingOverrideValidation(t *testing.T) {
+	tenant := &fakeLangfuse{publicKey: "pk-tenant-a"}
+	server := httptest.NewServer(tenant)
+	defer server.Close()
+
+	api := newTracingTestAPI(t)
+	invalid := map[string]*TracingOverride{
+		"unknown provider":  {Provider: "jaeger"},
+		"missing keys":      {Provider: "langfuse", Host: server.URL},
+		"bad host":          {Provider: "langfuse", Host: "ftp://traces", PublicKey: "pk", SecretKey: "sk"},
+		"wrong credentials": {Provider: "langfuse", Host: server.URL, PublicKey: "pk-other", SecretKey: "sk"},
+	}
+	for name, override := range invalid {
+		if _, err := api.selectTracer("session-1", override); err == nil {
+			t.Errorf("%s: expected override rejected", name)
+		}
+	}
+	if len(api.sessionTracing) != 0 {
+		t.Fatalf("expected rejected overrides not remembered, got %v", api.sessionTracing)
+	}
+
+	// handleQuery rejects the request before running anything
+	body := `{"query": "hi", "tracing": {"provider": "langfuse", "host": "` + server.URL + `"}}`
+	rec := httptest.NewRecorder()
+	api.handleQuery(rec, httptest.NewRequest(http.MethodPost, "/api/query", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid override, got %d", rec.Code)
+	}
+
+	api.tracingOverrideEnabled = false
+	rec = httptest.NewRecorder()
+	body = `{"query": "hi", "tracing": {"provider": "noop"}}`
+	api.handleQuery(rec, httptest.NewRequest(http.MethodPost, "/api/query", strings.NewReader(body)))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 when overrides are disabled, got %d", rec.Code)
+	}
+}
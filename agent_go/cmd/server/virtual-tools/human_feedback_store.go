@@ -2,7 +2,9 @@ package virtualtools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -16,32 +18,78 @@ type HumanFeedbackRequest struct {
 	CreatedAt      time.Time
 }
 
-// HumanFeedbackStore manages interactive feedback requests
-type HumanFeedbackStore struct {
-	requests map[string]*HumanFeedbackRequest
-	waiters  map[string]chan string
-	mu       sync.RWMutex
+// PendingRequest is a feedback request still waiting for a human response
+type PendingRequest struct {
+	UniqueID       string    `json:"unique_id"`
+	MessageForUser string    `json:"message_for_user"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
+// HumanFeedbackStore is the programmatic API for answering feedback requests, for embedders that
+// run agents and orchestrators in-process and build their own UI instead of using
+// /api/human-feedback/submit (which delegates to the same store)
+type HumanFeedbackStore interface {
+	// Submit answers the request with uniqueID. A response submitted before the request is
+	// created is kept and delivered as soon as it is.
+	Submit(uniqueID, response string) error
+	// Pending returns the unanswered requests, oldest first
+	Pending() []PendingRequest
+	// Subscribe delivers each new unanswered request on the returned channel until the returned
+	// cancel function is called. Requests are dropped for subscribers that fall behind; Pending
+	// still lists them.
+	Subscribe() (<-chan PendingRequest, func())
+}
+
+// ErrFeedbackTimeout is returned when no response arrives before the feedback request times out
+var ErrFeedbackTimeout = errors.New("timed out waiting for human feedback")
+
+// subscriberBufferSize is the number of undelivered requests a subscriber may fall behind by
+const subscriberBufferSize = 16
+
+// earlyResponse is a response submitted before its request was created
+type earlyResponse struct {
+	response    string
+	submittedAt time.Time
+}
+
+// InMemoryHumanFeedbackStore manages interactive feedback requests
+type InMemoryHumanFeedbackStore struct {
+	requests       map[string]*HumanFeedbackRequest
+	waiters        map[string]chan string
+	earlyResponses map[string]earlyResponse
+	subscribers    map[int]chan PendingRequest
+	nextSubscriber int
+	mu             sync.RWMutex
+}
+
+var _ HumanFeedbackStore = (*InMemoryHumanFeedbackStore)(nil)
+
 // Global singleton instance
 var (
-	globalHumanFeedbackStore *HumanFeedbackStore
+	globalHumanFeedbackStore *InMemoryHumanFeedbackStore
 	humanFeedbackStoreOnce   sync.Once
 )
 
 // GetHumanFeedbackStore returns the global singleton instance
-func GetHumanFeedbackStore() *HumanFeedbackStore {
+func GetHumanFeedbackStore() *InMemoryHumanFeedbackStore {
 	humanFeedbackStoreOnce.Do(func() {
-		globalHumanFeedbackStore = &HumanFeedbackStore{
-			requests: make(map[string]*HumanFeedbackRequest),
-			waiters:  make(map[string]chan string),
-		}
+		globalHumanFeedbackStore = NewInMemoryHumanFeedbackStore()
 	})
 	return globalHumanFeedbackStore
 }
 
+// NewInMemoryHumanFeedbackStore creates an empty feedback store
+func NewInMemoryHumanFeedbackStore() *InMemoryHumanFeedbackStore {
+	return &InMemoryHumanFeedbackStore{
+		requests:       make(map[string]*HumanFeedbackRequest),
+		waiters:        make(map[string]chan string),
+		earlyResponses: make(map[string]earlyResponse),
+		subscribers:    make(map[int]chan PendingRequest),
+	}
+}
+
 // CreateRequest creates a new feedback request
-func (s *HumanFeedbackStore) CreateRequest(uniqueID, message string) error {
+func (s *InMemoryHumanFeedbackStore) CreateRequest(uniqueID, message string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -49,25 +97,46 @@ func (s *HumanFeedbackStore) CreateRequest(uniqueID, message string) error {
 		return fmt.Errorf("feedback request %s already exists", uniqueID)
 	}
 
-	s.requests[uniqueID] = &HumanFeedbackRequest{
+	request := &HumanFeedbackRequest{
 		UniqueID:       uniqueID,
 		MessageForUser: message,
 		IsCompleted:    false,
 		CreatedAt:      time.Now(),
 	}
-
+	s.requests[uniqueID] = request
 	s.waiters[uniqueID] = make(chan string, 1)
+
+	// The response arrived first: answer the request right away
+	if early, exists := s.earlyResponses[uniqueID]; exists {
+		delete(s.earlyResponses, uniqueID)
+		request.UserResponse = early.response
+		request.IsCompleted = true
+		s.waiters[uniqueID] <- early.response
+		return nil
+	}
+
+	pending := PendingRequest{UniqueID: uniqueID, MessageForUser: message, CreatedAt: request.CreatedAt}
+	for _, subscriber := range s.subscribers {
+		select {
+		case subscriber <- pending:
+		default:
+		}
+	}
 	return nil
 }
 
-// SubmitResponse submits a user response to a feedback request
-func (s *HumanFeedbackStore) SubmitResponse(uniqueID, response string) error {
+// Submit submits a user response to a feedback request, or keeps it until the request is created
+func (s *InMemoryHumanFeedbackStore) Submit(uniqueID, response string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	request, exists := s.requests[uniqueID]
 	if !exists {
-		return fmt.Errorf("feedback request %s not found", uniqueID)
+		if _, submitted := s.earlyResponses[uniqueID]; submitted {
+			return fmt.Errorf("feedback request %s already has a response", uniqueID)
+		}
+		s.earlyResponses[uniqueID] = earlyResponse{response: response, submittedAt: time.Now()}
+		return nil
 	}
 
 	if request.IsCompleted {
@@ -88,8 +157,53 @@ func (s *HumanFeedbackStore) SubmitResponse(uniqueID, response string) error {
 	return nil
 }
 
-// WaitForResponse blocks until user responds or timeout occurs
-func (s *HumanFeedbackStore) WaitForResponse(uniqueID string, timeout time.Duration) (string, error) {
+// SubmitResponse submits a user response to a feedback request
+func (s *InMemoryHumanFeedbackStore) SubmitResponse(uniqueID, response string) error {
+	return s.Submit(uniqueID, response)
+}
+
+// Pending returns the unanswered requests, oldest first
+func (s *InMemoryHumanFeedbackStore) Pending() []PendingRequest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pending := make([]PendingRequest, 0, len(s.requests))
+	for _, request := range s.requests {
+		if !request.IsCompleted {
+			pending = append(pending, PendingRequest{UniqueID: request.UniqueID, MessageForUser: request.MessageForUser, CreatedAt: request.CreatedAt})
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+	return pending
+}
+
+// Subscribe delivers each new unanswered request until the returned cancel function is called
+func (s *InMemoryHumanFeedbackStore) Subscribe() (<-chan PendingRequest, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextSubscriber
+	s.nextSubscriber++
+	ch := make(chan PendingRequest, subscriberBufferSize)
+	s.subscribers[id] = ch
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.subscribers, id)
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// WaitForResponse blocks until user responds or timeout occurs. A timed out request is removed
+// from the pending requests and the returned error wraps ErrFeedbackTimeout.
+func (s *InMemoryHumanFeedbackStore) WaitForResponse(uniqueID string, timeout time.Duration) (string, error) {
 	s.mu.RLock()
 	waiter, exists := s.waiters[uniqueID]
 	s.mu.RUnlock()
@@ -105,12 +219,23 @@ func (s *HumanFeedbackStore) WaitForResponse(uniqueID string, timeout time.Durat
 	case response := <-waiter:
 		return response, nil
 	case <-ctx.Done():
-		return "", fmt.Errorf("timeout waiting for feedback: %w", ctx.Err())
 	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The response may have arrived while the timeout fired
+	select {
+	case response := <-waiter:
+		return response, nil
+	default:
+	}
+	delete(s.requests, uniqueID)
+	delete(s.waiters, uniqueID)
+	return "", fmt.Errorf("%w (request %s, %s)", ErrFeedbackTimeout, uniqueID, timeout)
 }
 
-// Cleanup removes old requests (optional cleanup)
-func (s *HumanFeedbackStore) Cleanup(maxAge time.Duration) {
+// Cleanup removes old requests and unclaimed early responses (optional cleanup)
+func (s *InMemoryHumanFeedbackStore) Cleanup(maxAge time.Duration) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -124,4 +249,9 @@ func (s *HumanFeedbackStore) Cleanup(maxAge time.Duration) {
 			}
 		}
 	}
+	for uniqueID, early := range s.earlyResponses {
+		if early.submittedAt.Before(cutoff) {
+			delete(s.earlyResponses, uniqueID)
+		}
+	}
 }
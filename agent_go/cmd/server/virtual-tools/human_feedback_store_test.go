@@ -0,0 +1,83 @@
+package virtualtools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubmitAfterRequest(t *testing.T) {
+	store := NewInMemoryHumanFeedbackStore()
+	updates, cancel := store.Subscribe()
+	defer cancel()
+
+	if err := store.CreateRequest("req-1", "Approve the plan?"); err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	select {
+	case pending := <-updates:
+		if pending.UniqueID != "req-1" || pending.MessageForUser != "Approve the plan?" {
+			t.Errorf("unexpected subscribed request: %+v", pending)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the new request to be delivered to the subscriber")
+	}
+	if pending := store.Pending(); len(pending) != 1 || pending[0].UniqueID != "req-1" {
+		t.Fatalf("expected req-1 pending, got %+v", pending)
+	}
+
+	var feedback HumanFeedbackStore = store
+	if err := feedback.Submit("req-1", "Approve"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	response, err := store.WaitForResponse("req-1", time.Second)
+	if err != nil || response != "Approve" {
+		t.Fatalf("expected the submitted response, got %q (%v)", response, err)
+	}
+	if pending := store.Pending(); len(pending) != 0 {
+		t.Errorf("expected no pending requests after the response, got %+v", pending)
+	}
+	if err := feedback.Submit("req-1", "Approve"); err == nil {
+		t.Error("expected a second response to a completed request to be rejected")
+	}
+}
+
+func TestSubmitBeforeRequest(t *testing.T) {
+	store := NewInMemoryHumanFeedbackStore()
+	updates, cancel := store.Subscribe()
+
+	if err := store.Submit("req-2", "Use the staging account"); err != nil {
+		t.Fatalf("Submit before the request exists: %v", err)
+	}
+	if err := store.Submit("req-2", "Use production"); err == nil {
+		t.Error("expected a second early response to be rejected")
+	}
+
+	if err := store.CreateRequest("req-2", "Which account?"); err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	response, err := store.WaitForResponse("req-2", time.Second)
+	if err != nil || response != "Use the staging account" {
+		t.Fatalf("expected the early response to be delivered, got %q (%v)", response, err)
+	}
+
+	// An already answered request is neither pending nor announced to subscribers
+	if pending := store.Pending(); len(pending) != 0 {
+		t.Errorf("expected no pending requests, got %+v", pending)
+	}
+	cancel()
+	if _, open := <-updates; open {
+		t.Error("expected no subscription updates for an answered request")
+	}
+	cancel() // Idempotent
+}
+
+func TestCleanupDropsUnclaimedEarlyResponses(t *testing.T) {
+	store := NewInMemoryHumanFeedbackStore()
+	store.Submit("stale", "yes")
+	store.Cleanup(0)
+
+	store.CreateRequest("stale", "Continue?")
+	if pending := store.Pending(); len(pending) != 1 {
+		t.Errorf("expected the expired early response to be dropped, got %d pending", len(pending))
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"mcp-agent/agent_go/pkg/database"
+	"mcp-agent/agent_go/pkg/orchestrator/agents/workflow/todo_creation_human"
+	orchtypes "mcp-agent/agent_go/pkg/orchestrator/types"
 )
 
 // WorkflowRequest represents a workflow creation request
@@ -15,6 +17,12 @@ type WorkflowRequest struct {
 	HumanVerificationRequired bool   `json:"human_verification_required"`
 }
 
+// PlanGraphRequest asks for a structured plan rendered as a graph definition
+type PlanGraphRequest struct {
+	Plan   *todo_creation_human.PlanningResponse `json:"plan"`
+	Format string                                `json:"format,omitempty"` // "mermaid" (default) or "graphviz"
+}
+
 // WorkflowExecuteRequest represents a workflow execution request (DEPRECATED - not used anymore)
 type WorkflowExecuteRequest struct {
 	PresetQueryID string `json:"preset_query_id"`
@@ -204,6 +212,10 @@ func (api *StreamingAPI) handleUpdateWorkflow(w http.ResponseWriter, r *http.Req
 	}
 
 	if req.SelectedOptions != nil {
+		if _, err := orchtypes.ValidateSelectedOptions(req.SelectedOptions); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		updateReq.SelectedOptions = req.SelectedOptions
 	}
 
@@ -236,3 +248,39 @@ func (api *StreamingAPI) handleUpdateWorkflow(w http.ResponseWriter, r *http.Req
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// handleWorkflowPlanGraph renders a plan's steps and context dependencies as a Mermaid or Graphviz graph
+func (api *StreamingAPI) handleWorkflowPlanGraph(w http.ResponseWriter, r *http.Request) {
+	// Enable CORS
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var req PlanGraphRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	format := todo_creation_human.PlanGraphFormat(req.Format)
+	graph, err := todo_creation_human.RenderPlanGraph(req.Plan, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format == "" {
+		format = todo_creation_human.PlanGraphMermaid
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"format":  format,
+		"graph":   graph,
+	})
+}
@@ -0,0 +1,66 @@
+package events
+
+// EventFilter selects events by type and hierarchy level; the zero value matches every event
+type EventFilter struct {
+	Types    []string // Event types to keep; empty keeps every type
+	MinLevel *int     // Lowest hierarchy level to keep; nil has no lower bound
+	MaxLevel *int     // Highest hierarchy level to keep; nil has no upper bound
+}
+
+// IsEmpty reports whether the filter matches every event
+func (f EventFilter) IsEmpty() bool {
+	return len(f.Types) == 0 && f.MinLevel == nil && f.MaxLevel == nil
+}
+
+// Matches reports whether event passes the filter. Events without data are at level 0.
+func (f EventFilter) Matches(event Event) bool {
+	if len(f.Types) > 0 {
+		matched := false
+		for _, eventType := range f.Types {
+			if event.Type == eventType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	level := 0
+	if event.Data != nil {
+		level = event.Data.HierarchyLevel
+	}
+	if f.MinLevel != nil && level < *f.MinLevel {
+		return false
+	}
+	if f.MaxLevel != nil && level > *f.MaxLevel {
+		return false
+	}
+	return true
+}
+
+// EventPage is the result of one incremental poll
+type EventPage struct {
+	Events         []Event
+	LastEventIndex int // Index of the last buffered event (0 while there are none)
+	NextCursor     int // Index to poll since next; -1 while the observer has no events
+}
+
+// GetEventsPage returns the events after the since cursor (-1 for all buffered events) that
+// match filter. The next cursor also covers events the filter skipped, so polling moves past them.
+func (es *EventStore) GetEventsPage(observerID string, since int, filter EventFilter) (EventPage, bool) {
+	events, lastIndex, cursor, exists := es.getEvents(observerID, since)
+	page := EventPage{Events: events, LastEventIndex: lastIndex, NextCursor: cursor}
+	if !exists || filter.IsEmpty() {
+		return page, exists
+	}
+
+	page.Events = make([]Event, 0, len(events))
+	for _, event := range events {
+		if filter.Matches(event) {
+			page.Events = append(page.Events, event)
+		}
+	}
+	return page, true
+}
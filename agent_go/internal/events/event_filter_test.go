@@ -0,0 +1,69 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+func addLeveledEvent(store *EventStore, observerID string, i int, eventType string, level int) {
+	store.AddEvent(observerID, Event{
+		ID:        fmt.Sprintf("evt-%d", i),
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Data:      &events.AgentEvent{Type: events.EventType(eventType), HierarchyLevel: level},
+	})
+}
+
+func eventIDs(evts []Event) []string {
+	ids := make([]string, len(evts))
+	for i, event := range evts {
+		ids[i] = event.ID
+	}
+	return ids
+}
+
+func TestGetFilteredEventsByTypeAndLevel(t *testing.T) {
+	store := NewEventStore(100)
+	defer store.Stop()
+	store.InitializeObserver("obs")
+	addLeveledEvent(store, "obs", 0, "conversation_start", 0)
+	addLeveledEvent(store, "obs", 1, "llm_generation_start", 1)
+	addLeveledEvent(store, "obs", 2, "tool_call_start", 2)
+	addLeveledEvent(store, "obs", 3, "tool_call_end", 2)
+	addLeveledEvent(store, "obs", 4, "tool_call_start", 3)
+	addLeveledEvent(store, "obs", 5, "conversation_end", 0)
+
+	one, two := 1, 2
+	tests := []struct {
+		name   string
+		filter EventFilter
+		want   []string
+	}{
+		{"no filter", EventFilter{}, []string{"evt-0", "evt-1", "evt-2", "evt-3", "evt-4", "evt-5"}},
+		{"types", EventFilter{Types: []string{"tool_call_start", "tool_call_end"}}, []string{"evt-2", "evt-3", "evt-4"}},
+		{"min level", EventFilter{MinLevel: &two}, []string{"evt-2", "evt-3", "evt-4"}},
+		{"max level", EventFilter{MaxLevel: &one}, []string{"evt-0", "evt-1", "evt-5"}},
+		{"level range", EventFilter{MinLevel: &one, MaxLevel: &two}, []string{"evt-1", "evt-2", "evt-3"}},
+		{"types and levels", EventFilter{Types: []string{"tool_call_start"}, MaxLevel: &two}, []string{"evt-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, exists := store.GetEventsPage("obs", -1, tt.filter)
+			if !exists || page.NextCursor != 5 {
+				t.Fatalf("unexpected exists=%v next cursor=%d", exists, page.NextCursor)
+			}
+			if got := fmt.Sprint(eventIDs(page.Events)); got != fmt.Sprint(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The cursor still moves past events the filter skipped
+	page, _ := store.GetEventsPage("obs", 3, EventFilter{Types: []string{"tool_call_end"}})
+	if len(page.Events) != 0 || page.NextCursor != 5 {
+		t.Fatalf("expected no events and cursor 5 after index 3, got %v (cursor %d)", eventIDs(page.Events), page.NextCursor)
+	}
+}
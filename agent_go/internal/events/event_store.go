@@ -42,26 +42,64 @@ func (e Event) MarshalJSON() ([]byte, error) {
 	return json.Marshal(result)
 }
 
+const (
+	// DefaultRetentionGrace is how long after its last poll an observer keeps extended buffer retention
+	DefaultRetentionGrace = 2 * time.Minute
+	// DefaultGraceMultiplier is how many times maxEvents a recently-active observer may buffer
+	DefaultGraceMultiplier = 2
+	// inactiveObserverTTL is how long a pruned observer survives the cleanup sweep without polling
+	inactiveObserverTTL = 5 * time.Minute
+)
+
 // EventStore manages in-memory event storage for multiple observers
 type EventStore struct {
-	events        map[string][]Event // observerID -> events
-	lastIndex     map[string]int     // observerID -> last event index
-	eventCounters map[string]int     // observerID -> event counter (persistent across messages)
+	events        map[string][]Event   // observerID -> events
+	lastIndex     map[string]int       // observerID -> last event index
+	eventCounters map[string]int       // observerID -> event counter (persistent across messages)
+	lastPolled    map[string]time.Time // observerID -> time of the last poll
 	mu            sync.RWMutex
 	maxEvents     int // Maximum events per observer
-	cleanupTicker *time.Ticker
-	stopCh        chan struct{}
+	// Observers that polled within retentionGrace keep up to maxEvents*graceMultiplier
+	// events so a client recovering from a transient network failure does not lose events
+	retentionGrace  time.Duration
+	graceMultiplier int
+	// Events dropped from the front of an observer's buffer; keeps polling indices stable
+	pruned map[string]int
+	// Completed sessions have their buffers freed after completedPruneGrace; opt-in, 0 (the default) disables pruning
+	completedAt         map[string]time.Time
+	completedPruneGrace time.Duration
+	cleanupTicker       *time.Ticker
+	stopCh              chan struct{}
+
+	// Called with every added event outside the store lock (see SetEventHook)
+	eventHook func(observerID string, event Event)
+
+	// Streaming subscribers signalled on every added event (see Subscribe)
+	subscribers map[string]map[chan struct{}]struct{}
+
+	// Emission throttle for observers that fall behind (see SetEmissionThrottle); disabled when throttleLag is 0
+	throttleLag      int
+	throttleInterval time.Duration
+	lastAdmitted     map[string]time.Time // observerID -> time the last non-lifecycle event was admitted while lagging
+	throttled        map[string]int       // observerID -> non-lifecycle events dropped by the throttle
 }
 
 // NewEventStore creates a new event store with configurable limits
 func NewEventStore(maxEvents int) *EventStore {
 	store := &EventStore{
-		events:        make(map[string][]Event),
-		lastIndex:     make(map[string]int),
-		eventCounters: make(map[string]int),
-		maxEvents:     maxEvents,
-		cleanupTicker: time.NewTicker(5 * time.Minute), // Cleanup every 5 minutes
-		stopCh:        make(chan struct{}),
+		events:          make(map[string][]Event),
+		lastIndex:       make(map[string]int),
+		eventCounters:   make(map[string]int),
+		lastPolled:      make(map[string]time.Time),
+		maxEvents:       maxEvents,
+		retentionGrace:  DefaultRetentionGrace,
+		graceMultiplier: DefaultGraceMultiplier,
+		pruned:          make(map[string]int),
+		completedAt:     make(map[string]time.Time),
+		lastAdmitted:    make(map[string]time.Time),
+		throttled:       make(map[string]int),
+		cleanupTicker:   time.NewTicker(5 * time.Minute), // Cleanup every 5 minutes
+		stopCh:          make(chan struct{}),
 	}
 
 	// Start background cleanup
@@ -72,6 +110,17 @@ func NewEventStore(maxEvents int) *EventStore {
 
 // AddEvent adds an event for a specific observer
 func (es *EventStore) AddEvent(observerID string, event Event) {
+	es.mu.Lock()
+	admitted := es.admit(observerID, event)
+	hook := es.eventHook
+	es.mu.Unlock()
+	if !admitted {
+		return
+	}
+	if hook != nil {
+		hook(observerID, event)
+	}
+
 	es.mu.Lock()
 	defer es.mu.Unlock()
 
@@ -85,10 +134,97 @@ func (es *EventStore) AddEvent(observerID string, event Event) {
 	es.events[observerID] = append(es.events[observerID], event)
 
 	// Remove old events if over limit
-	if len(es.events[observerID]) > es.maxEvents {
-		es.events[observerID] = es.events[observerID][len(es.events[observerID])-es.maxEvents:]
+	limit := es.retentionLimit(observerID)
+	if len(es.events[observerID]) > limit {
+		dropped := len(es.events[observerID]) - limit
+		es.events[observerID] = es.events[observerID][dropped:]
+		es.pruned[observerID] += dropped
+	}
+
+	es.notifySubscribers(observerID)
+}
+
+// SetEmissionThrottle slows the event stream of observers that fall behind: once an observer has
+// more than lagThreshold unpolled events, at most one non-lifecycle event per interval is kept and
+// the rest are dropped, so a slow poller loses fewer events to buffer eviction. Lifecycle events
+// (start, end, error, completion, human feedback) always flow. A lagThreshold of 0 disables throttling.
+func (es *EventStore) SetEmissionThrottle(lagThreshold int, interval time.Duration) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if lagThreshold < 0 {
+		lagThreshold = 0
+	}
+	es.throttleLag = lagThreshold
+	es.throttleInterval = interval
+}
+
+// admit reports whether the emission throttle lets the event through. Must be called with es.mu held.
+func (es *EventStore) admit(observerID string, event Event) bool {
+	if es.throttleLag <= 0 || events.IsLifecycleEvent(events.EventType(event.Type)) {
+		return true
+	}
+	if es.lag(observerID) <= es.throttleLag {
+		return true
+	}
+	now := time.Now()
+	if last, ok := es.lastAdmitted[observerID]; ok && now.Sub(last) < es.throttleInterval {
+		es.throttled[observerID]++
+		return false
+	}
+	es.lastAdmitted[observerID] = now
+	return true
+}
+
+// lag returns how many buffered events the observer has not polled yet. Must be called with es.mu held.
+func (es *EventStore) lag(observerID string) int {
+	lastIndex := es.pruned[observerID] + len(es.events[observerID]) - 1
+	unpolled := lastIndex - es.lastIndex[observerID]
+	if unpolled < 0 {
+		return 0
+	}
+	return unpolled
+}
+
+// ThrottledCount returns how many of the observer's events the emission throttle dropped
+func (es *EventStore) ThrottledCount(observerID string) int {
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+	return es.throttled[observerID]
+}
+
+// SetEventHook registers a function that sees every event as it is added, e.g. for validation.
+// The hook runs synchronously on the emitting goroutine and must not call back into the store.
+func (es *EventStore) SetEventHook(hook func(observerID string, event Event)) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.eventHook = hook
+}
+
+// SetRetentionGrace configures the grace window and buffer multiplier applied to recently-active observers.
+// A zero grace disables the extension.
+func (es *EventStore) SetRetentionGrace(grace time.Duration, multiplier int) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if multiplier < 1 {
+		multiplier = 1
 	}
+	es.retentionGrace = grace
+	es.graceMultiplier = multiplier
+}
 
+// retentionLimit returns the maximum number of buffered events for an observer.
+// Must be called with es.mu held.
+func (es *EventStore) retentionLimit(observerID string) int {
+	if es.retentionGrace <= 0 {
+		return es.maxEvents
+	}
+	lastPolled, polled := es.lastPolled[observerID]
+	if polled && time.Since(lastPolled) <= es.retentionGrace {
+		return es.maxEvents * es.graceMultiplier
+	}
+	return es.maxEvents
 }
 
 // InitializeObserver creates an empty event list for an observer
@@ -101,6 +237,7 @@ func (es *EventStore) InitializeObserver(observerID string) {
 		es.events[observerID] = make([]Event, 0)
 		es.lastIndex[observerID] = 0
 		es.eventCounters[observerID] = 0
+		es.lastPolled[observerID] = time.Now()
 	}
 }
 
@@ -119,45 +256,162 @@ func (es *EventStore) GetNextEventCounter(observerID string) int {
 	return es.eventCounters[observerID]
 }
 
-// GetEvents retrieves events for an observer since a specific index
+// GetEvents retrieves events for an observer since a specific index.
+// Indices are absolute across the observer's lifetime, so they stay valid after
+// old events have been trimmed or the buffer of a completed session has been pruned.
 func (es *EventStore) GetEvents(observerID string, sinceIndex int) ([]Event, int, bool) {
-	es.mu.RLock()
-	defer es.mu.RUnlock()
+	events, lastIndex, _, exists := es.getEvents(observerID, sinceIndex)
+	return events, lastIndex, exists
+}
+
+// getEvents implements GetEvents, also returning the cursor to poll from next:
+// the last event index, or -1 while the observer has no events yet
+func (es *EventStore) getEvents(observerID string, sinceIndex int) ([]Event, int, int, bool) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
 
 	events, exists := es.events[observerID]
 	if !exists {
-		return []Event{}, 0, false
+		return []Event{}, 0, -1, false
 	}
 
-	// If sinceIndex is beyond our events, return empty but with correct last index
-	if sinceIndex >= len(events) {
-		// Return the actual last event index (len(events) - 1) instead of len(events)
-		// This prevents the frontend from getting stuck in an infinite polling loop
-		lastIndex := len(events) - 1
-		if lastIndex < 0 {
-			lastIndex = 0
-		}
-		return []Event{}, lastIndex, true
+	// Record the poll so the observer gets extended retention while it is active
+	es.lastPolled[observerID] = time.Now()
+	es.lastIndex[observerID] = sinceIndex
+
+	// Translate the absolute index into the retained buffer; a cursor that points
+	// into the pruned range receives everything still buffered
+	base := es.pruned[observerID]
+	localIndex := sinceIndex - base
+	if localIndex < -1 {
+		localIndex = -1
+	}
+
+	// Return the actual last event index instead of the count
+	// This prevents the frontend from getting stuck in an infinite polling loop
+	cursor := base + len(events) - 1
+	lastIndex := cursor
+	if lastIndex < 0 {
+		lastIndex = 0
 	}
 
 	// Return events AFTER the specified index (excluding it)
 	// This ensures only new events are returned, preventing infinite loops
-	nextIndex := sinceIndex + 1
-	var newEvents []Event
+	nextIndex := localIndex + 1
 	if nextIndex >= len(events) {
-		// No new events after the sinceIndex
-		newEvents = []Event{}
-	} else {
-		newEvents = events[nextIndex:]
+		return []Event{}, lastIndex, cursor, true
+	}
+	return events[nextIndex:], lastIndex, cursor, true
+}
+
+// GetEventRange returns copies of the observer's buffered events with absolute indices from..to
+// (inclusive), the first index still buffered (earlier events were trimmed or pruned) and the
+// last event index. Unlike GetEvents it neither moves the polling cursor nor counts as a poll.
+func (es *EventStore) GetEventRange(observerID string, from, to int) ([]Event, int, int, bool) {
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+
+	events, exists := es.events[observerID]
+	if !exists {
+		return []Event{}, 0, 0, false
 	}
 
-	// Return the actual last event index (len(events) - 1) instead of len(events)
-	// This prevents the frontend from getting stuck in an infinite polling loop
-	lastIndex := len(events) - 1
-	if lastIndex < 0 {
-		lastIndex = 0
+	base := es.pruned[observerID]
+	lastIndex := base + len(events) - 1
+	start, end := from-base, to-base
+	if start < 0 {
+		start = 0
+	}
+	if end > len(events)-1 {
+		end = len(events) - 1
+	}
+	if start > end {
+		return []Event{}, base, lastIndex, true
+	}
+
+	rangeEvents := make([]Event, end-start+1)
+	copy(rangeEvents, events[start:end+1])
+	return rangeEvents, base, lastIndex, true
+}
+
+// Snapshot returns a copy of the observer's buffered events and how many earlier events
+// were already trimmed. Unlike GetEvents it does not count as a poll.
+func (es *EventStore) Snapshot(observerID string) ([]Event, int) {
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+
+	events := make([]Event, len(es.events[observerID]))
+	copy(events, es.events[observerID])
+	return events, es.pruned[observerID]
+}
+
+// SetCompletedPruneGrace enables freeing a completed session's buffer after grace. Pruning is off
+// by default; grace should be minutes so slow or reconnecting pollers still get the final events.
+// 0 disables pruning of completed sessions.
+func (es *EventStore) SetCompletedPruneGrace(grace time.Duration) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.completedPruneGrace = grace
+}
+
+// MarkCompleted schedules the observer's buffered events to be freed after the
+// completion grace period, leaving time for final polls. Completed sessions are
+// persisted in the database, which serves later retrieval.
+func (es *EventStore) MarkCompleted(observerID string) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if es.completedPruneGrace <= 0 {
+		return
+	}
+	if _, exists := es.events[observerID]; !exists {
+		return
+	}
+
+	completedAt := time.Now()
+	es.completedAt[observerID] = completedAt
+	time.AfterFunc(es.completedPruneGrace, func() {
+		es.pruneCompleted(observerID, completedAt)
+	})
+}
+
+// MarkActive cancels a pending prune when the observer's session starts new work
+func (es *EventStore) MarkActive(observerID string) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	delete(es.completedAt, observerID)
+}
+
+// pruneCompleted frees the observer's buffer if it is still marked completed at completedAt
+func (es *EventStore) pruneCompleted(observerID string, completedAt time.Time) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if marked, ok := es.completedAt[observerID]; !ok || !marked.Equal(completedAt) {
+		return
 	}
-	return newEvents, lastIndex, true
+	delete(es.completedAt, observerID)
+	es.dropBuffer(observerID)
+}
+
+// EvictBuffer frees the observer's buffered events immediately, keeping polling indices stable.
+// Used to shed memory under pressure; events are persisted in the database. Returns how many were freed.
+func (es *EventStore) EvictBuffer(observerID string) int {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	delete(es.completedAt, observerID)
+	return es.dropBuffer(observerID)
+}
+
+// dropBuffer empties the observer's buffer; callers must hold es.mu
+func (es *EventStore) dropBuffer(observerID string) int {
+	events, exists := es.events[observerID]
+	if !exists {
+		return 0
+	}
+	es.pruned[observerID] += len(events)
+	es.events[observerID] = make([]Event, 0)
+	return len(events)
 }
 
 // GetObserverStatus returns the status of an observer
@@ -177,10 +431,20 @@ func (es *EventStore) GetObserverStatus(observerID string) (int, bool) {
 func (es *EventStore) RemoveObserver(observerID string) {
 	es.mu.Lock()
 	defer es.mu.Unlock()
+	es.deleteObserver(observerID)
+}
 
+// deleteObserver drops the observer's state and ends its subscriptions; callers must hold es.mu
+func (es *EventStore) deleteObserver(observerID string) {
 	delete(es.events, observerID)
 	delete(es.lastIndex, observerID)
 	delete(es.eventCounters, observerID) // Clean up event counter to prevent memory leak
+	delete(es.lastPolled, observerID)
+	delete(es.pruned, observerID)
+	delete(es.completedAt, observerID)
+	delete(es.lastAdmitted, observerID)
+	delete(es.throttled, observerID)
+	es.closeSubscribers(observerID)
 }
 
 // GetActiveObservers returns all active observer IDs
@@ -219,9 +483,11 @@ func (es *EventStore) cleanupInactiveObservers() {
 	for observerID, events := range es.events {
 		// Remove observers with no events (inactive)
 		if len(events) == 0 {
-			delete(es.events, observerID)
-			delete(es.lastIndex, observerID)
-			delete(es.eventCounters, observerID) // Clean up event counter to prevent memory leak
+			// Keep pruned completed sessions whose observer is still polling
+			if es.pruned[observerID] > 0 && time.Since(es.lastPolled[observerID]) < inactiveObserverTTL {
+				continue
+			}
+			es.deleteObserver(observerID)
 		}
 	}
 }
@@ -240,10 +506,16 @@ func (es *EventStore) GetStats() map[string]interface{} {
 	for _, events := range es.events {
 		totalEvents += len(events)
 	}
+	totalThrottled := 0
+	for _, count := range es.throttled {
+		totalThrottled += count
+	}
 
 	return map[string]interface{}{
 		"total_observers": len(es.events),
 		"total_events":    totalEvents,
 		"max_events":      es.maxEvents,
+		"retention_grace": es.retentionGrace.String(),
+		"throttled":       totalThrottled,
 	}
 }
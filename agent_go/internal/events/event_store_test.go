@@ -0,0 +1,107 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func addTestEvents(store *EventStore, observerID string, from, to int) {
+	for i := from; i < to; i++ {
+		store.AddEvent(observerID, Event{ID: fmt.Sprintf("evt-%d", i), Type: "test", Timestamp: time.Now()})
+	}
+}
+
+func waitForBuffer(t *testing.T, store *EventStore, observerID string, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if total, _ := store.GetObserverStatus(observerID); total == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	total, _ := store.GetObserverStatus(observerID)
+	t.Fatalf("expected %d buffered events, got %d", want, total)
+}
+
+func TestCompletedSessionBufferFreedAfterGrace(t *testing.T) {
+	store := NewEventStore(100)
+	defer store.Stop()
+	store.SetCompletedPruneGrace(50 * time.Millisecond)
+	store.InitializeObserver("obs")
+	addTestEvents(store, "obs", 0, 5)
+
+	store.MarkCompleted("obs")
+
+	// Final polls within the grace period still see every event
+	evts, lastIndex, _ := store.GetEvents("obs", -1)
+	if len(evts) != 5 || lastIndex != 4 {
+		t.Fatalf("expected 5 events before grace expires, got %d (last index %d)", len(evts), lastIndex)
+	}
+
+	waitForBuffer(t, store, "obs", 0)
+
+	// The observer stays registered and its cursor remains valid for later events
+	evts, lastIndex, exists := store.GetEvents("obs", 4)
+	if !exists || len(evts) != 0 || lastIndex != 4 {
+		t.Fatalf("unexpected state after prune: exists=%v events=%d last=%d", exists, len(evts), lastIndex)
+	}
+	addTestEvents(store, "obs", 5, 7)
+	evts, lastIndex, _ = store.GetEvents("obs", 4)
+	if len(evts) != 2 || evts[0].ID != "evt-5" || lastIndex != 6 {
+		t.Fatalf("expected events 5-6 after prune, got %v (last index %d)", evts, lastIndex)
+	}
+}
+
+func TestMarkActiveCancelsPendingPrune(t *testing.T) {
+	store := NewEventStore(100)
+	defer store.Stop()
+	store.SetCompletedPruneGrace(20 * time.Millisecond)
+	store.InitializeObserver("obs")
+	addTestEvents(store, "obs", 0, 3)
+
+	store.MarkCompleted("obs")
+	store.MarkActive("obs")
+	time.Sleep(60 * time.Millisecond)
+
+	if total, _ := store.GetObserverStatus("obs"); total != 3 {
+		t.Fatalf("expected buffer kept for reactivated session, got %d events", total)
+	}
+}
+
+func TestCompletedPruneDisabledByDefault(t *testing.T) {
+	store := NewEventStore(100)
+	defer store.Stop()
+	store.InitializeObserver("obs")
+	addTestEvents(store, "obs", 0, 3)
+
+	store.MarkCompleted("obs")
+	time.Sleep(20 * time.Millisecond)
+
+	if total, _ := store.GetObserverStatus("obs"); total != 3 {
+		t.Fatalf("expected buffer kept when pruning disabled, got %d events", total)
+	}
+}
+
+func TestInactiveObserverCleanupClosesSubscribers(t *testing.T) {
+	store := NewEventStore(100)
+	defer store.Stop()
+	store.InitializeObserver("obs")
+	updates, unsubscribe := store.Subscribe("obs")
+
+	store.cleanupInactiveObservers()
+
+	select {
+	case _, open := <-updates:
+		if open {
+			t.Fatal("expected the subscription closed, got a signal")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the subscription closed when the inactive observer is cleaned up")
+	}
+	if _, exists := store.GetObserverStatus("obs"); exists {
+		t.Fatal("expected the inactive observer removed")
+	}
+	unsubscribe() // Must not close the channel a second time
+}
@@ -0,0 +1,51 @@
+package events
+
+// Subscribe returns a channel signalled whenever an event is added for observerID, for
+// streaming consumers that read new events with GetEventsPage. Signals coalesce: one
+// pending signal stands for any number of added events. The channel is closed when the
+// observer is removed; call the returned function to unsubscribe.
+func (es *EventStore) Subscribe(observerID string) (<-chan struct{}, func()) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if es.subscribers == nil {
+		es.subscribers = make(map[string]map[chan struct{}]struct{})
+	}
+	if es.subscribers[observerID] == nil {
+		es.subscribers[observerID] = make(map[chan struct{}]struct{})
+	}
+	ch := make(chan struct{}, 1)
+	es.subscribers[observerID][ch] = struct{}{}
+
+	unsubscribe := func() {
+		es.mu.Lock()
+		defer es.mu.Unlock()
+		if _, subscribed := es.subscribers[observerID][ch]; !subscribed {
+			return // Already closed by RemoveObserver
+		}
+		delete(es.subscribers[observerID], ch)
+		if len(es.subscribers[observerID]) == 0 {
+			delete(es.subscribers, observerID)
+		}
+		close(ch)
+	}
+	return ch, unsubscribe
+}
+
+// notifySubscribers signals the observer's subscribers without blocking; caller holds es.mu
+func (es *EventStore) notifySubscribers(observerID string) {
+	for ch := range es.subscribers[observerID] {
+		select {
+		case ch <- struct{}{}:
+		default: // A signal is already pending
+		}
+	}
+}
+
+// closeSubscribers ends the observer's subscriptions; caller holds es.mu
+func (es *EventStore) closeSubscribers(observerID string) {
+	for ch := range es.subscribers[observerID] {
+		close(ch)
+	}
+	delete(es.subscribers, observerID)
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+func addTypedEvent(store *EventStore, observerID string, i int, eventType events.EventType) {
+	store.AddEvent(observerID, Event{ID: fmt.Sprintf("evt-%d", i), Type: string(eventType), Timestamp: time.Now()})
+}
+
+func TestEmissionThrottleDropsNonEssentialEventsForSlowConsumer(t *testing.T) {
+	store := NewEventStore(100)
+	defer store.Stop()
+	store.SetEmissionThrottle(5, time.Hour)
+	store.InitializeObserver("obs")
+
+	// The consumer polls once and then falls behind while the agent keeps emitting
+	store.GetEvents("obs", -1)
+	addTypedEvent(store, "obs", 0, events.AgentStart)
+	for i := 1; i <= 20; i++ {
+		addTypedEvent(store, "obs", i, events.StreamingChunk)
+	}
+	addTypedEvent(store, "obs", 21, events.ToolCallError)
+	addTypedEvent(store, "obs", 22, events.AgentEnd)
+
+	evts, _, _ := store.GetEvents("obs", -1)
+	counts := map[string]int{}
+	for _, evt := range evts {
+		counts[evt.Type]++
+	}
+	// Five chunks fit in the lag allowance, one more is admitted for the interval, the rest are dropped
+	if counts[string(events.StreamingChunk)] != 6 || store.ThrottledCount("obs") != 14 {
+		t.Fatalf("expected 6 streaming chunks kept and 14 throttled, got %d kept and %d throttled", counts[string(events.StreamingChunk)], store.ThrottledCount("obs"))
+	}
+	if counts[string(events.AgentStart)] != 1 || counts[string(events.ToolCallError)] != 1 || counts[string(events.AgentEnd)] != 1 {
+		t.Fatalf("expected lifecycle events to flow, got %v", counts)
+	}
+	if evts[len(evts)-1].Type != string(events.AgentEnd) {
+		t.Fatalf("expected the agent end event last, got %s", evts[len(evts)-1].Type)
+	}
+}
+
+func TestEmissionThrottleReleasesOnceConsumerCatchesUp(t *testing.T) {
+	store := NewEventStore(100)
+	defer store.Stop()
+	store.SetEmissionThrottle(2, time.Hour)
+	store.InitializeObserver("obs")
+
+	for i := 0; i < 10; i++ {
+		addTypedEvent(store, "obs", i, events.StreamingChunk)
+	}
+	throttled := store.ThrottledCount("obs")
+	if throttled == 0 {
+		t.Fatal("expected the lagging observer to be throttled")
+	}
+
+	// After polling everything the observer is no longer behind
+	_, lastIndex, _ := store.GetEvents("obs", -1)
+	store.GetEvents("obs", lastIndex)
+	addTypedEvent(store, "obs", 10, events.StreamingChunk)
+	addTypedEvent(store, "obs", 11, events.StreamingChunk)
+	if store.ThrottledCount("obs") != throttled {
+		t.Fatalf("expected no throttling after catching up, got %d more dropped", store.ThrottledCount("obs")-throttled)
+	}
+}
+
+func TestEmissionThrottleDisabledByDefault(t *testing.T) {
+	store := NewEventStore(100)
+	defer store.Stop()
+	store.InitializeObserver("obs")
+
+	for i := 0; i < 50; i++ {
+		addTypedEvent(store, "obs", i, events.StreamingChunk)
+	}
+	if total, _ := store.GetObserverStatus("obs"); total != 50 || store.ThrottledCount("obs") != 0 {
+		t.Fatalf("expected every event kept without a throttle, got %d", total)
+	}
+}
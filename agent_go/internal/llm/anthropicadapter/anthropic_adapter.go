@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 
 	"mcp-agent/agent_go/internal/utils"
@@ -71,9 +72,9 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 		}
 	}
 
-	// Set temperature
+	// Set temperature (Anthropic accepts 0-1, unlike the 0-2 range of OpenAI-style providers)
 	if opts.Temperature > 0 {
-		params.Temperature = anthropic.Float(opts.Temperature)
+		params.Temperature = anthropic.Float(math.Min(opts.Temperature, 1))
 	}
 
 	// Set max tokens
@@ -81,6 +82,11 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 		params.MaxTokens = int64(opts.MaxTokens)
 	}
 
+	// Set stop sequences
+	if len(opts.StopSequences) > 0 {
+		params.StopSequences = opts.StopSequences
+	}
+
 	// Convert tools if provided
 	if len(opts.Tools) > 0 {
 		tools := convertTools(opts.Tools)
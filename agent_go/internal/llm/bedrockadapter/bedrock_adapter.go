@@ -66,6 +66,11 @@ func (b *BedrockAdapter) GenerateContent(ctx context.Context, messages []llmtype
 	}
 	requestBody["max_tokens"] = maxTokens
 
+	// Set stop sequences
+	if len(opts.StopSequences) > 0 {
+		requestBody["stop_sequences"] = opts.StopSequences
+	}
+
 	// Handle JSON mode if specified
 	// Claude 3.5+ supports structured output via response schema
 	// For earlier versions, we add JSON mode instruction to the first system/user message
@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultModelContextWindows holds the context window (input tokens) of known models, keyed by a
+// model ID fragment. As with the output token caps, the longest fragment contained in the model ID wins.
+var defaultModelContextWindows = map[string]int{
+	"gpt-4o":           128000,
+	"gpt-4.1":          1047576,
+	"gpt-5":            400000,
+	"o3":               200000,
+	"o4-mini":          200000,
+	"claude-3-5":       200000,
+	"claude-3-7":       200000,
+	"claude-sonnet-4":  200000,
+	"claude-opus-4":    200000,
+	"gemini-2.5":       1048576,
+	"grok-code-fast-1": 256000,
+	"grok-4":           256000,
+}
+
+// ModelContextWindowDefaults returns the per-model context windows, with the overrides from
+// LLM_MODEL_CONTEXT_WINDOWS ("model=tokens,model=tokens"; 0 removes a model) applied
+func ModelContextWindowDefaults() map[string]int {
+	windows := make(map[string]int, len(defaultModelContextWindows))
+	for model, tokens := range defaultModelContextWindows {
+		windows[model] = tokens
+	}
+	for _, entry := range strings.Split(os.Getenv("LLM_MODEL_CONTEXT_WINDOWS"), ",") {
+		model, value, found := strings.Cut(entry, "=")
+		model = strings.ToLower(strings.TrimSpace(model))
+		if !found || model == "" {
+			continue
+		}
+		tokens, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || tokens < 0 {
+			continue
+		}
+		if tokens == 0 {
+			delete(windows, model)
+		} else {
+			windows[model] = tokens
+		}
+	}
+	return windows
+}
+
+// ContextWindow returns the context window of modelID in tokens, or 0 when the model is unknown
+func ContextWindow(modelID string) int {
+	modelID = strings.ToLower(modelID)
+	bestMatch, tokens := "", 0
+	for model, window := range ModelContextWindowDefaults() {
+		if len(model) > len(bestMatch) && strings.Contains(modelID, model) {
+			bestMatch, tokens = model, window
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,34 @@
+package llm
+
+import "testing"
+
+func TestContextWindowMatchesLongestModelFragment(t *testing.T) {
+	t.Setenv("LLM_MODEL_CONTEXT_WINDOWS", "")
+
+	if got := ContextWindow("us.anthropic.claude-sonnet-4-20250514-v1:0"); got != 200000 {
+		t.Fatalf("expected the claude-sonnet-4 window of 200000, got %d", got)
+	}
+	if got := ContextWindow("openai/gpt-4.1-mini"); got != 1047576 {
+		t.Fatalf("expected gpt-4.1 to win over shorter fragments, got %d", got)
+	}
+	if got := ContextWindow("some-unknown-model"); got != 0 {
+		t.Fatalf("expected 0 for an unknown model, got %d", got)
+	}
+}
+
+func TestContextWindowOverridesFromEnv(t *testing.T) {
+	t.Setenv("LLM_MODEL_CONTEXT_WINDOWS", "claude-sonnet-4=1000000, my-model=32000,gpt-4o=0,bad")
+
+	if got := ContextWindow("claude-sonnet-4-5"); got != 1000000 {
+		t.Fatalf("expected the override of 1000000, got %d", got)
+	}
+	if got := ContextWindow("my-model-v2"); got != 32000 {
+		t.Fatalf("expected the added model window, got %d", got)
+	}
+	if got := ContextWindow("gpt-4o"); got != 0 {
+		t.Fatalf("expected the window removed by a 0 override, got %d", got)
+	}
+	if windows := GetLLMDefaults().ModelContextWindows; windows["my-model"] != 32000 {
+		t.Fatalf("expected the models endpoint to surface the effective windows, got %v", windows)
+	}
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// defaultModelMaxOutputTokens caps the output of calls that don't set max tokens, keyed by a model ID
+// fragment. The longest fragment contained in the model ID wins, so "claude-sonnet-4" matches both
+// "us.anthropic.claude-sonnet-4-20250514-v1:0" and "anthropic/claude-sonnet-4".
+var defaultModelMaxOutputTokens = map[string]int{
+	"gpt-4o":           16384,
+	"gpt-4.1":          32768,
+	"gpt-5":            32768,
+	"o3":               32768,
+	"o4-mini":          32768,
+	"claude-3-5":       8192,
+	"claude-3-7":       16384,
+	"claude-sonnet-4":  16384,
+	"claude-opus-4":    16384,
+	"gemini-2.5":       16384,
+	"grok-code-fast-1": 16384,
+	"grok-4":           16384,
+}
+
+// ModelMaxOutputTokenDefaults returns the per-model output token caps, with the overrides from
+// LLM_MODEL_MAX_OUTPUT_TOKENS ("model=tokens,model=tokens"; 0 removes a model's cap) applied
+func ModelMaxOutputTokenDefaults() map[string]int {
+	defaults := make(map[string]int, len(defaultModelMaxOutputTokens))
+	for model, tokens := range defaultModelMaxOutputTokens {
+		defaults[model] = tokens
+	}
+	for _, entry := range strings.Split(os.Getenv("LLM_MODEL_MAX_OUTPUT_TOKENS"), ",") {
+		model, value, found := strings.Cut(entry, "=")
+		model = strings.ToLower(strings.TrimSpace(model))
+		if !found || model == "" {
+			continue
+		}
+		tokens, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || tokens < 0 {
+			continue
+		}
+		if tokens == 0 {
+			delete(defaults, model)
+		} else {
+			defaults[model] = tokens
+		}
+	}
+	return defaults
+}
+
+// DefaultMaxOutputTokens returns the output token cap applied to modelID when a call doesn't set
+// one, or 0 when the model has no default
+func DefaultMaxOutputTokens(modelID string) int {
+	modelID = strings.ToLower(modelID)
+	bestMatch, tokens := "", 0
+	for model, maxTokens := range ModelMaxOutputTokenDefaults() {
+		if len(model) > len(bestMatch) && strings.Contains(modelID, model) {
+			bestMatch, tokens = model, maxTokens
+		}
+	}
+	return tokens
+}
+
+// withDefaultMaxOutputTokens appends the model's default output token cap unless the options set one
+func withDefaultMaxOutputTokens(modelID string, options []llmtypes.CallOption) ([]llmtypes.CallOption, int) {
+	callOptions := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(callOptions)
+	}
+	if callOptions.MaxTokens > 0 {
+		return options, 0
+	}
+	maxTokens := DefaultMaxOutputTokens(modelID)
+	if maxTokens == 0 {
+		return options, 0
+	}
+	return append(options, llmtypes.WithMaxTokens(maxTokens)), maxTokens
+}
@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+// optionsRecorder records the call options it receives
+type optionsRecorder struct {
+	options llmtypes.CallOptions
+}
+
+func (r *optionsRecorder) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	r.options = llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(&r.options)
+	}
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: "ok"}}}, nil
+}
+
+func generateMaxTokens(t *testing.T, modelID string, options ...llmtypes.CallOption) int {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+	recorder := &optionsRecorder{}
+	llm := NewProviderAwareLLM(recorder, ProviderBedrock, modelID, nil, "", testLogger)
+	if _, err := llm.GenerateContent(context.Background(), []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}, options...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return recorder.options.MaxTokens
+}
+
+func TestDefaultMaxOutputTokensAppliedForKnownModel(t *testing.T) {
+	t.Setenv("LLM_MODEL_MAX_OUTPUT_TOKENS", "")
+
+	if got := generateMaxTokens(t, "us.anthropic.claude-sonnet-4-20250514-v1:0"); got != 16384 {
+		t.Fatalf("expected the claude-sonnet-4 default of 16384, got %d", got)
+	}
+	if got := generateMaxTokens(t, "some-unknown-model"); got != 0 {
+		t.Fatalf("expected no cap for an unknown model, got %d", got)
+	}
+}
+
+func TestExplicitMaxTokensOverrideModelDefault(t *testing.T) {
+	t.Setenv("LLM_MODEL_MAX_OUTPUT_TOKENS", "claude-sonnet-4=4000,gpt-4o=0")
+
+	if got := generateMaxTokens(t, "us.anthropic.claude-sonnet-4-20250514-v1:0", llmtypes.WithMaxTokens(500)); got != 500 {
+		t.Fatalf("expected the request's max tokens to win, got %d", got)
+	}
+	if got := generateMaxTokens(t, "us.anthropic.claude-sonnet-4-20250514-v1:0"); got != 4000 {
+		t.Fatalf("expected the configured override of 4000, got %d", got)
+	}
+	if got := generateMaxTokens(t, "gpt-4o-mini"); got != 0 {
+		t.Fatalf("expected the cap removed by a 0 override, got %d", got)
+	}
+	if defaults := GetLLMDefaults().ModelMaxOutputTokens; defaults["claude-sonnet-4"] != 4000 || defaults["gpt-4.1"] != 32768 {
+		t.Fatalf("expected the models endpoint to surface the effective caps, got %v", defaults)
+	}
+}
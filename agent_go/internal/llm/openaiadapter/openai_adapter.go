@@ -65,6 +65,11 @@ func (o *OpenAIAdapter) GenerateContent(ctx context.Context, messages []llmtypes
 	// Some newer models (o1, o3, o4, gpt-4.1) don't support max_tokens and require max_completion_tokens instead
 	// To avoid parameter compatibility issues, we omit it entirely
 
+	// Set stop sequences
+	if len(opts.StopSequences) > 0 {
+		params.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: opts.StopSequences}
+	}
+
 	// Handle JSON mode if specified
 	if opts.JSONMode {
 		jsonObjParam := shared.NewResponseFormatJSONObjectParam()
@@ -46,6 +46,33 @@ const (
 	ProviderVertex     Provider = "vertex"
 )
 
+// supportedProviders lists the providers ValidateProvider accepts, in display order
+var supportedProviders = []Provider{ProviderBedrock, ProviderOpenAI, ProviderAnthropic, ProviderOpenRouter, ProviderVertex}
+
+// SupportedProviders returns the providers ValidateProvider accepts
+func SupportedProviders() []Provider {
+	return append([]Provider(nil), supportedProviders...)
+}
+
+// DefaultOpenRouterBaseURL is the OpenRouter API used unless OPENROUTER_BASE_URL is set
+const DefaultOpenRouterBaseURL = "https://openrouter.ai/api/v1"
+
+// openRouterBaseURL returns the OpenRouter API base URL (OPENROUTER_BASE_URL overrides the default)
+func openRouterBaseURL() string {
+	if baseURL := os.Getenv("OPENROUTER_BASE_URL"); baseURL != "" {
+		return strings.TrimRight(baseURL, "/")
+	}
+	return DefaultOpenRouterBaseURL
+}
+
+// openRouterAPIKey returns the OpenRouter API key, accepting the older OPEN_ROUTER_API_KEY name
+func openRouterAPIKey() string {
+	if apiKey := os.Getenv("OPENROUTER_API_KEY"); apiKey != "" {
+		return apiKey
+	}
+	return os.Getenv("OPEN_ROUTER_API_KEY")
+}
+
 // Config holds configuration for LLM initialization
 type Config struct {
 	Provider    Provider
@@ -73,6 +100,9 @@ func InitializeLLM(config Config) (llmtypes.Model, error) {
 	case ProviderOpenAI:
 		llm, err = initializeOpenAIWithFallback(config)
 	case ProviderAnthropic:
+		if config.ModelID == "" {
+			config.ModelID = GetDefaultModel(ProviderAnthropic)
+		}
 		llm, err = initializeAnthropic(config)
 	case ProviderOpenRouter:
 		llm, err = initializeOpenRouterWithFallback(config)
@@ -381,7 +411,7 @@ func initializeAnthropic(config Config) (llmtypes.Model, error) {
 	// Use provided model or default
 	modelID := config.ModelID
 	if modelID == "" {
-		modelID = "claude-3-5-sonnet-20241022"
+		modelID = GetDefaultModel(ProviderAnthropic)
 	}
 
 	logger := config.Logger
@@ -430,29 +460,30 @@ func initializeOpenRouter(config Config) (llmtypes.Model, error) {
 	emitLLMInitializationStart(config.Tracers, string(config.Provider), config.ModelID, config.Temperature, config.TraceID, llmMetadata)
 
 	// Check for API key
-	if os.Getenv("OPEN_ROUTER_API_KEY") == "" {
-		return nil, fmt.Errorf("OPEN_ROUTER_API_KEY environment variable is required for OpenRouter provider")
+	apiKey := openRouterAPIKey()
+	if apiKey == "" {
+		return nil, fmt.Errorf("OPENROUTER_API_KEY (or OPEN_ROUTER_API_KEY) environment variable is required for OpenRouter provider")
 	}
 
 	// Set default model if not specified
 	modelID := config.ModelID
 	if modelID == "" {
-		modelID = "moonshotai/kimi-k2"
+		modelID = GetDefaultModel(ProviderOpenRouter)
 	}
 
+	baseURL := openRouterBaseURL()
 	logger := config.Logger
-	logger.Infof("🔧 Initializing OpenRouter LLM - model_id: %s, base_url: https://openrouter.ai/api/v1", modelID)
+	logger.Infof("🔧 Initializing OpenRouter LLM - model_id: %s, base_url: %s", modelID, baseURL)
 
 	// 🆕 DETAILED OPENROUTER INITIALIZATION LOGGING
 	logger.Infof("🔧 [DEBUG] Creating OpenRouter LLM with OpenAI client...")
 	logger.Infof("🔧 [DEBUG] Model: %s", modelID)
-	logger.Infof("🔧 [DEBUG] Base URL: https://openrouter.ai/api/v1")
-	logger.Infof("🔧 [DEBUG] API Key present: %v", os.Getenv("OPEN_ROUTER_API_KEY") != "")
+	logger.Infof("🔧 [DEBUG] Base URL: %s", baseURL)
 
 	// Create OpenAI SDK client with OpenRouter base URL
 	clientOptions := []option.RequestOption{
-		option.WithAPIKey(os.Getenv("OPEN_ROUTER_API_KEY")),
-		option.WithBaseURL("https://openrouter.ai/api/v1"),
+		option.WithAPIKey(apiKey),
+		option.WithBaseURL(baseURL),
 	}
 
 	// Add optional OpenRouter headers if provided
@@ -653,6 +684,19 @@ func GetDefaultFallbackModels(provider Provider) []string {
 			}
 			return models
 		}
+		// OpenRouter serves every vendor behind one key, so fall back across vendors by default
+		return []string{"openai/gpt-4.1-mini", "anthropic/claude-sonnet-4"}
+	case ProviderAnthropic:
+		// Get fallback models from environment variable
+		fallbackModelsEnv := os.Getenv("ANTHROPIC_FALLBACK_MODELS")
+		if fallbackModelsEnv != "" {
+			// Split by comma and trim whitespace
+			models := strings.Split(fallbackModelsEnv, ",")
+			for i, model := range models {
+				models[i] = strings.TrimSpace(model)
+			}
+			return models
+		}
 		// No fallback models if environment variable is not set
 		return []string{}
 	case ProviderVertex:
@@ -677,8 +721,11 @@ func GetDefaultFallbackModels(provider Provider) []string {
 func GetCrossProviderFallbackModels(provider Provider) []string {
 	switch provider {
 	case ProviderBedrock:
-		// Get OpenAI cross-provider fallback models
-		openaiFallbackEnv := os.Getenv("BEDROCK_OPENAI_FALLBACK_MODELS")
+		// Get cross-provider fallback models (BEDROCK_OPENAI_FALLBACK_MODELS is the older name)
+		openaiFallbackEnv := os.Getenv("BEDROCK_CROSS_FALLBACK_MODELS")
+		if openaiFallbackEnv == "" {
+			openaiFallbackEnv = os.Getenv("BEDROCK_OPENAI_FALLBACK_MODELS")
+		}
 		if openaiFallbackEnv != "" {
 			// Split by comma and trim whitespace
 			models := strings.Split(openaiFallbackEnv, ",")
@@ -705,19 +752,46 @@ func GetCrossProviderFallbackModels(provider Provider) []string {
 		}
 		// No cross-provider fallbacks if environment variable is not set
 		return []string{}
+	case ProviderAnthropic:
+		// Get cross-provider fallback models for Anthropic (e.g. the same Claude models on Bedrock)
+		crossFallbackEnv := os.Getenv("ANTHROPIC_CROSS_FALLBACK_MODELS")
+		if crossFallbackEnv != "" {
+			// Split by comma and trim whitespace
+			models := strings.Split(crossFallbackEnv, ",")
+			for i, model := range models {
+				models[i] = strings.TrimSpace(model)
+			}
+			return models
+		}
+		// No cross-provider fallbacks if environment variable is not set
+		return []string{}
 	default:
 		return []string{}
 	}
 }
 
-// ValidateProvider checks if the provider is supported
+// GetCrossProviderFallbackProvider returns the provider of provider's cross-provider fallback
+// models from <PROVIDER>_CROSS_FALLBACK_PROVIDER (e.g. BEDROCK_CROSS_FALLBACK_PROVIDER=anthropic),
+// defaulting to OpenAI
+func GetCrossProviderFallbackProvider(provider Provider) string {
+	if crossProvider := os.Getenv(strings.ToUpper(string(provider)) + "_CROSS_FALLBACK_PROVIDER"); crossProvider != "" {
+		return crossProvider
+	}
+	return string(ProviderOpenAI)
+}
+
+// ValidateProvider checks if the provider is supported and returns its canonical name.
+// Provider names are case-insensitive and surrounding whitespace is ignored.
 func ValidateProvider(provider string) (Provider, error) {
-	switch Provider(provider) {
-	case ProviderBedrock, ProviderOpenAI, ProviderAnthropic, ProviderOpenRouter, ProviderVertex:
-		return Provider(provider), nil
-	default:
-		return "", fmt.Errorf("unsupported provider: %s. Supported providers: bedrock, openai, anthropic, openrouter, vertex", provider)
+	normalized := Provider(strings.ToLower(strings.TrimSpace(provider)))
+	names := make([]string, 0, len(supportedProviders))
+	for _, supported := range supportedProviders {
+		if normalized == supported {
+			return supported, nil
+		}
+		names = append(names, string(supported))
 	}
+	return "", fmt.Errorf("unsupported provider: %s. Supported providers: %s", provider, strings.Join(names, ", "))
 }
 
 // ProviderAwareLLM is a wrapper around LLM that preserves provider information
@@ -773,6 +847,12 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 	// 🆕 GOROUTINE DEBUGGING
 	p.logger.Infof("🧵 [DEBUG] Goroutine count before LLM call: %d", runtime.NumGoroutine())
 
+	// Cap the output of calls that don't set max tokens at the model's default
+	var defaultMaxTokens int
+	if options, defaultMaxTokens = withDefaultMaxOutputTokens(p.modelID, options); defaultMaxTokens > 0 {
+		p.logger.Infof("🔧 Applying default max output tokens for %s: %d", p.modelID, defaultMaxTokens)
+	}
+
 	// Automatically add usage parameter for OpenRouter requests to get cache token information
 	if p.provider == ProviderOpenRouter {
 		p.logger.Infof("🔧 Adding OpenRouter usage parameter for cache token information")
@@ -1340,6 +1420,10 @@ type LLMDefaultsResponse struct {
 	BedrockConfig    map[string]interface{} `json:"bedrock_config"`
 	OpenaiConfig     map[string]interface{} `json:"openai_config"`
 	AvailableModels  map[string][]string    `json:"available_models"`
+	// Output token cap per model ID fragment, applied when a request doesn't set max tokens
+	ModelMaxOutputTokens map[string]int `json:"model_max_output_tokens"`
+	// Context window per model ID fragment, used to compact oversized histories
+	ModelContextWindows map[string]int `json:"model_context_windows"`
 }
 
 // APIKeyValidationRequest represents a request to validate an API key
@@ -1409,10 +1493,7 @@ func GetLLMDefaults() LLMDefaultsResponse {
 	}
 
 	// Get API keys from environment for prefilling
-	openrouterAPIKey := os.Getenv("OPENROUTER_API_KEY")
-	if openrouterAPIKey == "" {
-		openrouterAPIKey = os.Getenv("OPEN_ROUTER_API_KEY") // Fallback to old naming
-	}
+	openrouterAPIKey := openRouterAPIKey()
 	openaiAPIKey := os.Getenv("OPENAI_API_KEY")
 
 	// Bedrock configuration
@@ -1546,6 +1627,8 @@ func GetLLMDefaults() LLMDefaultsResponse {
 			"openrouter": getOpenRouterAvailableModels(),
 			"openai":     getOpenAIAvailableModels(),
 		},
+		ModelMaxOutputTokens: ModelMaxOutputTokenDefaults(),
+		ModelContextWindows:  ModelContextWindowDefaults(),
 	}
 }
 
@@ -1614,7 +1697,7 @@ func validateOpenRouterAPIKey(apiKey string) (bool, string, error) {
 	// Test the API key by making a request to OpenRouter
 	logger.Infof("[OPENROUTER VALIDATION] Making request to OpenRouter API")
 	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", "https://openrouter.ai/api/v1/models", nil)
+	req, err := http.NewRequest("GET", openRouterBaseURL()+"/models", nil)
 	if err != nil {
 		logger.Errorf("[OPENROUTER VALIDATION ERROR] Failed to create request: %w", err)
 		return false, "", fmt.Errorf("failed to create request: %w", err)
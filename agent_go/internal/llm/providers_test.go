@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+func TestValidateProviderParsesSupportedProviders(t *testing.T) {
+	cases := map[string]Provider{
+		"openrouter":   ProviderOpenRouter,
+		" OpenRouter ": ProviderOpenRouter,
+		"bedrock":      ProviderBedrock,
+		"OPENAI":       ProviderOpenAI,
+		"anthropic":    ProviderAnthropic,
+		"vertex":       ProviderVertex,
+	}
+	for input, want := range cases {
+		got, err := ValidateProvider(input)
+		if err != nil || got != want {
+			t.Errorf("ValidateProvider(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+
+	if _, err := ValidateProvider("cohere"); err == nil {
+		t.Fatal("expected an unknown provider to be rejected")
+	}
+}
+
+func TestOpenRouterDefaults(t *testing.T) {
+	t.Setenv("OPENROUTER_PRIMARY_MODEL", "")
+	t.Setenv("OPENROUTER_FALLBACK_MODELS", "")
+	t.Setenv("OPENROUTER_BASE_URL", "")
+	if model := GetDefaultModel(ProviderOpenRouter); model != "moonshotai/kimi-k2" {
+		t.Fatalf("unexpected default model %q", model)
+	}
+	if fallbacks := GetDefaultFallbackModels(ProviderOpenRouter); len(fallbacks) == 0 {
+		t.Fatal("expected default OpenRouter fallback models")
+	}
+	if baseURL := openRouterBaseURL(); baseURL != DefaultOpenRouterBaseURL {
+		t.Fatalf("unexpected default base URL %q", baseURL)
+	}
+
+	t.Setenv("OPENROUTER_PRIMARY_MODEL", "openai/gpt-4o")
+	t.Setenv("OPENROUTER_FALLBACK_MODELS", "a/one, b/two")
+	t.Setenv("OPENROUTER_BASE_URL", "https://proxy.example.com/v1/")
+	if model := GetDefaultModel(ProviderOpenRouter); model != "openai/gpt-4o" {
+		t.Fatalf("expected the configured primary model, got %q", model)
+	}
+	if fallbacks := GetDefaultFallbackModels(ProviderOpenRouter); !reflect.DeepEqual(fallbacks, []string{"a/one", "b/two"}) {
+		t.Fatalf("expected the configured fallback models, got %v", fallbacks)
+	}
+	if baseURL := openRouterBaseURL(); baseURL != "https://proxy.example.com/v1" {
+		t.Fatalf("expected the configured base URL, got %q", baseURL)
+	}
+}
+
+func TestOpenRouterAPIKeyAcceptsLegacyName(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "")
+	t.Setenv("OPEN_ROUTER_API_KEY", "legacy-key")
+	if key := openRouterAPIKey(); key != "legacy-key" {
+		t.Fatalf("expected the legacy key, got %q", key)
+	}
+	t.Setenv("OPENROUTER_API_KEY", "new-key")
+	if key := openRouterAPIKey(); key != "new-key" {
+		t.Fatalf("expected OPENROUTER_API_KEY to take precedence, got %q", key)
+	}
+}
+
+func TestInitializeAnthropicWithFakeKey(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test")
+	t.Setenv("ANTHROPIC_PRIMARY_MODEL", "")
+	testLogger := logger.CreateDiscardLogger("error")
+
+	model, err := InitializeLLM(Config{Provider: ProviderAnthropic, Temperature: 0.2, Logger: testLogger})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	aware, ok := model.(*ProviderAwareLLM)
+	if !ok {
+		t.Fatalf("expected a provider-aware LLM, got %T", model)
+	}
+	if aware.GetProvider() != ProviderAnthropic || aware.GetModelID() != GetDefaultModel(ProviderAnthropic) {
+		t.Fatalf("unexpected provider %q and model %q", aware.GetProvider(), aware.GetModelID())
+	}
+
+	model, err = InitializeLLM(Config{Provider: ProviderAnthropic, ModelID: "claude-sonnet-4-20250514", Logger: testLogger})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id := model.(*ProviderAwareLLM).GetModelID(); id != "claude-sonnet-4-20250514" {
+		t.Fatalf("expected the requested model, got %q", id)
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	if _, err := InitializeLLM(Config{Provider: ProviderAnthropic, Logger: testLogger}); err == nil {
+		t.Fatal("expected an error without an API key")
+	}
+}
+
+func TestCrossProviderFallbackToAnthropic(t *testing.T) {
+	t.Setenv("BEDROCK_CROSS_FALLBACK_PROVIDER", "anthropic")
+	t.Setenv("BEDROCK_CROSS_FALLBACK_MODELS", "claude-sonnet-4-20250514")
+	if provider := GetCrossProviderFallbackProvider(ProviderBedrock); provider != string(ProviderAnthropic) {
+		t.Fatalf("expected anthropic, got %q", provider)
+	}
+	if models := GetCrossProviderFallbackModels(ProviderBedrock); !reflect.DeepEqual(models, []string{"claude-sonnet-4-20250514"}) {
+		t.Fatalf("unexpected cross-provider models %v", models)
+	}
+
+	t.Setenv("OPENAI_CROSS_FALLBACK_PROVIDER", "")
+	if provider := GetCrossProviderFallbackProvider(ProviderOpenAI); provider != string(ProviderOpenAI) {
+		t.Fatalf("expected the openai default, got %q", provider)
+	}
+}
@@ -0,0 +1,243 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// RecordingMode selects whether a RecordingModel calls the wrapped model or serves a cassette
+type RecordingMode string
+
+const (
+	// RecordingModeRecord proxies every call to the wrapped model and writes it to the cassette
+	RecordingModeRecord RecordingMode = "record"
+	// RecordingModeReplay serves recorded responses without calling any model
+	RecordingModeReplay RecordingMode = "replay"
+)
+
+// ErrNoRecordedResponse is returned in replay mode for a request the cassette does not hold
+var ErrNoRecordedResponse = errors.New("no recorded response for request")
+
+// Cassette is the file format of recorded LLM calls
+type Cassette struct {
+	Interactions []CassetteInteraction `json:"interactions"`
+}
+
+// CassetteInteraction is one recorded request/response pair. Request is kept for readability
+// only; replay matches on RequestHash.
+type CassetteInteraction struct {
+	RequestHash string                    `json:"request_hash"`
+	Request     json.RawMessage           `json:"request"`
+	Response    *llmtypes.ContentResponse `json:"response,omitempty"`
+	Error       string                    `json:"error,omitempty"`
+}
+
+// RecordingOption configures a RecordingModel
+type RecordingOption func(*RecordingModel)
+
+// WithRequestNormalizer rewrites message text before requests are hashed, e.g. to blank out
+// dates or generated IDs that change between runs
+func WithRequestNormalizer(normalize func(string) string) RecordingOption {
+	return func(m *RecordingModel) {
+		m.normalize = normalize
+	}
+}
+
+// RecordingModel wraps a Model to snapshot its responses for regression tests. Requests are
+// matched by a hash of their messages and call options (streaming callbacks excluded); identical
+// requests are answered in the order they were recorded.
+type RecordingModel struct {
+	model     llmtypes.Model
+	mode      RecordingMode
+	path      string
+	normalize func(string) string
+
+	mu       sync.Mutex
+	cassette Cassette
+	served   map[string]int // request hash -> responses already replayed
+}
+
+// NewRecordingModel creates a recording wrapper around model backed by the cassette at path.
+// In replay mode the cassette must exist and model may be nil.
+func NewRecordingModel(model llmtypes.Model, mode RecordingMode, path string, options ...RecordingOption) (*RecordingModel, error) {
+	m := &RecordingModel{
+		model:  model,
+		mode:   mode,
+		path:   path,
+		served: make(map[string]int),
+	}
+	for _, option := range options {
+		option(m)
+	}
+
+	switch mode {
+	case RecordingModeRecord:
+		if model == nil {
+			return nil, fmt.Errorf("record mode requires a model to record")
+		}
+	case RecordingModeReplay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &m.cassette); err != nil {
+			return nil, fmt.Errorf("decode cassette %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported recording mode: %s", mode)
+	}
+	return m, nil
+}
+
+// GenerateContent records or replays the call depending on the mode
+func (m *RecordingModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	request, hash, err := m.requestKey(messages, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.mode == RecordingModeReplay {
+		return m.replay(hash)
+	}
+
+	resp, genErr := m.model.GenerateContent(ctx, messages, options...)
+	interaction := CassetteInteraction{RequestHash: hash, Request: request, Response: resp}
+	if genErr != nil {
+		interaction.Error = genErr.Error()
+	}
+
+	m.mu.Lock()
+	m.cassette.Interactions = append(m.cassette.Interactions, interaction)
+	saveErr := m.saveLocked()
+	m.mu.Unlock()
+	if saveErr != nil {
+		return nil, saveErr
+	}
+	return resp, genErr
+}
+
+// replay serves the next recorded response for the request hash
+func (m *RecordingModel) replay(hash string) (*llmtypes.ContentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	skip := m.served[hash]
+	for _, interaction := range m.cassette.Interactions {
+		if interaction.RequestHash != hash {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		m.served[hash]++
+		if interaction.Error != "" {
+			return nil, errors.New(interaction.Error)
+		}
+		return interaction.Response, nil
+	}
+	return nil, fmt.Errorf("%w %s in %s", ErrNoRecordedResponse, hash, m.path)
+}
+
+// saveLocked writes the cassette; callers hold m.mu
+func (m *RecordingModel) saveLocked() error {
+	data, err := json.MarshalIndent(m.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cassette: %w", err)
+	}
+	if dir := filepath.Dir(m.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create cassette directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(m.path, data, 0o644); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return nil
+}
+
+// recordedPart is a message part tagged with its Go type so different part kinds never hash alike
+type recordedPart struct {
+	Kind  string      `json:"kind"`
+	Value interface{} `json:"value"`
+}
+
+type recordedMessage struct {
+	Role  llmtypes.ChatMessageType `json:"role"`
+	Parts []recordedPart           `json:"parts"`
+}
+
+// recordedRequest is the normalized form of a request that is hashed and stored in the cassette
+type recordedRequest struct {
+	Messages    []recordedMessage    `json:"messages"`
+	Model       string               `json:"model,omitempty"`
+	Temperature float64              `json:"temperature,omitempty"`
+	MaxTokens   int                  `json:"max_tokens,omitempty"`
+	JSONMode    bool                 `json:"json_mode,omitempty"`
+	Tools       []llmtypes.Tool      `json:"tools,omitempty"`
+	ToolChoice  *llmtypes.ToolChoice `json:"tool_choice,omitempty"`
+	Metadata    *llmtypes.Metadata   `json:"metadata,omitempty"`
+}
+
+// requestKey returns the normalized request and its hash
+func (m *RecordingModel) requestKey(messages []llmtypes.MessageContent, options []llmtypes.CallOption) (json.RawMessage, string, error) {
+	var callOptions llmtypes.CallOptions
+	for _, option := range options {
+		option(&callOptions)
+	}
+
+	request := recordedRequest{
+		Messages:    make([]recordedMessage, 0, len(messages)),
+		Model:       callOptions.Model,
+		Temperature: callOptions.Temperature,
+		MaxTokens:   callOptions.MaxTokens,
+		JSONMode:    callOptions.JSONMode,
+		Tools:       callOptions.Tools,
+		ToolChoice:  callOptions.ToolChoice,
+		Metadata:    callOptions.Metadata,
+	}
+	for _, message := range messages {
+		recorded := recordedMessage{Role: message.Role, Parts: make([]recordedPart, 0, len(message.Parts))}
+		for _, part := range message.Parts {
+			recorded.Parts = append(recorded.Parts, recordedPart{Kind: fmt.Sprintf("%T", part), Value: m.normalizePart(part)})
+		}
+		request.Messages = append(request.Messages, recorded)
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, "", fmt.Errorf("encode request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return data, hex.EncodeToString(sum[:]), nil
+}
+
+// normalizePart trims text and applies the request normalizer to the text-bearing parts
+func (m *RecordingModel) normalizePart(part llmtypes.ContentPart) llmtypes.ContentPart {
+	normalize := func(text string) string {
+		text = strings.TrimSpace(text)
+		if m.normalize != nil {
+			text = m.normalize(text)
+		}
+		return text
+	}
+	switch p := part.(type) {
+	case llmtypes.TextContent:
+		p.Text = normalize(p.Text)
+		return p
+	case llmtypes.ToolCallResponse:
+		p.Content = normalize(p.Content)
+		return p
+	}
+	return part
+}
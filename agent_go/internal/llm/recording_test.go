@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// countingModel answers with a call counter so a replay served by a live model would differ
+type countingModel struct {
+	calls int
+}
+
+func (m *countingModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	m.calls++
+	if text := messages[len(messages)-1].Parts[0].(llmtypes.TextContent).Text; text == "fail" {
+		return nil, fmt.Errorf("throttled on call %d", m.calls)
+	}
+	tokens := 10 * m.calls
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{
+		Content:        fmt.Sprintf("answer %d", m.calls),
+		StopReason:     "stop",
+		ToolCalls:      []llmtypes.ToolCall{{ID: "call-1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}}},
+		GenerationInfo: &llmtypes.GenerationInfo{InputTokens: &tokens},
+	}}}, nil
+}
+
+type recordedCall struct {
+	messages []llmtypes.MessageContent
+	options  []llmtypes.CallOption
+}
+
+func recordingCalls() []recordedCall {
+	weatherTool := llmtypes.Tool{Type: "function", Function: &llmtypes.FunctionDefinition{Name: "get_weather", Parameters: llmtypes.NewParameters(map[string]interface{}{"type": "object"})}}
+	return []recordedCall{
+		{messages: []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "weather in Paris?")}, options: []llmtypes.CallOption{llmtypes.WithTools([]llmtypes.Tool{weatherTool})}},
+		{messages: []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "weather in Paris?")}, options: []llmtypes.CallOption{llmtypes.WithTools([]llmtypes.Tool{weatherTool}), llmtypes.WithTemperature(0.2)}},
+		{messages: []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "weather in Paris?")}, options: []llmtypes.CallOption{llmtypes.WithTools([]llmtypes.Tool{weatherTool})}},
+		{messages: []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "fail")}},
+	}
+}
+
+func TestRecordingModelReplaysRecordedResponses(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassettes", "weather.json")
+	ctx := context.Background()
+
+	recorder, err := NewRecordingModel(&countingModel{}, RecordingModeRecord, cassette)
+	if err != nil {
+		t.Fatalf("NewRecordingModel(record): %v", err)
+	}
+	var recorded []*llmtypes.ContentResponse
+	var recordedErrs []error
+	for _, call := range recordingCalls() {
+		resp, err := recorder.GenerateContent(ctx, call.messages, call.options...)
+		recorded = append(recorded, resp)
+		recordedErrs = append(recordedErrs, err)
+	}
+
+	replayer, err := NewRecordingModel(nil, RecordingModeReplay, cassette)
+	if err != nil {
+		t.Fatalf("NewRecordingModel(replay): %v", err)
+	}
+	for i, call := range recordingCalls() {
+		resp, err := replayer.GenerateContent(ctx, call.messages, call.options...)
+		if !reflect.DeepEqual(resp, recorded[i]) {
+			t.Fatalf("call %d: replayed %+v, recorded %+v", i, resp, recorded[i])
+		}
+		if (err == nil) != (recordedErrs[i] == nil) || (err != nil && err.Error() != recordedErrs[i].Error()) {
+			t.Fatalf("call %d: replayed error %v, recorded %v", i, err, recordedErrs[i])
+		}
+	}
+	// The identical first and third requests are answered in recording order
+	if recorded[0].Choices[0].Content != "answer 1" || recorded[2].Choices[0].Content != "answer 3" {
+		t.Fatalf("unexpected recorded answers %q, %q", recorded[0].Choices[0].Content, recorded[2].Choices[0].Content)
+	}
+
+	_, err = replayer.GenerateContent(ctx, []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "weather in Oslo?")})
+	if !errors.Is(err, ErrNoRecordedResponse) {
+		t.Fatalf("expected ErrNoRecordedResponse for an unrecorded request, got %v", err)
+	}
+}
+
+func TestRecordingModelNormalizesRequests(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "dated.json")
+	ctx := context.Background()
+	dates := regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+	normalizer := WithRequestNormalizer(func(text string) string { return dates.ReplaceAllString(text, "<date>") })
+
+	recorder, err := NewRecordingModel(&countingModel{}, RecordingModeRecord, cassette, normalizer)
+	if err != nil {
+		t.Fatalf("NewRecordingModel(record): %v", err)
+	}
+	if _, err := recorder.GenerateContent(ctx, []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeSystem, "Today is 2026-01-02."), llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	replayer, err := NewRecordingModel(nil, RecordingModeReplay, cassette, normalizer)
+	if err != nil {
+		t.Fatalf("NewRecordingModel(replay): %v", err)
+	}
+	resp, err := replayer.GenerateContent(ctx, []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeSystem, "Today is 2026-03-04.  "), llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")})
+	if err != nil || resp.Choices[0].Content != "answer 1" {
+		t.Fatalf("expected the normalized request to replay, got %v, %v", resp, err)
+	}
+
+	if _, err := NewRecordingModel(nil, RecordingModeReplay, filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected replay without a cassette to fail")
+	}
+}
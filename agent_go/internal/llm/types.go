@@ -42,6 +42,7 @@ var (
 	WithModel         = llmtypes.WithModel
 	WithTemperature   = llmtypes.WithTemperature
 	WithMaxTokens     = llmtypes.WithMaxTokens
+	WithStopSequences = llmtypes.WithStopSequences
 	WithJSONMode      = llmtypes.WithJSONMode
 	WithTools         = llmtypes.WithTools
 	WithToolChoice    = llmtypes.WithToolChoice
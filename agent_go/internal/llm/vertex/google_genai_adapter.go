@@ -100,6 +100,11 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 		config.MaxOutputTokens = int32(opts.MaxTokens)
 	}
 
+	// Set stop sequences
+	if len(opts.StopSequences) > 0 {
+		config.StopSequences = opts.StopSequences
+	}
+
 	// Handle JSON mode if specified
 	if opts.JSONMode {
 		config.ResponseMIMEType = "application/json"
@@ -0,0 +1,77 @@
+package llmtypes
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// storedMessage is the JSON form of a conversation message; message parts are interfaces
+// and cannot be decoded directly
+type storedMessage struct {
+	Role  ChatMessageType `json:"role"`
+	Parts []storedPart    `json:"parts"`
+}
+
+type storedPart struct {
+	Type       string `json:"type"` // "text", "tool_call" or "tool_response"
+	Text       string `json:"text,omitempty"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Arguments  string `json:"arguments,omitempty"`
+	Content    string `json:"content,omitempty"`
+}
+
+// MarshalMessages encodes a conversation as JSON that UnmarshalMessages restores
+func MarshalMessages(history []MessageContent) ([]byte, error) {
+	messages := make([]storedMessage, 0, len(history))
+	for _, msg := range history {
+		stored := storedMessage{Role: msg.Role}
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case TextContent:
+				stored.Parts = append(stored.Parts, storedPart{Type: "text", Text: p.Text})
+			case ToolCall:
+				call := storedPart{Type: "tool_call", ToolCallID: p.ID}
+				if p.FunctionCall != nil {
+					call.Name = p.FunctionCall.Name
+					call.Arguments = p.FunctionCall.Arguments
+				}
+				stored.Parts = append(stored.Parts, call)
+			case ToolCallResponse:
+				stored.Parts = append(stored.Parts, storedPart{Type: "tool_response", ToolCallID: p.ToolCallID, Name: p.Name, Content: p.Content})
+			default:
+				return nil, fmt.Errorf("unsupported message part %T", part)
+			}
+		}
+		messages = append(messages, stored)
+	}
+	return json.Marshal(messages)
+}
+
+// UnmarshalMessages decodes a conversation encoded by MarshalMessages
+func UnmarshalMessages(data []byte) ([]MessageContent, error) {
+	var messages []storedMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, err
+	}
+	history := make([]MessageContent, 0, len(messages))
+	for _, stored := range messages {
+		msg := MessageContent{Role: stored.Role}
+		for _, part := range stored.Parts {
+			switch part.Type {
+			case "text":
+				msg.Parts = append(msg.Parts, TextContent{Text: part.Text})
+			case "tool_call":
+				msg.Parts = append(msg.Parts, ToolCall{
+					ID:           part.ToolCallID,
+					Type:         "function",
+					FunctionCall: &FunctionCall{Name: part.Name, Arguments: part.Arguments},
+				})
+			case "tool_response":
+				msg.Parts = append(msg.Parts, ToolCallResponse{ToolCallID: part.ToolCallID, Name: part.Name, Content: part.Content})
+			}
+		}
+		history = append(history, msg)
+	}
+	return history, nil
+}
@@ -21,6 +21,13 @@ func WithMaxTokens(maxTokens int) CallOption {
 	}
 }
 
+// WithStopSequences ends generation when the model produces any of the sequences
+func WithStopSequences(sequences []string) CallOption {
+	return func(opts *CallOptions) {
+		opts.StopSequences = sequences
+	}
+}
+
 // WithJSONMode enables JSON mode
 func WithJSONMode() CallOption {
 	return func(opts *CallOptions) {
@@ -183,6 +183,7 @@ type CallOptions struct {
 	Model         string
 	Temperature   float64
 	MaxTokens     int
+	StopSequences []string // Sequences that end generation when produced
 	JSONMode      bool
 	Tools         []Tool
 	ToolChoice    *ToolChoice
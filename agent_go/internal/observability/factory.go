@@ -1,6 +1,8 @@
 package observability
 
 import (
+	"fmt"
+	"net/url"
 	"strings"
 
 	"mcp-agent/agent_go/internal/utils"
@@ -9,6 +11,8 @@ import (
 const (
 	ProviderLangfuse = "langfuse"
 	ProviderNoop     = "noop"
+	ProviderJSONFile = "jsonfile"
+	ProviderOTel     = "otel"
 )
 
 // GetTracer returns a Tracer implementation based on the provided provider string.
@@ -22,6 +26,18 @@ func GetTracer(provider string) Tracer {
 		}
 		// Fallback to noop if Langfuse init fails
 		return NoopTracer{}
+	case ProviderJSONFile:
+		if tracer, err := NewJSONFileTracer(jsonTracePathFromEnv()); err == nil {
+			return tracer
+		}
+		// Fallback to noop if the trace file cannot be opened
+		return NoopTracer{}
+	case ProviderOTel:
+		if tracer, err := NewOTelTracer(otlpEndpointFromEnv()); err == nil {
+			return tracer
+		}
+		// Fallback to noop if the OTLP exporter cannot be created
+		return NoopTracer{}
 	case "noop":
 		return NoopTracer{}
 	default:
@@ -40,9 +56,71 @@ func GetTracerWithLogger(provider string, logger utils.ExtendedLogger) Tracer {
 		}
 		// Fallback to noop if Langfuse init fails
 		return NoopTracer{}
+	case ProviderJSONFile:
+		tracer, err := NewJSONFileTracer(jsonTracePathFromEnv())
+		if err == nil {
+			return tracer
+		}
+		logger.Warnf("JSON trace file unavailable, tracing disabled: %v", err)
+		return NoopTracer{}
+	case ProviderOTel:
+		tracer, err := NewOTelTracer(otlpEndpointFromEnv())
+		if err == nil {
+			return tracer
+		}
+		logger.Warnf("OTLP trace exporter unavailable, tracing disabled: %v", err)
+		return NoopTracer{}
 	case "noop":
 		return NoopTracer{}
 	default:
 		return NoopTracer{}
 	}
 }
+
+// TracingDestination selects where traces go: a provider and, for Langfuse, its host and credentials
+type TracingDestination struct {
+	Provider  string
+	Host      string // Langfuse host; empty uses Langfuse cloud
+	PublicKey string
+	SecretKey string
+}
+
+// Validate checks that the destination names a known provider with the settings it needs
+func (d TracingDestination) Validate() error {
+	switch strings.ToLower(d.Provider) {
+	case ProviderNoop:
+		return nil
+	case ProviderLangfuse:
+		if d.PublicKey == "" || d.SecretKey == "" {
+			return fmt.Errorf("langfuse tracing requires public_key and secret_key")
+		}
+		if d.Host != "" {
+			parsed, err := url.Parse(d.Host)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("invalid langfuse host %q: must be an http(s) URL", d.Host)
+			}
+		}
+		return nil
+	case "":
+		return fmt.Errorf("tracing provider is required")
+	default:
+		return fmt.Errorf("unsupported tracing provider %q, must be one of: %s, %s", d.Provider, ProviderLangfuse, ProviderNoop)
+	}
+}
+
+// GetTracerForDestination returns a tracer for an explicit destination. Unlike GetTracer it does not
+// fall back to noop: an invalid destination or failed authentication is returned as an error.
+func GetTracerForDestination(destination TracingDestination, logger utils.ExtendedLogger) (Tracer, error) {
+	if err := destination.Validate(); err != nil {
+		return nil, err
+	}
+	if strings.ToLower(destination.Provider) == ProviderNoop {
+		return NoopTracer{}, nil
+	}
+
+	host := strings.TrimRight(destination.Host, "/")
+	if host == "" {
+		host = "https://cloud.langfuse.com"
+	}
+	return NewLangfuseTracerForDestination(host, destination.PublicKey, destination.SecretKey, logger)
+}
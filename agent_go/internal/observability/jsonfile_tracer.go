@@ -0,0 +1,353 @@
+package observability
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultJSONTracePath is used when JSON_TRACE_PATH is not set
+const defaultJSONTracePath = "traces.jsonl"
+
+// JSONFileTracer writes traces as JSON lines: one object per span when it ends and one per trace
+// when it ends. The file is flushed on EndTrace, so a completed trace is on disk with all its spans.
+type JSONFileTracer struct {
+	path string
+
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+
+	traces map[string]*jsonTraceRecord
+	spans  map[string]*jsonSpanRecord
+
+	// Hierarchy tracking for agent events, mirroring the Langfuse tracer: traceID -> open span ID
+	agentSpans         map[string]string
+	conversationSpans  map[string]string
+	llmGenerationSpans map[string]string
+	toolSpans          map[string][]string // open tool spans, most recent last
+}
+
+// jsonTraceRecord is the JSON line written when a trace ends
+type jsonTraceRecord struct {
+	Kind      string                 `json:"kind"` // "trace"
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Input     interface{}            `json:"input,omitempty"`
+	Output    interface{}            `json:"output,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	StartTime time.Time              `json:"start_time"`
+	EndTime   *time.Time             `json:"end_time,omitempty"`
+}
+
+// jsonSpanRecord is the JSON line written when a span ends
+type jsonSpanRecord struct {
+	Kind      string      `json:"kind"` // "span"
+	ID        string      `json:"id"`
+	TraceID   string      `json:"trace_id"`
+	ParentID  string      `json:"parent_id,omitempty"` // empty for spans directly under the trace
+	Name      string      `json:"name"`
+	Type      string      `json:"type"` // SPAN, AGENT, GENERATION, TOOL
+	Input     interface{} `json:"input,omitempty"`
+	Output    interface{} `json:"output,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	StartTime time.Time   `json:"start_time"`
+	EndTime   *time.Time  `json:"end_time,omitempty"`
+}
+
+// jsonEventRecord is the JSON line written for agent events that do not open or close a span
+type jsonEventRecord struct {
+	Kind      string      `json:"kind"` // "event"
+	Type      string      `json:"type"`
+	TraceID   string      `json:"trace_id,omitempty"`
+	ParentID  string      `json:"parent_id,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+var (
+	// One tracer per path so tracers of concurrent agents never interleave partial lines
+	jsonFileTracers   = make(map[string]*JSONFileTracer)
+	jsonFileTracersMu sync.Mutex
+)
+
+// NewJSONFileTracer returns the JSON file tracer writing to path, creating the file (and its
+// directory) if needed. Tracers for the same path are shared; traces are appended to existing content.
+func NewJSONFileTracer(path string) (*JSONFileTracer, error) {
+	if path == "" {
+		path = defaultJSONTracePath
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON trace path %q: %w", path, err)
+	}
+
+	jsonFileTracersMu.Lock()
+	defer jsonFileTracersMu.Unlock()
+	if tracer, exists := jsonFileTracers[absPath]; exists {
+		return tracer, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create JSON trace directory: %w", err)
+	}
+	file, err := os.OpenFile(absPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JSON trace file: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	tracer := &JSONFileTracer{
+		path:               absPath,
+		file:               file,
+		writer:             writer,
+		traces:             make(map[string]*jsonTraceRecord),
+		spans:              make(map[string]*jsonSpanRecord),
+		agentSpans:         make(map[string]string),
+		conversationSpans:  make(map[string]string),
+		llmGenerationSpans: make(map[string]string),
+		toolSpans:          make(map[string][]string),
+	}
+	jsonFileTracers[absPath] = tracer
+	return tracer, nil
+}
+
+// jsonTracePathFromEnv returns JSON_TRACE_PATH or the default trace file
+func jsonTracePathFromEnv() string {
+	if path := os.Getenv("JSON_TRACE_PATH"); path != "" {
+		return path
+	}
+	return defaultJSONTracePath
+}
+
+// Path returns the absolute path of the trace file
+func (j *JSONFileTracer) Path() string {
+	return j.path
+}
+
+// StartTrace starts a new trace; it is written when EndTrace is called
+func (j *JSONFileTracer) StartTrace(name string, input interface{}) TraceID {
+	id := generateID()
+	j.mu.Lock()
+	j.startTraceLocked(id, name, input)
+	j.mu.Unlock()
+	return TraceID(id)
+}
+
+func (j *JSONFileTracer) startTraceLocked(id, name string, input interface{}) *jsonTraceRecord {
+	trace := &jsonTraceRecord{
+		Kind:      "trace",
+		ID:        id,
+		Name:      name,
+		Input:     input,
+		Metadata:  make(map[string]interface{}),
+		StartTime: time.Now(),
+	}
+	j.traces[id] = trace
+	return trace
+}
+
+// SetTraceMetadata adds a metadata entry to a trace that has not ended yet
+func (j *JSONFileTracer) SetTraceMetadata(traceID TraceID, key string, value interface{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if trace, exists := j.traces[string(traceID)]; exists {
+		trace.Metadata[key] = value
+	}
+}
+
+// EndTrace writes the trace and flushes the file
+func (j *JSONFileTracer) EndTrace(traceID TraceID, output interface{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	trace, exists := j.traces[string(traceID)]
+	if !exists {
+		return
+	}
+	delete(j.traces, string(traceID))
+	delete(j.agentSpans, string(traceID))
+	delete(j.conversationSpans, string(traceID))
+	delete(j.llmGenerationSpans, string(traceID))
+	delete(j.toolSpans, string(traceID))
+
+	endTime := time.Now()
+	trace.EndTime = &endTime
+	trace.Output = output
+	_ = j.writeLocked(trace)
+	_ = j.writer.Flush()
+}
+
+// StartSpan starts a span under a trace or, when parentID is a span ID, under that span
+func (j *JSONFileTracer) StartSpan(parentID string, name string, input interface{}) SpanID {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return SpanID(j.startSpanLocked(parentID, "SPAN", name, input))
+}
+
+func (j *JSONFileTracer) startSpanLocked(parentID, spanType, name string, input interface{}) string {
+	span := &jsonSpanRecord{
+		Kind:      "span",
+		ID:        generateID(),
+		TraceID:   parentID,
+		Name:      name,
+		Type:      spanType,
+		Input:     input,
+		StartTime: time.Now(),
+	}
+	if parent, exists := j.spans[parentID]; exists {
+		span.TraceID = parent.TraceID
+		span.ParentID = parentID
+	}
+	j.spans[span.ID] = span
+	return span.ID
+}
+
+// EndSpan writes a span with its output and, if err is non-nil, its error
+func (j *JSONFileTracer) EndSpan(spanID SpanID, output interface{}, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_ = j.endSpanLocked(string(spanID), output, err)
+}
+
+func (j *JSONFileTracer) endSpanLocked(spanID string, output interface{}, err error) error {
+	span, exists := j.spans[spanID]
+	if !exists {
+		return nil
+	}
+	delete(j.spans, spanID)
+
+	endTime := time.Now()
+	span.EndTime = &endTime
+	span.Output = output
+	if err != nil {
+		span.Error = err.Error()
+	}
+	return j.writeLocked(span)
+}
+
+// EmitEvent maps agent lifecycle events onto nested spans (agent > conversation > LLM generation >
+// tool call); other events are written as event lines under the current span
+func (j *JSONFileTracer) EmitEvent(event AgentEvent) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	traceID := event.GetTraceID()
+	switch event.GetType() {
+	case EventTypeAgentStart:
+		trace, exists := j.traces[traceID]
+		if !exists {
+			trace = j.startTraceLocked(traceID, EventTypeAgentStart, event.GetData())
+		}
+		trace.Metadata["event_type"] = EventTypeAgentStart
+		if correlationID := event.GetCorrelationID(); correlationID != "" {
+			trace.Metadata["correlation_id"] = correlationID
+		}
+		j.agentSpans[traceID] = j.startSpanLocked(traceID, "AGENT", "agent", event.GetData())
+		return nil
+	case EventTypeAgentEnd, EventTypeAgentError:
+		var err error
+		if event.GetType() == EventTypeAgentError {
+			err = fmt.Errorf("agent error")
+			if data, ok := event.GetData().(map[string]interface{}); ok {
+				if errorMsg, ok := data["error"].(string); ok {
+					err = fmt.Errorf("%s", errorMsg)
+				}
+			}
+		}
+		spanID := j.agentSpans[traceID]
+		delete(j.agentSpans, traceID)
+		return j.endSpanLocked(spanID, event.GetData(), err)
+	case EventTypeConversationStart:
+		j.conversationSpans[traceID] = j.startSpanLocked(j.parentForLocked(traceID, j.agentSpans), "SPAN", "conversation", event.GetData())
+		return nil
+	case EventTypeConversationEnd:
+		spanID := j.conversationSpans[traceID]
+		delete(j.conversationSpans, traceID)
+		return j.endSpanLocked(spanID, event.GetData(), nil)
+	case EventTypeLLMGenerationStart:
+		parentID := j.parentForLocked(traceID, j.conversationSpans, j.agentSpans)
+		j.llmGenerationSpans[traceID] = j.startSpanLocked(parentID, "GENERATION", "llm_generation", event.GetData())
+		return nil
+	case EventTypeLLMGenerationEnd:
+		spanID := j.llmGenerationSpans[traceID]
+		delete(j.llmGenerationSpans, traceID)
+		return j.endSpanLocked(spanID, event.GetData(), nil)
+	case EventTypeToolCallStart:
+		parentID := j.parentForLocked(traceID, j.llmGenerationSpans, j.conversationSpans, j.agentSpans)
+		j.toolSpans[traceID] = append(j.toolSpans[traceID], j.startSpanLocked(parentID, "TOOL", "tool_call", event.GetData()))
+		return nil
+	case EventTypeToolCallEnd:
+		open := j.toolSpans[traceID]
+		if len(open) == 0 {
+			return nil
+		}
+		j.toolSpans[traceID] = open[:len(open)-1]
+		return j.endSpanLocked(open[len(open)-1], event.GetData(), nil)
+	default:
+		return j.writeLocked(&jsonEventRecord{
+			Kind:      "event",
+			Type:      event.GetType(),
+			TraceID:   traceID,
+			ParentID:  j.parentForLocked(traceID, j.llmGenerationSpans, j.conversationSpans, j.agentSpans),
+			Data:      event.GetData(),
+			Timestamp: event.GetTimestamp(),
+		})
+	}
+}
+
+// parentForLocked returns the first open span of the trace in the given hierarchy levels, else the trace ID
+func (j *JSONFileTracer) parentForLocked(traceID string, levels ...map[string]string) string {
+	for _, level := range levels {
+		if spanID := level[traceID]; spanID != "" {
+			return spanID
+		}
+	}
+	return traceID
+}
+
+// EmitLLMEvent implements Tracer; LLM events are already covered by the generation spans
+func (j *JSONFileTracer) EmitLLMEvent(event LLMEvent) error {
+	return nil
+}
+
+// Flush writes buffered records to the file
+func (j *JSONFileTracer) Flush() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.writer.Flush()
+}
+
+// Close flushes and closes the trace file; later records are dropped
+func (j *JSONFileTracer) Close() error {
+	jsonFileTracersMu.Lock()
+	delete(jsonFileTracers, j.path)
+	jsonFileTracersMu.Unlock()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	flushErr := j.writer.Flush()
+	closeErr := j.file.Close()
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
+
+// writeLocked encodes one record as a JSON line. A record that cannot be encoded is replaced by
+// an error line so the file stays valid JSON lines.
+func (j *JSONFileTracer) writeLocked(record interface{}) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"kind": "encoding_error", "error": err.Error()})
+	}
+	data = append(data, '\n')
+	if _, writeErr := j.writer.Write(data); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
@@ -0,0 +1,174 @@
+package observability
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testAgentEvent is a minimal AgentEvent
+type testAgentEvent struct {
+	eventType string
+	traceID   string
+	data      interface{}
+}
+
+func (e testAgentEvent) GetType() string          { return e.eventType }
+func (e testAgentEvent) GetCorrelationID() string { return "" }
+func (e testAgentEvent) GetTimestamp() time.Time  { return time.Now() }
+func (e testAgentEvent) GetData() interface{}     { return e.data }
+func (e testAgentEvent) GetTraceID() string       { return e.traceID }
+func (e testAgentEvent) GetParentID() string      { return "" }
+
+func newTestJSONFileTracer(t *testing.T) *JSONFileTracer {
+	t.Helper()
+	tracer, err := NewJSONFileTracer(filepath.Join(t.TempDir(), "traces", "trace.jsonl"))
+	if err != nil {
+		t.Fatalf("NewJSONFileTracer: %v", err)
+	}
+	t.Cleanup(func() { tracer.Close() })
+	return tracer
+}
+
+// readJSONLines decodes every line of the trace file, failing on invalid JSON
+func readJSONLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open trace file: %v", err)
+	}
+	defer file.Close()
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func recordsByName(records []map[string]interface{}) map[string]map[string]interface{} {
+	byName := make(map[string]map[string]interface{})
+	for _, record := range records {
+		if name, ok := record["name"].(string); ok {
+			byName[name] = record
+		}
+	}
+	return byName
+}
+
+func TestJSONFileTracerWritesTraceWithNestedSpans(t *testing.T) {
+	tracer := newTestJSONFileTracer(t)
+
+	traceID := tracer.StartTrace("list buckets", map[string]interface{}{"query": "which buckets exist?"})
+	tracer.SetTraceMetadata(traceID, "agent_mode", "simple")
+	outer := tracer.StartSpan(string(traceID), "conversation", nil)
+	inner := tracer.StartSpan(string(outer), "list_buckets", map[string]interface{}{"region": "us-east-1"})
+	tracer.EndSpan(inner, "2 buckets", nil)
+	tracer.EndSpan(outer, nil, fmt.Errorf("max turns reached"))
+	tracer.EndTrace(traceID, "done")
+
+	// EndTrace flushes: the records are on disk without closing the tracer
+	byName := recordsByName(readJSONLines(t, tracer.Path()))
+
+	trace := byName["list buckets"]
+	if trace == nil || trace["kind"] != "trace" || trace["id"] != string(traceID) || trace["output"] != "done" {
+		t.Fatalf("unexpected trace record: %+v", trace)
+	}
+	if metadata, _ := trace["metadata"].(map[string]interface{}); metadata["agent_mode"] != "simple" {
+		t.Errorf("trace metadata = %+v, want agent_mode=simple", trace["metadata"])
+	}
+	if input, _ := trace["input"].(map[string]interface{}); input["query"] != "which buckets exist?" {
+		t.Errorf("trace input = %+v", trace["input"])
+	}
+
+	outerSpan, innerSpan := byName["conversation"], byName["list_buckets"]
+	if outerSpan == nil || innerSpan == nil {
+		t.Fatalf("missing span records: %+v", byName)
+	}
+	if outerSpan["trace_id"] != string(traceID) || outerSpan["parent_id"] != nil || outerSpan["error"] != "max turns reached" {
+		t.Errorf("unexpected outer span: %+v", outerSpan)
+	}
+	if innerSpan["trace_id"] != string(traceID) || innerSpan["parent_id"] != string(outer) || innerSpan["output"] != "2 buckets" {
+		t.Errorf("inner span is not nested under the outer span: %+v", innerSpan)
+	}
+}
+
+func TestJSONFileTracerNestsAgentEvents(t *testing.T) {
+	tracer := newTestJSONFileTracer(t)
+	traceID := tracer.StartTrace("agent run", nil)
+	emit := func(eventType string) {
+		if err := tracer.EmitEvent(testAgentEvent{eventType: eventType, traceID: string(traceID), data: map[string]interface{}{"type": eventType}}); err != nil {
+			t.Fatalf("EmitEvent(%s): %v", eventType, err)
+		}
+	}
+
+	for _, eventType := range []string{EventTypeAgentStart, EventTypeConversationStart, EventTypeLLMGenerationStart, EventTypeToolCallStart,
+		EventTypeToolCallEnd, EventTypeTokenUsage, EventTypeLLMGenerationEnd, EventTypeConversationEnd, EventTypeAgentEnd} {
+		emit(eventType)
+	}
+	tracer.EndTrace(traceID, nil)
+
+	records := readJSONLines(t, tracer.Path())
+	spans := recordsByName(records)
+	parents := map[string]string{"agent": "", "conversation": "agent", "llm_generation": "conversation", "tool_call": "llm_generation"}
+	for name, parentName := range parents {
+		span := spans[name]
+		if span == nil {
+			t.Fatalf("missing %s span in %+v", name, records)
+		}
+		if parentName != "" && span["parent_id"] != spans[parentName]["id"] {
+			t.Errorf("%s span parent = %v, want the %s span", name, span["parent_id"], parentName)
+		}
+	}
+
+	var tokenUsage map[string]interface{}
+	for _, record := range records {
+		if record["kind"] == "event" {
+			tokenUsage = record
+		}
+	}
+	if tokenUsage == nil || tokenUsage["type"] != EventTypeTokenUsage || tokenUsage["parent_id"] != spans["llm_generation"]["id"] {
+		t.Errorf("unexpected token usage event: %+v", tokenUsage)
+	}
+	if metadata, _ := spans["agent run"]["metadata"].(map[string]interface{}); metadata["event_type"] != EventTypeAgentStart {
+		t.Errorf("agent start metadata missing from trace: %+v", spans["agent run"])
+	}
+}
+
+func TestJSONFileTracerConcurrentTraces(t *testing.T) {
+	tracer := newTestJSONFileTracer(t)
+
+	// A second tracer for the same path is shared, so both write through one buffer
+	shared, err := NewJSONFileTracer(tracer.Path())
+	if err != nil || shared != tracer {
+		t.Fatalf("expected the tracer for the same path to be shared, got %p (%v)", shared, err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			traceID := tracer.StartTrace(fmt.Sprintf("trace-%d", i), nil)
+			for s := 0; s < 5; s++ {
+				tracer.EndSpan(tracer.StartSpan(string(traceID), fmt.Sprintf("span-%d-%d", i, s), nil), s, nil)
+			}
+			tracer.EndTrace(traceID, i)
+		}(i)
+	}
+	wg.Wait()
+
+	if records := readJSONLines(t, tracer.Path()); len(records) != 20*6 {
+		t.Fatalf("expected %d records, got %d", 20*6, len(records))
+	}
+}
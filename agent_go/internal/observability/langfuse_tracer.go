@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -80,6 +81,9 @@ var (
 	sharedLangfuseClient *LangfuseTracer
 	sharedInitialized    bool
 	sharedMutex          sync.Mutex
+
+	// Tracers for per-session destination overrides, keyed by host and credentials
+	destinationTracers = make(map[string]*LangfuseTracer)
 )
 
 // langfuseTrace represents a trace in Langfuse v2 API format
@@ -191,13 +195,33 @@ func NewLangfuseTracerWithLogger(logger utils.ExtendedLogger) (Tracer, error) {
 	return newLangfuseTracerWithLogger(logger)
 }
 
+// NewLangfuseTracerForDestination returns a Langfuse tracer that sends to the given host with the given
+// credentials instead of the LANGFUSE_* environment. Tracers are reused per destination.
+func NewLangfuseTracerForDestination(host, publicKey, secretKey string, logger utils.ExtendedLogger) (Tracer, error) {
+	sharedMutex.Lock()
+	defer sharedMutex.Unlock()
+
+	digest := sha256.Sum256([]byte(host + "\x00" + publicKey + "\x00" + secretKey))
+	key := hex.EncodeToString(digest[:])
+	if tracer, exists := destinationTracers[key]; exists {
+		return tracer, nil
+	}
+
+	tracer, err := newLangfuseTracerInstance(host, publicKey, secretKey, logger)
+	if err != nil {
+		return nil, err
+	}
+	destinationTracers[key] = tracer
+	return tracer, nil
+}
+
 // initializeSharedLangfuseClientWithLogger initializes the shared Langfuse client with an injected logger
 func initializeSharedLangfuseClientWithLogger(logger utils.ExtendedLogger) error {
 	// Auto-load .env file if present (similar to Python dotenv)
 	if _, err := os.Stat(".env"); err == nil {
 		if err := godotenv.Load(); err != nil {
 			// Don't fail if .env can't be loaded, just log
-			log.Printf("Warning: Could not load .env file: %w", err)
+			log.Printf("Warning: Could not load .env file: %v", err)
 		}
 	}
 
@@ -217,6 +241,22 @@ func initializeSharedLangfuseClientWithLogger(logger utils.ExtendedLogger) error
 			"- LANGFUSE_HOST (optional, default: %s)", host)
 	}
 
+	tracer, err := newLangfuseTracerInstance(host, publicKey, secretKey, logger)
+	if err != nil {
+		return err
+	}
+
+	sharedLangfuseClient = tracer
+
+	if tracer.debug {
+		tracer.logger.Infof("✅ Langfuse: Authentication successful (%s...) [Debug: Always Enabled]", publicKey[:10])
+	}
+
+	return nil
+}
+
+// newLangfuseTracerInstance creates an authenticated Langfuse tracer for one destination and starts its event processor
+func newLangfuseTracerInstance(host, publicKey, secretKey string, logger utils.ExtendedLogger) (*LangfuseTracer, error) {
 	// Always enable debug for comprehensive observability (similar to Python)
 	debug := true
 
@@ -242,20 +282,14 @@ func initializeSharedLangfuseClientWithLogger(logger utils.ExtendedLogger) error
 
 	// Test authentication (similar to Python auth_check)
 	if err := tracer.authCheck(); err != nil {
-		return fmt.Errorf("langfuse authentication failed for %s...: %w", publicKey[:10], err)
+		return nil, fmt.Errorf("langfuse authentication failed for %s...: %w", publicKey[:min(len(publicKey), 10)], err)
 	}
 
 	// Start background event processor
 	tracer.wg.Add(1)
 	go tracer.eventProcessor()
 
-	sharedLangfuseClient = tracer
-
-	if tracer.debug {
-		tracer.logger.Infof("✅ Langfuse: Authentication successful (%s...) [Debug: Always Enabled]", publicKey[:10])
-	}
-
-	return nil
+	return tracer, nil
 }
 
 // authCheck verifies authentication with Langfuse API using health endpoint
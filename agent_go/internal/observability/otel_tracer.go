@@ -0,0 +1,410 @@
+package observability
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// otelServiceName is the service.name resource attribute when OTEL_SERVICE_NAME is not set
+	otelServiceName = "mcp-agent"
+	// otelInstrumentationName names the tracer that creates the agent spans
+	otelInstrumentationName = "mcp-agent/agent_go/internal/observability"
+	// maxOTelAttributeLength truncates inputs, outputs and event fields recorded as span attributes
+	maxOTelAttributeLength = 4096
+)
+
+// OTelTracer maps the Tracer interface onto OpenTelemetry spans exported over OTLP/HTTP. Each trace
+// becomes a root span, and agent lifecycle events open nested spans (agent > conversation > LLM
+// generation > tool call) like the Langfuse tracer. Event fields are recorded as span attributes;
+// other events are added as span events to the current span. Trace IDs generated by StartTrace are
+// valid OpenTelemetry trace IDs, so the agent trace ID is the exported trace ID.
+type OTelTracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+
+	mu     sync.Mutex
+	traces map[string]*otelSpan // agent trace ID -> root span
+	spans  map[string]*otelSpan // span ID -> open span
+
+	// Hierarchy tracking for agent events, mirroring the Langfuse tracer: traceID -> open span ID
+	agentSpans         map[string]string
+	conversationSpans  map[string]string
+	llmGenerationSpans map[string]string
+	toolSpans          map[string][]string // open tool spans, most recent last
+}
+
+// otelSpan is an open span with the context that parents its children
+type otelSpan struct {
+	ctx     context.Context
+	span    trace.Span
+	traceID string
+}
+
+var (
+	// One tracer per endpoint so every agent shares a single batching exporter
+	otelTracers   = make(map[string]*OTelTracer)
+	otelTracersMu sync.Mutex
+)
+
+// NewOTelTracer returns the tracer exporting spans over OTLP/HTTP to endpoint (e.g.
+// "http://localhost:4318"). An empty endpoint uses the OTEL_EXPORTER_OTLP_* environment variables.
+// Tracers for the same endpoint are shared.
+func NewOTelTracer(endpoint string) (*OTelTracer, error) {
+	otelTracersMu.Lock()
+	defer otelTracersMu.Unlock()
+	if tracer, exists := otelTracers[endpoint]; exists {
+		return tracer, nil
+	}
+
+	var options []otlptracehttp.Option
+	if endpoint != "" {
+		options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	tracer := NewOTelTracerWithProvider(sdktrace.WithBatcher(exporter))
+	otelTracers[endpoint] = tracer
+	return tracer, nil
+}
+
+// NewOTelTracerWithProvider returns a tracer whose provider is built from options, e.g.
+// sdktrace.WithSyncer with an in-memory exporter in tests
+func NewOTelTracerWithProvider(options ...sdktrace.TracerProviderOption) *OTelTracer {
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = otelServiceName
+	}
+	options = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		sdktrace.WithIDGenerator(otelIDGenerator{}),
+	}, options...)
+
+	provider := sdktrace.NewTracerProvider(options...)
+	return &OTelTracer{
+		provider:           provider,
+		tracer:             provider.Tracer(otelInstrumentationName),
+		traces:             make(map[string]*otelSpan),
+		spans:              make(map[string]*otelSpan),
+		agentSpans:         make(map[string]string),
+		conversationSpans:  make(map[string]string),
+		llmGenerationSpans: make(map[string]string),
+		toolSpans:          make(map[string][]string),
+	}
+}
+
+// otlpEndpointFromEnv returns OTEL_EXPORTER_OTLP_ENDPOINT; empty lets the exporter apply its defaults
+func otlpEndpointFromEnv() string {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+}
+
+// StartTrace starts the root span of a new trace
+func (o *OTelTracer) StartTrace(name string, input interface{}) TraceID {
+	id := generateID()
+	o.mu.Lock()
+	o.startTraceLocked(id, name, input)
+	o.mu.Unlock()
+	return TraceID(id)
+}
+
+func (o *OTelTracer) startTraceLocked(id, name string, input interface{}) *otelSpan {
+	ctx := context.WithValue(context.Background(), otelTraceIDKey{}, id)
+	ctx, span := o.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(
+		attribute.String("agent.trace_id", id),
+	))
+	if input != nil {
+		span.SetAttributes(attribute.String("input", otelAttributeValue(input)))
+	}
+	root := &otelSpan{ctx: ctx, span: span, traceID: id}
+	o.traces[id] = root
+	return root
+}
+
+// SetTraceMetadata records a metadata entry on the root span of a trace that has not ended yet
+func (o *OTelTracer) SetTraceMetadata(traceID TraceID, key string, value interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if root, exists := o.traces[string(traceID)]; exists {
+		root.span.SetAttributes(attribute.String("metadata."+key, otelAttributeValue(value)))
+	}
+}
+
+// EndTrace ends the spans of the trace that are still open, then its root span
+func (o *OTelTracer) EndTrace(traceID TraceID, output interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	root, exists := o.traces[string(traceID)]
+	if !exists {
+		return
+	}
+	delete(o.traces, string(traceID))
+	delete(o.agentSpans, string(traceID))
+	delete(o.conversationSpans, string(traceID))
+	delete(o.llmGenerationSpans, string(traceID))
+	delete(o.toolSpans, string(traceID))
+	for spanID, open := range o.spans {
+		if open.traceID == string(traceID) {
+			open.span.End()
+			delete(o.spans, spanID)
+		}
+	}
+
+	if output != nil {
+		root.span.SetAttributes(attribute.String("output", otelAttributeValue(output)))
+	}
+	root.span.End()
+}
+
+// StartSpan starts a span under a trace or, when parentID is a span ID, under that span
+func (o *OTelTracer) StartSpan(parentID string, name string, input interface{}) SpanID {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return SpanID(o.startSpanLocked(parentID, "SPAN", name, input))
+}
+
+func (o *OTelTracer) startSpanLocked(parentID, spanType, name string, input interface{}) string {
+	parent, exists := o.spans[parentID]
+	if !exists {
+		if parent, exists = o.traces[parentID]; !exists {
+			parent = o.startTraceLocked(parentID, name, nil)
+		}
+	}
+
+	ctx, span := o.tracer.Start(parent.ctx, name, trace.WithAttributes(attribute.String("agent.span_type", spanType)))
+	span.SetAttributes(otelDataAttributes("input", input)...)
+	spanID := span.SpanContext().SpanID().String()
+	o.spans[spanID] = &otelSpan{ctx: ctx, span: span, traceID: parent.traceID}
+	return spanID
+}
+
+// EndSpan ends a span with its output and, if err is non-nil, an error status
+func (o *OTelTracer) EndSpan(spanID SpanID, output interface{}, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.endSpanLocked(string(spanID), output, err)
+}
+
+func (o *OTelTracer) endSpanLocked(spanID string, output interface{}, err error) {
+	open, exists := o.spans[spanID]
+	if !exists {
+		return
+	}
+	delete(o.spans, spanID)
+
+	open.span.SetAttributes(otelDataAttributes("output", output)...)
+	if err != nil {
+		open.span.RecordError(err)
+		open.span.SetStatus(codes.Error, err.Error())
+	}
+	open.span.End()
+}
+
+// EmitEvent maps agent lifecycle events onto nested spans (agent > conversation > LLM generation >
+// tool call); other events are added as span events to the current span
+func (o *OTelTracer) EmitEvent(event AgentEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	traceID := event.GetTraceID()
+	switch event.GetType() {
+	case EventTypeAgentStart:
+		root, exists := o.traces[traceID]
+		if !exists {
+			root = o.startTraceLocked(traceID, EventTypeAgentStart, event.GetData())
+		}
+		root.span.SetAttributes(attribute.String("metadata.event_type", EventTypeAgentStart))
+		if correlationID := event.GetCorrelationID(); correlationID != "" {
+			root.span.SetAttributes(attribute.String("metadata.correlation_id", correlationID))
+		}
+		o.agentSpans[traceID] = o.startSpanLocked(traceID, "AGENT", "agent", event.GetData())
+	case EventTypeAgentEnd, EventTypeAgentError:
+		var err error
+		if event.GetType() == EventTypeAgentError {
+			err = fmt.Errorf("agent error")
+			if data, ok := event.GetData().(map[string]interface{}); ok {
+				if errorMsg, ok := data["error"].(string); ok {
+					err = fmt.Errorf("%s", errorMsg)
+				}
+			}
+		}
+		spanID := o.agentSpans[traceID]
+		delete(o.agentSpans, traceID)
+		o.endSpanLocked(spanID, event.GetData(), err)
+	case EventTypeConversationStart:
+		o.conversationSpans[traceID] = o.startSpanLocked(o.parentForLocked(traceID, o.agentSpans), "SPAN", "conversation", event.GetData())
+	case EventTypeConversationEnd:
+		spanID := o.conversationSpans[traceID]
+		delete(o.conversationSpans, traceID)
+		o.endSpanLocked(spanID, event.GetData(), nil)
+	case EventTypeLLMGenerationStart:
+		parentID := o.parentForLocked(traceID, o.conversationSpans, o.agentSpans)
+		o.llmGenerationSpans[traceID] = o.startSpanLocked(parentID, "GENERATION", "llm_generation", event.GetData())
+	case EventTypeLLMGenerationEnd:
+		spanID := o.llmGenerationSpans[traceID]
+		delete(o.llmGenerationSpans, traceID)
+		o.endSpanLocked(spanID, event.GetData(), nil)
+	case EventTypeToolCallStart:
+		parentID := o.parentForLocked(traceID, o.llmGenerationSpans, o.conversationSpans, o.agentSpans)
+		o.toolSpans[traceID] = append(o.toolSpans[traceID], o.startSpanLocked(parentID, "TOOL", "tool_call", event.GetData()))
+	case EventTypeToolCallEnd:
+		open := o.toolSpans[traceID]
+		if len(open) == 0 {
+			return nil
+		}
+		o.toolSpans[traceID] = open[:len(open)-1]
+		o.endSpanLocked(open[len(open)-1], event.GetData(), nil)
+	default:
+		parentID := o.parentForLocked(traceID, o.llmGenerationSpans, o.conversationSpans, o.agentSpans)
+		parent, exists := o.spans[parentID]
+		if !exists {
+			if parent, exists = o.traces[parentID]; !exists {
+				return nil
+			}
+		}
+		parent.span.AddEvent(event.GetType(), trace.WithTimestamp(event.GetTimestamp()), trace.WithAttributes(otelDataAttributes("data", event.GetData())...))
+	}
+	return nil
+}
+
+// parentForLocked returns the first open span of the trace in the given hierarchy levels, else the trace ID
+func (o *OTelTracer) parentForLocked(traceID string, levels ...map[string]string) string {
+	for _, level := range levels {
+		if spanID := level[traceID]; spanID != "" {
+			return spanID
+		}
+	}
+	return traceID
+}
+
+// EmitLLMEvent implements Tracer; LLM events are already covered by the generation spans
+func (o *OTelTracer) EmitLLMEvent(event LLMEvent) error {
+	return nil
+}
+
+// Flush exports the spans that have ended
+func (o *OTelTracer) Flush() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return o.provider.ForceFlush(ctx)
+}
+
+// Shutdown flushes and stops the exporter; later spans are dropped
+func (o *OTelTracer) Shutdown(ctx context.Context) error {
+	otelTracersMu.Lock()
+	for endpoint, tracer := range otelTracers {
+		if tracer == o {
+			delete(otelTracers, endpoint)
+		}
+	}
+	otelTracersMu.Unlock()
+	return o.provider.Shutdown(ctx)
+}
+
+// otelDataAttributes flattens the top-level fields of event data into attributes named
+// "<prefix>.<field>"; data that is not an object is recorded as a single "<prefix>" attribute
+func otelDataAttributes(prefix string, data interface{}) []attribute.KeyValue {
+	if data == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return []attribute.KeyValue{attribute.String(prefix, fmt.Sprintf("%v", data))}
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return []attribute.KeyValue{attribute.String(prefix, truncateOTelAttribute(string(encoded)))}
+	}
+
+	attributes := make([]attribute.KeyValue, 0, len(fields))
+	for key, value := range fields {
+		name := prefix + "." + key
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			attributes = append(attributes, attribute.String(name, truncateOTelAttribute(v)))
+		case bool:
+			attributes = append(attributes, attribute.Bool(name, v))
+		case float64:
+			if v == float64(int64(v)) {
+				attributes = append(attributes, attribute.Int64(name, int64(v)))
+			} else {
+				attributes = append(attributes, attribute.Float64(name, v))
+			}
+		default:
+			attributes = append(attributes, attribute.String(name, otelAttributeValue(v)))
+		}
+	}
+	return attributes
+}
+
+// otelAttributeValue renders a value as a (truncated) string attribute, JSON-encoding non-strings
+func otelAttributeValue(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return truncateOTelAttribute(text)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return truncateOTelAttribute(fmt.Sprintf("%v", value))
+	}
+	return truncateOTelAttribute(string(encoded))
+}
+
+func truncateOTelAttribute(value string) string {
+	if len(value) <= maxOTelAttributeLength {
+		return value
+	}
+	return value[:maxOTelAttributeLength] + "...(truncated)"
+}
+
+// otelTraceIDKey carries the agent trace ID to otelIDGenerator when a root span starts
+type otelTraceIDKey struct{}
+
+// otelIDGenerator uses the agent trace ID as the OpenTelemetry trace ID when it is one (32 hex
+// characters, as generated by StartTrace); other trace IDs get a random OpenTelemetry trace ID
+type otelIDGenerator struct{}
+
+func (otelIDGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	traceID := randomOTelTraceID()
+	if id, ok := ctx.Value(otelTraceIDKey{}).(string); ok {
+		if parsed, err := trace.TraceIDFromHex(id); err == nil {
+			traceID = parsed
+		}
+	}
+	return traceID, randomOTelSpanID()
+}
+
+func (otelIDGenerator) NewSpanID(ctx context.Context, traceID trace.TraceID) trace.SpanID {
+	return randomOTelSpanID()
+}
+
+func randomOTelTraceID() trace.TraceID {
+	var id trace.TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func randomOTelSpanID() trace.SpanID {
+	var id trace.SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
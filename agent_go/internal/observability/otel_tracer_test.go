@@ -0,0 +1,135 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestOTelTracer(t *testing.T) (*OTelTracer, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := NewOTelTracerWithProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { tracer.Shutdown(context.Background()) })
+	return tracer, exporter
+}
+
+func spansByName(spans tracetest.SpanStubs) map[string]tracetest.SpanStub {
+	byName := make(map[string]tracetest.SpanStub)
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	return byName
+}
+
+func spanAttribute(span tracetest.SpanStub, key string) (attribute.Value, bool) {
+	for _, kv := range span.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestOTelTracerRecordsAgentSpanHierarchy(t *testing.T) {
+	tracer, exporter := newTestOTelTracer(t)
+
+	traceID := tracer.StartTrace("list buckets", map[string]interface{}{"query": "which buckets exist?"})
+	tracer.SetTraceMetadata(traceID, "agent_mode", "simple")
+	for _, event := range []testAgentEvent{
+		{eventType: EventTypeAgentStart, traceID: string(traceID), data: map[string]interface{}{"model_id": "gpt-4.1"}},
+		{eventType: EventTypeConversationStart, traceID: string(traceID)},
+		{eventType: EventTypeLLMGenerationStart, traceID: string(traceID), data: map[string]interface{}{"turn": 1}},
+		{eventType: EventTypeToolCallStart, traceID: string(traceID), data: map[string]interface{}{"tool_name": "list_buckets", "server_name": "aws"}},
+		{eventType: EventTypeToolCallEnd, traceID: string(traceID), data: map[string]interface{}{"result": "2 buckets", "duration": 1500}},
+		{eventType: EventTypeTokenUsage, traceID: string(traceID), data: map[string]interface{}{"total_tokens": 42}},
+		{eventType: EventTypeLLMGenerationEnd, traceID: string(traceID)},
+		{eventType: EventTypeConversationEnd, traceID: string(traceID)},
+		{eventType: EventTypeAgentEnd, traceID: string(traceID)},
+	} {
+		if err := tracer.EmitEvent(event); err != nil {
+			t.Fatalf("EmitEvent(%s): %v", event.eventType, err)
+		}
+	}
+	tracer.EndTrace(traceID, "done")
+
+	spans := spansByName(exporter.GetSpans())
+	if len(spans) != 5 {
+		t.Fatalf("expected root, agent, conversation, generation and tool spans, got %d: %v", len(spans), spans)
+	}
+	root, agent, conversation, generation, tool := spans["list buckets"], spans["agent"], spans["conversation"], spans["llm_generation"], spans["tool_call"]
+
+	// The agent trace ID is the exported trace ID and every span belongs to it
+	if got := root.SpanContext.TraceID().String(); got != string(traceID) {
+		t.Errorf("expected OTel trace ID %s, got %s", traceID, got)
+	}
+	for name, span := range spans {
+		if span.SpanContext.TraceID() != root.SpanContext.TraceID() {
+			t.Errorf("span %s is in another trace", name)
+		}
+	}
+
+	for child, parent := range map[string]tracetest.SpanStub{"agent": root, "conversation": agent, "llm_generation": conversation, "tool_call": generation} {
+		if got := spans[child].Parent.SpanID(); got != parent.SpanContext.SpanID() {
+			t.Errorf("expected %s to be a child of %s", child, parent.Name)
+		}
+	}
+
+	if value, ok := spanAttribute(root, "metadata.agent_mode"); !ok || value.AsString() != "simple" {
+		t.Errorf("expected trace metadata on the root span, got %v", root.Attributes)
+	}
+	if value, ok := spanAttribute(root, "output"); !ok || value.AsString() != "done" {
+		t.Errorf("expected the trace output on the root span, got %v", root.Attributes)
+	}
+	if value, ok := spanAttribute(tool, "input.tool_name"); !ok || value.AsString() != "list_buckets" {
+		t.Errorf("expected the tool name attribute, got %v", tool.Attributes)
+	}
+	if value, ok := spanAttribute(tool, "output.duration"); !ok || value.AsInt64() != 1500 {
+		t.Errorf("expected the tool duration attribute, got %v", tool.Attributes)
+	}
+	if value, ok := spanAttribute(tool, "agent.span_type"); !ok || value.AsString() != "TOOL" {
+		t.Errorf("expected the TOOL span type, got %v", tool.Attributes)
+	}
+	if len(generation.Events) != 1 || generation.Events[0].Name != EventTypeTokenUsage {
+		t.Errorf("expected the token usage event on the generation span, got %v", generation.Events)
+	}
+}
+
+func TestOTelTracerSpanErrorsAndOpenSpans(t *testing.T) {
+	tracer, exporter := newTestOTelTracer(t)
+
+	traceID := tracer.StartTrace("failing run", nil)
+	outer := tracer.StartSpan(string(traceID), "conversation", nil)
+	inner := tracer.StartSpan(string(outer), "list_buckets", map[string]interface{}{"region": "us-east-1"})
+	tracer.EndSpan(inner, nil, fmt.Errorf("access denied"))
+	tracer.StartSpan(string(outer), "never_ended", nil)
+	tracer.EndSpan(outer, nil, nil)
+	tracer.EndTrace(traceID, nil)
+
+	spans := spansByName(exporter.GetSpans())
+	failed := spans["list_buckets"]
+	if failed.Status.Code != codes.Error || failed.Status.Description != "access denied" {
+		t.Errorf("expected an error status, got %+v", failed.Status)
+	}
+	if len(failed.Events) != 1 || failed.Events[0].Name != "exception" {
+		t.Errorf("expected the error recorded as an exception event, got %v", failed.Events)
+	}
+	if value, ok := spanAttribute(failed, "input.region"); !ok || value.AsString() != "us-east-1" {
+		t.Errorf("expected the input attribute, got %v", failed.Attributes)
+	}
+	if _, ended := spans["never_ended"]; !ended {
+		t.Errorf("expected EndTrace to end spans left open, got %v", spans)
+	}
+
+	// Spans of an unknown trace ID start a trace of their own
+	orphan := tracer.StartSpan("external-trace", "orphan", nil)
+	tracer.EndSpan(orphan, nil, nil)
+	if _, exported := spansByName(exporter.GetSpans())["orphan"]; !exported {
+		t.Errorf("expected the orphan span to be exported")
+	}
+}
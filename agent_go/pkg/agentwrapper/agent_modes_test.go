@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/logger"
+	mcpagent "mcp-agent/agent_go/pkg/mcpagent"
+)
+
+// echoRunner answers with the last user message
+type echoRunner struct{}
+
+func (echoRunner) Run(ctx context.Context, a *mcpagent.Agent, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error) {
+	var last string
+	for _, msg := range messages {
+		if msg.Role != llmtypes.ChatMessageTypeHuman {
+			continue
+		}
+		for _, part := range msg.Parts {
+			if text, ok := part.(llmtypes.TextContent); ok {
+				last = text.Text
+			}
+		}
+	}
+	answer := "echo: " + last
+	return answer, append(messages, llmtypes.MessageContent{
+		Role:  llmtypes.ChatMessageTypeAI,
+		Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: answer}},
+	}), nil
+}
+
+func TestCustomAgentModeThroughWrapper(t *testing.T) {
+	const echoMode mcpagent.AgentMode = "echo"
+	factoryCalls := 0
+	if err := mcpagent.RegisterAgentMode(echoMode, func(a *mcpagent.Agent) (mcpagent.AgentRunner, error) {
+		factoryCalls++
+		return echoRunner{}, nil
+	}); err != nil {
+		t.Fatalf("RegisterAgentMode: %v", err)
+	}
+	defer mcpagent.UnregisterAgentMode(echoMode)
+
+	if err := mcpagent.RegisterAgentMode(mcpagent.ReActAgent, func(a *mcpagent.Agent) (mcpagent.AgentRunner, error) { return echoRunner{}, nil }); err == nil {
+		t.Fatal("expected the built-in ReAct mode to be rejected")
+	}
+
+	testLogger := logger.CreateDiscardLogger("error")
+	wrapper := &LLMAgentWrapper{
+		agent:   &mcpagent.Agent{ModelID: "test-model", Logger: testLogger, AgentMode: echoMode},
+		config:  LLMAgentConfig{AgentMode: echoMode},
+		metrics: &agentMetricsImpl{MinLatency: time.Hour, IsHealthy: true},
+		logger:  testLogger,
+	}
+
+	for _, question := range []string{"hello", "again"} {
+		answer, err := wrapper.Invoke(context.Background(), question)
+		if err != nil {
+			t.Fatalf("Invoke(%q): %v", question, err)
+		}
+		if answer != "echo: "+question {
+			t.Fatalf("Invoke(%q) = %q", question, answer)
+		}
+	}
+	if factoryCalls != 1 {
+		t.Fatalf("expected the runner created once per agent, got %d", factoryCalls)
+	}
+	if history := wrapper.GetHistory(); len(history) != 4 {
+		t.Fatalf("expected the echo runner's history kept by the wrapper, got %d messages", len(history))
+	}
+}
@@ -43,19 +43,63 @@ type LLMAgentConfig struct {
 	MaxTurns           int
 	StreamingChunkSize int
 	Timeout            time.Duration
-	ToolTimeout        time.Duration      // Tool execution timeout (default: 5 minutes)
-	AgentMode          mcpagent.AgentMode // Agent mode (Simple or ReAct)
-	CacheOnly          bool               // If true, only use cached servers (skip servers without cache)
-	SelectedTools      []string           // Selected tools in "server:tool" format
+	ToolTimeout        time.Duration            // Tool execution timeout (default: 5 minutes)
+	ToolTimeouts       map[string]time.Duration // Per-tool timeout overrides by tool name
+	AgentMode          mcpagent.AgentMode       // Agent mode (Simple, ReAct or a registered custom mode)
+	CacheOnly          bool                     // If true, only use cached servers (skip servers without cache)
+	SelectedTools      []string                 // Selected tools in "server:tool" format
 
 	// Smart routing configuration
 	EnableSmartRouting     bool // Enable smart routing for tool filtering
 	SmartRoutingMaxTools   int  // Threshold for max tools before enabling smart routing
 	SmartRoutingMaxServers int  // Threshold for max servers before enabling smart routing
 
+	// Server cap configuration
+	MaxServers           int    // Maximum MCP servers to connect (0 = no limit)
+	ServerSelectionQuery string // Query used to prioritize servers when MaxServers applies
+
+	// Handling of configured servers exposing identical tool sets ("" connects to all)
+	ServerDedupPolicy mcpagent.ServerDedupPolicy
+
+	// Tool usage examples in the system prompt
+	IncludeToolExamples  bool              // Inject one-shot usage examples for the selected tools
+	ToolExamples         map[string]string // Curated examples keyed by tool name (optional)
+	ToolExamplesMaxChars int               // Size bound for the examples section (0 = default)
+
+	// Context window (tokens) per model ID; oversized prompts switch to a larger-context model up front
+	ContextWindowModels map[string]int
+
+	// Drop the oldest turns once the prompt exceeds this fraction of the context window (0 disables)
+	HistoryCompactionThreshold float64
+
+	// Inline the content of workspace files referenced by tool results (empty root disables)
+	WorkspaceFileRoot     string
+	WorkspaceFileMaxBytes int // Per-file size limit (0 = default)
+
+	// Records the advertised tool set and the tools invoked per run (nil disables)
+	ToolUsageRecorder mcpagent.ToolUsageRecorder
+
+	// Reuses smart routing decisions for similar queries across runs (nil disables)
+	RoutingCache *mcpagent.RoutingCache
+
 	// Detailed LLM configuration from frontend
-	FallbackModels        []string               // Custom fallback models from frontend
-	CrossProviderFallback *CrossProviderFallback // Cross-provider fallback configuration
+	FallbackModels        []string                    // Custom fallback models from frontend
+	CrossProviderFallback *CrossProviderFallback      // Cross-provider fallback configuration
+	FallbackExclusions    mcpagent.FallbackExclusions // Providers/models the fallback chain must skip
+
+	// Retry budget and delays of throttled LLM calls (zero fields keep the defaults)
+	RetryConfig mcpagent.RetryConfig
+
+	// Attach a structured-LLM recap of the run to the completion event
+	RunSummary bool
+
+	// Output token cap (0 keeps the defaults) and stop sequences of every LLM call
+	MaxOutputTokens int
+	StopSequences   []string
+
+	// Use this model instead of creating one from Provider/ModelID, e.g. a fake or an
+	// llm.RecordingModel in tests
+	LLM llmtypes.Model
 }
 
 // CrossProviderFallback represents cross-provider fallback configuration
@@ -168,6 +212,7 @@ func NewLLMAgentWrapperWithTrace(ctx context.Context, config LLMAgentConfig, tra
 		mcpagent.WithToolChoice(config.ToolChoice),
 		mcpagent.WithMaxTurns(config.MaxTurns),
 		mcpagent.WithToolTimeout(config.ToolTimeout),
+		mcpagent.WithToolTimeouts(config.ToolTimeouts),
 		mcpagent.WithCacheOnly(config.CacheOnly),
 	}
 
@@ -183,12 +228,88 @@ func NewLLMAgentWrapperWithTrace(ctx context.Context, config LLMAgentConfig, tra
 			crossProviderFallback.Provider, crossProviderFallback.Models)
 	}
 
+	// Override the retry budget and delays of throttled LLM calls
+	if config.RetryConfig != (mcpagent.RetryConfig{}) {
+		agentOptions = append(agentOptions, mcpagent.WithRetryConfig(config.RetryConfig))
+		logger.Infof("⏳ Retry config - MaxRetries: %d, BaseDelay: %v, MaxDelay: %v, Multiplier: %v, Jitter: %v",
+			config.RetryConfig.MaxRetries, config.RetryConfig.BaseDelay, config.RetryConfig.MaxDelay, config.RetryConfig.Multiplier, config.RetryConfig.Jitter)
+	}
+
+	// Cap the output and stop generation at the configured sequences
+	if config.MaxOutputTokens > 0 || len(config.StopSequences) > 0 {
+		agentOptions = append(agentOptions, mcpagent.WithMaxOutputTokens(config.MaxOutputTokens), mcpagent.WithStopSequences(config.StopSequences))
+		logger.Infof("✂️ Output limits - MaxOutputTokens: %d, StopSequences: %q", config.MaxOutputTokens, config.StopSequences)
+	}
+
+	// Recap the run in the completion event
+	if config.RunSummary {
+		agentOptions = append(agentOptions, mcpagent.WithRunSummary(true))
+		logger.Infof("📝 Run summary enabled")
+	}
+
+	// Keep the fallback chain away from excluded providers and models
+	if !config.FallbackExclusions.IsEmpty() {
+		agentOptions = append(agentOptions, mcpagent.WithFallbackExclusions(config.FallbackExclusions))
+		logger.Infof("🚫 Fallback exclusions configured - providers: %v, models: %v", config.FallbackExclusions.Providers, config.FallbackExclusions.Models)
+	}
+
 	// Add selected tools if provided
 	if len(config.SelectedTools) > 0 {
 		agentOptions = append(agentOptions, mcpagent.WithSelectedTools(config.SelectedTools))
 		logger.Infof("🔧 Selected tools configured: %d tools", len(config.SelectedTools))
 	}
 
+	// Cap the number of connected servers, prioritizing by the query
+	if config.MaxServers > 0 {
+		agentOptions = append(agentOptions,
+			mcpagent.WithMaxServers(config.MaxServers),
+			mcpagent.WithServerSelectionQuery(config.ServerSelectionQuery),
+		)
+		logger.Infof("🎯 Max servers cap configured: %d", config.MaxServers)
+	}
+
+	// Skip servers that expose the same tools as another configured server
+	if config.ServerDedupPolicy != mcpagent.ServerDedupOff {
+		agentOptions = append(agentOptions, mcpagent.WithServerDedupPolicy(config.ServerDedupPolicy))
+		logger.Infof("🔁 Server dedup policy configured: %s", config.ServerDedupPolicy)
+	}
+
+	// Inject tool usage examples into the system prompt
+	if config.IncludeToolExamples {
+		agentOptions = append(agentOptions,
+			mcpagent.WithToolExamples(true),
+			mcpagent.WithToolExampleStore(config.ToolExamples),
+			mcpagent.WithToolExamplesMaxChars(config.ToolExamplesMaxChars),
+		)
+		logger.Infof("🧩 Tool usage examples enabled")
+	}
+
+	// Select a larger-context model up front when the prompt exceeds the model's window
+	if len(config.ContextWindowModels) > 0 {
+		agentOptions = append(agentOptions, mcpagent.WithContextWindowModels(config.ContextWindowModels))
+		logger.Infof("📏 Context-size model selection configured for %d models", len(config.ContextWindowModels))
+	}
+	if config.HistoryCompactionThreshold > 0 {
+		agentOptions = append(agentOptions, mcpagent.WithHistoryCompaction(config.HistoryCompactionThreshold, 0))
+		logger.Infof("🗜️ History compaction at %.0f%% of the context window", config.HistoryCompactionThreshold*100)
+	}
+
+	// Read workspace files referenced by tool results back into context
+	if config.WorkspaceFileRoot != "" {
+		agentOptions = append(agentOptions, mcpagent.WithWorkspaceFileReferences(config.WorkspaceFileRoot, config.WorkspaceFileMaxBytes))
+		logger.Infof("📎 Workspace file references enabled under %s", config.WorkspaceFileRoot)
+	}
+
+	// Track advertised-but-unused tools across runs
+	if config.ToolUsageRecorder != nil {
+		agentOptions = append(agentOptions, mcpagent.WithToolUsageRecorder(config.ToolUsageRecorder))
+	}
+
+	// Reuse smart routing decisions across runs
+	if config.RoutingCache != nil {
+		agentOptions = append(agentOptions, mcpagent.WithRoutingCache(config.RoutingCache))
+	}
+
 	// Add smart routing options if enabled
 	if config.EnableSmartRouting {
 		// Set smart routing thresholds (use defaults if not specified)
@@ -227,6 +348,19 @@ func NewLLMAgentWrapperWithTrace(ctx context.Context, config LLMAgentConfig, tra
 			logger, // Pass the logger parameter directly
 			agentOptions...,
 		)
+	} else if mcpagent.IsRegisteredAgentMode(config.AgentMode) {
+		// Create agent for a custom registered mode
+		agent, err = mcpagent.NewAgent(
+			ctx,
+			llm,
+			config.ServerName,
+			config.ConfigPath,
+			config.ModelID,
+			tracer,
+			traceID,
+			logger,
+			append(agentOptions, mcpagent.WithMode(config.AgentMode))...,
+		)
 	} else {
 		// Create Simple agent (default)
 		agent, err = mcpagent.NewSimpleAgent(
@@ -482,6 +616,11 @@ func (w *LLMAgentWrapper) Stop(ctx context.Context) error {
 	return nil
 }
 
+// Shutdown terminates the agent's MCP connections; the wrapper cannot be used afterwards
+func (w *LLMAgentWrapper) Shutdown(ctx context.Context) error {
+	return w.Stop(ctx)
+}
+
 // IsHealthy implements the AgentLifecycle interface
 func (w *LLMAgentWrapper) IsHealthy() bool {
 	w.mu.RLock()
@@ -668,6 +807,11 @@ func (w *LLMAgentWrapper) getLastErrorString() string {
 
 // initializeLLMWithConfig initializes an LLM using detailed configuration from frontend
 func initializeLLMWithConfig(config LLMAgentConfig, logger utils.ExtendedLogger, tracer observability.Tracer, traceID observability.TraceID) (llmtypes.Model, error) {
+	if config.LLM != nil {
+		logger.Infof("Using the LLM supplied in the agent config")
+		return config.LLM, nil
+	}
+
 	// Validate and convert provider string to llm.Provider type
 	llmProvider, err := llm.ValidateProvider(string(config.Provider))
 	if err != nil {
@@ -698,6 +842,9 @@ func initializeLLMWithConfig(config LLMAgentConfig, logger utils.ExtendedLogger,
 		logger.Infof("Added default cross-provider fallback models: %v", crossProviderFallbacks)
 	}
 
+	// Drop fallbacks this request must never use
+	fallbackModels = config.FallbackExclusions.Filter("", fallbackModels)
+
 	// Use the existing LLM provider system with detailed fallback models
 	llmConfig := llm.Config{
 		Provider:       llmProvider,
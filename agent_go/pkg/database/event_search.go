@@ -0,0 +1,64 @@
+package database
+
+import (
+	"log"
+	"strings"
+)
+
+// eventSearchIndexSQL creates an FTS5 index over events.event_data, kept in sync by triggers.
+// Events are only ever inserted and deleted.
+const eventSearchIndexSQL = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS events_fts USING fts5(event_data, content='events', content_rowid='rowid');
+	CREATE TRIGGER IF NOT EXISTS events_fts_insert AFTER INSERT ON events BEGIN
+		INSERT INTO events_fts(rowid, event_data) VALUES (new.rowid, new.event_data);
+	END;
+	CREATE TRIGGER IF NOT EXISTS events_fts_delete AFTER DELETE ON events BEGIN
+		INSERT INTO events_fts(events_fts, rowid, event_data) VALUES ('delete', old.rowid, old.event_data);
+	END;
+`
+
+// enableFullTextSearch creates the FTS5 event index and reports whether it is usable. SQLite builds
+// without FTS5 (go-sqlite3 needs the sqlite_fts5 build tag) fall back to LIKE matching.
+func (s *SQLiteDB) enableFullTextSearch() bool {
+	var exists int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'events_fts'`).Scan(&exists); err != nil {
+		return false
+	}
+	if _, err := s.db.Exec(eventSearchIndexSQL); err != nil {
+		log.Printf("[DATABASE] FTS5 unavailable, event content search uses LIKE: %v", err)
+		return false
+	}
+	if exists == 0 {
+		// Index the events stored before the index existed
+		if _, err := s.db.Exec(`INSERT INTO events_fts(events_fts) VALUES ('rebuild')`); err != nil {
+			log.Printf("[DATABASE] Failed to build the event search index, event content search uses LIKE: %v", err)
+			return false
+		}
+	}
+	return true
+}
+
+// textQueryCondition returns the WHERE condition and argument matching events whose data
+// contains query, or "" when query is blank. With FTS5 the query matches as a phrase whose
+// last word may be a prefix; otherwise it is a case-insensitive substring match.
+func (s *SQLiteDB) textQueryCondition(query string) (string, interface{}) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", nil
+	}
+	if s.fts {
+		return "rowid IN (SELECT rowid FROM events_fts WHERE events_fts MATCH ?)", ftsPhrase(query)
+	}
+	return `event_data LIKE ? ESCAPE '\'`, likePattern(query)
+}
+
+// ftsPhrase quotes query as a single FTS5 prefix phrase so its operators are matched literally
+func ftsPhrase(query string) string {
+	return `"` + strings.ReplaceAll(query, `"`, `""`) + `"*`
+}
+
+// likePattern builds a LIKE pattern matching query anywhere, with its wildcards escaped
+func likePattern(query string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query)
+	return "%" + escaped + "%"
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+func TestGetEventsTextQueryMatchesEventContent(t *testing.T) {
+	for _, mode := range []string{"like", "fts5"} {
+		t.Run(mode, func(t *testing.T) {
+			db := newTestSQLiteDB(t)
+			if mode == "fts5" && !db.enableFullTextSearch() {
+				t.Skip("SQLite was built without FTS5")
+			}
+			ctx := context.Background()
+			if _, err := db.CreateChatSession(ctx, &CreateChatSessionRequest{SessionID: "session-1"}); err != nil {
+				t.Fatalf("CreateChatSession: %v", err)
+			}
+			store := func(data events.EventData) {
+				t.Helper()
+				event := &events.AgentEvent{Type: data.GetEventType(), Timestamp: time.Now(), SessionID: "session-1", Data: data}
+				if err := db.StoreEvent(ctx, "session-1", event); err != nil {
+					t.Fatalf("StoreEvent: %v", err)
+				}
+			}
+			store(&events.ToolCallStartEvent{ToolName: "aws_s3_list_buckets", ServerName: "aws"})
+			store(&events.ToolCallEndEvent{ToolName: "github_create_issue", Result: "Created issue in the Zephyr repository", ServerName: "github"})
+			store(&events.UserMessageEvent{Content: "Budget is at 100% of the quota", Role: "user"})
+
+			search := func(query string) []string {
+				t.Helper()
+				response, err := db.GetEvents(ctx, &GetChatHistoryRequest{SessionID: "session-1", TextQuery: query})
+				if err != nil {
+					t.Fatalf("GetEvents(%q): %v", query, err)
+				}
+				if response.Total != len(response.Events) {
+					t.Fatalf("GetEvents(%q) total = %d, events = %d", query, response.Total, len(response.Events))
+				}
+				types := make([]string, 0, len(response.Events))
+				for _, event := range response.Events {
+					types = append(types, event.EventType)
+				}
+				return types
+			}
+
+			cases := []struct {
+				query string
+				want  []string
+			}{
+				{"aws_s3_list_buckets", []string{string(events.ToolCallStart)}},
+				{"zephyr", []string{string(events.ToolCallEnd)}},        // case-insensitive, matches the result
+				{"github_create", []string{string(events.ToolCallEnd)}}, // prefix of a tool name
+				{"100%", []string{string(events.UserMessageEventType)}}, // LIKE wildcards are literal
+				{"kubernetes", []string{}},                              // no match
+				{"", []string{"", "", ""}},                              // blank query does not filter
+			}
+			for _, tc := range cases {
+				got := search(tc.query)
+				if len(got) != len(tc.want) {
+					t.Fatalf("search %q returned %v, want %v", tc.query, got, tc.want)
+				}
+				for i := range tc.want {
+					if tc.want[i] != "" && got[i] != tc.want[i] {
+						t.Fatalf("search %q returned %v, want %v", tc.query, got, tc.want)
+					}
+				}
+			}
+		})
+	}
+}
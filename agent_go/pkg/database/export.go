@@ -0,0 +1,300 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// Session export formats
+const (
+	ExportFormatMarkdown = "md"
+	ExportFormatJSON     = "json"
+)
+
+// ErrUnsupportedExportFormat is returned by ExportSession for formats other than md and json
+var ErrUnsupportedExportFormat = errors.New("unsupported export format")
+
+// Transcript entry kinds
+const (
+	TranscriptUserMessage      = "user_message"
+	TranscriptAssistantMessage = "assistant_message"
+	TranscriptToolCall         = "tool_call"
+	TranscriptToolResult       = "tool_result"
+	TranscriptToolError        = "tool_error"
+	TranscriptError            = "error"
+	TranscriptFinalResult      = "final_result"
+)
+
+// exportPageSize is the number of events read per query while exporting a session
+const exportPageSize = 1000
+
+// SessionTranscript is the structured (JSON) export of a session
+type SessionTranscript struct {
+	SessionID   string            `json:"session_id"`
+	Title       string            `json:"title"`
+	AgentMode   string            `json:"agent_mode"`
+	Status      string            `json:"status"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	ExportedAt  time.Time         `json:"exported_at"`
+	Entries     []TranscriptEntry `json:"entries"`
+}
+
+// TranscriptEntry is one message, tool call or result of a session transcript. HierarchyLevel
+// nests the entries of sub-agents (0 is the top-level agent).
+type TranscriptEntry struct {
+	Kind           string    `json:"kind"`
+	EventType      string    `json:"event_type"`
+	Timestamp      time.Time `json:"timestamp"`
+	HierarchyLevel int       `json:"hierarchy_level"`
+	ToolName       string    `json:"tool_name,omitempty"`
+	ServerName     string    `json:"server_name,omitempty"`
+	Arguments      string    `json:"arguments,omitempty"`
+	Content        string    `json:"content"`
+}
+
+// storedEvent is the envelope of an AgentEvent as StoreEvent serializes it
+type storedEvent struct {
+	Type           events.EventType `json:"type"`
+	Timestamp      time.Time        `json:"timestamp"`
+	EventIndex     int              `json:"event_index"`
+	HierarchyLevel int              `json:"hierarchy_level"`
+	Data           json.RawMessage  `json:"data"`
+}
+
+// exportSession renders the transcript of a session stored in db
+func exportSession(ctx context.Context, db Database, sessionID, format string) ([]byte, error) {
+	if format != ExportFormatMarkdown && format != ExportFormatJSON {
+		return nil, fmt.Errorf("%w %q, must be %s or %s", ErrUnsupportedExportFormat, format, ExportFormatMarkdown, ExportFormatJSON)
+	}
+
+	session, err := db.GetChatSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat session: %w", err)
+	}
+
+	var stored []Event
+	for offset := 0; ; offset += exportPageSize {
+		page, err := db.GetEventsBySession(ctx, sessionID, exportPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		stored = append(stored, page...)
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+
+	transcript := BuildSessionTranscript(session, stored)
+	if format == ExportFormatJSON {
+		return json.MarshalIndent(transcript, "", "  ")
+	}
+	return []byte(RenderTranscriptMarkdown(transcript)), nil
+}
+
+// BuildSessionTranscript reconstructs the transcript of a session from its stored events. Events
+// are ordered by timestamp, then by their emission index; events that are not messages, tool
+// calls or results are left out.
+func BuildSessionTranscript(session *ChatSession, stored []Event) *SessionTranscript {
+	transcript := &SessionTranscript{
+		SessionID:   session.SessionID,
+		Title:       session.Title,
+		AgentMode:   session.AgentMode,
+		Status:      session.Status,
+		CreatedAt:   session.CreatedAt,
+		CompletedAt: session.CompletedAt,
+		ExportedAt:  time.Now(),
+		Entries:     []TranscriptEntry{},
+	}
+
+	envelopes := make([]storedEvent, 0, len(stored))
+	for _, event := range stored {
+		var envelope storedEvent
+		if err := json.Unmarshal(event.EventData, &envelope); err != nil {
+			continue
+		}
+		if envelope.Type == "" {
+			envelope.Type = events.EventType(event.EventType)
+		}
+		if envelope.Timestamp.IsZero() {
+			envelope.Timestamp = event.Timestamp
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	sort.SliceStable(envelopes, func(i, j int) bool {
+		if !envelopes[i].Timestamp.Equal(envelopes[j].Timestamp) {
+			return envelopes[i].Timestamp.Before(envelopes[j].Timestamp)
+		}
+		return envelopes[i].EventIndex < envelopes[j].EventIndex
+	})
+
+	for _, envelope := range envelopes {
+		entry, ok := transcriptEntry(envelope)
+		if !ok {
+			continue
+		}
+		transcript.Entries = appendTranscriptEntry(transcript.Entries, entry)
+	}
+	return transcript
+}
+
+// transcriptEntry converts a stored event to a transcript entry
+func transcriptEntry(envelope storedEvent) (TranscriptEntry, bool) {
+	entry := TranscriptEntry{
+		EventType:      string(envelope.Type),
+		Timestamp:      envelope.Timestamp,
+		HierarchyLevel: envelope.HierarchyLevel,
+	}
+
+	switch envelope.Type {
+	case events.UserMessage:
+		var data events.UserMessageEvent
+		if json.Unmarshal(envelope.Data, &data) != nil || data.Content == "" {
+			return entry, false
+		}
+		entry.Kind, entry.Content = TranscriptUserMessage, data.Content
+	case events.LLMGenerationEnd:
+		var data events.LLMGenerationEndEvent
+		if json.Unmarshal(envelope.Data, &data) != nil || strings.TrimSpace(data.Content) == "" {
+			return entry, false
+		}
+		entry.Kind, entry.Content = TranscriptAssistantMessage, data.Content
+	case events.ToolCallStart:
+		var data events.ToolCallStartEvent
+		if json.Unmarshal(envelope.Data, &data) != nil {
+			return entry, false
+		}
+		entry.Kind, entry.ToolName, entry.ServerName, entry.Arguments = TranscriptToolCall, data.ToolName, data.ServerName, data.ToolParams.Arguments
+	case events.ToolCallEnd:
+		var data events.ToolCallEndEvent
+		if json.Unmarshal(envelope.Data, &data) != nil {
+			return entry, false
+		}
+		entry.Kind, entry.ToolName, entry.ServerName, entry.Content = TranscriptToolResult, data.ToolName, data.ServerName, data.Result
+	case events.ToolCallError:
+		var data events.ToolCallErrorEvent
+		if json.Unmarshal(envelope.Data, &data) != nil {
+			return entry, false
+		}
+		entry.Kind, entry.ToolName, entry.ServerName, entry.Content = TranscriptToolError, data.ToolName, data.ServerName, data.Error
+	case events.ConversationError:
+		var data events.ConversationErrorEvent
+		if json.Unmarshal(envelope.Data, &data) != nil || data.Error == "" {
+			return entry, false
+		}
+		entry.Kind, entry.Content = TranscriptError, data.Error
+	case events.ConversationEnd:
+		var data events.ConversationEndEvent
+		if json.Unmarshal(envelope.Data, &data) != nil || data.Result == "" {
+			return entry, false
+		}
+		entry.Kind, entry.Content = TranscriptFinalResult, data.Result
+	case events.EventTypeUnifiedCompletion:
+		var data events.UnifiedCompletionEvent
+		if json.Unmarshal(envelope.Data, &data) != nil || data.FinalResult == "" {
+			return entry, false
+		}
+		entry.Kind, entry.Content = TranscriptFinalResult, data.FinalResult
+	default:
+		return entry, false
+	}
+	return entry, true
+}
+
+// appendTranscriptEntry appends entry, folding a final result into the identical assistant
+// message or final result just before it, so the answer appears once
+func appendTranscriptEntry(entries []TranscriptEntry, entry TranscriptEntry) []TranscriptEntry {
+	if entry.Kind == TranscriptFinalResult && len(entries) > 0 {
+		last := &entries[len(entries)-1]
+		if (last.Kind == TranscriptAssistantMessage || last.Kind == TranscriptFinalResult) &&
+			strings.TrimSpace(last.Content) == strings.TrimSpace(entry.Content) {
+			last.Kind = TranscriptFinalResult
+			return entries
+		}
+	}
+	return append(entries, entry)
+}
+
+// RenderTranscriptMarkdown renders a transcript as Markdown. Entries of sub-agents are nested
+// in blockquotes, one level per hierarchy level.
+func RenderTranscriptMarkdown(transcript *SessionTranscript) string {
+	var b strings.Builder
+
+	title := transcript.Title
+	if title == "" {
+		title = "Session " + transcript.SessionID
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "- Session: %s\n", transcript.SessionID)
+	if transcript.AgentMode != "" {
+		fmt.Fprintf(&b, "- Agent mode: %s\n", transcript.AgentMode)
+	}
+	if transcript.Status != "" {
+		fmt.Fprintf(&b, "- Status: %s\n", transcript.Status)
+	}
+	fmt.Fprintf(&b, "- Created: %s\n", transcript.CreatedAt.Format(time.RFC3339))
+	if transcript.CompletedAt != nil {
+		fmt.Fprintf(&b, "- Completed: %s\n", transcript.CompletedAt.Format(time.RFC3339))
+	}
+	b.WriteString("\n## Transcript\n")
+
+	for _, entry := range transcript.Entries {
+		var section strings.Builder
+		switch entry.Kind {
+		case TranscriptUserMessage:
+			fmt.Fprintf(&section, "### User\n\n%s\n", entry.Content)
+		case TranscriptAssistantMessage:
+			fmt.Fprintf(&section, "### Assistant\n\n%s\n", entry.Content)
+		case TranscriptToolCall:
+			fmt.Fprintf(&section, "### Tool call: %s\n\n%s", transcriptToolName(entry), markdownFence(entry.Arguments, "json"))
+		case TranscriptToolResult:
+			fmt.Fprintf(&section, "### Tool result: %s\n\n%s", transcriptToolName(entry), markdownFence(entry.Content, ""))
+		case TranscriptToolError:
+			fmt.Fprintf(&section, "### Tool error: %s\n\n%s", transcriptToolName(entry), markdownFence(entry.Content, ""))
+		case TranscriptError:
+			fmt.Fprintf(&section, "### Error\n\n%s\n", entry.Content)
+		case TranscriptFinalResult:
+			fmt.Fprintf(&section, "### Final result\n\n%s\n", entry.Content)
+		}
+		b.WriteString("\n")
+		b.WriteString(quoteMarkdown(section.String(), entry.HierarchyLevel))
+	}
+	return b.String()
+}
+
+// transcriptToolName returns server/tool, or the tool name when the server is unknown
+func transcriptToolName(entry TranscriptEntry) string {
+	if entry.ServerName == "" {
+		return entry.ToolName
+	}
+	return entry.ServerName + "/" + entry.ToolName
+}
+
+// markdownFence wraps content in a code fence longer than any backtick run inside it
+func markdownFence(content, language string) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + strings.TrimRight(content, "\n") + "\n" + fence + "\n"
+}
+
+// quoteMarkdown prefixes every line of text with one blockquote marker per level
+func quoteMarkdown(text string, level int) string {
+	if level <= 0 {
+		return text
+	}
+	prefix := strings.Repeat("> ", level)
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
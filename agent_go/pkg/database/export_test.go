@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+func newExportTestSession(t *testing.T) *SQLiteDB {
+	t.Helper()
+	db := newTestSQLiteDB(t)
+	ctx := context.Background()
+	if _, err := db.CreateChatSession(ctx, &CreateChatSessionRequest{SessionID: "session-1", Title: "Bucket audit", AgentMode: "simple"}); err != nil {
+		t.Fatalf("CreateChatSession: %v", err)
+	}
+
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	step := 0
+	store := func(level int, data events.EventData) {
+		t.Helper()
+		step++
+		event := &events.AgentEvent{Type: data.GetEventType(), Timestamp: start.Add(time.Duration(step) * time.Second), EventIndex: step, HierarchyLevel: level, SessionID: "session-1", Data: data}
+		if err := db.StoreEvent(ctx, "session-1", event); err != nil {
+			t.Fatalf("StoreEvent: %v", err)
+		}
+	}
+	store(0, &events.UserMessageEvent{Content: "Which S3 buckets are public?", Role: "user"})
+	store(0, &events.LLMGenerationEndEvent{Turn: 1, ToolCalls: 1})
+	store(0, &events.ToolCallStartEvent{Turn: 1, ToolName: "list_buckets", ServerName: "aws", ToolParams: events.ToolParams{Arguments: `{"region": "us-east-1"}`}})
+	store(1, &events.ToolCallEndEvent{Turn: 1, ToolName: "list_buckets", ServerName: "aws", Result: "logs (public), backups (private)"})
+	store(0, &events.LLMGenerationEndEvent{Turn: 2, Content: "Only the logs bucket is public."})
+	store(0, &events.ConversationEndEvent{Result: "Only the logs bucket is public.", Status: "completed"})
+	return db
+}
+
+func TestExportSessionMarkdown(t *testing.T) {
+	db := newExportTestSession(t)
+
+	exported, err := db.ExportSession(context.Background(), "session-1", ExportFormatMarkdown)
+	if err != nil {
+		t.Fatalf("ExportSession: %v", err)
+	}
+	markdown := string(exported)
+
+	position := -1
+	for _, want := range []string{
+		"# Bucket audit",
+		"### User\n\nWhich S3 buckets are public?",
+		"### Tool call: aws/list_buckets\n\n```json\n{\"region\": \"us-east-1\"}\n```",
+		"> ### Tool result: aws/list_buckets\n>\n> ```\n> logs (public), backups (private)\n> ```",
+		"### Final result\n\nOnly the logs bucket is public.",
+	} {
+		next := strings.Index(markdown, want)
+		if next <= position {
+			t.Fatalf("expected %q after position %d in:\n%s", want, position, markdown)
+		}
+		position = next
+	}
+	if strings.Count(markdown, "Only the logs bucket is public.") != 1 {
+		t.Errorf("expected the final answer once, got:\n%s", markdown)
+	}
+}
+
+func TestExportSessionJSON(t *testing.T) {
+	db := newExportTestSession(t)
+
+	exported, err := db.ExportSession(context.Background(), "session-1", ExportFormatJSON)
+	if err != nil {
+		t.Fatalf("ExportSession: %v", err)
+	}
+	var transcript SessionTranscript
+	if err := json.Unmarshal(exported, &transcript); err != nil {
+		t.Fatalf("export is not JSON: %v", err)
+	}
+
+	var kinds []string
+	for _, entry := range transcript.Entries {
+		kinds = append(kinds, entry.Kind)
+	}
+	want := []string{TranscriptUserMessage, TranscriptToolCall, TranscriptToolResult, TranscriptFinalResult}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Fatalf("entry kinds = %v, want %v", kinds, want)
+	}
+	if transcript.SessionID != "session-1" || transcript.Entries[2].HierarchyLevel != 1 || transcript.Entries[1].Arguments != `{"region": "us-east-1"}` {
+		t.Fatalf("unexpected transcript %+v", transcript)
+	}
+
+	if _, err := db.ExportSession(context.Background(), "session-1", "pdf"); !errors.Is(err, ErrUnsupportedExportFormat) {
+		t.Fatalf("expected an unsupported format error, got %v", err)
+	}
+	if _, err := db.ExportSession(context.Background(), "missing", ExportFormatJSON); err == nil {
+		t.Fatal("expected an error for an unknown session")
+	}
+}
@@ -34,6 +34,27 @@ type Database interface {
 	UpdateWorkflow(ctx context.Context, presetQueryID string, req *UpdateWorkflowRequest) (*Workflow, error)
 	DeleteWorkflow(ctx context.Context, presetQueryID string) error
 
+	// Conversation snapshots for sessions evicted from server memory (history is a JSON array of messages)
+	SaveConversationSnapshot(ctx context.Context, sessionID string, history string) error
+	GetConversationSnapshot(ctx context.Context, sessionID string) (string, error)
+	DeleteConversationSnapshot(ctx context.Context, sessionID string) error
+
+	// Tools enabled per session, restored after a server restart. GetSessionEnabledTools returns
+	// nil when the session has no stored selection; an empty selection means all tools
+	SaveSessionEnabledTools(ctx context.Context, sessionID string, enabledTools []string) error
+	GetSessionEnabledTools(ctx context.Context, sessionID string) ([]string, error)
+
+	// Retention. Sessions with status "active" and their events are never pruned.
+	// PruneEvents deletes events older than olderThan and returns how many were removed
+	PruneEvents(ctx context.Context, olderThan time.Time) (int64, error)
+	// PruneSessions deletes sessions with no activity since olderThan, with their events,
+	// conversation snapshots and enabled tools, and returns how many sessions were removed
+	PruneSessions(ctx context.Context, olderThan time.Time) (int64, error)
+
+	// ExportSession renders the session's transcript (user messages, LLM messages, tool calls
+	// and results, final result) as Markdown ("md") or a structured JSON bundle ("json")
+	ExportSession(ctx context.Context, sessionID, format string) ([]byte, error)
+
 	// Health check
 	Ping(ctx context.Context) error
 	Close() error
@@ -59,6 +80,7 @@ type EventFilter struct {
 	FromDate       time.Time        `json:"from_date,omitempty"`
 	ToDate         time.Time        `json:"to_date,omitempty"`
 	HierarchyLevel int              `json:"hierarchy_level,omitempty"`
+	TextQuery      string           `json:"text_query,omitempty"` // Content search over the serialized event data
 	Limit          int              `json:"limit,omitempty"`
 	Offset         int              `json:"offset,omitempty"`
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
 // Migration represents a database migration
@@ -17,7 +18,8 @@ type Migration struct {
 
 // MigrationRunner handles database migrations
 type MigrationRunner struct {
-	db *sql.DB
+	db       *sql.DB
+	postgres bool // Postgres uses $N placeholders and TIMESTAMPTZ
 }
 
 // NewMigrationRunner creates a new migration runner
@@ -25,6 +27,11 @@ func NewMigrationRunner(db *sql.DB) *MigrationRunner {
 	return &MigrationRunner{db: db}
 }
 
+// NewPostgresMigrationRunner creates a migration runner for a Postgres database
+func NewPostgresMigrationRunner(db *sql.DB) *MigrationRunner {
+	return &MigrationRunner{db: db, postgres: true}
+}
+
 // RunMigrations runs all pending migrations
 func (mr *MigrationRunner) RunMigrations(migrationsDir string) error {
 	// Create migrations table if it doesn't exist
@@ -69,10 +76,23 @@ func (mr *MigrationRunner) createMigrationsTable() error {
 			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
 	`
+	if mr.postgres {
+		query = strings.Replace(query, "DATETIME", "TIMESTAMPTZ", 1)
+	}
 	_, err := mr.db.Exec(query)
 	return err
 }
 
+// recordMigration marks a migration as applied
+func (mr *MigrationRunner) recordMigration(tx *sql.Tx, version int) error {
+	query := `INSERT INTO schema_migrations (version) VALUES (?)`
+	if mr.postgres {
+		query = `INSERT INTO schema_migrations (version) VALUES ($1)`
+	}
+	_, err := tx.Exec(query, version)
+	return err
+}
+
 // loadMigrations loads migration files from the migrations directory
 func (mr *MigrationRunner) loadMigrations(migrationsDir string) ([]Migration, error) {
 	var migrations []Migration
@@ -193,8 +213,7 @@ func (mr *MigrationRunner) runMigration(migration Migration) error {
 				fmt.Printf("⚠️  Migration %d: %s - Column 'selected_folder' already exists, skipping\n", migration.Version, migration.Name)
 
 				// Record migration as applied
-				_, recordErr := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, migration.Version)
-				if recordErr != nil {
+				if recordErr := mr.recordMigration(tx, migration.Version); recordErr != nil {
 					return fmt.Errorf("failed to record migration: %w", recordErr)
 				}
 
@@ -210,8 +229,7 @@ func (mr *MigrationRunner) runMigration(migration Migration) error {
 	}
 
 	// Record migration as applied
-	_, err = tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, migration.Version)
-	if err != nil {
+	if err := mr.recordMigration(tx, migration.Version); err != nil {
 		return fmt.Errorf("failed to record migration: %w", err)
 	}
 
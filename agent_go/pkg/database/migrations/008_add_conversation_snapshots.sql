@@ -0,0 +1,9 @@
+-- Migration 008: Add conversation_snapshots table
+-- Holds the conversation history of idle sessions evicted from server memory under memory pressure
+-- Format: JSON array of messages, restored into memory when the session receives its next query
+
+CREATE TABLE IF NOT EXISTS conversation_snapshots (
+    session_id TEXT PRIMARY KEY,
+    history TEXT NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
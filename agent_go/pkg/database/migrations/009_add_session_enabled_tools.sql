@@ -0,0 +1,9 @@
+-- Migration 009: Add session_enabled_tools table
+-- Holds the tools enabled for a chat session so the selection survives server restarts
+-- Format: JSON array of tool names; an empty array means all tools are enabled
+
+CREATE TABLE IF NOT EXISTS session_enabled_tools (
+    session_id TEXT PRIMARY KEY,
+    enabled_tools TEXT NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,79 @@
+-- Postgres Migration 000: Initial Database Schema
+-- Mirrors the SQLite schema after its migrations 000-008, using native types:
+-- TIMESTAMPTZ for times, BOOLEAN for flags and JSONB for event data and JSON settings
+
+-- Preset queries table (stores user-defined and predefined query templates)
+CREATE TABLE IF NOT EXISTS preset_queries (
+    id TEXT PRIMARY KEY DEFAULT replace(gen_random_uuid()::text, '-', ''),
+    label TEXT NOT NULL, -- Display name for the preset
+    query TEXT NOT NULL, -- The actual query text
+    selected_servers JSONB DEFAULT '[]', -- JSON array of server names (e.g., ["aws", "github"])
+    selected_tools JSONB DEFAULT '[]', -- JSON array of "server:tool" strings
+    selected_folder TEXT DEFAULT NULL, -- Single folder path for orchestrator/workflow modes
+    agent_mode TEXT DEFAULT 'ReAct', -- Agent mode: simple, ReAct, orchestrator, workflow
+    llm_config JSONB DEFAULT NULL, -- Optional provider/model override
+    is_predefined BOOLEAN DEFAULT FALSE, -- Whether this is a built-in preset
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT DEFAULT 'user' -- For future multi-user support
+);
+
+-- Chat sessions table (simple)
+CREATE TABLE IF NOT EXISTS chat_sessions (
+    id TEXT PRIMARY KEY DEFAULT replace(gen_random_uuid()::text, '-', ''),
+    session_id TEXT UNIQUE NOT NULL, -- Maps to events.SessionID
+    title TEXT, -- Auto-generated title
+    agent_mode TEXT, -- simple, react, orchestrator
+    preset_query_id TEXT REFERENCES preset_queries(id) ON DELETE SET NULL, -- Optional link to preset query
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ,
+    status TEXT DEFAULT 'active' -- active, completed, error
+);
+
+-- Events table (stores all typed events as JSON)
+CREATE TABLE IF NOT EXISTS events (
+    id TEXT PRIMARY KEY DEFAULT replace(gen_random_uuid()::text, '-', ''),
+    session_id TEXT NOT NULL,
+    chat_session_id TEXT REFERENCES chat_sessions(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL, -- Maps to events.EventType
+    timestamp TIMESTAMPTZ NOT NULL,
+    event_data JSONB NOT NULL -- The complete typed event
+);
+
+-- Workflows table (stores workflow state for todo-list-based execution)
+CREATE TABLE IF NOT EXISTS workflows (
+    id TEXT PRIMARY KEY DEFAULT replace(gen_random_uuid()::text, '-', ''),
+    preset_query_id TEXT NOT NULL REFERENCES preset_queries(id) ON DELETE CASCADE,
+    workflow_status TEXT DEFAULT 'pre-verification', -- 'pre-verification', 'post-verification', 'post-verification-todo-refinement'
+    selected_options JSONB DEFAULT NULL, -- Selected options for the current workflow phase
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Conversation history of idle sessions evicted from server memory (JSON array of messages)
+CREATE TABLE IF NOT EXISTS conversation_snapshots (
+    session_id TEXT PRIMARY KEY,
+    history TEXT NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tools enabled per chat session (JSON array of tool names, empty means all tools)
+CREATE TABLE IF NOT EXISTS session_enabled_tools (
+    session_id TEXT PRIMARY KEY,
+    enabled_tools TEXT NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_chat_sessions_created_at ON chat_sessions(created_at);
+CREATE INDEX IF NOT EXISTS idx_chat_sessions_preset_query_id ON chat_sessions(preset_query_id);
+CREATE INDEX IF NOT EXISTS idx_events_session_id ON events(session_id);
+CREATE INDEX IF NOT EXISTS idx_events_chat_session_id ON events(chat_session_id);
+CREATE INDEX IF NOT EXISTS idx_events_event_type ON events(event_type);
+CREATE INDEX IF NOT EXISTS idx_events_timestamp ON events(timestamp);
+CREATE INDEX IF NOT EXISTS idx_preset_queries_label ON preset_queries(label);
+CREATE INDEX IF NOT EXISTS idx_preset_queries_created_at ON preset_queries(created_at);
+CREATE INDEX IF NOT EXISTS idx_preset_queries_is_predefined ON preset_queries(is_predefined);
+CREATE INDEX IF NOT EXISTS idx_preset_queries_agent_mode ON preset_queries(agent_mode);
+CREATE INDEX IF NOT EXISTS idx_workflows_preset_query_id ON workflows(preset_query_id);
+CREATE INDEX IF NOT EXISTS idx_workflows_status ON workflows(workflow_status);
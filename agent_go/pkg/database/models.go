@@ -21,6 +21,7 @@ const (
 	AgentModeReAct        = "ReAct"
 	AgentModeOrchestrator = "orchestrator"
 	AgentModeWorkflow     = "workflow"
+	AgentModeAuto         = "auto" // Classified per query into simple, ReAct or orchestrator
 )
 
 // ChatSession represents a chat session in the database
@@ -87,6 +88,7 @@ type GetChatHistoryRequest struct {
 	EventType     string    `json:"event_type,omitempty"`
 	FromDate      time.Time `json:"from_date,omitempty"`
 	ToDate        time.Time `json:"to_date,omitempty"`
+	TextQuery     string    `json:"text_query,omitempty"` // Matches the serialized event data (tool names, messages, results)
 }
 
 // GetChatHistoryResponse represents the response for getting chat history
@@ -188,7 +190,7 @@ func (r *CreatePresetQueryRequest) Validate() error {
 
 	// Validate agent mode
 	if r.AgentMode != "" {
-		validModes := []string{AgentModeSimple, AgentModeReAct, AgentModeOrchestrator, AgentModeWorkflow}
+		validModes := []string{AgentModeSimple, AgentModeReAct, AgentModeOrchestrator, AgentModeWorkflow, AgentModeAuto}
 		valid := false
 		for _, mode := range validModes {
 			if r.AgentMode == mode {
@@ -213,7 +215,7 @@ func (r *CreatePresetQueryRequest) Validate() error {
 		if r.LLMConfig.ModelID == "" {
 			return fmt.Errorf("model_id is required when llm_config is provided")
 		}
-		validProviders := []string{"openrouter", "bedrock", "openai", "vertex"}
+		validProviders := []string{"openrouter", "bedrock", "openai", "anthropic", "vertex"}
 		valid := false
 		for _, provider := range validProviders {
 			if r.LLMConfig.Provider == provider {
@@ -244,7 +246,7 @@ type UpdatePresetQueryRequest struct {
 func (r *UpdatePresetQueryRequest) Validate() error {
 	// Validate agent mode if provided
 	if r.AgentMode != "" {
-		validModes := []string{AgentModeSimple, AgentModeReAct, AgentModeOrchestrator, AgentModeWorkflow}
+		validModes := []string{AgentModeSimple, AgentModeReAct, AgentModeOrchestrator, AgentModeWorkflow, AgentModeAuto}
 		valid := false
 		for _, mode := range validModes {
 			if r.AgentMode == mode {
@@ -269,7 +271,7 @@ func (r *UpdatePresetQueryRequest) Validate() error {
 		if r.LLMConfig.ModelID == "" {
 			return fmt.Errorf("model_id is required when llm_config is provided")
 		}
-		validProviders := []string{"openrouter", "bedrock", "openai", "vertex"}
+		validProviders := []string{"openrouter", "bedrock", "openai", "anthropic", "vertex"}
 		valid := false
 		for _, provider := range validProviders {
 			if r.LLMConfig.Provider == provider {
@@ -302,10 +304,19 @@ type WorkflowSelectedOption struct {
 	PhaseID     string `json:"phase_id"`     // Which phase this option belongs to
 }
 
+// WorkflowStepApproval is a reviewer's decision on one plan step when approving a workflow partially
+type WorkflowStepApproval struct {
+	StepNumber int    `json:"step_number"`        // 1-based step number in the plan
+	Approved   bool   `json:"approved"`           // Approved steps execute; the rest are held for revision
+	Feedback   string `json:"feedback,omitempty"` // Requested changes for a step that is not approved
+}
+
 // WorkflowSelectedOptions represents all selected options for a workflow phase (multiple groups)
 type WorkflowSelectedOptions struct {
 	PhaseID    string                   `json:"phase_id"`   // Which phase these options belong to
 	Selections []WorkflowSelectedOption `json:"selections"` // All selected options across groups
+	// Per-step decisions for partial approval (post-verification only); empty approves the whole plan
+	StepApprovals []WorkflowStepApproval `json:"step_approvals,omitempty"`
 }
 
 // Workflow represents a workflow state for todo-list-based execution
@@ -0,0 +1,836 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// PostgresDriverName is the database/sql driver NewPostgresDB opens. The binary must register a
+// Postgres driver under this name; the server imports github.com/jackc/pgx/v5/stdlib, which
+// registers "pgx".
+var PostgresDriverName = "pgx"
+
+// PostgresDB implements the Database interface using Postgres, so several server instances can
+// share one chat history
+type PostgresDB struct {
+	db *sql.DB
+}
+
+// NewPostgresDB creates a new Postgres database connection and applies the Postgres migrations
+func NewPostgresDB(dsn string) (*PostgresDB, error) {
+	if !slices.Contains(sql.Drivers(), PostgresDriverName) {
+		return nil, fmt.Errorf("postgres driver %q is not registered; import a Postgres database/sql driver in the binary", PostgresDriverName)
+	}
+
+	db, err := sql.Open(PostgresDriverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	// Run migrations (includes initial schema creation)
+	migrationRunner := NewPostgresMigrationRunner(db)
+	if err := migrationRunner.RunMigrations("pkg/database/migrations/postgres"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return &PostgresDB{db: db}, nil
+}
+
+// CreateChatSession creates a new chat session
+func (p *PostgresDB) CreateChatSession(ctx context.Context, req *CreateChatSessionRequest) (*ChatSession, error) {
+	query := `
+		INSERT INTO chat_sessions (session_id, title, agent_mode, preset_query_id, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, session_id, title, agent_mode, preset_query_id, created_at, completed_at, status
+	`
+
+	// Handle empty preset_query_id by converting to NULL
+	var presetQueryID interface{}
+	if req.PresetQueryID != "" {
+		presetQueryID = req.PresetQueryID
+	}
+
+	session, err := scanChatSession(p.db.QueryRowContext(ctx, query, req.SessionID, req.Title, req.AgentMode, presetQueryID, "active"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chat session: %w", err)
+	}
+	return session, nil
+}
+
+// GetChatSession retrieves a chat session by session ID
+func (p *PostgresDB) GetChatSession(ctx context.Context, sessionID string) (*ChatSession, error) {
+	query := `
+		SELECT id, session_id, title, agent_mode, preset_query_id, created_at, completed_at, status
+		FROM chat_sessions
+		WHERE session_id = $1
+	`
+
+	session, err := scanChatSession(p.db.QueryRowContext(ctx, query, sessionID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("chat session not found")
+		}
+		return nil, fmt.Errorf("failed to get chat session: %w", err)
+	}
+	return session, nil
+}
+
+// UpdateChatSession updates a chat session
+func (p *PostgresDB) UpdateChatSession(ctx context.Context, sessionID string, req *UpdateChatSessionRequest) (*ChatSession, error) {
+	query := `
+		UPDATE chat_sessions
+		SET title = COALESCE($1, title),
+		    agent_mode = COALESCE($2, agent_mode),
+		    preset_query_id = CASE
+		        WHEN $3::text = '' THEN NULL
+		        ELSE COALESCE($3::text, preset_query_id)
+		    END,
+		    status = COALESCE($4, status),
+		    completed_at = COALESCE($5, completed_at)
+		WHERE session_id = $6
+		RETURNING id, session_id, title, agent_mode, preset_query_id, created_at, completed_at, status
+	`
+
+	session, err := scanChatSession(p.db.QueryRowContext(ctx, query, req.Title, req.AgentMode, req.PresetQueryID, req.Status, req.CompletedAt, sessionID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("chat session not found")
+		}
+		return nil, fmt.Errorf("failed to update chat session: %w", err)
+	}
+	return session, nil
+}
+
+// DeleteChatSession deletes a chat session and all its events
+func (p *PostgresDB) DeleteChatSession(ctx context.Context, sessionID string) error {
+	return p.deleteOne(ctx, `DELETE FROM chat_sessions WHERE session_id = $1`, sessionID, "chat session", "chat session not found")
+}
+
+// ListChatSessions lists chat sessions with pagination
+func (p *PostgresDB) ListChatSessions(ctx context.Context, limit, offset int, presetQueryID *string) ([]ChatHistorySummary, int, error) {
+	var whereClause string
+	var args []interface{}
+	if presetQueryID != nil && *presetQueryID != "" {
+		whereClause = " WHERE cs.preset_query_id = $1"
+		args = append(args, *presetQueryID)
+	}
+
+	//nolint:gosec // G202: whereClause is a constant with a parameterized condition
+	countQuery := `SELECT COUNT(*) FROM chat_sessions cs` + whereClause
+	var total int
+	if err := p.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to get total count: %w", err)
+	}
+
+	//nolint:gosec // G201: whereClause is a constant with a parameterized condition
+	query := fmt.Sprintf(`
+		SELECT
+			cs.id,
+			cs.session_id,
+			cs.title,
+			cs.agent_mode,
+			cs.status,
+			cs.created_at,
+			cs.completed_at,
+			cs.preset_query_id,
+			COUNT(e.id) as total_events,
+			0 as total_turns,
+			MAX(e.timestamp) as last_activity
+		FROM chat_sessions cs
+		LEFT JOIN events e ON cs.id = e.chat_session_id%s
+		GROUP BY cs.id, cs.session_id, cs.title, cs.agent_mode, cs.status, cs.created_at, cs.completed_at, cs.preset_query_id
+		ORDER BY cs.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list chat sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []ChatHistorySummary
+	for rows.Next() {
+		var session ChatHistorySummary
+		var title, agentMode, presetQueryIDStr sql.NullString
+		var lastActivity sql.NullTime
+		err := rows.Scan(
+			&session.ChatSessionID, &session.SessionID, &title, &agentMode, &session.Status,
+			&session.CreatedAt, &session.CompletedAt, &presetQueryIDStr, &session.TotalEvents, &session.TotalTurns, &lastActivity,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan session: %w", err)
+		}
+		session.Title = title.String
+		session.AgentMode = agentMode.String
+		session.PresetQueryID = presetQueryIDStr.String
+		if lastActivity.Valid {
+			session.LastActivity = &lastActivity.Time
+		} else {
+			session.LastActivity = &session.CreatedAt
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list chat sessions: %w", err)
+	}
+
+	return sessions, total, nil
+}
+
+// StoreEvent stores an event in the database
+func (p *PostgresDB) StoreEvent(ctx context.Context, sessionID string, event *events.AgentEvent) error {
+	chatSession, err := p.GetChatSession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat session: %w", err)
+	}
+
+	eventData, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	query := `
+		INSERT INTO events (session_id, chat_session_id, event_type, timestamp, event_data)
+		VALUES ($1, $2, $3, $4, $5::jsonb)
+	`
+	if _, err := p.db.ExecContext(ctx, query, sessionID, chatSession.ID, string(event.Type), event.Timestamp, string(eventData)); err != nil {
+		return fmt.Errorf("failed to store event: %w", err)
+	}
+
+	return nil
+}
+
+// GetEvents retrieves events based on the request
+func (p *PostgresDB) GetEvents(ctx context.Context, req *GetChatHistoryRequest) (*GetEventsResponse, error) {
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if req.SessionID != "" {
+		addCondition("session_id = $%d", req.SessionID)
+	}
+	if req.EventType != "" {
+		addCondition("event_type = $%d", req.EventType)
+	}
+	if !req.FromDate.IsZero() {
+		addCondition("timestamp >= $%d", req.FromDate)
+	}
+	if !req.ToDate.IsZero() {
+		addCondition("timestamp <= $%d", req.ToDate)
+	}
+	if query := strings.TrimSpace(req.TextQuery); query != "" {
+		addCondition(`event_data::text ILIKE $%d ESCAPE '\'`, likePattern(query))
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	//nolint:gosec // G201: whereClause only holds constant, parameterized conditions
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM events %s", whereClause)
+	var total int
+	if err := p.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to get total count: %w", err)
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100 // Default limit
+	}
+	offset := req.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	//nolint:gosec // G201: whereClause only holds constant, parameterized conditions
+	query := fmt.Sprintf(`
+		SELECT id, session_id, chat_session_id, event_type, timestamp, event_data
+		FROM events %s
+		ORDER BY timestamp DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	eventList, err := p.queryEvents(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+
+	return &GetEventsResponse{
+		Events: eventList,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// GetEventsBySession retrieves events for a specific session
+func (p *PostgresDB) GetEventsBySession(ctx context.Context, sessionID string, limit, offset int) ([]Event, error) {
+	query := `
+		SELECT id, session_id, chat_session_id, event_type, timestamp, event_data
+		FROM events
+		WHERE session_id = $1
+		ORDER BY timestamp ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	eventList, err := p.queryEvents(ctx, query, sessionID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events by session: %w", err)
+	}
+	return eventList, nil
+}
+
+// queryEvents runs an events query and decodes the JSONB event data of each row
+func (p *PostgresDB) queryEvents(ctx context.Context, query string, args ...interface{}) ([]Event, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var eventList []Event
+	for rows.Next() {
+		var event Event
+		var chatSessionID sql.NullString
+		var eventDataJSON []byte
+		if err := rows.Scan(&event.ID, &event.SessionID, &chatSessionID, &event.EventType, &event.Timestamp, &eventDataJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		event.ChatSessionID = chatSessionID.String
+		if err := json.Unmarshal(eventDataJSON, &event.EventData); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal event data: %w", err)
+		}
+		eventList = append(eventList, event)
+	}
+	return eventList, rows.Err()
+}
+
+// PruneEvents deletes events older than olderThan, except those of active sessions
+func (p *PostgresDB) PruneEvents(ctx context.Context, olderThan time.Time) (int64, error) {
+	query := `
+		DELETE FROM events
+		WHERE timestamp < $1
+		  AND session_id NOT IN (SELECT session_id FROM chat_sessions WHERE status = 'active')
+	`
+
+	result, err := p.db.ExecContext(ctx, query, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune events: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// PruneSessions deletes inactive sessions whose last event, completion and creation are all older than olderThan
+func (p *PostgresDB) PruneSessions(ctx context.Context, olderThan time.Time) (int64, error) {
+	query := `
+		SELECT cs.session_id
+		FROM chat_sessions cs
+		LEFT JOIN events e ON e.chat_session_id = cs.id
+		WHERE COALESCE(cs.status, '') <> 'active'
+		GROUP BY cs.id, cs.session_id, cs.created_at, cs.completed_at
+		HAVING GREATEST(cs.created_at, cs.completed_at, MAX(e.timestamp)) < $1
+	`
+	return pruneSessionsWith(ctx, p.db, query, olderThan, true)
+}
+
+// ExportSession renders the transcript of a session in the given format
+func (p *PostgresDB) ExportSession(ctx context.Context, sessionID, format string) ([]byte, error) {
+	return exportSession(ctx, p, sessionID, format)
+}
+
+// Ping tests the database connection
+func (p *PostgresDB) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
+// presetQueryColumns are the columns every preset query statement returns, in scanPresetQuery order
+const presetQueryColumns = `id, label, query, selected_servers, selected_tools, selected_folder, agent_mode, llm_config, is_predefined, created_at, updated_at, created_by`
+
+// CreatePresetQuery creates a new preset query
+func (p *PostgresDB) CreatePresetQuery(ctx context.Context, req *CreatePresetQueryRequest) (*PresetQuery, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	selectedServersJSON, err := marshalStringList(req.SelectedServers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal selected servers: %w", err)
+	}
+	selectedToolsJSON, err := marshalStringList(req.SelectedTools)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal selected tools: %w", err)
+	}
+
+	// Prepare LLM config for insert (NULL when absent)
+	var llmConfigParam interface{}
+	if req.LLMConfig != nil {
+		llmConfigBytes, err := json.Marshal(req.LLMConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal LLM config: %w", err)
+		}
+		llmConfigParam = string(llmConfigBytes)
+	}
+
+	var selectedFolder interface{}
+	if req.SelectedFolder != "" {
+		selectedFolder = req.SelectedFolder
+	}
+
+	agentMode := req.AgentMode
+	if agentMode == "" {
+		agentMode = AgentModeReAct
+	}
+
+	query := `
+		INSERT INTO preset_queries (label, query, selected_servers, selected_tools, selected_folder, agent_mode, llm_config, is_predefined, created_by)
+		VALUES ($1, $2, $3::jsonb, $4::jsonb, $5, $6, $7::jsonb, $8, $9)
+		RETURNING ` + presetQueryColumns
+
+	preset, err := scanPresetQuery(p.db.QueryRowContext(ctx, query, req.Label, req.Query, selectedServersJSON, selectedToolsJSON, selectedFolder, agentMode, llmConfigParam, req.IsPredefined, "user"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create preset query: %w", err)
+	}
+	return preset, nil
+}
+
+// GetPresetQuery retrieves a preset query by ID
+func (p *PostgresDB) GetPresetQuery(ctx context.Context, id string) (*PresetQuery, error) {
+	query := `SELECT ` + presetQueryColumns + ` FROM preset_queries WHERE id = $1`
+
+	preset, err := scanPresetQuery(p.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("preset query not found")
+		}
+		return nil, fmt.Errorf("failed to get preset query: %w", err)
+	}
+	return preset, nil
+}
+
+// UpdatePresetQuery updates a preset query
+func (p *PostgresDB) UpdatePresetQuery(ctx context.Context, id string, req *UpdatePresetQueryRequest) (*PresetQuery, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	updateFields := []string{}
+	args := []interface{}{}
+	set := func(field, cast string, value interface{}) {
+		args = append(args, value)
+		updateFields = append(updateFields, fmt.Sprintf("%s = $%d%s", field, len(args), cast))
+	}
+
+	if req.Label != "" {
+		set("label", "", req.Label)
+	}
+	if req.Query != "" {
+		set("query", "", req.Query)
+	}
+	if req.SelectedServers != nil {
+		selectedServersJSON, err := marshalStringList(req.SelectedServers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal selected servers: %w", err)
+		}
+		set("selected_servers", "::jsonb", selectedServersJSON)
+	}
+	if req.SelectedTools != nil {
+		selectedToolsJSON, err := marshalStringList(req.SelectedTools)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal selected tools: %w", err)
+		}
+		set("selected_tools", "::jsonb", selectedToolsJSON)
+	}
+	if req.SelectedFolder != "" {
+		set("selected_folder", "", req.SelectedFolder)
+	}
+	if req.AgentMode != "" {
+		set("agent_mode", "", req.AgentMode)
+	}
+	if req.LLMConfig != nil {
+		llmConfigBytes, err := json.Marshal(req.LLMConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal LLM config: %w", err)
+		}
+		set("llm_config", "::jsonb", string(llmConfigBytes))
+	}
+
+	if len(updateFields) == 0 {
+		return nil, fmt.Errorf("no fields to update")
+	}
+
+	// Validate all update fields are from whitelist
+	for _, field := range updateFields {
+		if !validateUpdateField(field) {
+			return nil, fmt.Errorf("invalid update field: %s", field)
+		}
+	}
+
+	updateFields = append(updateFields, "updated_at = CURRENT_TIMESTAMP")
+	args = append(args, id)
+
+	//nolint:gosec // G201: updateFields are validated against whitelist and use parameterized queries ($N)
+	query := fmt.Sprintf(`
+		UPDATE preset_queries
+		SET %s
+		WHERE id = $%d
+		RETURNING %s
+	`, strings.Join(updateFields, ", "), len(args), presetQueryColumns)
+
+	preset, err := scanPresetQuery(p.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("preset query not found")
+		}
+		return nil, fmt.Errorf("failed to update preset query: %w", err)
+	}
+	return preset, nil
+}
+
+// DeletePresetQuery deletes a preset query
+func (p *PostgresDB) DeletePresetQuery(ctx context.Context, id string) error {
+	return p.deleteOne(ctx, `DELETE FROM preset_queries WHERE id = $1`, id, "preset query", "preset query not found")
+}
+
+// ListPresetQueries lists preset queries with pagination
+func (p *PostgresDB) ListPresetQueries(ctx context.Context, limit, offset int) ([]PresetQuery, int, error) {
+	var total int
+	if err := p.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM preset_queries`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to get total count: %w", err)
+	}
+
+	query := `SELECT ` + presetQueryColumns + ` FROM preset_queries ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+	rows, err := p.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list preset queries: %w", err)
+	}
+	defer rows.Close()
+
+	presets := make([]PresetQuery, 0) // Initialize as empty slice, not nil
+	for rows.Next() {
+		preset, err := scanPresetQuery(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan preset query: %w", err)
+		}
+		if preset.LLMConfig == nil {
+			preset.LLMConfig = json.RawMessage("null")
+		}
+		presets = append(presets, *preset)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list preset queries: %w", err)
+	}
+
+	return presets, total, nil
+}
+
+// workflowColumns are the columns every workflow statement returns, in scanWorkflow order
+const workflowColumns = `id, preset_query_id, workflow_status, selected_options, created_at, updated_at`
+
+// CreateWorkflow creates a new workflow
+func (p *PostgresDB) CreateWorkflow(ctx context.Context, req *CreateWorkflowRequest) (*Workflow, error) {
+	workflowStatus := req.WorkflowStatus
+	if workflowStatus == "" {
+		workflowStatus = WorkflowStatusPreVerification
+	}
+
+	var selectedOptionsJSON sql.NullString
+	if req.SelectedOptions != nil {
+		jsonBytes, err := json.Marshal(*req.SelectedOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal selected_options: %w", err)
+		}
+		selectedOptionsJSON = sql.NullString{String: string(jsonBytes), Valid: true}
+	}
+
+	query := `
+		INSERT INTO workflows (preset_query_id, workflow_status, selected_options)
+		VALUES ($1, $2, $3::jsonb)
+		RETURNING ` + workflowColumns
+
+	workflow, err := scanWorkflow(p.db.QueryRowContext(ctx, query, req.PresetQueryID, workflowStatus, selectedOptionsJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workflow: %w", err)
+	}
+	return workflow, nil
+}
+
+// GetWorkflowByPresetQueryID retrieves a workflow by preset query ID
+func (p *PostgresDB) GetWorkflowByPresetQueryID(ctx context.Context, presetQueryID string) (*Workflow, error) {
+	query := `SELECT ` + workflowColumns + ` FROM workflows WHERE preset_query_id = $1`
+
+	workflow, err := scanWorkflow(p.db.QueryRowContext(ctx, query, presetQueryID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("workflow not found for preset query: %s", presetQueryID)
+		}
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+	return workflow, nil
+}
+
+// UpdateWorkflow updates a workflow, creating it if it doesn't exist
+func (p *PostgresDB) UpdateWorkflow(ctx context.Context, presetQueryID string, req *UpdateWorkflowRequest) (*Workflow, error) {
+	existingWorkflow, err := p.GetWorkflowByPresetQueryID(ctx, presetQueryID)
+	if err != nil && !strings.Contains(err.Error(), "workflow not found for preset query") {
+		return nil, fmt.Errorf("failed to check existing workflow: %w", err)
+	}
+
+	if existingWorkflow == nil {
+		workflowStatus := WorkflowStatusPreVerification
+		if req.WorkflowStatus != nil {
+			workflowStatus = *req.WorkflowStatus
+		}
+
+		workflow, err := p.CreateWorkflow(ctx, &CreateWorkflowRequest{
+			PresetQueryID:   presetQueryID,
+			WorkflowStatus:  workflowStatus,
+			SelectedOptions: req.SelectedOptions,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create workflow: %w", err)
+		}
+		return workflow, nil
+	}
+
+	updateFields := []string{}
+	args := []interface{}{}
+	if req.WorkflowStatus != nil {
+		args = append(args, *req.WorkflowStatus)
+		updateFields = append(updateFields, fmt.Sprintf("workflow_status = $%d", len(args)))
+	}
+	if req.SelectedOptions != nil {
+		selectedOptionsJSON, err := json.Marshal(*req.SelectedOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal selected_options: %w", err)
+		}
+		args = append(args, string(selectedOptionsJSON))
+		updateFields = append(updateFields, fmt.Sprintf("selected_options = $%d::jsonb", len(args)))
+	}
+
+	if len(updateFields) == 0 {
+		return nil, fmt.Errorf("no fields to update")
+	}
+
+	for _, field := range updateFields {
+		if !validateUpdateField(field) {
+			return nil, fmt.Errorf("invalid update field: %s", field)
+		}
+	}
+
+	updateFields = append(updateFields, "updated_at = CURRENT_TIMESTAMP")
+	args = append(args, presetQueryID)
+
+	//nolint:gosec // G201: updateFields are validated against whitelist and use parameterized queries ($N)
+	query := fmt.Sprintf(`
+		UPDATE workflows
+		SET %s
+		WHERE preset_query_id = $%d
+		RETURNING %s
+	`, strings.Join(updateFields, ", "), len(args), workflowColumns)
+
+	return scanWorkflow(p.db.QueryRowContext(ctx, query, args...))
+}
+
+// DeleteWorkflow deletes a workflow
+func (p *PostgresDB) DeleteWorkflow(ctx context.Context, presetQueryID string) error {
+	return p.deleteOne(ctx, `DELETE FROM workflows WHERE preset_query_id = $1`, presetQueryID, "workflow", "workflow not found for preset query: "+presetQueryID)
+}
+
+// SaveConversationSnapshot stores (or replaces) the conversation history of a session
+func (p *PostgresDB) SaveConversationSnapshot(ctx context.Context, sessionID string, history string) error {
+	query := `
+		INSERT INTO conversation_snapshots (session_id, history, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (session_id) DO UPDATE SET history = excluded.history, updated_at = excluded.updated_at
+	`
+
+	if _, err := p.db.ExecContext(ctx, query, sessionID, history, time.Now()); err != nil {
+		return fmt.Errorf("failed to save conversation snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetConversationSnapshot returns the stored conversation history of a session, or "" if there is none
+func (p *PostgresDB) GetConversationSnapshot(ctx context.Context, sessionID string) (string, error) {
+	var history string
+	err := p.db.QueryRowContext(ctx, `SELECT history FROM conversation_snapshots WHERE session_id = $1`, sessionID).Scan(&history)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get conversation snapshot: %w", err)
+	}
+	return history, nil
+}
+
+// DeleteConversationSnapshot removes the stored conversation history of a session
+func (p *PostgresDB) DeleteConversationSnapshot(ctx context.Context, sessionID string) error {
+	if _, err := p.db.ExecContext(ctx, `DELETE FROM conversation_snapshots WHERE session_id = $1`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete conversation snapshot: %w", err)
+	}
+	return nil
+}
+
+// SaveSessionEnabledTools stores (or replaces) the tools enabled for a session
+func (p *PostgresDB) SaveSessionEnabledTools(ctx context.Context, sessionID string, enabledTools []string) error {
+	encoded, err := marshalStringList(enabledTools)
+	if err != nil {
+		return fmt.Errorf("failed to marshal enabled tools: %w", err)
+	}
+
+	query := `
+		INSERT INTO session_enabled_tools (session_id, enabled_tools, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (session_id) DO UPDATE SET enabled_tools = excluded.enabled_tools, updated_at = excluded.updated_at
+	`
+
+	if _, err := p.db.ExecContext(ctx, query, sessionID, encoded, time.Now()); err != nil {
+		return fmt.Errorf("failed to save session enabled tools: %w", err)
+	}
+	return nil
+}
+
+// GetSessionEnabledTools returns the tools enabled for a session, or nil if none were stored
+func (p *PostgresDB) GetSessionEnabledTools(ctx context.Context, sessionID string) ([]string, error) {
+	var encoded string
+	err := p.db.QueryRowContext(ctx, `SELECT enabled_tools FROM session_enabled_tools WHERE session_id = $1`, sessionID).Scan(&encoded)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get session enabled tools: %w", err)
+	}
+	return unmarshalEnabledTools(encoded)
+}
+
+// Close closes the database connection
+func (p *PostgresDB) Close() error {
+	return p.db.Close()
+}
+
+// deleteOne runs a single-row delete and reports notFound when nothing matched
+func (p *PostgresDB) deleteOne(ctx context.Context, query, key, what, notFound string) error {
+	result, err := p.db.ExecContext(ctx, query, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", what, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s", notFound)
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanChatSession scans the chat session columns, mapping NULL text columns to empty values
+func scanChatSession(row rowScanner) (*ChatSession, error) {
+	var session ChatSession
+	var title, agentMode, presetQueryID sql.NullString
+	if err := row.Scan(&session.ID, &session.SessionID, &title, &agentMode, &presetQueryID, &session.CreatedAt, &session.CompletedAt, &session.Status); err != nil {
+		return nil, err
+	}
+	session.Title = title.String
+	session.AgentMode = agentMode.String
+	if presetQueryID.Valid {
+		session.PresetQueryID = &presetQueryID.String
+	}
+	return &session, nil
+}
+
+// scanPresetQuery scans presetQueryColumns; JSONB columns arrive as their JSON text
+func scanPresetQuery(row rowScanner) (*PresetQuery, error) {
+	var preset PresetQuery
+	var selectedServers, selectedTools, agentMode, llmConfig, createdBy sql.NullString
+	var isPredefined sql.NullBool
+	err := row.Scan(
+		&preset.ID, &preset.Label, &preset.Query, &selectedServers, &selectedTools, &preset.SelectedFolder, &agentMode, &llmConfig, &isPredefined, &preset.CreatedAt, &preset.UpdatedAt, &createdBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	preset.SelectedServers = selectedServers.String
+	if !selectedServers.Valid {
+		preset.SelectedServers = "[]"
+	}
+	preset.SelectedTools = selectedTools.String
+	if !selectedTools.Valid {
+		preset.SelectedTools = "[]"
+	}
+	preset.AgentMode = agentMode.String
+	preset.IsPredefined = isPredefined.Bool
+	preset.CreatedBy = createdBy.String
+	if llmConfig.Valid {
+		preset.LLMConfig = json.RawMessage(llmConfig.String)
+	}
+	return &preset, nil
+}
+
+// scanWorkflow scans workflowColumns and decodes the selected options
+func scanWorkflow(row rowScanner) (*Workflow, error) {
+	var workflow Workflow
+	var workflowStatus, selectedOptionsJSON sql.NullString
+	if err := row.Scan(&workflow.ID, &workflow.PresetQueryID, &workflowStatus, &selectedOptionsJSON, &workflow.CreatedAt, &workflow.UpdatedAt); err != nil {
+		return nil, err
+	}
+	workflow.WorkflowStatus = workflowStatus.String
+
+	if selectedOptionsJSON.Valid && selectedOptionsJSON.String != "" {
+		var selectedOptions WorkflowSelectedOptions
+		if err := json.Unmarshal([]byte(selectedOptionsJSON.String), &selectedOptions); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal selected_options: %w", err)
+		}
+		workflow.SelectedOptions = &selectedOptions
+	}
+	return &workflow, nil
+}
+
+// marshalStringList encodes a server or tool selection as a JSON array, "[]" when empty
+func marshalStringList(values []string) (string, error) {
+	if len(values) == 0 {
+		return "[]", nil
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// unmarshalEnabledTools decodes a stored enabled tools selection, keeping an empty selection non-nil
+func unmarshalEnabledTools(encoded string) ([]string, error) {
+	enabledTools := []string{}
+	if err := json.Unmarshal([]byte(encoded), &enabledTools); err != nil {
+		return nil, fmt.Errorf("failed to decode session enabled tools: %w", err)
+	}
+	return enabledTools, nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// pruneSessionsWith deletes the sessions returned by selectQuery (run with cutoff), with their
+// events, conversation snapshots and enabled tools, in one transaction. Statements use ? placeholders, which
+// are rewritten to $1 for Postgres.
+func pruneSessionsWith(ctx context.Context, db *sql.DB, selectQuery string, cutoff time.Time, postgres bool) (int64, error) {
+	rows, err := db.QueryContext(ctx, selectQuery, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired sessions: %w", err)
+	}
+	var sessionIDs []string
+	for rows.Next() {
+		var sessionID string
+		if err := rows.Scan(&sessionID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan expired session: %w", err)
+		}
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to find expired sessions: %w", err)
+	}
+	if len(sessionIDs) == 0 {
+		return 0, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Events are deleted explicitly rather than relying on ON DELETE CASCADE, which SQLite
+	// only enforces on connections that enabled foreign keys
+	statements := []string{
+		`DELETE FROM events WHERE session_id = ?`,
+		`DELETE FROM conversation_snapshots WHERE session_id = ?`,
+		`DELETE FROM session_enabled_tools WHERE session_id = ?`,
+		`DELETE FROM chat_sessions WHERE session_id = ?`,
+	}
+	for _, sessionID := range sessionIDs {
+		for _, statement := range statements {
+			if postgres {
+				statement = strings.Replace(statement, "?", "$1", 1)
+			}
+			if _, err := tx.ExecContext(ctx, statement, sessionID); err != nil {
+				return 0, fmt.Errorf("failed to prune session %s: %w", sessionID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit session pruning: %w", err)
+	}
+	return int64(len(sessionIDs)), nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// newTestSQLiteDB opens a fresh SQLite database with the chat session, event, snapshot and enabled tools tables
+func newTestSQLiteDB(t *testing.T) *SQLiteDB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "chat_history.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, migration := range []string{"000_initial_schema.sql", "008_add_conversation_snapshots.sql", "009_add_session_enabled_tools.sql"} {
+		schema, err := os.ReadFile(filepath.Join("migrations", migration))
+		if err != nil {
+			t.Fatalf("read %s: %v", migration, err)
+		}
+		if _, err := db.Exec(string(schema)); err != nil {
+			t.Fatalf("apply %s: %v", migration, err)
+		}
+	}
+	return &SQLiteDB{db: db}
+}
+
+func TestPruneRemovesOnlyOldInactiveHistory(t *testing.T) {
+	db := newTestSQLiteDB(t)
+	ctx := context.Background()
+	now := time.Now()
+	old := now.Add(-72 * time.Hour)
+	cutoff := now.Add(-24 * time.Hour)
+
+	createSession := func(sessionID, status string, created time.Time, eventTimes ...time.Time) {
+		t.Helper()
+		if _, err := db.CreateChatSession(ctx, &CreateChatSessionRequest{SessionID: sessionID, Title: sessionID}); err != nil {
+			t.Fatalf("CreateChatSession(%s): %v", sessionID, err)
+		}
+		if _, err := db.db.ExecContext(ctx, `UPDATE chat_sessions SET status = ?, created_at = ? WHERE session_id = ?`, status, created, sessionID); err != nil {
+			t.Fatalf("backdate %s: %v", sessionID, err)
+		}
+		for _, timestamp := range eventTimes {
+			if err := db.StoreEvent(ctx, sessionID, &events.AgentEvent{Type: events.ToolCallStart, Timestamp: timestamp, SessionID: sessionID}); err != nil {
+				t.Fatalf("StoreEvent(%s): %v", sessionID, err)
+			}
+		}
+	}
+	eventCount := func(sessionID string) int {
+		t.Helper()
+		stored, err := db.GetEventsBySession(ctx, sessionID, 100, 0)
+		if err != nil {
+			t.Fatalf("GetEventsBySession(%s): %v", sessionID, err)
+		}
+		return len(stored)
+	}
+
+	createSession("recent", "completed", old, old, now)
+	createSession("expired", "completed", old, old, old)
+	createSession("running", "active", old, old)
+
+	// Old events go, except those of the active session
+	pruned, err := db.PruneEvents(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("PruneEvents: %v", err)
+	}
+	if pruned != 3 {
+		t.Fatalf("PruneEvents removed %d events, want 3", pruned)
+	}
+	if got := eventCount("recent"); got != 1 {
+		t.Fatalf("recent session has %d events, want its 1 recent event", got)
+	}
+	if got := eventCount("running"); got != 1 {
+		t.Fatalf("active session has %d events, want 1", got)
+	}
+
+	// Sessions with no activity since the cutoff go, active ones stay
+	if err := db.SaveConversationSnapshot(ctx, "expired", "[]"); err != nil {
+		t.Fatalf("SaveConversationSnapshot: %v", err)
+	}
+	prunedSessions, err := db.PruneSessions(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("PruneSessions: %v", err)
+	}
+	if prunedSessions != 1 {
+		t.Fatalf("PruneSessions removed %d sessions, want 1", prunedSessions)
+	}
+	if _, err := db.GetChatSession(ctx, "expired"); err == nil {
+		t.Fatal("expired session was not pruned")
+	}
+	if history, err := db.GetConversationSnapshot(ctx, "expired"); err != nil || history != "" {
+		t.Fatalf("expired session snapshot = %q, %v; want it removed", history, err)
+	}
+	for _, sessionID := range []string{"recent", "running"} {
+		if _, err := db.GetChatSession(ctx, sessionID); err != nil {
+			t.Fatalf("session %s was pruned: %v", sessionID, err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSessionEnabledToolsRoundTrip(t *testing.T) {
+	db := newTestSQLiteDB(t)
+	ctx := context.Background()
+
+	if tools, err := db.GetSessionEnabledTools(ctx, "session-1"); err != nil || tools != nil {
+		t.Fatalf("GetSessionEnabledTools before save = %v, %v; want nil", tools, err)
+	}
+
+	if err := db.SaveSessionEnabledTools(ctx, "session-1", []string{"aws:list_buckets", "github:get_issue"}); err != nil {
+		t.Fatalf("SaveSessionEnabledTools: %v", err)
+	}
+	if err := db.SaveSessionEnabledTools(ctx, "session-1", []string{"aws:list_buckets"}); err != nil {
+		t.Fatalf("SaveSessionEnabledTools (replace): %v", err)
+	}
+	tools, err := db.GetSessionEnabledTools(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("GetSessionEnabledTools: %v", err)
+	}
+	if !reflect.DeepEqual(tools, []string{"aws:list_buckets"}) {
+		t.Fatalf("GetSessionEnabledTools = %v, want the replaced selection", tools)
+	}
+
+	// An empty selection is stored and means all tools, not "no selection"
+	if err := db.SaveSessionEnabledTools(ctx, "session-2", nil); err != nil {
+		t.Fatalf("SaveSessionEnabledTools (empty): %v", err)
+	}
+	if tools, err := db.GetSessionEnabledTools(ctx, "session-2"); err != nil || tools == nil || len(tools) != 0 {
+		t.Fatalf("GetSessionEnabledTools (empty) = %#v, %v; want an empty selection", tools, err)
+	}
+}
+
+func TestPruneSessionsRemovesEnabledTools(t *testing.T) {
+	db := newTestSQLiteDB(t)
+	ctx := context.Background()
+
+	if _, err := db.CreateChatSession(ctx, &CreateChatSessionRequest{SessionID: "expired", Title: "expired"}); err != nil {
+		t.Fatalf("CreateChatSession: %v", err)
+	}
+	if _, err := db.db.ExecContext(ctx, `UPDATE chat_sessions SET status = ?, created_at = ? WHERE session_id = ?`, "completed", time.Now().Add(-72*time.Hour), "expired"); err != nil {
+		t.Fatalf("backdate session: %v", err)
+	}
+	if err := db.SaveSessionEnabledTools(ctx, "expired", []string{"aws:list_buckets"}); err != nil {
+		t.Fatalf("SaveSessionEnabledTools: %v", err)
+	}
+
+	if _, err := db.PruneSessions(ctx, time.Now().Add(-24*time.Hour)); err != nil {
+		t.Fatalf("PruneSessions: %v", err)
+	}
+	if tools, err := db.GetSessionEnabledTools(ctx, "expired"); err != nil || tools != nil {
+		t.Fatalf("enabled tools of pruned session = %v, %v; want them removed", tools, err)
+	}
+}
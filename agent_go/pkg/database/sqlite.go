@@ -15,7 +15,8 @@ import (
 
 // SQLiteDB implements the Database interface using SQLite
 type SQLiteDB struct {
-	db *sql.DB
+	db  *sql.DB
+	fts bool // events_fts is available for content search
 }
 
 // validateWhereClause ensures the WHERE clause only contains safe, parameterized conditions
@@ -76,7 +77,9 @@ func NewSQLiteDB(dbPath string) (*SQLiteDB, error) {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	return &SQLiteDB{db: db}, nil
+	s := &SQLiteDB{db: db}
+	s.fts = s.enableFullTextSearch()
+	return s, nil
 }
 
 // CreateChatSession creates a new chat session
@@ -381,6 +384,11 @@ func (s *SQLiteDB) GetEvents(ctx context.Context, req *GetChatHistoryRequest) (*
 		args = append(args, req.ToDate)
 	}
 
+	if condition, arg := s.textQueryCondition(req.TextQuery); condition != "" {
+		whereClause += " AND " + condition
+		args = append(args, arg)
+	}
+
 	// Validate WHERE clause for safety
 	if err := validateWhereClause(whereClause); err != nil {
 		return nil, fmt.Errorf("invalid WHERE clause: %w", err)
@@ -489,6 +497,44 @@ func (s *SQLiteDB) GetEventsBySession(ctx context.Context, sessionID string, lim
 	return events, nil
 }
 
+// PruneEvents deletes events older than olderThan, except those of active sessions
+func (s *SQLiteDB) PruneEvents(ctx context.Context, olderThan time.Time) (int64, error) {
+	// julianday compares the stored timestamps as times, whatever their offset
+	query := `
+		DELETE FROM events
+		WHERE julianday(timestamp) < julianday(?)
+		  AND session_id NOT IN (SELECT session_id FROM chat_sessions WHERE status = 'active')
+	`
+
+	result, err := s.db.ExecContext(ctx, query, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune events: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// PruneSessions deletes inactive sessions whose last event, completion and creation are all older than olderThan
+func (s *SQLiteDB) PruneSessions(ctx context.Context, olderThan time.Time) (int64, error) {
+	query := `
+		SELECT cs.session_id
+		FROM chat_sessions cs
+		LEFT JOIN events e ON e.chat_session_id = cs.id
+		WHERE COALESCE(cs.status, '') != 'active'
+		GROUP BY cs.id, cs.session_id, cs.created_at, cs.completed_at
+		HAVING MAX(
+			COALESCE(julianday(cs.created_at), 0),
+			COALESCE(julianday(cs.completed_at), 0),
+			COALESCE(MAX(julianday(e.timestamp)), 0)
+		) < julianday(?)
+	`
+	return pruneSessionsWith(ctx, s.db, query, olderThan, false)
+}
+
+// ExportSession renders the transcript of a session in the given format
+func (s *SQLiteDB) ExportSession(ctx context.Context, sessionID, format string) ([]byte, error) {
+	return exportSession(ctx, s, sessionID, format)
+}
+
 // Ping tests the database connection
 func (s *SQLiteDB) Ping(ctx context.Context) error {
 	return s.db.PingContext(ctx)
@@ -984,6 +1030,86 @@ func (s *SQLiteDB) DeleteWorkflow(ctx context.Context, presetQueryID string) err
 	return nil
 }
 
+// SaveConversationSnapshot stores (or replaces) the conversation history of a session
+func (s *SQLiteDB) SaveConversationSnapshot(ctx context.Context, sessionID string, history string) error {
+	query := `
+		INSERT INTO conversation_snapshots (session_id, history, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET history = excluded.history, updated_at = excluded.updated_at
+	`
+
+	_, err := s.db.ExecContext(ctx, query, sessionID, history, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save conversation snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetConversationSnapshot returns the stored conversation history of a session, or "" if there is none
+func (s *SQLiteDB) GetConversationSnapshot(ctx context.Context, sessionID string) (string, error) {
+	query := `SELECT history FROM conversation_snapshots WHERE session_id = ?`
+
+	var history string
+	err := s.db.QueryRowContext(ctx, query, sessionID).Scan(&history)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get conversation snapshot: %w", err)
+	}
+
+	return history, nil
+}
+
+// DeleteConversationSnapshot removes the stored conversation history of a session
+func (s *SQLiteDB) DeleteConversationSnapshot(ctx context.Context, sessionID string) error {
+	query := `DELETE FROM conversation_snapshots WHERE session_id = ?`
+
+	if _, err := s.db.ExecContext(ctx, query, sessionID); err != nil {
+		return fmt.Errorf("failed to delete conversation snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// SaveSessionEnabledTools stores (or replaces) the tools enabled for a session
+func (s *SQLiteDB) SaveSessionEnabledTools(ctx context.Context, sessionID string, enabledTools []string) error {
+	encoded, err := marshalStringList(enabledTools)
+	if err != nil {
+		return fmt.Errorf("failed to marshal enabled tools: %w", err)
+	}
+
+	query := `
+		INSERT INTO session_enabled_tools (session_id, enabled_tools, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET enabled_tools = excluded.enabled_tools, updated_at = excluded.updated_at
+	`
+
+	_, err = s.db.ExecContext(ctx, query, sessionID, encoded, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save session enabled tools: %w", err)
+	}
+
+	return nil
+}
+
+// GetSessionEnabledTools returns the tools enabled for a session, or nil if none were stored
+func (s *SQLiteDB) GetSessionEnabledTools(ctx context.Context, sessionID string) ([]string, error) {
+	query := `SELECT enabled_tools FROM session_enabled_tools WHERE session_id = ?`
+
+	var encoded string
+	err := s.db.QueryRowContext(ctx, query, sessionID).Scan(&encoded)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get session enabled tools: %w", err)
+	}
+
+	return unmarshalEnabledTools(encoded)
+}
+
 // Close closes the database connection
 func (s *SQLiteDB) Close() error {
 	return s.db.Close()
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// CostSummary accumulates the token usage of a conversation per model, for its
+// ConversationCostSummaryEvent. It is safe for concurrent use.
+type CostSummary struct {
+	mu        sync.Mutex
+	models    map[string]*ModelCostSummary
+	fallbacks int
+}
+
+// NewCostSummary creates an empty cost summary
+func NewCostSummary() *CostSummary {
+	return &CostSummary{models: make(map[string]*ModelCostSummary)}
+}
+
+// Add records the usage of one generation by modelID
+func (s *CostSummary) Add(modelID string, usage UsageMetrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	model, exists := s.models[modelID]
+	if !exists {
+		model = &ModelCostSummary{ModelID: modelID}
+		s.models[modelID] = model
+	}
+	model.Generations++
+	model.PromptTokens += usage.PromptTokens
+	model.CompletionTokens += usage.CompletionTokens
+	model.TotalTokens += usage.TotalTokens
+}
+
+// Merge adds a finished conversation's summary, e.g. of one agent within an orchestrator run
+func (s *CostSummary) Merge(summary *ConversationCostSummaryEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, usage := range summary.Models {
+		model, exists := s.models[usage.ModelID]
+		if !exists {
+			model = &ModelCostSummary{ModelID: usage.ModelID}
+			s.models[usage.ModelID] = model
+		}
+		model.Generations += usage.Generations
+		model.PromptTokens += usage.PromptTokens
+		model.CompletionTokens += usage.CompletionTokens
+		model.TotalTokens += usage.TotalTokens
+	}
+	s.fallbacks += summary.FallbackSwitches
+}
+
+// AddFallback records a switch to a fallback model
+func (s *CostSummary) AddFallback() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallbacks++
+}
+
+// Reset clears the summary for the next conversation
+func (s *CostSummary) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models = make(map[string]*ModelCostSummary)
+	s.fallbacks = 0
+}
+
+// Event builds the summary event, pricing each model's usage with estimate (nil prices nothing).
+// Models are listed by ID.
+func (s *CostSummary) Event(scope string, estimate func(modelID string, usage UsageMetrics) float64) *ConversationCostSummaryEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event := &ConversationCostSummaryEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Scope:            scope,
+		Models:           make([]ModelCostSummary, 0, len(s.models)),
+		FallbackSwitches: s.fallbacks,
+	}
+	for modelID, model := range s.models {
+		summary := *model
+		if estimate != nil {
+			summary.EstimatedCostUSD = estimate(modelID, UsageMetrics{
+				PromptTokens:     model.PromptTokens,
+				CompletionTokens: model.CompletionTokens,
+				TotalTokens:      model.TotalTokens,
+			})
+		}
+		event.Models = append(event.Models, summary)
+		event.PromptTokens += summary.PromptTokens
+		event.CompletionTokens += summary.CompletionTokens
+		event.TotalTokens += summary.TotalTokens
+		event.EstimatedCostUSD += summary.EstimatedCostUSD
+	}
+	sort.Slice(event.Models, func(i, j int) bool {
+		return event.Models[i].ModelID < event.Models[j].ModelID
+	})
+	return event
+}
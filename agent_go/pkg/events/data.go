@@ -231,6 +231,9 @@ type LLMGenerationEndEvent struct {
 	ToolCalls    int           `json:"tool_calls"`
 	Duration     time.Duration `json:"duration"`
 	UsageMetrics UsageMetrics  `json:"usage_metrics"`
+	// TurnSummary marks the end of a conversation turn, which repeats the usage of the turn's
+	// LLM call; token and cost meters skip it to count every call once
+	TurnSummary bool `json:"turn_summary,omitempty"`
 }
 
 func (e *LLMGenerationEndEvent) GetEventType() EventType {
@@ -375,8 +378,19 @@ type ToolCallErrorEvent struct {
 	Error      string        `json:"error"`
 	ServerName string        `json:"server_name"`
 	Duration   time.Duration `json:"duration"`
+	Reason     string        `json:"reason,omitempty"`  // ToolTimeoutReason, ToolBlockedByPolicyReason or ToolDeclinedByUserReason
+	Timeout    time.Duration `json:"timeout,omitempty"` // Timeout the tool exceeded
 }
 
+// ToolTimeoutReason is the ToolCallErrorEvent reason of a tool that exceeded its timeout
+const ToolTimeoutReason = "tool timeout"
+
+// ToolBlockedByPolicyReason is the ToolCallErrorEvent reason of a tool call the tool policy blocked
+const ToolBlockedByPolicyReason = "blocked_by_policy"
+
+// ToolDeclinedByUserReason is the ToolCallErrorEvent reason of a gated tool call the user did not approve
+const ToolDeclinedByUserReason = "declined_by_user"
+
 func (e *ToolCallErrorEvent) GetEventType() EventType {
 	return ToolCallError
 }
@@ -405,6 +419,22 @@ func (e *TokenUsageEvent) GetEventType() EventType {
 	return TokenUsageEventType
 }
 
+// TurnLatencyEvent breaks a conversation turn's duration down into LLM generation,
+// tool execution and remaining overhead
+type TurnLatencyEvent struct {
+	BaseEventData
+	Turn       int   `json:"turn"`
+	TotalMs    int64 `json:"total_ms"`
+	LLMMs      int64 `json:"llm_ms"`
+	ToolsMs    int64 `json:"tools_ms"`
+	OverheadMs int64 `json:"overhead_ms"`
+	ToolCalls  int   `json:"tool_calls"`
+}
+
+func (e *TurnLatencyEvent) GetEventType() EventType {
+	return TurnLatency
+}
+
 // ErrorDetailEvent represents detailed error information
 type ErrorDetailEvent struct {
 	BaseEventData
@@ -901,6 +931,21 @@ func NewMCPServerSelectionEvent(turn int, selectedServers []string, totalServers
 	}
 }
 
+// NewTurnLatencyEvent creates a new TurnLatencyEvent
+func NewTurnLatencyEvent(turn int, total, llm, tools, overhead time.Duration, toolCalls int) *TurnLatencyEvent {
+	return &TurnLatencyEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:       turn,
+		TotalMs:    total.Milliseconds(),
+		LLMMs:      llm.Milliseconds(),
+		ToolsMs:    tools.Milliseconds(),
+		OverheadMs: overhead.Milliseconds(),
+		ToolCalls:  toolCalls,
+	}
+}
+
 // NewTokenUsageEvent creates a new TokenUsageEvent
 func NewTokenUsageEvent(turn int, operation, modelID, provider string, promptTokens, completionTokens, totalTokens int, duration time.Duration, context string) *TokenUsageEvent {
 	return &TokenUsageEvent{
@@ -1071,6 +1116,7 @@ type LargeToolOutputDetectedEvent struct {
 	Threshold       int    `json:"threshold"`
 	OutputFolder    string `json:"output_folder"`
 	ServerAvailable bool   `json:"server_available"`
+	Policy          string `json:"policy,omitempty"` // Large output policy that will handle the output
 }
 
 func (e *LargeToolOutputDetectedEvent) GetEventType() EventType {
@@ -1120,6 +1166,22 @@ func (e *LargeToolOutputServerUnavailableEvent) GetEventType() EventType {
 	return LargeToolOutputServerUnavailableEventType
 }
 
+// LargeToolOutputHandledEvent reports how a large tool output was handled by the large output policy
+type LargeToolOutputHandledEvent struct {
+	BaseEventData
+	ToolName   string `json:"tool_name"`
+	Policy     string `json:"policy"`              // "write_to_file", "summarize" or "truncate"
+	OutputSize int    `json:"output_size"`         // Size of the original output in bytes
+	ResultSize int    `json:"result_size"`         // Size of the result inserted into the conversation
+	FilePath   string `json:"file_path,omitempty"` // File holding the full output, if it was written
+	Summary    string `json:"summary,omitempty"`   // Summary inserted into the conversation (summarize policy)
+	Error      string `json:"error,omitempty"`     // Why the policy fell back, e.g. a failed summary
+}
+
+func (e *LargeToolOutputHandledEvent) GetEventType() EventType {
+	return LargeToolOutputHandled
+}
+
 // Constructor functions for large tool output events
 func NewLargeToolOutputDetectedEvent(toolName string, outputSize int, outputFolder string) *LargeToolOutputDetectedEvent {
 	return &LargeToolOutputDetectedEvent{
@@ -1577,6 +1639,8 @@ type SmartRoutingStartEvent struct {
 	LLMProvider    string  `json:"llm_provider,omitempty"`    // The LLM provider used for smart routing
 	LLMTemperature float64 `json:"llm_temperature,omitempty"` // Temperature used for smart routing
 	LLMMaxTokens   int     `json:"llm_max_tokens,omitempty"`  // Max tokens used for smart routing
+	// Routing decision cache (see mcpagent.WithRoutingCache)
+	CacheHit bool `json:"cache_hit"` // A cached decision will be reused, so no routing LLM call follows
 }
 
 func (e *SmartRoutingStartEvent) GetEventType() EventType {
@@ -1606,6 +1670,9 @@ type SmartRoutingEndEvent struct {
 	LLMProvider    string  `json:"llm_provider,omitempty"`    // The LLM provider used for smart routing
 	LLMTemperature float64 `json:"llm_temperature,omitempty"` // Temperature used for smart routing
 	LLMMaxTokens   int     `json:"llm_max_tokens,omitempty"`  // Max tokens used for smart routing
+	// Routing decision cache (see mcpagent.WithRoutingCache)
+	CacheHit   bool    `json:"cache_hit"`            // The decision was reused from the cache, without an LLM call
+	Confidence float64 `json:"confidence,omitempty"` // Routing LLM's confidence in the decision (0.0-1.0)
 }
 
 func (e *SmartRoutingEndEvent) GetEventType() EventType {
@@ -1646,6 +1713,309 @@ func NewSmartRoutingEndEvent(totalTools, filteredTools, totalServers int, releva
 	}
 }
 
+// AgentModeSelectedEvent represents the agent mode chosen for a query when agent_mode is "auto"
+type AgentModeSelectedEvent struct {
+	BaseEventData
+	RequestedMode string        `json:"requested_mode"`      // Always "auto"
+	SelectedMode  string        `json:"selected_mode"`       // "simple", "react" or "orchestrator"
+	QueryClass    string        `json:"query_class"`         // "chat", "tool-use" or "complex"
+	Reasoning     string        `json:"reasoning,omitempty"` // Short explanation from the classifier
+	Fallback      bool          `json:"fallback"`            // True if classification failed and the default mode was used
+	Error         string        `json:"error,omitempty"`
+	Duration      time.Duration `json:"duration"`
+}
+
+func (e *AgentModeSelectedEvent) GetEventType() EventType {
+	return AgentModeSelected
+}
+
+// NewAgentModeSelectedEvent creates a new agent mode selected event
+func NewAgentModeSelectedEvent(selectedMode, queryClass, reasoning string, fallback bool, errorMsg string, duration time.Duration) *AgentModeSelectedEvent {
+	return &AgentModeSelectedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		RequestedMode: "auto",
+		SelectedMode:  selectedMode,
+		QueryClass:    queryClass,
+		Reasoning:     reasoning,
+		Fallback:      fallback,
+		Error:         errorMsg,
+		Duration:      duration,
+	}
+}
+
+// MemoryPressureEvent reports the server entering or leaving memory pressure
+type MemoryPressureEvent struct {
+	BaseEventData
+	UnderPressure   bool   `json:"under_pressure"`   // True while new runs are rejected
+	UsageBytes      uint64 `json:"usage_bytes"`      // Heap in use when the check ran
+	LimitBytes      uint64 `json:"limit_bytes"`      // Configured maximum memory usage
+	EvictedSessions int    `json:"evicted_sessions"` // Idle sessions whose state was moved to the database
+}
+
+func (e *MemoryPressureEvent) GetEventType() EventType {
+	return MemoryPressure
+}
+
+// NewMemoryPressureEvent creates a new memory pressure event
+func NewMemoryPressureEvent(underPressure bool, usageBytes, limitBytes uint64, evictedSessions int) *MemoryPressureEvent {
+	return &MemoryPressureEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		UnderPressure:   underPressure,
+		UsageBytes:      usageBytes,
+		LimitBytes:      limitBytes,
+		EvictedSessions: evictedSessions,
+	}
+}
+
+// ToolTransactionEvent reports a tool transaction beginning, committing or rolling back
+type ToolTransactionEvent struct {
+	BaseEventData
+	TransactionID      string   `json:"transaction_id"`
+	Name               string   `json:"name,omitempty"`
+	Status             string   `json:"status"`                        // "begin", "commit" or "rollback"
+	Steps              []string `json:"steps,omitempty"`               // Tools called in the transaction, in order
+	Reason             string   `json:"reason,omitempty"`              // Why a rollback happened
+	Compensated        []string `json:"compensated,omitempty"`         // Tools whose compensation ran, in rollback order
+	CompensationErrors []string `json:"compensation_errors,omitempty"` // Compensations that failed
+}
+
+func (e *ToolTransactionEvent) GetEventType() EventType {
+	switch e.Status {
+	case "commit":
+		return ToolTransactionCommit
+	case "rollback":
+		return ToolTransactionRollback
+	default:
+		return ToolTransactionBegin
+	}
+}
+
+// NewToolTransactionEvent creates a new tool transaction event with status "begin", "commit" or "rollback"
+func NewToolTransactionEvent(status, transactionID, name string, steps []string) *ToolTransactionEvent {
+	return &ToolTransactionEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		TransactionID: transactionID,
+		Name:          name,
+		Status:        status,
+		Steps:         steps,
+	}
+}
+
+// ToolAlternateUsedEvent reports a registered alternate tool called in place of a repeatedly failing tool
+type ToolAlternateUsedEvent struct {
+	BaseEventData
+	Turn           int    `json:"turn"`
+	ToolName       string `json:"tool_name"`
+	AlternateTool  string `json:"alternate_tool"`
+	Failures       int    `json:"failures"` // Consecutive failures of ToolName
+	Error          string `json:"error"`    // ToolName's latest failure
+	Succeeded      bool   `json:"succeeded"`
+	AlternateError string `json:"alternate_error,omitempty"`
+}
+
+func (e *ToolAlternateUsedEvent) GetEventType() EventType {
+	return ToolAlternateUsed
+}
+
+// NewToolAlternateUsedEvent creates a new tool alternate used event
+func NewToolAlternateUsedEvent(turn int, toolName, alternateTool string, failures int, toolErr string) *ToolAlternateUsedEvent {
+	return &ToolAlternateUsedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:          turn,
+		ToolName:      toolName,
+		AlternateTool: alternateTool,
+		Failures:      failures,
+		Error:         toolErr,
+	}
+}
+
+// ToolCallDeduplicatedEvent reports a tool call answered with the result of an identical call
+// (same tool and arguments) made earlier in the same turn, without executing the tool again
+type ToolCallDeduplicatedEvent struct {
+	BaseEventData
+	Turn               int    `json:"turn"`
+	ToolName           string `json:"tool_name"`
+	ServerName         string `json:"server_name,omitempty"`
+	ToolCallID         string `json:"tool_call_id"`
+	OriginalToolCallID string `json:"original_tool_call_id"` // The call whose result was reused
+}
+
+func (e *ToolCallDeduplicatedEvent) GetEventType() EventType {
+	return ToolCallDeduplicated
+}
+
+// NewToolCallDeduplicatedEvent creates a new tool call deduplicated event
+func NewToolCallDeduplicatedEvent(turn int, toolName, serverName, toolCallID, originalToolCallID string) *ToolCallDeduplicatedEvent {
+	return &ToolCallDeduplicatedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:               turn,
+		ToolName:           toolName,
+		ServerName:         serverName,
+		ToolCallID:         toolCallID,
+		OriginalToolCallID: originalToolCallID,
+	}
+}
+
+// ToolPermissionDeniedEvent reports a tool call that failed for missing permissions and, when one is
+// registered, the read-only fallback called in its place
+type ToolPermissionDeniedEvent struct {
+	BaseEventData
+	Turn          int    `json:"turn"`
+	ToolName      string `json:"tool_name"`
+	Error         string `json:"error"`
+	FallbackTool  string `json:"fallback_tool,omitempty"` // Empty when no read-only fallback is registered
+	Succeeded     bool   `json:"succeeded"`
+	FallbackError string `json:"fallback_error,omitempty"`
+}
+
+func (e *ToolPermissionDeniedEvent) GetEventType() EventType {
+	return ToolPermissionDenied
+}
+
+// NewToolPermissionDeniedEvent creates a new tool permission denied event
+func NewToolPermissionDeniedEvent(turn int, toolName, toolErr string) *ToolPermissionDeniedEvent {
+	return &ToolPermissionDeniedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:     turn,
+		ToolName: toolName,
+		Error:    toolErr,
+	}
+}
+
+// StructuredOutputAttemptEvent reports one structured output attempt: the validation errors found in
+// the model's JSON and whether it is asked again to correct them
+type StructuredOutputAttemptEvent struct {
+	BaseEventData
+	Attempt          int      `json:"attempt"`
+	MaxAttempts      int      `json:"max_attempts"`
+	Valid            bool     `json:"valid"`
+	ValidationErrors []string `json:"validation_errors,omitempty"`
+	Output           string   `json:"output,omitempty"` // The attempt's output, truncated
+	WillRetry        bool     `json:"will_retry"`
+}
+
+func (e *StructuredOutputAttemptEvent) GetEventType() EventType {
+	return StructuredOutputAttempt
+}
+
+// NewStructuredOutputAttemptEvent creates a new structured output attempt event
+func NewStructuredOutputAttemptEvent(attempt, maxAttempts int, validationErrors []string, output string) *StructuredOutputAttemptEvent {
+	return &StructuredOutputAttemptEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Attempt:          attempt,
+		MaxAttempts:      maxAttempts,
+		Valid:            len(validationErrors) == 0,
+		ValidationErrors: validationErrors,
+		Output:           output,
+		WillRetry:        len(validationErrors) > 0 && attempt < maxAttempts,
+	}
+}
+
+// CredentialRefreshEvent reports an LLM call that failed on expired credentials and the
+// re-authentication attempted before retrying it
+type CredentialRefreshEvent struct {
+	BaseEventData
+	Turn         int    `json:"turn"`
+	ModelID      string `json:"model_id"`
+	Provider     string `json:"provider"`
+	Error        string `json:"error"` // The authentication error
+	Refreshed    bool   `json:"refreshed"`
+	RefreshError string `json:"refresh_error,omitempty"`
+}
+
+func (e *CredentialRefreshEvent) GetEventType() EventType {
+	return CredentialRefresh
+}
+
+// NewCredentialRefreshEvent creates a new credential refresh event
+func NewCredentialRefreshEvent(turn int, modelID, provider, authErr string) *CredentialRefreshEvent {
+	return &CredentialRefreshEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:     turn,
+		ModelID:  modelID,
+		Provider: provider,
+		Error:    authErr,
+	}
+}
+
+// HistoryCompactedEvent reports the oldest turns of a conversation dropped before an LLM call
+// because the estimated prompt exceeded the compaction threshold of the model's context window
+type HistoryCompactedEvent struct {
+	BaseEventData
+	Turn            int    `json:"turn"`
+	ModelID         string `json:"model_id"`
+	ContextWindow   int    `json:"context_window"`
+	TokenLimit      int    `json:"token_limit"` // Threshold fraction of the context window
+	TokensBefore    int    `json:"tokens_before"`
+	TokensAfter     int    `json:"tokens_after"`
+	MessagesDropped int    `json:"messages_dropped"`
+	MessagesKept    int    `json:"messages_kept"`
+}
+
+func (e *HistoryCompactedEvent) GetEventType() EventType {
+	return HistoryCompacted
+}
+
+// NewHistoryCompactedEvent creates a new history compacted event
+func NewHistoryCompactedEvent(turn int, modelID string, contextWindow, tokenLimit, tokensBefore, tokensAfter, messagesDropped, messagesKept int) *HistoryCompactedEvent {
+	return &HistoryCompactedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:            turn,
+		ModelID:         modelID,
+		ContextWindow:   contextWindow,
+		TokenLimit:      tokenLimit,
+		TokensBefore:    tokensBefore,
+		TokensAfter:     tokensAfter,
+		MessagesDropped: messagesDropped,
+		MessagesKept:    messagesKept,
+	}
+}
+
+// ModelCostSummary is the token usage and estimated cost of one model within a conversation
+type ModelCostSummary struct {
+	ModelID          string  `json:"model_id"`
+	Generations      int     `json:"generations"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+}
+
+// ConversationCostSummaryEvent rolls up the token usage of a conversation ("conversation" scope) or
+// an orchestrator run ("orchestrator" scope) when it ends
+type ConversationCostSummaryEvent struct {
+	BaseEventData
+	Scope            string             `json:"scope"`
+	Models           []ModelCostSummary `json:"models"`
+	PromptTokens     int                `json:"prompt_tokens"`
+	CompletionTokens int                `json:"completion_tokens"`
+	TotalTokens      int                `json:"total_tokens"`
+	FallbackSwitches int                `json:"fallback_switches"`
+	EstimatedCostUSD float64            `json:"estimated_cost_usd"`
+}
+
+func (e *ConversationCostSummaryEvent) GetEventType() EventType {
+	return ConversationCostSummary
+}
+
 // UnifiedCompletionEvent represents a standardized completion event for all agent types
 type UnifiedCompletionEvent struct {
 	BaseEventData
@@ -1657,6 +2027,7 @@ type UnifiedCompletionEvent struct {
 	Duration    time.Duration          `json:"duration"`           // Total execution time
 	Turns       int                    `json:"turns"`              // Number of conversation turns
 	Error       string                 `json:"error,omitempty"`    // Error message if status is error
+	Summary     string                 `json:"summary,omitempty"`  // Recap of the run, when run summaries are enabled
 	Metadata    map[string]interface{} `json:"metadata,omitempty"` // Additional context
 }
 
@@ -1884,3 +2255,76 @@ type TodoStepsExtractedEvent struct {
 func (e *TodoStepsExtractedEvent) GetEventType() EventType {
 	return TodoStepsExtracted
 }
+
+// HeldTodoStep is a plan step not executed because it awaits revision
+type HeldTodoStep struct {
+	StepNumber int    `json:"step_number"`
+	Title      string `json:"title"`
+	Reason     string `json:"reason"`
+}
+
+// TodoStepsHeldForRevisionEvent reports the steps a partially approved workflow did not execute
+type TodoStepsHeldForRevisionEvent struct {
+	BaseEventData
+	ExecutedSteps []int          `json:"executed_steps"`
+	HeldSteps     []HeldTodoStep `json:"held_steps"`
+}
+
+func (e *TodoStepsHeldForRevisionEvent) GetEventType() EventType {
+	return TodoStepsHeldForRevision
+}
+
+// ConsensusModelOutputEvent reports one model's output for a step decided by multi-model consensus
+type ConsensusModelOutputEvent struct {
+	BaseEventData
+	Step   string `json:"step"`
+	Model  string `json:"model"` // provider/model_id
+	Output string `json:"output,omitempty"`
+	Answer string `json:"answer,omitempty"` // Comparable answer extracted from the output
+	Error  string `json:"error,omitempty"`  // Set when the model failed or its output had no usable answer
+}
+
+func (e *ConsensusModelOutputEvent) GetEventType() EventType {
+	return ConsensusModelOutput
+}
+
+// NewConsensusModelOutputEvent creates a new consensus model output event
+func NewConsensusModelOutputEvent(step, model, output, answer string) *ConsensusModelOutputEvent {
+	return &ConsensusModelOutputEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Step:   step,
+		Model:  model,
+		Output: output,
+		Answer: answer,
+	}
+}
+
+// ConsensusResolvedEvent reports how a consensus step's result was selected
+type ConsensusResolvedEvent struct {
+	BaseEventData
+	Step          string `json:"step"`
+	Method        string `json:"method"` // agreement, judge or no_consensus
+	SelectedModel string `json:"selected_model,omitempty"`
+	Answer        string `json:"answer,omitempty"`
+	Models        int    `json:"models"`
+	Votes         int    `json:"votes"` // Models that gave the selected answer
+}
+
+func (e *ConsensusResolvedEvent) GetEventType() EventType {
+	return ConsensusResolved
+}
+
+// NewConsensusResolvedEvent creates a new consensus resolved event
+func NewConsensusResolvedEvent(step, method string, models, votes int) *ConsensusResolvedEvent {
+	return &ConsensusResolvedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Step:   step,
+		Method: method,
+		Models: models,
+		Votes:  votes,
+	}
+}
@@ -0,0 +1,139 @@
+package events
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrorCategory is a known class of error that users can act on
+type ErrorCategory string
+
+const (
+	ErrorCategoryAuth          ErrorCategory = "auth"
+	ErrorCategoryThrottle      ErrorCategory = "throttle"
+	ErrorCategoryContextLength ErrorCategory = "context_length"
+	ErrorCategoryBadModel      ErrorCategory = "bad_model"
+)
+
+// Metadata keys set on error events by EnrichWithRemediation
+const (
+	ErrorCategoryMetadataKey   = "error_category"
+	RemediationHintMetadataKey = "remediation_hint"
+)
+
+// DefaultRemediationHints maps each error category to an actionable hint
+var DefaultRemediationHints = map[ErrorCategory]string{
+	ErrorCategoryAuth:          "Check that the provider API key or credentials are set and still valid, then retry.",
+	ErrorCategoryThrottle:      "The provider is rate limiting requests. Wait a moment and retry, lower request concurrency, or configure fallback models on another provider.",
+	ErrorCategoryContextLength: "The conversation is too long for the model. Start a new conversation, reduce the tools or files in context, or pick a model with a larger context window.",
+	ErrorCategoryBadModel:      "The model ID is not available for this provider or account. Check the model name and region, or select another model.",
+}
+
+// errorCategoryPatterns are lowercase message fragments per category, checked in order
+var errorCategoryPatterns = []struct {
+	category ErrorCategory
+	patterns []string
+}{
+	{ErrorCategoryThrottle, []string{"throttl", "rate limit", "rate_limit", "ratelimit", "too many requests", "429", "quota exceeded", "resource_exhausted"}},
+	{ErrorCategoryAuth, []string{"unauthorized", "401", "403", "invalid api key", "incorrect api key", "invalid_api_key", "authentication", "expired token", "expiredtoken", "access denied", "accessdenied", "permission denied"}},
+	{ErrorCategoryContextLength, []string{"context length", "context_length", "context window", "maximum context", "max_token", "max tokens", "input is too long", "too many tokens", "prompt is too long"}},
+	{ErrorCategoryBadModel, []string{"model not found", "model_not_found", "model does not exist", "unknown model", "invalid model", "unsupported model", "no such model", "model identifier is invalid"}},
+}
+
+// remediationHints holds the enrichment settings
+var remediationHints = struct {
+	mu      sync.RWMutex
+	enabled bool
+	hints   map[ErrorCategory]string
+}{
+	enabled: os.Getenv("ERROR_REMEDIATION_HINTS") != "false",
+	hints:   DefaultRemediationHints,
+}
+
+// ConfigureRemediationHints enables or disables error event enrichment. overrides replace
+// the default hint of their categories; a category mapped to "" gets no hint.
+func ConfigureRemediationHints(enabled bool, overrides map[ErrorCategory]string) {
+	hints := make(map[ErrorCategory]string, len(DefaultRemediationHints)+len(overrides))
+	for category, hint := range DefaultRemediationHints {
+		hints[category] = hint
+	}
+	for category, hint := range overrides {
+		hints[category] = hint
+	}
+
+	remediationHints.mu.Lock()
+	defer remediationHints.mu.Unlock()
+	remediationHints.enabled = enabled
+	remediationHints.hints = hints
+}
+
+// ClassifyError returns the category of an error message, or "" when it is not a known category
+func ClassifyError(message string) ErrorCategory {
+	message = strings.ToLower(message)
+	for _, entry := range errorCategoryPatterns {
+		for _, pattern := range entry.patterns {
+			if strings.Contains(message, pattern) {
+				return entry.category
+			}
+		}
+	}
+	return ""
+}
+
+// RemediationHint returns the hint for a category, or "" when there is none
+func RemediationHint(category ErrorCategory) string {
+	remediationHints.mu.RLock()
+	defer remediationHints.mu.RUnlock()
+	return remediationHints.hints[category]
+}
+
+// EnrichWithRemediation attaches the error category and remediation hint to an error event's
+// metadata. It returns false for events without a recognized error or when enrichment is disabled.
+func EnrichWithRemediation(data EventData) bool {
+	remediationHints.mu.RLock()
+	enabled := remediationHints.enabled
+	remediationHints.mu.RUnlock()
+	if !enabled {
+		return false
+	}
+
+	var base *BaseEventData
+	var category ErrorCategory
+	switch e := data.(type) {
+	case *AgentErrorEvent:
+		base, category = &e.BaseEventData, ClassifyError(e.Error)
+	case *ConversationErrorEvent:
+		base, category = &e.BaseEventData, ClassifyError(e.Error)
+	case *LLMGenerationErrorEvent:
+		base, category = &e.BaseEventData, ClassifyError(e.Error)
+	case *ToolCallErrorEvent:
+		base, category = &e.BaseEventData, ClassifyError(e.Error)
+	case *OrchestratorErrorEvent:
+		base, category = &e.BaseEventData, ClassifyError(e.Error)
+	case *FallbackAttemptEvent:
+		if !e.Success {
+			base, category = &e.BaseEventData, ClassifyError(e.Error)
+		}
+	case *ThrottlingDetectedEvent:
+		// Throttling events carry the error class rather than the provider message
+		base, category = &e.BaseEventData, ErrorCategoryThrottle
+		if e.ErrorType != "" && e.ErrorType != "throttling" && e.ErrorType != "throttling_error" {
+			category = ClassifyError(e.ErrorType)
+		}
+	}
+	if base == nil || category == "" {
+		return false
+	}
+	hint := RemediationHint(category)
+	if hint == "" {
+		return false
+	}
+
+	if base.Metadata == nil {
+		base.Metadata = make(map[string]interface{})
+	}
+	base.Metadata[ErrorCategoryMetadataKey] = string(category)
+	base.Metadata[RemediationHintMetadataKey] = hint
+	return true
+}
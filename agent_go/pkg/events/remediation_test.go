@@ -0,0 +1,59 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottlingEventCarriesRateLimitHint(t *testing.T) {
+	event := NewThrottlingDetectedEvent(1, "gpt-4o", "openai", 1, 3, time.Second, "throttling", 0)
+
+	if !EnrichWithRemediation(event) {
+		t.Fatal("expected the throttling event to be enriched")
+	}
+	if event.Metadata[ErrorCategoryMetadataKey] != string(ErrorCategoryThrottle) {
+		t.Fatalf("expected category %q, got %v", ErrorCategoryThrottle, event.Metadata[ErrorCategoryMetadataKey])
+	}
+	if event.Metadata[RemediationHintMetadataKey] != DefaultRemediationHints[ErrorCategoryThrottle] {
+		t.Fatalf("expected the rate-limit hint, got %v", event.Metadata[RemediationHintMetadataKey])
+	}
+}
+
+func TestErrorEventsClassifiedFromProviderMessage(t *testing.T) {
+	cases := map[string]ErrorCategory{
+		"status code 429: Rate limit reached for gpt-4o":                                    ErrorCategoryThrottle,
+		"ThrottlingException: Too many requests, please wait":                               ErrorCategoryThrottle,
+		"error, status code: 401, message: Incorrect API key provided":                      ErrorCategoryAuth,
+		"This model's maximum context length is 128000 tokens":                              ErrorCategoryContextLength,
+		"model_not_found: The model `gpt-9` does not exist or you do not have access to it": ErrorCategoryBadModel,
+		"open /workspace/report.md: file does not exist":                                    "",
+	}
+	for message, want := range cases {
+		event := &LLMGenerationErrorEvent{Error: message}
+		enriched := EnrichWithRemediation(event)
+		if want == "" {
+			if enriched || event.Metadata != nil {
+				t.Fatalf("expected %q to stay unenriched, got %v", message, event.Metadata)
+			}
+			continue
+		}
+		if !enriched || event.Metadata[ErrorCategoryMetadataKey] != string(want) {
+			t.Fatalf("expected %q classified as %q, got %v", message, want, event.Metadata)
+		}
+	}
+}
+
+func TestRemediationHintsConfigurable(t *testing.T) {
+	t.Cleanup(func() { ConfigureRemediationHints(true, nil) })
+
+	ConfigureRemediationHints(true, map[ErrorCategory]string{ErrorCategoryThrottle: "Ask the admin to raise the team quota."})
+	event := &OrchestratorErrorEvent{Error: "rate limit exceeded"}
+	if !EnrichWithRemediation(event) || event.Metadata[RemediationHintMetadataKey] != "Ask the admin to raise the team quota." {
+		t.Fatalf("expected the overridden hint, got %v", event.Metadata)
+	}
+
+	ConfigureRemediationHints(false, nil)
+	if EnrichWithRemediation(&OrchestratorErrorEvent{Error: "rate limit exceeded"}) {
+		t.Fatal("expected no enrichment when disabled")
+	}
+}
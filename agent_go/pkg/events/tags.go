@@ -0,0 +1,53 @@
+package events
+
+import "context"
+
+// eventTagsKey is the context key of the tags attached to every event emitted under a context
+type eventTagsKey struct{}
+
+// WithEventTags returns a context whose emitted events carry tags in their metadata, e.g. the
+// experiment variant serving a request. Tags add to those already on ctx; empty tags return ctx.
+func WithEventTags(ctx context.Context, tags map[string]string) context.Context {
+	if len(tags) == 0 {
+		return ctx
+	}
+	merged := make(map[string]string, len(tags))
+	for key, value := range EventTags(ctx) {
+		merged[key] = value
+	}
+	for key, value := range tags {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, eventTagsKey{}, merged)
+}
+
+// EventTags returns the event tags attached to ctx
+func EventTags(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	tags, _ := ctx.Value(eventTagsKey{}).(map[string]string)
+	return tags
+}
+
+// ApplyEventTags copies the tags attached to ctx into the event's metadata
+func ApplyEventTags(ctx context.Context, data EventData) {
+	tags := EventTags(ctx)
+	if len(tags) == 0 {
+		return
+	}
+	base, ok := data.(interface{ GetBaseEventData() *BaseEventData })
+	if !ok {
+		return
+	}
+	baseData := base.GetBaseEventData()
+	if baseData == nil {
+		return
+	}
+	if baseData.Metadata == nil {
+		baseData.Metadata = make(map[string]interface{}, len(tags))
+	}
+	for key, value := range tags {
+		baseData.Metadata[key] = value
+	}
+}
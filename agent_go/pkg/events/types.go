@@ -60,6 +60,7 @@ const (
 	Performance EventType = "performance"
 	TokenUsage  EventType = "token_usage"
 	ErrorDetail EventType = "error_detail"
+	TurnLatency EventType = "turn_latency"
 
 	// Event type aliases for backward compatibility
 	TokenUsageEventType  EventType = "token_usage"
@@ -74,6 +75,8 @@ const (
 	LargeToolOutputFileWrittenEventType       EventType = "large_tool_output_file_written"
 	LargeToolOutputFileWriteErrorEventType    EventType = "large_tool_output_file_write_error"
 	LargeToolOutputServerUnavailableEventType EventType = "large_tool_output_server_unavailable"
+	// Large output policy applied (see mcpagent.WithLargeOutputPolicy)
+	LargeToolOutputHandled EventType = "large_tool_output_handled"
 
 	// Fallback events
 	FallbackModelUsed  EventType = "fallback_model_used"
@@ -129,8 +132,10 @@ const (
 	StructuredOutputStart EventType = "structured_output_start"
 	StructuredOutputEnd   EventType = "structured_output_end"
 	StructuredOutputError EventType = "structured_output_error"
-	JSONValidationStart   EventType = "json_validation_start"
-	JSONValidationEnd     EventType = "json_validation_end"
+	// One structured output attempt and the validation errors that made it re-ask (if any)
+	StructuredOutputAttempt EventType = "structured_output_attempt"
+	JSONValidationStart     EventType = "json_validation_start"
+	JSONValidationEnd       EventType = "json_validation_end"
 
 	// Tool execution events
 	ToolExecution          EventType = "tool_execution"
@@ -152,6 +157,35 @@ const (
 	SmartRoutingStartEventType EventType = "smart_routing_start"
 	SmartRoutingEndEventType   EventType = "smart_routing_end"
 
+	// Automatic agent mode selection (agent_mode="auto")
+	AgentModeSelected EventType = "agent_mode_selected"
+
+	// Server memory pressure (idle session state shed, new runs rejected)
+	MemoryPressure EventType = "memory_pressure"
+
+	// Multi-step tool transactions (see mcpagent.WithToolTransactions)
+	ToolTransactionBegin    EventType = "tool_transaction_begin"
+	ToolTransactionCommit   EventType = "tool_transaction_commit"
+	ToolTransactionRollback EventType = "tool_transaction_rollback"
+
+	// Alternate tool called after a tool failed repeatedly (see mcpagent.WithToolAlternate)
+	ToolAlternateUsed EventType = "tool_alternate_used"
+
+	// Identical tool call in the same turn answered from the earlier result (see mcpagent.WithToolDeduplication)
+	ToolCallDeduplicated EventType = "tool_call_deduplicated"
+
+	// Tool call denied for missing permissions (see mcpagent.WithToolReadOnlyFallback)
+	ToolPermissionDenied EventType = "tool_permission_denied"
+
+	// Expired LLM credentials refreshed through the secret provider (see mcpagent.WithSecretProvider)
+	CredentialRefresh EventType = "credential_refresh"
+
+	// Oldest turns dropped before an LLM call to fit the context window (see mcpagent.WithHistoryCompaction)
+	HistoryCompacted EventType = "history_compacted"
+
+	// Token and cost roll-up emitted when a conversation or orchestrator run ends
+	ConversationCostSummary EventType = "conversation_cost_summary"
+
 	// Unified completion event
 	EventTypeUnifiedCompletion EventType = "unified_completion"
 )
@@ -170,9 +204,17 @@ const (
 
 	// Parallel execution events
 	IndependentStepsSelected EventType = "independent_steps_selected"
+	// Dependency graph produced by the plan breakdown agent
+	PlanDependencyAnalysis EventType = "plan_dependency_analysis"
 
 	// Todo planning events
 	TodoStepsExtracted EventType = "todo_steps_extracted"
+	// Partial approval: steps not executed because they await revision
+	TodoStepsHeldForRevision EventType = "todo_steps_held_for_revision"
+
+	// Multi-model consensus on designated steps (see orchestrator.ConsensusConfig)
+	ConsensusModelOutput EventType = "consensus_model_output"
+	ConsensusResolved    EventType = "consensus_resolved"
 
 	// Human Verification events
 	HumanVerificationResponse EventType = "human_verification_response"
@@ -237,9 +279,10 @@ func GetComponentFromEventType(eventType EventType) string {
 	switch {
 	case eventType == OrchestratorStart || eventType == OrchestratorEnd || eventType == OrchestratorError ||
 		eventType == OrchestratorAgentStart || eventType == OrchestratorAgentEnd || eventType == OrchestratorAgentError ||
-		eventType == StructuredOutputStart || eventType == StructuredOutputEnd || eventType == StructuredOutputError ||
+		eventType == StructuredOutputStart || eventType == StructuredOutputEnd || eventType == StructuredOutputError || eventType == StructuredOutputAttempt ||
 		eventType == JSONValidationStart || eventType == JSONValidationEnd ||
-		eventType == IndependentStepsSelected || eventType == TodoStepsExtracted:
+		eventType == IndependentStepsSelected || eventType == PlanDependencyAnalysis || eventType == TodoStepsExtracted || eventType == TodoStepsHeldForRevision ||
+		eventType == ConsensusModelOutput || eventType == ConsensusResolved:
 		return "orchestrator"
 	case eventType == AgentStart || eventType == AgentEnd || eventType == AgentError ||
 		eventType == ReActReasoningStart || eventType == ReActReasoningStep ||
@@ -283,3 +326,19 @@ func IsEndEvent(eventType EventType) bool {
 		eventType == OrchestratorEnd ||
 		eventType == OrchestratorAgentEnd
 }
+
+// IsLifecycleEvent reports whether an event marks a run's progress (start, end, error, completion)
+// or asks the user for input. Consumers need these even when other events are throttled or dropped.
+func IsLifecycleEvent(eventType EventType) bool {
+	return IsStartEvent(eventType) || IsEndEvent(eventType) ||
+		eventType == ConversationError ||
+		eventType == LLMGenerationError ||
+		eventType == ToolCallError ||
+		eventType == AgentError ||
+		eventType == OrchestratorError ||
+		eventType == OrchestratorAgentError ||
+		eventType == EventTypeUnifiedCompletion ||
+		eventType == RequestHumanFeedback ||
+		eventType == BlockingHumanFeedback ||
+		eventType == HumanVerificationResponse
+}
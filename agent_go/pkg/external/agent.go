@@ -90,6 +90,37 @@ type AgentCore interface {
 	//   - Updated message history that can be used for subsequent calls
 	//   - Any error that occurred during processing
 	InvokeWithHistory(ctx context.Context, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error)
+
+	// InvokeStream sends a prompt to the agent and streams the response text as it is generated.
+	//
+	// Tokens are batched into chunks of at least the configured stream chunk size
+	// (see AgentBuilder.WithStreamChunkSize). The channel closes when the run completes.
+	// If the run fails, the last chunk carries the error: check each chunk with StreamError.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation, timeouts, and tracing
+	//   - prompt: The user's question or instruction
+	//
+	// Returns:
+	//   - A channel of response chunks, closed on completion
+	//   - An error if the request could not be started
+	InvokeStream(ctx context.Context, prompt string) (<-chan string, error)
+
+	// ExportHistory returns a copy of the agent's conversation history.
+	//
+	// Invoke, InvokeStream and AskStructured continue this history; InvokeWithHistory
+	// replaces it with the conversation it returns. Persist it with MarshalHistory to
+	// resume the conversation in another process.
+	ExportHistory() []llmtypes.MessageContent
+
+	// ImportHistory replaces the agent's conversation history.
+	//
+	// The history is validated with ValidateHistory (alternating user/assistant turns,
+	// non-empty parts) and left unchanged if invalid.
+	ImportHistory(messages []llmtypes.MessageContent) error
+
+	// ClearHistory discards the conversation history so the next prompt starts a new conversation.
+	ClearHistory()
 }
 
 // AgentConfig provides configuration management and customization capabilities.
@@ -447,6 +478,10 @@ type agentImpl struct {
 	// 🆕 NEW: Trace management for proper cleanup
 	traceID observability.TraceID // Store the trace ID for cleanup
 	tracer  observability.Tracer  // Store the tracer for cleanup
+
+	// Conversation continued by Invoke, InvokeStream and AskStructured
+	history   []llmtypes.MessageContent
+	historyMu sync.Mutex
 }
 
 // NewAgent creates a new agent with the given configuration.
@@ -482,6 +517,16 @@ func NewAgent(ctx context.Context, config Config) (Agent, error) {
 		return nil, fmt.Errorf("invalid system prompt configuration: %w", err)
 	}
 
+	if err := ValidateHistory(config.InitialHistory); err != nil {
+		return nil, fmt.Errorf("invalid initial history: %w", err)
+	}
+
+	if config.ToolPolicy != nil {
+		if err := config.ToolPolicy.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize tracer based on configuration
 	var tracer observability.Tracer
 	if config.Tracer != nil {
@@ -536,6 +581,17 @@ func NewAgent(ctx context.Context, config Config) (Agent, error) {
 			agentLogger.Infof("✅ Langfuse tracer created successfully for host: %s", config.LangfuseHost)
 		}
 	}
+	if config.Tracer == nil && config.TraceProvider == observability.ProviderOTel {
+		// For OpenTelemetry the host is the OTLP/HTTP endpoint; empty uses OTEL_EXPORTER_OTLP_ENDPOINT
+		otelTracer, err := observability.NewOTelTracer(config.LangfuseHost)
+		if err != nil {
+			agentLogger.Warnf("Failed to create OpenTelemetry tracer: %v, falling back to noop tracer", err)
+			tracer = observability.NoopTracer{}
+		} else {
+			tracer = otelTracer
+			agentLogger.Infof("✅ OpenTelemetry tracer created for OTLP endpoint: %s", config.LangfuseHost)
+		}
+	}
 
 	// Generate a correlation ID - let internal agent handle trace creation
 	var traceID observability.TraceID
@@ -558,10 +614,43 @@ func NewAgent(ctx context.Context, config Config) (Agent, error) {
 		mcpagent.WithToolChoice(config.ToolChoice),
 		mcpagent.WithMaxTurns(config.MaxTurns),
 		mcpagent.WithToolTimeout(config.ToolTimeout),
+		mcpagent.WithToolTimeouts(config.ToolTimeouts),
 		// Enable smart routing for external agent (used by main streaming server)
 		// This helps reduce tool overload and improve LLM performance
 		mcpagent.WithSmartRouting(true),
 		mcpagent.WithSmartRoutingThresholds(20, 4), // 20 tools, 4 servers threshold
+		mcpagent.WithStructuredOutputRawFallback(config.StructuredOutputRawFallback),
+		mcpagent.WithStructuredOutputResume(config.StructuredOutputMaxResumes),
+		mcpagent.WithStructuredOutputMaxAttempts(config.StructuredOutputMaxAttempts),
+		mcpagent.WithToolTransactions(config.ToolTransactions),
+		mcpagent.WithToolAlternateThreshold(config.ToolAlternateThreshold),
+		mcpagent.WithPermissionErrorPatterns(config.PermissionErrorPatterns...),
+		mcpagent.WithSecretProvider(config.SecretProvider),
+		mcpagent.WithRetryConfig(config.RetryConfig),
+		mcpagent.WithRunSummary(config.RunSummary),
+		mcpagent.WithMaxOutputTokens(config.MaxOutputTokens),
+		mcpagent.WithStopSequences(config.StopSequences),
+	}
+	if config.HistoryCompactionThreshold > 0 {
+		agentOptions = append(agentOptions, mcpagent.WithHistoryCompaction(config.HistoryCompactionThreshold, config.HistoryCompactionKeepTurns))
+	}
+	if config.ToolArgLanguage != "" {
+		agentOptions = append(agentOptions, mcpagent.WithToolArgTranslation(config.ToolArgLanguage, config.ToolArgTranslator))
+	}
+	for toolName, alternate := range config.ToolAlternates {
+		agentOptions = append(agentOptions, mcpagent.WithToolAlternate(toolName, alternate))
+	}
+	for toolName, readOnlyTool := range config.ToolReadOnlyFallbacks {
+		agentOptions = append(agentOptions, mcpagent.WithToolReadOnlyFallback(toolName, readOnlyTool))
+	}
+	if config.RoutingCache != nil {
+		agentOptions = append(agentOptions, mcpagent.WithRoutingCache(config.RoutingCache))
+	}
+	if config.ToolPolicy != nil {
+		agentOptions = append(agentOptions, mcpagent.WithToolPolicy(*config.ToolPolicy))
+	}
+	for toolName, compensate := range config.ToolCompensations {
+		agentOptions = append(agentOptions, mcpagent.WithToolCompensation(toolName, compensate))
 	}
 
 	agent, err = mcpagent.NewAgent(
@@ -644,6 +733,7 @@ func NewAgent(ctx context.Context, config Config) (Agent, error) {
 		logger:  agentLogger, // Initialize logger field
 		traceID: traceID,     // 🆕 NEW: Store trace ID for cleanup
 		tracer:  tracer,      // 🆕 NEW: Store tracer for cleanup
+		history: copyHistory(config.InitialHistory),
 	}, nil
 }
 
@@ -753,7 +843,12 @@ func (a *agentImpl) Invoke(ctx context.Context, prompt string) (string, error) {
 	if ctx.Err() != nil {
 		return "", fmt.Errorf("context cancelled before invoking: %w", ctx.Err())
 	}
-	return a.agent.Ask(ctx, prompt)
+	response, messages, err := a.agent.AskWithHistory(ctx, a.continueHistory(prompt))
+	if err != nil {
+		return "", err
+	}
+	a.recordHistory(messages)
+	return response, nil
 }
 
 func (a *agentImpl) InvokeWithHistory(ctx context.Context, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error) {
@@ -761,11 +856,16 @@ func (a *agentImpl) InvokeWithHistory(ctx context.Context, messages []llmtypes.M
 	if ctx.Err() != nil {
 		return "", nil, fmt.Errorf("context cancelled before invoking with history: %w", ctx.Err())
 	}
-	return a.agent.AskWithHistory(ctx, messages)
+	response, updatedMessages, err := a.agent.AskWithHistory(ctx, messages)
+	if err != nil {
+		return "", updatedMessages, err
+	}
+	a.recordHistory(updatedMessages)
+	return response, updatedMessages, nil
 }
 
 // Structured output functions for external agent
-// AskStructured runs a single-question interaction and converts the result to structured output
+// AskStructured continues the agent's conversation history with a question and converts the result to structured output
 func AskStructured[T any](a Agent, ctx context.Context, question string, schema T, schemaString string) (T, error) {
 	// Check for context cancellation before invoking
 	if ctx.Err() != nil {
@@ -781,7 +881,12 @@ func AskStructured[T any](a Agent, ctx context.Context, question string, schema
 	}
 
 	// Use the mcpagent structured output function
-	return mcpagent.AskStructured(agentImpl.agent, ctx, question, schema, schemaString)
+	result, messages, err := mcpagent.AskWithHistoryStructured(agentImpl.agent, ctx, agentImpl.continueHistory(question), schema, schemaString)
+	if err != nil {
+		return result, err
+	}
+	agentImpl.recordHistory(messages)
+	return result, nil
 }
 
 // AskWithHistoryStructured runs an interaction using message history and converts the result to structured output
@@ -800,7 +905,45 @@ func AskWithHistoryStructured[T any](a Agent, ctx context.Context, messages []ll
 	}
 
 	// Use the mcpagent structured output function
-	return mcpagent.AskWithHistoryStructured(agentImpl.agent, ctx, messages, schema, schemaString)
+	result, updatedMessages, err := mcpagent.AskWithHistoryStructured(agentImpl.agent, ctx, messages, schema, schemaString)
+	if err != nil {
+		return result, updatedMessages, err
+	}
+	agentImpl.recordHistory(updatedMessages)
+	return result, updatedMessages, nil
+}
+
+// AskStructuredWithFallback runs a single-question interaction like AskStructured; when the agent was
+// built with WithStructuredOutputRawFallback, a persistent structured output failure returns the raw
+// text answer with Degraded set instead of an error
+func AskStructuredWithFallback[T any](a Agent, ctx context.Context, question string, schema T, schemaString string) (mcpagent.StructuredResult[T], error) {
+	if ctx.Err() != nil {
+		return mcpagent.StructuredResult[T]{}, fmt.Errorf("context cancelled before invoking: %w", ctx.Err())
+	}
+
+	agentImpl, ok := a.(*agentImpl)
+	if !ok {
+		return mcpagent.StructuredResult[T]{}, fmt.Errorf("failed to get underlying agent implementation")
+	}
+
+	return mcpagent.AskStructuredWithFallback(agentImpl.agent, ctx, question, schema, schemaString)
+}
+
+// ResumeStructured completes a partial structured object from a truncated structured call, such as
+// the Partial of a mcpagent.StructuredOutputTruncatedError
+func ResumeStructured[T any](a Agent, ctx context.Context, partial string, schema T, schemaString string) (T, error) {
+	if ctx.Err() != nil {
+		var zero T
+		return zero, fmt.Errorf("context cancelled before invoking: %w", ctx.Err())
+	}
+
+	agentImpl, ok := a.(*agentImpl)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("failed to get underlying agent implementation")
+	}
+
+	return mcpagent.ResumeStructured(agentImpl.agent, ctx, partial, schema, schemaString)
 }
 
 // AgentConfig implementation
@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"mcp-agent/agent_go/internal/llm"
+	"mcp-agent/agent_go/internal/llmtypes"
 	"mcp-agent/agent_go/internal/observability"
 	"mcp-agent/agent_go/internal/utils"
+	"mcp-agent/agent_go/pkg/mcpagent"
 )
 
 // AgentBuilder provides a fluent interface for building agent configurations
@@ -32,14 +34,62 @@ type AgentBuilder struct {
 	tracer        observability.Tracer
 
 	// Timeout configuration
-	timeout     time.Duration
-	toolTimeout time.Duration
+	timeout      time.Duration
+	toolTimeout  time.Duration
+	toolTimeouts map[string]time.Duration
 
 	// Custom logger
 	logger utils.ExtendedLogger
 
 	// System prompt configuration
 	systemPrompt SystemPromptConfig
+
+	// Structured output configuration
+	structuredOutputRawFallback bool
+	structuredOutputMaxResumes  int
+	structuredOutputMaxAttempts int
+
+	// Tool transaction configuration
+	toolTransactions  bool
+	toolCompensations map[string]mcpagent.CompensationFunc
+
+	// Alternate tool configuration
+	toolAlternates         map[string]string
+	toolAlternateThreshold int
+
+	toolReadOnlyFallbacks   map[string]string
+	permissionErrorPatterns []string
+
+	// Smart routing decision cache
+	routingCache *mcpagent.RoutingCache
+
+	// Credential refresh configuration
+	secretProvider mcpagent.SecretProvider
+
+	// Tool argument translation configuration
+	toolArgLanguage   string
+	toolArgTranslator mcpagent.ToolArgTranslator
+
+	// LLM retry configuration
+	retryConfig mcpagent.RetryConfig
+
+	// Run summary on completion
+	runSummary bool
+
+	// Output limits of every LLM call
+	maxOutputTokens int
+	stopSequences   []string
+
+	// Tool allowlist/denylist
+	toolPolicy *mcpagent.ToolPolicy
+
+	// Context-window-aware history compaction
+	historyCompactionThreshold float64
+	historyCompactionKeepTurns int
+
+	// InvokeStream chunking
+	streamChunkSize int
+	initialHistory  []llmtypes.MessageContent
 }
 
 // NewAgentBuilder creates a new agent builder with default values
@@ -104,7 +154,9 @@ func (b *AgentBuilder) WithMaxTurns(maxTurns int) *AgentBuilder {
 	return b
 }
 
-// WithObservability sets the observability configuration
+// WithObservability sets the observability configuration. traceProvider is "langfuse", "jsonfile"
+// (JSON lines written to JSON_TRACE_PATH, default traces.jsonl), "otel" (OpenTelemetry spans exported
+// over OTLP/HTTP) or "noop"; langfuseHost is the Langfuse host, or the OTLP endpoint for "otel".
 func (b *AgentBuilder) WithObservability(traceProvider, langfuseHost string) *AgentBuilder {
 	b.traceProvider = traceProvider
 	b.langfuseHost = langfuseHost
@@ -123,6 +175,12 @@ func (b *AgentBuilder) WithToolTimeout(toolTimeout time.Duration) *AgentBuilder
 	return b
 }
 
+// WithToolTimeouts overrides the tool execution timeout of individual tools by name
+func (b *AgentBuilder) WithToolTimeouts(toolTimeouts map[string]time.Duration) *AgentBuilder {
+	b.toolTimeouts = toolTimeouts
+	return b
+}
+
 // WithLogger sets the custom logger
 func (b *AgentBuilder) WithLogger(logger utils.ExtendedLogger) *AgentBuilder {
 	b.logger = logger
@@ -142,6 +200,27 @@ func (b *AgentBuilder) WithCustomSystemPrompt(template string) *AgentBuilder {
 	return b
 }
 
+// WithSystemPromptVars sets custom variables rendered into the system prompt template.
+// Each key replaces the {{KEY}} placeholder, e.g. {"USER_NAME": "Ada"} fills {{USER_NAME}}.
+func (b *AgentBuilder) WithSystemPromptVars(vars map[string]string) *AgentBuilder {
+	b.systemPrompt.Variables = make(map[string]string, len(vars))
+	for name, value := range vars {
+		b.systemPrompt.Variables[name] = value
+	}
+	return b
+}
+
+// WithSystemPromptSections registers custom named sections rendered into the system prompt
+// template. Each function fills the {{NAME}} placeholder of its key, e.g. {"COMPANY_POLICY": policy}
+// fills {{COMPANY_POLICY}}, and is called when the prompt is built.
+func (b *AgentBuilder) WithSystemPromptSections(sections map[string]func() string) *AgentBuilder {
+	b.systemPrompt.Sections = make(map[string]func() string, len(sections))
+	for name, section := range sections {
+		b.systemPrompt.Sections[name] = section
+	}
+	return b
+}
+
 // WithSystemPromptMode sets the system prompt mode
 func (b *AgentBuilder) WithSystemPromptMode(mode string) *AgentBuilder {
 	b.systemPrompt.Mode = mode
@@ -166,6 +245,154 @@ func (b *AgentBuilder) WithLargeOutputInstructions(include bool) *AgentBuilder {
 	return b
 }
 
+// WithStructuredOutputRawFallback returns the raw text answer flagged as degraded from
+// AskStructuredWithFallback instead of failing when structured output cannot be produced
+func (b *AgentBuilder) WithStructuredOutputRawFallback(enabled bool) *AgentBuilder {
+	b.structuredOutputRawFallback = enabled
+	return b
+}
+
+// WithStructuredOutputResume resumes structured output cut off by the output token limit up to
+// maxResumes times, completing the remaining fields of the partial object
+func (b *AgentBuilder) WithStructuredOutputResume(maxResumes int) *AgentBuilder {
+	b.structuredOutputMaxResumes = maxResumes
+	return b
+}
+
+// WithStructuredOutputMaxAttempts sets how many structured output attempts are made, each re-asking
+// the model with the validation errors of the previous one (default 3)
+func (b *AgentBuilder) WithStructuredOutputMaxAttempts(attempts int) *AgentBuilder {
+	b.structuredOutputMaxAttempts = attempts
+	return b
+}
+
+// WithStructuredRetries re-asks the model up to n times when structured output fails validation
+// against the JSON schema or the target type; shorthand for WithStructuredOutputMaxAttempts(n+1)
+func (b *AgentBuilder) WithStructuredRetries(n int) *AgentBuilder {
+	return b.WithStructuredOutputMaxAttempts(n + 1)
+}
+
+// WithToolTransactions lets the LLM group tool calls in transactions that are rolled back with
+// the registered compensations when one of their calls fails
+func (b *AgentBuilder) WithToolTransactions(enabled bool) *AgentBuilder {
+	b.toolTransactions = enabled
+	return b
+}
+
+// WithToolCompensation registers the action that undoes a successful call of toolName during rollback
+func (b *AgentBuilder) WithToolCompensation(toolName string, compensate mcpagent.CompensationFunc) *AgentBuilder {
+	if b.toolCompensations == nil {
+		b.toolCompensations = make(map[string]mcpagent.CompensationFunc)
+	}
+	b.toolCompensations[toolName] = compensate
+	return b
+}
+
+// WithToolAlternate calls alternateTool with the same arguments when toolName keeps failing
+func (b *AgentBuilder) WithToolAlternate(toolName, alternateTool string) *AgentBuilder {
+	if b.toolAlternates == nil {
+		b.toolAlternates = make(map[string]string)
+	}
+	b.toolAlternates[toolName] = alternateTool
+	return b
+}
+
+// WithToolAlternateThreshold sets how many consecutive failures of a tool trigger its alternate
+func (b *AgentBuilder) WithToolAlternateThreshold(failures int) *AgentBuilder {
+	b.toolAlternateThreshold = failures
+	return b
+}
+
+// WithToolReadOnlyFallback calls readOnlyTool with the same arguments when toolName fails with a permission error
+func (b *AgentBuilder) WithToolReadOnlyFallback(toolName, readOnlyTool string) *AgentBuilder {
+	if b.toolReadOnlyFallbacks == nil {
+		b.toolReadOnlyFallbacks = make(map[string]string)
+	}
+	b.toolReadOnlyFallbacks[toolName] = readOnlyTool
+	return b
+}
+
+// WithPermissionErrorPatterns adds substrings that mark a tool failure as a permission error
+func (b *AgentBuilder) WithPermissionErrorPatterns(patterns ...string) *AgentBuilder {
+	b.permissionErrorPatterns = append(b.permissionErrorPatterns, patterns...)
+	return b
+}
+
+// WithRoutingCache reuses smart routing decisions from cache for similar queries
+func (b *AgentBuilder) WithRoutingCache(cache *mcpagent.RoutingCache) *AgentBuilder {
+	b.routingCache = cache
+	return b
+}
+
+// WithSecretProvider re-authenticates through provider when an LLM call fails on expired credentials
+func (b *AgentBuilder) WithSecretProvider(provider mcpagent.SecretProvider) *AgentBuilder {
+	b.secretProvider = provider
+	return b
+}
+
+// WithToolArgTranslation translates non-English tool arguments to language before the tool runs
+// (translator nil uses the agent's LLM)
+func (b *AgentBuilder) WithToolArgTranslation(language string, translator mcpagent.ToolArgTranslator) *AgentBuilder {
+	b.toolArgLanguage = language
+	b.toolArgTranslator = translator
+	return b
+}
+
+// WithRetryConfig sets the retry budget and delays of throttled LLM calls (zero fields keep the defaults)
+func (b *AgentBuilder) WithRetryConfig(config mcpagent.RetryConfig) *AgentBuilder {
+	b.retryConfig = config
+	return b
+}
+
+// WithRunSummary attaches a recap of the run (what was done, tools used, decisions made) to the
+// completion event, at the cost of one structured LLM call per run
+func (b *AgentBuilder) WithRunSummary(enabled bool) *AgentBuilder {
+	b.runSummary = enabled
+	return b
+}
+
+// WithMaxOutputTokens caps the output tokens of every LLM call (0 keeps the defaults)
+func (b *AgentBuilder) WithMaxOutputTokens(maxTokens int) *AgentBuilder {
+	b.maxOutputTokens = maxTokens
+	return b
+}
+
+// WithStopSequences ends every LLM generation at the first of the sequences, e.g. to cut
+// structured extraction off after the closing delimiter
+func (b *AgentBuilder) WithStopSequences(sequences ...string) *AgentBuilder {
+	b.stopSequences = sequences
+	return b
+}
+
+// WithToolPolicy blocks tools by allowlist/denylist before any call, replacing the process-wide
+// default policy; patterns match tool names or "server:tool"
+func (b *AgentBuilder) WithToolPolicy(policy mcpagent.ToolPolicy) *AgentBuilder {
+	b.toolPolicy = &policy
+	return b
+}
+
+// WithHistoryCompaction drops the oldest turns before an LLM call once the estimated prompt exceeds
+// threshold (a fraction of the model's context window), keeping the system prompt and the
+// keepRecentTurns most recent turns (0 = default 2)
+func (b *AgentBuilder) WithHistoryCompaction(threshold float64, keepRecentTurns int) *AgentBuilder {
+	b.historyCompactionThreshold = threshold
+	b.historyCompactionKeepTurns = keepRecentTurns
+	return b
+}
+
+// WithStreamChunkSize batches InvokeStream tokens into chunks of at least n bytes (0 forwards every token)
+func (b *AgentBuilder) WithStreamChunkSize(n int) *AgentBuilder {
+	b.streamChunkSize = n
+	return b
+}
+
+// WithInitialHistory starts the agent from a previous conversation, e.g. one exported with
+// ExportHistory and persisted with MarshalHistory; Build fails if the history is invalid
+func (b *AgentBuilder) WithInitialHistory(messages []llmtypes.MessageContent) *AgentBuilder {
+	b.initialHistory = messages
+	return b
+}
+
 // Build creates the agent configuration and returns the agent
 func (b *AgentBuilder) Build(ctx context.Context) (Agent, error) {
 	// Convert builder to internal config for compatibility
@@ -184,8 +411,32 @@ func (b *AgentBuilder) Build(ctx context.Context) (Agent, error) {
 		Tracer:        b.tracer,
 		Timeout:       b.timeout,
 		ToolTimeout:   b.toolTimeout,
+		ToolTimeouts:  b.toolTimeouts,
 		Logger:        b.logger,
 		SystemPrompt:  b.systemPrompt,
+
+		StructuredOutputRawFallback: b.structuredOutputRawFallback,
+		StructuredOutputMaxResumes:  b.structuredOutputMaxResumes,
+		StructuredOutputMaxAttempts: b.structuredOutputMaxAttempts,
+		ToolTransactions:            b.toolTransactions,
+		ToolCompensations:           b.toolCompensations,
+		ToolAlternates:              b.toolAlternates,
+		ToolAlternateThreshold:      b.toolAlternateThreshold,
+		ToolReadOnlyFallbacks:       b.toolReadOnlyFallbacks,
+		PermissionErrorPatterns:     b.permissionErrorPatterns,
+		RoutingCache:                b.routingCache,
+		SecretProvider:              b.secretProvider,
+		ToolArgLanguage:             b.toolArgLanguage,
+		ToolArgTranslator:           b.toolArgTranslator,
+		RetryConfig:                 b.retryConfig,
+		RunSummary:                  b.runSummary,
+		MaxOutputTokens:             b.maxOutputTokens,
+		StopSequences:               b.stopSequences,
+		ToolPolicy:                  b.toolPolicy,
+		HistoryCompactionThreshold:  b.historyCompactionThreshold,
+		HistoryCompactionKeepTurns:  b.historyCompactionKeepTurns,
+		StreamChunkSize:             b.streamChunkSize,
+		InitialHistory:              b.initialHistory,
 	}
 
 	// Use the existing NewAgent function for now
@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"mcp-agent/agent_go/internal/llm"
+	"mcp-agent/agent_go/internal/llmtypes"
 	"mcp-agent/agent_go/internal/observability"
 	"mcp-agent/agent_go/internal/utils"
+	"mcp-agent/agent_go/pkg/mcpagent"
 )
 
 // MCPServerConfig holds configuration for a single MCP server
@@ -38,6 +40,12 @@ type SystemPromptConfig struct {
 	// Additional instructions to append to system prompt
 	AdditionalInstructions string
 
+	// Custom template variables rendered as {{NAME}} alongside the built-in placeholders
+	Variables map[string]string
+
+	// Custom named sections rendered as {{NAME}}, evaluated each time the prompt is built
+	Sections map[string]func() string
+
 	// Whether to include default tool handling instructions
 	IncludeToolInstructions bool
 
@@ -65,19 +73,79 @@ type Config struct {
 	MaxTurns    int          // Maximum conversation turns
 
 	// Observability configuration
-	TraceProvider string               // Tracing provider (console, langfuse, noop)
-	LangfuseHost  string               // Langfuse host URL
+	TraceProvider string               // Tracing provider (console, langfuse, jsonfile, otel, noop)
+	LangfuseHost  string               // Langfuse host URL, or the OTLP endpoint for otel
 	Tracer        observability.Tracer // 🆕 NEW: Optional tracer instance
 
 	// Timeout configuration
-	Timeout     time.Duration
-	ToolTimeout time.Duration // Tool execution timeout (default: 5 minutes)
+	Timeout      time.Duration
+	ToolTimeout  time.Duration            // Tool execution timeout (default: 5 minutes)
+	ToolTimeouts map[string]time.Duration // Per-tool timeout overrides by tool name
 
 	// Custom logger (optional) - uses our ExtendedLogger interface
 	Logger utils.ExtendedLogger
 
 	// System prompt configuration
 	SystemPrompt SystemPromptConfig
+
+	// Return the raw text answer (flagged as degraded) from AskStructuredWithFallback
+	// instead of an error when structured output cannot be produced
+	StructuredOutputRawFallback bool
+
+	// Resume structured output cut off by the output token limit up to this many times (0 disables)
+	StructuredOutputMaxResumes int
+
+	// Structured output attempts, each re-asking with the previous attempt's validation errors (0 = default 3)
+	StructuredOutputMaxAttempts int
+
+	// Expose begin/commit/rollback_transaction tools; a failed call in an open transaction
+	// runs the registered compensations of its earlier calls in reverse order
+	ToolTransactions  bool
+	ToolCompensations map[string]mcpagent.CompensationFunc // Tool name -> compensating action
+
+	// Call an alternate tool with the same arguments once a tool has failed ToolAlternateThreshold
+	// times in a row (0 uses the default of 2)
+	ToolAlternates         map[string]string // Tool name -> alternate tool name
+	ToolAlternateThreshold int
+
+	// Call a read-only tool with the same arguments when a tool fails with a permission error
+	ToolReadOnlyFallbacks   map[string]string // Tool name -> read-only tool name
+	PermissionErrorPatterns []string          // Extra substrings marking a tool failure as a permission error
+
+	// Reuse smart routing decisions for similar queries; share one cache between agents (nil disables)
+	RoutingCache *mcpagent.RoutingCache
+
+	// Refreshes expired LLM credentials (e.g. temporary Bedrock credentials) before retrying the call
+	SecretProvider mcpagent.SecretProvider
+
+	// Translate non-English tool arguments to this language before execution ("" disables);
+	// ToolArgTranslator nil translates with the agent's LLM
+	ToolArgLanguage   string
+	ToolArgTranslator mcpagent.ToolArgTranslator
+
+	// Retry budget and delays of throttled LLM calls (zero fields keep the defaults)
+	RetryConfig mcpagent.RetryConfig
+
+	// Attach a structured-LLM recap of the run to the completion event
+	RunSummary bool
+
+	// Output token cap (0 keeps the defaults) and stop sequences of every LLM call
+	MaxOutputTokens int
+	StopSequences   []string
+
+	// Tools blocked by allowlist/denylist before any call; nil uses the process-wide default policy
+	ToolPolicy *mcpagent.ToolPolicy
+
+	// Drop the oldest turns once the prompt exceeds this fraction of the context window (0 disables),
+	// always keeping the system prompt and HistoryCompactionKeepTurns recent turns (0 = default 2)
+	HistoryCompactionThreshold float64
+	HistoryCompactionKeepTurns int
+
+	// Minimum bytes per InvokeStream chunk (0 forwards every token as generated)
+	StreamChunkSize int
+
+	// Conversation the agent continues from (validated with ValidateHistory)
+	InitialHistory []llmtypes.MessageContent
 }
 
 // DefaultConfig returns a default configuration
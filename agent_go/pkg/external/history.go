@@ -0,0 +1,133 @@
+package external
+
+import (
+	"fmt"
+	"strings"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// ExportHistory returns a copy of the agent's conversation history, e.g. to persist it with
+// MarshalHistory and restore it in another process with ImportHistory
+func (a *agentImpl) ExportHistory() []llmtypes.MessageContent {
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+	return copyHistory(a.history)
+}
+
+// ImportHistory replaces the agent's conversation history; later prompts continue it.
+// The history is validated first (see ValidateHistory) and left unchanged when invalid.
+func (a *agentImpl) ImportHistory(messages []llmtypes.MessageContent) error {
+	if err := ValidateHistory(messages); err != nil {
+		return err
+	}
+	a.setHistory(messages)
+	return nil
+}
+
+// ClearHistory starts a new conversation
+func (a *agentImpl) ClearHistory() {
+	a.setHistory(nil)
+}
+
+// recordHistory stores the conversation returned by a run. The agent's own system prompt is
+// dropped because each run rebuilds it from the current configuration.
+func (a *agentImpl) recordHistory(messages []llmtypes.MessageContent) {
+	if len(messages) > 0 && messages[0].Role == llmtypes.ChatMessageTypeSystem {
+		messages = messages[1:]
+	}
+	a.setHistory(messages)
+}
+
+func (a *agentImpl) setHistory(messages []llmtypes.MessageContent) {
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+	a.history = copyHistory(messages)
+}
+
+// continueHistory returns the conversation history followed by a new user prompt
+func (a *agentImpl) continueHistory(prompt string) []llmtypes.MessageContent {
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+	messages := copyHistory(a.history)
+	return append(messages, llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, prompt))
+}
+
+// MarshalHistory encodes a conversation history as JSON for persistence
+func MarshalHistory(messages []llmtypes.MessageContent) ([]byte, error) {
+	return llmtypes.MarshalMessages(messages)
+}
+
+// UnmarshalHistory decodes a conversation history encoded by MarshalHistory
+func UnmarshalHistory(data []byte) ([]llmtypes.MessageContent, error) {
+	return llmtypes.UnmarshalMessages(data)
+}
+
+// ValidateHistory checks a conversation history can be continued: every message has non-empty parts,
+// a system message may only come first, the conversation starts with a user message, user and
+// assistant turns alternate, and tool responses only follow an assistant message with tool calls.
+func ValidateHistory(messages []llmtypes.MessageContent) error {
+	var previous llmtypes.ChatMessageType
+	pendingToolCalls := false
+	for i, message := range messages {
+		if len(message.Parts) == 0 {
+			return fmt.Errorf("history message %d (%s) has no parts", i, message.Role)
+		}
+		for _, part := range message.Parts {
+			if text, ok := part.(llmtypes.TextContent); ok && strings.TrimSpace(text.Text) == "" {
+				return fmt.Errorf("history message %d (%s) has an empty text part", i, message.Role)
+			}
+		}
+
+		switch message.Role {
+		case llmtypes.ChatMessageTypeSystem:
+			if i != 0 {
+				return fmt.Errorf("history message %d: a system message may only come first", i)
+			}
+		case llmtypes.ChatMessageTypeHuman:
+			if previous == llmtypes.ChatMessageTypeHuman {
+				return fmt.Errorf("history message %d: two consecutive user messages", i)
+			}
+			if pendingToolCalls {
+				return fmt.Errorf("history message %d: user message before the tool responses of message %d", i, i-1)
+			}
+		case llmtypes.ChatMessageTypeAI:
+			if previous != llmtypes.ChatMessageTypeHuman && previous != llmtypes.ChatMessageTypeTool {
+				return fmt.Errorf("history message %d: an assistant message must follow a user message or tool responses", i)
+			}
+			pendingToolCalls = false
+			for _, part := range message.Parts {
+				if _, ok := part.(llmtypes.ToolCall); ok {
+					pendingToolCalls = true
+				}
+			}
+		case llmtypes.ChatMessageTypeTool:
+			answersToolCalls := previous == llmtypes.ChatMessageTypeTool || previous == llmtypes.ChatMessageTypeAI && pendingToolCalls
+			if !answersToolCalls {
+				return fmt.Errorf("history message %d: tool responses must follow an assistant message with tool calls", i)
+			}
+		default:
+			return fmt.Errorf("history message %d has unsupported role %q", i, message.Role)
+		}
+
+		if message.Role != llmtypes.ChatMessageTypeSystem && previous == "" && message.Role != llmtypes.ChatMessageTypeHuman {
+			return fmt.Errorf("history message %d: the conversation must start with a user message", i)
+		}
+		if message.Role != llmtypes.ChatMessageTypeSystem {
+			previous = message.Role
+		}
+	}
+	return nil
+}
+
+// copyHistory copies messages and their part slices so callers cannot alias the agent's history
+func copyHistory(messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+	if messages == nil {
+		return nil
+	}
+	copied := make([]llmtypes.MessageContent, len(messages))
+	for i, message := range messages {
+		copied[i] = llmtypes.MessageContent{Role: message.Role, Parts: append([]llmtypes.ContentPart(nil), message.Parts...)}
+	}
+	return copied
+}
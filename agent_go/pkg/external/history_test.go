@@ -0,0 +1,186 @@
+package external
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// recordingLLM answers every call with the same content and records the messages of each call
+type recordingLLM struct {
+	answer string
+
+	mu    sync.Mutex
+	calls [][]llmtypes.MessageContent
+}
+
+func (l *recordingLLM) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	l.mu.Lock()
+	l.calls = append(l.calls, messages)
+	l.mu.Unlock()
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: l.answer}}}, nil
+}
+
+// firstCallText joins the text of the first call's non-system messages
+func (l *recordingLLM) firstCallText() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var texts []string
+	for _, message := range l.calls[0] {
+		if message.Role == llmtypes.ChatMessageTypeSystem {
+			continue
+		}
+		for _, part := range message.Parts {
+			if text, ok := part.(llmtypes.TextContent); ok {
+				texts = append(texts, text.Text)
+			}
+		}
+	}
+	return strings.Join(texts, " | ")
+}
+
+func sampleHistory() []llmtypes.MessageContent {
+	return []llmtypes.MessageContent{
+		llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "Which buckets do I have?"),
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{llmtypes.ToolCall{
+			ID: "call-1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "list_buckets", Arguments: `{}`},
+		}}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{
+			ToolCallID: "call-1", Name: "list_buckets", Content: `["logs","backups"]`,
+		}}},
+		llmtypes.TextPart(llmtypes.ChatMessageTypeAI, "You have two buckets: logs and backups."),
+	}
+}
+
+func TestHistoryRoundTripsThroughJSON(t *testing.T) {
+	a := newStreamTestAgent(t, &recordingLLM{answer: "unused"}, 0)
+	if err := a.ImportHistory(sampleHistory()); err != nil {
+		t.Fatalf("ImportHistory: %v", err)
+	}
+
+	data, err := MarshalHistory(a.ExportHistory())
+	if err != nil {
+		t.Fatalf("MarshalHistory: %v", err)
+	}
+	restored, err := UnmarshalHistory(data)
+	if err != nil {
+		t.Fatalf("UnmarshalHistory: %v", err)
+	}
+	if !reflect.DeepEqual(restored, sampleHistory()) {
+		t.Fatalf("restored history differs:\n got %+v\nwant %+v", restored, sampleHistory())
+	}
+
+	// A restarted agent accepts the restored history
+	if err := newStreamTestAgent(t, &recordingLLM{answer: "unused"}, 0).ImportHistory(restored); err != nil {
+		t.Fatalf("ImportHistory of restored history: %v", err)
+	}
+}
+
+func TestExportHistoryReturnsACopy(t *testing.T) {
+	a := newStreamTestAgent(t, &recordingLLM{answer: "unused"}, 0)
+	if err := a.ImportHistory(sampleHistory()); err != nil {
+		t.Fatalf("ImportHistory: %v", err)
+	}
+
+	exported := a.ExportHistory()
+	exported[0].Parts[0] = llmtypes.TextContent{Text: "changed"}
+	if !reflect.DeepEqual(a.ExportHistory(), sampleHistory()) {
+		t.Fatal("modifying the exported history changed the agent's history")
+	}
+}
+
+func TestImportHistoryRejectsInvalidHistory(t *testing.T) {
+	human := func(text string) llmtypes.MessageContent {
+		return llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, text)
+	}
+	ai := func(text string) llmtypes.MessageContent { return llmtypes.TextPart(llmtypes.ChatMessageTypeAI, text) }
+	history := sampleHistory()
+
+	cases := map[string][]llmtypes.MessageContent{
+		"empty parts":           {human("hi"), {Role: llmtypes.ChatMessageTypeAI}},
+		"empty text":            {human("hi"), ai("  ")},
+		"starts with ai":        {ai("hello")},
+		"consecutive users":     {human("hi"), human("again")},
+		"consecutive ai":        {human("hi"), ai("hello"), ai("hello again")},
+		"late system message":   {human("hi"), llmtypes.TextPart(llmtypes.ChatMessageTypeSystem, "be brief")},
+		"orphan tool response":  {human("hi"), ai("hello"), history[2]},
+		"missing tool response": {history[0], history[1], human("never mind")},
+	}
+	for name, messages := range cases {
+		a := newStreamTestAgent(t, &recordingLLM{answer: "unused"}, 0)
+		if err := a.ImportHistory(sampleHistory()); err != nil {
+			t.Fatalf("ImportHistory: %v", err)
+		}
+		if err := a.ImportHistory(messages); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+		if !reflect.DeepEqual(a.ExportHistory(), sampleHistory()) {
+			t.Errorf("%s: a rejected import changed the history", name)
+		}
+	}
+
+	withSystem := append([]llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeSystem, "be brief")}, sampleHistory()...)
+	if err := ValidateHistory(withSystem); err != nil {
+		t.Errorf("a leading system message should be accepted: %v", err)
+	}
+}
+
+func TestInvokeContinuesImportedHistory(t *testing.T) {
+	llm := &recordingLLM{answer: "The logs bucket."}
+	a := newStreamTestAgent(t, llm, 0)
+	if err := a.ImportHistory(sampleHistory()); err != nil {
+		t.Fatalf("ImportHistory: %v", err)
+	}
+
+	if _, err := a.Invoke(context.Background(), "Which one is bigger?"); err != nil {
+		t.Fatalf("Invoke: %v", err)
+	}
+	if got := llm.firstCallText(); !strings.Contains(got, "Which buckets do I have?") || !strings.HasSuffix(got, "Which one is bigger?") {
+		t.Fatalf("the LLM did not receive the imported history before the prompt: %q", got)
+	}
+
+	history := a.ExportHistory()
+	last := history[len(history)-1]
+	if last.Role != llmtypes.ChatMessageTypeAI || !reflect.DeepEqual(last.Parts, []llmtypes.ContentPart{llmtypes.TextContent{Text: "The logs bucket."}}) {
+		t.Fatalf("expected the answer to be appended to the history, got %+v", last)
+	}
+	if err := ValidateHistory(history); err != nil {
+		t.Fatalf("the continued history is invalid: %v", err)
+	}
+
+	a.ClearHistory()
+	if a.ExportHistory() != nil {
+		t.Fatal("ClearHistory left messages behind")
+	}
+}
+
+func TestAskStructuredAfterImport(t *testing.T) {
+	llm := &recordingLLM{answer: `{"bucket":"logs","count":2}`}
+	a := newStreamTestAgent(t, llm, 0)
+	if err := a.ImportHistory(sampleHistory()); err != nil {
+		t.Fatalf("ImportHistory: %v", err)
+	}
+
+	type bucketAnswer struct {
+		Bucket string `json:"bucket"`
+		Count  int    `json:"count"`
+	}
+	schema := `{"type":"object","properties":{"bucket":{"type":"string"},"count":{"type":"integer"}}}`
+	answer, err := AskStructured(Agent(a), context.Background(), "Which bucket holds logs?", bucketAnswer{}, schema)
+	if err != nil {
+		t.Fatalf("AskStructured: %v", err)
+	}
+	if answer != (bucketAnswer{Bucket: "logs", Count: 2}) {
+		t.Fatalf("answer = %+v", answer)
+	}
+	if got := llm.firstCallText(); !strings.Contains(got, "You have two buckets") {
+		t.Fatalf("AskStructured did not continue the imported history: %q", got)
+	}
+	if got := len(a.ExportHistory()); got < len(sampleHistory())+2 {
+		t.Fatalf("expected the question and answer to be recorded, history has %d messages", got)
+	}
+}
@@ -0,0 +1,243 @@
+package external
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// AgentFactory creates an agent for a configuration. NewAgent is used by default;
+// tests and custom setups can supply their own.
+type AgentFactory func(ctx context.Context, config Config) (Agent, error)
+
+// ComparisonRun captures the measurable outcome of running a query against one configuration
+type ComparisonRun struct {
+	Label            string        `json:"label"`
+	Answer           string        `json:"answer"`
+	Error            string        `json:"error,omitempty"`
+	Duration         time.Duration `json:"duration"`
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	TotalTokens      int           `json:"total_tokens"`
+	ToolCalls        []string      `json:"tool_calls"`
+}
+
+// ComparisonDiff quantifies the differences between the baseline and candidate runs.
+// Deltas are candidate minus baseline.
+type ComparisonDiff struct {
+	AnswerSimilarity         float64       `json:"answer_similarity"` // Jaccard similarity of answer words (0-1)
+	IdenticalAnswers         bool          `json:"identical_answers"`
+	PromptTokenDelta         int           `json:"prompt_token_delta"`
+	CompletionTokenDelta     int           `json:"completion_token_delta"`
+	TotalTokenDelta          int           `json:"total_token_delta"`
+	ToolCallCountDelta       int           `json:"tool_call_count_delta"`
+	ToolCallsOnlyInBaseline  []string      `json:"tool_calls_only_in_baseline"`
+	ToolCallsOnlyInCandidate []string      `json:"tool_calls_only_in_candidate"`
+	DurationDelta            time.Duration `json:"duration_delta"`
+}
+
+// ComparisonResult is the structured output of a prompt comparison
+type ComparisonResult struct {
+	Query     string         `json:"query"`
+	Baseline  ComparisonRun  `json:"baseline"`
+	Candidate ComparisonRun  `json:"candidate"`
+	Diff      ComparisonDiff `json:"diff"`
+}
+
+// PromptComparison runs the same query against two configurations (e.g. two
+// system prompt versions) and produces a structured diff of the results.
+//
+// Example:
+//
+//	baseline := external.DefaultConfig().WithAdditionalInstructions(oldInstructions)
+//	candidate := external.DefaultConfig().WithAdditionalInstructions(newInstructions)
+//	result, err := external.NewPromptComparison(baseline, candidate).Run(ctx, "List open issues")
+type PromptComparison struct {
+	baseline       Config
+	candidate      Config
+	baselineLabel  string
+	candidateLabel string
+	factory        AgentFactory
+}
+
+// NewPromptComparison creates a comparison between a baseline and a candidate configuration
+func NewPromptComparison(baseline, candidate Config) *PromptComparison {
+	return &PromptComparison{
+		baseline:       baseline,
+		candidate:      candidate,
+		baselineLabel:  "baseline",
+		candidateLabel: "candidate",
+		factory:        NewAgent,
+	}
+}
+
+// WithLabels sets the labels reported for the baseline and candidate runs
+func (p *PromptComparison) WithLabels(baseline, candidate string) *PromptComparison {
+	p.baselineLabel = baseline
+	p.candidateLabel = candidate
+	return p
+}
+
+// WithAgentFactory sets the factory used to create agents for each configuration
+func (p *PromptComparison) WithAgentFactory(factory AgentFactory) *PromptComparison {
+	p.factory = factory
+	return p
+}
+
+// Run executes the query against both configurations sequentially and diffs the results.
+// Query failures are recorded on the run rather than aborting the comparison; only
+// agent creation failures are returned as errors.
+func (p *PromptComparison) Run(ctx context.Context, query string) (*ComparisonResult, error) {
+	baseline, err := p.runOne(ctx, p.baseline, p.baselineLabel, query)
+	if err != nil {
+		return nil, err
+	}
+	candidate, err := p.runOne(ctx, p.candidate, p.candidateLabel, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ComparisonResult{
+		Query:     query,
+		Baseline:  baseline,
+		Candidate: candidate,
+		Diff:      diffComparisonRuns(baseline, candidate),
+	}, nil
+}
+
+func (p *PromptComparison) runOne(ctx context.Context, config Config, label, query string) (ComparisonRun, error) {
+	run := ComparisonRun{Label: label, ToolCalls: []string{}}
+
+	agent, err := p.factory(ctx, config)
+	if err != nil {
+		return run, fmt.Errorf("failed to create %s agent: %w", label, err)
+	}
+	defer agent.Close()
+
+	collector := &comparisonCollector{name: "prompt-comparison-" + label}
+	agent.AddEventListener(collector)
+	defer agent.RemoveEventListener(collector)
+
+	start := time.Now()
+	answer, err := agent.Invoke(ctx, query)
+	run.Duration = time.Since(start)
+	run.Answer = answer
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	collector.mu.Lock()
+	run.PromptTokens = collector.promptTokens
+	run.CompletionTokens = collector.completionTokens
+	run.TotalTokens = collector.totalTokens
+	run.ToolCalls = append(run.ToolCalls, collector.toolCalls...)
+	collector.mu.Unlock()
+
+	return run, nil
+}
+
+// comparisonCollector accumulates token usage and tool calls for a single run
+type comparisonCollector struct {
+	name             string
+	mu               sync.Mutex
+	promptTokens     int
+	completionTokens int
+	totalTokens      int
+	toolCalls        []string
+}
+
+func (c *comparisonCollector) HandleEvent(ctx context.Context, event *AgentEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch data := event.Data.(type) {
+	case *events.TokenUsageEvent:
+		c.promptTokens += data.PromptTokens
+		c.completionTokens += data.CompletionTokens
+		c.totalTokens += data.TotalTokens
+	case *events.ToolCallStartEvent:
+		c.toolCalls = append(c.toolCalls, data.ToolName)
+	}
+	return nil
+}
+
+func (c *comparisonCollector) Name() string {
+	return c.name
+}
+
+// diffComparisonRuns computes the structured diff between two runs
+func diffComparisonRuns(baseline, candidate ComparisonRun) ComparisonDiff {
+	onlyInBaseline, onlyInCandidate := diffToolCalls(baseline.ToolCalls, candidate.ToolCalls)
+	return ComparisonDiff{
+		AnswerSimilarity:         answerSimilarity(baseline.Answer, candidate.Answer),
+		IdenticalAnswers:         strings.TrimSpace(baseline.Answer) == strings.TrimSpace(candidate.Answer),
+		PromptTokenDelta:         candidate.PromptTokens - baseline.PromptTokens,
+		CompletionTokenDelta:     candidate.CompletionTokens - baseline.CompletionTokens,
+		TotalTokenDelta:          candidate.TotalTokens - baseline.TotalTokens,
+		ToolCallCountDelta:       len(candidate.ToolCalls) - len(baseline.ToolCalls),
+		ToolCallsOnlyInBaseline:  onlyInBaseline,
+		ToolCallsOnlyInCandidate: onlyInCandidate,
+		DurationDelta:            candidate.Duration - baseline.Duration,
+	}
+}
+
+// answerSimilarity returns the Jaccard similarity of the lowercase word sets of two answers
+func answerSimilarity(a, b string) float64 {
+	wordsA, wordsB := answerWords(a), answerWords(b)
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+
+	intersection := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			intersection++
+		}
+	}
+	union := len(wordsA) + len(wordsB) - intersection
+	return float64(intersection) / float64(union)
+}
+
+func answerWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[word] = true
+	}
+	return words
+}
+
+// diffToolCalls returns the tool names that appear in only one of the runs
+func diffToolCalls(baseline, candidate []string) ([]string, []string) {
+	baselineSet := make(map[string]bool, len(baseline))
+	for _, name := range baseline {
+		baselineSet[name] = true
+	}
+	candidateSet := make(map[string]bool, len(candidate))
+	for _, name := range candidate {
+		candidateSet[name] = true
+	}
+
+	onlyInBaseline := []string{}
+	for name := range baselineSet {
+		if !candidateSet[name] {
+			onlyInBaseline = append(onlyInBaseline, name)
+		}
+	}
+	onlyInCandidate := []string{}
+	for name := range candidateSet {
+		if !baselineSet[name] {
+			onlyInCandidate = append(onlyInCandidate, name)
+		}
+	}
+	sort.Strings(onlyInBaseline)
+	sort.Strings(onlyInCandidate)
+	return onlyInBaseline, onlyInCandidate
+}
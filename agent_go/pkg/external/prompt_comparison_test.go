@@ -0,0 +1,110 @@
+package external
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// mockComparisonAgent answers with a fixed response and emits scripted events.
+// Embedding Agent satisfies the interface; only the methods used by the harness are implemented.
+type mockComparisonAgent struct {
+	Agent
+	answer    string
+	events    []events.EventData
+	listeners []AgentEventListener
+}
+
+func (m *mockComparisonAgent) Invoke(ctx context.Context, prompt string) (string, error) {
+	for _, data := range m.events {
+		for _, listener := range m.listeners {
+			_ = listener.HandleEvent(ctx, &AgentEvent{Type: data.GetEventType(), Data: data})
+		}
+	}
+	return m.answer, nil
+}
+
+func (m *mockComparisonAgent) AddEventListener(listener AgentEventListener) {
+	m.listeners = append(m.listeners, listener)
+}
+
+func (m *mockComparisonAgent) RemoveEventListener(listener AgentEventListener) {
+	m.listeners = nil
+}
+
+func (m *mockComparisonAgent) Close() error {
+	return nil
+}
+
+func TestPromptComparisonCapturesDifferences(t *testing.T) {
+	agents := map[string]*mockComparisonAgent{
+		"v1": {
+			answer: "There are three open issues in the repository",
+			events: []events.EventData{
+				&events.ToolCallStartEvent{ToolName: "list_issues"},
+				&events.ToolCallStartEvent{ToolName: "get_repo"},
+				&events.TokenUsageEvent{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120},
+				&events.TokenUsageEvent{PromptTokens: 150, CompletionTokens: 30, TotalTokens: 180},
+			},
+		},
+		"v2": {
+			answer: "There are three open issues",
+			events: []events.EventData{
+				&events.ToolCallStartEvent{ToolName: "search_issues"},
+				&events.TokenUsageEvent{PromptTokens: 80, CompletionTokens: 10, TotalTokens: 90},
+			},
+		},
+	}
+	factory := func(ctx context.Context, config Config) (Agent, error) {
+		return agents[config.SystemPrompt.AdditionalInstructions], nil
+	}
+
+	baseline := DefaultConfig().WithAdditionalInstructions("v1")
+	candidate := DefaultConfig().WithAdditionalInstructions("v2")
+	result, err := NewPromptComparison(baseline, candidate).
+		WithLabels("prompt-v1", "prompt-v2").
+		WithAgentFactory(factory).
+		Run(context.Background(), "How many open issues?")
+	if err != nil {
+		t.Fatalf("comparison failed: %v", err)
+	}
+
+	if result.Baseline.Label != "prompt-v1" || result.Candidate.Label != "prompt-v2" {
+		t.Fatalf("unexpected labels: %q, %q", result.Baseline.Label, result.Candidate.Label)
+	}
+	if result.Baseline.TotalTokens != 300 || result.Candidate.TotalTokens != 90 {
+		t.Fatalf("unexpected token totals: %d, %d", result.Baseline.TotalTokens, result.Candidate.TotalTokens)
+	}
+
+	diff := result.Diff
+	if diff.IdenticalAnswers {
+		t.Fatalf("answers should differ")
+	}
+	// {there, are, three, open, issues} shared out of {+in, the, repository}
+	if diff.AnswerSimilarity != 5.0/8.0 {
+		t.Fatalf("expected similarity 0.625, got %v", diff.AnswerSimilarity)
+	}
+	if diff.PromptTokenDelta != -170 || diff.CompletionTokenDelta != -40 || diff.TotalTokenDelta != -210 {
+		t.Fatalf("unexpected token deltas: %+v", diff)
+	}
+	if diff.ToolCallCountDelta != -1 {
+		t.Fatalf("expected tool call delta -1, got %d", diff.ToolCallCountDelta)
+	}
+	if !reflect.DeepEqual(diff.ToolCallsOnlyInBaseline, []string{"get_repo", "list_issues"}) {
+		t.Fatalf("unexpected baseline-only tools: %v", diff.ToolCallsOnlyInBaseline)
+	}
+	if !reflect.DeepEqual(diff.ToolCallsOnlyInCandidate, []string{"search_issues"}) {
+		t.Fatalf("unexpected candidate-only tools: %v", diff.ToolCallsOnlyInCandidate)
+	}
+	if diff.DurationDelta != result.Candidate.Duration-result.Baseline.Duration {
+		t.Fatalf("duration delta mismatch")
+	}
+}
+
+func TestAnswerSimilarityIdentical(t *testing.T) {
+	if got := answerSimilarity("Hello, World", "hello world"); got != 1 {
+		t.Fatalf("expected similarity 1, got %v", got)
+	}
+}
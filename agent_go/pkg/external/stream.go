@@ -0,0 +1,108 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"mcp-agent/agent_go/pkg/mcpagent"
+)
+
+// StreamErrorPrefix starts the last chunk of an InvokeStream channel when the run failed;
+// use StreamError to tell it apart from response text
+const StreamErrorPrefix = "\x00stream-error: "
+
+// StreamError returns the error carried by an InvokeStream chunk, or nil for response text
+func StreamError(chunk string) error {
+	if !strings.HasPrefix(chunk, StreamErrorPrefix) {
+		return nil
+	}
+	return errors.New(strings.TrimPrefix(chunk, StreamErrorPrefix))
+}
+
+// InvokeStream sends a prompt to the agent and streams the response text as the LLM generates it.
+// Tokens are batched into chunks of at least StreamChunkSize bytes (0 forwards every token). The
+// channel closes when the run completes; a failed run ends with a chunk for which StreamError is non-nil.
+// When the provider does not stream, the full response arrives as a single chunk.
+func (a *agentImpl) InvokeStream(ctx context.Context, prompt string) (<-chan string, error) {
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("context cancelled before invoking: %w", ctx.Err())
+	}
+
+	chunks := make(chan string, 50)
+	stream := &chunkBuffer{size: a.config.StreamChunkSize, out: chunks, done: ctx.Done()}
+
+	go func() {
+		defer close(chunks)
+
+		response, messages, err := a.agent.AskWithHistory(mcpagent.WithTokenStream(ctx, stream.write), a.continueHistory(prompt))
+		if err != nil {
+			stream.flush()
+			stream.send(StreamErrorPrefix + err.Error())
+			return
+		}
+		a.recordHistory(messages)
+		if !stream.streamed() {
+			// The provider returned the response without streaming it
+			stream.send(response)
+			return
+		}
+		stream.flush()
+	}()
+
+	return chunks, nil
+}
+
+// chunkBuffer batches streamed tokens into chunks of at least size bytes
+type chunkBuffer struct {
+	size int
+	out  chan<- string
+	done <-chan struct{}
+
+	mu      sync.Mutex
+	pending strings.Builder
+	sent    bool
+}
+
+func (b *chunkBuffer) write(token string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending.WriteString(token)
+	if b.pending.Len() >= b.size && b.pending.Len() > 0 {
+		b.flushLocked()
+	}
+}
+
+func (b *chunkBuffer) flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending.Len() > 0 {
+		b.flushLocked()
+	}
+}
+
+func (b *chunkBuffer) flushLocked() {
+	chunk := b.pending.String()
+	b.pending.Reset()
+	b.sent = true
+	b.send(chunk)
+}
+
+func (b *chunkBuffer) streamed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sent || b.pending.Len() > 0
+}
+
+// send delivers chunk unless the caller's context is done
+func (b *chunkBuffer) send(chunk string) {
+	if chunk == "" {
+		return
+	}
+	select {
+	case b.out <- chunk:
+	case <-b.done:
+	}
+}
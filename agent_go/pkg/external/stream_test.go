@@ -0,0 +1,104 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/logger"
+	"mcp-agent/agent_go/pkg/mcpagent"
+)
+
+// chunkingLLM streams its answer as three chunks when a streaming callback is set
+type chunkingLLM struct {
+	chunks []string
+	err    error
+}
+
+func (l *chunkingLLM) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	opts := &llmtypes.CallOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+	full := ""
+	for _, chunk := range l.chunks {
+		if opts.StreamingFunc != nil {
+			opts.StreamingFunc(chunk)
+		}
+		full += chunk
+	}
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: full}}}, nil
+}
+
+func newStreamTestAgent(t *testing.T, llm llmtypes.Model, chunkSize int) *agentImpl {
+	t.Helper()
+	t.Setenv("BEDROCK_FALLBACK_MODELS", "")
+	t.Setenv("OPENAI_FALLBACK_MODELS", "")
+	testLogger := logger.CreateDiscardLogger("error")
+	return &agentImpl{
+		agent:  &mcpagent.Agent{LLM: llm, ModelID: "test-model", Logger: testLogger, AgentMode: mcpagent.SimpleAgent, MaxTurns: 2},
+		config: Config{StreamChunkSize: chunkSize},
+	}
+}
+
+func collectStream(t *testing.T, a *agentImpl) []string {
+	t.Helper()
+	stream, err := a.InvokeStream(context.Background(), "say hello")
+	if err != nil {
+		t.Fatalf("InvokeStream: %v", err)
+	}
+	var chunks []string
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func TestInvokeStreamForwardsEachChunk(t *testing.T) {
+	a := newStreamTestAgent(t, &chunkingLLM{chunks: []string{"Hel", "lo, ", "world"}}, 0)
+
+	chunks := collectStream(t, a)
+	if want := []string{"Hel", "lo, ", "world"}; !reflect.DeepEqual(chunks, want) {
+		t.Fatalf("chunks = %q, want %q", chunks, want)
+	}
+}
+
+func TestInvokeStreamBatchesByChunkSize(t *testing.T) {
+	a := newStreamTestAgent(t, &chunkingLLM{chunks: []string{"Hel", "lo, ", "world"}}, 6)
+
+	// "Hel"+"lo, " reaches 6 bytes; the rest is flushed on completion
+	chunks := collectStream(t, a)
+	if want := []string{"Hello, ", "world"}; !reflect.DeepEqual(chunks, want) {
+		t.Fatalf("chunks = %q, want %q", chunks, want)
+	}
+}
+
+func TestInvokeStreamEndsWithErrorSentinel(t *testing.T) {
+	a := newStreamTestAgent(t, &chunkingLLM{err: errors.New("invalid_api_key: Incorrect API key provided")}, 0)
+
+	chunks := collectStream(t, a)
+	if len(chunks) != 1 {
+		t.Fatalf("expected only the error chunk, got %q", chunks)
+	}
+	if err := StreamError(chunks[0]); err == nil {
+		t.Fatalf("expected the last chunk to carry the error, got %q", chunks[0])
+	}
+	if StreamError("Hello") != nil {
+		t.Error("response text must not be reported as an error")
+	}
+}
+
+func TestInvokeStreamRejectsCancelledContext(t *testing.T) {
+	a := newStreamTestAgent(t, &chunkingLLM{chunks: []string{"unused"}}, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := a.InvokeStream(ctx, "say hello"); err == nil {
+		t.Fatal("expected an error for a cancelled context")
+	}
+}
@@ -2,9 +2,24 @@ package external
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
+
+	"mcp-agent/agent_go/pkg/mcpagent"
 )
 
+// builtinPlaceholders are filled by the agent from its tools, prompts and resources
+var builtinPlaceholders = []string{
+	"{{TOOLS}}",
+	"{{PROMPTS_SECTION}}",
+	"{{RESOURCES_SECTION}}",
+	"{{VIRTUAL_TOOLS_SECTION}}",
+}
+
+// placeholderPattern matches {{NAME}} template placeholders
+var placeholderPattern = regexp.MustCompile(`\{\{([A-Za-z0-9_]+)\}\}`)
+
 // SystemPromptTemplates contains predefined system prompt templates
 var SystemPromptTemplates = map[string]string{
 	"simple": `You are a helpful AI assistant with access to various tools and resources.
@@ -149,11 +164,23 @@ func BuildSystemPrompt(config SystemPromptConfig, toolsSection, promptsSection,
 		template = SystemPromptTemplates["simple"]
 	}
 
-	// Replace placeholders
-	prompt := strings.ReplaceAll(template, "{{TOOLS}}", toolsSection)
-	prompt = strings.ReplaceAll(prompt, "{{PROMPTS_SECTION}}", promptsSection)
-	prompt = strings.ReplaceAll(prompt, "{{RESOURCES_SECTION}}", resourcesSection)
-	prompt = strings.ReplaceAll(prompt, "{{VIRTUAL_TOOLS_SECTION}}", virtualToolsSection)
+	// Built-in sections and custom per-request variables, then custom named sections
+	data := map[string]string{
+		"TOOLS":                 toolsSection,
+		"PROMPTS_SECTION":       promptsSection,
+		"RESOURCES_SECTION":     resourcesSection,
+		"VIRTUAL_TOOLS_SECTION": virtualToolsSection,
+	}
+	for name, value := range config.Variables {
+		if _, builtin := data[name]; !builtin {
+			data[name] = value
+		}
+	}
+	promptTemplate := mcpagent.NewSystemPromptTemplate(template)
+	for name, section := range config.Sections {
+		promptTemplate.RegisterSection(name, section)
+	}
+	prompt := promptTemplate.Render(data)
 
 	// Add additional instructions if provided
 	if config.AdditionalInstructions != "" {
@@ -203,6 +230,17 @@ func ValidateSystemPromptConfig(config SystemPromptConfig) error {
 		if err := ValidateCustomTemplate(config.CustomTemplate); err != nil {
 			return fmt.Errorf("custom template validation failed: %w", err)
 		}
+		// Custom sections define placeholders just like variables
+		defined := make(map[string]string, len(config.Variables)+len(config.Sections))
+		for name, value := range config.Variables {
+			defined[name] = value
+		}
+		for name := range config.Sections {
+			defined[name] = ""
+		}
+		if err := ValidateTemplateVariables(config.CustomTemplate, defined); err != nil {
+			return fmt.Errorf("custom template validation failed: %w", err)
+		}
 	}
 
 	return nil
@@ -210,12 +248,7 @@ func ValidateSystemPromptConfig(config SystemPromptConfig) error {
 
 // ValidateCustomTemplate ensures the custom template includes required placeholders
 func ValidateCustomTemplate(template string) error {
-	requiredPlaceholders := []string{
-		"{{TOOLS}}",
-		"{{PROMPTS_SECTION}}",
-		"{{RESOURCES_SECTION}}",
-		"{{VIRTUAL_TOOLS_SECTION}}",
-	}
+	requiredPlaceholders := builtinPlaceholders
 
 	var missingPlaceholders []string
 	for _, placeholder := range requiredPlaceholders {
@@ -231,3 +264,35 @@ func ValidateCustomTemplate(template string) error {
 
 	return nil
 }
+
+// ValidateTemplateVariables ensures every {{NAME}} placeholder in the template is either
+// a built-in placeholder or a provided custom variable
+func ValidateTemplateVariables(template string, vars map[string]string) error {
+	var undefined []string
+	seen := make(map[string]bool)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		placeholder, name := match[0], match[1]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		isBuiltin := false
+		for _, builtin := range builtinPlaceholders {
+			if placeholder == builtin {
+				isBuiltin = true
+				break
+			}
+		}
+		if _, ok := vars[name]; !isBuiltin && !ok {
+			undefined = append(undefined, name)
+		}
+	}
+
+	if len(undefined) > 0 {
+		sort.Strings(undefined)
+		return fmt.Errorf("custom template references undefined variables: %v", undefined)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package external
+
+import (
+	"strings"
+	"testing"
+)
+
+const varsTestTemplate = `Hello {{USER_NAME}} from {{TENANT}}. Today is {{DATE}}.
+{{TOOLS}}
+{{PROMPTS_SECTION}}
+{{RESOURCES_SECTION}}
+{{VIRTUAL_TOOLS_SECTION}}`
+
+func TestBuildSystemPromptRendersCustomVariables(t *testing.T) {
+	config := SystemPromptConfig{
+		Mode:           "custom",
+		CustomTemplate: varsTestTemplate,
+		Variables: map[string]string{
+			"USER_NAME": "Ada",
+			"TENANT":    "acme",
+			"DATE":      "2026-10-15",
+		},
+	}
+	if err := ValidateSystemPromptConfig(config); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+
+	prompt := BuildSystemPrompt(config, "tool-list", "prompt-list", "resource-list", "virtual-list")
+
+	for _, want := range []string{"Hello Ada from acme. Today is 2026-10-15.", "tool-list", "prompt-list", "resource-list", "virtual-list"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "{{") {
+		t.Errorf("expected all placeholders rendered, got:\n%s", prompt)
+	}
+}
+
+func TestValidateSystemPromptConfigRejectsUndefinedVariables(t *testing.T) {
+	config := SystemPromptConfig{
+		Mode:           "custom",
+		CustomTemplate: varsTestTemplate,
+		Variables:      map[string]string{"USER_NAME": "Ada"},
+	}
+
+	err := ValidateSystemPromptConfig(config)
+	if err == nil {
+		t.Fatal("expected error for undefined variables")
+	}
+	if !strings.Contains(err.Error(), "[DATE TENANT]") {
+		t.Errorf("expected undefined DATE and TENANT to be reported, got %v", err)
+	}
+}
+
+func TestAgentBuilderWithSystemPromptVarsCopiesMap(t *testing.T) {
+	vars := map[string]string{"USER_NAME": "Ada"}
+	builder := NewAgentBuilder().WithCustomSystemPrompt(varsTestTemplate).WithSystemPromptVars(vars)
+	vars["USER_NAME"] = "Grace"
+
+	if got := builder.systemPrompt.Variables["USER_NAME"]; got != "Ada" {
+		t.Errorf("expected builder to keep its own copy of vars, got %q", got)
+	}
+}
+
+func TestBuildSystemPromptRendersCustomSections(t *testing.T) {
+	policy := "Never share customer data."
+	config := SystemPromptConfig{
+		Mode:           "custom",
+		CustomTemplate: "{{COMPANY_POLICY}}\n{{TOOLS}}\n{{PROMPTS_SECTION}}\n{{RESOURCES_SECTION}}\n{{VIRTUAL_TOOLS_SECTION}}",
+		Sections: map[string]func() string{
+			"COMPANY_POLICY": func() string { return policy },
+		},
+	}
+	if err := ValidateSystemPromptConfig(config); err != nil {
+		t.Fatalf("expected sections to define their placeholders, got %v", err)
+	}
+
+	prompt := BuildSystemPrompt(config, "- search: Search the web", "", "", "")
+	if !strings.HasPrefix(prompt, "Never share customer data.\n- search: Search the web") {
+		t.Errorf("expected the policy section followed by the tool listing, got:\n%s", prompt)
+	}
+
+	// Sections are evaluated each time the prompt is built
+	policy = "Escalate refunds over $500."
+	if prompt := BuildSystemPrompt(config, "", "", "", ""); !strings.Contains(prompt, policy) {
+		t.Errorf("expected the updated policy, got:\n%s", prompt)
+	}
+}
@@ -0,0 +1,21 @@
+package external
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/mcpagent"
+)
+
+func TestBuildRejectsInvalidToolPolicy(t *testing.T) {
+	builder := NewAgentBuilder().WithToolPolicy(mcpagent.ToolPolicy{Denylist: []string{"delete_*", "aws:["}})
+	if builder.toolPolicy == nil || len(builder.toolPolicy.Denylist) != 2 {
+		t.Fatalf("expected the policy on the builder, got %+v", builder.toolPolicy)
+	}
+
+	_, err := builder.Build(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `invalid tool policy pattern "aws:["`) {
+		t.Fatalf("expected the malformed pattern to fail the build, got %v", err)
+	}
+}
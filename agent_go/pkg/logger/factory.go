@@ -121,6 +121,16 @@ func CreateTestLogger(logFile string, level string) Logger {
 	return logger
 }
 
+// CreateDiscardLogger creates a logger that drops all output, for tests that must not write log files
+func CreateDiscardLogger(level string) Logger {
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(io.Discard)
+	if logLevel, err := logrus.ParseLevel(level); err == nil {
+		logrusLogger.SetLevel(logLevel)
+	}
+	return Logger{logger: logrusLogger}
+}
+
 // CreateDefaultLogger creates logger with sensible defaults
 func CreateDefaultLogger() Logger {
 	return CreateTestLogger("logs/default.log", "info")
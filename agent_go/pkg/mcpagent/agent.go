@@ -3,6 +3,7 @@ package mcpagent
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"strings"
 	"sync"
 	"time"
@@ -101,6 +102,14 @@ func WithToolTimeout(timeout time.Duration) AgentOption {
 	}
 }
 
+// WithToolTimeouts overrides the tool execution timeout of individual tools by name (e.g. a short
+// timeout for web search, a long one for builds); other tools use the ToolTimeout default
+func WithToolTimeouts(timeouts map[string]time.Duration) AgentOption {
+	return func(a *Agent) {
+		a.ToolTimeouts = timeouts
+	}
+}
+
 // WithCustomTools adds custom tools to the agent during creation
 func WithCustomTools(tools []llmtypes.Tool) AgentOption {
 	return func(a *Agent) {
@@ -210,10 +219,108 @@ type Agent struct {
 	Temperature     float64
 	ToolChoice      string
 	ModelID         string
-	AgentMode       AgentMode     // NEW: Agent mode (Simple or ReAct)
-	ToolTimeout     time.Duration // Tool execution timeout (default: 5 minutes)
-	selectedTools   []string      // Selected tools in "server:tool" format
-	selectedServers []string      // Selected servers list for "all tools" mode determination
+	AgentMode       AgentMode                // NEW: Agent mode (Simple or ReAct)
+	ToolTimeout     time.Duration            // Tool execution timeout (default: 5 minutes)
+	ToolTimeouts    map[string]time.Duration // Per-tool timeout overrides by tool name
+	selectedTools   []string                 // Selected tools in "server:tool" format
+	selectedServers []string                 // Selected servers list for "all tools" mode determination
+
+	// Per-request cap on connected MCP servers (0 = unlimited)
+	maxServers           int
+	serverSelectionQuery string
+	// Server selection events recorded by NewAgent, emitted on the first run once listeners are attached
+	pendingServerSelections []*events.MCPServerSelectionEvent
+
+	// Handling of servers exposing identical tool sets (see WithServerDedupPolicy)
+	serverDedupPolicy ServerDedupPolicy
+	serverToolSets    serverToolSetLookup // nil reads tool sets from the tool cache
+
+	// One-shot tool usage examples injected into the system prompt (see WithToolExamples)
+	includeToolExamples  bool
+	toolExampleStore     map[string]string
+	toolExamplesMaxChars int
+
+	// Per-turn latency breakdown (see WithTurnLatencyBreakdown), aggregated per conversation
+	turnLatencyDisabled bool
+	latencySummary      LatencySummary
+	latencyMu           sync.Mutex
+
+	// Token and cost roll-up of the current conversation (see WithCostEstimator)
+	costEstimator   CostEstimator
+	costSummary     *events.CostSummary
+	costSummaryOnce sync.Once
+
+	// Return the raw text answer instead of an error when structured conversion fails (see WithStructuredOutputRawFallback)
+	structuredRawFallback bool
+
+	// Resume truncated structured output from the partial object (see WithStructuredOutputResume)
+	structuredMaxResumes int
+
+	// Structured output attempts before giving up on validation errors (see WithStructuredOutputMaxAttempts)
+	structuredMaxAttempts int
+
+	// Model converting answers to structured output (see WithStructuredOutputLLM); nil uses LLM
+	structuredOutputLLM llmtypes.Model
+
+	// Re-authenticates expired LLM credentials before retrying (see WithSecretProvider); nil disables
+	secretProvider SecretProvider
+	// Rebuilds the LLM client after a credential refresh; nil uses createFallbackLLM
+	llmRebuilder func(modelID string) (llmtypes.Model, error)
+
+	// Context window per model ID for pre-emptive large-context model selection (see WithContextWindowModels)
+	contextWindowModels map[string]int
+	contextModelFactory func(modelID string) (llmtypes.Model, error) // nil uses createFallbackLLM
+
+	// Oldest turns dropped before a call that would overflow the context window (see WithHistoryCompaction)
+	historyCompaction          bool
+	historyCompactionThreshold float64
+	historyCompactionKeepTurns int
+
+	// Inline workspace files referenced by tool results (see WithWorkspaceFileReferences)
+	workspaceFileRoot     string
+	workspaceFileMaxBytes int
+
+	// Per-run record of advertised vs invoked tools (see WithToolUsageRecorder)
+	toolUsageRecorder ToolUsageRecorder
+
+	// Smart routing decisions reused for similar queries (see WithRoutingCache)
+	routingCache *RoutingCache
+
+	// Multi-step tool transactions with compensating rollback (see WithToolTransactions)
+	toolTransactions  bool
+	toolCompensations map[string]CompensationFunc
+	transactionState  toolTransactionState
+
+	// Alternate tools called after repeated failures (see WithToolAlternate)
+	toolAlternates         map[string]string
+	toolAlternateThreshold int
+	toolFailures           toolFailureTracker
+
+	// Identical tool calls within a turn reuse the earlier result (see WithToolDeduplication)
+	toolDeduplication bool
+
+	// Allowlist/denylist checked before every tool call (see WithToolPolicy); nil uses DefaultToolPolicy
+	toolPolicy *ToolPolicy
+
+	// Tools that wait for human approval before running (see WithConfirmTools)
+	confirmTools   []string
+	confirmTimeout time.Duration
+	confirmStore   ToolConfirmationStore
+
+	// Hooks run before and after every tool call (see WithToolInterceptors)
+	toolInterceptors []ToolInterceptor
+
+	// Runner of a custom agent mode, created on first use (see RegisterAgentMode)
+	modeRunner AgentRunner
+
+	// Permission error handling (see WithToolReadOnlyFallback)
+	readOnlyFallbacks           map[string]string
+	permissionErrorPatterns     []string
+	permissionDetectionDisabled bool
+
+	// Canonical language tool arguments are translated to before execution (see WithToolArgTranslation); "" disables
+	toolArgLanguage   string
+	toolArgTranslator ToolArgTranslator // nil translates with the agent's LLM
 
 	// Enhanced tracking info
 	SystemPrompt string
@@ -230,6 +337,9 @@ type Agent struct {
 
 	// Large tool output handling
 	toolOutputHandler *utils.ToolOutputHandler
+	// Byte threshold and handling of large tool outputs (see WithLargeOutputThreshold, WithLargeOutputPolicy)
+	largeOutputThreshold int
+	largeOutputPolicy    LargeOutputPolicy
 
 	// Large output virtual tools configuration
 	EnableLargeOutputVirtualTools bool
@@ -254,6 +364,10 @@ type Agent struct {
 	listeners []AgentEventListener
 	mu        sync.RWMutex
 
+	// Optional audit sink for raw LLM requests/responses (see WithLLMAuditSink / LLM_AUDIT_DIR)
+	llmAuditSink      LLMAuditSink
+	llmAuditRedaction *RedactionPolicy
+
 	// Smart routing configuration with defaults
 	EnableSmartRouting    bool
 	SmartRoutingThreshold struct {
@@ -292,7 +406,28 @@ type Agent struct {
 	DiscoverPrompt bool // If true, include prompt details in system prompt (default: true)
 
 	// Cross-provider fallback configuration
-	CrossProviderFallback *CrossProviderFallback // Cross-provider fallback configuration from frontend
+	CrossProviderFallback *CrossProviderFallback                       // Cross-provider fallback configuration from frontend
+	fallbackExclusions    FallbackExclusions                           // Providers/models the fallback chain must skip
+	fallbackLLMFactory    func(modelID string) (llmtypes.Model, error) // nil initializes fallbacks with llm.InitializeLLM
+
+	// Retry budget and delays of throttled LLM calls (see WithRetryConfig); zero fields use the defaults
+	RetryConfig RetryConfig
+	retrySleep  func(ctx context.Context, delay time.Duration) error // nil waits on a timer
+	retryRand   *rand.Rand                                           // Source of retry jitter; nil uses the global source
+
+	// Output token cap and stop sequences of every LLM call (see WithMaxOutputTokens, WithStopSequences)
+	MaxOutputTokens int
+	StopSequences   []string
+
+	// Structured-LLM recap of the run attached to completion events (see WithRunSummary)
+	runSummaryEnabled bool
+
+	// Classifies failed LLM calls for the retry loop (see WithErrorClassifier); nil uses the built-in rules
+	errorClassifier *ErrorClassifier
+
+	// Background ping and reconnect of the MCP servers (see WithServerHealthCheck)
+	healthCheckConfig mcpclient.HealthCheckConfig
+	healthChecker     *mcpclient.HealthChecker
 }
 
 // CrossProviderFallback represents cross-provider fallback configuration
@@ -425,6 +560,12 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, serverName, configPath, m
 		option(ag)
 	}
 
+	// Skip servers exposing the same tools as another one when a dedup policy is configured
+	serverName = ag.applyServerDedup(config, serverName)
+
+	// Connect only to the top-priority servers when a max servers cap is configured
+	serverName = ag.applyMaxServersCap(config, serverName)
+
 	// 🆕 DETAILED AGENT CONNECTION DEBUG LOGGING
 	logger.Infof("🤖 [DEBUG] About to call NewAgentConnection - Time: %v", time.Now())
 	logger.Infof("🤖 [DEBUG] NewAgentConnection params - ServerName: %s, ConfigPath: %s, CacheOnly: %v", serverName, configPath, ag.CacheOnly)
@@ -539,10 +680,12 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, serverName, configPath, m
 	if !ag.hasCustomSystemPrompt {
 		ag.SystemPrompt = prompt.BuildSystemPromptWithoutTools(ag.prompts, ag.resources, string(ag.AgentMode), ag.DiscoverResource, ag.DiscoverPrompt, ag.Logger)
 	}
+	ag.applyToolExamples()
 
 	// Add virtual tools to the LLM tools list
 	virtualTools := ag.CreateVirtualTools()
 	ag.Tools = append(ag.Tools, virtualTools...)
+	ag.registerTransactionTools()
 
 	// 🎯 SMART ROUTING INITIALIZATION - Run AFTER all tools are loaded (including virtual tools)
 	// This ensures we have the complete tool count for accurate smart routing decisions
@@ -605,6 +748,9 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, serverName, configPath, m
 			len(ag.Tools), serverType, serverCount, ag.SmartRoutingThreshold.MaxTools, ag.SmartRoutingThreshold.MaxServers)
 	}
 
+	// Keep the connected servers alive when a health check is configured
+	ag.startServerHealthCheck()
+
 	// No more event listeners - events go directly to tracer
 	// Langfuse tracing is handled by the tracer itself
 
@@ -676,6 +822,7 @@ func (a *Agent) StartLLMGeneration(ctx context.Context) {
 func (a *Agent) EndLLMGeneration(ctx context.Context, result string, turn int, toolCalls int, duration time.Duration, usageMetrics events.UsageMetrics) {
 	// Emit LLM generation end event to close hierarchy
 	llmEndEvent := events.NewLLMGenerationEndEvent(turn, result, toolCalls, duration, usageMetrics)
+	llmEndEvent.TurnSummary = true
 	a.EmitTypedEvent(ctx, llmEndEvent)
 }
 
@@ -758,6 +905,7 @@ func (a *Agent) RebuildSystemPromptWithFilteredServers(ctx context.Context, rele
 
 	// Update the agent's system prompt
 	a.SystemPrompt = newSystemPrompt
+	a.applyToolExamples()
 
 	logger.Info("✅ System prompt rebuilt with filtered servers", map[string]interface{}{
 		"filtered_prompts_count":   len(filteredPrompts),
@@ -855,6 +1003,9 @@ func NewAgentWithObservability(ctx context.Context, llm llmtypes.Model, serverNa
 		option(ag)
 	}
 
+	// Keep the connected servers alive when a health check is configured
+	ag.startServerHealthCheck()
+
 	// No more event listeners - events go directly to tracer
 	// Tracing is handled by the tracer itself based on TRACING_PROVIDER
 
@@ -940,6 +1091,10 @@ func (a *Agent) initializeHierarchyForContext(ctx context.Context) {
 
 // EmitTypedEvent sends a typed event to all tracers AND all listeners
 func (a *Agent) EmitTypedEvent(ctx context.Context, eventData events.EventData) {
+	// Attach actionable remediation hints to error events with a known cause
+	events.EnrichWithRemediation(eventData)
+	// Tag the event with the request's tags (e.g. its experiment variant)
+	events.ApplyEventTags(ctx, eventData)
 
 	// ✅ SET HIERARCHY FIELDS ON EVENT DATA FIRST (SINGLE SOURCE OF TRUTH)
 	// Use interface-based approach - works for ALL event types that embed BaseEventData
@@ -1021,6 +1176,11 @@ func (a *Agent) EmitTypedEvent(ctx context.Context, eventData events.EventData)
 			a.Logger.Warnf("Failed to emit event to listener %T: %v", listener, err)
 		}
 	}
+
+	// Follow the completion of a conversation with its token and cost roll-up
+	if _, ok := eventData.(*events.UnifiedCompletionEvent); ok {
+		a.emitCostSummary(ctx)
+	}
 }
 
 // isStartOrEndEvent checks if an event type is a start or end event that needs correlation ID
@@ -1082,6 +1242,9 @@ func getClientNames(clients map[string]mcpclient.ClientInterface) []string {
 
 // Close closes all underlying MCP client connections.
 func (a *Agent) Close() {
+	// Stop the health checker first so it does not reconnect the clients being closed
+	a.stopServerHealthCheck()
+
 	// Close all clients in the map
 	for serverName, client := range a.Clients {
 		if client != nil {
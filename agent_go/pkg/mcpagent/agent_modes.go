@@ -0,0 +1,105 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// AgentRunner is a custom execution strategy for an agent mode. Run replaces the built-in
+// conversation loop: it gets the agent (for its LLM, tools and events) and the message history,
+// and returns the final answer and the updated history.
+type AgentRunner interface {
+	Run(ctx context.Context, a *Agent, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error)
+}
+
+// AgentRunnerFunc adapts a function to the AgentRunner interface
+type AgentRunnerFunc func(ctx context.Context, a *Agent, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error)
+
+// Run calls f
+func (f AgentRunnerFunc) Run(ctx context.Context, a *Agent, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error) {
+	return f(ctx, a, messages)
+}
+
+// AgentModeFactory creates the runner for an agent using a custom mode. It is called once per
+// agent, on its first conversation.
+type AgentModeFactory func(a *Agent) (AgentRunner, error)
+
+var (
+	agentModesMu sync.RWMutex
+	agentModes   = make(map[AgentMode]AgentModeFactory)
+)
+
+// RegisterAgentMode registers a custom agent mode. Agents created WithMode(name) run their
+// conversations through the factory's runner instead of the built-in loop. The built-in simple
+// and ReAct modes cannot be replaced; registering a name again replaces the earlier factory.
+func RegisterAgentMode(name AgentMode, factory AgentModeFactory) error {
+	if name == "" {
+		return fmt.Errorf("agent mode name is required")
+	}
+	if name == SimpleAgent || name == ReActAgent {
+		return fmt.Errorf("agent mode %q is built in and cannot be registered", name)
+	}
+	if factory == nil {
+		return fmt.Errorf("agent mode %q requires a factory", name)
+	}
+	agentModesMu.Lock()
+	defer agentModesMu.Unlock()
+	agentModes[name] = factory
+	return nil
+}
+
+// UnregisterAgentMode removes a custom agent mode
+func UnregisterAgentMode(name AgentMode) {
+	agentModesMu.Lock()
+	defer agentModesMu.Unlock()
+	delete(agentModes, name)
+}
+
+// IsRegisteredAgentMode reports whether name is a registered custom agent mode
+func IsRegisteredAgentMode(name AgentMode) bool {
+	_, ok := lookupAgentMode(name)
+	return ok
+}
+
+// RegisteredAgentModes returns the names of the registered custom agent modes, sorted
+func RegisteredAgentModes() []string {
+	agentModesMu.RLock()
+	defer agentModesMu.RUnlock()
+	names := make([]string, 0, len(agentModes))
+	for name := range agentModes {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupAgentMode(name AgentMode) (AgentModeFactory, bool) {
+	agentModesMu.RLock()
+	defer agentModesMu.RUnlock()
+	factory, ok := agentModes[name]
+	return factory, ok
+}
+
+// customModeRunner returns the runner for the agent's mode, or nil when the mode is built in
+func (a *Agent) customModeRunner() (AgentRunner, error) {
+	if a.modeRunner != nil {
+		return a.modeRunner, nil
+	}
+	factory, ok := lookupAgentMode(a.AgentMode)
+	if !ok {
+		return nil, nil
+	}
+	runner, err := factory(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create runner for agent mode %q: %w", a.AgentMode, err)
+	}
+	if runner == nil {
+		return nil, fmt.Errorf("agent mode %q factory returned no runner", a.AgentMode)
+	}
+	a.modeRunner = runner
+	return runner, nil
+}
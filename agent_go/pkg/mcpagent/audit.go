@@ -0,0 +1,228 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// LLMAuditRecord is the raw request/response of a single LLM generation, persisted for audit and replay
+type LLMAuditRecord struct {
+	SessionID   string                    `json:"session_id"`
+	TraceID     string                    `json:"trace_id"`
+	Turn        int                       `json:"turn"`
+	Timestamp   time.Time                 `json:"timestamp"`
+	Duration    time.Duration             `json:"duration"`
+	Provider    string                    `json:"provider"`
+	ModelID     string                    `json:"model_id"`
+	Messages    []llmtypes.MessageContent `json:"messages"`
+	Options     LLMAuditOptions           `json:"options"`
+	Response    *llmtypes.ContentResponse `json:"response,omitempty"`
+	Error       string                    `json:"error,omitempty"`
+	RedactCount int                       `json:"-"` // Number of values replaced by the redaction policy
+}
+
+// LLMAuditOptions is the serializable subset of llmtypes.CallOptions
+type LLMAuditOptions struct {
+	Model       string               `json:"model,omitempty"`
+	Temperature float64              `json:"temperature"`
+	MaxTokens   int                  `json:"max_tokens,omitempty"`
+	JSONMode    bool                 `json:"json_mode,omitempty"`
+	Tools       []string             `json:"tools,omitempty"` // Tool names only, definitions are static per agent
+	ToolChoice  *llmtypes.ToolChoice `json:"tool_choice,omitempty"`
+	Streaming   bool                 `json:"streaming,omitempty"`
+}
+
+// LLMAuditSink persists raw LLM requests/responses separately from the normal event flow
+type LLMAuditSink interface {
+	RecordLLMExchange(ctx context.Context, record []byte, meta *LLMAuditRecord) error
+}
+
+// RedactionPolicy controls which values are masked before an audit record is persisted
+type RedactionPolicy struct {
+	Patterns    []*regexp.Regexp
+	Replacement string
+}
+
+// DefaultRedactionPolicy masks common credentials (API keys, bearer tokens, AWS keys, passwords)
+func DefaultRedactionPolicy() *RedactionPolicy {
+	return &RedactionPolicy{
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`sk-[A-Za-z0-9_\-]{16,}`),
+			regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9_\-\.=]{16,}`),
+			regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+			regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{20,}`),
+			regexp.MustCompile(`(?i)(password|passwd|secret|api[_-]?key|access[_-]?token)(\\?"?\s*[:=]\s*\\?"?)[^\s"\\,}]+`),
+		},
+		Replacement: "[REDACTED]",
+	}
+}
+
+// Redact applies the policy to serialized record data and returns the redacted data with the number of replacements
+func (p *RedactionPolicy) Redact(data string) (string, int) {
+	if p == nil {
+		return data, 0
+	}
+	count := 0
+	for _, pattern := range p.Patterns {
+		data = pattern.ReplaceAllStringFunc(data, func(match string) string {
+			count++
+			// Keep the key name for key/value patterns so the record stays readable
+			if sub := pattern.FindStringSubmatch(match); len(sub) == 3 {
+				return sub[1] + sub[2] + p.Replacement
+			}
+			return p.Replacement
+		})
+	}
+	return data, count
+}
+
+// FileAuditSink appends audit records as JSON lines to one file per session
+type FileAuditSink struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileAuditSink creates a file audit sink writing to dir/<session_id>.jsonl
+func NewFileAuditSink(dir string) (*FileAuditSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory %s: %w", dir, err)
+	}
+	return &FileAuditSink{dir: dir}, nil
+}
+
+// RecordLLMExchange appends the record to the session's audit file
+func (s *FileAuditSink) RecordLLMExchange(ctx context.Context, record []byte, meta *LLMAuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := filepath.Join(s.dir, sanitizeAuditFileName(meta.SessionID)+".jsonl")
+	//nolint:gosec // G304: path is built from the configured audit directory and a sanitized session ID
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(record, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// sanitizeAuditFileName keeps session IDs safe to use as file names
+func sanitizeAuditFileName(name string) string {
+	if name == "" {
+		return "unknown_session"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == '.' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// WithLLMAuditSink persists every LLM request/response through the sink, redacted with the given policy
+// (DefaultRedactionPolicy when nil)
+func WithLLMAuditSink(sink LLMAuditSink, policy *RedactionPolicy) AgentOption {
+	return func(a *Agent) {
+		a.llmAuditSink = sink
+		if policy == nil {
+			policy = DefaultRedactionPolicy()
+		}
+		a.llmAuditRedaction = policy
+	}
+}
+
+// getLLMAuditSink returns the configured audit sink, falling back to a file sink
+// in LLM_AUDIT_DIR when set. Returns nil when auditing is disabled.
+func getLLMAuditSink(a *Agent) LLMAuditSink {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.llmAuditSink != nil {
+		return a.llmAuditSink
+	}
+
+	auditDir := os.Getenv("LLM_AUDIT_DIR")
+	if auditDir == "" {
+		return nil
+	}
+
+	sink, err := NewFileAuditSink(auditDir)
+	if err != nil {
+		getLogger(a).Warnf("⚠️ LLM audit disabled: %v", err)
+		return nil
+	}
+	a.llmAuditSink = sink
+	if a.llmAuditRedaction == nil {
+		a.llmAuditRedaction = DefaultRedactionPolicy()
+	}
+	return sink
+}
+
+// recordLLMAudit builds, redacts and persists the audit record for one generation.
+// Audit failures are logged and never affect the generation result.
+func recordLLMAudit(a *Agent, ctx context.Context, sink LLMAuditSink, turn int, messages []llmtypes.MessageContent, opts []llmtypes.CallOption, resp *llmtypes.ContentResponse, genErr error, start time.Time) {
+	callOpts := &llmtypes.CallOptions{}
+	for _, opt := range opts {
+		opt(callOpts)
+	}
+	toolNames := make([]string, 0, len(callOpts.Tools))
+	for _, tool := range callOpts.Tools {
+		if tool.Function != nil {
+			toolNames = append(toolNames, tool.Function.Name)
+		}
+	}
+
+	sessionID := string(a.TraceID)
+	if ctxSessionID, ok := ctx.Value("session_id").(string); ok && ctxSessionID != "" {
+		sessionID = ctxSessionID
+	}
+
+	record := &LLMAuditRecord{
+		SessionID: sessionID,
+		TraceID:   string(a.TraceID),
+		Turn:      turn,
+		Timestamp: start,
+		Duration:  time.Since(start),
+		Provider:  string(a.GetProvider()),
+		ModelID:   a.ModelID,
+		Messages:  messages,
+		Options: LLMAuditOptions{
+			Model:       callOpts.Model,
+			Temperature: callOpts.Temperature,
+			MaxTokens:   callOpts.MaxTokens,
+			JSONMode:    callOpts.JSONMode,
+			Tools:       toolNames,
+			ToolChoice:  callOpts.ToolChoice,
+			Streaming:   callOpts.StreamingFunc != nil,
+		},
+		Response: resp,
+	}
+	if genErr != nil {
+		record.Error = genErr.Error()
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		getLogger(a).Warnf("⚠️ Failed to serialize LLM audit record for turn %d: %v", turn, err)
+		return
+	}
+
+	redacted, count := a.llmAuditRedaction.Redact(string(data))
+	record.RedactCount = count
+
+	if err := sink.RecordLLMExchange(ctx, []byte(redacted), record); err != nil {
+		getLogger(a).Warnf("⚠️ Failed to persist LLM audit record for turn %d: %v", turn, err)
+	}
+}
@@ -0,0 +1,85 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// memoryAuditSink keeps audit records in memory
+type memoryAuditSink struct {
+	mu      sync.Mutex
+	records []string
+	metas   []*LLMAuditRecord
+}
+
+func (m *memoryAuditSink) RecordLLMExchange(ctx context.Context, record []byte, meta *LLMAuditRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, string(record))
+	m.metas = append(m.metas, meta)
+	return nil
+}
+
+func TestGenerateContentPersistsRedactedAuditRecordPerTurn(t *testing.T) {
+	sink := &memoryAuditSink{}
+	a := newTestAgent(t, answeringLLM("done"), WithLLMAuditSink(sink, nil))
+	ctx := context.WithValue(context.Background(), "session_id", "session-42")
+
+	for turn := 0; turn < 2; turn++ {
+		messages := []llmtypes.MessageContent{
+			llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "Use api_key=sk-abcdefghijklmnopqrstuvwxyz to call the API"),
+		}
+		if _, err, _ := GenerateContentWithRetry(a, ctx, messages, []llmtypes.CallOption{llmtypes.WithTemperature(0.2)}, turn, nil); err != nil {
+			t.Fatalf("generation failed: %v", err)
+		}
+	}
+
+	if len(sink.records) != 2 {
+		t.Fatalf("expected one audit record per turn, got %d", len(sink.records))
+	}
+	for turn, record := range sink.records {
+		if strings.Contains(record, "sk-abcdefghijklmnopqrstuvwxyz") {
+			t.Errorf("turn %d: secret was not redacted: %s", turn, record)
+		}
+		if !strings.Contains(record, "[REDACTED]") {
+			t.Errorf("turn %d: expected redaction marker in record", turn)
+		}
+
+		var decoded LLMAuditRecord
+		if err := json.Unmarshal([]byte(record), &decoded); err != nil {
+			t.Fatalf("turn %d: record is not valid JSON: %v", turn, err)
+		}
+		if decoded.SessionID != "session-42" || decoded.Turn != turn || decoded.Options.Temperature != 0.2 {
+			t.Errorf("turn %d: unexpected record keys: %+v", turn, decoded)
+		}
+		if sink.metas[turn].RedactCount == 0 {
+			t.Errorf("turn %d: expected redact count to be reported", turn)
+		}
+	}
+}
+
+func TestFileAuditSinkFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LLM_AUDIT_DIR", dir)
+
+	a := newTestAgent(t, answeringLLM("done"))
+	messages := []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hello")}
+	if _, err, _ := GenerateContentWithRetry(a, context.Background(), messages, nil, 0, nil); err != nil {
+		t.Fatalf("generation failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "trace-1.jsonl"))
+	if err != nil {
+		t.Fatalf("expected audit file for session: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Errorf("expected 1 audit line, got %d", lines)
+	}
+}
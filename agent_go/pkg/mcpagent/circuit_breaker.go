@@ -0,0 +1,227 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+const (
+	defaultModelCircuitWindow   = time.Minute
+	defaultModelCircuitCooldown = time.Minute
+)
+
+// CircuitBreakerConfig controls the per-model circuit breaker. A model whose calls fail
+// FailureThreshold times in a row within Window is skipped for Cooldown: new requests go straight
+// to the next fallback. After the cooldown one request tries the model again (half-open); success
+// closes the breaker, failure opens it for another cooldown. FailureThreshold 0 disables it.
+type CircuitBreakerConfig struct {
+	FailureThreshold int
+	Window           time.Duration
+	Cooldown         time.Duration
+}
+
+// CircuitBreakerConfigFromEnv reads MODEL_CIRCUIT_BREAKER_THRESHOLD,
+// MODEL_CIRCUIT_BREAKER_WINDOW_SECONDS and MODEL_CIRCUIT_BREAKER_COOLDOWN_SECONDS
+func CircuitBreakerConfigFromEnv() CircuitBreakerConfig {
+	config := CircuitBreakerConfig{
+		Window:   defaultModelCircuitWindow,
+		Cooldown: defaultModelCircuitCooldown,
+	}
+	if threshold, err := strconv.Atoi(os.Getenv("MODEL_CIRCUIT_BREAKER_THRESHOLD")); err == nil && threshold > 0 {
+		config.FailureThreshold = threshold
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("MODEL_CIRCUIT_BREAKER_WINDOW_SECONDS")); err == nil && seconds > 0 {
+		config.Window = time.Duration(seconds) * time.Second
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("MODEL_CIRCUIT_BREAKER_COOLDOWN_SECONDS")); err == nil && seconds > 0 {
+		config.Cooldown = time.Duration(seconds) * time.Second
+	}
+	return config
+}
+
+// ModelCircuitOpenError is returned when a model's breaker is open and no fallback is available
+type ModelCircuitOpenError struct {
+	ModelID string
+	Until   time.Time
+}
+
+func (e *ModelCircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for model %s is open until %s and no fallback model is available",
+		e.ModelID, e.Until.Format(time.RFC3339))
+}
+
+type modelCircuitState struct {
+	failures  []time.Time // Consecutive failures within the window
+	openUntil time.Time   // Zero while closed
+	trialAt   time.Time   // Start of the half-open trial request, zero when none is running
+}
+
+// modelCircuitBreaker is shared by all agents in the process, so one agent's failures spare the others
+type modelCircuitBreaker struct {
+	mu     sync.Mutex
+	config CircuitBreakerConfig
+	models map[string]*modelCircuitState
+	now    func() time.Time // nil uses time.Now
+}
+
+var modelCircuits = newModelCircuitBreaker(CircuitBreakerConfigFromEnv())
+
+func newModelCircuitBreaker(config CircuitBreakerConfig) *modelCircuitBreaker {
+	return &modelCircuitBreaker{
+		config: config,
+		models: make(map[string]*modelCircuitState),
+	}
+}
+
+// ConfigureModelCircuitBreaker replaces the circuit breaker settings and closes every breaker
+func ConfigureModelCircuitBreaker(config CircuitBreakerConfig) {
+	modelCircuits.mu.Lock()
+	defer modelCircuits.mu.Unlock()
+	if config.Window <= 0 {
+		config.Window = defaultModelCircuitWindow
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaultModelCircuitCooldown
+	}
+	modelCircuits.config = config
+	modelCircuits.models = make(map[string]*modelCircuitState)
+}
+
+func (b *modelCircuitBreaker) currentTime() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
+
+// isOpen reports whether requests to the model are being skipped, without claiming a half-open trial
+func (b *modelCircuitBreaker) isOpen(modelID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, exists := b.models[modelID]
+	return exists && b.currentTime().Before(state.openUntil)
+}
+
+// allow reports whether a request may be sent to the model. Once the cooldown is over, the first
+// caller gets the half-open trial and the others keep skipping the model until it reports back.
+func (b *modelCircuitBreaker) allow(modelID string) (bool, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, exists := b.models[modelID]
+	if !exists || state.openUntil.IsZero() {
+		return true, time.Time{}
+	}
+	now := b.currentTime()
+	if now.Before(state.openUntil) {
+		return false, state.openUntil
+	}
+	// A trial that never reported back (e.g. cancelled) is given up after a cooldown
+	if !state.trialAt.IsZero() && now.Sub(state.trialAt) < b.config.Cooldown {
+		return false, state.trialAt.Add(b.config.Cooldown)
+	}
+	state.trialAt = now
+	return true, time.Time{}
+}
+
+// recordFailure counts a failed call and opens the breaker at the threshold, or again when the
+// half-open trial failed. Returns true when this failure opened the breaker.
+func (b *modelCircuitBreaker) recordFailure(modelID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.config.FailureThreshold <= 0 {
+		return false
+	}
+	now := b.currentTime()
+	state, exists := b.models[modelID]
+	if !exists {
+		state = &modelCircuitState{}
+		b.models[modelID] = state
+	}
+	if !state.openUntil.IsZero() {
+		if now.Before(state.openUntil) {
+			return false
+		}
+		state.openUntil = now.Add(b.config.Cooldown)
+		state.trialAt = time.Time{}
+		return true
+	}
+
+	recent := state.failures[:0]
+	for _, failedAt := range state.failures {
+		if now.Sub(failedAt) < b.config.Window {
+			recent = append(recent, failedAt)
+		}
+	}
+	state.failures = append(recent, now)
+	if len(state.failures) < b.config.FailureThreshold {
+		return false
+	}
+	state.failures = nil
+	state.openUntil = now.Add(b.config.Cooldown)
+	return true
+}
+
+// recordSuccess closes the model's breaker and resets its consecutive failures
+func (b *modelCircuitBreaker) recordSuccess(modelID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.models, modelID)
+}
+
+// countsTowardCircuit reports whether a failed call says something about the model's health.
+// Oversized prompts and request errors are the caller's problem; cancellations are nobody's.
+func countsTowardCircuit(ctx context.Context, errorClass ErrorClass) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch errorClass {
+	case ErrorClassThrottling, ErrorClassConnection, ErrorClassStream, ErrorClassInternal, ErrorClassEmptyContent:
+		return true
+	}
+	return false
+}
+
+// withoutOpenCircuits drops the models whose breaker is open from a fallback list
+func withoutOpenCircuits(modelIDs []string) []string {
+	kept := make([]string, 0, len(modelIDs))
+	for _, modelID := range modelIDs {
+		if !modelCircuits.isOpen(modelID) {
+			kept = append(kept, modelID)
+		}
+	}
+	return kept
+}
+
+// bypassOpenCircuit switches the agent from its model, whose breaker is open, to the first
+// fallback that is allowed and initializes, and emits a model change event with reason circuit_open
+func (a *Agent) bypassOpenCircuit(ctx context.Context, turn int, until time.Time, fallbacks ...[]string) error {
+	logger := getLogger(a)
+	for _, modelIDs := range fallbacks {
+		for _, fallbackModelID := range modelIDs {
+			if fallbackModelID == a.ModelID {
+				continue
+			}
+			if allowed, _ := modelCircuits.allow(fallbackModelID); !allowed {
+				continue
+			}
+			fallbackLLM, err := a.createFallbackLLM(fallbackModelID)
+			if err != nil {
+				logger.Warnf("Failed to initialize fallback model %s while bypassing open circuit of %s: %v", fallbackModelID, a.ModelID, err)
+				continue
+			}
+			logger.Infof("⚡ Circuit breaker for model %s is open until %s, switching to %s", a.ModelID, until.Format(time.RFC3339), fallbackModelID)
+			oldModelID := a.ModelID
+			a.ModelID = fallbackModelID
+			a.LLM = fallbackLLM
+			a.EmitTypedEvent(ctx, events.NewModelChangeEvent(turn, oldModelID, fallbackModelID, "circuit_open", string(detectProviderFromModelID(fallbackModelID)), 0))
+			return nil
+		}
+	}
+	return &ModelCircuitOpenError{ModelID: a.ModelID, Until: until}
+}
@@ -0,0 +1,114 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+func configureCircuitBreakerForTest(t *testing.T, config CircuitBreakerConfig) *time.Time {
+	t.Helper()
+	ConfigureModelCircuitBreaker(config)
+	now := time.Now()
+	modelCircuits.now = func() time.Time { return now }
+	t.Cleanup(func() {
+		ConfigureModelCircuitBreaker(CircuitBreakerConfig{})
+		modelCircuits.now = nil
+	})
+	return &now
+}
+
+// newCircuitTestAgent returns a gpt-4o agent whose only fallback, gpt-4o-mini, always answers
+func newCircuitTestAgent(t *testing.T, primary llmtypes.Model, fallbackModels string) (*Agent, *eventCollector[*events.ModelChangeEvent]) {
+	t.Helper()
+	t.Setenv("OPENAI_FALLBACK_MODELS", fallbackModels)
+	a := newTestAgent(t, primary, onOpenAI, WithCrossProviderFallback(&CrossProviderFallback{Provider: "bedrock"}))
+	a.fallbackLLMFactory = func(modelID string) (llmtypes.Model, error) {
+		return answeringLLM("ok"), nil
+	}
+	a.retrySleep = func(ctx context.Context, delay time.Duration) error { return nil }
+	return a, collectEvents[*events.ModelChangeEvent](a)
+}
+
+func TestCircuitBreakerSkipsFailingModelUntilCooldown(t *testing.T) {
+	now := configureCircuitBreakerForTest(t, CircuitBreakerConfig{FailureThreshold: 2, Window: time.Minute, Cooldown: time.Minute})
+	messages := []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}
+
+	// Two throttled requests open the breaker; each is answered by the fallback
+	for i := 0; i < 2; i++ {
+		primary := throttledLLM(100)
+		a, _ := newCircuitTestAgent(t, primary, "gpt-4o-mini")
+		if _, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {}); err != nil {
+			t.Fatalf("request %d: expected the fallback to answer, got %v", i+1, err)
+		}
+		if primary.calls != 1 {
+			t.Fatalf("request %d: expected the primary model called once, got %d", i+1, primary.calls)
+		}
+	}
+
+	// While open, the primary model is skipped in favour of the fallback
+	primary := throttledLLM(100)
+	a, modelChanges := newCircuitTestAgent(t, primary, "gpt-4o-mini")
+	if _, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {}); err != nil {
+		t.Fatalf("expected the fallback to answer while the breaker is open, got %v", err)
+	}
+	if primary.calls != 0 || a.ModelID != "gpt-4o-mini" {
+		t.Fatalf("expected the open primary skipped, got %d calls and model %s", primary.calls, a.ModelID)
+	}
+	if changes := modelChanges.all(); len(changes) != 1 || changes[0].Reason != "circuit_open" || changes[0].OldModelID != "gpt-4o" || changes[0].NewModelID != "gpt-4o-mini" {
+		t.Fatalf("expected one circuit_open model change, got %+v", changes)
+	}
+
+	// After the cooldown a single half-open trial reaches the recovered model and closes the breaker
+	*now = now.Add(time.Minute + time.Second)
+	recovered := answeringLLM("ok")
+	a, modelChanges = newCircuitTestAgent(t, recovered, "gpt-4o-mini")
+	if _, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {}); err != nil {
+		t.Fatalf("expected the half-open trial to succeed, got %v", err)
+	}
+	if changes := modelChanges.all(); recovered.calls != 1 || a.ModelID != "gpt-4o" || len(changes) != 0 {
+		t.Fatalf("expected the primary model used again, got %d calls, model %s, changes %+v", recovered.calls, a.ModelID, changes)
+	}
+	if modelCircuits.isOpen("gpt-4o") {
+		t.Fatal("expected the breaker closed after a successful trial")
+	}
+}
+
+func TestCircuitBreakerFailedTrialReopens(t *testing.T) {
+	now := configureCircuitBreakerForTest(t, CircuitBreakerConfig{FailureThreshold: 1, Window: time.Minute, Cooldown: time.Minute})
+
+	if !modelCircuits.recordFailure("gpt-4o") || !modelCircuits.isOpen("gpt-4o") {
+		t.Fatal("expected the breaker open at the threshold")
+	}
+	*now = now.Add(2 * time.Minute)
+	if allowed, _ := modelCircuits.allow("gpt-4o"); !allowed {
+		t.Fatal("expected a half-open trial after the cooldown")
+	}
+	if allowed, _ := modelCircuits.allow("gpt-4o"); allowed {
+		t.Fatal("expected only one trial while it is running")
+	}
+	if !modelCircuits.recordFailure("gpt-4o") || !modelCircuits.isOpen("gpt-4o") {
+		t.Fatal("expected a failed trial to reopen the breaker")
+	}
+}
+
+func TestCircuitBreakerOpenWithoutFallbackFailsFast(t *testing.T) {
+	configureCircuitBreakerForTest(t, CircuitBreakerConfig{FailureThreshold: 1, Window: time.Minute, Cooldown: time.Minute})
+	modelCircuits.recordFailure("gpt-4o")
+
+	primary := answeringLLM("ok")
+	a, _ := newCircuitTestAgent(t, primary, "")
+	messages := []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}
+	_, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {})
+	var circuitErr *ModelCircuitOpenError
+	if !errors.As(err, &circuitErr) || circuitErr.ModelID != "gpt-4o" {
+		t.Fatalf("expected a circuit open error, got %v", err)
+	}
+	if primary.calls != 0 {
+		t.Fatalf("expected no calls to the open model, got %d", primary.calls)
+	}
+}
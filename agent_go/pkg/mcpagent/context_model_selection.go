@@ -0,0 +1,102 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// WithContextWindowModels enables pre-emptive model selection by context size. The map holds
+// the context window (in tokens) of the current model and of the larger-context candidates.
+// Before the first LLM call, if the estimated prompt exceeds the current model's window, the
+// smallest candidate that fits is selected. Unlike the fallbacks this happens before any error.
+func WithContextWindowModels(windows map[string]int) AgentOption {
+	return func(a *Agent) {
+		a.contextWindowModels = windows
+	}
+}
+
+// estimateContextTokens estimates the prompt size of the messages plus the tool definitions sent with them
+func estimateContextTokens(messages []llmtypes.MessageContent, tools []llmtypes.Tool) int {
+	tokens := estimateInputTokens(messages)
+	if len(tools) > 0 {
+		if toolJSON, err := json.Marshal(tools); err == nil {
+			tokens += len(toolJSON) / 4
+		}
+	}
+	return tokens
+}
+
+// pickModelForContext returns the smallest-window model that fits the estimate, or the largest
+// available when none fits. It returns "" when no model has a larger window than currentWindow.
+func pickModelForContext(windows map[string]int, currentWindow, estimated int) string {
+	candidates := make([]string, 0, len(windows))
+	for modelID, window := range windows {
+		if window > currentWindow {
+			candidates = append(candidates, modelID)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if windows[candidates[i]] != windows[candidates[j]] {
+			return windows[candidates[i]] < windows[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	for _, modelID := range candidates {
+		if windows[modelID] >= estimated {
+			return modelID
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+// selectModelForContext switches to a larger-context model when the estimated prompt does not
+// fit the current model's window, emitting a ModelChange event
+func (a *Agent) selectModelForContext(ctx context.Context, messages []llmtypes.MessageContent) {
+	if len(a.contextWindowModels) == 0 {
+		return
+	}
+	logger := getLogger(a)
+
+	currentWindow, known := a.contextWindowModels[a.ModelID]
+	if !known {
+		logger.Infof("Context window of model %s is not configured, skipping context-size model selection", a.ModelID)
+		return
+	}
+	estimated := estimateContextTokens(messages, a.filteredTools)
+	if estimated <= currentWindow {
+		return
+	}
+
+	startTime := time.Now()
+	modelID := pickModelForContext(a.contextWindowModels, currentWindow, estimated)
+	if modelID == "" {
+		logger.Warnf("Estimated context of %d tokens exceeds the %d token window of %s and no larger model is configured", estimated, currentWindow, a.ModelID)
+		return
+	}
+
+	createLLM := a.contextModelFactory
+	if createLLM == nil {
+		createLLM = a.createFallbackLLM
+	}
+	newLLM, err := createLLM(modelID)
+	if err != nil {
+		logger.Errorf("Failed to initialize larger-context model %s: %v", modelID, err)
+		return
+	}
+
+	oldModelID := a.ModelID
+	a.LLM = newLLM
+	a.ModelID = modelID
+	logger.Infof("📏 Estimated context of %d tokens exceeds the %d token window of %s, switched to %s (%d tokens)", estimated, currentWindow, oldModelID, modelID, a.contextWindowModels[modelID])
+
+	a.EmitTypedEvent(ctx, events.NewModelChangeEvent(0, oldModelID, modelID, "context_size_exceeded", string(detectProviderFromModelID(modelID)), time.Since(startTime)))
+}
@@ -0,0 +1,76 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+var testContextWindows = map[string]int{
+	"small-model":  1000,
+	"medium-model": 8000,
+	"huge-model":   200000,
+}
+
+func runContextSelection(t *testing.T, promptChars int) (*Agent, map[string]*fakeLLM, *eventCollector[*events.ModelChangeEvent], string) {
+	t.Helper()
+	// Each model answers with its own name so the test can tell which model served the call
+	llms := map[string]*fakeLLM{}
+	for modelID := range testContextWindows {
+		llms[modelID] = answeringLLM("answered by " + modelID)
+	}
+	a := newTestAgent(t, llms["small-model"], WithContextWindowModels(testContextWindows))
+	a.ModelID = "small-model"
+	a.contextModelFactory = func(modelID string) (llmtypes.Model, error) {
+		return llms[modelID], nil
+	}
+	changes := collectEvents[*events.ModelChangeEvent](a)
+
+	answer, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: strings.Repeat("x", promptChars)}}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return a, llms, changes, answer
+}
+
+func TestOversizedContextSelectsLargerModelBeforeFirstCall(t *testing.T) {
+	// ~5000 tokens: too large for small-model, fits medium-model
+	a, llms, changes, answer := runContextSelection(t, 20000)
+
+	if answer != "answered by medium-model" || a.ModelID != "medium-model" {
+		t.Fatalf("expected medium-model to serve the request, got %q (model %s)", answer, a.ModelID)
+	}
+	if llms["small-model"].calls != 0 {
+		t.Fatalf("expected small-model never to be called, got %d calls", llms["small-model"].calls)
+	}
+
+	if len(changes.all()) != 1 {
+		t.Fatalf("expected 1 model change event, got %d", len(changes.all()))
+	}
+	change := changes.all()[0]
+	if change.OldModelID != "small-model" || change.NewModelID != "medium-model" || change.Reason != "context_size_exceeded" {
+		t.Fatalf("unexpected model change event: %+v", change)
+	}
+}
+
+func TestHugeContextSelectsLargestFittingModel(t *testing.T) {
+	_, _, _, answer := runContextSelection(t, 400000)
+	if answer != "answered by huge-model" {
+		t.Fatalf("expected huge-model to serve the request, got %q", answer)
+	}
+}
+
+func TestContextWithinWindowKeepsModel(t *testing.T) {
+	a, _, changes, answer := runContextSelection(t, 400)
+	if answer != "answered by small-model" || a.ModelID != "small-model" {
+		t.Fatalf("expected small-model to be kept, got %q (model %s)", answer, a.ModelID)
+	}
+	if len(changes.all()) != 0 {
+		t.Fatalf("expected no model change, got %+v", changes.all())
+	}
+}
@@ -50,6 +50,14 @@ func isVirtualTool(toolName string) bool {
 	return false
 }
 
+// getToolTimeout returns the timeout of a single tool: its override if any, else the agent default
+func getToolTimeout(a *Agent, toolName string) time.Duration {
+	if timeout, ok := a.ToolTimeouts[toolName]; ok && timeout > 0 {
+		return timeout
+	}
+	return getToolExecutionTimeout(a)
+}
+
 // getToolExecutionTimeout returns the tool execution timeout duration
 func getToolExecutionTimeout(a *Agent) time.Duration {
 	// First check if agent has a specific timeout configured
@@ -113,12 +121,25 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 		a.MaxTurns = 50
 	}
 
+	// Report the server selection made by NewAgent now that listeners are attached
+	a.emitServerSelections(ctx)
+
+	// Custom agent modes run their own execution strategy
+	if runner, err := a.customModeRunner(); err != nil {
+		return "", messages, err
+	} else if runner != nil {
+		logger.Infof("Running custom agent mode %s", a.AgentMode)
+		return runner.Run(ctx, a, messages)
+	}
+
 	// Use the passed context for cancellation checks (not the agent's internal context)
 	// This ensures we use the context that the caller wants us to respect
 	agentCtx := ctx
 
 	// Track conversation start time for duration calculation
 	conversationStartTime := time.Now()
+	a.resetLatencySummary()
+	a.resetCostSummary()
 
 	// ✅ CONTEXT-AWARE HIERARCHY: Initialize based on calling context
 	// This ensures hierarchy reflects the actual calling context
@@ -127,6 +148,9 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 	// Ensure system prompt is included in messages
 	messages = ensureSystemPrompt(a, messages)
 
+	// Route oversized prompts to a larger-context model before the first call
+	a.selectModelForContext(ctx, messages)
+
 	// NEW: Set current query for hierarchy tracking (will be set later when lastUserMessage is extracted)
 
 	// Add cache validation AFTER the agent is fully initialized
@@ -298,6 +322,15 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 		logger.Infof("🔧 Using pre-determined tool set: %d tools (smart routing: %v)", len(a.filteredTools), a.EnableSmartRouting)
 	}
 
+	// Record the advertised tool set and the tools invoked during this run (see WithToolUsageRecorder)
+	if a.toolUsageRecorder != nil {
+		runStartIndex := len(messages)
+		defer func() { a.recordToolUsage(ctx, messages, runStartIndex) }()
+	}
+
+	// Undo a tool transaction the LLM left open (see WithToolTransactions)
+	defer a.rollbackOpenTransaction(ctx)
+
 	// ✅ Emit system prompt event AFTER smart routing has completed
 	// This ensures the frontend sees the final system prompt with filtered servers
 	systemPromptEvent := events.NewSystemPromptEvent(a.SystemPrompt, 0)
@@ -307,6 +340,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 	for turn := 0; turn < a.MaxTurns; turn++ {
 		// NEW: Start turn for hierarchy tracking
 		a.StartTurn(ctx, turn+1)
+		turnLatency := newTurnLatencyTracker()
 
 		// Extract the last message from the conversation (could be user, assistant, or tool)
 		var lastMessage string
@@ -346,6 +380,9 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 			return "", messages, fmt.Errorf("conversation cancelled: %w", agentCtx.Err())
 		}
 
+		// Drop the oldest turns when the prompt would overflow the context window (see WithHistoryCompaction)
+		messages = a.compactHistoryForCall(ctx, turn+1, messages)
+
 		// Use the current messages that include tool results from previous turns
 		llmMessages := messages
 
@@ -411,6 +448,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 				opts = append(opts, llmtypes.WithToolChoice(toolChoiceOpt))
 			}
 		}
+		opts = withTokenStreamOption(ctx, opts)
 		toolNames := make([]string, len(a.filteredTools))
 		for i, tool := range a.filteredTools {
 			toolNames[i] = tool.Function.Name
@@ -438,6 +476,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 		a.StartLLMGeneration(ctx)
 
 		// Use GenerateContentWithRetry for robust fallback handling
+		llmCallStart := time.Now()
 		resp, genErr, usage := GenerateContentWithRetry(a, ctx, llmMessages, opts, turn, func(msg string) {
 			// For ReAct agents, track reasoning in real-time
 			if a.AgentMode == ReActAgent {
@@ -515,6 +554,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 				return "", messages, fmt.Errorf("llm error: %w", genErr)
 			}
 		}
+		turnLatency.addLLM(time.Since(llmCallStart))
 		if resp == nil || resp.Choices == nil || len(resp.Choices) == 0 {
 
 			// 🎯 FIX: End the trace for error cases - replaced with event emission
@@ -547,6 +587,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 			messages = append(messages, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI, Parts: assistantParts})
 
 			// 2. For each tool call, execute and append the tool result as a new message
+			dedup := a.newToolCallDeduplicator()
 			for _, tc := range choice.ToolCalls {
 
 				// Determine server name for tool call events
@@ -595,6 +636,23 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 
 					continue
 				}
+
+				// Block tools denied by the tool policy before anything else happens (see WithToolPolicy)
+				if reason := a.checkToolPolicy(tc.FunctionCall.Name, serverName); reason != "" {
+					logger.Warnf("[AGENT DEBUG] AskWithHistory Turn %d: %s", turn+1, reason)
+
+					toolBlockedEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, reason, serverName, 0)
+					toolBlockedEvent.Reason = events.ToolBlockedByPolicyReason
+					a.EmitTypedEvent(ctx, toolBlockedEvent)
+
+					messages = append(messages, llmtypes.MessageContent{
+						Role:  llmtypes.ChatMessageTypeTool,
+						Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: toolPolicyFeedback(tc.FunctionCall.Name, reason)}},
+					})
+
+					continue
+				}
+
 				args, err := mcpclient.ParseToolArguments(tc.FunctionCall.Arguments)
 				if err != nil {
 					logger.Errorf("[AGENT DEBUG] AskWithHistory Tool args parsing error: %w", err)
@@ -615,6 +673,40 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 					continue
 				}
 
+				// Normalize non-English arguments to the canonical language (see WithToolArgTranslation)
+				args = a.translateToolArgs(ctx, tc.FunctionCall.Name, args)
+
+				// Let interceptors rewrite or reject the arguments (see WithToolInterceptors)
+				if len(a.toolInterceptors) > 0 {
+					intercepted, interceptErr := a.interceptToolArgs(ctx, tc.FunctionCall.Name, args)
+					if interceptErr != nil {
+						messages = append(messages, llmtypes.MessageContent{
+							Role:  llmtypes.ChatMessageTypeTool,
+							Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: a.rejectToolCall(ctx, turn+1, tc.FunctionCall.Name, serverName, interceptErr)}},
+						})
+						continue
+					}
+					args = intercepted
+				}
+
+				// Answer an identical call made earlier this turn from its result (see WithToolDeduplication)
+				if dedupResult, reused := a.reuseDuplicateToolCall(ctx, dedup, turn+1, tc, serverName, args); reused {
+					messages = append(messages, llmtypes.MessageContent{
+						Role:  llmtypes.ChatMessageTypeTool,
+						Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: dedupResult}},
+					})
+					continue
+				}
+
+				// Block until a human approves a gated tool (see WithConfirmTools)
+				if declined := a.confirmToolCall(ctx, turn+1, tc.FunctionCall.Name, serverName, tc.ID, args); declined != "" {
+					messages = append(messages, llmtypes.MessageContent{
+						Role:  llmtypes.ChatMessageTypeTool,
+						Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: declined}},
+					})
+					continue
+				}
+
 				// 🔧 FIX: Check custom tools FIRST before MCP client lookup
 				// Custom tools don't need MCP clients, so check them early
 				isCustomTool := false
@@ -692,7 +784,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 				}
 
 				// Create timeout context for tool execution
-				toolTimeout := getToolExecutionTimeout(a)
+				toolTimeout := getToolTimeout(a, tc.FunctionCall.Name)
 				toolCtx, cancel := context.WithTimeout(ctx, toolTimeout)
 				defer cancel()
 
@@ -766,11 +858,13 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 					// Handle regular MCP tool execution
 					result, toolErr = client.CallTool(toolCtx, tc.FunctionCall.Name, args)
 				}
+				result, toolErr = a.interceptToolCallResult(toolCtx, tc.FunctionCall.Name, result, toolErr)
 
 				duration := time.Since(startTime)
 
 				// Check for timeout
-				if toolCtx.Err() == context.DeadlineExceeded {
+				timedOut := toolCtx.Err() == context.DeadlineExceeded
+				if timedOut {
 					toolErr = fmt.Errorf("tool execution timed out after %s: %s", toolTimeout.String(), tc.FunctionCall.Name)
 					// Use agent's logger if available, otherwise use default
 					logger := getLogger(a)
@@ -809,17 +903,39 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 
 						// Emit tool call error event using typed event data
 						toolErrorEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, toolErr.Error(), serverName, duration)
+						if timedOut {
+							toolErrorEvent.Reason = events.ToolTimeoutReason
+							toolErrorEvent.Timeout = toolTimeout
+						}
 						a.EmitTypedEvent(ctx, toolErrorEvent)
 
 						// Instead of failing the entire conversation, provide feedback to the LLM
 						errorResultText := fmt.Sprintf("Tool execution failed - %v", toolErr)
 
+						// A permission error uses the read-only fallback (see WithToolReadOnlyFallback); after
+						// repeated other failures, call the tool's registered alternate instead (see WithToolAlternate)
+						stepTool, stepFailed := tc.FunctionCall.Name, true
+						if fallbackText, fallback, succeeded := a.handlePermissionError(ctx, turn+1, tc.FunctionCall.Name, args, toolErr.Error()); fallbackText != "" {
+							errorResultText = fallbackText
+							if succeeded {
+								stepTool, stepFailed = fallback, false
+							}
+						} else if alternateText, alternate, succeeded := a.runToolAlternate(ctx, turn+1, tc.FunctionCall.Name, args, toolErr.Error()); alternateText != "" {
+							errorResultText = alternateText
+							if succeeded {
+								stepTool, stepFailed = alternate, false
+							}
+						}
+						errorResultText += a.recordTransactionStep(ctx, stepTool, args, errorResultText, stepFailed)
+
 						// Add the error result to the conversation so the LLM can continue
 						messages = append(messages, llmtypes.MessageContent{
 							Role:  llmtypes.ChatMessageTypeTool, // Use "tool" role for tool responses
 							Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: errorResultText}},
 						})
 
+						turnLatency.addTool(time.Since(startTime))
+
 						// Continue to next turn instead of returning error
 						continue
 					}
@@ -854,38 +970,37 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 						}
 					}
 
-					// Check if this is a large tool output that should be written to file
-					if a.toolOutputHandler != nil {
-						// Check if this is a large tool output that should be written to file
-						if a.toolOutputHandler.IsLargeToolOutputWithModel(resultText, a.ModelID) {
-
-							// Emit large tool output detection event
-							detectedEvent := events.NewLargeToolOutputDetectedEvent(tc.FunctionCall.Name, len(resultText), a.toolOutputHandler.GetToolOutputFolder())
-							detectedEvent.ServerAvailable = a.toolOutputHandler.IsServerAvailable()
-							a.EmitTypedEvent(ctx, detectedEvent)
-
-							// Write large output to file
-							filePath, writeErr := a.toolOutputHandler.WriteToolOutputToFile(resultText, tc.FunctionCall.Name)
-							if writeErr == nil {
-								// Extract first 100 characters for Langfuse observability
-								preview := a.toolOutputHandler.ExtractFirstNCharacters(resultText, 100)
-
-								// Emit successful file write event with preview
-								fileWrittenEvent := events.NewLargeToolOutputFileWrittenEvent(tc.FunctionCall.Name, filePath, len(resultText), preview)
-								a.EmitTypedEvent(ctx, fileWrittenEvent)
+					// A permission error uses the read-only fallback (see WithToolReadOnlyFallback); after
+					// repeated other failures, call the tool's registered alternate instead (see WithToolAlternate)
+					stepTool, stepFailed := tc.FunctionCall.Name, result.IsError
+					if result.IsError {
+						if fallbackText, fallback, succeeded := a.handlePermissionError(ctx, turn+1, tc.FunctionCall.Name, args, resultText); fallbackText != "" {
+							resultText = fallbackText
+							if succeeded {
+								stepTool, stepFailed = fallback, false
+							}
+						} else if alternateText, alternate, succeeded := a.runToolAlternate(ctx, turn+1, tc.FunctionCall.Name, args, resultText); alternateText != "" {
+							resultText = alternateText
+							if succeeded {
+								stepTool, stepFailed = alternate, false
+							}
+						}
+					} else {
+						a.recordToolSuccess(tc.FunctionCall.Name)
+					}
 
-								// Create message with file path, first 100 characters, and instructions
-								fileMessage := a.toolOutputHandler.CreateToolOutputMessageWithPreview(tc.ID, filePath, resultText)
+					// Record the call in the open tool transaction; a failure rolls it back
+					resultText += a.recordTransactionStep(ctx, stepTool, args, resultText, stepFailed)
 
-								// Replace the result text with the file message
-								resultText = fileMessage
+					// Large tool outputs are written to file, summarized or truncated (see WithLargeOutputPolicy)
+					largeOutputHandled := false
+					if a.isLargeToolOutput(resultText) {
+						resultText, largeOutputHandled = a.handleLargeToolOutput(ctx, tc.ID, tc.FunctionCall.Name, resultText)
+					}
 
-							} else {
-								// Emit file write error event
-								fileErrorEvent := events.NewLargeToolOutputFileWriteErrorEvent(tc.FunctionCall.Name, writeErr.Error(), len(resultText))
-								a.EmitTypedEvent(ctx, fileErrorEvent)
-							}
-						}
+					// Bring the content of workspace files the tool referenced into context
+					if !largeOutputHandled {
+						resultText = a.inlineWorkspaceFileReferences(resultText)
 					}
 				} else {
 					resultText = "Tool execution completed but no result returned"
@@ -913,7 +1028,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 					"duration":    duration,
 					"turn":        turn + 1,
 					"success":     toolErr == nil,
-					"timeout":     getToolExecutionTimeout(a).String(),
+					"timeout":     toolTimeout.String(),
 				}
 				if toolErr != nil {
 					toolOutput["error"] = toolErr.Error()
@@ -924,7 +1039,11 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 					}
 				}
 
-				// Tool execution completed - emit tool call end event
+				// Tool execution completed - record its latency and emit tool call end event
+				turnLatency.addTool(time.Since(startTime))
+				if result != nil && !result.IsError {
+					dedup.store(tc.FunctionCall.Name, args, tc.ID, resultText)
+				}
 
 				// Emit tool call end event using typed event data (consolidated - contains all tool information)
 				toolEndEvent := events.NewToolCallEndEvent(turn+1, tc.FunctionCall.Name, resultText, serverName, duration, "")
@@ -935,6 +1054,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 
 			}
 
+			a.finishTurnLatency(ctx, turn+1, turnLatency)
 			continue
 		} else {
 			// No tool calls - add the assistant response to conversation history
@@ -947,6 +1067,8 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 				messages = append(messages, assistantMessage)
 			}
 
+			a.finishTurnLatency(ctx, turn+1, turnLatency)
+
 			// Check if this is a ReAct agent and if it has a completion pattern
 			if a.AgentMode == ReActAgent {
 				if IsReActCompletion(choice.Content) {
@@ -969,7 +1091,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 						time.Since(conversationStartTime), // duration
 						turn+1,                            // turns
 					)
-					a.EmitTypedEvent(ctx, unifiedCompletionEvent)
+					a.EmitTypedEvent(ctx, a.withRunSummary(ctx, messages, a.withLatencySummary(unifiedCompletionEvent)))
 
 					// Agent end event removed - no longer needed
 
@@ -1009,7 +1131,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 					time.Since(conversationStartTime), // duration
 					turn+1,                            // turns
 				)
-				a.EmitTypedEvent(ctx, unifiedCompletionEvent)
+				a.EmitTypedEvent(ctx, a.withRunSummary(ctx, messages, a.withLatencySummary(unifiedCompletionEvent)))
 
 				// NEW: End agent session for hierarchy tracking
 				a.EndAgentSession(ctx)
@@ -1061,6 +1183,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 	if !llm.IsO3O4Model(a.ModelID) {
 		finalOpts = append(finalOpts, llmtypes.WithTemperature(a.Temperature))
 	}
+	finalOpts = withTokenStreamOption(ctx, finalOpts)
 
 	finalResp, err, _ = GenerateContentWithRetry(a, ctx, messages, finalOpts, a.MaxTurns, func(msg string) {
 		// Optional: stream the final response
@@ -1096,7 +1219,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 				time.Since(conversationStartTime), // duration
 				a.MaxTurns,                        // turns
 			)
-			a.EmitTypedEvent(ctx, unifiedCompletionEvent)
+			a.EmitTypedEvent(ctx, a.withRunSummary(ctx, messages, a.withLatencySummary(unifiedCompletionEvent)))
 
 			// NEW: End agent session for hierarchy tracking
 			a.EndAgentSession(ctx)
@@ -1154,7 +1277,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 				time.Since(conversationStartTime), // duration
 				a.MaxTurns+1,                      // turns (+1 for the final turn)
 			)
-			a.EmitTypedEvent(ctx, unifiedCompletionEvent)
+			a.EmitTypedEvent(ctx, a.withRunSummary(ctx, messages, a.withLatencySummary(unifiedCompletionEvent)))
 
 			// Agent end event removed - no longer needed
 
@@ -1190,7 +1313,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 		time.Since(conversationStartTime), // duration
 		a.MaxTurns+1,                      // turns (+1 for the final turn)
 	)
-	a.EmitTypedEvent(ctx, unifiedCompletionEvent)
+	a.EmitTypedEvent(ctx, a.withRunSummary(ctx, messages, a.withLatencySummary(unifiedCompletionEvent)))
 
 	// NEW: End agent session for hierarchy tracking
 	a.EndAgentSession(ctx)
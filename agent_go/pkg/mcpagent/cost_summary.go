@@ -0,0 +1,56 @@
+package mcpagent
+
+import (
+	"context"
+
+	"mcp-agent/agent_go/internal/observability"
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/pricing"
+)
+
+// CostEstimator estimates the USD cost of LLM usage by a model
+type CostEstimator func(modelID string, usage events.UsageMetrics) float64
+
+// WithCostEstimator replaces the price table (pricing.EstimateCost) used for the conversation cost summary
+func WithCostEstimator(estimator CostEstimator) AgentOption {
+	return func(a *Agent) {
+		a.costEstimator = estimator
+	}
+}
+
+// recordGenerationUsage adds a successful generation to the conversation cost summary
+func (a *Agent) recordGenerationUsage(modelID string, usage observability.UsageMetrics, fellBack bool) {
+	summary := a.getCostSummary()
+	summary.Add(modelID, events.UsageMetrics{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.TotalTokens,
+	})
+	if fellBack {
+		summary.AddFallback()
+	}
+}
+
+// resetCostSummary clears the cost summary at the start of a conversation
+func (a *Agent) resetCostSummary() {
+	a.getCostSummary().Reset()
+}
+
+// emitCostSummary emits the cost summary of the conversation that just completed
+func (a *Agent) emitCostSummary(ctx context.Context) {
+	summary := a.getCostSummary()
+	estimator := a.costEstimator
+	if estimator == nil {
+		estimator = pricing.EstimateCost
+	}
+	event := summary.Event("conversation", estimator)
+	summary.Reset()
+	a.EmitTypedEvent(ctx, event)
+}
+
+func (a *Agent) getCostSummary() *events.CostSummary {
+	a.costSummaryOnce.Do(func() {
+		a.costSummary = events.NewCostSummary()
+	})
+	return a.costSummary
+}
@@ -0,0 +1,74 @@
+package mcpagent
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// usageLLM calls lookup on every turn but the last and reports usage growing with each turn
+func usageLLM(turns int) *fakeLLM {
+	return &fakeLLM{respond: func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		input, output := 100*call, 10*call
+		total := input + output
+		choice := &llmtypes.ContentChoice{GenerationInfo: &llmtypes.GenerationInfo{InputTokens: &input, OutputTokens: &output, TotalTokens: &total}}
+		if call < turns {
+			choice.ToolCalls = []llmtypes.ToolCall{toolCall("call-lookup", "lookup", `{}`)}
+		} else {
+			choice.Content = "done"
+		}
+		return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{choice}}, nil
+	}}
+}
+
+func TestCostSummaryMatchesGenerationEvents(t *testing.T) {
+	a := newTestAgent(t, usageLLM(3), WithCostEstimator(func(modelID string, usage events.UsageMetrics) float64 {
+		return float64(usage.PromptTokens)*0.001 + float64(usage.CompletionTokens)*0.002
+	}))
+	a.RegisterCustomTool("lookup", "Look something up", map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return "found", nil
+	})
+	generations := collectEvents[*events.LLMGenerationEndEvent](a)
+	summaries := collectEvents[*events.ConversationCostSummaryEvent](a)
+
+	if _, err := a.Ask(context.Background(), "look it up"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The turn summaries repeat the usage of the turn's call
+	turnUsage := map[int]events.UsageMetrics{}
+	for _, generation := range generations.all() {
+		if !generation.TurnSummary {
+			turnUsage[generation.Turn] = generation.UsageMetrics
+		}
+	}
+	if len(turnUsage) != 3 {
+		t.Fatalf("expected usage for 3 turns, got %+v", turnUsage)
+	}
+	if len(summaries.all()) != 1 {
+		t.Fatalf("expected one cost summary, got %d", len(summaries.all()))
+	}
+	var want events.UsageMetrics
+	for _, usage := range turnUsage {
+		want.PromptTokens += usage.PromptTokens
+		want.CompletionTokens += usage.CompletionTokens
+		want.TotalTokens += usage.TotalTokens
+	}
+	summary := summaries.all()[0]
+	if summary.Scope != "conversation" || summary.PromptTokens != want.PromptTokens ||
+		summary.CompletionTokens != want.CompletionTokens || summary.TotalTokens != want.TotalTokens {
+		t.Fatalf("summary %+v does not match the generation events %+v", summary, want)
+	}
+	if want.PromptTokens != 600 || want.CompletionTokens != 60 {
+		t.Fatalf("unexpected generation usage %+v", want)
+	}
+	if len(summary.Models) != 1 || summary.Models[0].ModelID != "test-model" || summary.Models[0].Generations != 3 {
+		t.Fatalf("expected 3 generations of test-model, got %+v", summary.Models)
+	}
+	if math.Abs(summary.EstimatedCostUSD-(600*0.001+60*0.002)) > 1e-9 {
+		t.Fatalf("unexpected estimated cost %v", summary.EstimatedCostUSD)
+	}
+}
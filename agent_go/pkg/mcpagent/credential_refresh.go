@@ -0,0 +1,100 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// SecretProvider refreshes LLM provider credentials that expired mid-run, e.g. temporary
+// Bedrock/STS credentials. RefreshCredentials must make the new credentials visible to newly
+// created clients (environment, shared credentials file, ...); the agent then rebuilds its LLM.
+type SecretProvider interface {
+	RefreshCredentials(ctx context.Context, provider string) error
+}
+
+// SecretProviderFunc adapts a function to SecretProvider
+type SecretProviderFunc func(ctx context.Context, provider string) error
+
+func (f SecretProviderFunc) RefreshCredentials(ctx context.Context, provider string) error {
+	return f(ctx, provider)
+}
+
+// WithSecretProvider re-authenticates through provider when an LLM call fails on expired or
+// invalid credentials, then retries the call once with a client using the refreshed credentials
+func WithSecretProvider(provider SecretProvider) AgentOption {
+	return func(a *Agent) {
+		a.secretProvider = provider
+	}
+}
+
+// credentialExpiryPatterns match authentication failures that fresh credentials can fix.
+// Permission errors (403 AccessDenied) are not included: new credentials won't grant access.
+var credentialExpiryPatterns = []string{
+	"expiredtoken",
+	"security token included in the request is expired",
+	"token has expired",
+	"token is expired",
+	"credentials have expired",
+	"unrecognizedclientexception",
+	"security token included in the request is invalid",
+	"invalid_api_key",
+	"incorrect api key",
+	"invalid x-api-key",
+	"authentication_error",
+	"unauthorized",
+	"status code: 401",
+	"status code 401",
+	"statuscode: 401",
+}
+
+// isCredentialExpiryError reports whether err is an expired or invalid credential error
+func isCredentialExpiryError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range credentialExpiryPatterns {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// reauthenticate refreshes the provider credentials and rebuilds the LLM client with them.
+// Returns whether the call should be retried.
+func (a *Agent) reauthenticate(ctx context.Context, turn int, authErr error) bool {
+	logger := getLogger(a)
+	event := events.NewCredentialRefreshEvent(turn, a.ModelID, string(a.provider), authErr.Error())
+	defer a.EmitTypedEvent(ctx, event)
+
+	if a.secretProvider == nil {
+		event.RefreshError = "no secret provider configured"
+		logger.Warnf("🔑 LLM credentials expired or invalid and no secret provider is configured: %v", authErr)
+		return false
+	}
+
+	logger.Infof("🔑 LLM credentials expired or invalid, refreshing through the secret provider: %v", authErr)
+	if err := a.secretProvider.RefreshCredentials(ctx, string(a.provider)); err != nil {
+		event.RefreshError = err.Error()
+		logger.Errorf("🔑 Credential refresh failed: %v", err)
+		return false
+	}
+
+	// Clients capture credentials when created, so rebuild the LLM to pick up the refreshed ones
+	rebuild := a.llmRebuilder
+	if rebuild == nil {
+		rebuild = a.createFallbackLLM
+	}
+	fresh, err := rebuild(a.ModelID)
+	if err != nil {
+		// Some SDKs re-read refreshed credentials on their own; retry with the current client
+		logger.Warnf("🔑 Failed to rebuild LLM after credential refresh, retrying with the current client: %v", err)
+	} else {
+		a.LLM = fresh
+	}
+	event.Refreshed = true
+	return true
+}
@@ -0,0 +1,92 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+const expiredTokenError = "operation error Bedrock Runtime: Converse, https response error StatusCode: 403, ExpiredTokenException: The security token included in the request is expired"
+
+func newCredentialTestAgent(t *testing.T, llm llmtypes.Model) (*Agent, *eventCollector[*events.CredentialRefreshEvent]) {
+	t.Helper()
+	a := newTestAgent(t, llm)
+	a.ModelID = "us.anthropic.claude-sonnet-4-20250514-v1:0"
+	return a, collectEvents[*events.CredentialRefreshEvent](a)
+}
+
+func TestCredentialExpiryReauthenticatesBeforeRetrying(t *testing.T) {
+	expired := failingLLM(expiredTokenError)
+	refreshed := answeringLLM("done")
+	a, refreshEvents := newCredentialTestAgent(t, expired)
+
+	var steps []string
+	WithSecretProvider(SecretProviderFunc(func(ctx context.Context, provider string) error {
+		steps = append(steps, "refresh")
+		return nil
+	}))(a)
+	a.llmRebuilder = func(modelID string) (llmtypes.Model, error) {
+		steps = append(steps, "rebuild")
+		return refreshed, nil
+	}
+
+	resp, err, _ := GenerateContentWithRetry(a, context.Background(), []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}, nil, 1, func(string) {})
+	if err != nil {
+		t.Fatalf("expected the retry after re-authentication to succeed, got %v", err)
+	}
+	if resp.Choices[0].Content != "done" {
+		t.Fatalf("unexpected response: %+v", resp.Choices[0])
+	}
+	if expired.calls != 1 || refreshed.calls != 1 || strings.Join(steps, ",") != "refresh,rebuild" {
+		t.Fatalf("expected one expired call, a refresh and rebuild, then one retry; got expired=%d refreshed=%d steps=%v", expired.calls, refreshed.calls, steps)
+	}
+
+	refreshes := refreshEvents.all()
+	if len(refreshes) != 1 || !refreshes[0].Refreshed || !strings.Contains(refreshes[0].Error, "ExpiredTokenException") {
+		t.Fatalf("expected one successful credential refresh event, got %+v", refreshes)
+	}
+}
+
+func TestCredentialExpiryFailsWhenReauthenticationFails(t *testing.T) {
+	expired := failingLLM(expiredTokenError)
+	a, refreshEvents := newCredentialTestAgent(t, expired)
+	providerCalls := 0
+	WithSecretProvider(SecretProviderFunc(func(ctx context.Context, provider string) error {
+		providerCalls++
+		return nil
+	}))(a)
+	// The refreshed credentials are still rejected: re-authentication is attempted only once
+	a.llmRebuilder = func(modelID string) (llmtypes.Model, error) {
+		return expired, nil
+	}
+
+	_, err, _ := GenerateContentWithRetry(a, context.Background(), []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}, nil, 1, func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "credentials expired") {
+		t.Fatalf("expected a credential expiry error, got %v", err)
+	}
+	if providerCalls != 1 || expired.calls != 2 {
+		t.Fatalf("expected one refresh and one retry, got %d refreshes and %d calls", providerCalls, expired.calls)
+	}
+
+	refreshes := refreshEvents.all()
+	if len(refreshes) != 1 {
+		t.Fatalf("expected one credential refresh event, got %d", len(refreshes))
+	}
+}
+
+func TestCredentialExpiryWithoutSecretProviderIsNotThrottling(t *testing.T) {
+	expired := failingLLM(expiredTokenError)
+	a, refreshEvents := newCredentialTestAgent(t, expired)
+
+	_, err, _ := GenerateContentWithRetry(a, context.Background(), []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}, nil, 1, func(string) {})
+	if err == nil || expired.calls != 1 {
+		t.Fatalf("expected an immediate failure without throttling retries, got %v after %d calls", err, expired.calls)
+	}
+	refreshes := refreshEvents.all()
+	if len(refreshes) != 1 || refreshes[0].Refreshed || refreshes[0].RefreshError != "no secret provider configured" {
+		t.Fatalf("expected an unrefreshed credential event, got %+v", refreshes)
+	}
+}
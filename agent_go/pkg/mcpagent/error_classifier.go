@@ -0,0 +1,160 @@
+package mcpagent
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrorClass is how GenerateContentWithRetry handles a failed LLM call
+type ErrorClass string
+
+const (
+	ErrorClassNone         ErrorClass = ""              // No error
+	ErrorClassMaxToken     ErrorClass = "max_token"     // Input exceeds the model's context; fall back to other models
+	ErrorClassThrottling   ErrorClass = "throttling"    // Rate limits and 5xx responses; fall back, then wait and retry
+	ErrorClassEmptyContent ErrorClass = "empty_content" // The model answered with no content; retry, then fall back
+	ErrorClassConnection   ErrorClass = "connection"    // Network failures; fall back to other models
+	ErrorClassStream       ErrorClass = "stream"        // Interrupted streams; fall back to other models
+	ErrorClassInternal     ErrorClass = "internal"      // Provider-side internal errors; fall back to other models
+	ErrorClassFatal        ErrorClass = "fatal"         // Anything else; returned to the caller as is
+)
+
+// ErrorRule classifies errors whose message contains Substring or matches Pattern
+type ErrorRule struct {
+	Class     ErrorClass
+	Substring string
+	Pattern   *regexp.Regexp
+}
+
+func (r ErrorRule) matches(msg string) bool {
+	if r.Pattern != nil {
+		return r.Pattern.MatchString(msg)
+	}
+	return r.Substring != "" && strings.Contains(msg, r.Substring)
+}
+
+// builtinErrorRules are checked in order; the first match wins, so a message matching several
+// classes (e.g. "context deadline exceeded" is both max_token and connection) keeps the class
+// that GenerateContentWithRetry has always handled it as.
+var builtinErrorRules = func() []ErrorRule {
+	groups := []struct {
+		class      ErrorClass
+		substrings []string
+	}{
+		{ErrorClassMaxToken, []string{
+			"max_token", "context", "max tokens", "Input is too long", "ValidationException", "too long",
+		}},
+		{ErrorClassThrottling, []string{
+			"ThrottlingException", "Too many tokens", "StatusCode: 429", "API returned unexpected status code: 429",
+			"status code: 429", "status code 429", "429", "rate limit", "throttled",
+			// Server errors (5xx) trigger fallback like throttling
+			"502", "503", "504", "500", "API returned unexpected status code: 5", "Provider returned error",
+			"Bad Gateway", "Service Unavailable", "Gateway Timeout",
+			// Anthropic API: rate limits and overload (HTTP 529)
+			"rate_limit_error", "overloaded_error", "Overloaded",
+		}},
+		{ErrorClassEmptyContent, []string{
+			"Choice.Content is empty string", "empty content error", "choice.Content is empty", "empty response",
+		}},
+		{ErrorClassConnection, []string{
+			"EOF", "connection refused", "timeout", "network", "dial tcp", "context deadline exceeded",
+			"connection reset", "broken pipe", "connection lost", "connection closed", "unexpected EOF",
+		}},
+		{ErrorClassStream, []string{
+			"stream error", "stream ID", "streaming", "stream closed", "stream interrupted", "stream timeout", "streaming error",
+		}},
+		{ErrorClassInternal, []string{
+			"INTERNAL_ERROR", "internal error", "server error", "unexpected error", "received from peer",
+			"peer error", "internal server error", "service error",
+			// Anthropic API's unexpected internal error
+			"api_error",
+		}},
+	}
+
+	var rules []ErrorRule
+	for _, group := range groups {
+		for _, substring := range group.substrings {
+			rules = append(rules, ErrorRule{Class: group.class, Substring: substring})
+		}
+	}
+	return rules
+}()
+
+// ErrorClassifier maps LLM errors to an ErrorClass. Rules added by callers are checked before
+// the built-in ones, in the order they were added.
+type ErrorClassifier struct {
+	mu    sync.RWMutex
+	rules []ErrorRule
+}
+
+// NewErrorClassifier returns a classifier with only the built-in rules
+func NewErrorClassifier() *ErrorClassifier {
+	return &ErrorClassifier{}
+}
+
+// defaultErrorClassifier is used by agents without WithErrorClassifier
+var defaultErrorClassifier = NewErrorClassifier()
+
+// WithErrorClassifier sets the classifier deciding how failed LLM calls are retried
+func WithErrorClassifier(classifier *ErrorClassifier) AgentOption {
+	return func(a *Agent) {
+		a.errorClassifier = classifier
+	}
+}
+
+// AddRule registers a rule checked before the built-in ones
+func (c *ErrorClassifier) AddRule(rule ErrorRule) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules = append(c.rules, rule)
+}
+
+// AddSubstring classifies errors whose message contains substring as class
+func (c *ErrorClassifier) AddSubstring(class ErrorClass, substring string) {
+	c.AddRule(ErrorRule{Class: class, Substring: substring})
+}
+
+// AddPattern classifies errors whose message matches the regular expression pattern as class
+func (c *ErrorClassifier) AddPattern(class ErrorClass, pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid error pattern %q: %w", pattern, err)
+	}
+	c.AddRule(ErrorRule{Class: class, Pattern: re})
+	return nil
+}
+
+// Classify returns the class of err: the first matching registered rule, then the first
+// matching built-in rule, otherwise ErrorClassFatal
+func (c *ErrorClassifier) Classify(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassNone
+	}
+	msg := err.Error()
+
+	c.mu.RLock()
+	for _, rule := range c.rules {
+		if rule.matches(msg) {
+			c.mu.RUnlock()
+			return rule.Class
+		}
+	}
+	c.mu.RUnlock()
+
+	for _, rule := range builtinErrorRules {
+		if rule.matches(msg) {
+			return rule.Class
+		}
+	}
+	return ErrorClassFatal
+}
+
+// classifyError classifies err with the agent's classifier
+func (a *Agent) classifyError(err error) ErrorClass {
+	if a.errorClassifier != nil {
+		return a.errorClassifier.Classify(err)
+	}
+	return defaultErrorClassifier.Classify(err)
+}
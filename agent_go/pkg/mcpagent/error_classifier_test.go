@@ -0,0 +1,102 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+func TestErrorClassifierProviderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  string
+		want ErrorClass
+	}{
+		// Bedrock
+		{"bedrock throttling", "operation error Bedrock Runtime: Converse, https response error StatusCode: 429, ThrottlingException: Too many requests, please wait before trying again.", ErrorClassThrottling},
+		{"bedrock input too long", "operation error Bedrock Runtime: Converse, https response error StatusCode: 400, ValidationException: Input is too long for requested model.", ErrorClassMaxToken},
+		{"bedrock service unavailable", "operation error Bedrock Runtime: Converse, ServiceUnavailableException: Service Unavailable", ErrorClassThrottling},
+		// OpenAI
+		{"openai rate limit", "API returned unexpected status code: 429: Rate limit reached for gpt-4.1 in organization on tokens per min (TPM)", ErrorClassThrottling},
+		{"openai context length", "This model's maximum context length is 128000 tokens. However, your messages resulted in 131072 tokens.", ErrorClassMaxToken},
+		{"openai empty choice", "Choice.Content is empty string", ErrorClassEmptyContent},
+		{"openai invalid key", "API returned unexpected status code: 401: Incorrect API key provided: sk-abc. You can find your API key at https://platform.openai.com/account/api-keys.", ErrorClassFatal},
+		// Anthropic
+		{"anthropic prompt too long", "prompt is too long: 210000 tokens > 200000 maximum", ErrorClassMaxToken},
+		{"anthropic overloaded", "API returned unexpected status code: 529: Overloaded", ErrorClassThrottling},
+		{"anthropic auth", "authentication_error: invalid x-api-key", ErrorClassFatal},
+		{"anthropic sdk overloaded", `POST "https://api.anthropic.com/v1/messages": {"type":"error","error":{"type":"overloaded_error","message":"busy"}}`, ErrorClassThrottling},
+		{"anthropic sdk rate limit", `{"type":"error","error":{"type":"rate_limit_error","message":"Number of request tokens has exceeded your per-minute rate limit"}}`, ErrorClassThrottling},
+		{"anthropic sdk api error", `{"type":"error","error":{"type":"api_error","message":"An unexpected error has occurred"}}`, ErrorClassInternal},
+		// Vertex / Gemini
+		{"gemini exhausted", "googleapi: Error 429: Resource has been exhausted (e.g. check quota).", ErrorClassThrottling},
+		{"gemini empty response", "empty response from model", ErrorClassEmptyContent},
+		{"gemini bad model", "googleapi: Error 404: Publisher Model gemini-9 was not found", ErrorClassFatal},
+		// OpenRouter
+		{"openrouter upstream", "API returned unexpected status code: 502: Provider returned error", ErrorClassThrottling},
+		// Transport
+		{"dns failure", `Post "https://api.openai.com/v1/chat/completions": dial tcp: lookup api.openai.com: no such host`, ErrorClassConnection},
+		{"connection reset", "read tcp 10.0.0.1:51234->52.1.1.1:443: read: connection reset by peer", ErrorClassConnection},
+		{"unexpected eof", "unexpected EOF", ErrorClassConnection},
+		{"http2 stream reset", "stream error: stream ID 3; INTERNAL_ERROR; received from peer", ErrorClassStream},
+		{"http2 goaway", "http2: server sent GOAWAY; ErrCode=INTERNAL_ERROR", ErrorClassInternal},
+		// Ordering: a deadline message is handled as max_token, as it always has been
+		{"deadline exceeded", "context deadline exceeded", ErrorClassMaxToken},
+	}
+
+	classifier := NewErrorClassifier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifier.Classify(errors.New(tt.err)); got != tt.want {
+				t.Errorf("Classify(%q) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	if got := classifier.Classify(nil); got != ErrorClassNone {
+		t.Errorf("Classify(nil) = %q, want none", got)
+	}
+}
+
+func TestErrorClassifierCustomRulesTakePrecedence(t *testing.T) {
+	classifier := NewErrorClassifier()
+	classifier.AddSubstring(ErrorClassThrottling, "Overloaded upstream")
+	if err := classifier.AddPattern(ErrorClassFatal, `status code: 4(0[0-9]|1[0-9])\b`); err != nil {
+		t.Fatalf("AddPattern: %v", err)
+	}
+
+	if got := classifier.Classify(errors.New("Overloaded upstream, try later")); got != ErrorClassThrottling {
+		t.Errorf("custom substring: got %q, want throttling", got)
+	}
+	// Built-in rules would call this max_token ("context"); the registered pattern wins
+	if got := classifier.Classify(errors.New("status code: 400, invalid context parameter")); got != ErrorClassFatal {
+		t.Errorf("custom pattern: got %q, want fatal", got)
+	}
+	// Other errors still use the built-in rules
+	if got := classifier.Classify(errors.New("status code: 429")); got != ErrorClassThrottling {
+		t.Errorf("built-in fallthrough: got %q, want throttling", got)
+	}
+
+	if err := classifier.AddPattern(ErrorClassFatal, "("); err == nil {
+		t.Error("expected an invalid pattern to be rejected")
+	}
+}
+
+func TestRetryLoopUsesAgentErrorClassifier(t *testing.T) {
+	llm := throttledLLM(100)
+	a, slept := newRetryTestAgent(t, llm, RetryConfig{MaxRetries: 3})
+
+	classifier := NewErrorClassifier()
+	classifier.AddSubstring(ErrorClassFatal, "Rate exceeded")
+	WithErrorClassifier(classifier)(a)
+
+	messages := []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}
+	if _, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {}); err == nil {
+		t.Fatal("expected the error to be returned")
+	}
+	if llm.calls != 1 || len(*slept) != 0 {
+		t.Fatalf("fatal errors must not be retried: %d calls, delays %v", llm.calls, *slept)
+	}
+}
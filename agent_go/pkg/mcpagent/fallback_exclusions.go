@@ -0,0 +1,77 @@
+package mcpagent
+
+import (
+	"strings"
+
+	"mcp-agent/agent_go/internal/llm"
+)
+
+// FallbackExclusions lists providers and models a request must never fall back to,
+// e.g. to keep data within a residency boundary. The primary model is not affected.
+type FallbackExclusions struct {
+	Providers []string `json:"exclude_providers,omitempty"`
+	Models    []string `json:"exclude_models,omitempty"`
+}
+
+// IsEmpty reports whether nothing is excluded
+func (e FallbackExclusions) IsEmpty() bool {
+	return len(e.Providers) == 0 && len(e.Models) == 0
+}
+
+// Allows reports whether modelID may be used as a fallback. listProvider is the provider the
+// fallback list belongs to ("" when unknown); the provider detected from the model ID is checked too.
+func (e FallbackExclusions) Allows(listProvider, modelID string) bool {
+	for _, model := range e.Models {
+		if strings.EqualFold(strings.TrimSpace(model), modelID) {
+			return false
+		}
+	}
+	detected := string(detectProviderFromModelID(modelID))
+	for _, provider := range e.Providers {
+		provider = strings.TrimSpace(provider)
+		if strings.EqualFold(provider, listProvider) || strings.EqualFold(provider, detected) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the models of a fallback list that are not excluded
+func (e FallbackExclusions) Filter(listProvider string, models []string) []string {
+	if e.IsEmpty() {
+		return models
+	}
+	allowed := make([]string, 0, len(models))
+	for _, modelID := range models {
+		if e.Allows(listProvider, modelID) {
+			allowed = append(allowed, modelID)
+		}
+	}
+	return allowed
+}
+
+// WithFallbackExclusions keeps the fallback chain away from the given providers and models
+func WithFallbackExclusions(exclusions FallbackExclusions) AgentOption {
+	return func(a *Agent) {
+		a.fallbackExclusions = exclusions
+	}
+}
+
+// SetFallbackExclusions replaces the providers and models the fallback chain must skip
+func (a *Agent) SetFallbackExclusions(exclusions FallbackExclusions) {
+	a.fallbackExclusions = exclusions
+}
+
+// excludeFallbacks drops excluded models from the same- and cross-provider fallback lists
+func (a *Agent) excludeFallbacks(provider llm.Provider, sameProviderFallbacks []string, crossProviderName string, crossProviderFallbacks []string) ([]string, []string) {
+	if a.fallbackExclusions.IsEmpty() {
+		return sameProviderFallbacks, crossProviderFallbacks
+	}
+	allowedSame := a.fallbackExclusions.Filter(string(provider), sameProviderFallbacks)
+	allowedCross := a.fallbackExclusions.Filter(crossProviderName, crossProviderFallbacks)
+	if skipped := len(sameProviderFallbacks) + len(crossProviderFallbacks) - len(allowedSame) - len(allowedCross); skipped > 0 {
+		getLogger(a).Infof("🚫 Skipping %d excluded fallback model(s) - excluded providers: %v, excluded models: %v",
+			skipped, a.fallbackExclusions.Providers, a.fallbackExclusions.Models)
+	}
+	return allowedSame, allowedCross
+}
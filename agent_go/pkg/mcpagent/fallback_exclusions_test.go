@@ -0,0 +1,71 @@
+package mcpagent
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// contextOverflowError fails a request with a max-token error so the fallback chain runs
+const contextOverflowError = "Input is too long for requested model"
+
+// newExclusionTestAgent returns a bedrock agent whose fallbacks are recorded instead of initialized;
+// bedrock fallbacks fail like the primary and every other fallback succeeds
+func newExclusionTestAgent(t *testing.T, exclusions FallbackExclusions) (*Agent, *[]string) {
+	t.Helper()
+	t.Setenv("BEDROCK_FALLBACK_MODELS", "us.anthropic.claude-3-5-haiku")
+	attempted := &[]string{}
+	a := newTestAgent(t, failingLLM(contextOverflowError),
+		WithCrossProviderFallback(&CrossProviderFallback{Provider: "openai", Models: []string{"gpt-4o", "gpt-4.1"}}),
+		WithFallbackExclusions(exclusions))
+	a.ModelID = "us.anthropic.claude-sonnet-4"
+	a.provider = "bedrock"
+	a.fallbackLLMFactory = func(modelID string) (llmtypes.Model, error) {
+		*attempted = append(*attempted, modelID)
+		if detectProviderFromModelID(modelID) == "bedrock" {
+			return failingLLM(contextOverflowError), nil
+		}
+		return answeringLLM("ok"), nil
+	}
+	return a, attempted
+}
+
+func TestExcludedProviderNeverAttemptedWhenPrimaryFails(t *testing.T) {
+	a, attempted := newExclusionTestAgent(t, FallbackExclusions{Providers: []string{"openai"}})
+
+	messages := []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}
+	if _, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {}); err == nil {
+		t.Fatal("expected the request to fail once only non-excluded fallbacks were tried")
+	}
+	if want := []string{"us.anthropic.claude-3-5-haiku"}; !reflect.DeepEqual(*attempted, want) {
+		t.Fatalf("expected only %v to be attempted, got %v", want, *attempted)
+	}
+}
+
+func TestExcludedModelSkippedInCrossProviderFallback(t *testing.T) {
+	a, attempted := newExclusionTestAgent(t, FallbackExclusions{Models: []string{"gpt-4o"}})
+
+	messages := []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}
+	if _, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {}); err != nil {
+		t.Fatalf("expected the allowed cross-provider fallback to succeed, got %v", err)
+	}
+	if want := []string{"us.anthropic.claude-3-5-haiku", "gpt-4.1"}; !reflect.DeepEqual(*attempted, want) {
+		t.Fatalf("expected %v to be attempted, got %v", want, *attempted)
+	}
+	if a.ModelID != "gpt-4.1" {
+		t.Fatalf("expected the agent to switch to gpt-4.1, got %s", a.ModelID)
+	}
+}
+
+func TestFallbackExclusionsFilterByListAndDetectedProvider(t *testing.T) {
+	exclusions := FallbackExclusions{Providers: []string{"OpenAI"}, Models: []string{"gemini-2.5-pro"}}
+	got := exclusions.Filter("vertex", []string{"gemini-2.5-pro", "gemini-2.5-flash", "gpt-4o"})
+	if want := []string{"gemini-2.5-flash"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got := exclusions.Filter("openai", []string{"custom-model"}); len(got) != 0 {
+		t.Fatalf("expected every model of an excluded provider's list to be dropped, got %v", got)
+	}
+}
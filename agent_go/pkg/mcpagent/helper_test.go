@@ -0,0 +1,254 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+// newTestAgent returns a simple agent on llm with options applied
+func newTestAgent(t *testing.T, llm llmtypes.Model, options ...AgentOption) *Agent {
+	t.Helper()
+	a := &Agent{
+		LLM:       llm,
+		ModelID:   "test-model",
+		TraceID:   "trace-1",
+		Logger:    logger.CreateDiscardLogger("error"),
+		AgentMode: SimpleAgent,
+		MaxTurns:  5,
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// eventCollector records the events of type T an agent emits
+type eventCollector[T events.EventData] struct {
+	mu     sync.Mutex
+	events []T
+}
+
+// collectEvents registers a collector for the events of type T on a
+func collectEvents[T events.EventData](a *Agent) *eventCollector[T] {
+	c := &eventCollector[T]{}
+	a.AddEventListener(c)
+	return c
+}
+
+func (c *eventCollector[T]) HandleEvent(ctx context.Context, event *events.AgentEvent) error {
+	if data, ok := event.Data.(T); ok {
+		c.mu.Lock()
+		c.events = append(c.events, data)
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+func (c *eventCollector[T]) Name() string {
+	return "event-collector"
+}
+
+// all returns the events collected so far
+func (c *eventCollector[T]) all() []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]T(nil), c.events...)
+}
+
+// last returns the latest event collected, or the zero T if there is none
+func (c *eventCollector[T]) last() T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var last T
+	if len(c.events) > 0 {
+		last = c.events[len(c.events)-1]
+	}
+	return last
+}
+
+// fakeLLM answers every call through respond and records the messages and resolved options it received
+type fakeLLM struct {
+	mu      sync.Mutex
+	respond func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error)
+	calls   int
+	prompts [][]llmtypes.MessageContent
+	options []llmtypes.CallOptions
+}
+
+func (f *fakeLLM) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	var resolved llmtypes.CallOptions
+	for _, option := range options {
+		option(&resolved)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	f.prompts = append(f.prompts, append([]llmtypes.MessageContent{}, messages...))
+	f.options = append(f.options, resolved)
+	return f.respond(f.calls, messages)
+}
+
+// promptText returns the text of the last message of the call-th prompt, counting from 0
+func (f *fakeLLM) promptText(call int) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var text strings.Builder
+	prompt := f.prompts[call]
+	for _, part := range prompt[len(prompt)-1].Parts {
+		if content, ok := part.(llmtypes.TextContent); ok {
+			text.WriteString(content.Text)
+		}
+	}
+	return text.String()
+}
+
+// lastToolResult returns the latest tool result the LLM was given
+func (f *fakeLLM) lastToolResult() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.prompts) - 1; i >= 0; i-- {
+		last := f.prompts[i][len(f.prompts[i])-1]
+		if last.Role != llmtypes.ChatMessageTypeTool {
+			continue
+		}
+		if response, ok := last.Parts[0].(llmtypes.ToolCallResponse); ok {
+			return response.Content
+		}
+	}
+	return ""
+}
+
+// textResponse is a response answering with content
+func textResponse(content string) *llmtypes.ContentResponse {
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: content}}}
+}
+
+// toolCallResponse is a response requesting the tool calls
+func toolCallResponse(calls ...llmtypes.ToolCall) *llmtypes.ContentResponse {
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{ToolCalls: calls}}}
+}
+
+// toolCall is a call of the named tool with JSON arguments
+func toolCall(id, name, arguments string) llmtypes.ToolCall {
+	return llmtypes.ToolCall{ID: id, Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: name, Arguments: arguments}}
+}
+
+// toolResults returns the tool results in messages, in order
+func toolResults(messages []llmtypes.MessageContent) []llmtypes.ToolCallResponse {
+	var results []llmtypes.ToolCallResponse
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if response, ok := part.(llmtypes.ToolCallResponse); ok {
+				results = append(results, response)
+			}
+		}
+	}
+	return results
+}
+
+// answeringLLM always answers with content
+func answeringLLM(content string) *fakeLLM {
+	return &fakeLLM{respond: func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		return textResponse(content), nil
+	}}
+}
+
+// failingLLM fails every call with message
+func failingLLM(message string) *fakeLLM {
+	return &fakeLLM{respond: func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		return nil, errors.New(message)
+	}}
+}
+
+// throttledLLM fails with a throttling error for the first failures calls, then answers
+func throttledLLM(failures int) *fakeLLM {
+	return &fakeLLM{respond: func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		if call <= failures {
+			return nil, errors.New("ThrottlingException: Rate exceeded")
+		}
+		return textResponse("ok"), nil
+	}}
+}
+
+// scriptedLLM returns the choices in order and fails any call beyond them
+func scriptedLLM(choices []*llmtypes.ContentChoice) *fakeLLM {
+	return &fakeLLM{respond: func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		if call > len(choices) {
+			return nil, errors.New("unexpected LLM call")
+		}
+		return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{choices[call-1]}}, nil
+	}}
+}
+
+// sequentialToolLLM requests the calls one per turn, then answers with answer
+func sequentialToolLLM(answer string, calls ...llmtypes.FunctionCall) *fakeLLM {
+	return &fakeLLM{respond: func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		if call > len(calls) {
+			return textResponse(answer), nil
+		}
+		next := calls[call-1]
+		return toolCallResponse(toolCall("call-"+next.Name, next.Name, next.Arguments)), nil
+	}}
+}
+
+// parallelToolLLM requests all calls in its first response, then answers with the tool results as "id=result;..."
+func parallelToolLLM(calls ...llmtypes.FunctionCall) *fakeLLM {
+	return &fakeLLM{respond: func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		if call == 1 {
+			toolCalls := make([]llmtypes.ToolCall, 0, len(calls))
+			for i, c := range calls {
+				toolCalls = append(toolCalls, toolCall("call-"+string(rune('a'+i)), c.Name, c.Arguments))
+			}
+			return toolCallResponse(toolCalls...), nil
+		}
+		var results []string
+		for _, result := range toolResults(messages) {
+			results = append(results, result.ToolCallID+"="+result.Content)
+		}
+		return textResponse(strings.Join(results, ";")), nil
+	}}
+}
+
+// echoToolLLM requests tool once, then answers with the tool result it was given
+func echoToolLLM(tool string) *fakeLLM {
+	return &fakeLLM{respond: echoToolResponder(tool)}
+}
+
+func echoToolResponder(tool string) func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+	return func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		results := toolResults(messages)
+		if len(results) == 0 {
+			return toolCallResponse(toolCall("call-1", tool, "{}")), nil
+		}
+		return textResponse(results[0].Content), nil
+	}
+}
+
+// slowToolLLM takes delay per call, requests slow_tool once and then answers "done"
+func slowToolLLM(delay time.Duration) *fakeLLM {
+	return &fakeLLM{respond: slowToolResponder(delay)}
+}
+
+func slowToolResponder(delay time.Duration) func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+	return func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		time.Sleep(delay)
+		if len(toolResults(messages)) == 0 {
+			return toolCallResponse(toolCall("call-1", "slow_tool", "{}")), nil
+		}
+		return textResponse("done"), nil
+	}
+}
+
+// onOpenAI runs the agent on gpt-4o with the openai provider, whose fallbacks come from OPENAI_FALLBACK_MODELS
+func onOpenAI(a *Agent) {
+	a.ModelID = "gpt-4o"
+	a.provider = "openai"
+}
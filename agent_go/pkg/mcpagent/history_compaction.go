@@ -0,0 +1,171 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"mcp-agent/agent_go/internal/llm"
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+const (
+	// defaultHistoryCompactionThreshold is the fraction of the context window a prompt may fill before compaction
+	defaultHistoryCompactionThreshold = 0.8
+	// defaultHistoryCompactionKeepTurns is how many of the most recent turns compaction never drops
+	defaultHistoryCompactionKeepTurns = 2
+)
+
+// WithHistoryCompaction drops the oldest turns of the conversation before an LLM call when the
+// estimated prompt exceeds threshold (a fraction of the model's context window, 0 uses 0.8).
+// System messages and the keepRecentTurns most recent turns (0 uses 2) are always kept; a note
+// tells the model that earlier messages were omitted. The context window comes from
+// WithContextWindowModels when it lists the model, else from llm.ContextWindow.
+func WithHistoryCompaction(threshold float64, keepRecentTurns int) AgentOption {
+	return func(a *Agent) {
+		a.historyCompaction = true
+		a.historyCompactionThreshold = threshold
+		a.historyCompactionKeepTurns = keepRecentTurns
+	}
+}
+
+// estimateMessageTokens estimates the tokens of a message including tool calls and tool results,
+// which estimateInputTokens leaves out
+func estimateMessageTokens(message llmtypes.MessageContent) int {
+	chars := 0
+	for _, part := range message.Parts {
+		switch p := part.(type) {
+		case llmtypes.TextContent:
+			chars += len(p.Text)
+		case llmtypes.ToolCall:
+			if p.FunctionCall != nil {
+				chars += len(p.FunctionCall.Name) + len(p.FunctionCall.Arguments)
+			}
+		case llmtypes.ToolCallResponse:
+			chars += len(p.Name) + len(p.Content)
+		}
+	}
+	return chars/4 + 4 // Per-message role and formatting overhead
+}
+
+// estimateHistoryTokens estimates the prompt size of the messages plus the tool definitions
+func estimateHistoryTokens(messages []llmtypes.MessageContent, tools []llmtypes.Tool) int {
+	tokens := 0
+	for _, message := range messages {
+		tokens += estimateMessageTokens(message)
+	}
+	if len(tools) > 0 {
+		if toolJSON, err := json.Marshal(tools); err == nil {
+			tokens += len(toolJSON) / 4
+		}
+	}
+	return tokens
+}
+
+// historyTurns splits the non-system messages into turns that can be dropped as a unit. A turn
+// starts at each user or assistant message; tool results stay with the assistant message that
+// requested them, so a dropped tool call never leaves an orphaned result behind.
+func historyTurns(messages []llmtypes.MessageContent) [][]llmtypes.MessageContent {
+	var turns [][]llmtypes.MessageContent
+	for _, message := range messages {
+		startsTurn := message.Role != llmtypes.ChatMessageTypeTool || len(turns) == 0
+		if startsTurn {
+			turns = append(turns, nil)
+		}
+		turns[len(turns)-1] = append(turns[len(turns)-1], message)
+	}
+	return turns
+}
+
+// compactHistory drops the oldest turns until the estimate fits limit or only keepTurns remain.
+// It returns the compacted messages and how many messages were dropped.
+func compactHistory(messages []llmtypes.MessageContent, tools []llmtypes.Tool, limit, keepTurns int) ([]llmtypes.MessageContent, int) {
+	if keepTurns < 1 {
+		keepTurns = 1
+	}
+	var system, conversation []llmtypes.MessageContent
+	for _, message := range messages {
+		if message.Role == llmtypes.ChatMessageTypeSystem {
+			system = append(system, message)
+		} else {
+			conversation = append(conversation, message)
+		}
+	}
+
+	turns := historyTurns(conversation)
+	tokens := estimateHistoryTokens(messages, tools)
+	dropped, droppedTurns := 0, 0
+	for tokens > limit && len(turns)-droppedTurns > keepTurns {
+		for _, message := range turns[droppedTurns] {
+			tokens -= estimateMessageTokens(message)
+			dropped++
+		}
+		droppedTurns++
+	}
+	if dropped == 0 {
+		return messages, 0
+	}
+
+	compacted := append([]llmtypes.MessageContent{}, system...)
+	note := llmtypes.TextContent{Text: fmt.Sprintf("[%d earlier messages of this conversation were omitted to fit the context window.]", dropped)}
+	kept := conversation[dropped:]
+	if kept[0].Role == llmtypes.ChatMessageTypeHuman {
+		// Merge the note into the first kept user message to avoid two consecutive user messages
+		first := llmtypes.MessageContent{Role: kept[0].Role, Parts: append([]llmtypes.ContentPart{note}, kept[0].Parts...)}
+		compacted = append(compacted, first)
+		kept = kept[1:]
+	} else {
+		// Providers expect the conversation to open with a user message
+		compacted = append(compacted, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{note}})
+	}
+	return append(compacted, kept...), dropped
+}
+
+// historyContextWindow returns the context window of the agent's current model, or 0 when unknown
+func (a *Agent) historyContextWindow() int {
+	if window, known := a.contextWindowModels[a.ModelID]; known {
+		return window
+	}
+	return llm.ContextWindow(a.ModelID)
+}
+
+// compactHistoryForCall drops the oldest turns when the prompt would exceed the compaction
+// threshold of the context window, emitting a HistoryCompacted event
+func (a *Agent) compactHistoryForCall(ctx context.Context, turn int, messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+	if !a.historyCompaction {
+		return messages
+	}
+	logger := getLogger(a)
+
+	window := a.historyContextWindow()
+	if window <= 0 {
+		logger.Infof("Context window of model %s is unknown, skipping history compaction", a.ModelID)
+		return messages
+	}
+	threshold := a.historyCompactionThreshold
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultHistoryCompactionThreshold
+	}
+	keepTurns := a.historyCompactionKeepTurns
+	if keepTurns <= 0 {
+		keepTurns = defaultHistoryCompactionKeepTurns
+	}
+
+	limit := int(float64(window) * threshold)
+	before := estimateHistoryTokens(messages, a.filteredTools)
+	if before <= limit {
+		return messages
+	}
+
+	compacted, dropped := compactHistory(messages, a.filteredTools, limit, keepTurns)
+	if dropped == 0 {
+		logger.Warnf("Estimated prompt of %d tokens exceeds %d tokens (%.0f%% of %s's window) but only the last %d turns remain", before, limit, threshold*100, a.ModelID, keepTurns)
+		return messages
+	}
+	after := estimateHistoryTokens(compacted, a.filteredTools)
+	logger.Infof("🗜️ Turn %d: dropped %d oldest messages, estimated prompt %d -> %d tokens (limit %d)", turn, dropped, before, after, limit)
+
+	a.EmitTypedEvent(ctx, events.NewHistoryCompactedEvent(turn, a.ModelID, window, limit, before, after, dropped, len(compacted)))
+	return compacted
+}
@@ -0,0 +1,121 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+const compactionSystemPrompt = "You are a careful research assistant."
+
+// oversizedHistory is a system prompt followed by turns of a user question, a tool call and a ~1000 token tool result
+func oversizedHistory(turns int) []llmtypes.MessageContent {
+	history := []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeSystem, compactionSystemPrompt)}
+	for i := 0; i < turns; i++ {
+		callID := fmt.Sprintf("call-%d", i)
+		history = append(history,
+			llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, fmt.Sprintf("question %d", i)),
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{llmtypes.ToolCall{ID: callID, Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "search", Arguments: `{"q":"x"}`}}}},
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: callID, Name: "search", Content: strings.Repeat("r", 4000)}}},
+			llmtypes.TextPart(llmtypes.ChatMessageTypeAI, fmt.Sprintf("answer %d", i)),
+		)
+	}
+	return history
+}
+
+func TestHistoryCompactionBringsOversizedHistoryUnderLimit(t *testing.T) {
+	history := oversizedHistory(10)
+	limit := 3000
+	if before := estimateHistoryTokens(history, nil); before <= limit {
+		t.Fatalf("test history must exceed the limit, estimated %d tokens", before)
+	}
+
+	compacted, dropped := compactHistory(history, nil, limit, 1)
+
+	if after := estimateHistoryTokens(compacted, nil); after > limit {
+		t.Fatalf("expected the compacted history under %d tokens, estimated %d", limit, after)
+	}
+	if dropped == 0 || len(compacted) >= len(history) {
+		t.Fatalf("expected messages to be dropped, dropped %d of %d", dropped, len(history))
+	}
+	if compacted[0].Role != llmtypes.ChatMessageTypeSystem || compacted[0].Parts[0].(llmtypes.TextContent).Text != compactionSystemPrompt {
+		t.Fatalf("expected the system prompt to be preserved, got %+v", compacted[0])
+	}
+	if compacted[1].Role != llmtypes.ChatMessageTypeHuman || !strings.Contains(compacted[1].Parts[0].(llmtypes.TextContent).Text, "omitted to fit the context window") {
+		t.Fatalf("expected a user message noting the omission after the system prompt, got %+v", compacted[1])
+	}
+	if last := compacted[len(compacted)-1]; last.Parts[0].(llmtypes.TextContent).Text != "answer 9" {
+		t.Fatalf("expected the most recent turn to be kept, got %+v", last)
+	}
+
+	// Every kept tool result still follows the tool call that requested it
+	calls := map[string]bool{}
+	for _, message := range compacted {
+		for _, part := range message.Parts {
+			switch p := part.(type) {
+			case llmtypes.ToolCall:
+				calls[p.ID] = true
+			case llmtypes.ToolCallResponse:
+				if !calls[p.ToolCallID] {
+					t.Fatalf("tool result %s kept without its tool call", p.ToolCallID)
+				}
+			}
+		}
+	}
+}
+
+func TestHistoryCompactionRunsBeforeTheLLMCall(t *testing.T) {
+	t.Setenv("LLM_MODEL_CONTEXT_WINDOWS", "test-model=6000")
+	fake := answeringLLM("done")
+	a := newTestAgent(t, fake, WithHistoryCompaction(0.5, 2))
+	a.SystemPrompt = compactionSystemPrompt
+	compactions := collectEvents[*events.HistoryCompactedEvent](a)
+
+	history := append(oversizedHistory(10), llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "summarize what you found"))
+	if _, _, err := a.AskWithHistory(context.Background(), history); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(fake.prompts) == 0 {
+		t.Fatal("expected the LLM to be called")
+	}
+	prompt := fake.prompts[0]
+	if estimated := estimateHistoryTokens(prompt, nil); estimated > 3000 {
+		t.Fatalf("expected the prompt compacted under 3000 tokens, estimated %d", estimated)
+	}
+	if prompt[0].Role != llmtypes.ChatMessageTypeSystem || prompt[0].Parts[0].(llmtypes.TextContent).Text != compactionSystemPrompt {
+		t.Fatalf("expected the system prompt first, got %+v", prompt[0])
+	}
+	if last := prompt[len(prompt)-1]; last.Parts[0].(llmtypes.TextContent).Text != "summarize what you found" {
+		t.Fatalf("expected the latest user message to be kept, got %+v", last)
+	}
+
+	compacted := compactions.all()
+	if len(compacted) != 1 {
+		t.Fatalf("expected one history compacted event, got %d", len(compacted))
+	}
+	if event := compacted[0]; event.ContextWindow != 6000 || event.TokenLimit != 3000 || event.TokensAfter > 3000 || event.MessagesDropped == 0 {
+		t.Fatalf("unexpected history compacted event %+v", event)
+	}
+}
+
+func TestHistoryCompactionDisabledOrFitting(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+	history := oversizedHistory(10)
+
+	a := &Agent{ModelID: "test-model", Logger: testLogger}
+	if got := a.compactHistoryForCall(context.Background(), 1, history); len(got) != len(history) {
+		t.Fatalf("expected no compaction when disabled, got %d of %d messages", len(got), len(history))
+	}
+
+	WithHistoryCompaction(0.8, 2)(a)
+	a.contextWindowModels = map[string]int{"test-model": 1000000}
+	if got := a.compactHistoryForCall(context.Background(), 1, history); len(got) != len(history) {
+		t.Fatalf("expected no compaction when the history fits, got %d of %d messages", len(got), len(history))
+	}
+}
@@ -0,0 +1,157 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// LargeOutputPolicy decides what is inserted into the conversation for a large tool output
+type LargeOutputPolicy string
+
+const (
+	// LargeOutputWriteToFile writes the output to disk and inserts a preview with the file path (default)
+	LargeOutputWriteToFile LargeOutputPolicy = "write_to_file"
+	// LargeOutputSummarize writes the output to disk and inserts an LLM summary with the file path
+	LargeOutputSummarize LargeOutputPolicy = "summarize"
+	// LargeOutputTruncate inserts the beginning of the output, cut at the threshold, without writing a file
+	LargeOutputTruncate LargeOutputPolicy = "truncate"
+)
+
+// largeOutputSummaryInputBytes caps how much of a large output is sent to the summarizer
+const largeOutputSummaryInputBytes = 64 * 1024
+
+const largeOutputSummarySchema = `{
+  "type": "object",
+  "properties": {
+    "summary": {"type": "string", "description": "Concise summary of the tool output"}
+  },
+  "required": ["summary"]
+}`
+
+// WithLargeOutputThreshold treats tool outputs larger than bytes as large. Without it the tool output
+// handler's token threshold applies.
+func WithLargeOutputThreshold(bytes int) AgentOption {
+	return func(a *Agent) {
+		a.largeOutputThreshold = bytes
+	}
+}
+
+// WithLargeOutputPolicy sets how large tool outputs are handled; the default is LargeOutputWriteToFile
+func WithLargeOutputPolicy(policy LargeOutputPolicy) AgentOption {
+	return func(a *Agent) {
+		a.largeOutputPolicy = policy
+	}
+}
+
+// isLargeToolOutput reports whether resultText exceeds the large output threshold
+func (a *Agent) isLargeToolOutput(resultText string) bool {
+	if a.toolOutputHandler == nil || !a.toolOutputHandler.Enabled {
+		return false
+	}
+	if a.largeOutputThreshold > 0 {
+		return len(resultText) > a.largeOutputThreshold
+	}
+	return a.toolOutputHandler.IsLargeToolOutputWithModel(resultText, a.ModelID)
+}
+
+// handleLargeToolOutput applies the large output policy to resultText and returns what goes into the
+// conversation instead; handled is false when the output is kept as is because the file write failed
+func (a *Agent) handleLargeToolOutput(ctx context.Context, toolCallID, toolName, resultText string) (string, bool) {
+	policy := a.largeOutputPolicy
+	if policy == "" {
+		policy = LargeOutputWriteToFile
+	}
+
+	detectedEvent := events.NewLargeToolOutputDetectedEvent(toolName, len(resultText), a.toolOutputHandler.GetToolOutputFolder())
+	detectedEvent.ServerAvailable = a.toolOutputHandler.IsServerAvailable()
+	detectedEvent.Threshold = a.largeOutputLimit()
+	detectedEvent.Policy = string(policy)
+	a.EmitTypedEvent(ctx, detectedEvent)
+
+	handledEvent := &events.LargeToolOutputHandledEvent{ToolName: toolName, Policy: string(policy), OutputSize: len(resultText)}
+	defer func() { a.EmitTypedEvent(ctx, handledEvent) }()
+
+	if policy == LargeOutputTruncate {
+		limit := a.largeOutputLimit()
+		truncated := truncateUTF8(resultText, limit) + fmt.Sprintf("\n\n[Output truncated: showing the first %d of %d bytes]", limit, len(resultText))
+		handledEvent.ResultSize = len(truncated)
+		return truncated, true
+	}
+
+	// Both remaining policies keep the full output on disk for the large output virtual tools
+	filePath, writeErr := a.toolOutputHandler.WriteToolOutputToFile(resultText, toolName)
+	if writeErr != nil {
+		a.EmitTypedEvent(ctx, events.NewLargeToolOutputFileWriteErrorEvent(toolName, writeErr.Error(), len(resultText)))
+		handledEvent.Error = writeErr.Error()
+		handledEvent.ResultSize = len(resultText)
+		return resultText, false
+	}
+	preview := a.toolOutputHandler.ExtractFirstNCharacters(resultText, 100)
+	a.EmitTypedEvent(ctx, events.NewLargeToolOutputFileWrittenEvent(toolName, filePath, len(resultText), preview))
+	handledEvent.FilePath = filePath
+
+	message := a.toolOutputHandler.CreateToolOutputMessageWithPreview(toolCallID, filePath, resultText)
+	if policy == LargeOutputSummarize {
+		summary, err := a.summarizeLargeToolOutput(ctx, toolName, resultText)
+		if err != nil {
+			// Fall back to the preview so the output is still reachable
+			a.Logger.Warnf("Failed to summarize large output of %s, using a preview instead: %v", toolName, err)
+			handledEvent.Error = err.Error()
+		} else {
+			handledEvent.Summary = summary
+			message = fmt.Sprintf("The tool output (%d bytes) was summarized:\n%s\n\nThe full output is saved to: %s\nUse read_large_output, search_large_output or query_large_output on that file when you need details the summary leaves out.",
+				len(resultText), summary, strings.ReplaceAll(filePath, "\\", "/"))
+		}
+	}
+	handledEvent.ResultSize = len(message)
+	return message, true
+}
+
+// summarizeLargeToolOutput asks the structured output LLM for a short summary of a large tool output
+func (a *Agent) summarizeLargeToolOutput(ctx context.Context, toolName, resultText string) (string, error) {
+	content := resultText
+	if len(content) > largeOutputSummaryInputBytes {
+		content = truncateUTF8(content, largeOutputSummaryInputBytes) + "\n[...remaining output omitted]"
+	}
+	prompt := fmt.Sprintf("Summarize the output of the tool %q in a few sentences. Keep the facts, identifiers, counts and errors needed to answer the user's request.\n\nTool output:\n%s", toolName, content)
+
+	jsonOutput, err := getOrCreateStructuredOutputGenerator(a).GenerateStructuredOutput(ctx, prompt, largeOutputSummarySchema)
+	if err != nil {
+		return "", err
+	}
+	var response struct {
+		Summary string `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(jsonOutput), &response); err != nil {
+		return "", fmt.Errorf("failed to parse summary: %w", err)
+	}
+	if strings.TrimSpace(response.Summary) == "" {
+		return "", fmt.Errorf("summary is empty")
+	}
+	return strings.TrimSpace(response.Summary), nil
+}
+
+// largeOutputLimit is the byte size large outputs are truncated to: the configured threshold, or
+// the tool output handler's threshold
+func (a *Agent) largeOutputLimit() int {
+	if a.largeOutputThreshold > 0 {
+		return a.largeOutputThreshold
+	}
+	return a.toolOutputHandler.Threshold
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,131 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/internal/utils"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+const largeOutputTestSummary = "The logs show 12 timeouts in the payment service"
+
+// largeOutputLLM calls fetch_logs, summarizes when asked and then answers with the tool result it was given;
+// summaries counts the summarization calls
+func largeOutputLLM() (llm *fakeLLM, summaries *int) {
+	summaries = new(int)
+	conversation := echoToolResponder("fetch_logs")
+	return &fakeLLM{respond: func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		for _, msg := range messages {
+			for _, part := range msg.Parts {
+				if text, ok := part.(llmtypes.TextContent); ok && strings.Contains(text.Text, "Summarize the output of the tool") {
+					*summaries++
+					return textResponse(`{"summary": "` + largeOutputTestSummary + `"}`), nil
+				}
+			}
+		}
+		return conversation(call, messages)
+	}}, summaries
+}
+
+// askWithLargeOutputTool runs a conversation whose only tool returns a 1MB string and returns the
+// tool result the LLM saw and the handled event
+func askWithLargeOutputTool(t *testing.T, options ...AgentOption) (string, *events.LargeToolOutputHandledEvent, int) {
+	t.Helper()
+	llm, summaries := largeOutputLLM()
+	output := strings.Repeat("2024-01-01 payment-service timeout\n", 1<<20/35+1)
+	// The large output options configure the handler, so it must exist before they apply
+	withHandler := func(a *Agent) {
+		a.toolOutputHandler = utils.NewToolOutputHandlerWithConfig(utils.DefaultLargeToolOutputThreshold, t.TempDir(), "session-1", true, true)
+	}
+	a := newTestAgent(t, llm, append([]AgentOption{withHandler}, options...)...)
+	a.customTools = map[string]CustomTool{
+		"fetch_logs": {Execution: func(ctx context.Context, args map[string]interface{}) (string, error) {
+			return output, nil
+		}},
+	}
+	handledEvents := collectEvents[*events.LargeToolOutputHandledEvent](a)
+
+	answer, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "why are payments failing?"}}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	recorded := handledEvents.all()
+	if len(recorded) != 1 {
+		t.Fatalf("expected one large output handled event, got %d", len(recorded))
+	}
+	handled := recorded[0]
+	if handled.OutputSize < 1<<20 {
+		t.Fatalf("handled event output size = %d, want the 1MB output", handled.OutputSize)
+	}
+	return answer, handled, *summaries
+}
+
+func assertFullOutputWritten(t *testing.T, handled *events.LargeToolOutputHandledEvent) {
+	t.Helper()
+	data, err := os.ReadFile(handled.FilePath)
+	if err != nil {
+		t.Fatalf("full output not written to disk: %v", err)
+	}
+	if len(data) < 1<<20 {
+		t.Fatalf("file holds %d bytes, want the full output", len(data))
+	}
+}
+
+func TestLargeOutputWriteToFilePolicy(t *testing.T) {
+	answer, handled, _ := askWithLargeOutputTool(t, WithLargeOutputThreshold(4096))
+
+	if handled.Policy != string(LargeOutputWriteToFile) {
+		t.Fatalf("policy = %q, want the write_to_file default", handled.Policy)
+	}
+	assertFullOutputWritten(t, handled)
+	if !strings.Contains(answer, handled.FilePath) || len(answer) >= 1<<20 {
+		t.Fatalf("expected a preview pointing at %s, got %d bytes", handled.FilePath, len(answer))
+	}
+}
+
+func TestLargeOutputSummarizePolicy(t *testing.T) {
+	answer, handled, summaries := askWithLargeOutputTool(t, WithLargeOutputThreshold(4096), WithLargeOutputPolicy(LargeOutputSummarize))
+
+	if handled.Policy != string(LargeOutputSummarize) || handled.Summary != largeOutputTestSummary || handled.Error != "" {
+		t.Fatalf("unexpected handled event %+v", handled)
+	}
+	if summaries != 1 {
+		t.Fatalf("expected one summarization call, got %d", summaries)
+	}
+	assertFullOutputWritten(t, handled)
+	if !strings.Contains(answer, largeOutputTestSummary) || !strings.Contains(answer, handled.FilePath) {
+		t.Fatalf("expected the summary and file path in the conversation, got %q", answer)
+	}
+	if handled.ResultSize != len(answer) {
+		t.Fatalf("result size = %d, want %d", handled.ResultSize, len(answer))
+	}
+}
+
+func TestLargeOutputTruncatePolicy(t *testing.T) {
+	answer, handled, _ := askWithLargeOutputTool(t, WithLargeOutputThreshold(4096), WithLargeOutputPolicy(LargeOutputTruncate))
+
+	if handled.Policy != string(LargeOutputTruncate) || handled.FilePath != "" {
+		t.Fatalf("unexpected handled event %+v", handled)
+	}
+	if !strings.HasPrefix(answer, "2024-01-01 payment-service timeout") || !strings.Contains(answer, "[Output truncated: showing the first 4096 of") {
+		t.Fatalf("expected the output cut at 4096 bytes, got %q", answer)
+	}
+	if len(answer) > 4096+100 {
+		t.Fatalf("truncated output is %d bytes", len(answer))
+	}
+}
+
+func TestLargeOutputThresholdNotExceeded(t *testing.T) {
+	a := &Agent{toolOutputHandler: utils.NewToolOutputHandler()}
+	WithLargeOutputThreshold(2 << 20)(a)
+	if a.isLargeToolOutput(strings.Repeat("a", 1<<20)) {
+		t.Fatal("expected a 1MB output under a 2MB threshold not to be large")
+	}
+}
@@ -14,169 +14,44 @@ import (
 
 // GenerateContentWithRetry handles LLM generation with robust retry logic for throttling errors
 func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes.MessageContent, opts []llmtypes.CallOption, turn int, sendMessage func(string)) (*llmtypes.ContentResponse, error, observability.UsageMetrics) {
+	start := time.Now()
+	modelID := a.ModelID
+	opts = a.withOutputLimitOptions(opts)
+	resp, err, usage := generateContentWithRetry(a, ctx, messages, opts, turn, sendMessage)
+	if err == nil {
+		// A fallback that answered becomes the agent's model
+		a.recordGenerationUsage(a.ModelID, usage, a.ModelID != modelID)
+		// One end event per successful call, whichever model answered; usage meters count these
+		if resp != nil && len(resp.Choices) > 0 {
+			a.EmitTypedEvent(ctx, events.NewLLMGenerationEndEvent(turn+1, resp.Choices[0].Content, len(resp.Choices[0].ToolCalls), time.Since(start), events.UsageMetrics{
+				PromptTokens:     usage.InputTokens,
+				CompletionTokens: usage.OutputTokens,
+				TotalTokens:      usage.TotalTokens,
+			}))
+		}
+	}
+
+	// Persist the raw request/response for audit and replay, separate from the event flow
+	if sink := getLLMAuditSink(a); sink != nil {
+		recordLLMAudit(a, ctx, sink, turn, messages, opts, resp, err, start)
+	}
+	return resp, err, usage
+}
+
+// generateContentWithRetry implements GenerateContentWithRetry
+func generateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes.MessageContent, opts []llmtypes.CallOption, turn int, sendMessage func(string)) (*llmtypes.ContentResponse, error, observability.UsageMetrics) {
 	// 🆕 DETAILED GENERATECONTENTWITHRETRY DEBUG LOGGING
 	logger := getLogger(a)
 	logger.Infof("🔄 [DEBUG] GenerateContentWithRetry START - Time: %v", time.Now())
 	logger.Infof("🔄 [DEBUG] GenerateContentWithRetry params - Messages: %d, Options: %d, Turn: %d", len(messages), len(opts), turn)
 	logger.Infof("🔄 [DEBUG] GenerateContentWithRetry context - Err: %v, Done: %v", ctx.Err(), ctx.Done())
 
-	maxRetries := 5
-	baseDelay := 30 * time.Second // Start with 30s for throttling
-	maxDelay := 5 * time.Minute   // Maximum 5 minutes
+	retryConfig := a.RetryConfig.withDefaults()
+	maxRetries := retryConfig.MaxRetries
+	maxDelay := retryConfig.MaxDelay
 	var lastErr error
 	var usage observability.UsageMetrics
 
-	isMaxTokenError := func(err error) bool {
-		if err == nil {
-			return false
-		}
-		msg := err.Error()
-		isMaxToken := strings.Contains(msg, "max_token") ||
-			strings.Contains(msg, "context") ||
-			strings.Contains(msg, "max tokens") ||
-			strings.Contains(msg, "Input is too long") ||
-			strings.Contains(msg, "ValidationException") ||
-			strings.Contains(msg, "too long")
-
-		// Enhanced debugging for max token error detection
-		if isMaxToken {
-			// Note: logger will be available in the main function scope
-			// This will be logged when the error is actually processed
-		}
-
-		return isMaxToken
-		// REMOVED: Empty content patterns to prevent conflict with isEmptyContentError
-		// Empty content errors should only be handled by isEmptyContentError function
-	}
-
-	isThrottlingError := func(err error) bool {
-		if err == nil {
-			return false
-		}
-		errStr := err.Error()
-		isThrottling := strings.Contains(errStr, "ThrottlingException") ||
-			strings.Contains(errStr, "Too many tokens") ||
-			strings.Contains(errStr, "StatusCode: 429") ||
-			strings.Contains(errStr, "API returned unexpected status code: 429") ||
-			strings.Contains(errStr, "status code: 429") ||
-			strings.Contains(errStr, "status code 429") ||
-			strings.Contains(errStr, "429") ||
-			strings.Contains(errStr, "rate limit") ||
-			strings.Contains(errStr, "throttled") ||
-			// Add server errors (5xx) to trigger fallback
-			strings.Contains(errStr, "502") ||
-			strings.Contains(errStr, "503") ||
-			strings.Contains(errStr, "504") ||
-			strings.Contains(errStr, "500") ||
-			strings.Contains(errStr, "API returned unexpected status code: 5") ||
-			strings.Contains(errStr, "Provider returned error") ||
-			strings.Contains(errStr, "Bad Gateway") ||
-			strings.Contains(errStr, "Service Unavailable") ||
-			strings.Contains(errStr, "Gateway Timeout")
-
-		// Enhanced debugging for throttling error detection
-		if isThrottling {
-			// Note: logger will be available in the main function scope
-			// This will be logged when the error is actually processed
-		}
-
-		return isThrottling
-	}
-
-	// Helper function to check if an error is an empty content error
-	isEmptyContentError := func(err error) bool {
-		if err == nil {
-			return false
-		}
-		msg := err.Error()
-		isEmptyContent := strings.Contains(msg, "Choice.Content is empty string") ||
-			strings.Contains(msg, "empty content error") ||
-			strings.Contains(msg, "choice.Content is empty") ||
-			strings.Contains(msg, "empty response")
-
-		// Enhanced debugging for empty content error detection
-		if isEmptyContent {
-			// Note: logger will be available in the main function scope
-			// This will be logged when the error is actually processed
-		}
-
-		return isEmptyContent
-	}
-
-	// Helper function to check if an error is a connection/network error
-	isConnectionError := func(err error) bool {
-		if err == nil {
-			return false
-		}
-		msg := err.Error()
-		isConnection := strings.Contains(msg, "EOF") ||
-			strings.Contains(msg, "connection refused") ||
-			strings.Contains(msg, "timeout") ||
-			strings.Contains(msg, "network") ||
-			strings.Contains(msg, "dial tcp") ||
-			strings.Contains(msg, "context deadline exceeded") ||
-			strings.Contains(msg, "connection reset") ||
-			strings.Contains(msg, "broken pipe") ||
-			strings.Contains(msg, "connection lost") ||
-			strings.Contains(msg, "connection closed") ||
-			strings.Contains(msg, "unexpected EOF")
-
-		// Enhanced debugging for connection error detection
-		if isConnection {
-			// Note: logger will be available in the main function scope
-			// This will be logged when the error is actually processed
-		}
-
-		return isConnection
-	}
-
-	// Helper function to check if an error is a stream-related error
-	isStreamError := func(err error) bool {
-		if err == nil {
-			return false
-		}
-		msg := err.Error()
-		isStream := strings.Contains(msg, "stream error") ||
-			strings.Contains(msg, "stream ID") ||
-			strings.Contains(msg, "streaming") ||
-			strings.Contains(msg, "stream closed") ||
-			strings.Contains(msg, "stream interrupted") ||
-			strings.Contains(msg, "stream timeout") ||
-			strings.Contains(msg, "streaming error")
-
-		// Enhanced debugging for stream error detection
-		if isStream {
-			// Note: logger will be available in the main function scope
-			// This will be logged when the error is actually processed
-		}
-
-		return isStream
-	}
-
-	// Helper function to check if an error is an internal server error
-	isInternalError := func(err error) bool {
-		if err == nil {
-			return false
-		}
-		msg := err.Error()
-		isInternal := strings.Contains(msg, "INTERNAL_ERROR") ||
-			strings.Contains(msg, "internal error") ||
-			strings.Contains(msg, "server error") ||
-			strings.Contains(msg, "unexpected error") ||
-			strings.Contains(msg, "received from peer") ||
-			strings.Contains(msg, "peer error") ||
-			strings.Contains(msg, "internal server error") ||
-			strings.Contains(msg, "service error")
-
-		// Enhanced debugging for internal error detection
-		if isInternal {
-			// Note: logger will be available in the main function scope
-			// This will be logged when the error is actually processed
-		}
-
-		return isInternal
-	}
-
 	// Get fallback models for the current provider
 	logger.Infof("Agent provider field: '%s'", a.provider)
 
@@ -208,10 +83,17 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 		logger.Infof("🔍 Using frontend cross-provider fallback - Provider: %s, Models: %v", crossProviderName, crossProviderFallbacks)
 	} else {
 		crossProviderFallbacks = llm.GetCrossProviderFallbackModels(provider)
-		crossProviderName = "openai" // Default fallback provider
+		crossProviderName = llm.GetCrossProviderFallbackProvider(provider)
 		logger.Infof("🔍 Using default cross-provider fallback - Provider: %s, Models: %v", crossProviderName, crossProviderFallbacks)
 	}
 
+	// Honor per-request exclusions before any fallback can be attempted
+	sameProviderFallbacks, crossProviderFallbacks = a.excludeFallbacks(provider, sameProviderFallbacks, crossProviderName, crossProviderFallbacks)
+
+	// Skip fallback models whose circuit breaker is open
+	sameProviderFallbacks = withoutOpenCircuits(sameProviderFallbacks)
+	crossProviderFallbacks = withoutOpenCircuits(crossProviderFallbacks)
+
 	logger.Infof("🔍 Fallback models loaded - same_provider: %v, cross_provider: %v", sameProviderFallbacks, crossProviderFallbacks)
 
 	// Create LLM generation with retry event (replaced span-based tracing)
@@ -231,6 +113,14 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 	}
 	a.EmitTypedEvent(ctx, llmGenerationStartEvent)
 
+	// Fail fast while the provider is marked degraded by outage detection
+	if degradedErr := providerOutages.check(string(a.provider)); degradedErr != nil {
+		sendMessage(fmt.Sprintf("\n🚨 %v", degradedErr))
+		return nil, degradedErr, usage
+	}
+
+	reauthenticated := false
+retryLoop:
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		select {
 		case <-ctx.Done():
@@ -249,6 +139,15 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 			logger.Infof("🔄 [DEBUG] GenerateContentWithRetry attempt %d - Context has no deadline", attempt+1)
 		}
 
+		// A model whose circuit breaker is open is not called; switch to the next fallback instead
+		if allowed, until := modelCircuits.allow(a.ModelID); !allowed {
+			if circuitErr := a.bypassOpenCircuit(ctx, turn, until, sameProviderFallbacks, crossProviderFallbacks); circuitErr != nil {
+				sendMessage(fmt.Sprintf("\n⚡ %v", circuitErr))
+				return nil, circuitErr, usage
+			}
+			sendMessage(fmt.Sprintf("\n⚡ Circuit breaker open for the previous model, continuing with %s", a.ModelID))
+		}
+
 		// Use non-streaming approach for all agents
 		llmCallStart := time.Now()
 		logger.Infof("🔄 [DEBUG] GenerateContentWithRetry attempt %d - Calling a.LLM.GenerateContent NOW - Time: %v", attempt+1, llmCallStart)
@@ -261,22 +160,8 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 		if err == nil {
 			logger.Infof("🔄 [DEBUG] GenerateContentWithRetry attempt %d - SUCCESS - Response: %v", attempt+1, resp != nil)
 			usage = extractUsageMetricsWithMessages(resp, messages)
-			// Emit LLM generation success event (replaced span-based tracing)
-			llmAttemptEndEvent := &events.LLMGenerationEndEvent{
-				BaseEventData: events.BaseEventData{
-					Timestamp: time.Now(),
-				},
-				Turn:      turn + 1,
-				Content:   resp.Choices[0].Content,
-				ToolCalls: len(resp.Choices[0].ToolCalls),
-				Duration:  time.Since(llmGenerationStartEvent.Timestamp),
-				UsageMetrics: events.UsageMetrics{
-					PromptTokens:     usage.InputTokens,
-					CompletionTokens: usage.OutputTokens,
-					TotalTokens:      usage.TotalTokens,
-				},
-			}
-			a.EmitTypedEvent(ctx, llmAttemptEndEvent)
+			providerOutages.recordSuccess(string(a.provider))
+			modelCircuits.recordSuccess(a.ModelID)
 			return resp, nil, usage
 		}
 
@@ -296,16 +181,27 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 		a.EmitTypedEvent(ctx, llmAttemptErrorEvent)
 
 		// Enhanced debugging: Show which error classification is being used
+		errorClass := a.classifyError(err)
+		if countsTowardCircuit(ctx, errorClass) && modelCircuits.recordFailure(a.ModelID) {
+			logger.Warnf("⚡ Circuit breaker opened for model %s after repeated %s errors", a.ModelID, errorClass)
+		}
 		logger.Infof("🔍 ERROR CLASSIFICATION DEBUG - Error: %s", err.Error())
-		logger.Infof("🔍 isMaxTokenError: %v", isMaxTokenError(err))
-		logger.Infof("🔍 isEmptyContentError: %v", isEmptyContentError(err))
-		logger.Infof("🔍 isThrottlingError: %v", isThrottlingError(err))
-		logger.Infof("🔍 isConnectionError: %v", isConnectionError(err))
-		logger.Infof("🔍 isStreamError: %v", isStreamError(err))
-		logger.Infof("🔍 isInternalError: %v", isInternalError(err))
-
-		// Handle max token errors with fallback models
-		if isMaxTokenError(err) {
+		logger.Infof("🔍 Error class: %s, credential expiry: %v", errorClass, isCredentialExpiryError(err))
+
+		// Expired credentials are refreshed and retried once, separately from throttling and fallbacks
+		if isCredentialExpiryError(err) {
+			if !reauthenticated && a.reauthenticate(ctx, turn, err) {
+				reauthenticated = true
+				sendMessage(fmt.Sprintf("\n🔑 Credentials expired (turn %d), re-authenticated and retrying...", turn))
+				continue
+			}
+			lastErr = fmt.Errorf("LLM credentials expired and re-authentication did not fix it: %w", err)
+			break
+		}
+
+		switch errorClass {
+		case ErrorClassMaxToken:
+			// Handle max token errors with fallback models
 			// 🔧 FIX: Reset reasoning tracker to prevent infinite final answer events
 			if a.AgentMode == ReActAgent && a.reasoningTracker != nil {
 				a.reasoningTracker.Reset()
@@ -669,11 +565,10 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 			}
 			a.EmitTypedEvent(ctx, maxTokenAllFailedEvent)
 			lastErr = fmt.Errorf("all fallback models failed for max_token error: %w", originalError)
-			break
-		}
+			break retryLoop
 
-		// Handle throttling errors with fallback models
-		if isThrottlingError(err) {
+		case ErrorClassThrottling:
+			// Handle throttling errors with fallback models
 			// 🔧 FIX: Reset reasoning tracker to prevent infinite final answer events
 			if a.AgentMode == ReActAgent && a.reasoningTracker != nil {
 				a.reasoningTracker.Reset()
@@ -682,8 +577,14 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 			// Track throttling start time
 			throttlingStartTime := time.Now()
 
+			// Choose the delay used if every fallback fails, so the event reports it
+			var delay time.Duration
+			if attempt < maxRetries-1 {
+				delay = a.retryDelay(retryConfig, attempt)
+			}
+
 			// Emit throttling detected event
-			throttlingEvent := events.NewThrottlingDetectedEvent(turn, a.ModelID, string(a.provider), attempt+1, maxRetries, time.Since(throttlingStartTime), "throttling", 0)
+			throttlingEvent := events.NewThrottlingDetectedEvent(turn, a.ModelID, string(a.provider), attempt+1, maxRetries, time.Since(throttlingStartTime), "throttling", delay)
 			a.EmitTypedEvent(ctx, throttlingEvent)
 
 			// Create throttling fallback event (replaced span-based tracing)
@@ -959,12 +860,14 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 				}
 			}
 
+			// Repeated exhaustion of every fallback may be a provider-wide outage: stop waiting and fail fast
+			if degradedErr := recordProviderOutage(a, ctx, "throttling_error", err); degradedErr != nil {
+				sendMessage(fmt.Sprintf("\n🚨 %v", degradedErr))
+				return nil, degradedErr, usage
+			}
+
 			// If all fallback models failed, try waiting and retrying with original model
 			if attempt < maxRetries-1 {
-				delay := time.Duration(float64(baseDelay) * (1.5 + float64(attempt)*0.5))
-				if delay > maxDelay {
-					delay = maxDelay
-				}
 
 				// Create retry delay event (replaced span-based tracing)
 				retryDelayEvent := &events.GenericEventData{
@@ -1000,8 +903,7 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 					}
 				}()
 
-				select {
-				case <-ctx.Done():
+				if err := a.waitRetryDelay(ctx, delay); err != nil {
 					// Emit retry delay cancellation event (replaced span-based tracing)
 					retryDelayCancelledEvent := &events.GenericEventData{
 						BaseEventData: events.BaseEventData{
@@ -1017,7 +919,6 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 					}
 					a.EmitTypedEvent(ctx, retryDelayCancelledEvent)
 					return nil, ctx.Err(), usage
-				case <-time.After(delay):
 				}
 
 				// Emit retry delay completion event (replaced span-based tracing)
@@ -1072,11 +973,10 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 			}
 			a.EmitTypedEvent(ctx, throttlingMaxRetriesEvent)
 			lastErr = fmt.Errorf("all models failed after %d attempts: %w", maxRetries, err)
-			break
-		}
+			break retryLoop
 
-		// Handle empty content errors with retry first (if retries available), then fallback models
-		if isEmptyContentError(err) {
+		case ErrorClassEmptyContent:
+			// Handle empty content errors with retry first (if retries available), then fallback models
 			logger.Infof("🔍 EMPTY CONTENT ERROR HANDLING STARTED")
 			logger.Infof("🔍 Error details: %s", err.Error())
 			logger.Infof("🔍 Available fallbacks - same_provider: %d, cross_provider: %d", len(sameProviderFallbacks), len(crossProviderFallbacks))
@@ -1123,11 +1023,7 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 				sendMessage(fmt.Sprintf("\n⚠️ Empty content error detected (turn %d, attempt %d/%d). Waiting %v before retrying with same model...", turn, attempt+1, maxRetries, emptyContentRetryDelay))
 
 				// Wait with context cancellation support
-				retryTimer := time.NewTimer(emptyContentRetryDelay)
-				defer retryTimer.Stop()
-
-				select {
-				case <-ctx.Done():
+				if err := a.waitRetryDelay(ctx, emptyContentRetryDelay); err != nil {
 					logger.Infof("❌ Empty content retry cancelled - context done: %v", ctx.Err())
 					// Emit retry delay cancellation event
 					emptyContentRetryDelayCancelledEvent := &events.GenericEventData{
@@ -1144,9 +1040,8 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 					}
 					a.EmitTypedEvent(ctx, emptyContentRetryDelayCancelledEvent)
 					return nil, ctx.Err(), usage
-				case <-retryTimer.C:
-					logger.Infof("✅ Empty content retry delay completed, retrying with same model %s", a.ModelID)
 				}
+				logger.Infof("✅ Empty content retry delay completed, retrying with same model %s", a.ModelID)
 
 				// Emit retry delay completion event
 				emptyContentRetryDelayCompletedEvent := &events.GenericEventData{
@@ -1472,11 +1367,10 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 			}
 			a.EmitTypedEvent(ctx, emptyContentAllFailedEvent)
 			lastErr = fmt.Errorf("all fallback models failed for empty content error: %w", err)
-			break
-		}
+			break retryLoop
 
-		// Handle connection/network errors with fallback models
-		if isConnectionError(err) {
+		case ErrorClassConnection:
+			// Handle connection/network errors with fallback models
 			// 🔧 FIX: Reset reasoning tracker to prevent infinite final answer events
 			if a.AgentMode == ReActAgent && a.reasoningTracker != nil {
 				a.reasoningTracker.Reset()
@@ -1719,32 +1613,31 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 			}
 			a.EmitTypedEvent(ctx, connectionErrorAllFailedEvent)
 			lastErr = fmt.Errorf("all fallback models failed for connection error: %w", err)
-			break
-		}
+			break retryLoop
 
-		// Handle stream errors with fallback models
-		if isStreamError(err) {
+		case ErrorClassStream:
+			// Handle stream errors with fallback models
 			resp, fallbackErr, fallbackUsage := handleErrorWithFallback(a, ctx, err, "stream_error", turn, attempt, maxRetries, sameProviderFallbacks, crossProviderFallbacks, sendMessage, messages, opts)
 			if fallbackErr == nil {
 				return resp, nil, fallbackUsage
 			}
 			lastErr = fallbackErr
-			break
-		}
+			break retryLoop
 
-		// Handle internal server errors with fallback models
-		if isInternalError(err) {
+		case ErrorClassInternal:
+			// Handle internal server errors with fallback models
 			resp, fallbackErr, fallbackUsage := handleErrorWithFallback(a, ctx, err, "internal_error", turn, attempt, maxRetries, sameProviderFallbacks, crossProviderFallbacks, sendMessage, messages, opts)
 			if fallbackErr == nil {
 				return resp, nil, fallbackUsage
 			}
 			lastErr = fallbackErr
-			break
-		}
+			break retryLoop
 
-		// For any other errors, just return the error
-		lastErr = err
-		break
+		default:
+			// For any other errors, just return the error
+			lastErr = err
+			break retryLoop
+		}
 	}
 
 	sendMessage(fmt.Sprintf("\n❌ LLM generation failed after %d attempts (turn %d): %v", maxRetries, turn, lastErr))
@@ -1947,11 +1840,22 @@ func handleErrorWithFallback(a *Agent, ctx context.Context, err error, errorType
 	}
 	a.EmitTypedEvent(ctx, errorAllFailedEvent)
 
+	// 5xx failures across every fallback count towards provider outage detection
+	if errorType == "internal_error" {
+		if degradedErr := recordProviderOutage(a, ctx, errorType, err); degradedErr != nil {
+			return nil, degradedErr, observability.UsageMetrics{}
+		}
+	}
+
 	return nil, fmt.Errorf("all fallback models failed for %s: %w", errorType, err), observability.UsageMetrics{}
 }
 
 // createFallbackLLM creates a fallback LLM instance for the given modelID
 func (a *Agent) createFallbackLLM(modelID string) (llmtypes.Model, error) {
+	if a.fallbackLLMFactory != nil {
+		return a.fallbackLLMFactory(modelID)
+	}
+
 	// ✅ FIXED: Detect provider from model ID instead of using agent's provider
 	provider := detectProviderFromModelID(modelID)
 
@@ -0,0 +1,164 @@
+package mcpagent
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/mcpclient"
+)
+
+// serverSelectionSourceMaxServers is the MCPServerSelection source used when the
+// per-request server cap trims the candidate list
+const serverSelectionSourceMaxServers = "max_servers"
+
+// WithMaxServers caps the number of MCP servers the agent connects to.
+// When more candidate servers are configured than the cap allows, only the
+// highest-priority servers for the selection query are connected. 0 disables the cap.
+func WithMaxServers(maxServers int) AgentOption {
+	return func(a *Agent) {
+		a.maxServers = maxServers
+	}
+}
+
+// WithServerSelectionQuery sets the query used to prioritize servers when the max servers cap applies
+func WithServerSelectionQuery(query string) AgentOption {
+	return func(a *Agent) {
+		a.serverSelectionQuery = query
+	}
+}
+
+// serverCandidate is a configured server scored against the selection query
+type serverCandidate struct {
+	name   string
+	pinned bool
+	score  int
+}
+
+// selectServersForCap returns the servers to connect to for the given server list
+// ("all", empty, or comma-separated names) and whether the cap trimmed the list.
+// Servers that own explicitly selected tools are always ranked first, then servers
+// are ordered by keyword overlap between the query and their name/description.
+func selectServersForCap(config *mcpclient.MCPConfig, serverName, query string, selectedTools []string, maxServers int) ([]string, int, bool) {
+	candidates := expandServerNames(config, serverName)
+
+	total := len(candidates)
+	if maxServers <= 0 || total <= maxServers {
+		return candidates, total, false
+	}
+
+	pinned := pinnedServers(selectedTools)
+
+	queryTerms := tokenizeForServerSelection(query)
+	scored := make([]serverCandidate, 0, total)
+	for _, name := range candidates {
+		text := name
+		if serverConfig, ok := config.MCPServers[name]; ok {
+			text += " " + serverConfig.Description
+		}
+		score := 0
+		for term := range tokenizeForServerSelection(text) {
+			if queryTerms[term] {
+				score++
+			}
+		}
+		scored = append(scored, serverCandidate{name: name, pinned: pinned[name], score: score})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].pinned != scored[j].pinned {
+			return scored[i].pinned
+		}
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].name < scored[j].name
+	})
+
+	selected := make([]string, 0, maxServers)
+	for _, candidate := range scored[:maxServers] {
+		selected = append(selected, candidate.name)
+	}
+	return selected, total, true
+}
+
+// expandServerNames returns the configured servers for a server list ("all", empty, or comma-separated names)
+func expandServerNames(config *mcpclient.MCPConfig, serverName string) []string {
+	var candidates []string
+	if serverName == "all" || serverName == "" {
+		for name := range config.MCPServers {
+			candidates = append(candidates, name)
+		}
+	} else {
+		for _, name := range strings.Split(serverName, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				candidates = append(candidates, name)
+			}
+		}
+	}
+	return candidates
+}
+
+// pinnedServers returns the servers that own explicitly selected "server:tool" tools
+func pinnedServers(selectedTools []string) map[string]bool {
+	pinned := make(map[string]bool)
+	for _, fullName := range selectedTools {
+		if parts := strings.SplitN(fullName, ":", 2); len(parts) == 2 {
+			pinned[parts[0]] = true
+		}
+	}
+	return pinned
+}
+
+// tokenizeForServerSelection splits text into a set of lowercase words longer than two characters
+func tokenizeForServerSelection(text string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) > 2 {
+			terms[word] = true
+		}
+	}
+	return terms
+}
+
+// applyMaxServersCap trims serverName to the top-priority servers when the cap is exceeded
+// and records an MCPServerSelection event describing the choice
+func (a *Agent) applyMaxServersCap(config *mcpclient.MCPConfig, serverName string) string {
+	if a.maxServers <= 0 {
+		return serverName
+	}
+
+	selected, total, capped := selectServersForCap(config, serverName, a.serverSelectionQuery, a.selectedTools, a.maxServers)
+	if !capped {
+		return serverName
+	}
+
+	a.Logger.Infof("🎯 Max servers cap (%d) applied - connecting to %v out of %d servers", a.maxServers, selected, total)
+	a.recordServerSelection(events.NewMCPServerSelectionEvent(0, selected, total, serverSelectionSourceMaxServers, a.serverSelectionQuery))
+
+	return strings.Join(selected, ",")
+}
+
+// recordServerSelection keeps a server selection event until the agent first runs.
+// Servers are chosen inside NewAgent, before callers can attach event listeners.
+func (a *Agent) recordServerSelection(event *events.MCPServerSelectionEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pendingServerSelections = append(a.pendingServerSelections, event)
+}
+
+// emitServerSelections emits the recorded server selection events once
+func (a *Agent) emitServerSelections(ctx context.Context) {
+	a.mu.Lock()
+	pending := a.pendingServerSelections
+	a.pendingServerSelections = nil
+	a.mu.Unlock()
+
+	for _, event := range pending {
+		a.EmitTypedEvent(ctx, event)
+	}
+}
@@ -0,0 +1,77 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/mcpclient"
+)
+
+func manyServersConfig() *mcpclient.MCPConfig {
+	config := &mcpclient.MCPConfig{MCPServers: map[string]mcpclient.MCPServerConfig{
+		"github":     {Description: "GitHub repositories, issues and pull requests"},
+		"slack":      {Description: "Send and read Slack messages"},
+		"postgres":   {Description: "Query a PostgreSQL database"},
+		"filesystem": {Description: "Read and write local files"},
+		"jira":       {Description: "Manage Jira issues and sprints"},
+	}}
+	for i := 0; i < 10; i++ {
+		config.MCPServers[fmt.Sprintf("misc-%02d", i)] = mcpclient.MCPServerConfig{Description: "Miscellaneous utilities"}
+	}
+	return config
+}
+
+func TestSelectServersForCapPrioritizesQuery(t *testing.T) {
+	selected, total, capped := selectServersForCap(manyServersConfig(), "all", "List open GitHub pull requests and Jira issues", nil, 2)
+	if !capped || total != 15 {
+		t.Fatalf("expected cap to apply to 15 servers, got capped=%v total=%d", capped, total)
+	}
+	if want := []string{"github", "jira"}; !reflect.DeepEqual(selected, want) {
+		t.Fatalf("expected %v, got %v", want, selected)
+	}
+}
+
+func TestSelectServersForCapPinsSelectedTools(t *testing.T) {
+	selected, _, _ := selectServersForCap(manyServersConfig(), "github,slack,postgres", "open pull requests", []string{"postgres:query"}, 2)
+	if want := []string{"postgres", "github"}; !reflect.DeepEqual(selected, want) {
+		t.Fatalf("expected %v, got %v", want, selected)
+	}
+}
+
+func TestSelectServersForCapUnderLimit(t *testing.T) {
+	selected, total, capped := selectServersForCap(manyServersConfig(), "github,slack", "anything", nil, 3)
+	if capped || total != 2 || len(selected) != 2 {
+		t.Fatalf("expected no cap, got capped=%v total=%d selected=%v", capped, total, selected)
+	}
+}
+
+func TestApplyMaxServersCapEmitsSelection(t *testing.T) {
+	a := newTestAgent(t, answeringLLM("done"))
+	a.maxServers = 1
+	a.serverSelectionQuery = "query the postgres database"
+
+	serverName := a.applyMaxServersCap(manyServersConfig(), "all")
+	if serverName != "postgres" {
+		t.Fatalf("expected only postgres to connect, got %q", serverName)
+	}
+
+	// Like the server, attach listeners only after the agent is built
+	selections := collectEvents[*events.MCPServerSelectionEvent](a)
+	for i := 0; i < 2; i++ {
+		if _, err := a.Ask(context.Background(), "list the tables"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	selected := selections.all()
+	if len(selected) != 1 {
+		t.Fatalf("expected one selection event across both runs, got %d", len(selected))
+	}
+	selection := selected[0]
+	if selection.Source != serverSelectionSourceMaxServers || selection.TotalServers != 15 || !reflect.DeepEqual(selection.SelectedServers, []string{"postgres"}) {
+		t.Fatalf("unexpected selection event: %+v", selection)
+	}
+}
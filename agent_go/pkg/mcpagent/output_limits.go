@@ -0,0 +1,33 @@
+package mcpagent
+
+import "mcp-agent/agent_go/internal/llmtypes"
+
+// WithMaxOutputTokens caps the output tokens of every LLM call of the agent (0 keeps the defaults)
+func WithMaxOutputTokens(maxTokens int) AgentOption {
+	return func(a *Agent) {
+		a.MaxOutputTokens = maxTokens
+	}
+}
+
+// WithStopSequences ends every LLM generation of the agent at the first of the sequences
+func WithStopSequences(sequences []string) AgentOption {
+	return func(a *Agent) {
+		a.StopSequences = sequences
+	}
+}
+
+// withOutputLimitOptions appends the configured output limits after opts, so they override the
+// per-call defaults
+func (a *Agent) withOutputLimitOptions(opts []llmtypes.CallOption) []llmtypes.CallOption {
+	if a.MaxOutputTokens <= 0 && len(a.StopSequences) == 0 {
+		return opts
+	}
+	limited := append([]llmtypes.CallOption{}, opts...)
+	if a.MaxOutputTokens > 0 {
+		limited = append(limited, llmtypes.WithMaxTokens(a.MaxOutputTokens))
+	}
+	if len(a.StopSequences) > 0 {
+		limited = append(limited, llmtypes.WithStopSequences(a.StopSequences))
+	}
+	return limited
+}
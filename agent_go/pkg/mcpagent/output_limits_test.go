@@ -0,0 +1,47 @@
+package mcpagent
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+func generateWithOutputLimits(t *testing.T, options ...AgentOption) llmtypes.CallOptions {
+	t.Helper()
+	fake := answeringLLM("done")
+	a := newTestAgent(t, fake, options...)
+
+	messages := []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "extract the invoice total")}
+	opts := []llmtypes.CallOption{llmtypes.WithTemperature(0.2), llmtypes.WithMaxTokens(40000)}
+	if _, err, _ := GenerateContentWithRetry(a, context.Background(), messages, opts, 0, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.calls != 1 {
+		t.Fatalf("expected one LLM call, got %d", fake.calls)
+	}
+	return fake.options[0]
+}
+
+func TestOutputLimitsPassedToModel(t *testing.T) {
+	got := generateWithOutputLimits(t, WithMaxOutputTokens(256), WithStopSequences([]string{"</invoice>", "\n\n"}))
+
+	if got.MaxTokens != 256 {
+		t.Fatalf("expected the configured max output tokens to override the default, got %d", got.MaxTokens)
+	}
+	if !reflect.DeepEqual(got.StopSequences, []string{"</invoice>", "\n\n"}) {
+		t.Fatalf("unexpected stop sequences %q", got.StopSequences)
+	}
+	if got.Temperature != 0.2 {
+		t.Fatalf("expected the other options to be kept, got temperature %v", got.Temperature)
+	}
+}
+
+func TestOutputLimitsUnsetKeepCallDefaults(t *testing.T) {
+	got := generateWithOutputLimits(t)
+
+	if got.MaxTokens != 40000 || got.StopSequences != nil {
+		t.Fatalf("expected the call defaults without output limits, got max tokens %d and stop sequences %q", got.MaxTokens, got.StopSequences)
+	}
+}
@@ -0,0 +1,254 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultProviderOutageWindow   = 5 * time.Minute
+	defaultProviderOutageCooldown = 2 * time.Minute
+	providerStatusCheckTimeout    = 5 * time.Second
+)
+
+// ProviderOutageConfig controls provider-wide outage detection. A provider whose fallbacks are all
+// exhausted by throttling/5xx errors Threshold times within Window is marked degraded for Cooldown,
+// and new LLM requests to it fail fast. Threshold 0 disables detection.
+type ProviderOutageConfig struct {
+	Threshold int
+	Window    time.Duration
+	Cooldown  time.Duration
+	// Optional status endpoint per provider (Statuspage /api/v2/status.json format). When the status
+	// page reports the provider operational, failures are not treated as an outage.
+	StatusURLs map[string]string
+}
+
+// ProviderOutageConfigFromEnv reads PROVIDER_OUTAGE_THRESHOLD, PROVIDER_OUTAGE_WINDOW_SECONDS,
+// PROVIDER_OUTAGE_COOLDOWN_SECONDS and PROVIDER_STATUS_URL_<PROVIDER> (e.g. PROVIDER_STATUS_URL_OPENAI)
+func ProviderOutageConfigFromEnv() ProviderOutageConfig {
+	config := ProviderOutageConfig{
+		Window:     defaultProviderOutageWindow,
+		Cooldown:   defaultProviderOutageCooldown,
+		StatusURLs: make(map[string]string),
+	}
+	if threshold, err := strconv.Atoi(os.Getenv("PROVIDER_OUTAGE_THRESHOLD")); err == nil && threshold > 0 {
+		config.Threshold = threshold
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("PROVIDER_OUTAGE_WINDOW_SECONDS")); err == nil && seconds > 0 {
+		config.Window = time.Duration(seconds) * time.Second
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("PROVIDER_OUTAGE_COOLDOWN_SECONDS")); err == nil && seconds > 0 {
+		config.Cooldown = time.Duration(seconds) * time.Second
+	}
+	for _, env := range os.Environ() {
+		key, value, found := strings.Cut(env, "=")
+		if found && value != "" && strings.HasPrefix(key, "PROVIDER_STATUS_URL_") {
+			config.StatusURLs[strings.ToLower(strings.TrimPrefix(key, "PROVIDER_STATUS_URL_"))] = value
+		}
+	}
+	return config
+}
+
+// ProviderDegradedError is returned for LLM requests to a provider marked degraded
+type ProviderDegradedError struct {
+	Provider string
+	Reason   string
+	Until    time.Time
+}
+
+func (e *ProviderDegradedError) Error() string {
+	return fmt.Sprintf("provider %s is degraded (%s); requests to it are paused until %s",
+		e.Provider, e.Reason, e.Until.Format(time.RFC3339))
+}
+
+// ProviderHealthStatus is the outage state of one provider, for health reporting
+type ProviderHealthStatus struct {
+	Provider       string    `json:"provider"`
+	Degraded       bool      `json:"degraded"`
+	Reason         string    `json:"reason,omitempty"`
+	DegradedUntil  time.Time `json:"degraded_until,omitempty"`
+	RecentFailures int       `json:"recent_failures"`
+}
+
+type providerOutageState struct {
+	failures      []time.Time // All-fallback failures within the window
+	reason        string
+	degradedUntil time.Time
+}
+
+// providerOutageTracker is shared by all agents in the process since outages are provider-wide
+type providerOutageTracker struct {
+	mu        sync.Mutex
+	config    ProviderOutageConfig
+	providers map[string]*providerOutageState
+	client    *http.Client
+	now       func() time.Time // nil uses time.Now
+}
+
+var providerOutages = newProviderOutageTracker(ProviderOutageConfigFromEnv())
+
+func newProviderOutageTracker(config ProviderOutageConfig) *providerOutageTracker {
+	return &providerOutageTracker{
+		config:    config,
+		providers: make(map[string]*providerOutageState),
+		client:    &http.Client{Timeout: providerStatusCheckTimeout},
+	}
+}
+
+// ConfigureProviderOutageDetection replaces the outage detection settings and clears provider state
+func ConfigureProviderOutageDetection(config ProviderOutageConfig) {
+	providerOutages.mu.Lock()
+	defer providerOutages.mu.Unlock()
+	if config.Window <= 0 {
+		config.Window = defaultProviderOutageWindow
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaultProviderOutageCooldown
+	}
+	providerOutages.config = config
+	providerOutages.providers = make(map[string]*providerOutageState)
+}
+
+// ProviderHealth returns the outage state of every provider that has failed recently
+func ProviderHealth() []ProviderHealthStatus {
+	return providerOutages.health()
+}
+
+func (t *providerOutageTracker) currentTime() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+// check returns an error if the provider is currently degraded
+func (t *providerOutageTracker) check(provider string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, exists := t.providers[provider]
+	if !exists || !t.currentTime().Before(state.degradedUntil) {
+		return nil
+	}
+	return &ProviderDegradedError{Provider: provider, Reason: state.reason, Until: state.degradedUntil}
+}
+
+// recordFailure notes that every fallback failed with throttling/5xx errors and marks the provider
+// degraded once the threshold is reached within the window. Returns the degraded error when it is.
+func (t *providerOutageTracker) recordFailure(ctx context.Context, provider string) error {
+	t.mu.Lock()
+	if t.config.Threshold <= 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	now := t.currentTime()
+	state, exists := t.providers[provider]
+	if !exists {
+		state = &providerOutageState{}
+		t.providers[provider] = state
+	}
+	recent := state.failures[:0]
+	for _, failedAt := range state.failures {
+		if now.Sub(failedAt) < t.config.Window {
+			recent = append(recent, failedAt)
+		}
+	}
+	state.failures = append(recent, now)
+	count := len(state.failures)
+	threshold, window, cooldown := t.config.Threshold, t.config.Window, t.config.Cooldown
+	statusURL := t.config.StatusURLs[provider]
+	t.mu.Unlock()
+
+	if count < threshold {
+		return nil
+	}
+
+	reason := fmt.Sprintf("all fallback models failed %d times within %s", count, window)
+	if statusURL != "" {
+		operational, description, err := t.fetchProviderStatus(ctx, statusURL)
+		if err == nil && operational {
+			// The provider reports no incident; the failures are likely specific to this deployment
+			return nil
+		}
+		if err == nil && description != "" {
+			reason += "; status page: " + description
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state.reason = reason
+	state.degradedUntil = t.currentTime().Add(cooldown)
+	return &ProviderDegradedError{Provider: provider, Reason: reason, Until: state.degradedUntil}
+}
+
+// recordSuccess clears the failure history after the provider answers again
+func (t *providerOutageTracker) recordSuccess(provider string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.providers, provider)
+}
+
+func (t *providerOutageTracker) health() []ProviderHealthStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.currentTime()
+	statuses := make([]ProviderHealthStatus, 0, len(t.providers))
+	for provider, state := range t.providers {
+		status := ProviderHealthStatus{Provider: provider, RecentFailures: len(state.failures)}
+		if now.Before(state.degradedUntil) {
+			status.Degraded = true
+			status.Reason = state.reason
+			status.DegradedUntil = state.degradedUntil
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Provider < statuses[j].Provider })
+	return statuses
+}
+
+// fetchProviderStatus reads a Statuspage status endpoint and reports whether the provider is operational
+func (t *providerOutageTracker) fetchProviderStatus(ctx context.Context, url string) (bool, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, providerStatusCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, "", err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return false, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, "", fmt.Errorf("status endpoint returned %d", resp.StatusCode)
+	}
+
+	var page struct {
+		Status struct {
+			Indicator   string `json:"indicator"`
+			Description string `json:"description"`
+		} `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return false, "", err
+	}
+	return page.Status.Indicator == "none", page.Status.Description, nil
+}
+
+// recordProviderOutage records an all-fallback failure for the agent's provider and returns the
+// degraded error when this failure marks the provider degraded
+func recordProviderOutage(a *Agent, ctx context.Context, errorType string, err error) error {
+	degradedErr := providerOutages.recordFailure(ctx, string(a.provider))
+	if degradedErr != nil {
+		getLogger(a).Warnf("🚨 Provider %s marked degraded after %s: %v", a.provider, errorType, err)
+	}
+	return degradedErr
+}
@@ -0,0 +1,94 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+func configureOutageDetectionForTest(t *testing.T, config ProviderOutageConfig) {
+	t.Helper()
+	ConfigureProviderOutageDetection(config)
+	t.Cleanup(func() {
+		ConfigureProviderOutageDetection(ProviderOutageConfig{})
+		providerOutages.now = nil
+	})
+}
+
+// failAllFallbacks simulates a 5xx error after which every fallback model also failed
+func failAllFallbacks(a *Agent) error {
+	messages := []llmtypes.MessageContent{{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "hello"}}}}
+	_, err, _ := handleErrorWithFallback(a, context.Background(), errors.New("status code 503: service unavailable"), "internal_error", 0, 0, 1, nil, nil, func(string) {}, messages, nil)
+	return err
+}
+
+func TestProviderMarkedDegradedAfterRepeatedAllFallbackFailures(t *testing.T) {
+	configureOutageDetectionForTest(t, ProviderOutageConfig{Threshold: 2, Window: time.Minute, Cooldown: time.Minute})
+	now := time.Now()
+	providerOutages.now = func() time.Time { return now }
+
+	llm := answeringLLM("ok")
+	a := newTestAgent(t, llm, onOpenAI)
+
+	var degradedErr *ProviderDegradedError
+	if err := failAllFallbacks(a); err == nil || errors.As(err, &degradedErr) {
+		t.Fatalf("expected a plain fallback failure below the threshold, got %v", err)
+	}
+	if err := failAllFallbacks(a); !errors.As(err, &degradedErr) || degradedErr.Provider != "openai" {
+		t.Fatalf("expected provider degraded error once the threshold is reached, got %v", err)
+	}
+
+	health := ProviderHealth()
+	if len(health) != 1 || !health[0].Degraded || health[0].Provider != "openai" || health[0].RecentFailures != 2 {
+		t.Fatalf("expected openai reported degraded, got %+v", health)
+	}
+
+	// New requests are short-circuited without reaching the provider
+	messages := []llmtypes.MessageContent{{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "hello"}}}}
+	if _, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {}); !errors.As(err, &degradedErr) {
+		t.Fatalf("expected degraded error for a new request, got %v", err)
+	}
+	if llm.calls != 0 {
+		t.Fatalf("expected no LLM calls while degraded, got %d", llm.calls)
+	}
+
+	// After the cooldown requests go through again and a success clears the state
+	now = now.Add(2 * time.Minute)
+	if _, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {}); err != nil {
+		t.Fatalf("expected request to succeed after cooldown, got %v", err)
+	}
+	if llm.calls != 1 || len(ProviderHealth()) != 0 {
+		t.Fatalf("expected provider recovered, calls=%d health=%+v", llm.calls, ProviderHealth())
+	}
+}
+
+func TestProviderStatusPageGatesDegradation(t *testing.T) {
+	indicator := "none"
+	statusPage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":{"indicator":"` + indicator + `","description":"Partial API outage"}}`))
+	}))
+	defer statusPage.Close()
+
+	configureOutageDetectionForTest(t, ProviderOutageConfig{Threshold: 1, StatusURLs: map[string]string{"openai": statusPage.URL}})
+	a := newTestAgent(t, answeringLLM("ok"), onOpenAI)
+
+	// The status page reports the provider operational, so failures are not treated as an outage
+	var degradedErr *ProviderDegradedError
+	if err := failAllFallbacks(a); errors.As(err, &degradedErr) {
+		t.Fatalf("expected no degradation while the status page is operational, got %v", err)
+	}
+
+	indicator = "major"
+	if err := failAllFallbacks(a); !errors.As(err, &degradedErr) {
+		t.Fatalf("expected provider degraded during a reported incident, got %v", err)
+	}
+	if !strings.Contains(degradedErr.Reason, "Partial API outage") {
+		t.Fatalf("expected status page description in the reason, got %q", degradedErr.Reason)
+	}
+}
@@ -0,0 +1,98 @@
+package mcpagent
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Default retry settings of GenerateContentWithRetry
+const (
+	DefaultRetryMaxRetries = 5
+	DefaultRetryBaseDelay  = 30 * time.Second
+	DefaultRetryMaxDelay   = 5 * time.Minute
+	DefaultRetryMultiplier = 0.5
+)
+
+// RetryConfig bounds how GenerateContentWithRetry retries throttled LLM calls. After every
+// fallback failed, attempt n (0-based) waits BaseDelay * (1 + Multiplier*(n+1)), capped at MaxDelay.
+// With Jitter the wait is instead drawn uniformly between BaseDelay and that delay, so agents
+// throttled together do not retry in lockstep. Zero fields use the defaults.
+type RetryConfig struct {
+	MaxRetries int           // Attempts with the original model (default 5)
+	BaseDelay  time.Duration // Delay unit between attempts (default 30s)
+	MaxDelay   time.Duration // Upper bound of any single delay (default 5m)
+	Multiplier float64       // Delay growth per attempt, as a fraction of BaseDelay (default 0.5)
+	Jitter     bool          // Randomize each delay between BaseDelay and the computed delay
+}
+
+// WithRetryConfig sets the retry budget and delays of LLM calls; zero fields keep the defaults
+func WithRetryConfig(config RetryConfig) AgentOption {
+	return func(a *Agent) {
+		a.RetryConfig = config
+	}
+}
+
+// withDefaults fills unset fields with the default retry settings
+func (c RetryConfig) withDefaults() RetryConfig {
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = DefaultRetryMaxRetries
+	}
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = DefaultRetryBaseDelay
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = DefaultRetryMaxDelay
+	}
+	if c.Multiplier <= 0 {
+		c.Multiplier = DefaultRetryMultiplier
+	}
+	return c
+}
+
+// Delay returns the wait before retrying after the given 0-based attempt
+func (c RetryConfig) Delay(attempt int) time.Duration {
+	delay := time.Duration(float64(c.BaseDelay) * (1 + c.Multiplier*float64(attempt+1)))
+	if delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	return delay
+}
+
+// jitteredDelay returns a delay drawn between BaseDelay and Delay(attempt), using random for a
+// value in [0, 1)
+func (c RetryConfig) jitteredDelay(attempt int, random func() float64) time.Duration {
+	upper := c.Delay(attempt)
+	if upper <= c.BaseDelay {
+		return upper
+	}
+	return c.BaseDelay + time.Duration(random()*float64(upper-c.BaseDelay))
+}
+
+// retryDelay returns the wait before retrying after the given 0-based attempt, jittered when
+// the config enables it
+func (a *Agent) retryDelay(config RetryConfig, attempt int) time.Duration {
+	if !config.Jitter {
+		return config.Delay(attempt)
+	}
+	random := rand.Float64
+	if a.retryRand != nil {
+		random = a.retryRand.Float64
+	}
+	return config.jitteredDelay(attempt, random)
+}
+
+// waitRetryDelay blocks for delay or until ctx is done
+func (a *Agent) waitRetryDelay(ctx context.Context, delay time.Duration) error {
+	if a.retrySleep != nil {
+		return a.retrySleep(ctx, delay)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,126 @@
+package mcpagent
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// newRetryTestAgent returns an agent without fallback models that records its retry delays
+func newRetryTestAgent(t *testing.T, llm llmtypes.Model, config RetryConfig) (*Agent, *[]time.Duration) {
+	t.Helper()
+	t.Setenv("OPENAI_FALLBACK_MODELS", "")
+	slept := &[]time.Duration{}
+	a := newTestAgent(t, llm, onOpenAI, WithRetryConfig(config))
+	a.retrySleep = func(ctx context.Context, delay time.Duration) error {
+		*slept = append(*slept, delay)
+		return nil
+	}
+	return a, slept
+}
+
+func TestRetryConfigBoundsAttemptsAndDelays(t *testing.T) {
+	llm := throttledLLM(2)
+	a, slept := newRetryTestAgent(t, llm, RetryConfig{MaxRetries: 4, BaseDelay: time.Second, MaxDelay: 2500 * time.Millisecond, Multiplier: 1})
+
+	messages := []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}
+	if _, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {}); err != nil {
+		t.Fatalf("expected success after two throttled attempts, got %v", err)
+	}
+	if llm.calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", llm.calls)
+	}
+	if want := []time.Duration{2 * time.Second, 2500 * time.Millisecond}; !reflect.DeepEqual(*slept, want) {
+		t.Fatalf("expected delays %v (second capped at MaxDelay), got %v", want, *slept)
+	}
+}
+
+func TestRetryConfigStopsAtMaxRetries(t *testing.T) {
+	llm := throttledLLM(100)
+	a, slept := newRetryTestAgent(t, llm, RetryConfig{MaxRetries: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second})
+
+	messages := []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}
+	if _, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {}); err == nil {
+		t.Fatal("expected failure once the retry budget is exhausted")
+	}
+	if llm.calls != 3 {
+		t.Fatalf("expected exactly MaxRetries (3) attempts, got %d", llm.calls)
+	}
+	var total time.Duration
+	for _, delay := range *slept {
+		total += delay
+	}
+	// Default multiplier 0.5: 15ms + 20ms, no wait after the last attempt
+	if len(*slept) != 2 || total != 35*time.Millisecond {
+		t.Fatalf("expected two delays totalling 35ms, got %v", *slept)
+	}
+}
+
+func TestRetryConfigDefaultsMatchPreviousSchedule(t *testing.T) {
+	config := RetryConfig{}.withDefaults()
+	if config.MaxRetries != 5 {
+		t.Fatalf("expected 5 retries by default, got %d", config.MaxRetries)
+	}
+	want := []time.Duration{45 * time.Second, 60 * time.Second, 75 * time.Second, 90 * time.Second}
+	for attempt, delay := range want {
+		if got := config.Delay(attempt); got != delay {
+			t.Fatalf("attempt %d: expected %v, got %v", attempt, delay, got)
+		}
+	}
+	if got := config.Delay(20); got != 5*time.Minute {
+		t.Fatalf("expected delays capped at 5m, got %v", got)
+	}
+}
+
+func TestRetryConfigJitterSpreadsDelays(t *testing.T) {
+	llm := throttledLLM(4)
+	config := RetryConfig{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 3 * time.Second, Multiplier: 1, Jitter: true}
+	a, slept := newRetryTestAgent(t, llm, config)
+	a.retryRand = rand.New(rand.NewSource(42))
+	throttling := collectEvents[*events.ThrottlingDetectedEvent](a)
+
+	messages := []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, "hi")}
+	if _, err, _ := generateContentWithRetry(a, context.Background(), messages, nil, 0, func(string) {}); err != nil {
+		t.Fatalf("expected success after four throttled attempts, got %v", err)
+	}
+	if len(*slept) != 4 {
+		t.Fatalf("expected 4 delays, got %v", *slept)
+	}
+
+	distinct := map[time.Duration]bool{}
+	for attempt, delay := range *slept {
+		if upper := config.Delay(attempt); delay < config.BaseDelay || delay > upper {
+			t.Fatalf("attempt %d: delay %v outside [%v, %v]", attempt, delay, config.BaseDelay, upper)
+		}
+		distinct[delay] = true
+	}
+	if len(distinct) < 2 {
+		t.Fatalf("expected jittered delays to vary across attempts, got %v", *slept)
+	}
+
+	// The throttling events report the delay that was then waited
+	throttled := throttling.all()
+	if len(throttled) != 4 {
+		t.Fatalf("expected 4 throttling events, got %d", len(throttled))
+	}
+	for i, event := range throttled {
+		if event.RetryDelay != (*slept)[i].String() {
+			t.Fatalf("event %d reports retry delay %q, waited %v", i, event.RetryDelay, (*slept)[i])
+		}
+	}
+}
+
+func TestRetryConfigJitterDisabledIsDeterministic(t *testing.T) {
+	config := RetryConfig{BaseDelay: time.Second, MaxDelay: time.Minute, Multiplier: 1}
+	a := &Agent{retryRand: rand.New(rand.NewSource(1))}
+	for attempt := 0; attempt < 3; attempt++ {
+		if got, want := a.retryDelay(config, attempt), config.Delay(attempt); got != want {
+			t.Fatalf("attempt %d: expected the fixed delay %v without jitter, got %v", attempt, want, got)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package mcpagent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// DefaultRoutingCacheMinConfidence is the routing confidence below which decisions are not reused
+const DefaultRoutingCacheMinConfidence = 0.7
+
+// DefaultRoutingCacheMaxEntries bounds the routing cache; the least recently used decisions are evicted first
+const DefaultRoutingCacheMaxEntries = 500
+
+// RoutingDecision is a smart routing server selection that can be reused for similar queries
+type RoutingDecision struct {
+	Servers    []string  `json:"servers"`
+	Reasoning  string    `json:"reasoning,omitempty"`
+	Confidence float64   `json:"confidence"` // 0.0-1.0, reported by the routing LLM
+	CreatedAt  time.Time `json:"created_at"`
+	// Servers whose tools the decision was made against, so a tool set change can invalidate it
+	ToolServers []string  `json:"tool_servers,omitempty"`
+	LastUsed    time.Time `json:"last_used,omitempty"`
+}
+
+// RoutingCache stores smart routing decisions keyed by the normalized conversation and the
+// signature of the available tool set, so similar queries against the same tools skip the
+// routing LLM call. Decisions expire after the TTL, low-confidence decisions are never reused and
+// the least recently used decisions are evicted beyond the maximum size. A cache is safe for concurrent use and meant to be shared by agents (see WithRoutingCache).
+type RoutingCache struct {
+	mu            sync.Mutex
+	entries       map[string]RoutingDecision
+	ttl           time.Duration
+	minConfidence float64
+	maxEntries    int
+	path          string // JSON file the decisions persist to; "" keeps them in memory
+}
+
+// NewRoutingCache creates a routing cache. ttl <= 0 keeps decisions until they are invalidated and
+// minConfidence <= 0 uses DefaultRoutingCacheMinConfidence. With a path, decisions are loaded from
+// and saved to that JSON file so they survive restarts.
+func NewRoutingCache(ttl time.Duration, minConfidence float64, path string) (*RoutingCache, error) {
+	if minConfidence <= 0 {
+		minConfidence = DefaultRoutingCacheMinConfidence
+	}
+	cache := &RoutingCache{entries: make(map[string]RoutingDecision), ttl: ttl, minConfidence: minConfidence, maxEntries: DefaultRoutingCacheMaxEntries, path: path}
+	if path == "" {
+		return cache, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing cache %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse routing cache %s: %w", path, err)
+	}
+	cache.evict()
+	return cache, nil
+}
+
+// SetMaxEntries bounds the number of cached decisions, evicting the least recently used ones.
+// maxEntries <= 0 removes the bound.
+func (c *RoutingCache) SetMaxEntries(maxEntries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxEntries = maxEntries
+	if c.evict() {
+		c.save()
+	}
+}
+
+// WithRoutingCache reuses smart routing decisions from cache for similar queries
+func WithRoutingCache(cache *RoutingCache) AgentOption {
+	return func(a *Agent) {
+		a.routingCache = cache
+	}
+}
+
+// Lookup returns the decision for key if it is fresh and confident enough; stale or
+// low-confidence decisions are invalidated
+func (c *RoutingCache) Lookup(key string) (RoutingDecision, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	decision, exists := c.entries[key]
+	if !exists {
+		return RoutingDecision{}, false
+	}
+	if (c.ttl > 0 && time.Since(decision.CreatedAt) > c.ttl) || decision.Confidence < c.minConfidence {
+		delete(c.entries, key)
+		c.save()
+		return RoutingDecision{}, false
+	}
+	decision.LastUsed = time.Now()
+	c.entries[key] = decision
+	return decision, true
+}
+
+// Store caches decision under key. Decisions below the minimum confidence are not stored, and
+// replace (invalidate) a previous decision for the same key.
+func (c *RoutingCache) Store(key string, decision RoutingDecision) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if decision.Confidence < c.minConfidence {
+		if _, exists := c.entries[key]; exists {
+			delete(c.entries, key)
+			c.save()
+		}
+		return
+	}
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = time.Now()
+	}
+	decision.LastUsed = time.Now()
+	c.entries[key] = decision
+	c.evict()
+	c.save()
+}
+
+// Invalidate removes the decision for key
+func (c *RoutingCache) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	c.save()
+}
+
+// InvalidateServer removes the decisions made against a tool set that included server, and
+// returns how many were removed. Call it when the server's tools change.
+func (c *RoutingCache) InvalidateServer(server string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for key, decision := range c.entries {
+		for _, toolServer := range decision.ToolServers {
+			if toolServer == server {
+				delete(c.entries, key)
+				removed++
+				break
+			}
+		}
+	}
+	if removed > 0 {
+		c.save()
+	}
+	return removed
+}
+
+// Clear removes all cached decisions
+func (c *RoutingCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]RoutingDecision)
+	c.save()
+}
+
+// Len returns the number of cached decisions
+func (c *RoutingCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// evict drops the least recently used decisions beyond maxEntries and reports whether any were dropped
+func (c *RoutingCache) evict() bool {
+	if c.maxEntries <= 0 || len(c.entries) <= c.maxEntries {
+		return false
+	}
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return routingDecisionLastUsed(c.entries[keys[i]]).Before(routingDecisionLastUsed(c.entries[keys[j]]))
+	})
+	for _, key := range keys[:len(keys)-c.maxEntries] {
+		delete(c.entries, key)
+	}
+	return true
+}
+
+// routingDecisionLastUsed falls back to the creation time for decisions persisted before LastUsed existed
+func routingDecisionLastUsed(decision RoutingDecision) time.Time {
+	if decision.LastUsed.IsZero() {
+		return decision.CreatedAt
+	}
+	return decision.LastUsed
+}
+
+// save writes the decisions to the cache file; failures only cost future cache hits
+func (c *RoutingCache) save() {
+	if c.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return
+	}
+	if dir := filepath.Dir(c.path); dir != "." {
+		_ = os.MkdirAll(dir, 0755)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err == nil {
+		_ = os.Rename(tmp, c.path)
+	}
+}
+
+// routingCacheKey identifies a routing decision by the normalized conversation context and the
+// signature of the tool set (tool names and their servers) it was made against
+func routingCacheKey(conversationContext string, tools []llmtypes.Tool, toolToServer map[string]string) string {
+	signature := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		if tool.Function == nil {
+			continue
+		}
+		signature = append(signature, toolToServer[tool.Function.Name]+"/"+tool.Function.Name)
+	}
+	sort.Strings(signature)
+
+	hash := sha256.New()
+	hash.Write([]byte(normalizeRoutingQuery(conversationContext)))
+	hash.Write([]byte{0})
+	hash.Write([]byte(strings.Join(signature, ",")))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// routingToolServers returns the sorted, distinct servers providing tools
+func routingToolServers(tools []llmtypes.Tool, toolToServer map[string]string) []string {
+	seen := make(map[string]bool)
+	servers := make([]string, 0)
+	for _, tool := range tools {
+		if tool.Function == nil {
+			continue
+		}
+		if server := toolToServer[tool.Function.Name]; server != "" && !seen[server] {
+			seen[server] = true
+			servers = append(servers, server)
+		}
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// normalizeRoutingQuery lower-cases the query and reduces punctuation and whitespace to single
+// spaces, so queries differing only in case or formatting share a routing decision
+func normalizeRoutingQuery(query string) string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// parseRoutingConfidence reads the confidence from the routing LLM's JSON response; responses
+// without one (including the text fallback) count as zero confidence and are not cached
+func parseRoutingConfidence(llmResponse string) float64 {
+	var response struct {
+		Confidence float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(llmResponse), &response); err != nil {
+		return 0
+	}
+	return response.Confidence
+}
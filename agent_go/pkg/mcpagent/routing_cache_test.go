@@ -0,0 +1,173 @@
+package mcpagent
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+func routingTestTool(name string) llmtypes.Tool {
+	return llmtypes.Tool{Type: "function", Function: &llmtypes.FunctionDefinition{Name: name}}
+}
+
+// newRoutingTestAgent returns an agent with aws and github tools whose routing LLM selects aws
+func newRoutingTestAgent(t *testing.T, cache *RoutingCache, response string) (*Agent, *fakeLLM) {
+	t.Helper()
+	llm := answeringLLM(response)
+	a := newTestAgent(t, llm, WithRoutingCache(cache))
+	a.Tools = []llmtypes.Tool{routingTestTool("list_buckets"), routingTestTool("list_repos")}
+	a.toolToServer = map[string]string{
+		"list_buckets": "aws",
+		"list_repos":   "github",
+	}
+	return a, llm
+}
+
+const confidentAWSRouting = `{"relevant_servers": ["aws"], "reasoning": "bucket question", "confidence": 0.9}`
+
+func TestRoutingCacheReusesDecisionForSimilarQuery(t *testing.T) {
+	cache, err := NewRoutingCache(time.Hour, 0, "")
+	if err != nil {
+		t.Fatalf("NewRoutingCache: %v", err)
+	}
+	a, llm := newRoutingTestAgent(t, cache, confidentAWSRouting)
+	routeStarts := collectEvents[*events.SmartRoutingStartEvent](a)
+	routeEnds := collectEvents[*events.SmartRoutingEndEvent](a)
+
+	first, err := a.filterToolsByRelevance(context.Background(), "User: List my S3 buckets.\n")
+	if err != nil {
+		t.Fatalf("first routing failed: %v", err)
+	}
+	// Same query with different case, punctuation and spacing
+	second, err := a.filterToolsByRelevance(context.Background(), "User:  list my s3 buckets\n")
+	if err != nil {
+		t.Fatalf("second routing failed: %v", err)
+	}
+
+	if llm.calls != 1 {
+		t.Fatalf("expected the similar query to reuse the cached decision, got %d routing LLM calls", llm.calls)
+	}
+	if len(first) != 1 || len(second) != 1 || second[0].Function.Name != "list_buckets" {
+		t.Fatalf("expected both queries routed to the aws tools, got %d and %d tools", len(first), len(second))
+	}
+
+	ends := routeEnds.all()
+	if len(ends) != 2 {
+		t.Fatalf("expected a routing end event per query, got %d", len(ends))
+	}
+	if ends[0].CacheHit || !ends[1].CacheHit {
+		t.Errorf("expected only the second decision marked cached, got %v and %v", ends[0].CacheHit, ends[1].CacheHit)
+	}
+	if starts := routeStarts.all(); len(starts) != 2 || starts[0].CacheHit || !starts[1].CacheHit {
+		t.Errorf("expected only the second start event marked as a cache hit, got %+v", starts)
+	}
+	if ends[1].Confidence != 0.9 || ends[1].SelectedServers != "aws" {
+		t.Errorf("unexpected cached routing event: %+v", ends[1])
+	}
+}
+
+func TestRoutingCacheMissesOnDifferentQueryOrToolSet(t *testing.T) {
+	cache, _ := NewRoutingCache(time.Hour, 0, "")
+	a, llm := newRoutingTestAgent(t, cache, confidentAWSRouting)
+
+	a.filterToolsByRelevance(context.Background(), "User: list my buckets\n")
+	a.filterToolsByRelevance(context.Background(), "User: list my repositories\n")
+	if llm.calls != 2 {
+		t.Fatalf("expected a different query to be routed again, got %d calls", llm.calls)
+	}
+
+	// A new tool changes the tool-set signature
+	a.Tools = append(a.Tools, routingTestTool("list_issues"))
+	a.toolToServer["list_issues"] = "github"
+	a.filterToolsByRelevance(context.Background(), "User: list my buckets\n")
+	if llm.calls != 3 {
+		t.Fatalf("expected a changed tool set to be routed again, got %d calls", llm.calls)
+	}
+}
+
+func TestRoutingCacheSkipsLowConfidenceAndExpiredDecisions(t *testing.T) {
+	cache, _ := NewRoutingCache(time.Hour, 0.8, "")
+	a, llm := newRoutingTestAgent(t, cache, `{"relevant_servers": ["aws"], "reasoning": "unsure", "confidence": 0.5}`)
+
+	a.filterToolsByRelevance(context.Background(), "User: list my buckets\n")
+	a.filterToolsByRelevance(context.Background(), "User: list my buckets\n")
+	if llm.calls != 2 || cache.Len() != 0 {
+		t.Fatalf("expected a low-confidence decision not to be reused, got %d calls and %d cached", llm.calls, cache.Len())
+	}
+
+	// A decision that was confident when cached is invalidated once it expires
+	cache.Store("key", RoutingDecision{Servers: []string{"aws"}, Confidence: 0.9, CreatedAt: time.Now().Add(-2 * time.Hour)})
+	if _, ok := cache.Lookup("key"); ok || cache.Len() != 0 {
+		t.Error("expected an expired decision to be invalidated")
+	}
+}
+
+func TestRoutingCachePersistsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routing", "cache.json")
+	cache, err := NewRoutingCache(time.Hour, 0, path)
+	if err != nil {
+		t.Fatalf("NewRoutingCache: %v", err)
+	}
+	cache.Store("key", RoutingDecision{Servers: []string{"aws"}, Reasoning: "bucket question", Confidence: 0.9})
+
+	reloaded, err := NewRoutingCache(time.Hour, 0, path)
+	if err != nil {
+		t.Fatalf("reloading the cache: %v", err)
+	}
+	decision, ok := reloaded.Lookup("key")
+	if !ok || len(decision.Servers) != 1 || decision.Servers[0] != "aws" || decision.Reasoning != "bucket question" {
+		t.Fatalf("expected the decision to survive a reload, got %+v (found %v)", decision, ok)
+	}
+}
+
+func TestRoutingCacheInvalidatesOnServerToolChange(t *testing.T) {
+	cache, _ := NewRoutingCache(time.Hour, 0, "")
+	a, llm := newRoutingTestAgent(t, cache, confidentAWSRouting)
+
+	a.filterToolsByRelevance(context.Background(), "User: list my buckets\n")
+	if removed := cache.InvalidateServer("slack"); removed != 0 {
+		t.Fatalf("invalidating an unrelated server removed %d decisions", removed)
+	}
+	a.filterToolsByRelevance(context.Background(), "User: list my buckets\n")
+	if llm.calls != 1 {
+		t.Fatalf("expected a cache hit before invalidation, got %d calls", llm.calls)
+	}
+
+	// The github server's tools changed, so decisions made against them are stale
+	if removed := cache.InvalidateServer("github"); removed != 1 {
+		t.Fatalf("InvalidateServer removed %d decisions, want 1", removed)
+	}
+	a.filterToolsByRelevance(context.Background(), "User: list my buckets\n")
+	if llm.calls != 2 {
+		t.Fatalf("expected the query to be routed again after invalidation, got %d calls", llm.calls)
+	}
+}
+
+func TestRoutingCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache, _ := NewRoutingCache(time.Hour, 0, "")
+	cache.SetMaxEntries(2)
+	decision := RoutingDecision{Servers: []string{"aws"}, Confidence: 0.9}
+
+	cache.Store("a", decision)
+	cache.Store("b", decision)
+	if _, ok := cache.Lookup("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	cache.Store("c", decision)
+
+	if cache.Len() != 2 {
+		t.Fatalf("cache holds %d decisions, want 2", cache.Len())
+	}
+	if _, ok := cache.Lookup("b"); ok {
+		t.Error("expected the least recently used decision b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Lookup(key); !ok {
+			t.Errorf("expected %s to stay cached", key)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// Bounds of the transcript sent to the structured LLM for the run summary
+const (
+	runSummaryMaxPartChars       = 1000
+	runSummaryMaxTranscriptChars = 30000
+)
+
+// RunSummary is the recap of a run attached to its completion event (see WithRunSummary)
+type RunSummary struct {
+	Summary   string   `json:"summary"`
+	Actions   []string `json:"actions"`
+	ToolsUsed []string `json:"tools_used"`
+	Decisions []string `json:"decisions"`
+}
+
+const runSummarySchema = `{
+  "type": "object",
+  "properties": {
+    "summary": {"type": "string", "description": "Two to four sentences on what the run did and what it concluded"},
+    "actions": {"type": "array", "items": {"type": "string"}, "description": "Main steps taken, in order"},
+    "tools_used": {"type": "array", "items": {"type": "string"}, "description": "Names of the tools called"},
+    "decisions": {"type": "array", "items": {"type": "string"}, "description": "Notable choices made and why"}
+  },
+  "required": ["summary", "actions", "tools_used", "decisions"]
+}`
+
+// WithRunSummary enables a structured-LLM recap of the run (what was done, tools used, decisions made)
+// attached to the completion event. Disabled by default since it costs an extra LLM call per run.
+func WithRunSummary(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.runSummaryEnabled = enabled
+	}
+}
+
+// withRunSummary attaches the run summary to a completion event when enabled. A failed summary is
+// logged and leaves the event as is.
+func (a *Agent) withRunSummary(ctx context.Context, messages []llmtypes.MessageContent, event *events.UnifiedCompletionEvent) *events.UnifiedCompletionEvent {
+	if !a.runSummaryEnabled {
+		return event
+	}
+	summary, err := a.summarizeRun(ctx, messages, event.FinalResult)
+	if err != nil {
+		a.Logger.Warnf("⚠️ Run summary failed: %v", err)
+		return event
+	}
+	event.Summary = summary.Summary
+	if event.Metadata == nil {
+		event.Metadata = make(map[string]interface{})
+	}
+	event.Metadata["run_summary"] = summary
+	return event
+}
+
+// summarizeRun asks the structured LLM for a recap of the conversation in messages
+func (a *Agent) summarizeRun(ctx context.Context, messages []llmtypes.MessageContent, finalResult string) (RunSummary, error) {
+	prompt := "Summarize the following agent run for the user: what was done, which tools were used and the decisions made along the way.\n\n" +
+		runTranscript(messages, finalResult)
+
+	summary, err := ConvertToStructuredOutput(a, ctx, prompt, RunSummary{}, runSummarySchema)
+	if err != nil {
+		return RunSummary{}, err
+	}
+	if strings.TrimSpace(summary.Summary) == "" {
+		return RunSummary{}, fmt.Errorf("structured LLM returned an empty summary")
+	}
+
+	// The tools actually called are known; don't rely on the model to list them
+	if called := calledToolNames(messages); len(called) > 0 {
+		summary.ToolsUsed = called
+	}
+	return summary, nil
+}
+
+// runTranscript renders the conversation as plain text, truncating long parts and keeping the most recent
+// messages when the whole transcript is too long
+func runTranscript(messages []llmtypes.MessageContent, finalResult string) string {
+	var entries []string
+	for _, message := range messages {
+		if message.Role == llmtypes.ChatMessageTypeSystem {
+			continue
+		}
+		for _, part := range message.Parts {
+			switch p := part.(type) {
+			case llmtypes.TextContent:
+				if strings.TrimSpace(p.Text) != "" {
+					entries = append(entries, fmt.Sprintf("[%s] %s", message.Role, truncateRunSummaryPart(p.Text)))
+				}
+			case llmtypes.ToolCall:
+				if p.FunctionCall != nil {
+					entries = append(entries, fmt.Sprintf("[tool call] %s(%s)", p.FunctionCall.Name, truncateRunSummaryPart(p.FunctionCall.Arguments)))
+				}
+			case llmtypes.ToolCallResponse:
+				entries = append(entries, fmt.Sprintf("[tool result] %s: %s", p.Name, truncateRunSummaryPart(p.Content)))
+			}
+		}
+	}
+	if finalResult != "" {
+		entries = append(entries, "[final answer] "+truncateRunSummaryPart(finalResult))
+	}
+
+	// Keep the most recent entries within the transcript budget
+	size := 0
+	first := len(entries)
+	for first > 0 && size+len(entries[first-1]) <= runSummaryMaxTranscriptChars {
+		first--
+		size += len(entries[first]) + 1
+	}
+	return strings.Join(entries[first:], "\n")
+}
+
+func truncateRunSummaryPart(text string) string {
+	if len(text) <= runSummaryMaxPartChars {
+		return text
+	}
+	return text[:runSummaryMaxPartChars] + "... [truncated]"
+}
+
+// calledToolNames returns the sorted names of the tools called in messages
+func calledToolNames(messages []llmtypes.MessageContent) []string {
+	seen := make(map[string]bool)
+	for _, message := range messages {
+		for _, part := range message.Parts {
+			if call, ok := part.(llmtypes.ToolCall); ok && call.FunctionCall != nil {
+				seen[call.FunctionCall.Name] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
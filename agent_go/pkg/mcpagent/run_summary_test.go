@@ -0,0 +1,125 @@
+package mcpagent
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// summarizingLLM runs the slow tool conversation and answers structured output prompts with summaryJSON
+func summarizingLLM(summaryJSON string) *fakeLLM {
+	conversation := slowToolResponder(mockLLMDelay)
+	return &fakeLLM{respond: func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		if isSummaryPrompt(messages) {
+			return textResponse(summaryJSON), nil
+		}
+		return conversation(call, messages)
+	}}
+}
+
+func isSummaryPrompt(messages []llmtypes.MessageContent) bool {
+	if len(messages) != 2 || messages[0].Role != llmtypes.ChatMessageTypeSystem {
+		return false
+	}
+	system, ok := messages[0].Parts[0].(llmtypes.TextContent)
+	return ok && strings.Contains(system.Text, "structured JSON output")
+}
+
+// summaryPrompts returns the transcripts llm was asked to summarize
+func summaryPrompts(llm *fakeLLM) []string {
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	var prompts []string
+	for _, messages := range llm.prompts {
+		if isSummaryPrompt(messages) {
+			prompts = append(prompts, messages[1].Parts[0].(llmtypes.TextContent).Text)
+		}
+	}
+	return prompts
+}
+
+func askSlowTool(t *testing.T, a *Agent) {
+	t.Helper()
+	answer, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "run the slow tool"}}},
+	})
+	if err != nil || answer != "done" {
+		t.Fatalf("unexpected result: %q, %v", answer, err)
+	}
+}
+
+func TestRunSummaryAttachedToCompletionEvent(t *testing.T) {
+	llm := summarizingLLM(`{"summary": "Ran the slow tool and reported it finished.", "actions": ["called slow_tool"], "tools_used": ["made_up_tool"], "decisions": ["answered after one tool call"]}`)
+	a := newLatencyTestAgent(t, llm, WithRunSummary(true))
+	completions := collectEvents[*events.UnifiedCompletionEvent](a)
+
+	askSlowTool(t, a)
+
+	completion := completions.last()
+	if completion == nil {
+		t.Fatal("expected a completion event")
+	}
+	if completion.Summary != "Ran the slow tool and reported it finished." {
+		t.Errorf("completion summary = %q", completion.Summary)
+	}
+	summary, ok := completion.Metadata["run_summary"].(RunSummary)
+	if !ok {
+		t.Fatalf("expected run_summary metadata, got %v", completion.Metadata["run_summary"])
+	}
+	// Tools used come from the conversation, not the model's answer
+	if !reflect.DeepEqual(summary.ToolsUsed, []string{"slow_tool"}) {
+		t.Errorf("tools used = %v, want [slow_tool]", summary.ToolsUsed)
+	}
+	if prompts := summaryPrompts(llm); len(prompts) != 1 || !strings.Contains(prompts[0], "[tool call] slow_tool") {
+		t.Errorf("expected one summary prompt with the transcript, got %v", prompts)
+	}
+}
+
+func TestRunSummaryDisabledByDefault(t *testing.T) {
+	llm := summarizingLLM(`{"summary": "unused"}`)
+	a := newLatencyTestAgent(t, llm)
+	completions := collectEvents[*events.UnifiedCompletionEvent](a)
+
+	askSlowTool(t, a)
+
+	if completion := completions.last(); completion.Summary != "" || completion.Metadata["run_summary"] != nil {
+		t.Errorf("expected no summary, got %q", completion.Summary)
+	}
+	if prompts := summaryPrompts(llm); len(prompts) != 0 {
+		t.Errorf("expected no summary LLM call, got %d", len(prompts))
+	}
+}
+
+func TestRunSummaryFailureKeepsCompletionEvent(t *testing.T) {
+	a := newLatencyTestAgent(t, summarizingLLM(`{"summary": ""}`), WithRunSummary(true), WithStructuredOutputMaxAttempts(1))
+	completions := collectEvents[*events.UnifiedCompletionEvent](a)
+
+	askSlowTool(t, a)
+
+	completion := completions.last()
+	if completion == nil || completion.FinalResult != "done" {
+		t.Fatalf("expected the completion event despite the failed summary, got %+v", completion)
+	}
+	if completion.Summary != "" {
+		t.Errorf("expected no summary, got %q", completion.Summary)
+	}
+}
+
+func TestRunTranscriptKeepsMostRecentEntries(t *testing.T) {
+	var messages []llmtypes.MessageContent
+	for i := 0; i < 100; i++ {
+		messages = append(messages, llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, strings.Repeat("x", 2000)))
+	}
+	transcript := runTranscript(messages, "final")
+
+	if len(transcript) > runSummaryMaxTranscriptChars {
+		t.Errorf("transcript is %d chars, want at most %d", len(transcript), runSummaryMaxTranscriptChars)
+	}
+	if !strings.HasSuffix(transcript, "[final answer] final") {
+		t.Errorf("expected the final answer to be kept")
+	}
+}
@@ -0,0 +1,139 @@
+package mcpagent
+
+import (
+	"sort"
+	"strings"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/mcpcache"
+	"mcp-agent/agent_go/pkg/mcpclient"
+)
+
+// ServerDedupPolicy controls what happens to configured servers that expose identical tool sets
+type ServerDedupPolicy string
+
+const (
+	// ServerDedupOff connects to every configured server (default)
+	ServerDedupOff ServerDedupPolicy = ""
+	// ServerDedupLogOnly detects redundant servers and logs them but still connects to all
+	ServerDedupLogOnly ServerDedupPolicy = "log"
+	// ServerDedupKeepOne connects to one server per identical tool set
+	ServerDedupKeepOne ServerDedupPolicy = "keep_one"
+)
+
+// serverSelectionSourceDedup is the MCPServerSelection source used when redundant servers are skipped
+const serverSelectionSourceDedup = "server_dedup"
+
+// WithServerDedupPolicy sets how servers exposing identical tool sets are handled.
+// Tool sets come from the tool cache, so servers that were never discovered are always connected.
+func WithServerDedupPolicy(policy ServerDedupPolicy) AgentOption {
+	return func(a *Agent) {
+		a.serverDedupPolicy = policy
+	}
+}
+
+// ParseServerDedupPolicy parses "off", "log" or "keep_one"; anything else disables dedup
+func ParseServerDedupPolicy(value string) ServerDedupPolicy {
+	switch policy := ServerDedupPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case ServerDedupLogOnly, ServerDedupKeepOne:
+		return policy
+	default:
+		return ServerDedupOff
+	}
+}
+
+// serverToolSetLookup returns the tool names a server exposes, if known
+type serverToolSetLookup func(serverName string, serverConfig mcpclient.MCPServerConfig) ([]string, bool)
+
+// cachedServerToolSet looks the server's tools up in the tool cache without connecting
+func (a *Agent) cachedServerToolSet(serverName string, serverConfig mcpclient.MCPServerConfig) ([]string, bool) {
+	entry, found := mcpcache.GetCacheManager(a.Logger).Get(mcpcache.GenerateUnifiedCacheKey(serverName, serverConfig))
+	if !found || len(entry.Tools) == 0 {
+		return nil, false
+	}
+	names := make([]string, 0, len(entry.Tools))
+	for _, tool := range entry.Tools {
+		if tool.Function != nil {
+			names = append(names, tool.Function.Name)
+		}
+	}
+	return names, len(names) > 0
+}
+
+// findRedundantServers groups candidates by identical tool sets and returns the servers to keep
+// plus, for each redundant server, the server kept in its place. Servers pinned by selected tools
+// are preferred, then the first by name. Servers with unknown tool sets are always kept.
+func findRedundantServers(candidates []string, toolSets map[string][]string, pinned map[string]bool) ([]string, map[string]string) {
+	sorted := append([]string(nil), candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if pinned[sorted[i]] != pinned[sorted[j]] {
+			return pinned[sorted[i]]
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	keptBySignature := make(map[string]string)
+	redundant := make(map[string]string)
+	for _, name := range sorted {
+		tools, known := toolSets[name]
+		if !known || len(tools) == 0 {
+			continue
+		}
+		signature := append([]string(nil), tools...)
+		sort.Strings(signature)
+		key := strings.Join(signature, "\x00")
+		if kept, exists := keptBySignature[key]; exists {
+			redundant[name] = kept
+			continue
+		}
+		keptBySignature[key] = name
+	}
+
+	kept := make([]string, 0, len(candidates))
+	for _, name := range candidates {
+		if _, isRedundant := redundant[name]; !isRedundant {
+			kept = append(kept, name)
+		}
+	}
+	return kept, redundant
+}
+
+// applyServerDedup drops servers whose tool set duplicates another candidate's under ServerDedupKeepOne,
+// logging every redundant server it detects
+func (a *Agent) applyServerDedup(config *mcpclient.MCPConfig, serverName string) string {
+	if a.serverDedupPolicy == ServerDedupOff || serverName == mcpclient.NoServers {
+		return serverName
+	}
+
+	candidates := expandServerNames(config, serverName)
+	sort.Strings(candidates)
+	lookup := a.serverToolSets
+	if lookup == nil {
+		lookup = a.cachedServerToolSet
+	}
+	toolSets := make(map[string][]string)
+	for _, name := range candidates {
+		if serverConfig, ok := config.MCPServers[name]; ok {
+			if tools, known := lookup(name, serverConfig); known {
+				toolSets[name] = tools
+			}
+		}
+	}
+
+	kept, redundant := findRedundantServers(candidates, toolSets, pinnedServers(a.selectedTools))
+	if len(redundant) == 0 {
+		return serverName
+	}
+	for _, name := range candidates {
+		if keptServer, isRedundant := redundant[name]; isRedundant {
+			a.Logger.Infof("🔁 Server %s exposes the same tools as %s (policy: %s)", name, keptServer, a.serverDedupPolicy)
+		}
+	}
+	if a.serverDedupPolicy != ServerDedupKeepOne {
+		return serverName
+	}
+
+	a.Logger.Infof("🔁 Skipping %d redundant server(s) - connecting to %v out of %d servers", len(redundant), kept, len(candidates))
+	a.recordServerSelection(events.NewMCPServerSelectionEvent(0, kept, len(candidates), serverSelectionSourceDedup, a.serverSelectionQuery))
+	return strings.Join(kept, ",")
+}
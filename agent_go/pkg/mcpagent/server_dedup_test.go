@@ -0,0 +1,85 @@
+package mcpagent
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/mcpclient"
+)
+
+func overlappingServersConfig() *mcpclient.MCPConfig {
+	return &mcpclient.MCPConfig{MCPServers: map[string]mcpclient.MCPServerConfig{
+		"github":        {Description: "GitHub repositories"},
+		"github-mirror": {Description: "GitHub repositories (second install)"},
+		"slack":         {Description: "Send and read Slack messages"},
+	}}
+}
+
+func newDedupTestAgent(t *testing.T, policy ServerDedupPolicy) *Agent {
+	t.Helper()
+	a := newTestAgent(t, answeringLLM("done"), WithServerDedupPolicy(policy))
+	a.serverToolSets = func(serverName string, _ mcpclient.MCPServerConfig) ([]string, bool) {
+		return map[string][]string{
+			"github":        {"create_issue", "list_pull_requests"},
+			"github-mirror": {"list_pull_requests", "create_issue"},
+			"slack":         {"send_message"},
+		}[serverName], true
+	}
+	return a
+}
+
+// runAndCollectSelections attaches a listener after construction, like the server does, and runs the agent
+func runAndCollectSelections(t *testing.T, a *Agent) []*events.MCPServerSelectionEvent {
+	t.Helper()
+	selections := collectEvents[*events.MCPServerSelectionEvent](a)
+	if _, err := a.Ask(context.Background(), "open an issue"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return selections.all()
+}
+
+func TestServerDedupKeepsOneOfIdenticalServers(t *testing.T) {
+	a := newDedupTestAgent(t, ServerDedupKeepOne)
+
+	serverName := a.applyServerDedup(overlappingServersConfig(), "all")
+	if serverName != "github,slack" {
+		t.Fatalf("expected only github and slack to connect, got %q", serverName)
+	}
+
+	selections := runAndCollectSelections(t, a)
+	if len(selections) != 1 {
+		t.Fatalf("expected one selection event, got %d", len(selections))
+	}
+	if selection := selections[0]; selection.Source != serverSelectionSourceDedup || selection.TotalServers != 3 {
+		t.Fatalf("unexpected selection event: %+v", selection)
+	}
+}
+
+func TestServerDedupPrefersPinnedServer(t *testing.T) {
+	a := newDedupTestAgent(t, ServerDedupKeepOne)
+	a.selectedTools = []string{"github-mirror:create_issue"}
+
+	if serverName := a.applyServerDedup(overlappingServersConfig(), "github,github-mirror"); serverName != "github-mirror" {
+		t.Fatalf("expected the server owning the selected tool to be kept, got %q", serverName)
+	}
+}
+
+func TestServerDedupLogOnlyConnectsAll(t *testing.T) {
+	a := newDedupTestAgent(t, ServerDedupLogOnly)
+
+	if serverName := a.applyServerDedup(overlappingServersConfig(), "all"); serverName != "all" {
+		t.Fatalf("expected every server to connect under the log policy, got %q", serverName)
+	}
+	if selections := runAndCollectSelections(t, a); len(selections) != 0 {
+		t.Fatalf("expected no selection event, got %d", len(selections))
+	}
+}
+
+func TestFindRedundantServersKeepsUnknownToolSets(t *testing.T) {
+	kept, redundant := findRedundantServers([]string{"a", "b", "c"}, map[string][]string{"a": {"x"}, "b": {"x"}}, nil)
+	if !reflect.DeepEqual(kept, []string{"a", "c"}) || !reflect.DeepEqual(redundant, map[string]string{"b": "a"}) {
+		t.Fatalf("unexpected dedup result: kept=%v redundant=%v", kept, redundant)
+	}
+}
@@ -0,0 +1,41 @@
+package mcpagent
+
+import (
+	"context"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/mcpclient"
+)
+
+// WithServerHealthCheck pings the agent's MCP servers every interval and reconnects a server that
+// stopped answering with up to maxRetries attempts, emitting a connection event on each transition.
+// A zero interval disables the health check (the default).
+func WithServerHealthCheck(interval time.Duration, maxRetries int) AgentOption {
+	return func(a *Agent) {
+		a.healthCheckConfig = mcpclient.HealthCheckConfig{
+			Interval:   interval,
+			MaxRetries: maxRetries,
+		}
+	}
+}
+
+// startServerHealthCheck starts the background health checker once the agent's clients are connected
+func (a *Agent) startServerHealthCheck() {
+	if a.healthCheckConfig.Interval <= 0 || len(a.Clients) == 0 {
+		return
+	}
+
+	a.healthChecker = mcpclient.NewHealthChecker(a.healthCheckConfig, a.Clients, a.Logger, func(event *events.MCPServerConnectionEvent) {
+		a.EmitTypedEvent(context.Background(), event)
+	})
+	a.healthChecker.Start(context.Background())
+}
+
+// stopServerHealthCheck stops the background health checker, if one is running
+func (a *Agent) stopServerHealthCheck() {
+	if a.healthChecker != nil {
+		a.healthChecker.Stop()
+		a.healthChecker = nil
+	}
+}
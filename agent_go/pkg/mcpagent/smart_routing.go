@@ -99,54 +99,81 @@ func (a *Agent) filterToolsByRelevance(ctx context.Context, conversationContext
 	if startEvent.LLMMaxTokens == 0 {
 		startEvent.LLMMaxTokens = 1000 // Default max tokens
 	}
-	a.EmitTypedEvent(ctx, startEvent)
 
 	startTime := time.Now()
 
+	// Reuse a cached decision for a similar query against the same tool set
+	var relevantServers []string
+	var reasoning, llmResponse, cacheKey string
+	var confidence float64
+	cached := false
+	if a.routingCache != nil {
+		cacheKey = routingCacheKey(conversationContext, a.Tools, a.toolToServer)
+		if decision, ok := a.routingCache.Lookup(cacheKey); ok {
+			relevantServers, reasoning, confidence, cached = decision.Servers, decision.Reasoning, decision.Confidence, true
+			a.Logger.Infof("🎯 Reusing cached smart routing decision: servers %v (confidence %.2f)", relevantServers, confidence)
+		}
+	}
+	startEvent.CacheHit = cached
+	a.EmitTypedEvent(ctx, startEvent)
+
 	// Get relevant servers with reasoning
-	relevantServers, reasoning, llmResponse, err := a.determineRelevantServersWithReasoning(ctx, conversationContext)
-	if err != nil {
-		// Emit failure event
-		endEvent := events.NewSmartRoutingEndEvent(
-			len(a.Tools), 0, a.getServerCount(), nil, "",
-			time.Since(startTime), false, err.Error(),
-		)
-
-		// NEW: Add appended prompt information even for failures
-		endEvent.HasAppendedPrompts = a.HasAppendedPrompts
-		endEvent.AppendedPromptCount = len(a.AppendedSystemPrompts)
-
-		if a.HasAppendedPrompts && len(a.AppendedSystemPrompts) > 0 {
-			// Create a summary of appended prompts
-			var summary strings.Builder
-			for i, prompt := range a.AppendedSystemPrompts {
-				if i > 0 {
-					summary.WriteString("; ")
-				}
-				// Take first 100 chars of each prompt
-				content := prompt
-				if len(content) > 100 {
-					content = content[:100] + "..."
+	if !cached {
+		var err error
+		relevantServers, reasoning, llmResponse, err = a.determineRelevantServersWithReasoning(ctx, conversationContext)
+		if err != nil {
+			// Emit failure event
+			endEvent := events.NewSmartRoutingEndEvent(
+				len(a.Tools), 0, a.getServerCount(), nil, "",
+				time.Since(startTime), false, err.Error(),
+			)
+
+			// NEW: Add appended prompt information even for failures
+			endEvent.HasAppendedPrompts = a.HasAppendedPrompts
+			endEvent.AppendedPromptCount = len(a.AppendedSystemPrompts)
+
+			if a.HasAppendedPrompts && len(a.AppendedSystemPrompts) > 0 {
+				// Create a summary of appended prompts
+				var summary strings.Builder
+				for i, prompt := range a.AppendedSystemPrompts {
+					if i > 0 {
+						summary.WriteString("; ")
+					}
+					// Take first 100 chars of each prompt
+					content := prompt
+					if len(content) > 100 {
+						content = content[:100] + "..."
+					}
+					summary.WriteString(content)
 				}
-				summary.WriteString(content)
+				endEvent.AppendedPromptSummary = summary.String()
 			}
-			endEvent.AppendedPromptSummary = summary.String()
-		}
 
-		// Add LLM information for smart routing
-		endEvent.LLMModelID = a.ModelID
-		endEvent.LLMProvider = string(a.GetProvider())
-		endEvent.LLMTemperature = a.SmartRoutingConfig.Temperature
-		if endEvent.LLMTemperature == 0 {
-			endEvent.LLMTemperature = 0.1 // Default temperature
-		}
-		endEvent.LLMMaxTokens = a.SmartRoutingConfig.MaxTokens
-		if endEvent.LLMMaxTokens == 0 {
-			endEvent.LLMMaxTokens = 1000 // Default max tokens
+			// Add LLM information for smart routing
+			endEvent.LLMModelID = a.ModelID
+			endEvent.LLMProvider = string(a.GetProvider())
+			endEvent.LLMTemperature = a.SmartRoutingConfig.Temperature
+			if endEvent.LLMTemperature == 0 {
+				endEvent.LLMTemperature = 0.1 // Default temperature
+			}
+			endEvent.LLMMaxTokens = a.SmartRoutingConfig.MaxTokens
+			if endEvent.LLMMaxTokens == 0 {
+				endEvent.LLMMaxTokens = 1000 // Default max tokens
+			}
+
+			a.EmitTypedEvent(ctx, endEvent)
+			return nil, err
 		}
 
-		a.EmitTypedEvent(ctx, endEvent)
-		return nil, err
+		confidence = parseRoutingConfidence(llmResponse)
+		if a.routingCache != nil {
+			a.routingCache.Store(cacheKey, RoutingDecision{
+				Servers:     relevantServers,
+				Reasoning:   reasoning,
+				Confidence:  confidence,
+				ToolServers: routingToolServers(a.Tools, a.toolToServer),
+			})
+		}
 	}
 
 	// 🔄 NEW: Rebuild system prompt with filtered servers
@@ -165,6 +192,8 @@ func (a *Agent) filterToolsByRelevance(ctx context.Context, conversationContext
 	// Populate LLM response fields for debugging
 	endEvent.LLMResponse = llmResponse
 	endEvent.SelectedServers = strings.Join(relevantServers, ", ")
+	endEvent.CacheHit = cached
+	endEvent.Confidence = confidence
 
 	// NEW: Add appended prompt information
 	endEvent.HasAppendedPrompts = a.HasAppendedPrompts
@@ -342,9 +371,13 @@ func (a *Agent) makeLightweightLLMCallWithReasoning(ctx context.Context, prompt
 			"reasoning": {
 				"type": "string",
 				"description": "Brief explanation of why these servers were selected"
+			},
+			"confidence": {
+				"type": "number",
+				"description": "Confidence from 0.0 to 1.0 that these servers cover everything the conversation needs"
 			}
 		},
-		"required": ["relevant_servers", "reasoning"]
+		"required": ["relevant_servers", "reasoning", "confidence"]
 	}`
 
 	// Use configurable values with fallbacks
@@ -571,7 +604,7 @@ func (a *Agent) parseTextServerResponse(response string) ([]string, error) {
 
 // Filter tools by server
 func (a *Agent) filterToolsByServers(relevantServers []string) []llmtypes.Tool {
-		var filteredTools []llmtypes.Tool
+	var filteredTools []llmtypes.Tool
 
 	for _, tool := range a.Tools {
 		// Check if this is a custom tool (no server mapping)
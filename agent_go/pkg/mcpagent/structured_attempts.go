@@ -0,0 +1,86 @@
+package mcpagent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// defaultStructuredOutputMaxAttempts is the first attempt plus two re-asks
+	defaultStructuredOutputMaxAttempts = 3
+	// structuredOutputEventMaxChars bounds the attempt output carried by StructuredOutputAttemptEvent
+	structuredOutputEventMaxChars = 500
+)
+
+// WithStructuredOutputMaxAttempts sets how many times structured output is generated before giving up
+// on validation errors (default 3). Every attempt after the first re-asks the model with the errors found.
+func WithStructuredOutputMaxAttempts(attempts int) AgentOption {
+	return func(a *Agent) {
+		if attempts > 0 {
+			a.structuredMaxAttempts = attempts
+		}
+	}
+}
+
+func (a *Agent) structuredOutputMaxAttempts() int {
+	if a.structuredMaxAttempts > 0 {
+		return a.structuredMaxAttempts
+	}
+	return defaultStructuredOutputMaxAttempts
+}
+
+// validateStructuredOutput checks jsonOutput against the JSON schema (when there is one) and decodes
+// it into target, returning the validation errors found
+func validateStructuredOutput(jsonOutput string, target interface{}, schema *structuredSchemaNode) []StructuredFieldError {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(jsonOutput), &decoded); err != nil {
+		return []StructuredFieldError{{Message: fmt.Sprintf("invalid JSON structure: %v", err)}}
+	}
+	if schema != nil {
+		if schemaErrors := validateAgainstSchema(decoded, schema); len(schemaErrors) > 0 {
+			return schemaErrors
+		}
+	}
+	if err := json.Unmarshal([]byte(jsonOutput), target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return []StructuredFieldError{{Field: typeErr.Field, Message: fmt.Sprintf("must be %s, got JSON %s", typeErr.Type, typeErr.Value)}}
+		}
+		return []StructuredFieldError{{Message: fmt.Sprintf("JSON does not match the schema: %v", err)}}
+	}
+	return nil
+}
+
+// ValidateStructuredOutput checks jsonOutput against schemaString (a JSON schema; "" skips the schema
+// check) and decodes it into target, like the structured Ask calls do for the LLM's answer
+func ValidateStructuredOutput(jsonOutput, schemaString string, target interface{}) error {
+	if fieldErrors := validateStructuredOutput(jsonOutput, target, parseStructuredSchema(schemaString)); len(fieldErrors) > 0 {
+		return &StructuredOutputValidationError{Attempts: 1, Errors: fieldErrors}
+	}
+	return nil
+}
+
+// buildStructuredReaskPrompt asks for the structured output again, showing the rejected attempt and why
+func buildStructuredReaskPrompt(textOutput, previousOutput string, validationErrors []string) string {
+	var b strings.Builder
+	b.WriteString(textOutput)
+	b.WriteString("\n\nYour previous JSON response failed validation:\n")
+	for _, validationErr := range validationErrors {
+		b.WriteString("- ")
+		b.WriteString(validationErr)
+		b.WriteString("\n")
+	}
+	b.WriteString("\nPrevious response:\n")
+	b.WriteString(truncateStructuredOutput(previousOutput))
+	b.WriteString("\n\nReturn the corrected JSON object, fixing every error above.")
+	return b.String()
+}
+
+func truncateStructuredOutput(output string) string {
+	if len(output) <= structuredOutputEventMaxChars {
+		return output
+	}
+	return output[:structuredOutputEventMaxChars] + "...(truncated)"
+}
@@ -0,0 +1,89 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+type temperatureReport struct {
+	City        string `json:"city"`
+	Temperature int    `json:"temperature"`
+}
+
+func TestStructuredOutputValidationErrorEventPrecedesRetry(t *testing.T) {
+	llm := scriptedLLM([]*llmtypes.ContentChoice{
+		{Content: "It is 21 degrees in Paris."},
+		// The first structured attempt violates the schema: temperature must be a number
+		{Content: `{"city": "Paris", "temperature": "twenty-one"}`},
+		{Content: `{"city": "Paris", "temperature": 21}`},
+	})
+	a := newTestAgent(t, llm)
+	attempts := collectEvents[*events.StructuredOutputAttemptEvent](a)
+
+	report, err := AskStructured(a, context.Background(), "temperature in Paris?", temperatureReport{}, `{"city": "string", "temperature": "number"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.City != "Paris" || report.Temperature != 21 {
+		t.Fatalf("expected the corrected report, got %+v", report)
+	}
+	// The re-ask shows the model its rejected output and the error
+	if !strings.Contains(llm.promptText(2), `"temperature": "twenty-one"`) || !strings.Contains(llm.promptText(2), `field "temperature" must be int`) {
+		t.Fatalf("expected the re-ask to carry the validation error, got %q", llm.promptText(2))
+	}
+
+	attempted := attempts.all()
+	if len(attempted) != 2 {
+		t.Fatalf("expected two attempt events, got %d", len(attempted))
+	}
+	first, second := attempted[0], attempted[1]
+	if first.Attempt != 1 || first.Valid || !first.WillRetry || len(first.ValidationErrors) != 1 || !strings.Contains(first.ValidationErrors[0], "temperature") {
+		t.Fatalf("expected a validation-error event for the first attempt, got %+v", first)
+	}
+	if second.Attempt != 2 || !second.Valid || second.WillRetry || len(second.ValidationErrors) != 0 {
+		t.Fatalf("expected a valid second attempt, got %+v", second)
+	}
+}
+
+func TestStructuredOutputMaxAttemptsStopsReasking(t *testing.T) {
+	llm := answeringLLM(proseAnswer)
+	a := newTestAgent(t, llm, WithStructuredOutputMaxAttempts(2))
+	attempts := collectEvents[*events.StructuredOutputAttemptEvent](a)
+
+	_, err := AskStructured(a, context.Background(), "weather in Paris?", weatherReport{}, `{"city": "string", "condition": "string"}`)
+	if err == nil || !strings.Contains(err.Error(), "failed validation after 2 attempts") {
+		t.Fatalf("expected a validation failure after 2 attempts, got %v", err)
+	}
+	// One conversation call plus two structured attempts
+	if llm.calls != 3 {
+		t.Fatalf("expected 3 LLM calls, got %d", llm.calls)
+	}
+	if attempted := attempts.all(); len(attempted) != 2 || attempted[1].WillRetry {
+		t.Fatalf("expected two attempt events with no retry after the last, got %+v", attempted)
+	}
+}
+
+func TestStructuredOutputUsesStructuredOutputLLM(t *testing.T) {
+	executionLLM := scriptedLLM([]*llmtypes.ContentChoice{{Content: "It is 21 degrees in Paris."}})
+	structuredLLM := scriptedLLM([]*llmtypes.ContentChoice{{Content: `{"city": "Paris", "temperature": 21}`}})
+	a := newTestAgent(t, executionLLM, WithStructuredOutputLLM(structuredLLM))
+
+	report, err := AskStructured(a, context.Background(), "temperature in Paris?", temperatureReport{}, `{"city": "string", "temperature": "number"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.City != "Paris" || report.Temperature != 21 {
+		t.Fatalf("expected the structured report, got %+v", report)
+	}
+	if executionLLM.calls != 1 || structuredLLM.calls != 1 {
+		t.Fatalf("expected the question on the execution LLM and the conversion on the structured output LLM, got %d/%d calls",
+			executionLLM.calls, structuredLLM.calls)
+	}
+	if !strings.Contains(structuredLLM.promptText(0), "It is 21 degrees in Paris.") {
+		t.Fatalf("expected the answer converted by the structured output LLM, got %q", structuredLLM.promptText(0))
+	}
+}
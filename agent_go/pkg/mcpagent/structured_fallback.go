@@ -0,0 +1,64 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// WithStructuredOutputRawFallback makes the *WithFallback structured calls return the raw
+// text answer, flagged as degraded, when it cannot be converted to structured output after retries
+func WithStructuredOutputRawFallback(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.structuredRawFallback = enabled
+	}
+}
+
+// StructuredResult is the outcome of a structured call that may have degraded to raw text
+type StructuredResult[T any] struct {
+	// Value is the parsed structured output (zero value when Degraded)
+	Value T
+	// RawResponse is the unstructured text answer the structured output was derived from
+	RawResponse string
+	// Degraded is true when structured conversion failed and only RawResponse is usable
+	Degraded bool
+	// DegradedReason describes why structured conversion failed
+	DegradedReason string
+}
+
+// AskStructuredWithFallback is like AskStructured but, when raw fallback is enabled, returns the
+// raw text answer with Degraded set instead of failing on persistent structured output errors
+func AskStructuredWithFallback[T any](a *Agent, ctx context.Context, question string, schema T, schemaString string) (StructuredResult[T], error) {
+	userMessage := llmtypes.MessageContent{
+		Role:  llmtypes.ChatMessageTypeHuman,
+		Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: question}},
+	}
+
+	result, _, err := AskWithHistoryStructuredWithFallback(a, ctx, []llmtypes.MessageContent{userMessage}, schema, schemaString)
+	return result, err
+}
+
+// AskWithHistoryStructuredWithFallback is the message history variant of AskStructuredWithFallback
+func AskWithHistoryStructuredWithFallback[T any](a *Agent, ctx context.Context, messages []llmtypes.MessageContent, schema T, schemaString string) (StructuredResult[T], []llmtypes.MessageContent, error) {
+	textResponse, updatedMessages, err := a.AskWithHistory(ctx, messages)
+	if err != nil {
+		return StructuredResult[T]{}, updatedMessages, fmt.Errorf("failed to get text response: %w", err)
+	}
+
+	result := StructuredResult[T]{RawResponse: textResponse}
+	value, err := ConvertToStructuredOutput(a, ctx, textResponse, schema, schemaString)
+	if err != nil {
+		// Context cancellation is never degraded into a raw answer
+		if !a.structuredRawFallback || ctx.Err() != nil {
+			return result, updatedMessages, fmt.Errorf("failed to convert to structured output: %w", err)
+		}
+		a.Logger.Warnf("⚠️ Structured output failed, returning raw text response (%d chars): %v", len(textResponse), err)
+		result.Degraded = true
+		result.DegradedReason = err.Error()
+		return result, updatedMessages, nil
+	}
+
+	result.Value = value
+	return result, updatedMessages, nil
+}
@@ -0,0 +1,49 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+)
+
+// proseAnswer is never valid JSON
+const proseAnswer = "The weather in Paris is sunny."
+
+type weatherReport struct {
+	City      string `json:"city"`
+	Condition string `json:"condition"`
+}
+
+func TestAskStructuredWithFallbackReturnsRawWhenEnabled(t *testing.T) {
+	llm := answeringLLM(proseAnswer)
+	a := newTestAgent(t, llm, WithStructuredOutputRawFallback(true))
+
+	result, err := AskStructuredWithFallback(a, context.Background(), "weather in Paris?", weatherReport{}, `{"city": "string", "condition": "string"}`)
+	if err != nil {
+		t.Fatalf("expected raw fallback instead of error, got %v", err)
+	}
+	if !result.Degraded || result.DegradedReason == "" {
+		t.Fatalf("expected degraded result with a reason, got %+v", result)
+	}
+	if result.RawResponse != proseAnswer {
+		t.Fatalf("expected raw text answer, got %q", result.RawResponse)
+	}
+	if result.Value != (weatherReport{}) {
+		t.Fatalf("expected zero structured value when degraded, got %+v", result.Value)
+	}
+	// One conversation call plus the initial structured attempt and its retries
+	if llm.calls < 3 {
+		t.Fatalf("expected structured output retries before falling back, got %d LLM calls", llm.calls)
+	}
+}
+
+func TestAskStructuredWithFallbackFailsWhenDisabled(t *testing.T) {
+	a := newTestAgent(t, answeringLLM(proseAnswer))
+
+	result, err := AskStructuredWithFallback(a, context.Background(), "weather in Paris?", weatherReport{}, `{"city": "string", "condition": "string"}`)
+	if err == nil {
+		t.Fatalf("expected an error without raw fallback, got %+v", result)
+	}
+	if result.Degraded {
+		t.Fatalf("expected no degradation flag on failure, got %+v", result)
+	}
+}
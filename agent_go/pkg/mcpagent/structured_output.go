@@ -10,6 +10,7 @@ import (
 
 	"mcp-agent/agent_go/internal/llmtypes"
 	"mcp-agent/agent_go/internal/utils"
+	"mcp-agent/agent_go/pkg/events"
 )
 
 // LangchaingoStructuredOutputConfig contains configuration for structured output generation
@@ -45,6 +46,17 @@ func (sog *LangchaingoStructuredOutputGenerator) GenerateStructuredOutput(ctx co
 
 	sog.logger.Infof("Enhanced prompt length: %d chars", len(enhancedPrompt))
 
+	response, err := sog.generate(ctx, enhancedPrompt)
+	if err != nil {
+		sog.logger.Errorf("LLM call failed: %w", err)
+		return "", fmt.Errorf("failed to generate structured output: %w", err)
+	}
+
+	return sog.extractContent(response)
+}
+
+// generate sends a structured output prompt to the LLM in JSON mode
+func (sog *LangchaingoStructuredOutputGenerator) generate(ctx context.Context, prompt string) (*llmtypes.ContentResponse, error) {
 	// Always use JSON mode for consistent output
 	messages := []llmtypes.MessageContent{
 		{
@@ -56,7 +68,7 @@ func (sog *LangchaingoStructuredOutputGenerator) GenerateStructuredOutput(ctx co
 		{
 			Role: llmtypes.ChatMessageTypeHuman,
 			Parts: []llmtypes.ContentPart{
-				llmtypes.TextContent{Text: enhancedPrompt},
+				llmtypes.TextContent{Text: prompt},
 			},
 		},
 	}
@@ -76,13 +88,7 @@ func (sog *LangchaingoStructuredOutputGenerator) GenerateStructuredOutput(ctx co
 	}
 
 	sog.logger.Infof("Structured output max_tokens: %d", maxTokens)
-	response, err := sog.llm.GenerateContent(ctx, messages, opts...)
-	if err != nil {
-		sog.logger.Errorf("LLM call failed: %w", err)
-		return "", fmt.Errorf("failed to generate structured output: %w", err)
-	}
-
-	return sog.extractContent(response)
+	return sog.llm.GenerateContent(ctx, messages, opts...)
 }
 
 // extractContent extracts content from the LLM response
@@ -266,64 +272,70 @@ func (sog *LangchaingoStructuredOutputGenerator) retryGeneration(ctx context.Con
 	return retryGenerator.GenerateStructuredOutput(ctx, retryPrompt, "")
 }
 
-// ConvertToStructuredOutput converts text output to structured format using the LLM
+// ConvertToStructuredOutput converts text output to structured format using the LLM. Output that is not
+// valid JSON, violates the JSON schema in schemaString or does not match T is fed back with its validation
+// errors for another attempt (see WithStructuredOutputMaxAttempts); each attempt emits a
+// StructuredOutputAttemptEvent. Output still invalid after the last attempt returns a
+// *StructuredOutputValidationError.
 func ConvertToStructuredOutput[T any](a *Agent, ctx context.Context, textOutput string, schema T, schemaString string) (T, error) {
-	// Use the LLM to convert the text output to structured JSON
-	generator := getOrCreateStructuredOutputGenerator(a)
+	var zero T
 
-	jsonOutput, err := generator.GenerateStructuredOutput(ctx, textOutput, schemaString)
-	if err != nil {
-		var zero T
-		return zero, fmt.Errorf("failed to convert to structured output: %w", err)
-	}
+	// Use the LLM to convert the text output to structured JSON; validation happens per attempt below
+	generator := getOrCreateStructuredOutputGenerator(a)
+	generator.config.ValidateOutput = false
+	maxAttempts := a.structuredOutputMaxAttempts()
+	jsonSchema := parseStructuredSchema(schemaString)
+
+	prompt := textOutput
+	for attempt := 1; ; attempt++ {
+		var jsonOutput string
+		var err error
+		if a.structuredMaxResumes > 0 {
+			jsonOutput, err = generator.GenerateStructuredOutputWithResume(ctx, prompt, schemaString, a.structuredMaxResumes)
+		} else {
+			jsonOutput, err = generator.GenerateStructuredOutput(ctx, prompt, schemaString)
+		}
+		if err != nil {
+			return zero, fmt.Errorf("failed to convert to structured output: %w", err)
+		}
 
-	// Add detailed logging for JSON parsing
-	a.Logger.Infof("🔍 JSON PARSING DEBUG: Starting JSON unmarshaling")
-	a.Logger.Infof("🔍 JSON PARSING DEBUG: JSON output length: %d chars", len(jsonOutput))
-	a.Logger.Infof("🔍 JSON PARSING DEBUG: JSON output content: %s", jsonOutput)
-
-	// Validate JSON before parsing (using interface{} to support both objects and arrays)
-	var jsonValidator interface{}
-	if err := json.Unmarshal([]byte(jsonOutput), &jsonValidator); err != nil {
-		a.Logger.Errorf("❌ JSON PARSING DEBUG: JSON validation failed: %w", err)
-		var zero T
-		return zero, fmt.Errorf("invalid JSON structure: %w", err)
-	}
-	a.Logger.Infof("✅ JSON PARSING DEBUG: JSON validation passed")
-
-	// Parse JSON back to the target type
-	var result T
-	if err := json.Unmarshal([]byte(jsonOutput), &result); err != nil {
-		a.Logger.Errorf("❌ JSON PARSING DEBUG: JSON unmarshaling failed: %w", err)
-		var zero T
-		return zero, fmt.Errorf("failed to parse structured output: %w", err)
-	}
+		// Add detailed logging for JSON parsing
+		a.Logger.Infof("🔍 JSON PARSING DEBUG: Attempt %d/%d, JSON output length: %d chars", attempt, maxAttempts, len(jsonOutput))
+		a.Logger.Infof("🔍 JSON PARSING DEBUG: JSON output content: %s", jsonOutput)
 
-	// Log the parsed result for debugging
-	a.Logger.Infof("✅ JSON PARSING DEBUG: JSON unmarshaling successful")
-	a.Logger.Infof("🔍 JSON PARSING DEBUG: Parsed result type: %T", result)
+		var result T
+		fieldErrors := validateStructuredOutput(jsonOutput, &result, jsonSchema)
+		validationErrors := structuredErrorStrings(fieldErrors)
+		a.EmitTypedEvent(ctx, events.NewStructuredOutputAttemptEvent(attempt, maxAttempts, validationErrors, truncateStructuredOutput(jsonOutput)))
+		if len(validationErrors) == 0 {
+			a.Logger.Infof("✅ JSON PARSING DEBUG: JSON unmarshaling successful, parsed result type: %T", result)
 
-	// Add detailed logging for struct field assignment
-	if resultBytes, err := json.Marshal(result); err == nil {
-		a.Logger.Infof("🔍 JSON PARSING DEBUG: Struct after unmarshaling: %s", string(resultBytes))
-	}
+			// Special logging for PlanningResponse to debug should_continue issue
+			if planningResp, ok := any(result).(interface{ GetShouldContinue() bool }); ok {
+				a.Logger.Infof("🔍 JSON PARSING DEBUG: PlanningResponse should_continue: %t", planningResp.GetShouldContinue())
+			}
+			return result, nil
+		}
 
-	// Special logging for PlanningResponse to debug should_continue issue
-	if planningResp, ok := any(result).(interface{ GetShouldContinue() bool }); ok {
-		a.Logger.Infof("🔍 JSON PARSING DEBUG: PlanningResponse should_continue: %t", planningResp.GetShouldContinue())
+		a.Logger.Errorf("❌ JSON PARSING DEBUG: Attempt %d/%d failed validation: %s", attempt, maxAttempts, strings.Join(validationErrors, "; "))
+		if attempt >= maxAttempts {
+			return zero, &StructuredOutputValidationError{Attempts: attempt, Errors: fieldErrors}
+		}
+		prompt = buildStructuredReaskPrompt(textOutput, jsonOutput, validationErrors)
 	}
+}
 
-	// Try to extract should_continue using reflection for debugging
-	if jsonData, err := json.Marshal(result); err == nil {
-		var debugMap map[string]interface{}
-		if err := json.Unmarshal(jsonData, &debugMap); err == nil {
-			if shouldContinue, exists := debugMap["should_continue"]; exists {
-				a.Logger.Infof("🔍 JSON PARSING DEBUG: should_continue from parsed result: %v (type: %T)", shouldContinue, shouldContinue)
-			}
-		}
+// WithStructuredOutputLLM converts answers to structured output with model instead of the agent's LLM,
+// e.g. a cheaper or faster model than the one executing the conversation
+func WithStructuredOutputLLM(model llmtypes.Model) AgentOption {
+	return func(a *Agent) {
+		a.structuredOutputLLM = model
 	}
+}
 
-	return result, nil
+// SetStructuredOutputLLM replaces the model used for structured output conversion; nil uses the agent's LLM
+func (a *Agent) SetStructuredOutputLLM(model llmtypes.Model) {
+	a.structuredOutputLLM = model
 }
 
 // getOrCreateStructuredOutputGenerator creates a structured output generator if needed
@@ -335,5 +347,9 @@ func getOrCreateStructuredOutputGenerator(a *Agent) *LangchaingoStructuredOutput
 		MaxRetries:     2,
 	}
 
-	return NewLangchaingoStructuredOutputGenerator(a.LLM, config, a.Logger)
+	model := a.LLM
+	if a.structuredOutputLLM != nil {
+		model = a.structuredOutputLLM
+	}
+	return NewLangchaingoStructuredOutputGenerator(model, config, a.Logger)
 }
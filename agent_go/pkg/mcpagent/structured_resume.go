@@ -0,0 +1,247 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// WithStructuredOutputResume resumes structured output that was cut off by the output token limit:
+// the partial object and the schema are fed back up to maxResumes times so the model completes the
+// remaining fields, and the pieces are stitched into one object. 0 disables resuming.
+func WithStructuredOutputResume(maxResumes int) AgentOption {
+	return func(a *Agent) {
+		if maxResumes > 0 {
+			a.structuredMaxResumes = maxResumes
+		}
+	}
+}
+
+// StructuredOutputTruncatedError is returned when structured output is still incomplete after all
+// resumes. Partial holds the completed part of the object as valid JSON and can be passed to
+// ResumeStructured to continue later.
+type StructuredOutputTruncatedError struct {
+	Partial string
+	Resumes int
+}
+
+func (e *StructuredOutputTruncatedError) Error() string {
+	return fmt.Sprintf("structured output still truncated after %d resumes (%d chars of partial output)", e.Resumes, len(e.Partial))
+}
+
+// ResumeStructured completes a partial structured object previously returned by a truncated
+// structured call, e.g. StructuredOutputTruncatedError.Partial. The partial may be cut off mid-value.
+func ResumeStructured[T any](a *Agent, ctx context.Context, partial string, schema T, schemaString string) (T, error) {
+	var zero T
+	maxResumes := max(a.structuredMaxResumes, 1)
+
+	generator := getOrCreateStructuredOutputGenerator(a)
+	jsonOutput, err := generator.resumeStructuredOutput(ctx, partial, schemaString, maxResumes)
+	if err != nil {
+		return zero, fmt.Errorf("failed to resume structured output: %w", err)
+	}
+
+	var result T
+	if err := json.Unmarshal([]byte(jsonOutput), &result); err != nil {
+		return zero, fmt.Errorf("failed to parse resumed structured output: %w", err)
+	}
+	return result, nil
+}
+
+// GenerateStructuredOutputWithResume generates structured output and, when the response is cut off,
+// resumes it up to maxResumes times from the partial object
+func (sog *LangchaingoStructuredOutputGenerator) GenerateStructuredOutputWithResume(ctx context.Context, prompt string, schema string, maxResumes int) (string, error) {
+	response, err := sog.generate(ctx, sog.buildStructuredPromptWithSchema(prompt, schema))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate structured output: %w", err)
+	}
+	if response == nil || len(response.Choices) == 0 || response.Choices[0].Content == "" {
+		return "", fmt.Errorf("no content in LLM response")
+	}
+
+	content := sog.cleanContentForJSON(response.Choices[0].Content)
+	if !isTruncatedStopReason(response.Choices[0].StopReason) && json.Valid([]byte(content)) {
+		return content, nil
+	}
+	if !strings.HasPrefix(content, "{") {
+		return "", fmt.Errorf("invalid JSON output that cannot be resumed: %s", content[:min(len(content), 200)])
+	}
+
+	sog.logger.Warnf("⚠️ Structured output truncated after %d chars, resuming from the partial object", len(content))
+	return sog.resumeStructuredOutput(ctx, content, schema, maxResumes)
+}
+
+// resumeStructuredOutput asks the model for the fields missing from the partial object and merges
+// them in, repeating while the continuation itself is cut off
+func (sog *LangchaingoStructuredOutputGenerator) resumeStructuredOutput(ctx context.Context, partial string, schema string, maxResumes int) (string, error) {
+	merged, err := repairTruncatedJSONObject(partial)
+	if err != nil {
+		return "", err
+	}
+
+	for resume := 1; resume <= maxResumes; resume++ {
+		partialJSON, err := json.Marshal(merged)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode partial object: %w", err)
+		}
+
+		response, err := sog.generate(ctx, buildResumePrompt(string(partialJSON), partial, schema))
+		if err != nil {
+			return "", fmt.Errorf("failed to resume structured output: %w", err)
+		}
+		if response == nil || len(response.Choices) == 0 {
+			return "", fmt.Errorf("no response generated from LLM")
+		}
+
+		content := sog.cleanContentForJSON(response.Choices[0].Content)
+		truncated := isTruncatedStopReason(response.Choices[0].StopReason) || !json.Valid([]byte(content))
+		continuation, err := repairTruncatedJSONObject(content)
+		if err != nil {
+			return "", fmt.Errorf("invalid continuation on resume %d: %w", resume, err)
+		}
+		mergeStructuredObjects(merged, continuation)
+		sog.logger.Infof("🔁 Structured output resume %d/%d merged %d fields (truncated: %t)", resume, maxResumes, len(continuation), truncated)
+
+		if !truncated {
+			result, err := json.Marshal(merged)
+			if err != nil {
+				return "", fmt.Errorf("failed to encode resumed object: %w", err)
+			}
+			return string(result), nil
+		}
+		partial = content
+	}
+
+	result, _ := json.Marshal(merged)
+	return "", &StructuredOutputTruncatedError{Partial: string(result), Resumes: maxResumes}
+}
+
+// buildResumePrompt asks for only the remaining fields of a cut-off object
+func buildResumePrompt(partialJSON, rawTail, schema string) string {
+	var parts []string
+	parts = append(parts, "A previous response producing a JSON object was cut off by the output limit.")
+	parts = append(parts, "\n\nThe complete part of the object so far:\n", partialJSON)
+	parts = append(parts, "\n\nThe response ended with:\n", rawTail[max(len(rawTail)-500, 0):])
+	if schema != "" {
+		parts = append(parts, "\n\nThe full object must match this schema:\n", schema)
+	}
+	parts = append(parts, "\n\nReturn a JSON object containing ONLY the fields that are missing from the partial object, ",
+		"including any field that was cut off. For an array that was cut off, return only the remaining items; ",
+		"they are appended to the existing ones. Nested objects are merged field by field.")
+	parts = append(parts, "\n\nCRITICAL: Return ONLY the JSON object. No text, no explanations, no markdown. Just the JSON.")
+	return strings.Join(parts, "")
+}
+
+// isTruncatedStopReason reports whether the provider stopped because of the output token limit
+func isTruncatedStopReason(stopReason string) bool {
+	switch strings.ToLower(stopReason) {
+	case "length", "max_tokens", "max_output_tokens":
+		return true
+	}
+	return false
+}
+
+// repairTruncatedJSONObject parses a JSON object that may be cut off mid-value, keeping every
+// complete member and element and dropping the incomplete one
+func repairTruncatedJSONObject(content string) (map[string]interface{}, error) {
+	content = strings.TrimSpace(content)
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &object); err == nil {
+		return object, nil
+	}
+	if !strings.HasPrefix(content, "{") {
+		return nil, fmt.Errorf("partial output is not a JSON object")
+	}
+
+	// Cut at the last point where every open value is complete, then close the open containers.
+	// Cut points are also kept per nesting depth so an array element cut off mid-way can be dropped
+	// whole; the continuation then appends it complete instead of duplicating half of it.
+	type cutPoint struct {
+		index int
+		open  string
+	}
+	var last *cutPoint
+	cutsAtDepth := make(map[int]*cutPoint)
+	var open []byte
+	record := func(index int) {
+		last = &cutPoint{index: index, open: string(open)}
+		cutsAtDepth[len(open)] = last
+	}
+	inString, escaped := false, false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			open = append(open, c)
+			record(i + 1)
+		case '}', ']':
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+			if len(open) > 0 {
+				record(i + 1)
+			}
+		case ',':
+			if len(open) > 0 {
+				record(i)
+			}
+		}
+	}
+	cut := last
+	for depth := 0; depth < len(open)-1; depth++ {
+		if open[depth] == '[' {
+			cut = cutsAtDepth[depth+1]
+			break
+		}
+	}
+	if cut == nil {
+		return nil, fmt.Errorf("partial output has no complete JSON members")
+	}
+
+	repaired := []byte(strings.TrimRight(content[:cut.index], " \t\r\n,"))
+	for i := len(cut.open) - 1; i >= 0; i-- {
+		if cut.open[i] == '{' {
+			repaired = append(repaired, '}')
+		} else {
+			repaired = append(repaired, ']')
+		}
+	}
+	if err := json.Unmarshal(repaired, &object); err != nil {
+		return nil, fmt.Errorf("failed to repair truncated JSON: %w", err)
+	}
+	return object, nil
+}
+
+// mergeStructuredObjects stitches a continuation into the partial object: nested objects merge,
+// arrays append and other values fill in or replace the cut-off field
+func mergeStructuredObjects(dst, src map[string]interface{}) {
+	for key, value := range src {
+		switch existing := dst[key].(type) {
+		case map[string]interface{}:
+			if nested, ok := value.(map[string]interface{}); ok {
+				mergeStructuredObjects(existing, nested)
+				continue
+			}
+		case []interface{}:
+			if items, ok := value.([]interface{}); ok {
+				dst[key] = append(existing, items...)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
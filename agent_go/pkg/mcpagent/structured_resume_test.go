@@ -0,0 +1,86 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+type auditSection struct {
+	Heading  string   `json:"heading"`
+	Body     string   `json:"body"`
+	Findings []string `json:"findings"`
+}
+
+type auditReport struct {
+	Title    string         `json:"title"`
+	Summary  string         `json:"summary"`
+	Sections []auditSection `json:"sections"`
+	Metadata struct {
+		Author string   `json:"author"`
+		Tags   []string `json:"tags"`
+	} `json:"metadata"`
+	Recommendation string `json:"recommendation"`
+}
+
+const auditReportSchema = `{"title": "string", "summary": "string", "sections": [{"heading": "string", "body": "string", "findings": ["string"]}], "metadata": {"author": "string", "tags": ["string"]}, "recommendation": "string"}`
+
+func TestAskStructuredResumesTruncatedObject(t *testing.T) {
+	llm := scriptedLLM([]*llmtypes.ContentChoice{
+		{Content: "The audit covered IAM and storage."},
+		// First structured pass hits the output limit inside the second section
+		{StopReason: "length", Content: `{"title": "Cloud audit", "summary": "Two areas reviewed", "metadata": {"author": "ops", "tags": ["iam"]}, "sections": [{"heading": "IAM", "body": "Roles reviewed", "findings": ["stale keys"]}, {"heading": "Storage", "body": "Buckets rev`},
+		{Content: `{"sections": [{"heading": "Storage", "body": "Buckets reviewed", "findings": ["public bucket", "no versioning"]}], "metadata": {"tags": ["storage"]}, "recommendation": "Rotate keys and lock down buckets"}`},
+	})
+
+	a := newTestAgent(t, llm, WithStructuredOutputResume(2))
+
+	report, err := AskStructured(a, context.Background(), "audit the account", auditReport{}, auditReportSchema)
+	if err != nil {
+		t.Fatalf("expected resume to complete the object, got %v", err)
+	}
+	if llm.calls != 3 {
+		t.Fatalf("expected answer, structured pass and one resume, got %d calls", llm.calls)
+	}
+	if !strings.Contains(llm.promptText(2), `"title":"Cloud audit"`) || !strings.Contains(llm.promptText(2), auditReportSchema) {
+		t.Fatalf("expected resume prompt to carry the partial object and schema, got %q", llm.promptText(2))
+	}
+
+	if report.Title != "Cloud audit" || report.Summary != "Two areas reviewed" || report.Recommendation != "Rotate keys and lock down buckets" {
+		t.Fatalf("unexpected top-level fields: %+v", report)
+	}
+	if len(report.Sections) != 2 || report.Sections[0].Heading != "IAM" || report.Sections[1].Body != "Buckets reviewed" || len(report.Sections[1].Findings) != 2 {
+		t.Fatalf("expected the cut-off section replaced by its complete version, got %+v", report.Sections)
+	}
+	if report.Metadata.Author != "ops" || strings.Join(report.Metadata.Tags, ",") != "iam,storage" {
+		t.Fatalf("expected nested metadata merged, got %+v", report.Metadata)
+	}
+}
+
+func TestResumeStructuredFromTruncationError(t *testing.T) {
+	llm := scriptedLLM([]*llmtypes.ContentChoice{
+		{Content: "The audit covered IAM."},
+		{StopReason: "length", Content: `{"title": "Cloud audit", "summary": "IAM only", "sections": [`},
+		{StopReason: "length", Content: `{"sections": [{"heading": "IAM", "body": "Roles reviewed", "findings": []}], "metadata": {"auth`},
+		// Later call through ResumeStructured
+		{Content: `{"metadata": {"author": "ops", "tags": []}, "recommendation": "Rotate keys"}`},
+	})
+	a := newTestAgent(t, llm, WithStructuredOutputResume(1))
+
+	_, err := AskStructured(a, context.Background(), "audit the account", auditReport{}, auditReportSchema)
+	var truncated *StructuredOutputTruncatedError
+	if !errors.As(err, &truncated) {
+		t.Fatalf("expected truncation error once resumes are exhausted, got %v", err)
+	}
+
+	report, err := ResumeStructured(a, context.Background(), truncated.Partial, auditReport{}, auditReportSchema)
+	if err != nil {
+		t.Fatalf("expected ResumeStructured to complete the object, got %v", err)
+	}
+	if report.Title != "Cloud audit" || len(report.Sections) != 1 || report.Metadata.Author != "ops" || report.Recommendation != "Rotate keys" {
+		t.Fatalf("unexpected resumed report: %+v", report)
+	}
+}
@@ -0,0 +1,237 @@
+package mcpagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// maxStructuredSchemaDepth bounds schema validation of deeply nested output
+const maxStructuredSchemaDepth = 32
+
+// StructuredFieldError is one reason structured output was rejected. Field is the path of the
+// offending value ("sections[0].heading"); it is empty for errors about the output as a whole.
+type StructuredFieldError struct {
+	Field   string
+	Message string
+}
+
+func (e StructuredFieldError) String() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("field %q %s", e.Field, e.Message)
+}
+
+// StructuredOutputValidationError is returned when structured output still fails validation (JSON
+// syntax, the target type or the JSON schema) after the last attempt
+type StructuredOutputValidationError struct {
+	Attempts int
+	Errors   []StructuredFieldError // Errors of the last attempt
+}
+
+func (e *StructuredOutputValidationError) Error() string {
+	return fmt.Sprintf("structured output failed validation after %d attempts: %s", e.Attempts, strings.Join(structuredErrorStrings(e.Errors), "; "))
+}
+
+// Fields returns the paths of the fields that failed validation
+func (e *StructuredOutputValidationError) Fields() []string {
+	var fields []string
+	for _, fieldErr := range e.Errors {
+		if fieldErr.Field != "" {
+			fields = append(fields, fieldErr.Field)
+		}
+	}
+	return fields
+}
+
+func structuredErrorStrings(fieldErrors []StructuredFieldError) []string {
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fieldErr := range fieldErrors {
+		messages = append(messages, fieldErr.String())
+	}
+	return messages
+}
+
+// structuredSchemaNode is the subset of JSON Schema checked against structured output
+type structuredSchemaNode struct {
+	Type                 json.RawMessage                  `json:"type"`
+	Properties           map[string]*structuredSchemaNode `json:"properties"`
+	AdditionalProperties json.RawMessage                  `json:"additionalProperties"`
+	Required             []string                         `json:"required"`
+	Items                *structuredSchemaNode            `json:"items"`
+	Enum                 []interface{}                    `json:"enum"`
+	MinItems             *int                             `json:"minItems"`
+	MaxItems             *int                             `json:"maxItems"`
+	MinLength            *int                             `json:"minLength"`
+	Minimum              *float64                         `json:"minimum"`
+	Maximum              *float64                         `json:"maximum"`
+}
+
+// UnmarshalJSON accepts boolean schemas, which are not checked
+func (n *structuredSchemaNode) UnmarshalJSON(data []byte) error {
+	if trimmed := strings.TrimSpace(string(data)); trimmed == "true" || trimmed == "false" {
+		*n = structuredSchemaNode{}
+		return nil
+	}
+	type plainSchemaNode structuredSchemaNode
+	return json.Unmarshal(data, (*plainSchemaNode)(n))
+}
+
+// parseStructuredSchema returns the JSON Schema in schemaString, or nil when it is not one. Many
+// callers pass an example object instead (`{"city": "string"}`), which only the target type validates;
+// a schema is recognized by "$schema", an object "properties" or an object "items" at the root.
+func parseStructuredSchema(schemaString string) *structuredSchemaNode {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(schemaString), &root); err != nil {
+		return nil
+	}
+	isSchema := false
+	if _, ok := root["$schema"]; ok {
+		isSchema = true
+	}
+	for _, key := range []string{"properties", "items"} {
+		if raw, ok := root[key]; ok && strings.HasPrefix(strings.TrimSpace(string(raw)), "{") {
+			isSchema = true
+		}
+	}
+	if !isSchema {
+		return nil
+	}
+	var node structuredSchemaNode
+	if err := json.Unmarshal([]byte(schemaString), &node); err != nil {
+		return nil
+	}
+	return &node
+}
+
+// validateAgainstSchema checks the decoded output against the schema and returns every violation
+func validateAgainstSchema(value interface{}, schema *structuredSchemaNode) []StructuredFieldError {
+	var fieldErrors []StructuredFieldError
+	validateSchemaNode("", value, schema, 0, func(path, message string) {
+		fieldErrors = append(fieldErrors, StructuredFieldError{Field: path, Message: message})
+	})
+	return fieldErrors
+}
+
+func validateSchemaNode(path string, value interface{}, node *structuredSchemaNode, depth int, report func(path, message string)) {
+	if node == nil || depth > maxStructuredSchemaDepth {
+		return
+	}
+	if expected := structuredSchemaTypes(node.Type); len(expected) > 0 && !matchesStructuredSchemaType(value, expected) {
+		report(path, fmt.Sprintf("must be %s, got %s", strings.Join(expected, " or "), structuredValueType(value)))
+		return
+	}
+	if len(node.Enum) > 0 && !enumContains(node.Enum, value) {
+		allowed, _ := json.Marshal(node.Enum)
+		report(path, fmt.Sprintf("must be one of %s", allowed))
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for _, required := range node.Required {
+			if _, present := typed[required]; !present {
+				report(joinSchemaPath(path, required), "is required")
+			}
+		}
+		closed := strings.TrimSpace(string(node.AdditionalProperties)) == "false"
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, known := node.Properties[key]
+			if !known {
+				if closed {
+					report(joinSchemaPath(path, key), "is not allowed by the schema")
+				}
+				continue
+			}
+			validateSchemaNode(joinSchemaPath(path, key), typed[key], property, depth+1, report)
+		}
+	case []interface{}:
+		if node.MinItems != nil && len(typed) < *node.MinItems {
+			report(path, fmt.Sprintf("must have at least %d items, got %d", *node.MinItems, len(typed)))
+		}
+		if node.MaxItems != nil && len(typed) > *node.MaxItems {
+			report(path, fmt.Sprintf("must have at most %d items, got %d", *node.MaxItems, len(typed)))
+		}
+		for i, item := range typed {
+			validateSchemaNode(fmt.Sprintf("%s[%d]", path, i), item, node.Items, depth+1, report)
+		}
+	case string:
+		if node.MinLength != nil && len([]rune(typed)) < *node.MinLength {
+			report(path, fmt.Sprintf("must be at least %d characters", *node.MinLength))
+		}
+	case float64:
+		if node.Minimum != nil && typed < *node.Minimum {
+			report(path, fmt.Sprintf("must be >= %v, got %v", *node.Minimum, typed))
+		}
+		if node.Maximum != nil && typed > *node.Maximum {
+			report(path, fmt.Sprintf("must be <= %v, got %v", *node.Maximum, typed))
+		}
+	}
+}
+
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func structuredSchemaTypes(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}
+	}
+	var multiple []string
+	json.Unmarshal(raw, &multiple)
+	return multiple
+}
+
+func matchesStructuredSchemaType(value interface{}, expected []string) bool {
+	actual := structuredValueType(value)
+	for _, schemaType := range expected {
+		if schemaType == actual || (schemaType == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func structuredValueType(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if typed == math.Trunc(typed) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) && structuredValueType(allowed) == structuredValueType(value) {
+			return true
+		}
+	}
+	return false
+}
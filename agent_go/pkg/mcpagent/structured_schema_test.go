@@ -0,0 +1,88 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+type incidentReport struct {
+	Service  string   `json:"service"`
+	Severity string   `json:"severity"`
+	Owners   []string `json:"owners"`
+}
+
+const incidentReportSchema = `{
+  "type": "object",
+  "properties": {
+    "service": {"type": "string"},
+    "severity": {"type": "string", "enum": ["low", "high"]},
+    "owners": {"type": "array", "items": {"type": "string"}, "minItems": 1}
+  },
+  "required": ["service", "severity", "owners"]
+}`
+
+func TestStructuredOutputReasksWhenSchemaRequiredFieldMissing(t *testing.T) {
+	llm := scriptedLLM([]*llmtypes.ContentChoice{
+		{Content: "The checkout service is down, the payments team owns it."},
+		// Decodes into the Go struct fine, but the schema requires severity
+		{Content: `{"service": "checkout", "owners": ["payments"]}`},
+		{Content: `{"service": "checkout", "severity": "high", "owners": ["payments"]}`},
+	})
+	a := newTestAgent(t, llm)
+
+	report, err := AskStructured(a, context.Background(), "summarize the incident", incidentReport{}, incidentReportSchema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Severity != "high" {
+		t.Fatalf("expected the corrected report, got %+v", report)
+	}
+	if llm.calls != 3 || !strings.Contains(llm.promptText(2), `field "severity" is required`) {
+		t.Fatalf("expected the re-ask to name the missing field, got %q", llm.promptText(llm.calls-1))
+	}
+}
+
+func TestStructuredOutputSchemaValidationErrorListsFields(t *testing.T) {
+	omitted := `{"service": "checkout", "severity": "urgent", "owners": []}`
+	llm := scriptedLLM([]*llmtypes.ContentChoice{
+		{Content: "The checkout service is down."},
+		{Content: omitted},
+		{Content: omitted},
+	})
+	a := newTestAgent(t, llm, WithStructuredOutputMaxAttempts(2))
+
+	_, err := AskStructured(a, context.Background(), "summarize the incident", incidentReport{}, incidentReportSchema)
+	var validationErr *StructuredOutputValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a StructuredOutputValidationError, got %v", err)
+	}
+	if validationErr.Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", validationErr.Attempts)
+	}
+	if fields := validationErr.Fields(); !reflect.DeepEqual(fields, []string{"owners", "severity"}) {
+		t.Errorf("expected owners and severity to fail, got %v (%v)", fields, err)
+	}
+	if !strings.Contains(err.Error(), `field "severity" must be one of ["low","high"]`) {
+		t.Errorf("expected the enum violation in the error, got %v", err)
+	}
+}
+
+func TestParseStructuredSchemaIgnoresExampleObjects(t *testing.T) {
+	if parseStructuredSchema(`{"city": "string", "type": "string"}`) != nil {
+		t.Error("expected an example object not to be treated as a JSON schema")
+	}
+	if parseStructuredSchema(incidentReportSchema) == nil {
+		t.Error("expected a JSON schema with properties to be recognized")
+	}
+
+	schema := parseStructuredSchema(`{"type": "array", "items": {"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}}`)
+	fieldErrors := validateAgainstSchema([]interface{}{map[string]interface{}{"id": 1.5}, map[string]interface{}{}}, schema)
+	if got := structuredErrorStrings(fieldErrors); !reflect.DeepEqual(got, []string{`field "[0].id" must be integer, got number`, `field "[1].id" is required`}) {
+		t.Errorf("unexpected array validation errors: %v", got)
+	}
+}
@@ -0,0 +1,65 @@
+package mcpagent
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// systemPromptPlaceholder matches {{NAME}} placeholders in a system prompt template
+var systemPromptPlaceholder = regexp.MustCompile(`\{\{([A-Za-z0-9_]+)\}\}`)
+
+// SystemPromptTemplate renders a system prompt from a template with {{NAME}} placeholders.
+// Placeholders are filled from the data passed to Render (the agent's {{TOOLS}},
+// {{PROMPTS_SECTION}}, ...) and from registered named sections such as {{COMPANY_POLICY}}.
+type SystemPromptTemplate struct {
+	mu       sync.RWMutex
+	template string
+	sections map[string]func() string
+}
+
+// NewSystemPromptTemplate creates a template with no registered sections
+func NewSystemPromptTemplate(template string) *SystemPromptTemplate {
+	return &SystemPromptTemplate{
+		template: template,
+		sections: make(map[string]func() string),
+	}
+}
+
+// RegisterSection registers a named section rendered into {{name}}. The function is called on
+// every Render that references the section, so it can return content that changes over time.
+func (t *SystemPromptTemplate) RegisterSection(name string, section func() string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sections[name] = section
+}
+
+// Sections returns the names of the registered sections, sorted
+func (t *SystemPromptTemplate) Sections() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	names := make([]string, 0, len(t.sections))
+	for name := range t.sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render fills the template's placeholders. Values in data take precedence over registered
+// sections; placeholders found in neither are left as they are. Rendered content is not
+// scanned again, so a section returning "{{TOOLS}}" is inserted literally.
+func (t *SystemPromptTemplate) Render(data map[string]string) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return systemPromptPlaceholder.ReplaceAllStringFunc(t.template, func(placeholder string) string {
+		name := placeholder[2 : len(placeholder)-2]
+		if value, ok := data[name]; ok {
+			return value
+		}
+		if section, ok := t.sections[name]; ok && section != nil {
+			return section()
+		}
+		return placeholder
+	})
+}
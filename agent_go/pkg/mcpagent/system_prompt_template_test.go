@@ -0,0 +1,21 @@
+package mcpagent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSystemPromptTemplateRender(t *testing.T) {
+	tmpl := NewSystemPromptTemplate("{{COMPANY_POLICY}}\nTools:\n{{TOOLS}}\n{{UNKNOWN}}")
+	tmpl.RegisterSection("COMPANY_POLICY", func() string { return "Be polite. {{TOOLS}}" })
+	tmpl.RegisterSection("TOOLS", func() string { return "overridden by data" })
+
+	got := tmpl.Render(map[string]string{"TOOLS": "- search: Search the web"})
+	want := "Be polite. {{TOOLS}}\nTools:\n- search: Search the web\n{{UNKNOWN}}"
+	if got != want {
+		t.Fatalf("Render() = %q, want %q", got, want)
+	}
+	if sections := tmpl.Sections(); !reflect.DeepEqual(sections, []string{"COMPANY_POLICY", "TOOLS"}) {
+		t.Fatalf("Sections() = %v", sections)
+	}
+}
@@ -0,0 +1,26 @@
+package mcpagent
+
+import (
+	"context"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+type tokenStreamKey struct{}
+
+// WithTokenStream returns a context whose conversation turns stream generated text to fn as the
+// LLM produces it. Providers without streaming support only return the full response.
+func WithTokenStream(ctx context.Context, fn func(chunk string)) context.Context {
+	if fn == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tokenStreamKey{}, fn)
+}
+
+// withTokenStreamOption adds the streaming callback of ctx, if any, to a conversation turn's options
+func withTokenStreamOption(ctx context.Context, opts []llmtypes.CallOption) []llmtypes.CallOption {
+	if fn, ok := ctx.Value(tokenStreamKey{}).(func(string)); ok {
+		return append(opts, llmtypes.WithStreamingFunc(fn))
+	}
+	return opts
+}
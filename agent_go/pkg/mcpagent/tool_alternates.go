@@ -0,0 +1,156 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/mcpclient"
+)
+
+// defaultToolAlternateThreshold is how many consecutive failures of a tool trigger its alternate
+const defaultToolAlternateThreshold = 2
+
+// toolFailureTracker counts consecutive failures per tool
+type toolFailureTracker struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// WithToolAlternate registers alternateTool as a stand-in for toolName: once toolName has failed
+// the threshold number of times in a row (see WithToolAlternateThreshold), each further failure
+// calls alternateTool with the same arguments and feeds its result back to the LLM
+func WithToolAlternate(toolName, alternateTool string) AgentOption {
+	return func(a *Agent) {
+		if a.toolAlternates == nil {
+			a.toolAlternates = make(map[string]string)
+		}
+		a.toolAlternates[toolName] = alternateTool
+	}
+}
+
+// WithToolAlternateThreshold sets how many consecutive failures of a tool trigger its alternate (default 2)
+func WithToolAlternateThreshold(failures int) AgentOption {
+	return func(a *Agent) {
+		a.toolAlternateThreshold = failures
+	}
+}
+
+// recordToolSuccess resets the consecutive failure count of a tool
+func (a *Agent) recordToolSuccess(toolName string) {
+	if len(a.toolAlternates) == 0 {
+		return
+	}
+	a.toolFailures.mu.Lock()
+	delete(a.toolFailures.counts, toolName)
+	a.toolFailures.mu.Unlock()
+}
+
+// runToolAlternate records a failure of toolName and, once it reaches the threshold, calls the
+// registered alternate. Returns the text to feed back to the LLM ("" when no alternate ran), the
+// alternate's name and whether it succeeded.
+func (a *Agent) runToolAlternate(ctx context.Context, turn int, toolName string, args map[string]interface{}, failure string) (string, string, bool) {
+	alternate, exists := a.toolAlternates[toolName]
+	if !exists {
+		return "", "", false
+	}
+
+	a.toolFailures.mu.Lock()
+	if a.toolFailures.counts == nil {
+		a.toolFailures.counts = make(map[string]int)
+	}
+	a.toolFailures.counts[toolName]++
+	failures := a.toolFailures.counts[toolName]
+	a.toolFailures.mu.Unlock()
+
+	threshold := a.toolAlternateThreshold
+	if threshold <= 0 {
+		threshold = defaultToolAlternateThreshold
+	}
+	if failures < threshold {
+		return "", "", false
+	}
+
+	logger := getLogger(a)
+	logger.Infof("🔀 Tool %s failed %d times in a row, calling alternate tool %s", toolName, failures, alternate)
+
+	altCtx, cancel := context.WithTimeout(ctx, getToolExecutionTimeout(a))
+	defer cancel()
+	result, err := a.callToolByName(altCtx, alternate, args)
+
+	event := events.NewToolAlternateUsedEvent(turn, toolName, alternate, failures, failure)
+	event.Succeeded = err == nil
+	if err != nil {
+		event.AlternateError = err.Error()
+	}
+	a.EmitTypedEvent(ctx, event)
+
+	note := fmt.Sprintf("Tool execution failed - %s\n\nTool %s has failed %d times in a row, so the alternate tool %s was called with the same arguments", failure, toolName, failures, alternate)
+	if err != nil {
+		logger.Warnf("Alternate tool %s for %s failed: %v", alternate, toolName, err)
+		return fmt.Sprintf("%s, but it also failed: %v", note, err), alternate, false
+	}
+	return fmt.Sprintf("%s. Its result:\n%s", note, result), alternate, true
+}
+
+// callToolByName executes a custom, virtual or MCP tool outside the LLM tool loop.
+// A tool result flagged as an error is returned as an error.
+func (a *Agent) callToolByName(ctx context.Context, toolName string, args map[string]interface{}) (string, error) {
+	if reason := a.checkToolPolicy(toolName, a.toolToServer[toolName]); reason != "" {
+		return "", fmt.Errorf("%s: %s", events.ToolBlockedByPolicyReason, reason)
+	}
+	intercepting := len(a.toolInterceptors) > 0
+	if intercepting {
+		intercepted, err := a.interceptToolArgs(ctx, toolName, args)
+		if err != nil {
+			return "", err
+		}
+		args = intercepted
+	}
+	// Confirm the arguments the tool will actually run with
+	if declined := a.confirmToolCall(ctx, 0, toolName, a.toolToServer[toolName], toolName, args); declined != "" {
+		return "", fmt.Errorf("%s: %s", events.ToolDeclinedByUserReason, declined)
+	}
+	resultText, err := a.executeToolByName(ctx, toolName, args)
+	if intercepting {
+		return a.interceptToolResult(ctx, toolName, resultText, err)
+	}
+	return resultText, err
+}
+
+// executeToolByName runs the tool without policy, confirmation or interceptors
+func (a *Agent) executeToolByName(ctx context.Context, toolName string, args map[string]interface{}) (string, error) {
+	if customTool, exists := a.customTools[toolName]; exists {
+		return customTool.Execution(ctx, args)
+	}
+	if isVirtualTool(toolName) {
+		return a.HandleVirtualTool(ctx, toolName, args)
+	}
+
+	serverName := a.toolToServer[toolName]
+	client := a.Client
+	if c, exists := a.Clients[serverName]; exists {
+		client = c
+	}
+	if client == nil {
+		if serverName == "" {
+			return "", fmt.Errorf("tool %s is not available", toolName)
+		}
+		onDemandClient, err := a.createOnDemandConnection(ctx, serverName)
+		if err != nil {
+			return "", fmt.Errorf("failed to connect to server %s: %w", serverName, err)
+		}
+		client = onDemandClient
+	}
+
+	result, err := client.CallTool(ctx, toolName, args)
+	if err != nil {
+		return "", err
+	}
+	resultText := mcpclient.ToolResultAsString(result, getLogger(a))
+	if result.IsError {
+		return "", fmt.Errorf("%s", resultText)
+	}
+	return resultText, nil
+}
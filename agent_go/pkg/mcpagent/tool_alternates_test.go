@@ -0,0 +1,50 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+func TestToolAlternateUsedAfterFailureThreshold(t *testing.T) {
+	llm := sequentialToolLLM("done",
+		llmtypes.FunctionCall{Name: "query_metrics", Arguments: `{"metric": "cpu"}`},
+		llmtypes.FunctionCall{Name: "query_metrics", Arguments: `{"metric": "cpu"}`},
+	)
+	a := newTestAgent(t, llm, WithToolAlternate("query_metrics", "query_metrics_legacy"), WithToolAlternateThreshold(2))
+
+	var alternateArgs []map[string]interface{}
+	a.RegisterCustomTool("query_metrics", "Query metrics", map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return "", fmt.Errorf("metrics backend unavailable")
+	})
+	a.RegisterCustomTool("query_metrics_legacy", "Query metrics (legacy API)", map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		alternateArgs = append(alternateArgs, args)
+		return "cpu: 42%", nil
+	})
+	alternates := collectEvents[*events.ToolAlternateUsedEvent](a)
+
+	if _, err := a.Ask(context.Background(), "what is the cpu usage?"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The first failure is reported as is; the second reaches the threshold and uses the alternate
+	if len(alternateArgs) != 1 || alternateArgs[0]["metric"] != "cpu" {
+		t.Fatalf("expected the alternate called once with the original arguments, got %v", alternateArgs)
+	}
+	if result := llm.lastToolResult(); !strings.Contains(result, "alternate tool query_metrics_legacy") || !strings.Contains(result, "cpu: 42%") {
+		t.Fatalf("expected the alternate's result fed back to the LLM, got %q", result)
+	}
+
+	used := alternates.all()
+	if len(used) != 1 {
+		t.Fatalf("expected one alternate event, got %d", len(used))
+	}
+	event := used[0]
+	if event.ToolName != "query_metrics" || event.AlternateTool != "query_metrics_legacy" || event.Failures != 2 || !event.Succeeded || !strings.Contains(event.Error, "metrics backend unavailable") {
+		t.Fatalf("unexpected alternate event: %+v", event)
+	}
+}
@@ -0,0 +1,165 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// DefaultToolArgLanguage is the canonical language tool arguments are normalized to
+const DefaultToolArgLanguage = "English"
+
+// ToolArgTranslator translates tool argument strings into language, returning them in the same order
+type ToolArgTranslator interface {
+	Translate(ctx context.Context, texts []string, language string) ([]string, error)
+}
+
+// ToolArgTranslatorFunc adapts a function to ToolArgTranslator
+type ToolArgTranslatorFunc func(ctx context.Context, texts []string, language string) ([]string, error)
+
+func (f ToolArgTranslatorFunc) Translate(ctx context.Context, texts []string, language string) ([]string, error) {
+	return f(ctx, texts, language)
+}
+
+// WithToolArgTranslation normalizes string tool arguments written in another script (any non-ASCII
+// letters) to language ("" uses DefaultToolArgLanguage) before the tool executes. Only the arguments
+// passed to the executor change: the conversation and the final answer keep the user's language.
+// translator nil translates with the agent's LLM.
+func WithToolArgTranslation(language string, translator ToolArgTranslator) AgentOption {
+	return func(a *Agent) {
+		if language == "" {
+			language = DefaultToolArgLanguage
+		}
+		a.toolArgLanguage = language
+		a.toolArgTranslator = translator
+	}
+}
+
+// translateToolArgs returns args with non-canonical strings translated. On failure the original
+// args are returned so the tool still runs.
+func (a *Agent) translateToolArgs(ctx context.Context, toolName string, args map[string]interface{}) map[string]interface{} {
+	if a.toolArgLanguage == "" {
+		return args
+	}
+
+	var texts []string
+	collectTranslatableStrings(args, &texts)
+	if len(texts) == 0 {
+		return args
+	}
+
+	translator := a.toolArgTranslator
+	if translator == nil {
+		translator = ToolArgTranslatorFunc(a.translateWithLLM)
+	}
+	translated, err := translator.Translate(ctx, texts, a.toolArgLanguage)
+	if err == nil && len(translated) != len(texts) {
+		err = fmt.Errorf("expected %d translations, got %d", len(texts), len(translated))
+	}
+	if err != nil {
+		getLogger(a).Warnf("🌐 Failed to translate arguments of tool %s to %s, using them as given: %v", toolName, a.toolArgLanguage, err)
+		return args
+	}
+
+	getLogger(a).Infof("🌐 Translated %d argument value(s) of tool %s to %s", len(texts), toolName, a.toolArgLanguage)
+	next := 0
+	return replaceTranslatableStrings(args, translated, &next).(map[string]interface{})
+}
+
+// translateWithLLM asks the agent's LLM for the translations as a JSON array
+func (a *Agent) translateWithLLM(ctx context.Context, texts []string, language string) ([]string, error) {
+	input, err := json.Marshal(texts)
+	if err != nil {
+		return nil, err
+	}
+	prompt := fmt.Sprintf("Translate each string in this JSON array to %s. Keep names, identifiers, numbers and formatting unchanged. "+
+		"Respond with only a JSON array of the translated strings, in the same order.\n\n%s", language, input)
+	resp, err := a.LLM.GenerateContent(ctx, []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, prompt)}, llmtypes.WithTemperature(0))
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("empty translation response")
+	}
+
+	content := strings.TrimSpace(resp.Choices[0].Content)
+	if start, end := strings.Index(content, "["), strings.LastIndex(content, "]"); start >= 0 && end > start {
+		content = content[start : end+1]
+	}
+	var translated []string
+	if err := json.Unmarshal([]byte(content), &translated); err != nil {
+		return nil, fmt.Errorf("invalid translation response: %w", err)
+	}
+	return translated, nil
+}
+
+// needsTranslation reports whether s contains letters outside ASCII
+func needsTranslation(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII && unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectTranslatableStrings appends the strings in value that need translation, in traversal order
+func collectTranslatableStrings(value interface{}, texts *[]string) {
+	switch v := value.(type) {
+	case string:
+		if needsTranslation(v) {
+			*texts = append(*texts, v)
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			collectTranslatableStrings(v[key], texts)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectTranslatableStrings(item, texts)
+		}
+	}
+}
+
+// replaceTranslatableStrings copies value, replacing the strings collectTranslatableStrings found
+// with translated in the same traversal order
+func replaceTranslatableStrings(value interface{}, translated []string, next *int) interface{} {
+	switch v := value.(type) {
+	case string:
+		if needsTranslation(v) {
+			replacement := translated[*next]
+			*next++
+			return replacement
+		}
+		return v
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for _, key := range sortedKeys(v) {
+			copied[key] = replaceTranslatableStrings(v[key], translated, next)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = replaceTranslatableStrings(item, translated, next)
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+// sortedKeys returns the keys of m in a stable order so collection and replacement line up
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// dictionaryTranslator translates from a fixed dictionary and records what it was asked
+type dictionaryTranslator struct {
+	words     map[string]string
+	requested []string
+	language  string
+}
+
+func (d *dictionaryTranslator) Translate(ctx context.Context, texts []string, language string) ([]string, error) {
+	d.requested = append(d.requested, texts...)
+	d.language = language
+	translated := make([]string, len(texts))
+	for i, text := range texts {
+		translated[i] = d.words[text]
+	}
+	return translated, nil
+}
+
+func runTranslatedToolCall(t *testing.T, options ...AgentOption) (map[string]interface{}, *fakeLLM) {
+	t.Helper()
+	llm := sequentialToolLLM("done",
+		llmtypes.FunctionCall{Name: "search_products", Arguments: `{"query": "赤いランニングシューズ", "filters": {"color": "rouge", "size": "42"}, "tags": ["été", "sale"]}`},
+	)
+	a := newTestAgent(t, llm, options...)
+
+	var received map[string]interface{}
+	a.RegisterCustomTool("search_products", "Search the product catalog", map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		received = args
+		return "3 products found", nil
+	})
+	if _, err := a.Ask(context.Background(), "trouve des chaussures de course rouges"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return received, llm
+}
+
+func TestToolArgsTranslatedBeforeExecution(t *testing.T) {
+	translator := &dictionaryTranslator{words: map[string]string{
+		"赤いランニングシューズ": "red running shoes",
+		"été":         "summer",
+	}}
+	received, llm := runTranslatedToolCall(t, WithToolArgTranslation("", translator))
+
+	// Only strings with non-ASCII letters are sent for translation
+	if len(translator.requested) != 2 || translator.language != DefaultToolArgLanguage {
+		t.Fatalf("expected the two non-English arguments translated to English, got %v (%s)", translator.requested, translator.language)
+	}
+	if received["query"] != "red running shoes" {
+		t.Fatalf("expected the executor to receive the translated query, got %v", received["query"])
+	}
+	tags, _ := received["tags"].([]interface{})
+	if len(tags) != 2 || tags[0] != "summer" || tags[1] != "sale" {
+		t.Fatalf("expected the executor to receive translated tags, got %v", received["tags"])
+	}
+	if filters := received["filters"].(map[string]interface{}); filters["color"] != "rouge" || filters["size"] != "42" {
+		t.Fatalf("expected other arguments unchanged, got %v", received)
+	}
+	if result := llm.lastToolResult(); result != "3 products found" {
+		t.Fatalf("expected the tool result fed back unchanged, got %q", result)
+	}
+}
+
+func TestToolArgTranslationDisabledByDefault(t *testing.T) {
+	received, _ := runTranslatedToolCall(t)
+
+	tags, _ := received["tags"].([]interface{})
+	if len(tags) != 2 || tags[0] != "été" {
+		t.Fatalf("expected arguments passed as given without the option, got %v", received["tags"])
+	}
+}
@@ -0,0 +1,146 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	virtualtools "mcp-agent/agent_go/cmd/server/virtual-tools"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// defaultToolConfirmationTimeout is how long a gated tool call waits for approval before it is declined
+const defaultToolConfirmationTimeout = 10 * time.Minute
+
+// ToolConfirmationStore registers a confirmation request and blocks until it is answered.
+// The global HumanFeedbackStore implements it.
+type ToolConfirmationStore interface {
+	CreateRequest(uniqueID, message string) error
+	WaitForResponse(uniqueID string, timeout time.Duration) (string, error)
+}
+
+// WithConfirmTools requires human approval before calling tools matching any of the glob patterns
+// (tool name or "server:tool", see ToolPolicy). The agent emits a RequestHumanFeedbackEvent and
+// blocks until the request is answered through the HumanFeedbackStore; any answer other than
+// "Approve" (or yes) declines the call, which is reported to the LLM as a tool error.
+func WithConfirmTools(patterns []string) AgentOption {
+	return func(a *Agent) {
+		a.confirmTools = append(a.confirmTools, patterns...)
+	}
+}
+
+// WithToolConfirmationTimeout sets how long a gated tool call waits for approval (default 10 minutes)
+func WithToolConfirmationTimeout(timeout time.Duration) AgentOption {
+	return func(a *Agent) {
+		a.confirmTimeout = timeout
+	}
+}
+
+// WithToolConfirmationStore routes confirmations through store instead of the global HumanFeedbackStore
+func WithToolConfirmationStore(store ToolConfirmationStore) AgentOption {
+	return func(a *Agent) {
+		a.confirmStore = store
+	}
+}
+
+// requiresConfirmation reports whether the tool is gated by WithConfirmTools
+func (a *Agent) requiresConfirmation(toolName, serverName string) bool {
+	if len(a.confirmTools) == 0 {
+		return false
+	}
+	names := []string{toolName}
+	if serverName != "" {
+		names = append(names, serverName+":"+toolName)
+	}
+	_, matched := matchToolPattern(a.confirmTools, names)
+	return matched
+}
+
+// confirmToolCall asks a human to approve a gated tool call and blocks until they answer. args are the
+// arguments the tool will run with, after translation and interceptors. It returns "" when the call may
+// run, else the tool error to report to the LLM.
+func (a *Agent) confirmToolCall(ctx context.Context, turn int, toolName, serverName, toolCallID string, args map[string]interface{}) string {
+	if !a.requiresConfirmation(toolName, serverName) {
+		return ""
+	}
+	logger := getLogger(a)
+	arguments, err := json.Marshal(args)
+	if err != nil {
+		return a.declineToolCall(ctx, turn, toolName, serverName, fmt.Sprintf("its arguments could not be shown for confirmation: %v", err))
+	}
+
+	store := a.confirmStore
+	if store == nil {
+		store = virtualtools.GetHumanFeedbackStore()
+	}
+	timeout := a.confirmTimeout
+	if timeout <= 0 {
+		timeout = defaultToolConfirmationTimeout
+	}
+
+	requestID := fmt.Sprintf("tool-confirm-%s-%s-%d", a.TraceID, toolCallID, time.Now().UnixNano())
+	question := fmt.Sprintf("The agent wants to run tool '%s' with arguments %s. Approve?", toolName, string(arguments))
+	if err := store.CreateRequest(requestID, question); err != nil {
+		logger.Errorf("Failed to create confirmation request for tool %s: %v", toolName, err)
+		return a.declineToolCall(ctx, turn, toolName, serverName, fmt.Sprintf("confirmation could not be requested: %v", err))
+	}
+
+	a.EmitTypedEvent(ctx, &events.RequestHumanFeedbackEvent{
+		BaseEventData:     events.BaseEventData{Timestamp: time.Now()},
+		SessionID:         string(a.TraceID),
+		RequestID:         requestID,
+		VerificationType:  "tool_confirmation",
+		Title:             fmt.Sprintf("Confirm tool call: %s", toolName),
+		ActionLabel:       "Approve",
+		ActionDescription: question,
+	})
+
+	logger.Infof("⏸️ Turn %d: waiting for approval of tool %s (request %s, timeout %s)", turn, toolName, requestID, timeout)
+	response, err := store.WaitForResponse(requestID, timeout)
+	if err != nil {
+		if errors.Is(err, virtualtools.ErrFeedbackTimeout) {
+			return a.declineToolCall(ctx, turn, toolName, serverName, fmt.Sprintf("nobody approved it within %s", timeout))
+		}
+		return a.declineToolCall(ctx, turn, toolName, serverName, fmt.Sprintf("confirmation failed: %v", err))
+	}
+
+	if isApproval(response) {
+		logger.Infof("▶️ Turn %d: tool %s approved", turn, toolName)
+		return ""
+	}
+	reason := "the user declined"
+	if strings.TrimSpace(response) != "" && !isRejection(response) {
+		reason = fmt.Sprintf("the user declined: %s", strings.TrimSpace(response))
+	}
+	return a.declineToolCall(ctx, turn, toolName, serverName, reason)
+}
+
+// declineToolCall emits the tool call error of a declined call and returns the text for the LLM
+func (a *Agent) declineToolCall(ctx context.Context, turn int, toolName, serverName, reason string) string {
+	getLogger(a).Warnf("⛔ Turn %d: tool %s not executed, %s", turn, toolName, reason)
+
+	errorEvent := events.NewToolCallErrorEvent(turn, toolName, reason, serverName, 0)
+	errorEvent.Reason = events.ToolDeclinedByUserReason
+	a.EmitTypedEvent(ctx, errorEvent)
+
+	return fmt.Sprintf("Tool execution failed - tool '%s' requires confirmation and was not executed because %s. Do not retry it unless the user asks you to.", toolName, reason)
+}
+
+func isApproval(response string) bool {
+	switch strings.ToLower(strings.TrimSpace(response)) {
+	case "approve", "approved", "yes", "y":
+		return true
+	}
+	return false
+}
+
+func isRejection(response string) bool {
+	switch strings.ToLower(strings.TrimSpace(response)) {
+	case "reject", "rejected", "deny", "denied", "decline", "declined", "no", "n":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package mcpagent
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	virtualtools "mcp-agent/agent_go/cmd/server/virtual-tools"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// answerConfirmations answers every confirmation request of store with response and records the questions
+func answerConfirmations(t *testing.T, store *virtualtools.InMemoryHumanFeedbackStore, response string) (*[]string, func()) {
+	t.Helper()
+	requests, cancel := store.Subscribe()
+	var questions []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for request := range requests {
+			questions = append(questions, request.MessageForUser)
+			if err := store.Submit(request.UniqueID, response); err != nil {
+				t.Errorf("submit: %v", err)
+			}
+		}
+	}()
+	return &questions, func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestConfirmToolsApprovedToolRuns(t *testing.T) {
+	store := virtualtools.NewInMemoryHumanFeedbackStore()
+	questions, stop := answerConfirmations(t, store, "Approve")
+
+	executed, answer, toolErrors := askWithPolicy(t, WithConfirmTools([]string{"delete_*"}), WithToolConfirmationStore(store))
+	stop()
+
+	if len(executed) != 2 {
+		t.Fatalf("expected both tools to run after approval, got %v", executed)
+	}
+	if !strings.Contains(answer, "call-a=delete_file ok") {
+		t.Fatalf("unexpected tool results %q", answer)
+	}
+	if len(*questions) != 1 || !strings.Contains((*questions)[0], "delete_file") || !strings.Contains((*questions)[0], "/tmp/report.txt") {
+		t.Fatalf("expected one confirmation request for delete_file, got %v", *questions)
+	}
+	errorEvents := toolErrors.all()
+	if len(errorEvents) != 0 {
+		t.Fatalf("expected no tool call errors, got %+v", errorEvents)
+	}
+}
+
+func TestConfirmToolsDeclinedToolNeverExecutes(t *testing.T) {
+	store := virtualtools.NewInMemoryHumanFeedbackStore()
+	_, stop := answerConfirmations(t, store, "Reject")
+
+	executed, answer, toolErrors := askWithPolicy(t, WithConfirmTools([]string{"delete_*"}), WithToolConfirmationStore(store))
+	stop()
+
+	if len(executed) != 1 || executed[0] != "read_file" {
+		t.Fatalf("expected only read_file to run, got %v", executed)
+	}
+	if !strings.Contains(answer, "call-a=Tool execution failed - tool 'delete_file' requires confirmation and was not executed because the user declined") {
+		t.Fatalf("unexpected tool results %q", answer)
+	}
+
+	errorEvents := toolErrors.all()
+	if len(errorEvents) != 1 || errorEvents[0].ToolName != "delete_file" || errorEvents[0].Reason != events.ToolDeclinedByUserReason {
+		t.Fatalf("expected delete_file declined by the user, got %+v", errorEvents)
+	}
+}
+
+func TestConfirmToolsUnansweredRequestTimesOut(t *testing.T) {
+	store := virtualtools.NewInMemoryHumanFeedbackStore()
+
+	executed, answer, _ := askWithPolicy(t, WithConfirmTools([]string{"delete_file"}), WithToolConfirmationStore(store), WithToolConfirmationTimeout(50*time.Millisecond))
+
+	if len(executed) != 1 || executed[0] != "read_file" {
+		t.Fatalf("expected the unanswered delete_file to be skipped, got %v", executed)
+	}
+	if !strings.Contains(answer, "nobody approved it within 50ms") {
+		t.Fatalf("unexpected tool results %q", answer)
+	}
+	if pending := store.Pending(); len(pending) != 0 {
+		t.Fatalf("expected the timed out request to be removed, got %v", pending)
+	}
+}
+
+func TestConfirmToolsShowsInterceptedArguments(t *testing.T) {
+	store := virtualtools.NewInMemoryHumanFeedbackStore()
+	questions, stop := answerConfirmations(t, store, "Approve")
+
+	askWithPolicy(t, WithConfirmTools([]string{"delete_file"}), WithToolConfirmationStore(store), WithToolInterceptors(&tenantInterceptor{tenant: "acme"}))
+	stop()
+
+	// The human approves the arguments the tool runs with, not the LLM's raw ones
+	if len(*questions) != 1 || !strings.Contains((*questions)[0], `"tenant":"acme"`) {
+		t.Fatalf("expected the confirmation to show the intercepted arguments, got %v", *questions)
+	}
+}
@@ -0,0 +1,94 @@
+package mcpagent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// WithToolDeduplication answers a tool call with the result of an identical call (same tool and
+// arguments) made earlier in the same turn, instead of executing the tool again. Only successful
+// results are reused; tools with side effects meant to repeat should not be used with it.
+func WithToolDeduplication(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.toolDeduplication = enabled
+	}
+}
+
+// toolCallDeduplicator remembers the successful tool results of one turn. A nil deduplicator
+// (deduplication disabled) never reuses results.
+type toolCallDeduplicator struct {
+	results map[string]dedupToolResult
+}
+
+type dedupToolResult struct {
+	toolCallID string
+	result     string
+}
+
+// newToolCallDeduplicator returns a deduplicator for a turn, or nil when deduplication is disabled
+func (a *Agent) newToolCallDeduplicator() *toolCallDeduplicator {
+	if !a.toolDeduplication {
+		return nil
+	}
+	return &toolCallDeduplicator{results: make(map[string]dedupToolResult)}
+}
+
+// store records the result of a successful call
+func (d *toolCallDeduplicator) store(toolName string, args map[string]interface{}, toolCallID, result string) {
+	if d == nil {
+		return
+	}
+	key, ok := toolCallDedupKey(toolName, args)
+	if !ok {
+		return
+	}
+	if _, exists := d.results[key]; !exists {
+		d.results[key] = dedupToolResult{toolCallID: toolCallID, result: result}
+	}
+}
+
+// lookup returns the earlier result of an identical call
+func (d *toolCallDeduplicator) lookup(toolName string, args map[string]interface{}) (dedupToolResult, bool) {
+	if d == nil {
+		return dedupToolResult{}, false
+	}
+	key, ok := toolCallDedupKey(toolName, args)
+	if !ok {
+		return dedupToolResult{}, false
+	}
+	previous, exists := d.results[key]
+	return previous, exists
+}
+
+// reuseDuplicateToolCall returns the earlier result when tc repeats a call made this turn, and emits
+// the deduplicated and tool call end events for it
+func (a *Agent) reuseDuplicateToolCall(ctx context.Context, dedup *toolCallDeduplicator, turn int, tc llmtypes.ToolCall, serverName string, args map[string]interface{}) (string, bool) {
+	previous, exists := dedup.lookup(tc.FunctionCall.Name, args)
+	if !exists {
+		return "", false
+	}
+	getLogger(a).Infof("♻️ Turn %d: tool %s called again with identical arguments, reusing the result of call %s", turn, tc.FunctionCall.Name, previous.toolCallID)
+	a.EmitTypedEvent(ctx, events.NewToolCallDeduplicatedEvent(turn, tc.FunctionCall.Name, serverName, tc.ID, previous.toolCallID))
+	a.EmitTypedEvent(ctx, events.NewToolCallEndEvent(turn, tc.FunctionCall.Name, previous.result, serverName, time.Duration(0), ""))
+	return previous.result, true
+}
+
+// toolCallDedupKey hashes the tool name and its arguments. Arguments are re-encoded from the parsed
+// map, so key order and whitespace differences in the LLM's JSON do not matter.
+func toolCallDedupKey(toolName string, args map[string]interface{}) (string, bool) {
+	normalized, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	hash := sha256.New()
+	hash.Write([]byte(toolName))
+	hash.Write([]byte{0})
+	hash.Write(normalized)
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
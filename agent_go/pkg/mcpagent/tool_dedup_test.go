@@ -0,0 +1,60 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+func askWithDuplicateToolCalls(t *testing.T, options ...AgentOption) (string, int, *eventCollector[*events.ToolCallDeduplicatedEvent]) {
+	t.Helper()
+	a := newTestAgent(t, parallelToolLLM(
+		llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city": "Paris", "units": "metric"}`},
+		llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"units":"metric","city":"Paris"}`}, // same call, different formatting
+		llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city": "Oslo", "units": "metric"}`},
+	), options...)
+	executions := 0
+	a.RegisterCustomTool("get_weather", "Get the weather", map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		executions++
+		return "sunny in " + args["city"].(string), nil
+	})
+	deduplicated := collectEvents[*events.ToolCallDeduplicatedEvent](a)
+
+	answer, err := a.Ask(context.Background(), "weather in Paris and Oslo?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return answer, executions, deduplicated
+}
+
+func TestToolDeduplicationRunsIdenticalCallOnce(t *testing.T) {
+	answer, executions, deduplicated := askWithDuplicateToolCalls(t, WithToolDeduplication(true))
+
+	if executions != 2 {
+		t.Fatalf("expected the tool executed once for Paris and once for Oslo, got %d executions", executions)
+	}
+	// Every tool call still gets its own result
+	if answer != "call-a=sunny in Paris;call-b=sunny in Paris;call-c=sunny in Oslo" {
+		t.Fatalf("unexpected tool results %q", answer)
+	}
+
+	recorded := deduplicated.all()
+	if len(recorded) != 1 {
+		t.Fatalf("expected one deduplicated event, got %d", len(recorded))
+	}
+	if event := recorded[0]; event.ToolCallID != "call-b" || event.OriginalToolCallID != "call-a" || event.ToolName != "get_weather" {
+		t.Fatalf("unexpected deduplicated event %+v", event)
+	}
+}
+
+func TestToolDeduplicationDisabledByDefault(t *testing.T) {
+	_, executions, deduplicated := askWithDuplicateToolCalls(t)
+	if executions != 3 {
+		t.Fatalf("expected every call executed without deduplication, got %d executions", executions)
+	}
+	if recorded := deduplicated.all(); len(recorded) != 0 {
+		t.Fatalf("expected no deduplicated events, got %d", len(recorded))
+	}
+}
@@ -0,0 +1,136 @@
+package mcpagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// DefaultToolExamplesMaxChars bounds the size of the tool usage examples section
+const DefaultToolExamplesMaxChars = 4000
+
+// WithToolExamples enables injecting one-shot tool usage examples into the system prompt.
+// Examples come from the curated store (see WithToolExampleStore) or, when absent,
+// from "examples"/"example" values in the MCP tool input schema.
+func WithToolExamples(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.includeToolExamples = enabled
+	}
+}
+
+// WithToolExampleStore sets curated usage examples keyed by tool name. Curated examples
+// take precedence over examples derived from tool definitions.
+func WithToolExampleStore(examples map[string]string) AgentOption {
+	return func(a *Agent) {
+		a.toolExampleStore = examples
+	}
+}
+
+// WithToolExamplesMaxChars caps the size of the tool examples section (0 uses DefaultToolExamplesMaxChars)
+func WithToolExamplesMaxChars(maxChars int) AgentOption {
+	return func(a *Agent) {
+		a.toolExamplesMaxChars = maxChars
+	}
+}
+
+// applyToolExamples appends the tool usage examples section to the system prompt when enabled
+func (a *Agent) applyToolExamples() {
+	if !a.includeToolExamples {
+		return
+	}
+
+	section := buildToolExamplesSection(a.filteredTools, a.toolExampleStore, a.toolExamplesMaxChars)
+	if section == "" {
+		return
+	}
+	a.SystemPrompt = strings.TrimRight(a.SystemPrompt, "\n") + "\n\n" + section
+
+	if a.Logger != nil {
+		a.Logger.Infof("🧩 Added tool usage examples to system prompt (%d chars)", len(section))
+	}
+}
+
+// buildToolExamplesSection renders usage examples for the given tools, stopping before maxChars is exceeded
+func buildToolExamplesSection(tools []llmtypes.Tool, store map[string]string, maxChars int) string {
+	if maxChars <= 0 {
+		maxChars = DefaultToolExamplesMaxChars
+	}
+
+	const header = "## Tool Usage Examples\n\nExample arguments for some of the available tools:\n"
+
+	var builder strings.Builder
+	added, omitted := 0, 0
+	for _, tool := range tools {
+		if tool.Function == nil {
+			continue
+		}
+		example := store[tool.Function.Name]
+		if example == "" {
+			example = exampleFromToolSchema(tool.Function.Parameters)
+		}
+		if example == "" {
+			continue
+		}
+
+		entry := fmt.Sprintf("\n### %s\n```json\n%s\n```\n", tool.Function.Name, strings.TrimSpace(example))
+		if len(header)+builder.Len()+len(entry) > maxChars {
+			omitted++
+			continue
+		}
+		builder.WriteString(entry)
+		added++
+	}
+
+	if added == 0 {
+		return ""
+	}
+	if omitted > 0 {
+		builder.WriteString(fmt.Sprintf("\n(%d more examples omitted to limit prompt size)\n", omitted))
+	}
+	return header + builder.String()
+}
+
+// exampleFromToolSchema builds example arguments from a tool's input schema. A top-level
+// "examples" entry is used as-is; otherwise per-property "examples"/"example" values are combined.
+func exampleFromToolSchema(params *llmtypes.Parameters) string {
+	if params == nil {
+		return ""
+	}
+
+	if examples, ok := params.Additional["examples"].([]interface{}); ok && len(examples) > 0 {
+		return marshalExample(examples[0])
+	}
+
+	args := make(map[string]interface{})
+	names := make([]string, 0, len(params.Properties))
+	for name := range params.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := params.Properties[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if examples, ok := prop["examples"].([]interface{}); ok && len(examples) > 0 {
+			args[name] = examples[0]
+		} else if example, ok := prop["example"]; ok {
+			args[name] = example
+		}
+	}
+	if len(args) == 0 {
+		return ""
+	}
+	return marshalExample(args)
+}
+
+func marshalExample(value interface{}) string {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
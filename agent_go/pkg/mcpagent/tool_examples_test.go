@@ -0,0 +1,87 @@
+package mcpagent
+
+import (
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+func exampleTestTools() []llmtypes.Tool {
+	return []llmtypes.Tool{
+		{Type: "function", Function: &llmtypes.FunctionDefinition{
+			Name: "search_issues",
+			Parameters: &llmtypes.Parameters{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"query": map[string]interface{}{"type": "string", "examples": []interface{}{"is:open label:bug"}},
+					"limit": map[string]interface{}{"type": "integer", "example": float64(10)},
+				},
+			},
+		}},
+		{Type: "function", Function: &llmtypes.FunctionDefinition{
+			Name: "create_issue",
+			Parameters: &llmtypes.Parameters{
+				Type:       "object",
+				Properties: map[string]interface{}{"title": map[string]interface{}{"type": "string"}},
+				Additional: map[string]interface{}{"examples": []interface{}{map[string]interface{}{"title": "Crash on startup"}}},
+			},
+		}},
+		{Type: "function", Function: &llmtypes.FunctionDefinition{
+			Name:       "get_repo",
+			Parameters: &llmtypes.Parameters{Type: "object"},
+		}},
+	}
+}
+
+func TestToolExamplesRenderedForSelectedTools(t *testing.T) {
+	a := &Agent{
+		SystemPrompt:        "You are a helpful assistant.",
+		filteredTools:       exampleTestTools(),
+		includeToolExamples: true,
+		toolExampleStore:    map[string]string{"get_repo": `{"owner": "acme", "repo": "widgets"}`},
+	}
+	a.applyToolExamples()
+
+	for _, want := range []string{
+		"You are a helpful assistant.",
+		"## Tool Usage Examples",
+		"### search_issues",
+		`"query": "is:open label:bug"`,
+		`"limit": 10`,
+		"### create_issue",
+		`"title": "Crash on startup"`,
+		"### get_repo",
+		`{"owner": "acme", "repo": "widgets"}`,
+	} {
+		if !strings.Contains(a.SystemPrompt, want) {
+			t.Errorf("expected system prompt to contain %q, got:\n%s", want, a.SystemPrompt)
+		}
+	}
+}
+
+func TestToolExamplesOmittedWhenDisabled(t *testing.T) {
+	a := &Agent{
+		SystemPrompt:     "You are a helpful assistant.",
+		filteredTools:    exampleTestTools(),
+		toolExampleStore: map[string]string{"get_repo": `{"owner": "acme"}`},
+	}
+	a.applyToolExamples()
+
+	if a.SystemPrompt != "You are a helpful assistant." {
+		t.Fatalf("expected system prompt unchanged when examples are disabled, got:\n%s", a.SystemPrompt)
+	}
+}
+
+func TestToolExamplesSectionIsSizeBounded(t *testing.T) {
+	section := buildToolExamplesSection(exampleTestTools(), nil, 200)
+	if len(section) > 200+len("\n(1 more examples omitted to limit prompt size)\n") {
+		t.Fatalf("expected section bounded near 200 chars, got %d", len(section))
+	}
+	if !strings.Contains(section, "### search_issues") || strings.Contains(section, "### create_issue") {
+		t.Fatalf("expected only the first example to fit, got:\n%s", section)
+	}
+	if !strings.Contains(section, "1 more examples omitted") {
+		t.Fatalf("expected omitted examples to be noted, got:\n%s", section)
+	}
+}
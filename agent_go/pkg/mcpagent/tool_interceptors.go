@@ -0,0 +1,73 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/mcpclient"
+)
+
+// ToolInterceptor wraps every tool call the agent makes, e.g. to redact secrets in arguments or
+// inject tenant context. Before may return rewritten arguments; an error rejects the call, which is
+// reported to the LLM as a tool error. After sees the result text and execution error and may
+// replace either.
+type ToolInterceptor interface {
+	Before(ctx context.Context, toolName string, args map[string]interface{}) (map[string]interface{}, error)
+	After(ctx context.Context, toolName string, result string, err error) (string, error)
+}
+
+// WithToolInterceptors adds interceptors around tool calls. Before hooks run in registration order,
+// After hooks in reverse order, so the first interceptor is the outermost.
+func WithToolInterceptors(interceptors ...ToolInterceptor) AgentOption {
+	return func(a *Agent) {
+		a.toolInterceptors = append(a.toolInterceptors, interceptors...)
+	}
+}
+
+// interceptToolArgs runs the Before hooks and returns the arguments to call the tool with
+func (a *Agent) interceptToolArgs(ctx context.Context, toolName string, args map[string]interface{}) (map[string]interface{}, error) {
+	for _, interceptor := range a.toolInterceptors {
+		rewritten, err := interceptor.Before(ctx, toolName, args)
+		if err != nil {
+			return nil, err
+		}
+		if rewritten != nil {
+			args = rewritten
+		}
+	}
+	return args, nil
+}
+
+// interceptToolResult runs the After hooks over a tool's result text and error
+func (a *Agent) interceptToolResult(ctx context.Context, toolName, result string, err error) (string, error) {
+	for i := len(a.toolInterceptors) - 1; i >= 0; i-- {
+		result, err = a.toolInterceptors[i].After(ctx, toolName, result, err)
+	}
+	return result, err
+}
+
+// interceptToolCallResult runs the After hooks over an MCP call result, keeping its error flag
+func (a *Agent) interceptToolCallResult(ctx context.Context, toolName string, result *mcp.CallToolResult, err error) (*mcp.CallToolResult, error) {
+	if len(a.toolInterceptors) == 0 {
+		return result, err
+	}
+	text, isError := "", false
+	if err == nil && result != nil {
+		text, isError = mcpclient.ToolResultAsString(result, getLogger(a)), result.IsError
+	}
+	text, err = a.interceptToolResult(ctx, toolName, text, err)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResult{IsError: isError, Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil
+}
+
+// rejectToolCall emits the tool call error of a call an interceptor rejected and returns the text for the LLM
+func (a *Agent) rejectToolCall(ctx context.Context, turn int, toolName, serverName string, err error) string {
+	getLogger(a).Warnf("⛔ Turn %d: tool %s rejected by interceptor: %v", turn, toolName, err)
+	a.EmitTypedEvent(ctx, events.NewToolCallErrorEvent(turn, toolName, err.Error(), serverName, 0))
+	return fmt.Sprintf("Tool execution failed - tool '%s' was not executed: %v", toolName, err)
+}
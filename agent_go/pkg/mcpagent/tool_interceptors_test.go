@@ -0,0 +1,144 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// tenantInterceptor injects a tenant argument and redacts tokens in the result
+type tenantInterceptor struct {
+	tenant string
+}
+
+func (i *tenantInterceptor) Before(ctx context.Context, toolName string, args map[string]interface{}) (map[string]interface{}, error) {
+	rewritten := make(map[string]interface{}, len(args)+1)
+	for key, value := range args {
+		rewritten[key] = value
+	}
+	rewritten["tenant"] = i.tenant
+	return rewritten, nil
+}
+
+func (i *tenantInterceptor) After(ctx context.Context, toolName string, result string, err error) (string, error) {
+	return strings.ReplaceAll(result, "secret-token", "[REDACTED]"), err
+}
+
+// countingInterceptor records how often each hook ran per tool and can reject a tool
+type countingInterceptor struct {
+	mu     sync.Mutex
+	before map[string]int
+	after  map[string]int
+	reject string
+}
+
+func (i *countingInterceptor) Before(ctx context.Context, toolName string, args map[string]interface{}) (map[string]interface{}, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.before[toolName]++
+	if toolName == i.reject {
+		return nil, errors.New("blocked for this tenant")
+	}
+	return nil, nil
+}
+
+func (i *countingInterceptor) After(ctx context.Context, toolName string, result string, err error) (string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.after[toolName]++
+	return result, err
+}
+
+// askWithInterceptors runs one turn calling delete_file and read_file, whose results echo their arguments
+func askWithInterceptors(t *testing.T, interceptors ...ToolInterceptor) (string, *eventCollector[*events.ToolCallErrorEvent]) {
+	t.Helper()
+	a := newTestAgent(t, parallelToolLLM(
+		llmtypes.FunctionCall{Name: "delete_file", Arguments: `{"path": "/tmp/report.txt"}`},
+		llmtypes.FunctionCall{Name: "read_file", Arguments: `{"path": "/tmp/report.txt"}`},
+	), WithToolInterceptors(interceptors...))
+
+	for _, name := range []string{"delete_file", "read_file"} {
+		name := name
+		a.RegisterCustomTool(name, name, map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+			return fmt.Sprintf("%s %v for %v with secret-token", name, args["path"], args["tenant"]), nil
+		})
+	}
+	toolErrors := collectEvents[*events.ToolCallErrorEvent](a)
+
+	answer, err := a.Ask(context.Background(), "clean up the report")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return answer, toolErrors
+}
+
+func TestToolInterceptorsRewriteArgumentsAndResults(t *testing.T) {
+	counter := &countingInterceptor{before: map[string]int{}, after: map[string]int{}}
+	answer, _ := askWithInterceptors(t, &tenantInterceptor{tenant: "acme"}, counter)
+
+	if !strings.Contains(answer, "call-a=delete_file /tmp/report.txt for acme with [REDACTED]") ||
+		!strings.Contains(answer, "call-b=read_file /tmp/report.txt for acme with [REDACTED]") {
+		t.Fatalf("expected tenant injected and token redacted, got %q", answer)
+	}
+	for _, name := range []string{"delete_file", "read_file"} {
+		if counter.before[name] != 1 || counter.after[name] != 1 {
+			t.Fatalf("expected one before and after call for %s, got %d/%d", name, counter.before[name], counter.after[name])
+		}
+	}
+}
+
+func TestToolInterceptorBeforeErrorRejectsCall(t *testing.T) {
+	counter := &countingInterceptor{before: map[string]int{}, after: map[string]int{}, reject: "delete_file"}
+	answer, toolErrors := askWithInterceptors(t, counter)
+
+	if !strings.Contains(answer, "call-a=Tool execution failed - tool 'delete_file' was not executed: blocked for this tenant") {
+		t.Fatalf("expected delete_file rejected, got %q", answer)
+	}
+	if !strings.Contains(answer, "call-b=read_file /tmp/report.txt") {
+		t.Fatalf("expected read_file to run, got %q", answer)
+	}
+	if counter.after["delete_file"] != 0 || counter.after["read_file"] != 1 {
+		t.Fatalf("expected After only for executed calls, got %v", counter.after)
+	}
+
+	errorEvents := toolErrors.all()
+	if len(errorEvents) != 1 || errorEvents[0].ToolName != "delete_file" {
+		t.Fatalf("expected one tool call error for delete_file, got %+v", errorEvents)
+	}
+}
+
+func TestToolInterceptorsAfterRunInReverseOrder(t *testing.T) {
+	var order []string
+	a := &Agent{toolInterceptors: []ToolInterceptor{&orderInterceptor{name: "outer", order: &order}, &orderInterceptor{name: "inner", order: &order}}}
+
+	if _, err := a.interceptToolArgs(context.Background(), "read_file", map[string]interface{}{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := a.interceptToolResult(context.Background(), "read_file", "ok", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "before:outer,before:inner,after:inner,after:outer" {
+		t.Fatalf("unexpected hook order %s", got)
+	}
+}
+
+type orderInterceptor struct {
+	name  string
+	order *[]string
+}
+
+func (i *orderInterceptor) Before(ctx context.Context, toolName string, args map[string]interface{}) (map[string]interface{}, error) {
+	*i.order = append(*i.order, "before:"+i.name)
+	return args, nil
+}
+
+func (i *orderInterceptor) After(ctx context.Context, toolName string, result string, err error) (string, error) {
+	*i.order = append(*i.order, "after:"+i.name)
+	return result, err
+}
@@ -0,0 +1,113 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// defaultPermissionErrorPatterns are lower-case substrings of tool failures caused by missing permissions
+var defaultPermissionErrorPatterns = []string{
+	"permission denied",
+	"access denied",
+	"accessdenied",
+	"operation not permitted",
+	"not authorized",
+	"unauthorized",
+	"forbidden",
+	"insufficient permission",
+	"read-only file system",
+	"eacces",
+	"eperm",
+}
+
+// WithToolReadOnlyFallback registers readOnlyTool to call with the same arguments when toolName fails
+// with a permission error, e.g. a read-only variant of a write tool. Unlike WithToolAlternate it is
+// called on the first such failure, since retrying a denied call cannot succeed.
+func WithToolReadOnlyFallback(toolName, readOnlyTool string) AgentOption {
+	return func(a *Agent) {
+		if a.readOnlyFallbacks == nil {
+			a.readOnlyFallbacks = make(map[string]string)
+		}
+		a.readOnlyFallbacks[toolName] = readOnlyTool
+	}
+}
+
+// WithPermissionErrorPatterns adds case-insensitive substrings that mark a tool failure as a permission error
+func WithPermissionErrorPatterns(patterns ...string) AgentOption {
+	return func(a *Agent) {
+		for _, pattern := range patterns {
+			if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" {
+				a.permissionErrorPatterns = append(a.permissionErrorPatterns, pattern)
+			}
+		}
+	}
+}
+
+// WithPermissionErrorDetection enables (the default) or disables permission error detection; when
+// disabled, permission errors are reported and retried like any other tool failure
+func WithPermissionErrorDetection(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.permissionDetectionDisabled = !enabled
+	}
+}
+
+// isPermissionError reports whether a tool failure was caused by missing permissions
+func (a *Agent) isPermissionError(failure string) bool {
+	if a.permissionDetectionDisabled {
+		return false
+	}
+	lower := strings.ToLower(failure)
+	for _, pattern := range defaultPermissionErrorPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	for _, pattern := range a.permissionErrorPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePermissionError handles a tool failure caused by missing permissions: it calls the tool's
+// read-only fallback if one is registered, else tells the LLM not to retry. Returns the text to feed
+// back to the LLM ("" when failure is not a permission error), the fallback's name and whether it succeeded.
+func (a *Agent) handlePermissionError(ctx context.Context, turn int, toolName string, args map[string]interface{}, failure string) (string, string, bool) {
+	if !a.isPermissionError(failure) {
+		return "", "", false
+	}
+
+	logger := getLogger(a)
+	event := events.NewToolPermissionDeniedEvent(turn, toolName, failure)
+	note := fmt.Sprintf("Tool execution failed - %s\n\nThis is a permission error: the agent is not allowed to perform this operation with %s, so calling it again will fail the same way.", failure, toolName)
+
+	fallback, exists := a.readOnlyFallbacks[toolName]
+	if !exists {
+		logger.Infof("🔒 Tool %s was denied permission, not retrying: %s", toolName, failure)
+		a.EmitTypedEvent(ctx, event)
+		return note + " Do not retry it; continue with read-only tools, or tell the user which permission is needed.", "", false
+	}
+
+	logger.Infof("🔒 Tool %s was denied permission, calling read-only fallback %s", toolName, fallback)
+	fallbackCtx, cancel := context.WithTimeout(ctx, getToolExecutionTimeout(a))
+	defer cancel()
+	result, err := a.callToolByName(fallbackCtx, fallback, args)
+
+	event.FallbackTool = fallback
+	event.Succeeded = err == nil
+	if err != nil {
+		event.FallbackError = err.Error()
+	}
+	a.EmitTypedEvent(ctx, event)
+
+	note += fmt.Sprintf(" The read-only fallback %s was called with the same arguments", fallback)
+	if err != nil {
+		logger.Warnf("Read-only fallback %s for %s failed: %v", fallback, toolName, err)
+		return fmt.Sprintf("%s, but it also failed: %v. Do not retry %s; tell the user which permission is needed.", note, err, toolName), fallback, false
+	}
+	return fmt.Sprintf("%s. Its result:\n%s", note, result), fallback, true
+}
@@ -0,0 +1,102 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// newPermissionTestAgent returns an agent whose write_file tool is denied and whose LLM calls it once
+func newPermissionTestAgent(t *testing.T, options ...AgentOption) (*Agent, *fakeLLM, *int) {
+	t.Helper()
+	llm := sequentialToolLLM("done", llmtypes.FunctionCall{Name: "write_file", Arguments: `{"path": "/etc/app.conf"}`})
+	a := newTestAgent(t, llm, options...)
+
+	writeCalls := 0
+	a.RegisterCustomTool("write_file", "Write a file", map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		writeCalls++
+		return "", fmt.Errorf("open /etc/app.conf: Permission denied")
+	})
+	return a, llm, &writeCalls
+}
+
+func TestPermissionErrorCallsReadOnlyFallback(t *testing.T) {
+	a, llm, writeCalls := newPermissionTestAgent(t, WithToolReadOnlyFallback("write_file", "read_file"))
+	var readArgs []map[string]interface{}
+	a.RegisterCustomTool("read_file", "Read a file", map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		readArgs = append(readArgs, args)
+		return "log_level=info", nil
+	})
+	denials := collectEvents[*events.ToolPermissionDeniedEvent](a)
+
+	if _, err := a.Ask(context.Background(), "set the log level to debug"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The fallback runs on the first denial, without waiting for repeated failures
+	if *writeCalls != 1 || len(readArgs) != 1 || readArgs[0]["path"] != "/etc/app.conf" {
+		t.Fatalf("expected one denied write and one read with the same arguments, got %d writes, reads %v", *writeCalls, readArgs)
+	}
+	if result := llm.lastToolResult(); !strings.Contains(result, "permission error") || !strings.Contains(result, "read-only fallback read_file") || !strings.Contains(result, "log_level=info") {
+		t.Fatalf("expected the fallback result fed back to the LLM, got %q", result)
+	}
+
+	denied := denials.all()
+	if len(denied) != 1 {
+		t.Fatalf("expected one permission event, got %d", len(denied))
+	}
+	if event := denied[0]; event.ToolName != "write_file" || event.FallbackTool != "read_file" || !event.Succeeded || !strings.Contains(event.Error, "Permission denied") {
+		t.Fatalf("unexpected permission event: %+v", event)
+	}
+}
+
+func TestPermissionErrorWithoutFallbackTellsLLMNotToRetry(t *testing.T) {
+	// An alternate must not be used for a permission error, even past its failure threshold
+	a, llm, _ := newPermissionTestAgent(t, WithToolAlternate("write_file", "write_file_v2"), WithToolAlternateThreshold(1))
+	alternateCalled := false
+	a.RegisterCustomTool("write_file_v2", "Write a file", map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		alternateCalled = true
+		return "written", nil
+	})
+	denials := collectEvents[*events.ToolPermissionDeniedEvent](a)
+
+	if _, err := a.Ask(context.Background(), "set the log level to debug"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if alternateCalled {
+		t.Error("the alternate tool was called for a permission error")
+	}
+	if result := llm.lastToolResult(); !strings.Contains(result, "Permission denied") || !strings.Contains(result, "Do not retry it") {
+		t.Fatalf("expected an informative no-retry result, got %q", result)
+	}
+	if denied := denials.all(); len(denied) != 1 || denied[0].FallbackTool != "" {
+		t.Fatalf("expected one permission event without fallback, got %+v", denied)
+	}
+}
+
+func TestPermissionErrorDetectionConfig(t *testing.T) {
+	a := &Agent{}
+	if a.isPermissionError("quota exceeded for bucket logs") {
+		t.Error("an unrelated error was detected as a permission error")
+	}
+	for _, failure := range []string{"403 Forbidden", "AccessDenied: not allowed to PutObject", "EACCES: open /var/log"} {
+		if !a.isPermissionError(failure) {
+			t.Errorf("%q was not detected as a permission error", failure)
+		}
+	}
+
+	WithPermissionErrorPatterns("Policy Violation")(a)
+	if !a.isPermissionError("write blocked: policy violation") {
+		t.Error("a custom pattern did not match case-insensitively")
+	}
+
+	WithPermissionErrorDetection(false)(a)
+	if a.isPermissionError("Permission denied") {
+		t.Error("detection is disabled but a permission error was still detected")
+	}
+}
@@ -0,0 +1,114 @@
+package mcpagent
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ToolPolicy blocks tools by name independently of server selection. Patterns are globs
+// ("*", "?", "[...]") matched against the tool name and, for MCP tools, "server:tool". A tool
+// matching the denylist is always blocked; a non-empty allowlist blocks every tool it does not match.
+type ToolPolicy struct {
+	Allowlist []string
+	Denylist  []string
+}
+
+// ParseToolPolicyPatterns splits a comma-separated pattern list, dropping empty entries
+func ParseToolPolicyPatterns(patterns string) []string {
+	var parsed []string
+	for _, pattern := range strings.Split(patterns, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			parsed = append(parsed, pattern)
+		}
+	}
+	return parsed
+}
+
+// Validate reports the first malformed pattern
+func (p ToolPolicy) Validate() error {
+	for _, pattern := range append(append([]string{}, p.Allowlist...), p.Denylist...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool policy pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// IsEmpty reports whether the policy allows every tool
+func (p ToolPolicy) IsEmpty() bool {
+	return len(p.Allowlist) == 0 && len(p.Denylist) == 0
+}
+
+// Check returns why the tool is blocked, or "" when the policy allows it. serverName may be empty.
+func (p ToolPolicy) Check(toolName, serverName string) string {
+	names := []string{toolName}
+	if serverName != "" {
+		names = append(names, serverName+":"+toolName)
+	}
+
+	if pattern, matched := matchToolPattern(p.Denylist, names); matched {
+		return fmt.Sprintf("tool '%s' matches denylist pattern '%s'", toolName, pattern)
+	}
+	if len(p.Allowlist) > 0 {
+		if _, matched := matchToolPattern(p.Allowlist, names); !matched {
+			return fmt.Sprintf("tool '%s' is not in the allowlist", toolName)
+		}
+	}
+	return ""
+}
+
+func matchToolPattern(patterns, names []string) (string, bool) {
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if matched, _ := path.Match(pattern, name); matched {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+var defaultToolPolicy = struct {
+	mu     sync.RWMutex
+	policy ToolPolicy
+}{}
+
+// SetDefaultToolPolicy sets the process-wide policy used by agents without their own (see WithToolPolicy)
+func SetDefaultToolPolicy(policy ToolPolicy) {
+	defaultToolPolicy.mu.Lock()
+	defer defaultToolPolicy.mu.Unlock()
+	defaultToolPolicy.policy = policy
+}
+
+// DefaultToolPolicy returns the process-wide tool policy
+func DefaultToolPolicy() ToolPolicy {
+	defaultToolPolicy.mu.RLock()
+	defer defaultToolPolicy.mu.RUnlock()
+	return defaultToolPolicy.policy
+}
+
+// WithToolPolicy blocks tools by allowlist/denylist before any call, replacing the default policy
+func WithToolPolicy(policy ToolPolicy) AgentOption {
+	return func(a *Agent) {
+		a.toolPolicy = &policy
+	}
+}
+
+// checkToolPolicy returns why the agent's tool policy blocks the call, or "" when it is allowed
+func (a *Agent) checkToolPolicy(toolName, serverName string) string {
+	policy := DefaultToolPolicy()
+	if a.toolPolicy != nil {
+		policy = *a.toolPolicy
+	}
+	if policy.IsEmpty() {
+		return ""
+	}
+	return policy.Check(toolName, serverName)
+}
+
+// toolPolicyFeedback tells the LLM the tool is blocked so it does not retry it
+func toolPolicyFeedback(toolName, reason string) string {
+	return fmt.Sprintf("❌ Tool '%s' is blocked by the tool policy (%s). It was not executed. Do not call it again; use another tool or answer without it.", toolName, reason)
+}
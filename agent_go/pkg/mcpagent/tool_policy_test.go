@@ -0,0 +1,101 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// askWithPolicy runs one turn calling delete_file and read_file and returns the executed tools
+func askWithPolicy(t *testing.T, options ...AgentOption) ([]string, string, *eventCollector[*events.ToolCallErrorEvent]) {
+	t.Helper()
+	a := newTestAgent(t, parallelToolLLM(
+		llmtypes.FunctionCall{Name: "delete_file", Arguments: `{"path": "/tmp/report.txt"}`},
+		llmtypes.FunctionCall{Name: "read_file", Arguments: `{"path": "/tmp/report.txt"}`},
+	), options...)
+
+	var mu sync.Mutex
+	var executed []string
+	for _, name := range []string{"delete_file", "read_file"} {
+		name := name
+		a.RegisterCustomTool(name, name, map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			executed = append(executed, name)
+			return name + " ok", nil
+		})
+	}
+	toolErrors := collectEvents[*events.ToolCallErrorEvent](a)
+
+	answer, err := a.Ask(context.Background(), "clean up the report")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return executed, answer, toolErrors
+}
+
+func TestToolPolicyDeniedToolNeverExecutes(t *testing.T) {
+	executed, answer, toolErrors := askWithPolicy(t, WithToolPolicy(ToolPolicy{Denylist: []string{"delete_*"}}))
+
+	if len(executed) != 1 || executed[0] != "read_file" {
+		t.Fatalf("expected only read_file to run, got %v", executed)
+	}
+	if !strings.Contains(answer, "call-a=❌ Tool 'delete_file' is blocked by the tool policy") || !strings.Contains(answer, "call-b=read_file ok") {
+		t.Fatalf("unexpected tool results %q", answer)
+	}
+
+	errorEvents := toolErrors.all()
+	if len(errorEvents) != 1 {
+		t.Fatalf("expected one tool call error event, got %d", len(errorEvents))
+	}
+	if event := errorEvents[0]; event.ToolName != "delete_file" || event.Reason != events.ToolBlockedByPolicyReason {
+		t.Fatalf("unexpected tool call error event %+v", event)
+	}
+}
+
+func TestToolPolicyAllowlistExcludesEverythingElse(t *testing.T) {
+	executed, _, toolErrors := askWithPolicy(t, WithToolPolicy(ToolPolicy{Allowlist: []string{"read_*"}}))
+
+	if len(executed) != 1 || executed[0] != "read_file" {
+		t.Fatalf("expected only the allowlisted read_file to run, got %v", executed)
+	}
+	errorEvents := toolErrors.all()
+	if len(errorEvents) != 1 || errorEvents[0].ToolName != "delete_file" || errorEvents[0].Reason != events.ToolBlockedByPolicyReason {
+		t.Fatalf("expected delete_file blocked by policy, got %+v", errorEvents)
+	}
+}
+
+func TestToolPolicyDefaultAppliesUnlessOverridden(t *testing.T) {
+	SetDefaultToolPolicy(ToolPolicy{Denylist: []string{"*"}})
+	defer SetDefaultToolPolicy(ToolPolicy{})
+
+	if executed, _, _ := askWithPolicy(t); len(executed) != 0 {
+		t.Fatalf("expected the default policy to block every tool, got %v", executed)
+	}
+	if executed, _, _ := askWithPolicy(t, WithToolPolicy(ToolPolicy{})); len(executed) != 2 {
+		t.Fatalf("expected an agent policy to replace the default, got %v", executed)
+	}
+}
+
+func TestToolPolicyMatchesServerQualifiedNames(t *testing.T) {
+	policy := ToolPolicy{Allowlist: []string{"github:*"}, Denylist: []string{"github:delete_repo"}}
+	if reason := policy.Check("get_issue", "github"); reason != "" {
+		t.Fatalf("expected github:get_issue allowed, got %q", reason)
+	}
+	if reason := policy.Check("delete_repo", "github"); !strings.Contains(reason, "denylist") {
+		t.Fatalf("expected github:delete_repo denied, got %q", reason)
+	}
+	if reason := policy.Check("list_buckets", "aws"); !strings.Contains(reason, "allowlist") {
+		t.Fatalf("expected aws:list_buckets outside the allowlist, got %q", reason)
+	}
+	if err := (ToolPolicy{Denylist: []string{"[bad"}}).Validate(); err == nil {
+		t.Fatal("expected a malformed pattern to be rejected")
+	}
+	if got := ParseToolPolicyPatterns(" delete_*, ,fs:rm "); len(got) != 2 || got[0] != "delete_*" || got[1] != "fs:rm" {
+		t.Fatalf("unexpected parsed patterns %v", got)
+	}
+}
@@ -0,0 +1,64 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// sleepingTool returns after delay unless its context ends first
+func sleepingTool(delay time.Duration) func(ctx context.Context, args map[string]interface{}) (string, error) {
+	return func(ctx context.Context, args map[string]interface{}) (string, error) {
+		select {
+		case <-time.After(delay):
+			return "done", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+func TestToolTimeoutOverrideAppliesPerTool(t *testing.T) {
+	llm := sequentialToolLLM("done",
+		llmtypes.FunctionCall{Name: "web_search", Arguments: `{}`},
+		llmtypes.FunctionCall{Name: "run_build", Arguments: `{}`},
+	)
+	a := newTestAgent(t, llm, WithToolTimeout(5*time.Second), WithToolTimeouts(map[string]time.Duration{"run_build": 50 * time.Millisecond}))
+
+	// web_search is slower than run_build's override but within the default; run_build exceeds its override
+	a.RegisterCustomTool("web_search", "Search the web", map[string]interface{}{"type": "object"}, sleepingTool(100*time.Millisecond))
+	a.RegisterCustomTool("run_build", "Run the build", map[string]interface{}{"type": "object"}, sleepingTool(5*time.Second))
+	toolErrors := collectEvents[*events.ToolCallErrorEvent](a)
+
+	start := time.Now()
+	if _, err := a.Ask(context.Background(), "build the project"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("expected run_build to be cut off by its override, took %s", elapsed)
+	}
+	if !strings.Contains(llm.lastToolResult(), "timed out after 50ms") {
+		t.Fatalf("expected the timeout reported to the LLM, got %q", llm.lastToolResult())
+	}
+
+	if len(toolErrors.all()) != 1 {
+		t.Fatalf("expected one tool error event, got %d", len(toolErrors.all()))
+	}
+	event := toolErrors.all()[0]
+	if event.ToolName != "run_build" || event.Reason != events.ToolTimeoutReason || event.Timeout != 50*time.Millisecond {
+		t.Fatalf("unexpected tool error event: %+v", event)
+	}
+}
+
+func TestGetToolTimeoutFallsBackToDefault(t *testing.T) {
+	a := &Agent{ToolTimeout: time.Minute, ToolTimeouts: map[string]time.Duration{"run_build": 10 * time.Minute, "broken": 0}}
+	for tool, want := range map[string]time.Duration{"run_build": 10 * time.Minute, "web_search": time.Minute, "broken": time.Minute} {
+		if got := getToolTimeout(a, tool); got != want {
+			t.Errorf("getToolTimeout(%q) = %s, want %s", tool, got, want)
+		}
+	}
+}
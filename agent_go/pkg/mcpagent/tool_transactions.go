@@ -0,0 +1,223 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// Transaction tools exposed to the LLM when tool transactions are enabled
+const (
+	BeginTransactionTool    = "begin_transaction"
+	CommitTransactionTool   = "commit_transaction"
+	RollbackTransactionTool = "rollback_transaction"
+)
+
+// CompensationFunc undoes a successful tool call when its transaction rolls back.
+// It receives the original call's arguments and result text.
+type CompensationFunc func(ctx context.Context, args map[string]interface{}, result string) error
+
+// toolTransactionState is the open transaction of an agent, if any
+type toolTransactionState struct {
+	mu     sync.Mutex
+	active *toolTransaction
+}
+
+type toolTransaction struct {
+	id    string
+	name  string
+	steps []toolTransactionStep
+}
+
+type toolTransactionStep struct {
+	tool   string
+	args   map[string]interface{}
+	result string
+}
+
+// WithToolTransactions exposes begin/commit/rollback_transaction tools so the LLM can group
+// tool calls; when a call in an open transaction fails, earlier calls are compensated in reverse order
+func WithToolTransactions(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.toolTransactions = enabled
+	}
+}
+
+// WithToolCompensation registers the action that undoes a successful call of toolName during rollback
+func WithToolCompensation(toolName string, compensate CompensationFunc) AgentOption {
+	return func(a *Agent) {
+		a.RegisterToolCompensation(toolName, compensate)
+	}
+}
+
+// RegisterToolCompensation registers the action that undoes a successful call of toolName during rollback
+func (a *Agent) RegisterToolCompensation(toolName string, compensate CompensationFunc) {
+	if a.toolCompensations == nil {
+		a.toolCompensations = make(map[string]CompensationFunc)
+	}
+	a.toolCompensations[toolName] = compensate
+}
+
+// registerTransactionTools adds the transaction tools when tool transactions are enabled
+func (a *Agent) registerTransactionTools() {
+	if !a.toolTransactions {
+		return
+	}
+	a.RegisterCustomTool(BeginTransactionTool,
+		"Start a transaction grouping the following tool calls. If any of them fails, the completed ones are undone. Call commit_transaction when all steps succeed.",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Short description of what the transaction does",
+				},
+			},
+		},
+		a.beginTransaction)
+	a.RegisterCustomTool(CommitTransactionTool,
+		"Commit the open transaction after all of its tool calls succeeded.",
+		map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+		a.commitTransaction)
+	a.RegisterCustomTool(RollbackTransactionTool,
+		"Undo the tool calls made in the open transaction.",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"reason": map[string]interface{}{
+					"type":        "string",
+					"description": "Why the transaction is rolled back",
+				},
+			},
+		},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			reason, _ := args["reason"].(string)
+			if reason == "" {
+				reason = "rollback requested"
+			}
+			summary, ok := a.rollbackTransaction(ctx, reason)
+			if !ok {
+				return "", fmt.Errorf("no transaction is open")
+			}
+			return summary, nil
+		})
+}
+
+func isTransactionTool(name string) bool {
+	return name == BeginTransactionTool || name == CommitTransactionTool || name == RollbackTransactionTool
+}
+
+func (a *Agent) beginTransaction(ctx context.Context, args map[string]interface{}) (string, error) {
+	name, _ := args["name"].(string)
+
+	a.transactionState.mu.Lock()
+	if open := a.transactionState.active; open != nil {
+		a.transactionState.mu.Unlock()
+		return "", fmt.Errorf("transaction %s is already open; commit or roll it back first", open.id)
+	}
+	tx := &toolTransaction{id: fmt.Sprintf("txn_%d", time.Now().UnixNano()), name: name}
+	a.transactionState.active = tx
+	a.transactionState.mu.Unlock()
+
+	a.EmitTypedEvent(ctx, events.NewToolTransactionEvent("begin", tx.id, tx.name, nil))
+	return fmt.Sprintf("Transaction %s started. If a tool call fails before commit_transaction, the completed calls are undone.", tx.id), nil
+}
+
+func (a *Agent) commitTransaction(ctx context.Context, args map[string]interface{}) (string, error) {
+	a.transactionState.mu.Lock()
+	tx := a.transactionState.active
+	a.transactionState.active = nil
+	a.transactionState.mu.Unlock()
+	if tx == nil {
+		return "", fmt.Errorf("no transaction is open")
+	}
+
+	a.EmitTypedEvent(ctx, events.NewToolTransactionEvent("commit", tx.id, tx.name, tx.stepNames()))
+	return fmt.Sprintf("Transaction %s committed (%d tool calls).", tx.id, len(tx.steps)), nil
+}
+
+// recordTransactionStep records a tool call made inside the open transaction. A failed call rolls
+// the transaction back; the returned note describes the rollback for the LLM ("" otherwise).
+func (a *Agent) recordTransactionStep(ctx context.Context, toolName string, args map[string]interface{}, result string, failed bool) string {
+	if !a.toolTransactions || isTransactionTool(toolName) {
+		return ""
+	}
+
+	a.transactionState.mu.Lock()
+	tx := a.transactionState.active
+	if tx != nil && !failed {
+		tx.steps = append(tx.steps, toolTransactionStep{tool: toolName, args: args, result: result})
+	}
+	a.transactionState.mu.Unlock()
+	if tx == nil || !failed {
+		return ""
+	}
+
+	summary, _ := a.rollbackTransaction(ctx, fmt.Sprintf("%s failed", toolName))
+	return "\n\n" + summary
+}
+
+// rollbackOpenTransaction rolls back a transaction left open when the run ends
+func (a *Agent) rollbackOpenTransaction(ctx context.Context) {
+	if !a.toolTransactions {
+		return
+	}
+	if _, rolledBack := a.rollbackTransaction(ctx, "run ended before commit_transaction"); rolledBack {
+		getLogger(a).Warnf("Rolled back tool transaction left open at the end of the run")
+	}
+}
+
+// rollbackTransaction runs the compensations of the open transaction's completed steps in reverse
+// order and emits a rollback event; returns false when no transaction is open
+func (a *Agent) rollbackTransaction(ctx context.Context, reason string) (string, bool) {
+	a.transactionState.mu.Lock()
+	tx := a.transactionState.active
+	a.transactionState.active = nil
+	a.transactionState.mu.Unlock()
+	if tx == nil {
+		return "", false
+	}
+
+	rollbackEvent := events.NewToolTransactionEvent("rollback", tx.id, tx.name, tx.stepNames())
+	rollbackEvent.Reason = reason
+	var notCompensated []string
+	for i := len(tx.steps) - 1; i >= 0; i-- {
+		step := tx.steps[i]
+		compensate, exists := a.toolCompensations[step.tool]
+		if !exists {
+			notCompensated = append(notCompensated, step.tool)
+			continue
+		}
+		if err := compensate(ctx, step.args, step.result); err != nil {
+			getLogger(a).Errorf("Compensation for tool %s in transaction %s failed: %v", step.tool, tx.id, err)
+			rollbackEvent.CompensationErrors = append(rollbackEvent.CompensationErrors, fmt.Sprintf("%s: %v", step.tool, err))
+			continue
+		}
+		rollbackEvent.Compensated = append(rollbackEvent.Compensated, step.tool)
+	}
+	a.EmitTypedEvent(ctx, rollbackEvent)
+
+	summary := fmt.Sprintf("Transaction %s rolled back (%s).", tx.id, reason)
+	if len(rollbackEvent.Compensated) > 0 {
+		summary += " Undone: " + strings.Join(rollbackEvent.Compensated, ", ") + "."
+	}
+	if len(rollbackEvent.CompensationErrors) > 0 {
+		summary += " Compensation failed: " + strings.Join(rollbackEvent.CompensationErrors, "; ") + "."
+	}
+	if len(notCompensated) > 0 {
+		summary += " No compensation registered (not undone): " + strings.Join(notCompensated, ", ") + "."
+	}
+	return summary, true
+}
+
+func (tx *toolTransaction) stepNames() []string {
+	names := make([]string, 0, len(tx.steps))
+	for _, step := range tx.steps {
+		names = append(names, step.tool)
+	}
+	return names
+}
@@ -0,0 +1,58 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+func TestToolTransactionCompensatesEarlierStepOnFailure(t *testing.T) {
+	llm := sequentialToolLLM("bucket setup failed and was undone",
+		llmtypes.FunctionCall{Name: BeginTransactionTool, Arguments: `{"name": "provision bucket"}`},
+		llmtypes.FunctionCall{Name: "create_bucket", Arguments: `{"bucket": "logs"}`},
+		llmtypes.FunctionCall{Name: "configure_bucket", Arguments: `{"bucket": "logs", "versioning": true}`},
+	)
+	var compensatedArgs map[string]interface{}
+	var compensatedResult string
+	a := newTestAgent(t, llm, WithToolTransactions(true), WithToolCompensation("create_bucket", func(ctx context.Context, args map[string]interface{}, result string) error {
+		compensatedArgs, compensatedResult = args, result
+		return nil
+	}))
+	a.RegisterCustomTool("create_bucket", "Create a bucket", map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return "created bucket arn:aws:s3:::logs", nil
+	})
+	a.RegisterCustomTool("configure_bucket", "Configure a bucket", map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return "", fmt.Errorf("access denied")
+	})
+	a.registerTransactionTools()
+	transactions := collectEvents[*events.ToolTransactionEvent](a)
+
+	answer, err := a.Ask(context.Background(), "set up a versioned logs bucket")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if answer != "bucket setup failed and was undone" {
+		t.Fatalf("unexpected answer %q", answer)
+	}
+
+	if compensatedArgs["bucket"] != "logs" || compensatedResult != "created bucket arn:aws:s3:::logs" {
+		t.Fatalf("expected create_bucket compensated with its original args and result, got %v %q", compensatedArgs, compensatedResult)
+	}
+	if result := llm.lastToolResult(); !strings.Contains(result, "rolled back") || !strings.Contains(result, "Undone: create_bucket") {
+		t.Fatalf("expected the failed call's result to report the rollback, got %q", result)
+	}
+
+	recorded := transactions.all()
+	if len(recorded) != 2 || recorded[0].GetEventType() != events.ToolTransactionBegin {
+		t.Fatalf("expected begin and rollback events, got %+v", recorded)
+	}
+	rollback := recorded[1]
+	if rollback.GetEventType() != events.ToolTransactionRollback || rollback.Reason != "configure_bucket failed" ||
+		len(rollback.Compensated) != 1 || rollback.Compensated[0] != "create_bucket" || rollback.TransactionID != recorded[0].TransactionID {
+		t.Fatalf("unexpected rollback event: %+v", rollback)
+	}
+}
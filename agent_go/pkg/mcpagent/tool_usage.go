@@ -0,0 +1,151 @@
+package mcpagent
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// ToolUsageRecord is the tool set advertised to the LLM during one run and how often each tool was invoked
+type ToolUsageRecord struct {
+	SessionID  string         `json:"session_id"`
+	TraceID    string         `json:"trace_id"`
+	Timestamp  time.Time      `json:"timestamp"`
+	Advertised []string       `json:"advertised"`
+	Invoked    map[string]int `json:"invoked"` // Tool name -> number of calls
+}
+
+// ToolUsageRecorder receives one record per completed run
+type ToolUsageRecorder interface {
+	RecordToolUsage(ctx context.Context, record *ToolUsageRecord) error
+}
+
+// ToolUsageStats is the rollup of one tool across all recorded runs
+type ToolUsageStats struct {
+	Tool           string `json:"tool"`
+	AdvertisedRuns int    `json:"advertised_runs"`
+	InvokedRuns    int    `json:"invoked_runs"`
+	Calls          int    `json:"calls"`
+}
+
+// ToolUsageRollup aggregates tool usage records in memory to find tools that are advertised but never used
+type ToolUsageRollup struct {
+	mu    sync.RWMutex
+	runs  int
+	tools map[string]*ToolUsageStats
+}
+
+// NewToolUsageRollup creates an empty tool usage rollup
+func NewToolUsageRollup() *ToolUsageRollup {
+	return &ToolUsageRollup{tools: make(map[string]*ToolUsageStats)}
+}
+
+// RecordToolUsage adds one run to the rollup
+func (r *ToolUsageRollup) RecordToolUsage(ctx context.Context, record *ToolUsageRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.runs++
+	for _, name := range record.Advertised {
+		r.stats(name).AdvertisedRuns++
+	}
+	for name, calls := range record.Invoked {
+		stats := r.stats(name)
+		stats.InvokedRuns++
+		stats.Calls += calls
+	}
+	return nil
+}
+
+// stats returns the entry for a tool, creating it if needed; callers must hold r.mu
+func (r *ToolUsageRollup) stats(name string) *ToolUsageStats {
+	stats, exists := r.tools[name]
+	if !exists {
+		stats = &ToolUsageStats{Tool: name}
+		r.tools[name] = stats
+	}
+	return stats
+}
+
+// Runs returns how many runs were recorded
+func (r *ToolUsageRollup) Runs() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.runs
+}
+
+// Stats returns the rollup of every tool seen, sorted by name
+func (r *ToolUsageRollup) Stats() []ToolUsageStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make([]ToolUsageStats, 0, len(r.tools))
+	for _, s := range r.tools {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Tool < stats[j].Tool })
+	return stats
+}
+
+// UnusedTools returns tools that were advertised but never invoked, most often advertised first.
+// These are candidates to drop from smart-routing.
+func (r *ToolUsageRollup) UnusedTools() []ToolUsageStats {
+	var unused []ToolUsageStats
+	for _, s := range r.Stats() {
+		if s.AdvertisedRuns > 0 && s.InvokedRuns == 0 {
+			unused = append(unused, s)
+		}
+	}
+	sort.SliceStable(unused, func(i, j int) bool { return unused[i].AdvertisedRuns > unused[j].AdvertisedRuns })
+	return unused
+}
+
+// WithToolUsageRecorder records, per run, the advertised tool set and which tools were invoked
+func WithToolUsageRecorder(recorder ToolUsageRecorder) AgentOption {
+	return func(a *Agent) {
+		a.toolUsageRecorder = recorder
+	}
+}
+
+// recordToolUsage reports the run's advertised tools and the tool calls made after the first
+// startIndex messages (earlier messages belong to previous runs)
+func (a *Agent) recordToolUsage(ctx context.Context, messages []llmtypes.MessageContent, startIndex int) {
+	if a.toolUsageRecorder == nil {
+		return
+	}
+
+	advertised := make([]string, 0, len(a.filteredTools))
+	for _, tool := range a.filteredTools {
+		if tool.Function != nil {
+			advertised = append(advertised, tool.Function.Name)
+		}
+	}
+	invoked := make(map[string]int)
+	if startIndex < len(messages) {
+		for _, msg := range messages[startIndex:] {
+			for _, part := range msg.Parts {
+				if call, ok := part.(llmtypes.ToolCall); ok && call.FunctionCall != nil && call.FunctionCall.Name != "" {
+					invoked[call.FunctionCall.Name]++
+				}
+			}
+		}
+	}
+
+	sessionID := string(a.TraceID)
+	if ctxSessionID, ok := ctx.Value("session_id").(string); ok && ctxSessionID != "" {
+		sessionID = ctxSessionID
+	}
+	record := &ToolUsageRecord{
+		SessionID:  sessionID,
+		TraceID:    string(a.TraceID),
+		Timestamp:  time.Now(),
+		Advertised: advertised,
+		Invoked:    invoked,
+	}
+	if err := a.toolUsageRecorder.RecordToolUsage(ctx, record); err != nil {
+		getLogger(a).Warnf("⚠️ Failed to record tool usage: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+)
+
+// bucketLLM calls list_buckets once per run, then answers
+func bucketLLM() *fakeLLM {
+	return &fakeLLM{respond: func(call int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		if last := messages[len(messages)-1]; last.Role != llmtypes.ChatMessageTypeTool {
+			return toolCallResponse(toolCall("call-1", "list_buckets", "{}")), nil
+		}
+		return textResponse("logs, backups"), nil
+	}}
+}
+
+func toolDefinition(name string) llmtypes.Tool {
+	return llmtypes.Tool{Type: "function", Function: &llmtypes.FunctionDefinition{Name: name}}
+}
+
+func TestToolUsageRollupFindsAdvertisedButUnusedTools(t *testing.T) {
+	rollup := NewToolUsageRollup()
+	a := newTestAgent(t, bucketLLM(), WithToolUsageRecorder(rollup))
+	a.Tools = []llmtypes.Tool{toolDefinition("list_buckets"), toolDefinition("delete_bucket"), toolDefinition("get_billing")}
+	a.customTools = map[string]CustomTool{
+		"list_buckets": {Execution: func(ctx context.Context, args map[string]interface{}) (string, error) {
+			return "logs, backups", nil
+		}},
+	}
+
+	history := []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "list my buckets"}}},
+	}
+	_, history, err := AskWithHistory(a, context.Background(), history)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The second run continues the conversation; calls from the first run must not be counted again
+	history = append(history, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "list them again"}}})
+	if _, _, err := AskWithHistory(a, context.Background(), history); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rollup.Runs() != 2 {
+		t.Fatalf("expected 2 recorded runs, got %d", rollup.Runs())
+	}
+	unused := rollup.UnusedTools()
+	if len(unused) != 2 || unused[0].Tool != "delete_bucket" || unused[1].Tool != "get_billing" {
+		t.Fatalf("expected delete_bucket and get_billing unused, got %+v", unused)
+	}
+	if unused[0].AdvertisedRuns != 2 {
+		t.Fatalf("expected unused tools advertised in both runs, got %+v", unused[0])
+	}
+	for _, stats := range rollup.Stats() {
+		if stats.Tool == "list_buckets" && (stats.InvokedRuns != 2 || stats.Calls != 2) {
+			t.Fatalf("expected list_buckets invoked once per run, got %+v", stats)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package mcpagent
+
+import (
+	"context"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// WithTurnLatencyBreakdown enables or disables the per-turn latency breakdown (enabled by default)
+func WithTurnLatencyBreakdown(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.turnLatencyDisabled = !enabled
+	}
+}
+
+// TurnLatencyBreakdown is where the time of a single conversation turn went
+type TurnLatencyBreakdown struct {
+	Turn      int           `json:"turn"`
+	Total     time.Duration `json:"total"`
+	LLM       time.Duration `json:"llm"`
+	Tools     time.Duration `json:"tools"`
+	Overhead  time.Duration `json:"overhead"`
+	ToolCalls int           `json:"tool_calls"`
+}
+
+// LatencySummary aggregates the latency breakdown over all turns of a conversation
+type LatencySummary struct {
+	Turns     int           `json:"turns"`
+	Total     time.Duration `json:"total"`
+	LLM       time.Duration `json:"llm"`
+	Tools     time.Duration `json:"tools"`
+	Overhead  time.Duration `json:"overhead"`
+	ToolCalls int           `json:"tool_calls"`
+}
+
+// Metadata returns the summary in milliseconds for completion event metadata
+func (s LatencySummary) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"turns":       s.Turns,
+		"total_ms":    s.Total.Milliseconds(),
+		"llm_ms":      s.LLM.Milliseconds(),
+		"tools_ms":    s.Tools.Milliseconds(),
+		"overhead_ms": s.Overhead.Milliseconds(),
+		"tool_calls":  s.ToolCalls,
+	}
+}
+
+// turnLatencyTracker accumulates LLM and tool time within one turn
+type turnLatencyTracker struct {
+	start     time.Time
+	llm       time.Duration
+	tools     time.Duration
+	toolCalls int
+}
+
+func newTurnLatencyTracker() *turnLatencyTracker {
+	return &turnLatencyTracker{start: time.Now()}
+}
+
+func (t *turnLatencyTracker) addLLM(d time.Duration) {
+	t.llm += d
+}
+
+func (t *turnLatencyTracker) addTool(d time.Duration) {
+	t.tools += d
+	t.toolCalls++
+}
+
+// breakdown attributes whatever is not LLM or tool time to overhead
+func (t *turnLatencyTracker) breakdown(turn int) TurnLatencyBreakdown {
+	total := time.Since(t.start)
+	overhead := total - t.llm - t.tools
+	if overhead < 0 {
+		overhead = 0
+	}
+	return TurnLatencyBreakdown{
+		Turn:      turn,
+		Total:     total,
+		LLM:       t.llm,
+		Tools:     t.tools,
+		Overhead:  overhead,
+		ToolCalls: t.toolCalls,
+	}
+}
+
+// finishTurnLatency emits the turn's latency breakdown and adds it to the conversation summary
+func (a *Agent) finishTurnLatency(ctx context.Context, turn int, tracker *turnLatencyTracker) {
+	if a.turnLatencyDisabled || tracker == nil {
+		return
+	}
+
+	b := tracker.breakdown(turn)
+	a.latencyMu.Lock()
+	a.latencySummary.Turns++
+	a.latencySummary.Total += b.Total
+	a.latencySummary.LLM += b.LLM
+	a.latencySummary.Tools += b.Tools
+	a.latencySummary.Overhead += b.Overhead
+	a.latencySummary.ToolCalls += b.ToolCalls
+	a.latencyMu.Unlock()
+
+	a.EmitTypedEvent(ctx, events.NewTurnLatencyEvent(b.Turn, b.Total, b.LLM, b.Tools, b.Overhead, b.ToolCalls))
+}
+
+// resetLatencySummary clears the summary at the start of a conversation
+func (a *Agent) resetLatencySummary() {
+	a.latencyMu.Lock()
+	a.latencySummary = LatencySummary{}
+	a.latencyMu.Unlock()
+}
+
+// GetLatencySummary returns the latency breakdown aggregated over the turns of the last conversation
+func (a *Agent) GetLatencySummary() LatencySummary {
+	a.latencyMu.Lock()
+	defer a.latencyMu.Unlock()
+	return a.latencySummary
+}
+
+// withLatencySummary attaches the latency summary to a completion event's metadata
+func (a *Agent) withLatencySummary(event *events.UnifiedCompletionEvent) *events.UnifiedCompletionEvent {
+	if a.turnLatencyDisabled {
+		return event
+	}
+	if event.Metadata == nil {
+		event.Metadata = make(map[string]interface{})
+	}
+	event.Metadata["latency_breakdown"] = a.GetLatencySummary().Metadata()
+	return event
+}
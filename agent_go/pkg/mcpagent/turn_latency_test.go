@@ -0,0 +1,98 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+const (
+	mockLLMDelay  = 30 * time.Millisecond
+	mockToolDelay = 50 * time.Millisecond
+)
+
+// newLatencyTestAgent returns an agent on llm whose slow_tool takes mockToolDelay
+func newLatencyTestAgent(t *testing.T, llm llmtypes.Model, options ...AgentOption) *Agent {
+	t.Helper()
+	a := newTestAgent(t, llm, options...)
+	a.MaxTurns = 3
+	a.customTools = map[string]CustomTool{
+		"slow_tool": {Execution: func(ctx context.Context, args map[string]interface{}) (string, error) {
+			time.Sleep(mockToolDelay)
+			return "ok", nil
+		}},
+	}
+	return a
+}
+
+func TestTurnLatencyBreakdownSumsToTurnDuration(t *testing.T) {
+	a := newLatencyTestAgent(t, slowToolLLM(mockLLMDelay))
+	latencies := collectEvents[*events.TurnLatencyEvent](a)
+	completions := collectEvents[*events.UnifiedCompletionEvent](a)
+
+	answer, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "run the slow tool"}}},
+	})
+	if err != nil || answer != "done" {
+		t.Fatalf("unexpected result: %q, %v", answer, err)
+	}
+
+	turns := latencies.all()
+	if len(turns) != 2 {
+		t.Fatalf("expected 2 turn latency events, got %d", len(turns))
+	}
+
+	for _, turn := range turns {
+		sum := turn.LLMMs + turn.ToolsMs + turn.OverheadMs
+		// Millisecond truncation of each component can lose up to 1ms apiece
+		if diff := turn.TotalMs - sum; diff < 0 || diff > 3 {
+			t.Errorf("turn %d breakdown %d+%d+%d=%d does not match total %d", turn.Turn, turn.LLMMs, turn.ToolsMs, turn.OverheadMs, sum, turn.TotalMs)
+		}
+		if turn.LLMMs < mockLLMDelay.Milliseconds() {
+			t.Errorf("turn %d expected at least %dms of LLM time, got %d", turn.Turn, mockLLMDelay.Milliseconds(), turn.LLMMs)
+		}
+	}
+
+	first, second := turns[0], turns[1]
+	if first.ToolCalls != 1 || first.ToolsMs < mockToolDelay.Milliseconds() {
+		t.Errorf("expected first turn to attribute %dms to 1 tool call, got %dms over %d calls", mockToolDelay.Milliseconds(), first.ToolsMs, first.ToolCalls)
+	}
+	if second.ToolCalls != 0 || second.ToolsMs != 0 {
+		t.Errorf("expected no tool time on the final turn, got %+v", second)
+	}
+
+	summary := a.GetLatencySummary()
+	if summary.Turns != 2 || summary.ToolCalls != 1 || summary.Total != summary.LLM+summary.Tools+summary.Overhead {
+		t.Errorf("unexpected latency summary: %+v", summary)
+	}
+	completion := completions.last()
+	if completion == nil {
+		t.Fatalf("expected a completion event")
+	}
+	breakdown, ok := completion.Metadata["latency_breakdown"].(map[string]interface{})
+	if !ok || breakdown["turns"] != 2 || breakdown["tools_ms"] != summary.Tools.Milliseconds() {
+		t.Errorf("expected latency summary in completion metadata, got %v", completion.Metadata)
+	}
+}
+
+func TestTurnLatencyBreakdownDisabled(t *testing.T) {
+	a := newLatencyTestAgent(t, slowToolLLM(mockLLMDelay), WithTurnLatencyBreakdown(false))
+	latencies := collectEvents[*events.TurnLatencyEvent](a)
+	completions := collectEvents[*events.UnifiedCompletionEvent](a)
+
+	if _, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "run the slow tool"}}},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if turns := latencies.all(); len(turns) != 0 {
+		t.Fatalf("expected no turn latency events when disabled, got %d", len(turns))
+	}
+	if _, ok := completions.last().Metadata["latency_breakdown"]; ok {
+		t.Fatalf("expected no latency summary when disabled")
+	}
+}
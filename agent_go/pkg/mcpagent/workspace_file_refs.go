@@ -0,0 +1,113 @@
+package mcpagent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultWorkspaceFileMaxBytes bounds how much of each referenced workspace file is inlined
+	DefaultWorkspaceFileMaxBytes = 16 * 1024
+	// maxInlinedWorkspaceFiles bounds how many referenced files are inlined per tool result
+	maxInlinedWorkspaceFiles = 3
+)
+
+// workspaceFileRefPattern matches path-like tokens with a file extension, e.g. "reports/q3.md"
+var workspaceFileRefPattern = regexp.MustCompile(`[\w./\\-]*\w\.[A-Za-z0-9]{1,8}\b`)
+
+// WithWorkspaceFileReferences makes tool results that reference files under root carry the
+// file content, so the model can use it in later turns instead of seeing only a path.
+// Each file is truncated to maxBytes (0 uses DefaultWorkspaceFileMaxBytes).
+func WithWorkspaceFileReferences(root string, maxBytes int) AgentOption {
+	return func(a *Agent) {
+		a.workspaceFileRoot = root
+		a.workspaceFileMaxBytes = maxBytes
+	}
+}
+
+// inlineWorkspaceFileReferences appends the content of workspace files referenced in a tool result
+func (a *Agent) inlineWorkspaceFileReferences(resultText string) string {
+	if a.workspaceFileRoot == "" {
+		return resultText
+	}
+	maxBytes := a.workspaceFileMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultWorkspaceFileMaxBytes
+	}
+
+	var builder strings.Builder
+	seen := make(map[string]bool)
+	inlined := 0
+	for _, ref := range workspaceFileRefPattern.FindAllString(resultText, -1) {
+		if inlined >= maxInlinedWorkspaceFiles {
+			break
+		}
+		path, ok := resolveWorkspacePath(a.workspaceFileRoot, ref)
+		if !ok || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		content, truncated, err := readFileHead(path, maxBytes)
+		if err != nil {
+			getLogger(a).Warnf("Failed to read referenced workspace file %s: %v", ref, err)
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("\n\n--- Content of workspace file %s ---\n%s", ref, content))
+		if truncated {
+			builder.WriteString(fmt.Sprintf("\n[truncated to the first %d bytes]", maxBytes))
+		}
+		inlined++
+	}
+
+	if inlined == 0 {
+		return resultText
+	}
+	getLogger(a).Infof("📎 Inlined %d referenced workspace file(s) into tool result", inlined)
+	return resultText + builder.String()
+}
+
+// resolveWorkspacePath maps a referenced path to a regular file inside root, rejecting anything outside it
+func resolveWorkspacePath(root, ref string) (string, bool) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", false
+	}
+	path := filepath.FromSlash(ref)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(absRoot, path)
+	}
+	path = filepath.Clean(path)
+
+	rel, err := filepath.Rel(absRoot, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return path, true
+}
+
+// readFileHead reads at most maxBytes of a file and reports whether it was cut short
+func readFileHead(path string, maxBytes int) (string, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, maxBytes+1)
+	n, err := file.Read(buf)
+	if err != nil && n == 0 {
+		return "", false, err
+	}
+	if n > maxBytes {
+		return string(buf[:maxBytes]), true, nil
+	}
+	return string(buf[:n]), false, nil
+}
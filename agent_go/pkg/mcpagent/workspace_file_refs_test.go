@@ -0,0 +1,75 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+func askWithReportTool(t *testing.T, root string, options ...AgentOption) string {
+	t.Helper()
+	a := newTestAgent(t, echoToolLLM("write_report"), options...)
+	a.customTools = map[string]CustomTool{
+		"write_report": {Execution: func(ctx context.Context, args map[string]interface{}) (string, error) {
+			if err := os.MkdirAll(filepath.Join(root, "reports"), 0755); err != nil {
+				return "", err
+			}
+			if err := os.WriteFile(filepath.Join(root, "reports", "q3.md"), []byte("Revenue grew 12% in Q3."), 0644); err != nil {
+				return "", err
+			}
+			return "Report written to reports/q3.md", nil
+		}},
+	}
+
+	answer, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "write the Q3 report"}}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return answer
+}
+
+func TestReferencedWorkspaceFileIsReadableInLaterTurn(t *testing.T) {
+	root := t.TempDir()
+	answer := askWithReportTool(t, root, WithWorkspaceFileReferences(root, 0))
+
+	if !strings.Contains(answer, "Report written to reports/q3.md") || !strings.Contains(answer, "Revenue grew 12% in Q3.") {
+		t.Fatalf("expected file content in the tool result seen on the next turn, got %q", answer)
+	}
+}
+
+func TestReferencedWorkspaceFileNotInlinedByDefault(t *testing.T) {
+	answer := askWithReportTool(t, t.TempDir())
+	if answer != "Report written to reports/q3.md" {
+		t.Fatalf("expected the plain tool result, got %q", answer)
+	}
+}
+
+func TestInlineWorkspaceFileReferencesLimits(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "big.txt"), []byte(strings.Repeat("a", 100)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(filepath.Dir(root), "secret.txt")
+	if err := os.WriteFile(outside, []byte("do not leak"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(outside)
+
+	testLogger := logger.CreateDiscardLogger("error")
+	a := &Agent{Logger: testLogger, workspaceFileRoot: root, workspaceFileMaxBytes: 10}
+	result := a.inlineWorkspaceFileReferences("wrote big.txt, see also ../secret.txt and " + outside)
+
+	if !strings.Contains(result, strings.Repeat("a", 10)+"\n[truncated to the first 10 bytes]") || strings.Contains(result, strings.Repeat("a", 11)) {
+		t.Fatalf("expected big.txt truncated to 10 bytes, got %q", result)
+	}
+	if strings.Contains(result, "do not leak") {
+		t.Fatalf("expected files outside the workspace to be ignored, got %q", result)
+	}
+}
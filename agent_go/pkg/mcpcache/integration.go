@@ -8,6 +8,7 @@ import (
 
 	"mcp-agent/agent_go/internal/observability"
 	"mcp-agent/agent_go/internal/utils"
+	"mcp-agent/agent_go/pkg/events"
 	"mcp-agent/agent_go/pkg/mcpclient"
 
 	"mcp-agent/agent_go/internal/llmtypes"
@@ -376,6 +377,14 @@ func GetCachedOrFreshConnection(
 				}
 			}
 
+			// Entries discovered for an older config or server version can never be hit again
+			reason := "not_found"
+			if !cacheManager.Enabled() {
+				reason = "disabled"
+			} else if superseded := cacheManager.InvalidateSuperseded(srvName, cacheKey, serverConfig.Version); superseded != "" {
+				reason = superseded
+			}
+
 			// Track cache miss status (no individual event emission)
 			serverStatus[srvName] = ServerCacheStatus{
 				ServerName:     srvName,
@@ -384,7 +393,7 @@ func GetCachedOrFreshConnection(
 				ToolsCount:     0,
 				PromptsCount:   0,
 				ResourcesCount: 0,
-				Reason:         "not_found",
+				Reason:         reason,
 			}
 
 			missedServers = append(missedServers, srvName)
@@ -706,6 +715,7 @@ func performOriginalConnectionLogic(
 	// Log discovery start (events handled by connection.go)
 
 	parallelResults := mcpclient.DiscoverAllToolsParallel(ctx, filteredConfig, logger)
+	emitServerDiscoveryEvents(parallelResults, traceID, tracers, logger)
 
 	discoveryDuration := time.Since(discoveryStartTime)
 	logger.Info("✅ Parallel tool discovery completed", map[string]interface{}{
@@ -907,6 +917,14 @@ func cacheFreshConnectionData(
 			TTLMinutes:   cacheManager.GetTTL(), // Use configured TTL instead of hardcoded 30 minutes
 			Protocol:     string(serverConfig.Protocol),
 			IsValid:      true,
+
+			ServerVersion: serverConfig.Version,
+		}
+		// Record the name and version the server reported at initialization
+		if client, exists := result.Clients[srvName]; exists && client != nil {
+			if info := client.GetServerInfo(); info != nil {
+				entry.ServerInfo = map[string]interface{}{"name": info.Name, "version": info.Version}
+			}
 		}
 
 		// Store in cache using configuration-aware cache key
@@ -1143,4 +1161,24 @@ func EmitComprehensiveCacheEvent(
 	}
 }
 
+// emitServerDiscoveryEvents emits one MCPServerDiscovery event per discovered server with its timing
+func emitServerDiscoveryEvents(results []mcpclient.ParallelToolDiscoveryResult, traceID string, tracers []observability.Tracer, logger utils.ExtendedLogger) {
+	if len(tracers) == 0 || !mcpclient.DiscoveryMetricsEnabled() {
+		return
+	}
+
+	for _, result := range results {
+		event := events.NewAgentEvent(result.DiscoveryEvent())
+		event.TraceID = traceID
+		event.CorrelationID = fmt.Sprintf("discovery-%s-%s", result.ServerName, traceID)
+
+		for _, tracer := range tracers {
+			if err := tracer.EmitEvent(event); err != nil {
+				// Discovery continues; the event is only lost for this tracer
+				logger.Warnf("Failed to emit discovery event for %s: %v", result.ServerName, err)
+			}
+		}
+	}
+}
+
 // Individual cache event functions removed - only comprehensive cache events are used
@@ -38,6 +38,8 @@ type CacheEntry struct {
 	TTLMinutes   int                    `json:"ttl_minutes"`
 	Protocol     string                 `json:"protocol"`
 	ServerInfo   map[string]interface{} `json:"server_info,omitempty"`
+	// Declared server version (MCPServerConfig.Version) the entry was discovered for
+	ServerVersion string `json:"server_version,omitempty"`
 
 	// Cache management
 	IsValid      bool   `json:"is_valid"`
@@ -62,6 +64,7 @@ func (ce *CacheEntry) UpdateAccessTime() {
 type CacheManager struct {
 	cacheDir   string
 	ttlMinutes int
+	enabled    bool // MCP_CACHE_ENABLED; when false discovery always runs and nothing is written
 	logger     utils.ExtendedLogger
 	mu         sync.RWMutex
 	cache      map[string]*CacheEntry // cache key -> entry
@@ -96,28 +99,49 @@ func GetCacheManager(logger utils.ExtendedLogger) *CacheManager {
 			}
 		}
 
-		instance = &CacheManager{
-			cacheDir:   cacheDir,
-			ttlMinutes: ttlMinutes, // Configurable TTL via environment variable
-			logger:     logger,
-			cache:      make(map[string]*CacheEntry),
+		// Disk caching of tool discovery is on unless MCP_CACHE_ENABLED=false
+		enabled := os.Getenv("MCP_CACHE_ENABLED") != "false"
+
+		instance = newCacheManager(cacheDir, ttlMinutes, enabled, logger)
+	})
+	return instance
+}
+
+// newCacheManager creates a cache manager and loads the entries persisted in cacheDir
+func newCacheManager(cacheDir string, ttlMinutes int, enabled bool, logger utils.ExtendedLogger) *CacheManager {
+	cm := &CacheManager{
+		cacheDir:   cacheDir,
+		ttlMinutes: ttlMinutes, // Configurable TTL via environment variable
+		enabled:    enabled,
+		logger:     logger,
+		cache:      make(map[string]*CacheEntry),
+	}
+	if !enabled {
+		if logger != nil {
+			logger.Infof("MCP discovery cache disabled (MCP_CACHE_ENABLED=false)")
 		}
+		return cm
+	}
 
-		// Initialize cache directory
-		if err := os.MkdirAll(cacheDir, 0755); err != nil {
-			if logger != nil {
-				logger.Warnf("Failed to create cache directory %s: %v", cacheDir, err)
-			}
+	// Initialize cache directory
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		if logger != nil {
+			logger.Warnf("Failed to create cache directory %s: %v", cacheDir, err)
 		}
+	}
 
-		// Load existing cache entries
-		instance.loadExistingCache()
-	})
-	return instance
+	// Load existing cache entries
+	cm.loadExistingCache()
+	return cm
+}
+
+// Enabled reports whether discovered tools are cached on disk
+func (cm *CacheManager) Enabled() bool {
+	return cm.enabled
 }
 
 // GenerateServerConfigHash creates a hash of the server configuration
-// This includes command, args, env vars, URL, headers, and protocol
+// This includes command, args, env vars, URL, headers, protocol and the declared server version
 func GenerateServerConfigHash(config mcpclient.MCPServerConfig) string {
 	// Create a deterministic representation of the config
 	configData := struct {
@@ -127,6 +151,7 @@ func GenerateServerConfigHash(config mcpclient.MCPServerConfig) string {
 		URL      string            `json:"url"`
 		Headers  map[string]string `json:"headers"`
 		Protocol string            `json:"protocol"`
+		Version  string            `json:"version,omitempty"` // Omitted when unset so existing keys stay valid
 	}{
 		Command:  config.Command,
 		Args:     config.Args,
@@ -134,6 +159,7 @@ func GenerateServerConfigHash(config mcpclient.MCPServerConfig) string {
 		URL:      config.URL,
 		Headers:  config.Headers,
 		Protocol: string(config.Protocol),
+		Version:  config.Version,
 	}
 
 	// Sort maps for deterministic output
@@ -221,6 +247,9 @@ func (cm *CacheManager) Get(cacheKey string) (*CacheEntry, bool) {
 
 // Put stores a cache entry using configuration-aware cache key
 func (cm *CacheManager) Put(entry *CacheEntry, config mcpclient.MCPServerConfig) error {
+	if !cm.enabled {
+		return nil
+	}
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
@@ -252,6 +281,34 @@ func (cm *CacheManager) Invalidate(cacheKey string) error {
 	return nil
 }
 
+// InvalidateSuperseded removes the server's entries stored under a key other than currentKey, i.e. entries
+// discovered for an older configuration or server version. Returns the reason: "version_changed" when
+// a removed entry was discovered for a different declared version, "config_changed" otherwise, "" if none.
+func (cm *CacheManager) InvalidateSuperseded(serverName, currentKey, version string) string {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	reason := ""
+	for key, entry := range cm.cache {
+		if entry.ServerName != serverName || key == currentKey {
+			continue
+		}
+		if entry.ServerVersion != version {
+			reason = "version_changed"
+		} else if reason == "" {
+			reason = "config_changed"
+		}
+		delete(cm.cache, key)
+		if err := os.Remove(cm.getCacheFilePath(key)); err != nil && !os.IsNotExist(err) {
+			cm.logger.Warnf("Failed to remove superseded cache file for %s: %v", serverName, err)
+		}
+	}
+	if reason != "" {
+		cm.logger.Infof("Invalidated superseded cache entries for server %s (%s)", serverName, reason)
+	}
+	return reason
+}
+
 // InvalidateByServer invalidates all cache entries for a specific server
 func (cm *CacheManager) InvalidateByServer(configPath, serverName string) error {
 	cm.mu.Lock()
@@ -465,6 +522,9 @@ func (cm *CacheManager) loadFromFile(cacheFile string) *CacheEntry {
 
 // ReloadFromDisk reloads a specific cache entry from disk and updates the in-memory cache
 func (cm *CacheManager) ReloadFromDisk(cacheKey string) *CacheEntry {
+	if !cm.enabled {
+		return nil
+	}
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
@@ -0,0 +1,102 @@
+package mcpcache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/internal/observability"
+	"mcp-agent/agent_go/internal/utils"
+	"mcp-agent/agent_go/pkg/logger"
+	"mcp-agent/agent_go/pkg/mcpclient"
+)
+
+// cacheEventRecorder collects comprehensive cache events
+type cacheEventRecorder struct {
+	observability.NoopTracer
+	mu     sync.Mutex
+	events []*ComprehensiveCacheEvent
+}
+
+func (r *cacheEventRecorder) EmitEvent(event observability.AgentEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cacheEvent, ok := event.(*ComprehensiveCacheEvent); ok {
+		r.events = append(r.events, cacheEvent)
+	}
+	return nil
+}
+
+func (r *cacheEventRecorder) last() *ComprehensiveCacheEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events[len(r.events)-1]
+}
+
+func writeServerConfig(t *testing.T, path, version string) *mcpclient.MCPConfig {
+	t.Helper()
+	config := &mcpclient.MCPConfig{MCPServers: map[string]mcpclient.MCPServerConfig{
+		"aws": {Command: "aws-mcp-server", Args: []string{"--stdio"}, Version: version},
+	}}
+	if err := mcpclient.SaveConfig(path, config); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return config
+}
+
+// useCacheManager installs a cache manager reading cacheDir as the singleton, as on a cold start
+func useCacheManager(t *testing.T, cacheDir string, log utils.ExtendedLogger) *CacheManager {
+	t.Helper()
+	once.Do(func() {})
+	previous := instance
+	instance = newCacheManager(cacheDir, 60, true, log)
+	t.Cleanup(func() { instance = previous })
+	return instance
+}
+
+func TestDiscoveryServedFromDiskCacheForUnchangedServer(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	configPath := filepath.Join(dir, "mcp_servers.json")
+	config := writeServerConfig(t, configPath, "1.4.0")
+
+	// First discovery connects to the server and persists what it found
+	discovered := &CachedConnectionResult{
+		Clients:      map[string]mcpclient.ClientInterface{},
+		ToolToServer: map[string]string{"list_buckets": "aws"},
+		Tools: []llmtypes.Tool{{Type: "function", Function: &llmtypes.FunctionDefinition{
+			Name: "list_buckets", Parameters: llmtypes.NewParameters(map[string]interface{}{"type": "object"}),
+		}}},
+	}
+	cacheFreshConnectionData(useCacheManager(t, cacheDir, testLogger), config, configPath, []string{"aws"}, discovered, nil, testLogger)
+
+	// After a restart the second discovery is served from disk without connecting
+	useCacheManager(t, cacheDir, testLogger)
+	recorder := &cacheEventRecorder{}
+	result, err := GetCachedOrFreshConnection(context.Background(), nil, "aws", configPath, []observability.Tracer{recorder}, testLogger, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.CacheUsed || len(result.Clients) != 0 || len(result.Tools) != 1 || result.ToolToServer["list_buckets"] != "aws" {
+		t.Fatalf("expected cached tools without connections, got %+v", result)
+	}
+	if event := recorder.last(); event.CacheHits != 1 || event.ServerStatus["aws"].Status != "hit" {
+		t.Fatalf("expected a cache hit event, got %+v", event)
+	}
+
+	// A new server version invalidates the entry discovered for the old one
+	writeServerConfig(t, configPath, "1.5.0")
+	if _, err := GetCachedOrFreshConnection(context.Background(), nil, "aws", configPath, []observability.Tracer{recorder}, testLogger, true); err == nil {
+		t.Fatalf("expected no cached data for the new server version")
+	}
+	if status := recorder.last().ServerStatus["aws"]; status.Status != "miss" || status.Reason != "version_changed" {
+		t.Fatalf("expected a version_changed miss, got %+v", status)
+	}
+	if files, _ := os.ReadDir(cacheDir); len(files) != 0 {
+		t.Fatalf("expected the superseded cache file removed, found %d files", len(files))
+	}
+}
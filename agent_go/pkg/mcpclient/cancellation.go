@@ -0,0 +1,105 @@
+package mcpclient
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"mcp-agent/agent_go/internal/utils"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// methodNotificationCancelled is the MCP notification a client sends to cancel a request it issued
+const methodNotificationCancelled = "notifications/cancelled"
+
+// cancelNotificationTimeout bounds how long we wait to deliver a cancellation notification
+const cancelNotificationTimeout = 5 * time.Second
+
+// CancellationPropagationEnabled reports whether in-flight MCP requests should be cancelled
+// on the server when their context is cancelled. Enabled by default; set
+// MCP_CANCELLATION_PROPAGATION=false to disable.
+func CancellationPropagationEnabled() bool {
+	value := strings.TrimSpace(strings.ToLower(os.Getenv("MCP_CANCELLATION_PROPAGATION")))
+	return value != "false" && value != "0" && value != "off"
+}
+
+// cancellingTransport wraps an MCP transport and sends a notifications/cancelled message
+// to the server when a request's context is cancelled before the response arrives.
+// The mcp-go client doesn't do this itself, so long-running tool calls on external
+// servers would otherwise keep running after a session is stopped.
+type cancellingTransport struct {
+	transport.Interface
+	logger utils.ExtendedLogger
+}
+
+// withCancellationPropagation wraps the transport if cancellation propagation is enabled
+func withCancellationPropagation(inner transport.Interface, logger utils.ExtendedLogger) transport.Interface {
+	if !CancellationPropagationEnabled() {
+		return inner
+	}
+	return &cancellingTransport{Interface: inner, logger: logger}
+}
+
+// SendRequest sends the request and notifies the server if the context is cancelled while it's in flight
+func (t *cancellingTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	response, err := t.Interface.SendRequest(ctx, request)
+	if err != nil && ctx.Err() != nil && request.Method != string(mcp.MethodInitialize) {
+		t.sendCancelled(request, ctx.Err())
+	}
+	return response, err
+}
+
+// sendCancelled notifies the server that the request's result will be unused
+func (t *cancellingTransport) sendCancelled(request transport.JSONRPCRequest, cause error) {
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: methodNotificationCancelled,
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{
+					"requestId": request.ID,
+					"reason":    cause.Error(),
+				},
+			},
+		},
+	}
+
+	// The request context is already done, so use a fresh one to deliver the notification
+	ctx, cancel := context.WithTimeout(context.Background(), cancelNotificationTimeout)
+	defer cancel()
+
+	if err := t.Interface.SendNotification(ctx, notification); err != nil {
+		if t.logger != nil {
+			t.logger.Warnf("⚠️ Failed to send cancellation for MCP request %v (%s): %v", request.ID.Value(), request.Method, err)
+		}
+		return
+	}
+
+	if t.logger != nil {
+		t.logger.Infof("🛑 Sent cancellation for in-flight MCP request %v (%s)", request.ID.Value(), request.Method)
+	}
+}
+
+// SetProtocolVersion forwards to HTTP transports so wrapping doesn't hide the capability
+func (t *cancellingTransport) SetProtocolVersion(version string) {
+	if httpConn, ok := t.Interface.(transport.HTTPConnection); ok {
+		httpConn.SetProtocolVersion(version)
+	}
+}
+
+// SetRequestHandler forwards to bidirectional transports (e.g. for sampling requests)
+func (t *cancellingTransport) SetRequestHandler(handler transport.RequestHandler) {
+	if bidirectional, ok := t.Interface.(transport.BidirectionalInterface); ok {
+		bidirectional.SetRequestHandler(handler)
+	}
+}
+
+// SetConnectionLostHandler forwards to transports that report lost connections
+func (t *cancellingTransport) SetConnectionLostHandler(handler func(error)) {
+	if setter, ok := t.Interface.(interface{ SetConnectionLostHandler(func(error)) }); ok {
+		setter.SetConnectionLostHandler(handler)
+	}
+}
@@ -0,0 +1,101 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mockCancellableServer answers initialize and blocks tools/call until the caller gives up,
+// recording any notifications it receives
+type mockCancellableServer struct {
+	mu            sync.Mutex
+	notifications []mcp.JSONRPCNotification
+	toolCallIDs   []mcp.RequestId
+}
+
+func (m *mockCancellableServer) Start(ctx context.Context) error { return nil }
+
+func (m *mockCancellableServer) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	switch request.Method {
+	case string(mcp.MethodInitialize):
+		result, _ := json.Marshal(mcp.InitializeResult{ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION})
+		return &transport.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: request.ID, Result: result}, nil
+	default:
+		m.mu.Lock()
+		m.toolCallIDs = append(m.toolCallIDs, request.ID)
+		m.mu.Unlock()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+}
+
+func (m *mockCancellableServer) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifications = append(m.notifications, notification)
+	return nil
+}
+
+func (m *mockCancellableServer) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+}
+
+func (m *mockCancellableServer) Close() error { return nil }
+
+func (m *mockCancellableServer) GetSessionId() string { return "" }
+
+func (m *mockCancellableServer) cancelledNotifications() []mcp.JSONRPCNotification {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var cancelled []mcp.JSONRPCNotification
+	for _, n := range m.notifications {
+		if n.Method == methodNotificationCancelled {
+			cancelled = append(cancelled, n)
+		}
+	}
+	return cancelled
+}
+
+func TestCancelledToolCallNotifiesServer(t *testing.T) {
+	server := &mockCancellableServer{}
+	mcpClient := client.NewClient(withCancellationPropagation(server, nil))
+
+	if _, err := mcpClient.Initialize(context.Background(), mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+
+	// Simulate a session stop while a long-running tool call is in flight
+	ctx, stopSession := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		stopSession()
+	}()
+
+	c := &Client{mcpClient: mcpClient}
+	if _, err := c.CallTool(ctx, "slow_tool", nil); err == nil {
+		t.Fatal("expected cancelled tool call to return an error")
+	}
+
+	cancelled := server.cancelledNotifications()
+	if len(cancelled) != 1 {
+		t.Fatalf("expected 1 cancellation notification, got %d", len(cancelled))
+	}
+	if got := cancelled[0].Params.AdditionalFields["requestId"]; got != server.toolCallIDs[0] {
+		t.Errorf("cancellation requestId = %v, want %v", got, server.toolCallIDs[0])
+	}
+}
+
+func TestCancellationPropagationDisabled(t *testing.T) {
+	t.Setenv("MCP_CANCELLATION_PROPAGATION", "false")
+
+	server := &mockCancellableServer{}
+	if wrapped := withCancellationPropagation(server, nil); wrapped != transport.Interface(server) {
+		t.Fatal("expected transport to be left unwrapped when propagation is disabled")
+	}
+}
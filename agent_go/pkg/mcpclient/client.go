@@ -161,6 +161,7 @@ func (c *Client) connectOnce(ctx context.Context) error {
 		})
 		if err != nil {
 			c.mcpClient.Close()
+			c.mcpClient = nil
 			return fmt.Errorf("failed to initialize MCP connection: %w", err)
 		}
 
@@ -174,6 +175,7 @@ func (c *Client) connectOnce(ctx context.Context) error {
 		}
 	}
 
+	trackConnection(c)
 	return nil
 }
 
@@ -260,12 +262,37 @@ func (c *Client) Close() error {
 	c.contextCancel = nil
 	c.mu.Unlock()
 
+	untrackConnection(c)
 	if c.mcpClient != nil {
 		return c.mcpClient.Close()
 	}
 	return nil
 }
 
+// Ping checks that the server still answers requests on the current connection
+func (c *Client) Ping(ctx context.Context) error {
+	if c.mcpClient == nil {
+		return fmt.Errorf("client not connected")
+	}
+	if err := c.mcpClient.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping MCP server '%s': %w", c.getServerName(), err)
+	}
+	return nil
+}
+
+// Reconnect drops the current connection and makes a single new connection attempt
+func (c *Client) Reconnect(ctx context.Context) error {
+	if c.mcpClient != nil {
+		c.mcpClient.Close()
+		c.mcpClient = nil
+	}
+	untrackConnection(c)
+
+	connectCtx, cancel := context.WithTimeout(ctx, c.retryConfig.ConnectTimeout)
+	defer cancel()
+	return c.connectOnce(connectCtx)
+}
+
 // GetServerInfo returns information about the connected server
 func (c *Client) GetServerInfo() *mcp.Implementation {
 	return c.serverInfo
@@ -443,6 +470,11 @@ type ParallelToolDiscoveryResult struct {
 	Tools      []mcp.Tool
 	Error      error
 	Client     ClientInterface // Add client to the result so it can be reused
+
+	// Discovery timing for this server
+	ConnectDuration time.Duration
+	ListDuration    time.Duration
+	Duration        time.Duration // Connect + list tools
 }
 
 // DiscoverAllToolsParallel connects to all servers in the config in parallel, lists tools, and returns results per server.
@@ -458,6 +490,7 @@ func DiscoverAllToolsParallel(ctx context.Context, cfg *MCPConfig, logger utils.
 
 	resultsCh := make(chan ParallelToolDiscoveryResult, len(servers))
 	var wg sync.WaitGroup
+	discoveryStartTime := time.Now()
 
 	logger.Infof("🔍 DiscoverAllToolsParallel: Starting goroutines for %d servers", len(servers))
 	for _, name := range servers {
@@ -493,7 +526,7 @@ func DiscoverAllToolsParallel(ctx context.Context, cfg *MCPConfig, logger utils.
 				if cancel != nil {
 					cancel() // Clean up context on connection failure
 				}
-				resultsCh <- ParallelToolDiscoveryResult{ServerName: name, Tools: nil, Error: err, Client: nil}
+				resultsCh <- ParallelToolDiscoveryResult{ServerName: name, Tools: nil, Error: err, Client: nil, ConnectDuration: connectDuration, Duration: connectDuration}
 				return
 			}
 
@@ -538,7 +571,15 @@ func DiscoverAllToolsParallel(ctx context.Context, cfg *MCPConfig, logger utils.
 			}
 
 			logger.Infof("🔍 DiscoverAllToolsParallel: Sending result for server=%s", name)
-			resultsCh <- ParallelToolDiscoveryResult{ServerName: name, Tools: tools, Error: err, Client: client}
+			resultsCh <- ParallelToolDiscoveryResult{
+				ServerName:      name,
+				Tools:           tools,
+				Error:           err,
+				Client:          client,
+				ConnectDuration: connectDuration,
+				ListDuration:    listDuration,
+				Duration:        connectDuration + listDuration,
+			}
 			logger.Infof("✅ DiscoverAllToolsParallel: Result sent for server=%s", name)
 		}(name, srvCfg)
 	}
@@ -588,6 +629,7 @@ func DiscoverAllToolsParallel(ctx context.Context, cfg *MCPConfig, logger utils.
 					ServerName: name,
 					Tools:      nil,
 					Error:      fmt.Errorf("tool discovery timed out for this server"),
+					Duration:   time.Since(discoveryStartTime),
 				})
 			}
 		}
@@ -641,8 +683,16 @@ func DiscoverAllToolsParallel(ctx context.Context, cfg *MCPConfig, logger utils.
 	logger.Infof("🎯 DiscoverAllToolsParallel: FINAL SUMMARY - total_servers=%d, successful=%d, failed=%d, total_tools=%d",
 		len(results), successCount, errorCount, totalTools)
 
-	// Note: To emit actual events, we would need to pass tracers to this function
-	// For now, we log the information so it appears in the server logs
+	// Roll up per-server timings; callers with tracers emit the per-server
+	// MCPServerDiscovery events via ParallelToolDiscoveryResult.DiscoveryEvent
+	if DiscoveryMetricsEnabled() {
+		metrics := GetDiscoveryMetrics()
+		for _, result := range results {
+			metrics.Record(result)
+			logger.Infof("⏱️ DiscoverAllToolsParallel: server=%s, discovery_time=%v (connect=%v, list=%v), tools=%d",
+				result.ServerName, result.Duration, result.ConnectDuration, result.ListDuration, len(result.Tools))
+		}
+	}
 
 	return results
 }
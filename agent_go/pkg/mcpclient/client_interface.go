@@ -21,6 +21,12 @@ type ClientInterface interface {
 	// Close closes the connection
 	Close() error
 
+	// Ping checks that the server still answers on the current connection
+	Ping(ctx context.Context) error
+
+	// Reconnect replaces the current connection with a fresh one (single attempt)
+	Reconnect(ctx context.Context) error
+
 	// GetServerInfo returns server information
 	GetServerInfo() *mcp.Implementation
 
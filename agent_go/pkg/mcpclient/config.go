@@ -81,6 +81,8 @@ type MCPServerConfig struct {
 	Description string            `json:"description,omitempty"`
 	Protocol    ProtocolType      `json:"protocol,omitempty"`
 	PoolConfig  *PoolConfig       `json:"pool_config,omitempty"`
+	// Version is the expected server version; changing it invalidates cached tool discovery
+	Version string `json:"version,omitempty"`
 	// SSE/HTTP specific fields
 	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
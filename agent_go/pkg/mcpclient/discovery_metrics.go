@@ -0,0 +1,129 @@
+package mcpclient
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// DiscoveryMetricsEnabled reports whether per-server discovery timing is recorded and emitted.
+// Enabled by default; set MCP_DISCOVERY_METRICS=false to disable.
+func DiscoveryMetricsEnabled() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("MCP_DISCOVERY_METRICS"))) {
+	case "false", "0", "off", "no":
+		return false
+	default:
+		return true
+	}
+}
+
+// ServerDiscoveryMetrics is the discovery performance rollup for one server
+type ServerDiscoveryMetrics struct {
+	ServerName          string        `json:"server_name"`
+	Discoveries         int           `json:"discoveries"`
+	Failures            int           `json:"failures"`
+	LastDuration        time.Duration `json:"last_duration"`
+	LastConnectDuration time.Duration `json:"last_connect_duration"`
+	LastListDuration    time.Duration `json:"last_list_duration"`
+	MinDuration         time.Duration `json:"min_duration"`
+	MaxDuration         time.Duration `json:"max_duration"`
+	AverageDuration     time.Duration `json:"average_duration"`
+	TotalDuration       time.Duration `json:"total_duration"`
+	LastToolCount       int           `json:"last_tool_count"`
+	LastError           string        `json:"last_error,omitempty"`
+	LastDiscoveredAt    time.Time     `json:"last_discovered_at"`
+}
+
+// DiscoveryMetrics aggregates discovery timings per server
+type DiscoveryMetrics struct {
+	mu      sync.RWMutex
+	servers map[string]*ServerDiscoveryMetrics
+}
+
+// NewDiscoveryMetrics creates an empty discovery metrics rollup
+func NewDiscoveryMetrics() *DiscoveryMetrics {
+	return &DiscoveryMetrics{servers: make(map[string]*ServerDiscoveryMetrics)}
+}
+
+var defaultDiscoveryMetrics = NewDiscoveryMetrics()
+
+// GetDiscoveryMetrics returns the process-wide discovery metrics rollup
+func GetDiscoveryMetrics() *DiscoveryMetrics {
+	return defaultDiscoveryMetrics
+}
+
+// Record adds a discovery result to the rollup
+func (m *DiscoveryMetrics) Record(result ParallelToolDiscoveryResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, exists := m.servers[result.ServerName]
+	if !exists {
+		stats = &ServerDiscoveryMetrics{ServerName: result.ServerName, MinDuration: result.Duration}
+		m.servers[result.ServerName] = stats
+	}
+
+	stats.Discoveries++
+	stats.LastDuration = result.Duration
+	stats.LastConnectDuration = result.ConnectDuration
+	stats.LastListDuration = result.ListDuration
+	stats.TotalDuration += result.Duration
+	stats.AverageDuration = stats.TotalDuration / time.Duration(stats.Discoveries)
+	if result.Duration < stats.MinDuration {
+		stats.MinDuration = result.Duration
+	}
+	if result.Duration > stats.MaxDuration {
+		stats.MaxDuration = result.Duration
+	}
+	stats.LastToolCount = len(result.Tools)
+	stats.LastDiscoveredAt = time.Now()
+	if result.Error != nil {
+		stats.Failures++
+		stats.LastError = result.Error.Error()
+	} else {
+		stats.LastError = ""
+	}
+}
+
+// Snapshot returns a copy of the per-server metrics sorted by server name
+func (m *DiscoveryMetrics) Snapshot() []ServerDiscoveryMetrics {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshot := make([]ServerDiscoveryMetrics, 0, len(m.servers))
+	for _, stats := range m.servers {
+		snapshot = append(snapshot, *stats)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].ServerName < snapshot[j].ServerName
+	})
+	return snapshot
+}
+
+// Reset clears all recorded metrics
+func (m *DiscoveryMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.servers = make(map[string]*ServerDiscoveryMetrics)
+}
+
+// DiscoveryEvent builds the per-server MCPServerDiscovery event for a discovery result
+func (r ParallelToolDiscoveryResult) DiscoveryEvent() *events.MCPServerDiscoveryEvent {
+	connected, failed := 1, 0
+	if r.Error != nil {
+		connected, failed = 0, 1
+	}
+
+	eventData := events.NewMCPServerDiscoveryEvent(1, connected, failed, r.Duration)
+	eventData.ServerName = r.ServerName
+	eventData.Operation = "server_discovery"
+	eventData.ToolCount = len(r.Tools)
+	if r.Error != nil {
+		eventData.Error = r.Error.Error()
+	}
+	return eventData
+}
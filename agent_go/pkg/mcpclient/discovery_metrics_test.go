@@ -0,0 +1,98 @@
+package mcpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+// newMockDiscoveryServer starts an in-process streamable HTTP MCP server exposing toolCount tools
+func newMockDiscoveryServer(name string, toolCount int) *httptest.Server {
+	mcpServer := server.NewMCPServer(name, "1.0.0", server.WithToolCapabilities(false))
+	for i := 0; i < toolCount; i++ {
+		tool := mcp.NewTool(fmt.Sprintf("%s_tool_%d", name, i), mcp.WithDescription("mock tool"))
+		mcpServer.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		})
+	}
+	return server.NewTestStreamableHTTPServer(mcpServer)
+}
+
+func TestDiscoverAllToolsParallelRecordsPerServerTimings(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+
+	toolCounts := map[string]int{"alpha": 1, "beta": 3}
+	cfg := &MCPConfig{MCPServers: map[string]MCPServerConfig{}}
+	for name, count := range toolCounts {
+		ts := newMockDiscoveryServer(name, count)
+		defer ts.Close()
+		cfg.MCPServers[name] = MCPServerConfig{URL: ts.URL + "/mcp", Protocol: ProtocolHTTP}
+	}
+
+	GetDiscoveryMetrics().Reset()
+	results := DiscoverAllToolsParallel(context.Background(), cfg, testLogger)
+	if len(results) != len(toolCounts) {
+		t.Fatalf("expected %d results, got %d", len(toolCounts), len(results))
+	}
+
+	for _, result := range results {
+		if result.Client != nil {
+			defer result.Client.Close()
+		}
+		if result.Error != nil {
+			t.Fatalf("discovery failed for %s: %v", result.ServerName, result.Error)
+		}
+
+		event := result.DiscoveryEvent()
+		if event.ServerName != result.ServerName {
+			t.Errorf("expected event for %s, got %s", result.ServerName, event.ServerName)
+		}
+		if event.DiscoveryTime <= 0 {
+			t.Errorf("expected non-zero discovery time for %s", result.ServerName)
+		}
+		if event.ToolCount != toolCounts[result.ServerName] {
+			t.Errorf("expected %d tools for %s, got %d", toolCounts[result.ServerName], result.ServerName, event.ToolCount)
+		}
+		if event.ConnectedServers != 1 || event.FailedServers != 0 {
+			t.Errorf("unexpected connection counts for %s: %+v", result.ServerName, event)
+		}
+	}
+
+	snapshot := GetDiscoveryMetrics().Snapshot()
+	if len(snapshot) != 2 || snapshot[0].ServerName != "alpha" || snapshot[1].ServerName != "beta" {
+		t.Fatalf("unexpected metrics snapshot: %+v", snapshot)
+	}
+	for _, stats := range snapshot {
+		if stats.Discoveries != 1 || stats.LastDuration <= 0 || stats.AverageDuration != stats.LastDuration {
+			t.Errorf("unexpected rollup for %s: %+v", stats.ServerName, stats)
+		}
+		if stats.LastToolCount != toolCounts[stats.ServerName] {
+			t.Errorf("expected %d tools in rollup for %s, got %d", toolCounts[stats.ServerName], stats.ServerName, stats.LastToolCount)
+		}
+	}
+}
+
+func TestDiscoveryMetricsRecordsFailures(t *testing.T) {
+	metrics := NewDiscoveryMetrics()
+	metrics.Record(ParallelToolDiscoveryResult{ServerName: "flaky", Duration: 20})
+	metrics.Record(ParallelToolDiscoveryResult{ServerName: "flaky", Duration: 10, Error: fmt.Errorf("boom")})
+
+	stats := metrics.Snapshot()[0]
+	if stats.Discoveries != 2 || stats.Failures != 1 || stats.LastError != "boom" {
+		t.Fatalf("unexpected failure rollup: %+v", stats)
+	}
+	if stats.MinDuration != 10 || stats.MaxDuration != 20 || stats.AverageDuration != 15 {
+		t.Fatalf("unexpected duration rollup: %+v", stats)
+	}
+
+	event := ParallelToolDiscoveryResult{ServerName: "flaky", Duration: 10, Error: fmt.Errorf("boom")}.DiscoveryEvent()
+	if event.FailedServers != 1 || event.Error != "boom" {
+		t.Fatalf("unexpected failure event: %+v", event)
+	}
+}
@@ -0,0 +1,221 @@
+package mcpclient
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"mcp-agent/agent_go/internal/utils"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// Connection statuses reported by the health checker in MCPServerConnectionEvent.Status
+const (
+	HealthStatusDisconnected    = "disconnected"     // A connected server stopped answering pings
+	HealthStatusReconnected     = "reconnected"      // A reconnection attempt succeeded
+	HealthStatusReconnectFailed = "reconnect_failed" // All reconnection attempts of a round failed
+)
+
+const (
+	defaultHealthCheckRetryDelay = time.Second
+	healthCheckPingTimeout       = 30 * time.Second
+)
+
+// HealthCheckConfig controls the background health checker. Every Interval each server is pinged;
+// a failed ping triggers up to MaxRetries reconnection attempts, RetryDelay apart and doubling up
+// to Interval. A server whose round fails stays down and gets a new round on the next tick.
+type HealthCheckConfig struct {
+	Interval   time.Duration
+	MaxRetries int
+	RetryDelay time.Duration // Zero uses one second
+}
+
+// HealthEventHandler receives a connection event on every health transition of a server
+type HealthEventHandler func(event *events.MCPServerConnectionEvent)
+
+// HealthChecker pings connected MCP servers in the background and reconnects the ones that died
+type HealthChecker struct {
+	config  HealthCheckConfig
+	clients map[string]ClientInterface
+	logger  utils.ExtendedLogger
+	onEvent HealthEventHandler
+
+	mu   sync.Mutex
+	down map[string]bool // Servers whose last check failed
+	// Servers whose last reconnection round failed, so repeated failures are reported once
+	failed map[string]bool
+
+	started  bool
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewHealthChecker creates a health checker for the given clients; onEvent may be nil
+func NewHealthChecker(config HealthCheckConfig, clients map[string]ClientInterface, logger utils.ExtendedLogger, onEvent HealthEventHandler) *HealthChecker {
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = defaultHealthCheckRetryDelay
+	}
+	if config.MaxRetries < 1 {
+		config.MaxRetries = 1
+	}
+
+	snapshot := make(map[string]ClientInterface, len(clients))
+	for name, client := range clients {
+		if client != nil {
+			snapshot[name] = client
+		}
+	}
+
+	return &HealthChecker{
+		config:  config,
+		clients: snapshot,
+		logger:  logger,
+		onEvent: onEvent,
+		down:    make(map[string]bool),
+		failed:  make(map[string]bool),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start runs the check loop in the background until Stop is called or ctx is done
+func (h *HealthChecker) Start(ctx context.Context) {
+	h.mu.Lock()
+	if h.started {
+		h.mu.Unlock()
+		return
+	}
+	h.started = true
+	h.mu.Unlock()
+
+	if h.config.Interval <= 0 {
+		close(h.done)
+		return
+	}
+
+	go func() {
+		defer close(h.done)
+		ticker := time.NewTicker(h.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				h.Check(ctx)
+			case <-h.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the check loop and waits for a running check to finish
+func (h *HealthChecker) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+	})
+
+	h.mu.Lock()
+	started := h.started
+	h.mu.Unlock()
+	if started {
+		<-h.done
+	}
+}
+
+// Check pings every server once and reconnects the ones that do not answer
+func (h *HealthChecker) Check(ctx context.Context) {
+	names := make([]string, 0, len(h.clients))
+	for name := range h.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return
+		}
+		h.checkServer(ctx, name, h.clients[name])
+	}
+}
+
+func (h *HealthChecker) checkServer(ctx context.Context, name string, client ClientInterface) {
+	h.mu.Lock()
+	wasDown := h.down[name]
+	h.mu.Unlock()
+
+	if !wasDown {
+		pingCtx, cancel := context.WithTimeout(ctx, healthCheckPingTimeout)
+		err := client.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		h.logger.Warnf("⚠️ MCP server '%s' failed its health check: %v", name, err)
+		h.mu.Lock()
+		h.down[name] = true
+		h.mu.Unlock()
+		h.emit(name, HealthStatusDisconnected, 0, err.Error())
+	}
+
+	h.reconnect(ctx, name, client)
+}
+
+// reconnect makes up to MaxRetries reconnection attempts with doubling delays between them
+func (h *HealthChecker) reconnect(ctx context.Context, name string, client ClientInterface) {
+	start := time.Now()
+	delay := h.config.RetryDelay
+	var lastErr error
+
+	for attempt := 1; attempt <= h.config.MaxRetries; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(delay):
+			case <-h.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+			delay *= 2
+			if h.config.Interval > 0 && delay > h.config.Interval {
+				delay = h.config.Interval
+			}
+		}
+
+		h.logger.Infof("🔄 Reconnecting to MCP server '%s' (attempt %d/%d)...", name, attempt, h.config.MaxRetries)
+		lastErr = client.Reconnect(ctx)
+		if lastErr == nil {
+			h.logger.Infof("✅ Reconnected to MCP server '%s' after %d attempt(s)", name, attempt)
+			h.mu.Lock()
+			delete(h.down, name)
+			delete(h.failed, name)
+			h.mu.Unlock()
+			h.emit(name, HealthStatusReconnected, time.Since(start), "")
+			return
+		}
+		h.logger.Warnf("⚠️ Reconnection attempt %d/%d to MCP server '%s' failed: %v", attempt, h.config.MaxRetries, name, lastErr)
+	}
+
+	h.mu.Lock()
+	alreadyFailed := h.failed[name]
+	h.failed[name] = true
+	h.mu.Unlock()
+	if !alreadyFailed {
+		h.logger.Errorf("❌ Giving up on MCP server '%s' until the next health check: %v", name, lastErr)
+		h.emit(name, HealthStatusReconnectFailed, time.Since(start), lastErr.Error())
+	}
+}
+
+func (h *HealthChecker) emit(name, status string, duration time.Duration, errMsg string) {
+	if h.onEvent == nil {
+		return
+	}
+	eventData := events.NewMCPServerConnectionEvent(name, status, 0, duration, errMsg)
+	eventData.Operation = "health_check"
+	h.onEvent(eventData)
+}
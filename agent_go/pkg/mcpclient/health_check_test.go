@@ -0,0 +1,145 @@
+package mcpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+// newFlakyMCPServer starts a streamable HTTP MCP server that answers 503 while down is set
+func newFlakyMCPServer(down *atomic.Bool) *httptest.Server {
+	mcpServer := server.NewMCPServer("flaky", "1.0.0")
+	handler := server.NewStreamableHTTPServer(mcpServer)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "server down", http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+}
+
+type recordedHealthEvents struct {
+	mu     sync.Mutex
+	events []*events.MCPServerConnectionEvent
+}
+
+func (r *recordedHealthEvents) record(event *events.MCPServerConnectionEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordedHealthEvents) statuses() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statuses := make([]string, 0, len(r.events))
+	for _, event := range r.events {
+		statuses = append(statuses, event.Status)
+	}
+	return statuses
+}
+
+func TestHealthCheckerReconnectsServerThatDropsAndRecovers(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+	var down atomic.Bool
+	ts := newFlakyMCPServer(&down)
+	defer ts.Close()
+
+	client := New(MCPServerConfig{URL: ts.URL + "/mcp", Protocol: ProtocolHTTP}, testLogger)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer client.Close()
+
+	recorder := &recordedHealthEvents{}
+	checker := NewHealthChecker(HealthCheckConfig{Interval: time.Minute, MaxRetries: 2, RetryDelay: time.Millisecond},
+		map[string]ClientInterface{"flaky": client}, testLogger, recorder.record)
+	ctx := context.Background()
+
+	checker.Check(ctx)
+	if statuses := recorder.statuses(); len(statuses) != 0 {
+		t.Fatalf("expected no events while healthy, got %v", statuses)
+	}
+
+	down.Store(true)
+	checker.Check(ctx)
+	checker.Check(ctx) // still down: the failure must not be reported twice
+
+	down.Store(false)
+	checker.Check(ctx)
+	checker.Check(ctx) // healthy again: no further events
+
+	expected := []string{HealthStatusDisconnected, HealthStatusReconnectFailed, HealthStatusReconnected}
+	statuses := recorder.statuses()
+	if len(statuses) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, statuses)
+	}
+	for i := range expected {
+		if statuses[i] != expected[i] {
+			t.Fatalf("expected events %v, got %v", expected, statuses)
+		}
+	}
+	for _, event := range recorder.events {
+		if event.ServerName != "flaky" || event.Operation != "health_check" {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	}
+	if recorder.events[0].Error == "" || recorder.events[1].Error == "" {
+		t.Errorf("expected failure events to carry the error, got %+v and %+v", recorder.events[0], recorder.events[1])
+	}
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("expected reconnected client to answer pings: %v", err)
+	}
+}
+
+func TestHealthCheckerLoopReconnectsInBackground(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+	var down atomic.Bool
+	ts := newFlakyMCPServer(&down)
+	defer ts.Close()
+
+	client := New(MCPServerConfig{URL: ts.URL + "/mcp", Protocol: ProtocolHTTP}, testLogger)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer client.Close()
+
+	reconnected := make(chan struct{}, 1)
+	recorder := &recordedHealthEvents{}
+	checker := NewHealthChecker(HealthCheckConfig{Interval: 10 * time.Millisecond, MaxRetries: 3, RetryDelay: time.Millisecond},
+		map[string]ClientInterface{"flaky": client}, testLogger, func(event *events.MCPServerConnectionEvent) {
+			recorder.record(event)
+			if event.Status == HealthStatusDisconnected {
+				down.Store(false) // recover before the reconnection attempts
+			}
+			if event.Status == HealthStatusReconnected {
+				reconnected <- struct{}{}
+			}
+		})
+
+	down.Store(true)
+	checker.Start(context.Background())
+	defer checker.Stop()
+
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for reconnect, events so far: %v", recorder.statuses())
+	}
+
+	statuses := recorder.statuses()
+	if len(statuses) != 2 || statuses[0] != HealthStatusDisconnected || statuses[1] != HealthStatusReconnected {
+		t.Fatalf("expected disconnected then reconnected, got %v", statuses)
+	}
+}
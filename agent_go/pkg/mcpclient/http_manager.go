@@ -43,7 +43,7 @@ func (h *HTTPManager) CreateClient() (*client.Client, error) {
 	}
 
 	// Create client with transport
-	return client.NewClient(httpTransport), nil
+	return client.NewClient(withCancellationPropagation(httpTransport, h.logger)), nil
 }
 
 // Connect creates and starts an HTTP client
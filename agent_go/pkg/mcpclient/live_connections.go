@@ -0,0 +1,29 @@
+package mcpclient
+
+import "sync"
+
+// liveConnections tracks the clients holding an open MCP connection, so connections orphaned by
+// stopped sessions show up as a growing count instead of silently leaking subprocesses and sockets
+var liveConnections = struct {
+	mu      sync.Mutex
+	clients map[*Client]struct{}
+}{clients: make(map[*Client]struct{})}
+
+// LiveConnections returns the number of MCP connections opened by Connect and not yet closed
+func LiveConnections() int {
+	liveConnections.mu.Lock()
+	defer liveConnections.mu.Unlock()
+	return len(liveConnections.clients)
+}
+
+func trackConnection(c *Client) {
+	liveConnections.mu.Lock()
+	defer liveConnections.mu.Unlock()
+	liveConnections.clients[c] = struct{}{}
+}
+
+func untrackConnection(c *Client) {
+	liveConnections.mu.Lock()
+	defer liveConnections.mu.Unlock()
+	delete(liveConnections.clients, c)
+}
@@ -0,0 +1,29 @@
+package mcpclient
+
+import (
+	"context"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+func TestLiveConnectionsTracksConnectAndClose(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+	ts := newMockDiscoveryServer("live", 1)
+	defer ts.Close()
+
+	baseline := LiveConnections()
+	client := New(MCPServerConfig{URL: ts.URL + "/mcp", Protocol: ProtocolHTTP}, testLogger)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	if got := LiveConnections(); got != baseline+1 {
+		t.Fatalf("expected %d live connections after connect, got %d", baseline+1, got)
+	}
+
+	client.Close()
+	client.Close() // closing twice must not drive the count below the baseline
+	if got := LiveConnections(); got != baseline {
+		t.Fatalf("expected %d live connections after close, got %d", baseline, got)
+	}
+}
@@ -46,7 +46,7 @@ func (s *SSEManager) CreateClient() (*client.Client, error) {
 	}
 
 	// Create client with transport
-	return client.NewClient(sseTransport), nil
+	return client.NewClient(withCancellationPropagation(sseTransport, s.logger)), nil
 }
 
 // Connect creates and starts an SSE client
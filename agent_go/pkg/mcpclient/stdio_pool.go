@@ -11,6 +11,7 @@ import (
 	"mcp-agent/agent_go/internal/utils"
 
 	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -88,11 +89,12 @@ func (p *StdioConnectionPool) GetConnection(ctx context.Context, serverKey strin
 func (p *StdioConnectionPool) createNewConnection(ctx context.Context, serverKey string, command string, args []string, env []string) (*StdioConnection, error) {
 	p.logger.Infof("🔧 [STDIO POOL] Creating new stdio connection: %s %v", command, args)
 
-	// Create the MCP client
-	mcpClient, err := client.NewStdioMCPClient(command, env, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stdio client: %w", err)
+	// Create and start the stdio transport, then wrap it so cancelled calls are propagated to the server
+	stdioTransport := transport.NewStdio(command, env, args...)
+	if err := stdioTransport.Start(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to start stdio transport: %w", err)
 	}
+	mcpClient := client.NewClient(withCancellationPropagation(stdioTransport, p.logger))
 
 	// Initialize the connection
 	initCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
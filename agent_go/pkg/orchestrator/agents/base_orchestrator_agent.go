@@ -53,7 +53,7 @@ func NewBaseOrchestratorAgentWithEventBridge(
 // Initialize initializes the base orchestrator agent
 func (boa *BaseOrchestratorAgent) Initialize(ctx context.Context) error {
 	// Create LLM instance
-	llmInstance, err := boa.createLLM(ctx)
+	llmInstance, err := boa.createLLM(ctx, boa.config.Provider, boa.config.Model, boa.config.Temperature)
 	if err != nil {
 		return fmt.Errorf("failed to create LLM: %w", err)
 	}
@@ -90,6 +90,17 @@ func (boa *BaseOrchestratorAgent) Initialize(ctx context.Context) error {
 	}
 
 	boa.baseAgent = baseAgent
+	boa.baseAgent.agent.SetFallbackExclusions(boa.config.FallbackExclusions())
+
+	// Extract structured output with the per-request override LLM, if any
+	if provider, modelID, temperature, overridden := boa.config.StructuredOutputModel(); overridden {
+		structuredLLM, err := boa.createLLM(ctx, provider, modelID, temperature)
+		if err != nil {
+			return fmt.Errorf("failed to create structured output LLM: %w", err)
+		}
+		boa.baseAgent.agent.SetStructuredOutputLLM(structuredLLM)
+		boa.logger.Infof("🔧 %s agent extracts structured output with %s/%s", boa.agentType, provider, modelID)
+	}
 
 	// Append the agent-specific prompt to the existing system prompt
 	boa.baseAgent.agent.AppendSystemPrompt(boa.systemPrompt)
@@ -363,21 +374,21 @@ func (boa *BaseOrchestratorAgent) emitAgentEndEvent(ctx context.Context, templat
 	boa.emitEvent(ctx, events.OrchestratorAgentEnd, eventData)
 }
 
-// createLLM creates an LLM instance based on the agent configuration
-func (boa *BaseOrchestratorAgent) createLLM(ctx context.Context) (llmtypes.Model, error) {
+// createLLM creates an LLM instance for the provider and model with the agent's fallback configuration
+func (boa *BaseOrchestratorAgent) createLLM(ctx context.Context, provider, modelID string, temperature float64) (llmtypes.Model, error) {
 	// Generate trace ID for this agent session
 	traceID := observability.TraceID(fmt.Sprintf("%s-agent-%d", boa.agentType, time.Now().UnixNano()))
 
 	// Build fallback models list
 	var fallbackModels []string
 
-	// Add custom fallback models from frontend if provided
-	if len(boa.config.FallbackModels) > 0 {
+	// Add custom fallback models from frontend if provided; they belong to the agent's own provider
+	if len(boa.config.FallbackModels) > 0 && provider == boa.config.Provider {
 		fallbackModels = append(fallbackModels, boa.config.FallbackModels...)
 		// Using custom fallback models from frontend
 	} else {
 		// Use default fallback models for the provider
-		fallbackModels = append(fallbackModels, llm.GetDefaultFallbackModels(llm.Provider(boa.config.Provider))...)
+		fallbackModels = append(fallbackModels, llm.GetDefaultFallbackModels(llm.Provider(provider))...)
 		// Using default fallback models for provider
 	}
 
@@ -388,16 +399,19 @@ func (boa *BaseOrchestratorAgent) createLLM(ctx context.Context) (llmtypes.Model
 		// Added cross-provider fallback models
 	} else {
 		// Add default cross-provider fallbacks
-		crossProviderFallbacks := llm.GetCrossProviderFallbackModels(llm.Provider(boa.config.Provider))
+		crossProviderFallbacks := llm.GetCrossProviderFallbackModels(llm.Provider(provider))
 		fallbackModels = append(fallbackModels, crossProviderFallbacks...)
 		// Added default cross-provider fallback models
 	}
 
+	// Drop fallbacks this request must never use
+	fallbackModels = boa.config.FallbackExclusions().Filter("", fallbackModels)
+
 	// Create LLM configuration
 	config := llm.Config{
-		Provider:       llm.Provider(boa.config.Provider),
-		ModelID:        boa.config.Model,
-		Temperature:    boa.config.Temperature,
+		Provider:       llm.Provider(provider),
+		ModelID:        modelID,
+		Temperature:    temperature,
 		Tracers:        nil, // Tracers will be set later if needed
 		TraceID:        traceID,
 		FallbackModels: fallbackModels,
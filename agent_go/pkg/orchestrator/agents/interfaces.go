@@ -3,7 +3,9 @@ package agents
 import (
 	"context"
 	"fmt"
+	"mcp-agent/agent_go/internal/llm"
 	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/mcpagent"
 	"os"
 	"strconv"
 	"strings"
@@ -51,6 +53,11 @@ type OrchestratorAgentConfig struct {
 	// Detailed LLM configuration from frontend
 	FallbackModels        []string               `json:"fallback_models,omitempty"`
 	CrossProviderFallback *CrossProviderFallback `json:"cross_provider_fallback,omitempty"`
+	ExcludeProviders      []string               `json:"exclude_providers,omitempty"` // Never fall back to these providers
+	ExcludeModels         []string               `json:"exclude_models,omitempty"`    // Never fall back to these models
+
+	// Model converting answers to structured output; nil uses the execution LLM above
+	StructuredOutputLLM *StructuredOutputLLMConfig `json:"structured_output_llm,omitempty"`
 
 	// Required Agent behavior
 	Mode         AgentMode    `json:"mode" validate:"required"`
@@ -88,6 +95,40 @@ type CrossProviderFallback struct {
 	Models   []string `json:"models"`
 }
 
+// StructuredOutputLLMConfig overrides the LLM of structured output extraction, e.g. with a cheaper or
+// faster model than the execution LLM. Empty fields keep the execution LLM's value.
+type StructuredOutputLLMConfig struct {
+	Provider    string   `json:"provider,omitempty"`
+	ModelID     string   `json:"model_id,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+// FallbackExclusions returns the providers and models the agent's fallback chain must skip
+func (c *OrchestratorAgentConfig) FallbackExclusions() mcpagent.FallbackExclusions {
+	return mcpagent.FallbackExclusions{Providers: c.ExcludeProviders, Models: c.ExcludeModels}
+}
+
+// StructuredOutputModel returns the provider, model and temperature of structured output extraction,
+// filling unset override fields from the execution LLM (another provider without a model uses its
+// default model); overridden is false without an override
+func (c *OrchestratorAgentConfig) StructuredOutputModel() (provider, modelID string, temperature float64, overridden bool) {
+	provider, modelID, temperature = c.Provider, c.Model, c.Temperature
+	if c.StructuredOutputLLM == nil {
+		return provider, modelID, temperature, false
+	}
+	if c.StructuredOutputLLM.Provider != "" && c.StructuredOutputLLM.Provider != provider {
+		provider = c.StructuredOutputLLM.Provider
+		modelID = llm.GetDefaultModel(llm.Provider(provider))
+	}
+	if c.StructuredOutputLLM.ModelID != "" {
+		modelID = c.StructuredOutputLLM.ModelID
+	}
+	if c.StructuredOutputLLM.Temperature != nil {
+		temperature = *c.StructuredOutputLLM.Temperature
+	}
+	return provider, modelID, temperature, true
+}
+
 // NewOrchestratorAgentConfig creates a new agent configuration with minimal defaults
 func NewOrchestratorAgentConfig(name string) *OrchestratorAgentConfig {
 	return &OrchestratorAgentConfig{
@@ -2,6 +2,7 @@ package agents
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"text/template"
@@ -42,6 +43,11 @@ func (ra *OrchestratorReportAgent) Execute(ctx context.Context, templateVars map
 	return ra.ExecuteWithInputProcessor(ctx, templateVars, ra.reportInputProcessor, conversationHistory)
 }
 
+// ExecuteStructured executes the report agent and returns the report as JSON matching schema
+func (ra *OrchestratorReportAgent) ExecuteStructured(ctx context.Context, templateVars map[string]string, conversationHistory []llmtypes.MessageContent, schema string) (json.RawMessage, error) {
+	return ExecuteStructuredWithInputProcessor[json.RawMessage](ra.BaseOrchestratorAgent, ctx, templateVars, ra.reportInputProcessor, conversationHistory, schema)
+}
+
 // reportInputProcessor processes inputs specifically for report generation using template replacement
 func (ra *OrchestratorReportAgent) reportInputProcessor(templateVars map[string]string) string {
 	// Use the predefined prompt with template variable replacement
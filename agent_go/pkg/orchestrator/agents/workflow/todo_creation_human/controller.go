@@ -2,9 +2,14 @@ package todo_creation_human
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"mcp-agent/agent_go/internal/llmtypes"
@@ -22,6 +27,7 @@ import (
 type StepProgress struct {
 	CompletedStepIndices []int     `json:"completed_step_indices"` // 0-based indices
 	TotalSteps           int       `json:"total_steps"`
+	PlanChecksum         string    `json:"plan_checksum,omitempty"` // planChecksum of the steps the progress belongs to
 	LastUpdated          time.Time `json:"last_updated"`
 }
 
@@ -33,8 +39,9 @@ type TodoStep struct {
 	WhyThisStep         string   `json:"why_this_step"`
 	ContextDependencies []string `json:"context_dependencies"`
 	ContextOutput       string   `json:"context_output"`
-	SuccessPatterns     []string `json:"success_patterns,omitempty"` // NEW - what worked (includes tools)
-	FailurePatterns     []string `json:"failure_patterns,omitempty"` // NEW - what failed (includes tools to avoid)
+	SuccessPatterns     []string `json:"success_patterns,omitempty"`  // NEW - what worked (includes tools)
+	FailurePatterns     []string `json:"failure_patterns,omitempty"`  // NEW - what failed (includes tools to avoid)
+	SuccessThreshold    float64  `json:"success_threshold,omitempty"` // Minimum validation score (0.0-1.0); 0 uses the orchestrator default
 }
 
 // TodoStepsExtractedEvent represents the event when todo steps are extracted from a plan
@@ -77,6 +84,39 @@ type HumanControlledTodoPlannerOrchestrator struct {
 
 	// Learning detail level preference (set once before execution, used for all learning phases)
 	learningDetailLevel string // "exact" or "general"
+
+	// Default validation score threshold for steps without their own success_threshold.
+	// 0 keeps the binary is_success_criteria_met behavior.
+	successThreshold float64
+
+	// Recorded execution outputs keyed by context output file, injected into dependent steps
+	stepOutputs             map[string]recordedStepOutput
+	injectDependencyOutputs bool
+
+	// Escalate to the human gate only after this many automated failures of a step.
+	// Steps that pass validation auto-proceed. 0 always asks the human (default).
+	humanEscalationAfterFailures int
+
+	// Ask the human to change approach once the same plan feedback has been given this many times.
+	// 0 uses defaultRepeatedFeedbackLimit, negative disables the detection.
+	repeatedFeedbackLimit int
+
+	// Lexical (word-overlap) similarity threshold for the post-planning duplicate step merge pass (0 = disabled)
+	stepDedupThreshold float64
+
+	// Execute independent steps concurrently with up to this many workers (0 or 1 = one by one)
+	parallelStepWorkers int
+
+	// Guard steps_done.json progress and recorded step outputs while steps run concurrently
+	progressMu    sync.Mutex
+	stepOutputsMu sync.Mutex
+
+	// Stop after planning and return the plan without executing it (see SetDryRun)
+	dryRun bool
+
+	// planStepsFunc and stepAttemptFunc replace planning and step execution (tests)
+	planStepsFunc   func(ctx context.Context, objective, workspacePath string) ([]TodoStep, error)
+	stepAttemptFunc stepAttemptFunc
 }
 
 // NewHumanControlledTodoPlannerOrchestrator creates a new human-controlled todo planner orchestrator
@@ -119,9 +159,10 @@ func NewHumanControlledTodoPlannerOrchestrator(
 	}
 
 	return &HumanControlledTodoPlannerOrchestrator{
-		BaseOrchestrator: baseOrchestrator,
-		sessionID:        fmt.Sprintf("session_%d", time.Now().UnixNano()),
-		workflowID:       fmt.Sprintf("workflow_%d", time.Now().UnixNano()),
+		BaseOrchestrator:        baseOrchestrator,
+		sessionID:               fmt.Sprintf("session_%d", time.Now().UnixNano()),
+		workflowID:              fmt.Sprintf("workflow_%d", time.Now().UnixNano()),
+		injectDependencyOutputs: true,
 	}, nil
 }
 
@@ -167,6 +208,28 @@ func (hcpo *HumanControlledTodoPlannerOrchestrator) saveStepProgress(ctx context
 	return nil
 }
 
+// planChecksum hashes the ordered step titles and descriptions, so progress saved for a plan can be
+// told apart from progress of an edited plan with the same number of steps
+func planChecksum(steps []TodoStep) string {
+	hash := sha256.New()
+	for _, step := range steps {
+		fmt.Fprintf(hash, "%d\x00%s\x00%d\x00%s\x00", len(step.Title), step.Title, len(step.Description), step.Description)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// progressPlanDrift returns why saved progress no longer matches the plan, "" when it still does.
+// Progress saved before checksums were recorded is matched by step count only.
+func progressPlanDrift(progress *StepProgress, steps []TodoStep) string {
+	if progress.TotalSteps != len(steps) {
+		return fmt.Sprintf("the plan has %d steps, the saved progress %d", len(steps), progress.TotalSteps)
+	}
+	if progress.PlanChecksum != "" && progress.PlanChecksum != planChecksum(steps) {
+		return "step titles or descriptions changed since the progress was saved"
+	}
+	return ""
+}
+
 // deleteStepProgress deletes steps_done.json file
 func (hcpo *HumanControlledTodoPlannerOrchestrator) deleteStepProgress(ctx context.Context) error {
 	progressPath := hcpo.getStepsProgressPath()
@@ -197,6 +260,239 @@ func (hcpo *HumanControlledTodoPlannerOrchestrator) CreateTodoList(ctx context.C
 	hcpo.SetObjective(objective)
 	hcpo.SetWorkspacePath(workspacePath)
 
+	planSteps := hcpo.planTodoSteps
+	if hcpo.planStepsFunc != nil {
+		planSteps = hcpo.planStepsFunc
+	}
+	breakdownSteps, err := planSteps(ctx, objective, workspacePath)
+	if err != nil {
+		return "", err
+	}
+
+	// A dry run stops here, before any execution agent runs or MCP tool is called
+	if hcpo.dryRun {
+		return hcpo.dryRunPlan(breakdownSteps)
+	}
+
+	// EARLY PROGRESS CHECK: Check if all steps are already completed before proceeding
+	// This prevents running plan reader unnecessarily if all steps are done
+	hcpo.GetLogger().Infof("🔍 Early progress check: Checking if all steps are already completed")
+	hcpo.GetLogger().Infof("🔍 DEBUG: breakdownSteps count before early progress check: %d", len(breakdownSteps))
+
+	earlyProgress, err := hcpo.loadStepProgress(ctx)
+	if err == nil && earlyProgress != nil && len(earlyProgress.CompletedStepIndices) > 0 {
+		hcpo.GetLogger().Infof("📊 Found early progress: %d/%d steps completed",
+			len(earlyProgress.CompletedStepIndices), earlyProgress.TotalSteps)
+
+		// Check if the progress belongs to this plan
+		if drift := progressPlanDrift(earlyProgress, breakdownSteps); drift == "" {
+			// Calculate if all steps are completed
+			if len(earlyProgress.CompletedStepIndices) == earlyProgress.TotalSteps {
+				hcpo.GetLogger().Infof("✅ ALL steps already completed - skipping to writer phase")
+
+				// Phase 3: Write/Update todo list with critique validation loop
+				err = hcpo.runWriterPhaseWithHumanReview(ctx, 1)
+				if err != nil {
+					hcpo.GetLogger().Warnf("⚠️ Writer phase with critique validation failed: %w", err)
+				}
+
+				// Return early with completion message
+				return "Todo planning complete. All steps already executed. Final todo list saved as `todo_final.md`.", nil
+			}
+			hcpo.GetLogger().Infof("📊 Not all steps completed yet - will proceed with execution")
+		} else if earlyProgress.TotalSteps != len(breakdownSteps) {
+			hcpo.GetLogger().Warnf("⚠️ Total steps changed (previous: %d, current: %d), will create new progress",
+				earlyProgress.TotalSteps, len(breakdownSteps))
+			earlyProgress = nil // Don't use old progress if plan changed
+		} else {
+			// Same step count but different steps: the resume dialog below decides what to keep
+			hcpo.GetLogger().Warnf("⚠️ Plan changed (%s), not skipping completed steps", drift)
+		}
+	}
+
+	// Check for existing progress and ask user if they want to resume
+	var startFromStep int = 0 // 0-based index, 0 means start from beginning
+	var existingProgress *StepProgress
+
+	// Use earlyProgress if available, otherwise load it
+	if earlyProgress != nil {
+		existingProgress = earlyProgress
+		err = nil // Reset err since earlyProgress was successfully loaded earlier
+		hcpo.GetLogger().Infof("✅ Using early progress (avoided reload)")
+	} else {
+		// Check if there's existing progress
+		existingProgress, err = hcpo.loadStepProgress(ctx)
+		if err != nil {
+			// File doesn't exist - this is normal for first run, log and continue
+			hcpo.GetLogger().Infof("ℹ️ No existing progress file found (this is normal for first run), will start fresh execution")
+			existingProgress = nil
+			err = nil // Reset err to allow execution to proceed
+		}
+	}
+
+	// Process existing progress if available
+	if err == nil && existingProgress != nil && len(existingProgress.CompletedStepIndices) > 0 {
+		hcpo.GetLogger().Infof("📊 Found existing progress: %d/%d steps completed",
+			len(existingProgress.CompletedStepIndices), existingProgress.TotalSteps)
+
+		// Check if the progress belongs to this plan (step count and content)
+		planDrift := progressPlanDrift(existingProgress, breakdownSteps)
+		if existingProgress.TotalSteps != len(breakdownSteps) {
+			hcpo.GetLogger().Warnf("⚠️ Plan has changed (different number of steps), ignoring previous progress")
+			existingProgress = nil
+		} else if planDrift != "" && len(existingProgress.CompletedStepIndices) == existingProgress.TotalSteps {
+			// Nothing to resume: every step of the old plan is done, but the current plan differs
+			hcpo.GetLogger().Warnf("⚠️ Plan has changed (%s), ignoring previous progress", planDrift)
+			existingProgress = nil
+		} else {
+			if planDrift != "" {
+				hcpo.GetLogger().Warnf("⚠️ Plan has changed (%s), completed steps may not match the current plan", planDrift)
+			}
+
+			// Check if all steps are completed first
+			allStepsCompleted := len(existingProgress.CompletedStepIndices) == existingProgress.TotalSteps
+
+			// Ask user if they want to resume
+			nextIncompleteStep := 0
+			if !allStepsCompleted {
+				for i := 0; i < existingProgress.TotalSteps; i++ {
+					completed := false
+					for _, completedIdx := range existingProgress.CompletedStepIndices {
+						if completedIdx == i {
+							completed = true
+							break
+						}
+					}
+					if !completed {
+						nextIncompleteStep = i + 1 // 1-based for display
+						break
+					}
+				}
+			}
+
+			if allStepsCompleted {
+				// All steps are completed, skip directly to writer phase
+				hcpo.GetLogger().Infof("✅ All steps already completed (%d/%d), skipping execution phase and going directly to writer phase",
+					len(existingProgress.CompletedStepIndices), existingProgress.TotalSteps)
+
+				// Phase 3: Write/Update todo list with critique validation loop
+				err = hcpo.runWriterPhaseWithHumanReview(ctx, 1)
+				if err != nil {
+					hcpo.GetLogger().Warnf("⚠️ Writer phase with critique validation failed: %w", err)
+				}
+
+				// Return early with completion message
+				return "Todo planning complete. All steps already executed. Final todo list saved as `todo_final.md`.", nil
+			} else if nextIncompleteStep > 0 {
+				// Calculate the last completed step number (1-based) for display
+				lastCompletedStepNumber := max(existingProgress.CompletedStepIndices) + 1 // Convert to 1-based
+
+				resumeContext := fmt.Sprintf("Last updated: %s", existingProgress.LastUpdated.Format("2006-01-02 15:04:05"))
+				if planDrift != "" {
+					resumeContext += fmt.Sprintf("\n\n⚠️ Plan changed: %s. Completed steps may not match the current plan; consider starting from the beginning.", planDrift)
+				}
+
+				requestID := fmt.Sprintf("resume_progress_%d", time.Now().UnixNano())
+				choice, err := hcpo.RequestThreeChoiceFeedback(
+					ctx,
+					requestID,
+					fmt.Sprintf("Found existing progress: %d/%d steps completed. How would you like to proceed?",
+						len(existingProgress.CompletedStepIndices), existingProgress.TotalSteps),
+					fmt.Sprintf("Resume from Step %d", nextIncompleteStep),
+					"Start from Beginning",
+					fmt.Sprintf("Fast Execute (0 to Step %d)", lastCompletedStepNumber),
+					resumeContext,
+					hcpo.getSessionID(),
+					hcpo.getWorkflowID(),
+				)
+				if err != nil {
+					hcpo.GetLogger().Warnf("⚠️ Failed to get user decision for resuming: %w", err)
+					choice = "option1" // Default to resume
+				}
+
+				// Track fast execute mode
+				fastExecuteMode := false
+				fastExecuteEndStep := -1
+
+				switch choice {
+				case "option1": // Resume from next incomplete step
+					startFromStep = nextIncompleteStep - 1 // Convert back to 0-based
+					hcpo.GetLogger().Infof("✅ User chose to resume from step %d", nextIncompleteStep)
+				case "option2": // Start from beginning (normal execution)
+					hcpo.GetLogger().Infof("🔄 User chose to start from beginning, will reset progress")
+					// Delete existing progress and start fresh
+					if err := hcpo.deleteStepProgress(ctx); err != nil {
+						hcpo.GetLogger().Warnf("⚠️ Failed to delete step progress: %w", err)
+					}
+					existingProgress = nil
+					startFromStep = 0
+				case "option3": // Fast execute completed steps
+					hcpo.GetLogger().Infof("⚡ User chose fast execute mode for completed steps")
+					fastExecuteMode = true
+					fastExecuteEndStep = max(existingProgress.CompletedStepIndices)
+					// Delete previous completed indices to re-execute them
+					startFromStep = 0
+					// Reset completed indices for steps to be re-executed
+					var newCompletedIndices []int
+					for _, idx := range existingProgress.CompletedStepIndices {
+						if idx > fastExecuteEndStep {
+							newCompletedIndices = append(newCompletedIndices, idx)
+						}
+					}
+					existingProgress.CompletedStepIndices = newCompletedIndices
+					hcpo.GetLogger().Infof("⚡ Will fast execute steps 0 to %d, then continue with normal execution from step %d", fastExecuteEndStep, nextIncompleteStep)
+				}
+
+				// Store fast execute mode for use in execution loop
+				hcpo.SetFastExecuteMode(fastExecuteMode, fastExecuteEndStep)
+			} else {
+				// This should not happen if logic is correct, but handle edge case
+				hcpo.GetLogger().Warnf("⚠️ Unexpected state: progress exists but couldn't determine next incomplete step. Starting from beginning.")
+				existingProgress = nil
+				startFromStep = 0
+			}
+		}
+	}
+
+	// Phase 2: Execute plan steps one by one (with validation after each step)
+
+	// Safety check: Ensure breakdownSteps is not empty
+	if len(breakdownSteps) == 0 {
+		return "", fmt.Errorf("no steps to execute: breakdownSteps is empty (this should not happen - plan was approved but has no steps)")
+	}
+
+	hcpo.GetLogger().Infof("✅ Proceeding to execution phase with %d steps", len(breakdownSteps))
+
+	// Initialize progress tracking if not already loaded
+	if existingProgress == nil {
+		existingProgress = &StepProgress{
+			CompletedStepIndices: []int{},
+			TotalSteps:           len(breakdownSteps),
+		}
+	}
+	// Progress saved from now on belongs to the current plan
+	existingProgress.PlanChecksum = planChecksum(breakdownSteps)
+
+	_, err = hcpo.runExecutionPhase(ctx, breakdownSteps, 1, existingProgress, startFromStep)
+	if err != nil {
+		return "", fmt.Errorf("execution phase failed: %w", err)
+	}
+
+	// Phase 3: Write/Update todo list with critique validation loop
+	err = hcpo.runWriterPhaseWithHumanReview(ctx, 1)
+	if err != nil {
+		hcpo.GetLogger().Warnf("⚠️ Writer phase with critique validation failed: %w", err)
+	}
+
+	duration := time.Since(hcpo.GetStartTime())
+	hcpo.GetLogger().Infof("✅ Human-controlled todo planning completed in %v", duration)
+
+	return "Todo planning complete. Final todo list saved as `todo_final.md`.", nil
+}
+
+// planTodoSteps runs the planning phases (variable extraction, planning, plan reading and plan
+// approval) and returns the plan steps to execute
+func (hcpo *HumanControlledTodoPlannerOrchestrator) planTodoSteps(ctx context.Context, objective, workspacePath string) ([]TodoStep, error) {
 	// PHASE 0: Variable Extraction with Human Verification (NEW)
 	// Check if variables.json already exists
 	variablesPath := fmt.Sprintf("%s/todo_creation_human/variables/variables.json", workspacePath)
@@ -436,11 +732,12 @@ func (hcpo *HumanControlledTodoPlannerOrchestrator) CreateTodoList(ctx context.C
 					// Safety check: Ensure plan has steps
 					if len(existingPlan.Steps) == 0 {
 						hcpo.GetLogger().Errorf("❌ Existing plan has no steps - plan reader returned empty steps array")
-						return "", fmt.Errorf("existing plan has no steps: plan reader returned empty steps array")
+						return nil, fmt.Errorf("existing plan has no steps: plan reader returned empty steps array")
 					}
 
 					// Convert existing plan to TodoStep format
 					breakdownSteps = hcpo.convertPlanStepsToTodoSteps(existingPlan.Steps)
+					breakdownSteps = hcpo.dedupPlanSteps(ctx, breakdownSteps)
 					hcpo.GetLogger().Infof("✅ Converted existing plan: %d steps extracted", len(breakdownSteps))
 					hcpo.emitTodoStepsExtractedEvent(ctx, breakdownSteps, "existing_plan")
 
@@ -449,7 +746,8 @@ func (hcpo *HumanControlledTodoPlannerOrchestrator) CreateTodoList(ctx context.C
 					var humanFeedback string
 					approved := false
 
-					for revisionAttempt := 1; revisionAttempt <= maxPlanRevisions; revisionAttempt++ {
+					// A dry run previews the plan without asking for approval
+					for revisionAttempt := 1; !hcpo.dryRun && revisionAttempt <= maxPlanRevisions; revisionAttempt++ {
 						hcpo.GetLogger().Infof("🔄 Plan JSON approval attempt %d/%d", revisionAttempt, maxPlanRevisions)
 
 						// Request human approval for JSON plan
@@ -495,265 +793,88 @@ func (hcpo *HumanControlledTodoPlannerOrchestrator) CreateTodoList(ctx context.C
 		}
 	}
 
-	if !planExists {
-		hcpo.GetLogger().Infof("🔄 No existing plan found, creating new plan to execute objective")
-
-		// NOTE: Don't delete existing progress here - only delete when actually starting new execution
-		// This prevents losing progress if plan reader fails or if user chooses to use existing plan
-
-		// Phase 1.9: Planning → Plan Reader → Approval loop
-		maxPlanRevisions := 20 // Allow up to 20 plan revisions
-		// Initialize with initial planning feedback (e.g., from "Update Existing Plan" option)
-		humanFeedback := initialPlanningFeedback
-		var planReaderConversationHistory []llmtypes.MessageContent
-		var approvedPlan *PlanningResponse
-		var err error
-
-		for revisionAttempt := 1; revisionAttempt <= maxPlanRevisions; revisionAttempt++ {
-			hcpo.GetLogger().Infof("🔄 Plan creation/approval attempt %d/%d", revisionAttempt, maxPlanRevisions)
-
-			// Phase 1: Create markdown plan (with optional human feedback)
-			_, planReaderConversationHistory, err = hcpo.runPlanningPhase(ctx, revisionAttempt, humanFeedback, planReaderConversationHistory)
-			if err != nil {
-				return "", fmt.Errorf("planning phase failed: %w", err)
-			}
-
-			// Phase 1.75: Read markdown plan and convert to structured JSON
-			approvedPlan, err = hcpo.runPlanReaderPhase(ctx)
-			if err != nil {
-				return "", fmt.Errorf("plan reader phase failed: %w", err)
-			}
-
-			// Safety check: Ensure plan has steps
-			if len(approvedPlan.Steps) == 0 {
-				return "", fmt.Errorf("new plan has no steps: plan reader returned empty steps array")
-			}
-
-			// Convert approved plan steps to TodoStep format for execution
-			breakdownSteps = hcpo.convertPlanStepsToTodoSteps(approvedPlan.Steps)
-			hcpo.GetLogger().Infof("✅ Converted new plan: %d steps extracted", len(breakdownSteps))
-
-			// Emit todo steps extracted event after plan reader conversion
-			hcpo.emitTodoStepsExtractedEvent(ctx, breakdownSteps, "new_plan_converted")
-
-			// Request human approval for JSON plan (after event emission)
-			approvedInternal, feedbackInternal, err := hcpo.requestPlanApproval(ctx, revisionAttempt)
-			if err != nil {
-				return "", fmt.Errorf("plan approval request failed: %w", err)
-			}
-
-			if approvedInternal {
-				hcpo.GetLogger().Infof("✅ JSON plan approved by human, proceeding to execution with %d steps", len(breakdownSteps))
-				break // Exit retry loop and continue to execution
-			}
-
-			// Plan rejected with feedback for revision
-			hcpo.GetLogger().Infof("🔄 Plan revision requested (attempt %d/%d): %s", revisionAttempt, maxPlanRevisions, feedbackInternal)
-			humanFeedback = feedbackInternal // Store feedback for next iteration
-
-			if revisionAttempt >= maxPlanRevisions {
-				return "", fmt.Errorf("max plan revision<|uniquepaddingtoken122|> attempts (%d) reached", maxPlanRevisions)
-			}
-		}
-
-		// Plan approved and converted, continue to execution
-	}
-
-	// EARLY PROGRESS CHECK: Check if all steps are already completed before proceeding
-	// This prevents running plan reader unnecessarily if all steps are done
-	hcpo.GetLogger().Infof("🔍 Early progress check: Checking if all steps are already completed")
-	hcpo.GetLogger().Infof("🔍 DEBUG: breakdownSteps count before early progress check: %d", len(breakdownSteps))
-
-	earlyProgress, err := hcpo.loadStepProgress(ctx)
-	if err == nil && earlyProgress != nil && len(earlyProgress.CompletedStepIndices) > 0 {
-		hcpo.GetLogger().Infof("📊 Found early progress: %d/%d steps completed",
-			len(earlyProgress.CompletedStepIndices), earlyProgress.TotalSteps)
-
-		// Check if total steps match
-		if earlyProgress.TotalSteps == len(breakdownSteps) {
-			// Calculate if all steps are completed
-			if len(earlyProgress.CompletedStepIndices) == earlyProgress.TotalSteps {
-				hcpo.GetLogger().Infof("✅ ALL steps already completed - skipping to writer phase")
-
-				// Phase 3: Write/Update todo list with critique validation loop
-				err = hcpo.runWriterPhaseWithHumanReview(ctx, 1)
-				if err != nil {
-					hcpo.GetLogger().Warnf("⚠️ Writer phase with critique validation failed: %w", err)
-				}
-
-				// Return early with completion message
-				return "Todo planning complete. All steps already executed. Final todo list saved as `todo_final.md`.", nil
-			}
-			hcpo.GetLogger().Infof("📊 Not all steps completed yet - will proceed with execution")
-		} else {
-			hcpo.GetLogger().Warnf("⚠️ Total steps changed (previous: %d, current: %d), will create new progress",
-				earlyProgress.TotalSteps, len(breakdownSteps))
-			earlyProgress = nil // Don't use old progress if plan changed
-		}
-	}
-
-	// Check for existing progress and ask user if they want to resume
-	var startFromStep int = 0 // 0-based index, 0 means start from beginning
-	var existingProgress *StepProgress
-
-	// Use earlyProgress if available, otherwise load it
-	if earlyProgress != nil {
-		existingProgress = earlyProgress
-		err = nil // Reset err since earlyProgress was successfully loaded earlier
-		hcpo.GetLogger().Infof("✅ Using early progress (avoided reload)")
-	} else {
-		// Check if there's existing progress
-		existingProgress, err = hcpo.loadStepProgress(ctx)
-		if err != nil {
-			// File doesn't exist - this is normal for first run, log and continue
-			hcpo.GetLogger().Infof("ℹ️ No existing progress file found (this is normal for first run), will start fresh execution")
-			existingProgress = nil
-			err = nil // Reset err to allow execution to proceed
-		}
-	}
-
-	// Process existing progress if available
-	if err == nil && existingProgress != nil && len(existingProgress.CompletedStepIndices) > 0 {
-		hcpo.GetLogger().Infof("📊 Found existing progress: %d/%d steps completed",
-			len(existingProgress.CompletedStepIndices), existingProgress.TotalSteps)
-
-		// Check if total steps match (plan might have changed)
-		if existingProgress.TotalSteps != len(breakdownSteps) {
-			hcpo.GetLogger().Warnf("⚠️ Plan has changed (different number of steps), ignoring previous progress")
-			existingProgress = nil
-		} else {
-			// Check if all steps are completed first
-			allStepsCompleted := len(existingProgress.CompletedStepIndices) == existingProgress.TotalSteps
-
-			// Ask user if they want to resume
-			nextIncompleteStep := 0
-			if !allStepsCompleted {
-				for i := 0; i < existingProgress.TotalSteps; i++ {
-					completed := false
-					for _, completedIdx := range existingProgress.CompletedStepIndices {
-						if completedIdx == i {
-							completed = true
-							break
-						}
-					}
-					if !completed {
-						nextIncompleteStep = i + 1 // 1-based for display
-						break
-					}
-				}
-			}
-
-			if allStepsCompleted {
-				// All steps are completed, skip directly to writer phase
-				hcpo.GetLogger().Infof("✅ All steps already completed (%d/%d), skipping execution phase and going directly to writer phase",
-					len(existingProgress.CompletedStepIndices), existingProgress.TotalSteps)
-
-				// Phase 3: Write/Update todo list with critique validation loop
-				err = hcpo.runWriterPhaseWithHumanReview(ctx, 1)
-				if err != nil {
-					hcpo.GetLogger().Warnf("⚠️ Writer phase with critique validation failed: %w", err)
-				}
-
-				// Return early with completion message
-				return "Todo planning complete. All steps already executed. Final todo list saved as `todo_final.md`.", nil
-			} else if nextIncompleteStep > 0 {
-				// Calculate the last completed step number (1-based) for display
-				lastCompletedStepNumber := max(existingProgress.CompletedStepIndices) + 1 // Convert to 1-based
-
-				requestID := fmt.Sprintf("resume_progress_%d", time.Now().UnixNano())
-				choice, err := hcpo.RequestThreeChoiceFeedback(
-					ctx,
-					requestID,
-					fmt.Sprintf("Found existing progress: %d/%d steps completed. How would you like to proceed?",
-						len(existingProgress.CompletedStepIndices), existingProgress.TotalSteps),
-					fmt.Sprintf("Resume from Step %d", nextIncompleteStep),
-					"Start from Beginning",
-					fmt.Sprintf("Fast Execute (0 to Step %d)", lastCompletedStepNumber),
-					fmt.Sprintf("Last updated: %s", existingProgress.LastUpdated.Format("2006-01-02 15:04:05")),
-					hcpo.getSessionID(),
-					hcpo.getWorkflowID(),
-				)
-				if err != nil {
-					hcpo.GetLogger().Warnf("⚠️ Failed to get user decision for resuming: %w", err)
-					choice = "option1" // Default to resume
-				}
-
-				// Track fast execute mode
-				fastExecuteMode := false
-				fastExecuteEndStep := -1
-
-				switch choice {
-				case "option1": // Resume from next incomplete step
-					startFromStep = nextIncompleteStep - 1 // Convert back to 0-based
-					hcpo.GetLogger().Infof("✅ User chose to resume from step %d", nextIncompleteStep)
-				case "option2": // Start from beginning (normal execution)
-					hcpo.GetLogger().Infof("🔄 User chose to start from beginning, will reset progress")
-					// Delete existing progress and start fresh
-					if err := hcpo.deleteStepProgress(ctx); err != nil {
-						hcpo.GetLogger().Warnf("⚠️ Failed to delete step progress: %w", err)
-					}
-					existingProgress = nil
-					startFromStep = 0
-				case "option3": // Fast execute completed steps
-					hcpo.GetLogger().Infof("⚡ User chose fast execute mode for completed steps")
-					fastExecuteMode = true
-					fastExecuteEndStep = max(existingProgress.CompletedStepIndices)
-					// Delete previous completed indices to re-execute them
-					startFromStep = 0
-					// Reset completed indices for steps to be re-executed
-					var newCompletedIndices []int
-					for _, idx := range existingProgress.CompletedStepIndices {
-						if idx > fastExecuteEndStep {
-							newCompletedIndices = append(newCompletedIndices, idx)
-						}
-					}
-					existingProgress.CompletedStepIndices = newCompletedIndices
-					hcpo.GetLogger().Infof("⚡ Will fast execute steps 0 to %d, then continue with normal execution from step %d", fastExecuteEndStep, nextIncompleteStep)
-				}
-
-				// Store fast execute mode for use in execution loop
-				hcpo.SetFastExecuteMode(fastExecuteMode, fastExecuteEndStep)
-			} else {
-				// This should not happen if logic is correct, but handle edge case
-				hcpo.GetLogger().Warnf("⚠️ Unexpected state: progress exists but couldn't determine next incomplete step. Starting from beginning.")
-				existingProgress = nil
-				startFromStep = 0
-			}
-		}
-	}
-
-	// Phase 2: Execute plan steps one by one (with validation after each step)
+	if !planExists {
+		hcpo.GetLogger().Infof("🔄 No existing plan found, creating new plan to execute objective")
 
-	// Safety check: Ensure breakdownSteps is not empty
-	if len(breakdownSteps) == 0 {
-		return "", fmt.Errorf("no steps to execute: breakdownSteps is empty (this should not happen - plan was approved but has no steps)")
-	}
+		// NOTE: Don't delete existing progress here - only delete when actually starting new execution
+		// This prevents losing progress if plan reader fails or if user chooses to use existing plan
 
-	hcpo.GetLogger().Infof("✅ Proceeding to execution phase with %d steps", len(breakdownSteps))
+		// Phase 1.9: Planning → Plan Reader → Approval loop
+		maxPlanRevisions := 20 // Allow up to 20 plan revisions
+		// Initialize with initial planning feedback (e.g., from "Update Existing Plan" option)
+		humanFeedback := initialPlanningFeedback
+		var planReaderConversationHistory []llmtypes.MessageContent
+		var approvedPlan *PlanningResponse
+		var err error
+		feedbackLoop := hcpo.newFeedbackLoopDetector()
 
-	// Initialize progress tracking if not already loaded
-	if existingProgress == nil {
-		existingProgress = &StepProgress{
-			CompletedStepIndices: []int{},
-			TotalSteps:           len(breakdownSteps),
-		}
-	}
+		for revisionAttempt := 1; revisionAttempt <= maxPlanRevisions; revisionAttempt++ {
+			hcpo.GetLogger().Infof("🔄 Plan creation/approval attempt %d/%d", revisionAttempt, maxPlanRevisions)
 
-	_, err = hcpo.runExecutionPhase(ctx, breakdownSteps, 1, existingProgress, startFromStep)
-	if err != nil {
-		return "", fmt.Errorf("execution phase failed: %w", err)
-	}
+			// Phase 1: Create markdown plan (with optional human feedback)
+			_, planReaderConversationHistory, err = hcpo.runPlanningPhase(ctx, revisionAttempt, humanFeedback, planReaderConversationHistory)
+			if err != nil {
+				return nil, fmt.Errorf("planning phase failed: %w", err)
+			}
 
-	// Phase 3: Write/Update todo list with critique validation loop
-	err = hcpo.runWriterPhaseWithHumanReview(ctx, 1)
-	if err != nil {
-		hcpo.GetLogger().Warnf("⚠️ Writer phase with critique validation failed: %w", err)
-	}
+			// Phase 1.75: Read markdown plan and convert to structured JSON
+			approvedPlan, err = hcpo.runPlanReaderPhase(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("plan reader phase failed: %w", err)
+			}
 
-	duration := time.Since(hcpo.GetStartTime())
-	hcpo.GetLogger().Infof("✅ Human-controlled todo planning completed in %v", duration)
+			// Safety check: Ensure plan has steps
+			if len(approvedPlan.Steps) == 0 {
+				return nil, fmt.Errorf("new plan has no steps: plan reader returned empty steps array")
+			}
 
-	return "Todo planning complete. Final todo list saved as `todo_final.md`.", nil
+			// Convert approved plan steps to TodoStep format for execution
+			breakdownSteps = hcpo.convertPlanStepsToTodoSteps(approvedPlan.Steps)
+			breakdownSteps = hcpo.dedupPlanSteps(ctx, breakdownSteps)
+			hcpo.GetLogger().Infof("✅ Converted new plan: %d steps extracted", len(breakdownSteps))
+
+			// Emit todo steps extracted event after plan reader conversion
+			hcpo.emitTodoStepsExtractedEvent(ctx, breakdownSteps, "new_plan_converted")
+
+			// A dry run previews the plan without asking for approval
+			if hcpo.dryRun {
+				break
+			}
+
+			// Request human approval for JSON plan (after event emission)
+			approvedInternal, feedbackInternal, err := hcpo.requestPlanApproval(ctx, revisionAttempt)
+			if err != nil {
+				return nil, fmt.Errorf("plan approval request failed: %w", err)
+			}
+
+			if approvedInternal {
+				hcpo.GetLogger().Infof("✅ JSON plan approved by human, proceeding to execution with %d steps", len(breakdownSteps))
+				break // Exit retry loop and continue to execution
+			}
+
+			// Plan rejected with feedback for revision
+			hcpo.GetLogger().Infof("🔄 Plan revision requested (attempt %d/%d): %s", revisionAttempt, maxPlanRevisions, feedbackInternal)
+
+			// Break out of revision churn when the same feedback keeps coming back
+			approvedAfterLoop, nextFeedback, err := hcpo.resolveRepeatedFeedback(ctx, feedbackLoop, feedbackInternal)
+			if err != nil {
+				return nil, err
+			}
+			if approvedAfterLoop {
+				hcpo.GetLogger().Infof("✅ Proceeding with current plan: %d steps", len(breakdownSteps))
+				break
+			}
+			humanFeedback = nextFeedback // Store feedback for next iteration
+
+			if revisionAttempt >= maxPlanRevisions {
+				return nil, fmt.Errorf("max plan revision<|uniquepaddingtoken122|> attempts (%d) reached", maxPlanRevisions)
+			}
+		}
+
+		// Plan approved and converted, continue to execution
+	}
+
+	return breakdownSteps, nil
 }
 
 // runPlanningPhase creates markdown plan
@@ -861,12 +982,44 @@ func (hcpo *HumanControlledTodoPlannerOrchestrator) convertPlanStepsToTodoSteps(
 			ContextOutput:       step.ContextOutput.String(), // Convert FlexibleContextOutput to string
 			SuccessPatterns:     step.SuccessPatterns,
 			FailurePatterns:     step.FailurePatterns,
+			SuccessThreshold:    step.SuccessThreshold,
 		}
 	}
 	return todoSteps
 }
 
-// runExecutionPhase executes the plan steps one by one
+// stepExecution is the state shared by the steps of one execution phase
+type stepExecution struct {
+	steps     []TodoStep
+	iteration int
+	progress  *StepProgress
+	// Human feedback given so far, passed to the steps starting afterwards. It is only appended
+	// by the human gate, which never runs concurrently with step attempts.
+	feedbackHistory []string
+}
+
+// stepRun is the execution state of one step, kept across automated retries and re-executions
+type stepRun struct {
+	index              int // 0-based step index
+	step               TodoStep
+	history            []llmtypes.MessageContent // Execution conversation history
+	humanFeedback      string                    // Added to the history before the next attempts
+	automatedFailures  int                       // Failed automated attempts, across re-executions
+	lastAttemptPassed  bool
+	validationResponse *ValidationResponse
+}
+
+// stepGateDecision is the outcome of the human gate after a step's automated attempts
+type stepGateDecision int
+
+const (
+	stepGateCompleted stepGateDecision = iota // The step is done and saved to steps_done.json
+	stepGateRetry                             // Run the step's attempts again (automated retry or re-execution with feedback)
+	stepGateStopped                           // The human stopped the step without completing it
+)
+
+// runExecutionPhase executes the plan steps one by one, or by dependency layer when parallel
+// step workers are configured (see SetParallelStepWorkers)
 func (hcpo *HumanControlledTodoPlannerOrchestrator) runExecutionPhase(
 	ctx context.Context,
 	breakdownSteps []TodoStep,
@@ -895,9 +1048,9 @@ func (hcpo *HumanControlledTodoPlannerOrchestrator) runExecutionPhase(
 	}
 
 	// Track human feedback across all steps for continuous improvement
-	var humanFeedbackHistory []string
+	execution := &stepExecution{steps: breakdownSteps, iteration: iteration, progress: progress}
 
-	// Execute each step one by one
+	var pending []int
 	for i, step := range breakdownSteps {
 		// Skip if step is already completed
 		if i < startFromStep {
@@ -919,326 +1072,418 @@ func (hcpo *HumanControlledTodoPlannerOrchestrator) runExecutionPhase(
 				i+1, len(breakdownSteps), step.Title)
 			continue
 		}
+		pending = append(pending, i)
+	}
 
-		hcpo.GetLogger().Infof("📋 Executing step %d/%d: %s", i+1, len(breakdownSteps), step.Title)
-
-		// Initialize variables for step execution
-		maxRetryAttempts := 3
-		var executionConversationHistory []llmtypes.MessageContent
-		var humanFeedback string
-		stepCompleted := false
-
-		// Outer loop: Handle re-execution with human feedback
-		for !stepCompleted {
-			// Add human feedback to conversation history if provided
-			if humanFeedback != "" {
-				humanFeedbackMessage := llmtypes.MessageContent{
-					Role: llmtypes.ChatMessageTypeHuman,
-					Parts: []llmtypes.ContentPart{llmtypes.TextContent{
-						Text: fmt.Sprintf("## Human Feedback for Step %d:\n%s", i+1, humanFeedback),
-					}},
-				}
-				executionConversationHistory = append(executionConversationHistory, humanFeedbackMessage)
-				hcpo.GetLogger().Infof("📝 Added human feedback to conversation history for step %d", i+1)
-				humanFeedback = "" // Reset for next iteration
-			}
+	// runStepAttempts creates the execution and validation agents of each step
+	attempt := hcpo.runStepAttempts
+	if hcpo.stepAttemptFunc != nil {
+		attempt = hcpo.stepAttemptFunc
+	}
 
-			// Prepare template variables for this specific step with individual fields
-			// RESOLVE VARIABLES: Replace {{VARS}} with actual values for execution
-			templateVars := map[string]string{
-				"StepNumber":          fmt.Sprintf("%d", i+1),
-				"TotalSteps":          fmt.Sprintf("%d", len(breakdownSteps)),
-				"StepTitle":           hcpo.resolveVariables(step.Title),
-				"StepDescription":     hcpo.resolveVariables(step.Description),
-				"StepSuccessCriteria": hcpo.resolveVariables(step.SuccessCriteria),
-				"StepWhyThisStep":     hcpo.resolveVariables(step.WhyThisStep),
-				"StepContextOutput":   hcpo.resolveVariables(step.ContextOutput),
-				"WorkspacePath":       hcpo.GetWorkspacePath(),
-				"LearningAgentOutput": "", // Will be populated with learning agent's output
-			}
+	if hcpo.parallelStepWorkers > 1 {
+		if err := hcpo.runStepLayers(ctx, execution, pending, attempt, hcpo.gateStep); err != nil {
+			return nil, err
+		}
+		hcpo.GetLogger().Infof("✅ All steps execution completed")
+		return nil, nil
+	}
 
-			// Combine success and failure patterns from plan breakdown into LearningAgentOutput
-			var learningOutputParts []string
-			if len(step.SuccessPatterns) > 0 {
-				learningOutputParts = append(learningOutputParts, "## ✅ Success Patterns from Plan:")
-				for _, pattern := range step.SuccessPatterns {
-					learningOutputParts = append(learningOutputParts, fmt.Sprintf("- Success Pattern: %s", pattern))
-				}
+	// Execute each step one by one
+	for _, i := range pending {
+		run := &stepRun{index: i, step: breakdownSteps[i]}
+		hcpo.GetLogger().Infof("📋 Executing step %d/%d: %s", i+1, len(breakdownSteps), run.step.Title)
+
+		// Re-run the step's attempts until the human gate completes or stops it
+		for {
+			if err := attempt(ctx, execution, run); err != nil {
+				return nil, err
 			}
-			if len(step.FailurePatterns) > 0 {
-				learningOutputParts = append(learningOutputParts, "## ❌ Failure Patterns from Plan:")
-				for _, pattern := range step.FailurePatterns {
-					learningOutputParts = append(learningOutputParts, fmt.Sprintf("- Failure Pattern: %s", pattern))
-				}
+			decision := hcpo.gateStep(ctx, execution, run)
+			if decision == stepGateCompleted {
+				hcpo.markStepCompleted(ctx, execution, i)
 			}
-
-			if len(learningOutputParts) > 0 {
-				templateVars["LearningAgentOutput"] = strings.Join(learningOutputParts, "\n")
-			} else {
-				templateVars["LearningAgentOutput"] = ""
+			if decision != stepGateRetry {
+				break
 			}
+		}
+	}
 
-			// Add context dependencies as a comma-separated string (also resolve variables)
-			if len(step.ContextDependencies) > 0 {
-				resolvedDeps := make([]string, len(step.ContextDependencies))
-				for idx, dep := range step.ContextDependencies {
-					resolvedDeps[idx] = hcpo.resolveVariables(dep)
-				}
-				templateVars["StepContextDependencies"] = strings.Join(resolvedDeps, ", ")
-			} else {
-				templateVars["StepContextDependencies"] = ""
-			}
+	hcpo.GetLogger().Infof("✅ All steps execution completed")
+	return nil, nil
+}
 
-			// Add variable names if available (same format as other agents)
-			if variableNames := hcpo.formatVariableNames(); variableNames != "" {
-				templateVars["VariableNames"] = variableNames
-			}
+// runStepAttempts executes and validates a step with automatic retries, running the learning
+// phases after each validation. Failures are recorded on run for the human gate; an error is only
+// returned when the execution agent cannot be created.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) runStepAttempts(ctx context.Context, execution *stepExecution, run *stepRun) error {
+	i, totalSteps := run.index, len(execution.steps)
+	step := &run.step
+	maxRetryAttempts := 3
+
+	// Add human feedback to conversation history if provided
+	if run.humanFeedback != "" {
+		humanFeedbackMessage := llmtypes.MessageContent{
+			Role: llmtypes.ChatMessageTypeHuman,
+			Parts: []llmtypes.ContentPart{llmtypes.TextContent{
+				Text: fmt.Sprintf("## Human Feedback for Step %d:\n%s", i+1, run.humanFeedback),
+			}},
+		}
+		run.history = append(run.history, humanFeedbackMessage)
+		hcpo.GetLogger().Infof("📝 Added human feedback to conversation history for step %d", i+1)
+		run.humanFeedback = "" // Reset for next iteration
+	}
+
+	// Prepare template variables for this specific step with individual fields
+	// RESOLVE VARIABLES: Replace {{VARS}} with actual values for execution
+	templateVars := map[string]string{
+		"StepNumber":          fmt.Sprintf("%d", i+1),
+		"TotalSteps":          fmt.Sprintf("%d", totalSteps),
+		"StepTitle":           hcpo.resolveVariables(step.Title),
+		"StepDescription":     hcpo.resolveVariables(step.Description),
+		"StepSuccessCriteria": hcpo.resolveVariables(step.SuccessCriteria),
+		"StepWhyThisStep":     hcpo.resolveVariables(step.WhyThisStep),
+		"StepContextOutput":   hcpo.resolveVariables(step.ContextOutput),
+		"WorkspacePath":       hcpo.GetWorkspacePath(),
+		"LearningAgentOutput": "", // Will be populated with learning agent's output
+	}
 
-			// Add variable values if available (name = value - description format)
-			if variableValues := hcpo.formatVariableValues(); variableValues != "" {
-				templateVars["VariableValues"] = variableValues
-			}
+	// Combine success and failure patterns from plan breakdown into LearningAgentOutput
+	var learningOutputParts []string
+	if len(step.SuccessPatterns) > 0 {
+		learningOutputParts = append(learningOutputParts, "## ✅ Success Patterns from Plan:")
+		for _, pattern := range step.SuccessPatterns {
+			learningOutputParts = append(learningOutputParts, fmt.Sprintf("- Success Pattern: %s", pattern))
+		}
+	}
+	if len(step.FailurePatterns) > 0 {
+		learningOutputParts = append(learningOutputParts, "## ❌ Failure Patterns from Plan:")
+		for _, pattern := range step.FailurePatterns {
+			learningOutputParts = append(learningOutputParts, fmt.Sprintf("- Failure Pattern: %s", pattern))
+		}
+	}
 
-			// Add human feedback from previous steps to conversation history (first iteration only)
-			if len(humanFeedbackHistory) > 0 && len(executionConversationHistory) == 0 {
-				previousFeedbackMessage := llmtypes.MessageContent{
-					Role: llmtypes.ChatMessageTypeHuman,
-					Parts: []llmtypes.ContentPart{llmtypes.TextContent{
-						Text: fmt.Sprintf("## Previous Steps' Feedback for Context:\n%s", strings.Join(humanFeedbackHistory, "\n---\n")),
-					}},
-				}
-				executionConversationHistory = append(executionConversationHistory, previousFeedbackMessage)
-				hcpo.GetLogger().Infof("📝 Added human feedback from previous steps to conversation history for step %d", i+1)
-			}
+	if len(learningOutputParts) > 0 {
+		templateVars["LearningAgentOutput"] = strings.Join(learningOutputParts, "\n")
+	} else {
+		templateVars["LearningAgentOutput"] = ""
+	}
 
-			// Inner loop: Automatic retry logic
-			var validationFeedback []ValidationFeedback
-			var validationResponse *ValidationResponse
+	// Add context dependencies as a comma-separated string (also resolve variables)
+	if len(step.ContextDependencies) > 0 {
+		resolvedDeps := make([]string, len(step.ContextDependencies))
+		for idx, dep := range step.ContextDependencies {
+			resolvedDeps[idx] = hcpo.resolveVariables(dep)
+		}
+		templateVars["StepContextDependencies"] = strings.Join(resolvedDeps, ", ")
+	} else {
+		templateVars["StepContextDependencies"] = ""
+	}
 
-			for retryAttempt := 1; retryAttempt <= maxRetryAttempts; retryAttempt++ {
-				hcpo.GetLogger().Infof("🔄 Executing step %d/%d (attempt %d/%d): %s", i+1, len(breakdownSteps), retryAttempt, maxRetryAttempts, step.Title)
+	// Inject recorded outputs of the steps this step depends on
+	templateVars["DependencyOutputs"] = hcpo.formatDependencyOutputs(*step)
 
-				// Add validation feedback to template variables if this is a retry
-				if retryAttempt > 1 && validationFeedback != nil {
-					feedbackJSON, _ := json.Marshal(validationFeedback)
-					templateVars["ValidationFeedback"] = fmt.Sprintf("## Validation Feedback (Retry Attempt %d):\n%s", retryAttempt, string(feedbackJSON))
-					hcpo.GetLogger().Infof("📝 Added validation feedback to template variables for step %d, retry %d", i+1, retryAttempt)
-				} else {
-					templateVars["ValidationFeedback"] = "" // No validation feedback for first attempt
-				}
+	// Add variable names if available (same format as other agents)
+	if variableNames := hcpo.formatVariableNames(); variableNames != "" {
+		templateVars["VariableNames"] = variableNames
+	}
 
-				// Create execution agent for this step
-				// Resolve variables in step title before using in agent name
-				resolvedTitle := hcpo.resolveVariables(step.Title)
-				agentName := fmt.Sprintf("execution-agent-step-%d-%s", i+1, strings.ReplaceAll(resolvedTitle, " ", "-"))
-				executionAgent, err := hcpo.createExecutionAgent(ctx, "execution", i+1, iteration, agentName)
-				if err != nil {
-					return nil, fmt.Errorf("failed to create execution agent for step %d: %w", i+1, err)
-				}
+	// Add variable values if available (name = value - description format)
+	if variableValues := hcpo.formatVariableValues(); variableValues != "" {
+		templateVars["VariableValues"] = variableValues
+	}
 
-				// Execute this specific step with execution conversation history
-				_, executionConversationHistory, err = executionAgent.Execute(ctx, templateVars, executionConversationHistory)
-				if err != nil {
-					hcpo.GetLogger().Warnf("⚠️ Step %d execution failed (attempt %d): %v", i+1, retryAttempt, err)
-					if retryAttempt >= maxRetryAttempts {
-						hcpo.GetLogger().Errorf("❌ Step %d execution failed after %d attempts, exiting retry loop", i+1, maxRetryAttempts)
-						break // Exit retry loop - will proceed to human feedback
-					}
-					continue // Retry on next attempt
-				}
+	// Add human feedback from previous steps to conversation history (first iteration only)
+	if len(execution.feedbackHistory) > 0 && len(run.history) == 0 {
+		previousFeedbackMessage := llmtypes.MessageContent{
+			Role: llmtypes.ChatMessageTypeHuman,
+			Parts: []llmtypes.ContentPart{llmtypes.TextContent{
+				Text: fmt.Sprintf("## Previous Steps' Feedback for Context:\n%s", strings.Join(execution.feedbackHistory, "\n---\n")),
+			}},
+		}
+		run.history = append(run.history, previousFeedbackMessage)
+		hcpo.GetLogger().Infof("📝 Added human feedback from previous steps to conversation history for step %d", i+1)
+	}
 
-				hcpo.GetLogger().Infof("✅ Step %d execution completed successfully (attempt %d)", i+1, retryAttempt)
+	// Automatic retry logic
+	var validationFeedback []ValidationFeedback
+	run.validationResponse = nil
+	run.lastAttemptPassed = false
 
-				// Validate this step's execution using structured output
-				hcpo.GetLogger().Infof("🔍 Validating step %d execution (attempt %d)", i+1, retryAttempt)
+	for retryAttempt := 1; retryAttempt <= maxRetryAttempts; retryAttempt++ {
+		hcpo.GetLogger().Infof("🔄 Executing step %d/%d (attempt %d/%d): %s", i+1, totalSteps, retryAttempt, maxRetryAttempts, step.Title)
 
-				// Reuse resolved title from execution agent (already resolved above)
-				validationAgentName := fmt.Sprintf("validation-agent-step-%d-%s", i+1, strings.ReplaceAll(resolvedTitle, " ", "-"))
-				validationAgent, err := hcpo.createValidationAgent(ctx, "validation", i+1, iteration, validationAgentName)
-				if err != nil {
-					hcpo.GetLogger().Warnf("⚠️ Failed to create validation agent for step %d: %v", i+1, err)
-					if retryAttempt >= maxRetryAttempts {
-						break // Exit retry loop - will proceed to human feedback
-					}
-					continue // Retry on next attempt
-				}
+		// Add validation feedback to template variables if this is a retry
+		if retryAttempt > 1 && validationFeedback != nil {
+			feedbackJSON, _ := json.Marshal(validationFeedback)
+			templateVars["ValidationFeedback"] = fmt.Sprintf("## Validation Feedback (Retry Attempt %d):\n%s", retryAttempt, string(feedbackJSON))
+			hcpo.GetLogger().Infof("📝 Added validation feedback to template variables for step %d, retry %d", i+1, retryAttempt)
+		} else {
+			templateVars["ValidationFeedback"] = "" // No validation feedback for first attempt
+		}
 
-				// Prepare validation template variables with individual fields
-				validationTemplateVars := map[string]string{
-					"StepNumber":          fmt.Sprintf("%d", i+1),
-					"TotalSteps":          fmt.Sprintf("%d", len(breakdownSteps)),
-					"StepTitle":           step.Title,
-					"StepDescription":     step.Description,
-					"StepSuccessCriteria": step.SuccessCriteria,
-					"StepWhyThisStep":     step.WhyThisStep,
-					"StepContextOutput":   step.ContextOutput,
-					"WorkspacePath":       hcpo.GetWorkspacePath(),
-					"ExecutionHistory":    shared.FormatConversationHistory(executionConversationHistory),
-				}
+		// Create execution agent for this step
+		// Resolve variables in step title before using in agent name
+		resolvedTitle := hcpo.resolveVariables(step.Title)
+		agentName := fmt.Sprintf("execution-agent-step-%d-%s", i+1, strings.ReplaceAll(resolvedTitle, " ", "-"))
+		executionAgent, err := hcpo.createExecutionAgent(ctx, "execution", i+1, execution.iteration, agentName)
+		if err != nil {
+			return fmt.Errorf("failed to create execution agent for step %d: %w", i+1, err)
+		}
 
-				// Add context dependencies as a comma-separated string
-				if len(step.ContextDependencies) > 0 {
-					validationTemplateVars["StepContextDependencies"] = strings.Join(step.ContextDependencies, ", ")
-				} else {
-					validationTemplateVars["StepContextDependencies"] = ""
-				}
+		// Execute this specific step with execution conversation history
+		var executionOutput string
+		executionOutput, run.history, err = executionAgent.Execute(ctx, templateVars, run.history)
+		if err != nil {
+			hcpo.GetLogger().Warnf("⚠️ Step %d execution failed (attempt %d): %v", i+1, retryAttempt, err)
+			run.automatedFailures++
+			if retryAttempt >= maxRetryAttempts {
+				hcpo.GetLogger().Errorf("❌ Step %d execution failed after %d attempts, exiting retry loop", i+1, maxRetryAttempts)
+				break // Exit retry loop - will proceed to human feedback
+			}
+			continue // Retry on next attempt
+		}
 
-				// Validate this step's execution using structured output
-				validationResponse, err = validationAgent.(*HumanControlledTodoPlannerValidationAgent).ExecuteStructured(ctx, validationTemplateVars, []llmtypes.MessageContent{})
-				if err != nil {
-					hcpo.GetLogger().Warnf("⚠️ Step %d validation failed (attempt %d): %v", i+1, retryAttempt, err)
-					if retryAttempt >= maxRetryAttempts {
-						break // Exit retry loop - will proceed to human feedback with nil validationResponse
-					}
-					continue // Retry on next attempt
-				}
+		hcpo.GetLogger().Infof("✅ Step %d execution completed successfully (attempt %d)", i+1, retryAttempt)
+
+		// Record the latest output so dependent steps can receive it
+		hcpo.recordStepOutput(i+1, *step, executionOutput)
+
+		// Validate this step's execution using structured output
+		hcpo.GetLogger().Infof("🔍 Validating step %d execution (attempt %d)", i+1, retryAttempt)
+
+		// Reuse resolved title from execution agent (already resolved above)
+		validationAgentName := fmt.Sprintf("validation-agent-step-%d-%s", i+1, strings.ReplaceAll(resolvedTitle, " ", "-"))
+		validationAgent, err := hcpo.createValidationAgent(ctx, "validation", i+1, execution.iteration, validationAgentName)
+		if err != nil {
+			hcpo.GetLogger().Warnf("⚠️ Failed to create validation agent for step %d: %v", i+1, err)
+			run.automatedFailures++
+			if retryAttempt >= maxRetryAttempts {
+				break // Exit retry loop - will proceed to human feedback
+			}
+			continue // Retry on next attempt
+		}
+
+		// Prepare validation template variables with individual fields
+		validationTemplateVars := map[string]string{
+			"StepNumber":          fmt.Sprintf("%d", i+1),
+			"TotalSteps":          fmt.Sprintf("%d", totalSteps),
+			"StepTitle":           step.Title,
+			"StepDescription":     step.Description,
+			"StepSuccessCriteria": step.SuccessCriteria,
+			"StepWhyThisStep":     step.WhyThisStep,
+			"StepContextOutput":   step.ContextOutput,
+			"WorkspacePath":       hcpo.GetWorkspacePath(),
+			"ExecutionHistory":    shared.FormatConversationHistory(run.history),
+		}
+
+		// Add context dependencies as a comma-separated string
+		if len(step.ContextDependencies) > 0 {
+			validationTemplateVars["StepContextDependencies"] = strings.Join(step.ContextDependencies, ", ")
+		} else {
+			validationTemplateVars["StepContextDependencies"] = ""
+		}
+
+		// Validate this step's execution using structured output
+		stepThreshold := hcpo.GetStepSuccessThreshold(*step)
+		validationResponse, err := validationAgent.(*HumanControlledTodoPlannerValidationAgent).ExecuteStructured(ctx, validationTemplateVars, []llmtypes.MessageContent{}, stepThreshold)
+		run.validationResponse = validationResponse
+		if err != nil {
+			hcpo.GetLogger().Warnf("⚠️ Step %d validation failed (attempt %d): %v", i+1, retryAttempt, err)
+			run.automatedFailures++
+			if retryAttempt >= maxRetryAttempts {
+				break // Exit retry loop - will proceed to human feedback with nil validationResponse
+			}
+			continue // Retry on next attempt
+		}
 
-				hcpo.GetLogger().Infof("✅ Step %d validation completed successfully (attempt %d)", i+1, retryAttempt)
-				hcpo.GetLogger().Infof("📊 Validation result: Success Criteria Met: %v, Status: %s", validationResponse.IsSuccessCriteriaMet, validationResponse.ExecutionStatus)
+		hcpo.GetLogger().Infof("✅ Step %d validation completed successfully (attempt %d)", i+1, retryAttempt)
+		stepPassed := validationResponse.Passes(stepThreshold)
+		scoreText := "n/a"
+		if validationResponse.Score != nil {
+			scoreText = fmt.Sprintf("%.2f", *validationResponse.Score)
+		}
+		hcpo.GetLogger().Infof("📊 Validation result: Success Criteria Met: %v, Score: %s, Threshold: %.2f, Passed: %v, Status: %s", validationResponse.IsSuccessCriteriaMet, scoreText, stepThreshold, stepPassed, validationResponse.ExecutionStatus)
 
-				// FAST MODE: Skip learning agents entirely
-				isFastExecuteStep := hcpo.IsFastExecuteStep(i)
-				if isFastExecuteStep {
-					hcpo.GetLogger().Infof("⚡ Fast mode: Skipping learning agents for step %d", i+1)
+		// FAST MODE: Skip learning agents entirely
+		isFastExecuteStep := hcpo.IsFastExecuteStep(i)
+		if isFastExecuteStep {
+			hcpo.GetLogger().Infof("⚡ Fast mode: Skipping learning agents for step %d", i+1)
+		} else {
+			// Run appropriate learning phase based on validation result
+			if stepPassed {
+				// Success Learning Agent - analyze what worked well and update plan.json
+				hcpo.GetLogger().Infof("🧠 Running success learning analysis for step %d", i+1)
+				successLearningOutput, err := hcpo.runSuccessLearningPhase(ctx, i+1, totalSteps, step, run.history, validationResponse)
+				if err != nil {
+					hcpo.GetLogger().Warnf("⚠️ Success learning phase failed for step %d: %v", i+1, err)
 				} else {
-					// Run appropriate learning phase based on validation result
-					if validationResponse.IsSuccessCriteriaMet {
-						// Success Learning Agent - analyze what worked well and update plan.json
-						hcpo.GetLogger().Infof("🧠 Running success learning analysis for step %d", i+1)
-						successLearningOutput, err := hcpo.runSuccessLearningPhase(ctx, i+1, len(breakdownSteps), &step, executionConversationHistory, validationResponse)
-						if err != nil {
-							hcpo.GetLogger().Warnf("⚠️ Success learning phase failed for step %d: %v", i+1, err)
-						} else {
-							hcpo.GetLogger().Infof("✅ Success learning analysis completed for step %d", i+1)
-
-							// Append success learning analysis to existing LearningAgentOutput
-							if successLearningOutput != "" {
-								existingOutput := templateVars["LearningAgentOutput"]
-								if existingOutput != "" {
-									templateVars["LearningAgentOutput"] = existingOutput + "\n\n" + successLearningOutput
-								} else {
-									templateVars["LearningAgentOutput"] = successLearningOutput
-								}
-							}
-						}
-					} else {
-						// Failure Learning Agent - analyze what went wrong and provide refined task description
-						hcpo.GetLogger().Infof("🧠 Running failure learning analysis for step %d", i+1)
-						refinedTaskDescription, learningAnalysis, err := hcpo.runFailureLearningPhase(ctx, i+1, len(breakdownSteps), &step, executionConversationHistory, validationResponse)
-						if err != nil {
-							hcpo.GetLogger().Warnf("⚠️ Failure learning phase failed for step %d: %v", i+1, err)
+					hcpo.GetLogger().Infof("✅ Success learning analysis completed for step %d", i+1)
+
+					// Append success learning analysis to existing LearningAgentOutput
+					if successLearningOutput != "" {
+						existingOutput := templateVars["LearningAgentOutput"]
+						if existingOutput != "" {
+							templateVars["LearningAgentOutput"] = existingOutput + "\n\n" + successLearningOutput
 						} else {
-							hcpo.GetLogger().Infof("✅ Failure learning analysis completed for step %d", i+1)
-
-							// Update step description for retry
-							if refinedTaskDescription != "" {
-								step.Description = refinedTaskDescription
-								templateVars["StepDescription"] = refinedTaskDescription
-								hcpo.GetLogger().Infof("🔄 Updated step %d description with refined task for retry", i+1)
-							}
-
-							// Update LearningAgentOutput with full learning analysis
-							if learningAnalysis != "" {
-								existingOutput := templateVars["LearningAgentOutput"]
-								if existingOutput != "" {
-									templateVars["LearningAgentOutput"] = existingOutput + "\n\n" + learningAnalysis
-								} else {
-									templateVars["LearningAgentOutput"] = learningAnalysis
-								}
-							}
+							templateVars["LearningAgentOutput"] = successLearningOutput
 						}
 					}
 				}
-
-				// Check if success criteria was met
-				if validationResponse.IsSuccessCriteriaMet {
-					hcpo.GetLogger().Infof("✅ Step %d passed validation - success criteria met", i+1)
-					break // Exit retry loop and continue to next step
+			} else {
+				// Failure Learning Agent - analyze what went wrong and provide refined task description
+				hcpo.GetLogger().Infof("🧠 Running failure learning analysis for step %d", i+1)
+				refinedTaskDescription, learningAnalysis, err := hcpo.runFailureLearningPhase(ctx, i+1, totalSteps, step, run.history, validationResponse)
+				if err != nil {
+					hcpo.GetLogger().Warnf("⚠️ Failure learning phase failed for step %d: %v", i+1, err)
 				} else {
-					hcpo.GetLogger().Warnf("⚠️ Step %d failed validation - success criteria not met (attempt %d/%d)", i+1, retryAttempt, maxRetryAttempts)
+					hcpo.GetLogger().Infof("✅ Failure learning analysis completed for step %d", i+1)
 
-					// Store feedback for next retry attempt
-					validationFeedback = validationResponse.Feedback
+					// Update step description for retry
+					if refinedTaskDescription != "" {
+						step.Description = refinedTaskDescription
+						templateVars["StepDescription"] = refinedTaskDescription
+						hcpo.GetLogger().Infof("🔄 Updated step %d description with refined task for retry", i+1)
+					}
 
-					if retryAttempt >= maxRetryAttempts {
-						hcpo.GetLogger().Errorf("❌ Step %d failed validation after %d attempts", i+1, maxRetryAttempts)
-						// Continue to next step even if validation failed
-						break
-					} else {
-						hcpo.GetLogger().Infof("🔄 Retrying step %d execution with validation feedback", i+1)
-						// Note: conversation history is preserved from previous attempts for context
+					// Update LearningAgentOutput with full learning analysis
+					if learningAnalysis != "" {
+						existingOutput := templateVars["LearningAgentOutput"]
+						if existingOutput != "" {
+							templateVars["LearningAgentOutput"] = existingOutput + "\n\n" + learningAnalysis
+						} else {
+							templateVars["LearningAgentOutput"] = learningAnalysis
+						}
 					}
 				}
 			}
+		}
+
+		// Check if success criteria was met (or the score reached the step threshold)
+		if stepPassed {
+			hcpo.GetLogger().Infof("✅ Step %d passed validation - success criteria met", i+1)
+			run.lastAttemptPassed = true
+			break // Exit retry loop and continue to next step
+		} else {
+			run.automatedFailures++
+			hcpo.GetLogger().Warnf("⚠️ Step %d failed validation - success criteria not met (attempt %d/%d)", i+1, retryAttempt, maxRetryAttempts)
 
-			// BLOCKING HUMAN FEEDBACK - Ask user if they want to continue to next step or re-execute current step
-			// FAST MODE: Skip human feedback and auto-approve
-			isFastExecuteStep := hcpo.IsFastExecuteStep(i)
-			var approved bool
-			var feedback string
+			// Store feedback for next retry attempt
+			validationFeedback = validationResponse.Feedback
 
-			if isFastExecuteStep {
-				hcpo.GetLogger().Infof("⚡ Fast mode: Auto-approving step %d without human feedback", i+1)
-				approved = true
-				feedback = "" // No feedback in fast mode
+			if retryAttempt >= maxRetryAttempts {
+				hcpo.GetLogger().Errorf("❌ Step %d failed validation after %d attempts", i+1, maxRetryAttempts)
+				// Continue to next step even if validation failed
+				break
 			} else {
-				// Normal mode: Request human feedback
-				var validationSummary string
-				if validationResponse != nil {
-					validationSummary = fmt.Sprintf("Step %d validation completed. Success Criteria Met: %v, Status: %s", i+1, validationResponse.IsSuccessCriteriaMet, validationResponse.ExecutionStatus)
-				} else {
-					validationSummary = fmt.Sprintf("Step %d execution failed - no validation response available", i+1)
-				}
-				var err error
-				approved, feedback, err = hcpo.requestHumanFeedback(ctx, i+1, len(breakdownSteps), validationSummary)
-				if err != nil {
-					hcpo.GetLogger().Warnf("⚠️ Human feedback request failed: %w", err)
-					// Default to continue if feedback fails
-					approved = true
-				}
+				hcpo.GetLogger().Infof("🔄 Retrying step %d execution with validation feedback", i+1)
+				// Note: conversation history is preserved from previous attempts for context
 			}
+		}
+	}
+	return nil
+}
 
-			// Store human feedback for future steps (even if approved, user might have provided guidance)
-			if feedback != "" {
-				feedbackEntry := fmt.Sprintf("Step %d/%d Feedback: %s", i+1, len(breakdownSteps), feedback)
-				humanFeedbackHistory = append(humanFeedbackHistory, feedbackEntry)
-				hcpo.GetLogger().Infof("📝 Stored human feedback for future steps: %s", feedbackEntry)
-			}
+// gateStep is the BLOCKING HUMAN FEEDBACK after a step's attempts: it asks the user whether to
+// continue to the next step or re-execute the current one. Fast mode and passing steps under the
+// escalation policy skip the question.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) gateStep(ctx context.Context, execution *stepExecution, run *stepRun) stepGateDecision {
+	i, totalSteps := run.index, len(execution.steps)
+
+	// FAST MODE: Skip human feedback and auto-approve
+	isFastExecuteStep := hcpo.IsFastExecuteStep(i)
+	var approved bool
+	var feedback string
+
+	if isFastExecuteStep {
+		hcpo.GetLogger().Infof("⚡ Fast mode: Auto-approving step %d without human feedback", i+1)
+		approved = true
+		feedback = "" // No feedback in fast mode
+	} else if !hcpo.shouldEscalateToHuman(run.lastAttemptPassed, run.automatedFailures) {
+		if run.lastAttemptPassed {
+			hcpo.GetLogger().Infof("✅ Step %d passed validation - auto-proceeding without human feedback", i+1)
+			approved = true
+		} else {
+			// Below the escalation threshold: run another round of automated retries
+			hcpo.GetLogger().Infof("🔄 Step %d has %d/%d automated failures - retrying before escalating to human", i+1, run.automatedFailures, hcpo.humanEscalationAfterFailures)
+			return stepGateRetry
+		}
+	} else {
+		// Normal mode: Request human feedback
+		var validationSummary string
+		if run.validationResponse != nil {
+			validationSummary = fmt.Sprintf("Step %d validation completed. Success Criteria Met: %v, Status: %s", i+1, run.validationResponse.IsSuccessCriteriaMet, run.validationResponse.ExecutionStatus)
+		} else {
+			validationSummary = fmt.Sprintf("Step %d execution failed - no validation response available", i+1)
+		}
+		var err error
+		approved, feedback, err = hcpo.requestHumanFeedback(ctx, i+1, totalSteps, validationSummary)
+		if errors.Is(err, orchestrator.ErrFeedbackTimeout) {
+			// Nobody answered (abandoned session): leave the step incomplete and skip to the next one
+			hcpo.GetLogger().Warnf("⌛ No approval for step %d before the feedback timeout - skipping to the next step", i+1)
+			return stepGateStopped
+		}
+		if err != nil {
+			hcpo.GetLogger().Warnf("⚠️ Human feedback request failed: %w", err)
+			// Default to continue if feedback fails
+			approved = true
+		}
+	}
 
-			if approved {
-				// User approved - mark step as completed and exit outer loop
-				progress.CompletedStepIndices = append(progress.CompletedStepIndices, i)
-				if err := hcpo.saveStepProgress(ctx, progress); err != nil {
-					hcpo.GetLogger().Warnf("⚠️ Failed to save step progress: %w", err)
-				} else {
-					hcpo.GetLogger().Infof("✅ Step %d/%d marked as completed and saved", i+1, len(breakdownSteps))
-				}
-				stepCompleted = true
-			} else if !isFastExecuteStep {
-				// User rejected - ask if they want to re-execute this step with feedback or move to next step
-				// Skip this in fast mode (should not happen anyway since we auto-approve)
-				shouldReexecute, err := hcpo.requestReexecuteDecision(ctx, i+1, len(breakdownSteps), feedback)
-				if err != nil {
-					hcpo.GetLogger().Warnf("⚠️ Re-execution decision request failed: %w", err)
-					shouldReexecute = false // Default to stop if decision fails
-				}
+	// Store human feedback for future steps (even if approved, user might have provided guidance)
+	if feedback != "" {
+		feedbackEntry := fmt.Sprintf("Step %d/%d Feedback: %s", i+1, totalSteps, feedback)
+		execution.feedbackHistory = append(execution.feedbackHistory, feedbackEntry)
+		hcpo.GetLogger().Infof("📝 Stored human feedback for future steps: %s", feedbackEntry)
+	}
 
-				if shouldReexecute {
-					// User wants to re-execute - set feedback and continue outer loop
-					hcpo.GetLogger().Infof("🔄 Will re-execute step %d with human feedback: %s", i+1, feedback)
-					humanFeedback = feedback
-					// Outer loop will continue, adding feedback to conversation history
-				} else {
-					// User wants to stop execution
-					hcpo.GetLogger().Infof("🛑 User requested to stop execution after step %d with feedback: %s", i+1, feedback)
-					break // Break out of outer loop (ends all steps)
-				}
-			}
-		} // End of outer loop for step execution
+	if approved {
+		return stepGateCompleted
+	}
+	if isFastExecuteStep {
+		// Should not happen since fast mode auto-approves
+		return stepGateRetry
 	}
 
-	hcpo.GetLogger().Infof("✅ All steps execution completed")
-	return nil, nil
+	// User rejected - ask if they want to re-execute this step with feedback or move to next step
+	shouldReexecute, err := hcpo.requestReexecuteDecision(ctx, i+1, totalSteps, feedback)
+	if err != nil {
+		hcpo.GetLogger().Warnf("⚠️ Re-execution decision request failed: %w", err)
+		shouldReexecute = false // Default to stop if decision fails
+	}
+
+	if shouldReexecute {
+		// User wants to re-execute - the feedback is added to the conversation history of the next attempts
+		hcpo.GetLogger().Infof("🔄 Will re-execute step %d with human feedback: %s", i+1, feedback)
+		run.humanFeedback = feedback
+		return stepGateRetry
+	}
+
+	// User wants to stop execution of this step
+	hcpo.GetLogger().Infof("🛑 User requested to stop execution after step %d with feedback: %s", i+1, feedback)
+	return stepGateStopped
+}
+
+// markStepCompleted adds a step to the completed steps and saves steps_done.json. It is safe to
+// call from concurrently executing steps.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) markStepCompleted(ctx context.Context, execution *stepExecution, index int) {
+	hcpo.progressMu.Lock()
+	defer hcpo.progressMu.Unlock()
+
+	progress := execution.progress
+	for _, completedIdx := range progress.CompletedStepIndices {
+		if completedIdx == index {
+			return
+		}
+	}
+	progress.CompletedStepIndices = append(progress.CompletedStepIndices, index)
+	sort.Ints(progress.CompletedStepIndices)
+
+	if err := hcpo.saveStepProgress(ctx, progress); err != nil {
+		hcpo.GetLogger().Warnf("⚠️ Failed to save step progress: %w", err)
+	} else {
+		hcpo.GetLogger().Infof("✅ Step %d/%d marked as completed and saved", index+1, len(execution.steps))
+	}
 }
 
 // max returns the maximum value in a slice of integers
@@ -2082,6 +2327,40 @@ func (hcpo *HumanControlledTodoPlannerOrchestrator) SetFastExecuteMode(enabled b
 	hcpo.fastExecuteEndStep = endStep
 }
 
+// SetSuccessThreshold sets the default validation score threshold (0.0-1.0) used for
+// steps that don't declare their own success_threshold. 0 disables score-based acceptance.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) SetSuccessThreshold(threshold float64) {
+	hcpo.successThreshold = threshold
+}
+
+// SetHumanEscalationAfterFailures sets how many automated failures a step may accumulate
+// before the human feedback gate fires. Steps that pass validation proceed without asking.
+// 0 restores the default of always asking the human after each step.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) SetHumanEscalationAfterFailures(failures int) {
+	hcpo.humanEscalationAfterFailures = failures
+}
+
+// shouldEscalateToHuman reports whether the human feedback gate fires for a step
+// given whether its last attempt passed validation and its automated failure count
+func (hcpo *HumanControlledTodoPlannerOrchestrator) shouldEscalateToHuman(passed bool, automatedFailures int) bool {
+	if hcpo.humanEscalationAfterFailures <= 0 {
+		return true
+	}
+	if passed {
+		return false
+	}
+	return automatedFailures >= hcpo.humanEscalationAfterFailures
+}
+
+// GetStepSuccessThreshold returns the validation score threshold for a step,
+// falling back to the orchestrator default when the step doesn't set one
+func (hcpo *HumanControlledTodoPlannerOrchestrator) GetStepSuccessThreshold(step TodoStep) float64 {
+	if step.SuccessThreshold > 0 {
+		return step.SuccessThreshold
+	}
+	return hcpo.successThreshold
+}
+
 // GetLearningDetailLevel returns the stored learning detail level preference
 func (hcpo *HumanControlledTodoPlannerOrchestrator) GetLearningDetailLevel() string {
 	if hcpo.learningDetailLevel == "" {
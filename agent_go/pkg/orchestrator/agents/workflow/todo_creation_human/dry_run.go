@@ -0,0 +1,36 @@
+package todo_creation_human
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DryRunPlan is the result of a dry run: the plan the workflow would execute
+type DryRunPlan struct {
+	DryRun     bool       `json:"dry_run"`
+	Objective  string     `json:"objective"`
+	TotalSteps int        `json:"total_steps"`
+	Steps      []TodoStep `json:"steps"`
+}
+
+// SetDryRun makes CreateTodoList stop after the planning phases: the plan is extracted (and
+// TodoStepsExtractedEvent emitted) but not approved or executed, so no execution agent runs and no
+// MCP tool is called. CreateTodoList then returns the plan as DryRunPlan JSON.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) SetDryRun(enabled bool) {
+	hcpo.dryRun = enabled
+}
+
+// dryRunPlan returns the planned steps as DryRunPlan JSON
+func (hcpo *HumanControlledTodoPlannerOrchestrator) dryRunPlan(steps []TodoStep) (string, error) {
+	hcpo.GetLogger().Infof("🧪 Dry run: planned %d steps, skipping execution", len(steps))
+	plan, err := json.MarshalIndent(DryRunPlan{
+		DryRun:     true,
+		Objective:  hcpo.GetObjective(),
+		TotalSteps: len(steps),
+		Steps:      steps,
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode dry run plan: %w", err)
+	}
+	return string(plan), nil
+}
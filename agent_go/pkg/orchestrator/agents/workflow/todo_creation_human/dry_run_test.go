@@ -0,0 +1,41 @@
+package todo_creation_human
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestDryRunReturnsPlanWithoutExecutingSteps(t *testing.T) {
+	hcpo, _ := newParallelTestOrchestrator(t, 1)
+	hcpo.SetDryRun(true)
+
+	planned := parallelTestPlan()
+	hcpo.planStepsFunc = func(ctx context.Context, objective, workspacePath string) ([]TodoStep, error) {
+		return planned, nil
+	}
+	attempts := 0
+	hcpo.stepAttemptFunc = func(ctx context.Context, execution *stepExecution, run *stepRun) error {
+		attempts++
+		return nil
+	}
+
+	result, err := hcpo.Execute(context.Background(), "Audit the service owners", "Workflow/audit", nil)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if attempts != 0 {
+		t.Fatalf("expected no step execution in a dry run, got %d attempts", attempts)
+	}
+
+	var plan DryRunPlan
+	if err := json.Unmarshal([]byte(result), &plan); err != nil {
+		t.Fatalf("dry run result is not a plan: %v (%q)", err, result)
+	}
+	if !plan.DryRun || plan.Objective != "Audit the service owners" || plan.TotalSteps != len(planned) || len(plan.Steps) != len(planned) {
+		t.Fatalf("unexpected dry run plan: %+v", plan)
+	}
+	if plan.Steps[3].Title != "Map owners" || len(plan.Steps[3].ContextDependencies) != 2 {
+		t.Fatalf("expected the planned steps unchanged, got %+v", plan.Steps[3])
+	}
+}
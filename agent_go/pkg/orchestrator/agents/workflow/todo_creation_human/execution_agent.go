@@ -29,6 +29,7 @@ type HumanControlledTodoPlannerExecutionTemplate struct {
 	PreviousHumanFeedback   string
 	VariableNames           string // Variable names with descriptions ({{VAR_NAME}} - description)
 	VariableValues          string // Variable names with actual values ({{VAR_NAME}} = value - description)
+	DependencyOutputs       string // Recorded outputs of the steps listed in context dependencies
 }
 
 // HumanControlledTodoPlannerExecutionAgent executes the objective using MCP servers in human-controlled mode
@@ -70,8 +71,9 @@ func (hctpea *HumanControlledTodoPlannerExecutionAgent) Execute(ctx context.Cont
 		"WorkspacePath":           workspacePath,
 		"ValidationFeedback":      templateVars["ValidationFeedback"],
 		"LearningAgentOutput":     templateVars["LearningAgentOutput"],
-		"VariableNames":           templateVars["VariableNames"],  // May be empty if no variables
-		"VariableValues":          templateVars["VariableValues"], // May be empty if no variables
+		"VariableNames":           templateVars["VariableNames"],     // May be empty if no variables
+		"VariableValues":          templateVars["VariableValues"],    // May be empty if no variables
+		"DependencyOutputs":       templateVars["DependencyOutputs"], // May be empty if no dependency outputs recorded
 	}
 
 	// Create template data for validation
@@ -89,6 +91,7 @@ func (hctpea *HumanControlledTodoPlannerExecutionAgent) Execute(ctx context.Cont
 		LearningAgentOutput:     executionTemplateVars["LearningAgentOutput"],
 		VariableNames:           executionTemplateVars["VariableNames"],
 		VariableValues:          executionTemplateVars["VariableValues"],
+		DependencyOutputs:       executionTemplateVars["DependencyOutputs"],
 	}
 
 	// Execute using template validation
@@ -113,6 +116,7 @@ func (hctpea *HumanControlledTodoPlannerExecutionAgent) humanControlledExecution
 		PreviousHumanFeedback:   templateVars["PreviousHumanFeedback"],
 		VariableNames:           templateVars["VariableNames"],
 		VariableValues:          templateVars["VariableValues"],
+		DependencyOutputs:       templateVars["DependencyOutputs"],
 	}
 
 	// 	## 📁 FILE PERMISSIONS
@@ -182,6 +186,14 @@ func (hctpea *HumanControlledTodoPlannerExecutionAgent) humanControlledExecution
 - "read_file returned 245 lines from config.json"
 - "Created {{.WorkspacePath}}/todo_creation_human/execution/step_1_results.md with 10 database URLs"
 
+{{if .DependencyOutputs}}
+## 📦 OUTPUTS FROM DEPENDENCY STEPS
+
+{{.DependencyOutputs}}
+
+**Important**: These are the recorded outputs of the steps listed in Context Dependencies. Use them directly instead of re-running those steps; read the context files only if you need details not shown here.
+{{end}}
+
 {{if .LearningAgentOutput}}
 ## 🧠 LEARNING AGENT OUTPUT
 
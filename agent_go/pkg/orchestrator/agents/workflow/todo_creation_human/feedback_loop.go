@@ -0,0 +1,102 @@
+package todo_creation_human
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// defaultRepeatedFeedbackLimit is how many times the same rejection feedback may be given
+// before the human is asked to change approach or abort
+const defaultRepeatedFeedbackLimit = 2
+
+// SetRepeatedFeedbackLimit sets how many times identical plan rejection feedback may be given
+// before the revision loop stops and asks the human to change approach or abort.
+// 0 uses the default (2), a negative value disables the detection.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) SetRepeatedFeedbackLimit(limit int) {
+	hcpo.repeatedFeedbackLimit = limit
+}
+
+// feedbackLoopDetector counts identical feedback across revision attempts
+type feedbackLoopDetector struct {
+	limit  int
+	counts map[string]int
+}
+
+func (hcpo *HumanControlledTodoPlannerOrchestrator) newFeedbackLoopDetector() *feedbackLoopDetector {
+	limit := hcpo.repeatedFeedbackLimit
+	if limit == 0 {
+		limit = defaultRepeatedFeedbackLimit
+	}
+	return &feedbackLoopDetector{limit: limit, counts: make(map[string]int)}
+}
+
+// record counts the feedback and reports whether it has now been repeated up to the limit
+func (d *feedbackLoopDetector) record(feedback string) (int, bool) {
+	key := normalizeFeedback(feedback)
+	if d.limit < 0 || key == "" {
+		return 0, false
+	}
+	d.counts[key]++
+	return d.counts[key], d.counts[key] >= d.limit
+}
+
+// normalizeFeedback lowercases feedback and drops punctuation and extra whitespace so trivially
+// different wordings of the same feedback compare equal
+func normalizeFeedback(feedback string) string {
+	words := strings.FieldsFunc(strings.ToLower(feedback), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// resolveRepeatedFeedback records rejection feedback and, when the same feedback keeps coming
+// back, asks the human to change approach, proceed with the current plan, or abort.
+// Returns (approved, feedback for the next revision, error); aborting returns an error.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) resolveRepeatedFeedback(ctx context.Context, detector *feedbackLoopDetector, feedback string) (bool, string, error) {
+	count, repeated := detector.record(feedback)
+	if !repeated {
+		return false, feedback, nil
+	}
+	hcpo.GetLogger().Warnf("🔁 Same plan feedback given %d times, asking the user to change approach", count)
+
+	requestID := fmt.Sprintf("feedback_loop_%d", time.Now().UnixNano())
+	choice, err := hcpo.RequestThreeChoiceFeedback(
+		ctx,
+		requestID,
+		fmt.Sprintf("You have given the same feedback %d times and the revised plans have not resolved it. How do you want to continue?", count),
+		"Change Approach",
+		"Proceed With Current Plan",
+		"Abort Planning",
+		fmt.Sprintf("Repeated feedback:\n%s", feedback),
+		hcpo.getSessionID(),
+		hcpo.getWorkflowID(),
+	)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to resolve repeated plan feedback: %w", err)
+	}
+
+	switch choice {
+	case "option2":
+		hcpo.GetLogger().Infof("✅ User chose to proceed with the current plan after repeated feedback")
+		return true, "", nil
+	case "option3":
+		return false, "", fmt.Errorf("planning aborted by user after the same feedback was given %d times", count)
+	}
+
+	// Change approach: ask for new guidance instead of repeating the old feedback
+	approved, newFeedback, err := hcpo.RequestHumanFeedback(
+		ctx,
+		fmt.Sprintf("feedback_loop_approach_%d", time.Now().UnixNano()),
+		"Describe a different approach for the plan",
+		fmt.Sprintf("Previous feedback that did not work:\n%s", feedback),
+		hcpo.getSessionID(),
+		hcpo.getWorkflowID(),
+	)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get new plan approach: %w", err)
+	}
+	return approved, newFeedback, nil
+}
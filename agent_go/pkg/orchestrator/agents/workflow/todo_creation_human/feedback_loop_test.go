@@ -0,0 +1,123 @@
+package todo_creation_human
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	virtualtools "mcp-agent/agent_go/cmd/server/virtual-tools"
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+	"mcp-agent/agent_go/pkg/orchestrator"
+)
+
+// scriptedHuman answers blocking feedback prompts in order with the scripted responses
+type scriptedHuman struct {
+	mu        sync.Mutex
+	responses []string
+	prompts   []*events.BlockingHumanFeedbackEvent
+}
+
+func (h *scriptedHuman) HandleEvent(ctx context.Context, event *events.AgentEvent) error {
+	prompt, ok := event.Data.(*events.BlockingHumanFeedbackEvent)
+	if !ok {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.prompts = append(h.prompts, prompt)
+	if len(h.responses) == 0 {
+		return nil
+	}
+	response := h.responses[0]
+	h.responses = h.responses[1:]
+
+	// The request is registered in the store right after the event is emitted
+	go func() {
+		store := virtualtools.GetHumanFeedbackStore()
+		for i := 0; i < 100; i++ {
+			if store.SubmitResponse(prompt.RequestID, response) == nil {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	return nil
+}
+
+func (h *scriptedHuman) Name() string {
+	return "scripted-human"
+}
+
+func newFeedbackLoopPlanner(t *testing.T, responses ...string) (*HumanControlledTodoPlannerOrchestrator, *scriptedHuman) {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+	human := &scriptedHuman{responses: responses}
+	base, err := orchestrator.NewBaseOrchestrator(testLogger, human, orchestrator.OrchestratorTypeWorkflow, "openai", "gpt-4.1", "", 0.2, "simple", nil, nil, nil, 5, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create base orchestrator: %v", err)
+	}
+	return &HumanControlledTodoPlannerOrchestrator{BaseOrchestrator: base}, human
+}
+
+func TestRepeatedFeedbackPromptsToChangeApproach(t *testing.T) {
+	hcpo, human := newFeedbackLoopPlanner(t, "option1", "Split the deployment into a separate step")
+	detector := hcpo.newFeedbackLoopDetector()
+
+	approved, feedback, err := hcpo.resolveRepeatedFeedback(context.Background(), detector, "Add more detail to step 2")
+	if err != nil || approved || feedback != "Add more detail to step 2" {
+		t.Fatalf("first feedback should pass through unchanged, got %v %q %v", approved, feedback, err)
+	}
+	if len(human.prompts) != 0 {
+		t.Fatalf("expected no loop-break prompt after the first feedback")
+	}
+
+	// Same feedback with different casing and punctuation counts as a repeat
+	approved, feedback, err = hcpo.resolveRepeatedFeedback(context.Background(), detector, "add more detail to step 2!")
+	if err != nil || approved {
+		t.Fatalf("unexpected result after repeated feedback: %v %v", approved, err)
+	}
+	if feedback != "Split the deployment into a separate step" {
+		t.Fatalf("expected the new approach as next feedback, got %q", feedback)
+	}
+
+	human.mu.Lock()
+	defer human.mu.Unlock()
+	if len(human.prompts) != 2 {
+		t.Fatalf("expected loop-break prompt and new approach prompt, got %d prompts", len(human.prompts))
+	}
+	loopPrompt := human.prompts[0]
+	if !loopPrompt.ThreeChoiceMode || loopPrompt.Option1Label != "Change Approach" || loopPrompt.Option3Label != "Abort Planning" {
+		t.Fatalf("expected change approach/abort prompt, got %+v", loopPrompt)
+	}
+	if !strings.Contains(loopPrompt.Question, "same feedback 2 times") {
+		t.Fatalf("expected prompt to mention the repetition, got %q", loopPrompt.Question)
+	}
+}
+
+func TestRepeatedFeedbackAbort(t *testing.T) {
+	hcpo, _ := newFeedbackLoopPlanner(t, "option3")
+	detector := hcpo.newFeedbackLoopDetector()
+
+	hcpo.resolveRepeatedFeedback(context.Background(), detector, "Use fewer steps")
+	if _, _, err := hcpo.resolveRepeatedFeedback(context.Background(), detector, "Use fewer steps"); err == nil || !strings.Contains(err.Error(), "aborted") {
+		t.Fatalf("expected planning to abort, got %v", err)
+	}
+}
+
+func TestRepeatedFeedbackDetectionDisabled(t *testing.T) {
+	hcpo, human := newFeedbackLoopPlanner(t)
+	hcpo.SetRepeatedFeedbackLimit(-1)
+	detector := hcpo.newFeedbackLoopDetector()
+
+	for i := 0; i < 3; i++ {
+		if _, feedback, err := hcpo.resolveRepeatedFeedback(context.Background(), detector, "Use fewer steps"); err != nil || feedback != "Use fewer steps" {
+			t.Fatalf("expected feedback to pass through when disabled, got %q %v", feedback, err)
+		}
+	}
+	if len(human.prompts) != 0 {
+		t.Fatalf("expected no prompts when detection is disabled, got %d", len(human.prompts))
+	}
+}
@@ -0,0 +1,21 @@
+package todo_creation_human
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStepApprovalTimeoutSkipsToNextStep(t *testing.T) {
+	hcpo, _ := newParallelTestOrchestrator(t, 1)
+	hcpo.SetHumanFeedbackTimeout(20 * time.Millisecond)
+	execution := &stepExecution{steps: progressTestSteps(), progress: &StepProgress{TotalSteps: 2}}
+	run := &stepRun{index: 0, step: execution.steps[0]}
+
+	if decision := hcpo.gateStep(context.Background(), execution, run); decision != stepGateStopped {
+		t.Fatalf("expected an unanswered step approval to skip the step, got decision %v", decision)
+	}
+	if len(execution.progress.CompletedStepIndices) != 0 {
+		t.Errorf("expected the skipped step to stay incomplete, got %v", execution.progress.CompletedStepIndices)
+	}
+}
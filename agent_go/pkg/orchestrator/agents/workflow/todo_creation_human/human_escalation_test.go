@@ -0,0 +1,37 @@
+package todo_creation_human
+
+import "testing"
+
+func TestHumanGateAlwaysFiresByDefault(t *testing.T) {
+	hcpo := &HumanControlledTodoPlannerOrchestrator{}
+
+	if !hcpo.shouldEscalateToHuman(true, 0) {
+		t.Errorf("default policy should ask the human even when the step passed")
+	}
+}
+
+func TestHumanEscalationPolicy(t *testing.T) {
+	hcpo := &HumanControlledTodoPlannerOrchestrator{}
+	hcpo.SetHumanEscalationAfterFailures(3)
+
+	// A passing step skips the human gate, even after earlier failed attempts
+	if hcpo.shouldEscalateToHuman(true, 0) {
+		t.Errorf("passing step should auto-proceed")
+	}
+	if hcpo.shouldEscalateToHuman(true, 2) {
+		t.Errorf("step that passed after retries should auto-proceed")
+	}
+
+	// A repeatedly failing step keeps retrying until it reaches the threshold, then escalates
+	for failures := 1; failures < 3; failures++ {
+		if hcpo.shouldEscalateToHuman(false, failures) {
+			t.Errorf("step with %d failures should retry automatically", failures)
+		}
+	}
+	if !hcpo.shouldEscalateToHuman(false, 3) {
+		t.Errorf("step with 3 failures should escalate to human")
+	}
+	if !hcpo.shouldEscalateToHuman(false, 6) {
+		t.Errorf("step with 6 failures should escalate to human")
+	}
+}
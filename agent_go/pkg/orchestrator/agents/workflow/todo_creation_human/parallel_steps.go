@@ -0,0 +1,139 @@
+package todo_creation_human
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// stepAttemptFunc runs a step's automated attempts; stepGateFunc decides what happens to it next
+type stepAttemptFunc func(ctx context.Context, execution *stepExecution, run *stepRun) error
+type stepGateFunc func(ctx context.Context, execution *stepExecution, run *stepRun) stepGateDecision
+
+// SetParallelStepWorkers executes independent steps concurrently with at most this many workers.
+// Steps are grouped into dependency layers from their context dependencies, and a layer only starts
+// once every step of the previous one has passed the human gate. 0 or 1 executes the steps one by one.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) SetParallelStepWorkers(workers int) {
+	hcpo.parallelStepWorkers = workers
+}
+
+// planStepLayers groups the step indices into dependency layers: a step comes after the steps
+// producing its context dependencies, so the steps of a layer are independent of each other.
+// Dependencies on completed steps or on files no step produces are ignored, and a dependency
+// cycle is broken by scheduling its first remaining step on its own.
+func planStepLayers(steps []TodoStep, indices []int) [][]int {
+	// The first step declaring a context output is its producer
+	producers := make(map[string]int)
+	for i, step := range steps {
+		for _, output := range strings.Split(step.ContextOutput, ",") {
+			if key := dependencyKey(output); key != "" && key != "." {
+				if _, exists := producers[key]; !exists {
+					producers[key] = i
+				}
+			}
+		}
+	}
+
+	pending := make(map[int]bool, len(indices))
+	for _, i := range indices {
+		pending[i] = true
+	}
+	blockers := make(map[int]map[int]bool) // step -> pending producers it waits for
+	for _, i := range indices {
+		for _, dep := range steps[i].ContextDependencies {
+			producer, exists := producers[dependencyKey(dep)]
+			if !exists || producer == i || !pending[producer] {
+				continue
+			}
+			if blockers[i] == nil {
+				blockers[i] = make(map[int]bool)
+			}
+			blockers[i][producer] = true
+		}
+	}
+
+	remaining := append([]int(nil), indices...)
+	sort.Ints(remaining)
+	var layers [][]int
+	for len(remaining) > 0 {
+		var layer, rest []int
+		for _, i := range remaining {
+			if len(blockers[i]) == 0 {
+				layer = append(layer, i)
+			} else {
+				rest = append(rest, i)
+			}
+		}
+		if len(layer) == 0 {
+			layer, rest = rest[:1], rest[1:]
+		}
+		for _, done := range layer {
+			for _, i := range rest {
+				delete(blockers[i], done)
+			}
+		}
+		layers = append(layers, layer)
+		remaining = rest
+	}
+	return layers
+}
+
+// runStepLayers executes the pending steps layer by layer. The steps of a layer run their attempts
+// concurrently, then pass the human gate one at a time in plan order; steps the gate sends back
+// (automated retry or re-execution with feedback) run again before the next layer starts.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) runStepLayers(ctx context.Context, execution *stepExecution, pending []int, attempt stepAttemptFunc, gate stepGateFunc) error {
+	layers := planStepLayers(execution.steps, pending)
+	hcpo.GetLogger().Infof("⚡ Executing %d steps in %d dependency layers with up to %d parallel workers", len(pending), len(layers), hcpo.parallelStepWorkers)
+
+	for n, layer := range layers {
+		active := make([]*stepRun, len(layer))
+		for k, i := range layer {
+			active[k] = &stepRun{index: i, step: execution.steps[i]}
+		}
+		hcpo.GetLogger().Infof("📋 Executing dependency layer %d/%d (%d steps)", n+1, len(layers), len(layer))
+
+		for len(active) > 0 {
+			if err := hcpo.runStepAttemptsConcurrently(ctx, execution, active, attempt); err != nil {
+				return err
+			}
+
+			var again []*stepRun
+			for _, run := range active {
+				switch gate(ctx, execution, run) {
+				case stepGateCompleted:
+					hcpo.markStepCompleted(ctx, execution, run.index)
+				case stepGateRetry:
+					again = append(again, run)
+				}
+			}
+			active = again
+		}
+	}
+	return nil
+}
+
+// runStepAttemptsConcurrently runs the attempts of the given steps with at most parallelStepWorkers at a time
+func (hcpo *HumanControlledTodoPlannerOrchestrator) runStepAttemptsConcurrently(ctx context.Context, execution *stepExecution, runs []*stepRun, attempt stepAttemptFunc) error {
+	workers := hcpo.parallelStepWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	slots := make(chan struct{}, workers)
+	errs := make([]error, len(runs))
+	var wg sync.WaitGroup
+	for k, run := range runs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(k int, run *stepRun) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			hcpo.GetLogger().Infof("📋 Executing step %d/%d: %s", run.index+1, len(execution.steps), run.step.Title)
+			errs[k] = attempt(ctx, execution, run)
+		}(k, run)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,203 @@
+package todo_creation_human
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+// discardListener drops the orchestrator's events
+type discardListener struct{}
+
+func (discardListener) HandleEvent(ctx context.Context, event *events.AgentEvent) error { return nil }
+func (discardListener) Name() string                                                    { return "discard" }
+
+// parallelTestPlan has two independent roots, two steps depending on them and a final step
+// depending on both: layers [1 2] [3 4] [5]
+func parallelTestPlan() []TodoStep {
+	return []TodoStep{
+		{Title: "Collect URLs", ContextOutput: "urls.md"},
+		{Title: "Collect owners", ContextOutput: "owners.md"},
+		{Title: "Check URLs", ContextDependencies: []string{"execution/urls.md"}, ContextOutput: "checks.md"},
+		{Title: "Map owners", ContextDependencies: []string{"urls.md", "owners.md"}, ContextOutput: "owner_map.md"},
+		{Title: "Write report", ContextDependencies: []string{"checks.md", "owner_map.md", "inventory.md"}},
+	}
+}
+
+func newParallelTestOrchestrator(t *testing.T, workers int) (*HumanControlledTodoPlannerOrchestrator, func() StepProgress) {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+
+	var mu sync.Mutex
+	var saved string
+	executors := map[string]interface{}{
+		"update_workspace_file": func(ctx context.Context, args map[string]interface{}) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			saved, _ = args["content"].(string)
+			return "ok", nil
+		},
+	}
+	hcpo, err := NewHumanControlledTodoPlannerOrchestrator("openai", "gpt-4.1", 0.2, "simple", nil, nil, "", nil, 5, testLogger, nil, discardListener{}, nil, executors)
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	hcpo.SetParallelStepWorkers(workers)
+
+	lastSaved := func() StepProgress {
+		mu.Lock()
+		defer mu.Unlock()
+		var progress StepProgress
+		if err := json.Unmarshal([]byte(saved), &progress); err != nil {
+			t.Fatalf("steps_done.json is not valid: %v (%q)", err, saved)
+		}
+		return progress
+	}
+	return hcpo, lastSaved
+}
+
+func TestPlanStepLayers(t *testing.T) {
+	steps := parallelTestPlan()
+	if layers := planStepLayers(steps, []int{0, 1, 2, 3, 4}); !reflect.DeepEqual(layers, [][]int{{0, 1}, {2, 3}, {4}}) {
+		t.Errorf("unexpected layers: %v", layers)
+	}
+
+	// Dependencies on completed steps no longer hold a step back
+	if layers := planStepLayers(steps, []int{2, 3, 4}); !reflect.DeepEqual(layers, [][]int{{2, 3}, {4}}) {
+		t.Errorf("unexpected layers after completed roots: %v", layers)
+	}
+
+	// Steps 1 and 2 read each other's output: the cycle is broken in plan order
+	steps[0].ContextDependencies = []string{"checks.md"}
+	if layers := planStepLayers(steps, []int{0, 1, 2, 3, 4}); !reflect.DeepEqual(layers, [][]int{{1}, {0}, {2, 3}, {4}}) {
+		t.Errorf("unexpected layers for a cyclic plan: %v", layers)
+	}
+}
+
+func TestRunStepLayersRespectsDependencies(t *testing.T) {
+	hcpo, lastSaved := newParallelTestOrchestrator(t, 2)
+	execution := &stepExecution{steps: parallelTestPlan(), progress: &StepProgress{TotalSteps: 5}}
+
+	var mu sync.Mutex
+	completed := make(map[int]bool)
+	running, maxRunning := 0, 0
+	attempts := make(map[int]int)
+	bothRootsRunning := make(chan struct{})
+	var rootsStarted sync.WaitGroup
+	rootsStarted.Add(2)
+	go func() {
+		rootsStarted.Wait()
+		close(bothRootsRunning)
+	}()
+
+	attempt := func(ctx context.Context, execution *stepExecution, run *stepRun) error {
+		mu.Lock()
+		for _, dep := range run.step.ContextDependencies {
+			for producer, step := range execution.steps {
+				if dependencyKey(step.ContextOutput) == dependencyKey(dep) && !completed[producer] {
+					t.Errorf("step %d started before its dependency step %d completed", run.index+1, producer+1)
+				}
+			}
+		}
+		attempts[run.index]++
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		first := attempts[run.index] == 1
+		mu.Unlock()
+
+		// The two independent roots must be in flight at the same time
+		if first && run.index < 2 {
+			rootsStarted.Done()
+			select {
+			case <-bothRootsRunning:
+			case <-time.After(5 * time.Second):
+				t.Errorf("step %d did not run concurrently with the other root step", run.index+1)
+			}
+		}
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}
+
+	var gated []int
+	gate := func(ctx context.Context, execution *stepExecution, run *stepRun) stepGateDecision {
+		mu.Lock()
+		defer mu.Unlock()
+		gated = append(gated, run.index)
+		// The human re-executes "Check URLs" once: "Write report" must wait for the second run
+		if run.index == 2 && attempts[2] == 1 {
+			return stepGateRetry
+		}
+		completed[run.index] = true
+		return stepGateCompleted
+	}
+
+	if err := hcpo.runStepLayers(context.Background(), execution, []int{0, 1, 2, 3, 4}, attempt, gate); err != nil {
+		t.Fatalf("runStepLayers: %v", err)
+	}
+
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 concurrent steps, got %d", maxRunning)
+	}
+	// The gate runs in plan order after each batch, and only the re-executed step runs again
+	if want := []int{0, 1, 2, 3, 2, 4}; !reflect.DeepEqual(gated, want) {
+		t.Errorf("expected gate order %v, got %v", want, gated)
+	}
+	if progress := lastSaved(); !reflect.DeepEqual(progress.CompletedStepIndices, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("unexpected steps_done.json progress: %+v", progress)
+	}
+}
+
+func TestRunStepLayersWorkerLimitAndConcurrentProgress(t *testing.T) {
+	hcpo, lastSaved := newParallelTestOrchestrator(t, 3)
+	steps := make([]TodoStep, 8)
+	for i := range steps {
+		steps[i] = TodoStep{Title: "Independent step"}
+	}
+	execution := &stepExecution{steps: steps, progress: &StepProgress{CompletedStepIndices: []int{1}, TotalSteps: len(steps)}}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	attempt := func(ctx context.Context, execution *stepExecution, run *stepRun) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		// Steps also save progress while others are still running
+		hcpo.markStepCompleted(ctx, execution, run.index)
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}
+	gate := func(ctx context.Context, execution *stepExecution, run *stepRun) stepGateDecision {
+		return stepGateCompleted
+	}
+
+	if err := hcpo.runStepLayers(context.Background(), execution, []int{0, 2, 3, 4, 5, 6, 7}, attempt, gate); err != nil {
+		t.Fatalf("runStepLayers: %v", err)
+	}
+	if maxRunning != 3 {
+		t.Errorf("expected the 3 workers to be used and not exceeded, got %d concurrent steps", maxRunning)
+	}
+	want := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	if !reflect.DeepEqual(execution.progress.CompletedStepIndices, want) {
+		t.Errorf("expected each step completed exactly once, got %v", execution.progress.CompletedStepIndices)
+	}
+	if progress := lastSaved(); !reflect.DeepEqual(progress.CompletedStepIndices, want) {
+		t.Errorf("unexpected steps_done.json progress: %+v", progress)
+	}
+}
@@ -0,0 +1,200 @@
+package todo_creation_human
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PlanGraphFormat selects the graph definition language produced by RenderPlanGraph
+type PlanGraphFormat string
+
+const (
+	PlanGraphMermaid  PlanGraphFormat = "mermaid"
+	PlanGraphGraphviz PlanGraphFormat = "graphviz"
+)
+
+// planGraphEdge connects the step producing a context file to a step depending on it
+type planGraphEdge struct {
+	from   string
+	to     string
+	label  string
+	cyclic bool // The dependency is part of a cycle (or the step depends on its own output)
+}
+
+// planGraph is the dependency structure of a plan, independent of the output format
+type planGraph struct {
+	nodes   []planGraphNode
+	edges   []planGraphEdge
+	missing []planGraphNode // Context files no step produces
+}
+
+type planGraphNode struct {
+	id    string
+	label string
+}
+
+// RenderPlanGraph returns the plan's steps and their context dependencies as a Mermaid flowchart or
+// Graphviz digraph. A step depending on a context file gets an edge from the step that outputs it;
+// files no step outputs are drawn as dashed "missing" nodes and edges in a cycle are highlighted.
+func RenderPlanGraph(plan *PlanningResponse, format PlanGraphFormat) (string, error) {
+	if plan == nil {
+		return "", fmt.Errorf("plan is required")
+	}
+	graph := buildPlanGraph(plan.Steps)
+	switch format {
+	case PlanGraphMermaid, "":
+		return graph.mermaid(), nil
+	case PlanGraphGraphviz, "dot":
+		return graph.graphviz(), nil
+	default:
+		return "", fmt.Errorf("unsupported plan graph format %q (use %q or %q)", format, PlanGraphMermaid, PlanGraphGraphviz)
+	}
+}
+
+func buildPlanGraph(steps []PlanStep) *planGraph {
+	graph := &planGraph{}
+
+	// The first step declaring a context output is its producer
+	producers := make(map[string]int)
+	for i, step := range steps {
+		graph.nodes = append(graph.nodes, planGraphNode{id: fmt.Sprintf("step%d", i+1), label: fmt.Sprintf("%d. %s", i+1, strings.Join(strings.Fields(step.Title), " "))})
+		for _, output := range strings.Split(step.ContextOutput.String(), ",") {
+			if key := dependencyKey(output); key != "" && key != "." {
+				if _, exists := producers[key]; !exists {
+					producers[key] = i
+				}
+			}
+		}
+	}
+
+	type stepDependency struct {
+		producer, consumer int
+		file               string
+	}
+	var dependencies []stepDependency
+	adjacency := make(map[int][]int)
+	missingIDs := make(map[string]string)
+	for i, step := range steps {
+		for _, dep := range step.ContextDependencies {
+			dep = strings.TrimSpace(dep)
+			if dep == "" {
+				continue
+			}
+			producer, exists := producers[dependencyKey(dep)]
+			if !exists {
+				id, seen := missingIDs[dependencyKey(dep)]
+				if !seen {
+					id = fmt.Sprintf("missing%d", len(graph.missing)+1)
+					missingIDs[dependencyKey(dep)] = id
+					graph.missing = append(graph.missing, planGraphNode{id: id, label: "missing: " + dep})
+				}
+				graph.edges = append(graph.edges, planGraphEdge{from: id, to: graph.nodes[i].id})
+				continue
+			}
+			dependencies = append(dependencies, stepDependency{producer: producer, consumer: i, file: dep})
+			adjacency[producer] = append(adjacency[producer], i)
+		}
+	}
+
+	// A dependency is in a cycle when the consumer's outputs lead back to the producer
+	for _, dep := range dependencies {
+		graph.edges = append(graph.edges, planGraphEdge{
+			from:   graph.nodes[dep.producer].id,
+			to:     graph.nodes[dep.consumer].id,
+			label:  dep.file,
+			cyclic: planStepReaches(adjacency, dep.consumer, dep.producer),
+		})
+	}
+	return graph
+}
+
+// planStepReaches reports whether target is reachable from start along dependency edges
+func planStepReaches(adjacency map[int][]int, start, target int) bool {
+	visited := map[int]bool{start: true}
+	queue := []int{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == target {
+			return true
+		}
+		for _, next := range adjacency[current] {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+func (g *planGraph) mermaid() string {
+	escape := strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace
+
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for _, node := range g.nodes {
+		fmt.Fprintf(&b, "    %s[\"%s\"]\n", node.id, escape(node.label))
+	}
+	for _, node := range g.missing {
+		fmt.Fprintf(&b, "    %s[/\"%s\"/]\n", node.id, escape(node.label))
+	}
+	var cyclic []int
+	for i, edge := range g.edges {
+		arrow := "-->"
+		if edge.cyclic || strings.HasPrefix(edge.from, "missing") {
+			arrow = "-.->"
+		}
+		if edge.label != "" {
+			fmt.Fprintf(&b, "    %s %s|\"%s\"| %s\n", edge.from, arrow, escape(edge.label), edge.to)
+		} else {
+			fmt.Fprintf(&b, "    %s %s %s\n", edge.from, arrow, edge.to)
+		}
+		if edge.cyclic {
+			cyclic = append(cyclic, i)
+		}
+	}
+	if len(g.missing) > 0 {
+		b.WriteString("    classDef missing stroke-dasharray: 5 5\n")
+		ids := make([]string, 0, len(g.missing))
+		for _, node := range g.missing {
+			ids = append(ids, node.id)
+		}
+		fmt.Fprintf(&b, "    class %s missing\n", strings.Join(ids, ","))
+	}
+	for _, i := range cyclic {
+		fmt.Fprintf(&b, "    linkStyle %d stroke:#d33\n", i)
+	}
+	return b.String()
+}
+
+func (g *planGraph) graphviz() string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace
+
+	var b strings.Builder
+	b.WriteString("digraph plan {\n    rankdir=TB;\n    node [shape=box];\n")
+	for _, node := range g.nodes {
+		fmt.Fprintf(&b, "    %s [label=\"%s\"];\n", node.id, escape(node.label))
+	}
+	for _, node := range g.missing {
+		fmt.Fprintf(&b, "    %s [label=\"%s\", style=dashed];\n", node.id, escape(node.label))
+	}
+	for _, edge := range g.edges {
+		var attrs []string
+		if edge.label != "" {
+			attrs = append(attrs, fmt.Sprintf("label=\"%s\"", escape(edge.label)))
+		}
+		if edge.cyclic {
+			attrs = append(attrs, "color=red", "style=dashed")
+		} else if strings.HasPrefix(edge.from, "missing") {
+			attrs = append(attrs, "style=dashed")
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, "    %s -> %s [%s];\n", edge.from, edge.to, strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&b, "    %s -> %s;\n", edge.from, edge.to)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package todo_creation_human
+
+import (
+	"strings"
+	"testing"
+)
+
+func graphTestPlan() *PlanningResponse {
+	return &PlanningResponse{Steps: []PlanStep{
+		{Title: "Collect URLs", ContextOutput: "step_1_results.md"},
+		{Title: "Check \"URLs\"", ContextDependencies: []string{"execution/step_1_results.md"}, ContextOutput: "step_2_results.md"},
+		{Title: "Write report", ContextDependencies: []string{"step_1_results.md", "step_2_results.md", "inventory.md"}},
+	}}
+}
+
+func TestPlanGraphMermaidHasStepNodesAndDependencyEdges(t *testing.T) {
+	graph, err := RenderPlanGraph(graphTestPlan(), PlanGraphMermaid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"flowchart TD",
+		`step1["1. Collect URLs"]`,
+		`step2["2. Check #quot;URLs#quot;"]`,
+		`step3["3. Write report"]`,
+		`step1 -->|"execution/step_1_results.md"| step2`,
+		`step1 -->|"step_1_results.md"| step3`,
+		`step2 -->|"step_2_results.md"| step3`,
+		// Nothing outputs inventory.md: it is drawn as a missing input rather than dropped
+		`missing1[/"missing: inventory.md"/]`,
+		"missing1 -.-> step3",
+	} {
+		if !strings.Contains(graph, want) {
+			t.Errorf("expected mermaid graph to contain %q, got:\n%s", want, graph)
+		}
+	}
+	if strings.Contains(graph, "linkStyle") {
+		t.Errorf("expected no cycle highlighting for an acyclic plan, got:\n%s", graph)
+	}
+}
+
+func TestPlanGraphGraphvizMarksCycles(t *testing.T) {
+	plan := graphTestPlan()
+	// Step 1 now reads the report step 3 writes: 1 -> 3 -> 1 is a cycle
+	plan.Steps[0].ContextDependencies = []string{"report.md"}
+	plan.Steps[2].ContextOutput = "report.md"
+
+	graph, err := RenderPlanGraph(plan, PlanGraphGraphviz)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"digraph plan {",
+		`step2 [label="2. Check \"URLs\""];`,
+		`step3 -> step1 [label="report.md", color=red, style=dashed];`,
+		`step1 -> step3 [label="step_1_results.md", color=red, style=dashed];`,
+		`missing1 [label="missing: inventory.md", style=dashed];`,
+	} {
+		if !strings.Contains(graph, want) {
+			t.Errorf("expected graphviz graph to contain %q, got:\n%s", want, graph)
+		}
+	}
+	if !strings.HasSuffix(graph, "}\n") {
+		t.Errorf("expected a closed digraph, got:\n%s", graph)
+	}
+
+	if _, err := RenderPlanGraph(plan, "svg"); err == nil {
+		t.Errorf("expected an unsupported format to be rejected")
+	}
+}
@@ -86,6 +86,12 @@ func (hcpra *HumanControlledPlanReaderAgent) ExecuteStructured(ctx context.Conte
 								"type": "string"
 							},
 							"description": "List of approaches that failed, including tools to avoid (extract from 'Failure Patterns:' section)"
+						},
+						"success_threshold": {
+							"type": "number",
+							"minimum": 0,
+							"maximum": 1,
+							"description": "Optional minimum validation score (0.0-1.0) for the step to pass (extract from 'Success Threshold:' if present)"
 						}
 					},
 					"required": ["title", "description", "success_criteria", "why_this_step"]
@@ -187,6 +193,7 @@ The output JSON must maintain variable placeholders, not resolved values.
   - context_output: From "- **Context Output**: [content]"
   - success_patterns: From "- **Success Patterns**: [bullet list]" - See parsing rules below
   - failure_patterns: From "- **Failure Patterns**: [bullet list]" - See parsing rules below
+  - success_threshold: From "- **Success Threshold**: [number]" (optional, omit if missing)
 
 **Context Dependencies Conversion Rules**:
 - "none" → [] (empty array)
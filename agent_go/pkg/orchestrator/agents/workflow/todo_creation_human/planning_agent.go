@@ -63,9 +63,10 @@ type PlanStep struct {
 	SuccessCriteria     string                `json:"success_criteria"`
 	WhyThisStep         string                `json:"why_this_step"`
 	ContextDependencies []string              `json:"context_dependencies"`
-	ContextOutput       FlexibleContextOutput `json:"context_output"`             // Use flexible type to handle string or array
-	SuccessPatterns     []string              `json:"success_patterns,omitempty"` // NEW - what worked (includes tools)
-	FailurePatterns     []string              `json:"failure_patterns,omitempty"` // NEW - what failed (includes tools to avoid)
+	ContextOutput       FlexibleContextOutput `json:"context_output"`              // Use flexible type to handle string or array
+	SuccessPatterns     []string              `json:"success_patterns,omitempty"`  // NEW - what worked (includes tools)
+	FailurePatterns     []string              `json:"failure_patterns,omitempty"`  // NEW - what failed (includes tools to avoid)
+	SuccessThreshold    float64               `json:"success_threshold,omitempty"` // Optional minimum validation score (0.0-1.0)
 }
 
 // PlanningResponse represents the structured response from planning
@@ -0,0 +1,208 @@
+package todo_creation_human
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DuplicateStepPair records a step whose wording nearly repeats an earlier step
+type DuplicateStepPair struct {
+	KeepIndex      int     `json:"keep_index"`      // 0-based index of the step that is kept
+	DuplicateIndex int     `json:"duplicate_index"` // 0-based index of the step merged into it
+	Similarity     float64 `json:"similarity"`      // Word-overlap similarity, 0.0-1.0
+}
+
+// dedupStopWords are ignored when comparing step text
+var dedupStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "to": true, "of": true, "in": true,
+	"on": true, "for": true, "with": true, "from": true, "into": true, "by": true, "is": true,
+	"are": true, "it": true, "its": true, "this": true, "that": true, "all": true, "any": true,
+}
+
+// MinStepDedupThreshold is the lowest threshold the dedup pass runs with. Word overlap below it
+// mostly reflects shared vocabulary ("fetch", "issues", "GitHub") rather than repeated work.
+const MinStepDedupThreshold = 0.6
+
+// SetStepDedupThreshold enables the post-planning dedup pass. Steps whose title and description
+// reach the threshold (0.0-1.0) of lexical similarity with an earlier step are offered to the human
+// for merging. The comparison is word overlap, not meaning: it catches a step the planner repeated
+// with near-identical wording, not a paraphrase of it. Positive thresholds below MinStepDedupThreshold
+// are raised to it. 0 disables the pass (default).
+func (hcpo *HumanControlledTodoPlannerOrchestrator) SetStepDedupThreshold(threshold float64) {
+	if threshold > 0 && threshold < MinStepDedupThreshold {
+		threshold = MinStepDedupThreshold
+	}
+	hcpo.stepDedupThreshold = threshold
+}
+
+// dedupPlanSteps detects lexically duplicate steps and, after human confirmation, merges them.
+// Returns the steps unchanged when dedup is disabled, nothing is found or the human declines.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) dedupPlanSteps(ctx context.Context, steps []TodoStep) []TodoStep {
+	if hcpo.stepDedupThreshold <= 0 {
+		return steps
+	}
+
+	pairs := findLexicalDuplicateSteps(steps, hcpo.stepDedupThreshold)
+	if len(pairs) == 0 {
+		return steps
+	}
+	hcpo.GetLogger().Infof("🔍 Found %d plan steps worded like an earlier step (threshold %.2f)", len(pairs), hcpo.stepDedupThreshold)
+
+	var details strings.Builder
+	for _, pair := range pairs {
+		details.WriteString(fmt.Sprintf("- Step %d %q → merge into step %d %q (%.0f%% word overlap)\n",
+			pair.DuplicateIndex+1, steps[pair.DuplicateIndex].Title,
+			pair.KeepIndex+1, steps[pair.KeepIndex].Title,
+			pair.Similarity*100))
+	}
+
+	requestID := fmt.Sprintf("step_dedup_%d", time.Now().UnixNano())
+	merge, err := hcpo.RequestYesNoFeedback(
+		ctx,
+		requestID,
+		fmt.Sprintf("The plan contains %d steps that repeat the wording of an earlier step. Do you want to merge them?", len(pairs)),
+		"Merge Steps",
+		"Keep All Steps",
+		details.String(),
+		hcpo.getSessionID(),
+		hcpo.getWorkflowID(),
+	)
+	if err != nil {
+		hcpo.GetLogger().Warnf("⚠️ Failed to get merge decision for duplicate steps: %v, keeping all steps", err)
+		return steps
+	}
+	if !merge {
+		hcpo.GetLogger().Infof("ℹ️ User chose to keep all %d steps", len(steps))
+		return steps
+	}
+
+	merged := mergeDuplicateSteps(steps, pairs)
+	hcpo.GetLogger().Infof("✅ Merged duplicate steps: %d → %d steps", len(steps), len(merged))
+	return merged
+}
+
+// findLexicalDuplicateSteps compares the wording of every step with the earlier steps that are kept
+// and flags it as a duplicate of the most similar one at or above the threshold
+func findLexicalDuplicateSteps(steps []TodoStep, threshold float64) []DuplicateStepPair {
+	words := make([]map[string]bool, len(steps))
+	for i, step := range steps {
+		words[i] = stepWords(step)
+	}
+
+	var pairs []DuplicateStepPair
+	duplicate := make(map[int]bool)
+	for j := range steps {
+		best, bestSimilarity := -1, 0.0
+		for i := 0; i < j; i++ {
+			if duplicate[i] {
+				continue
+			}
+			if similarity := lexicalSimilarity(words[i], words[j]); similarity >= threshold && similarity > bestSimilarity {
+				best, bestSimilarity = i, similarity
+			}
+		}
+		if best >= 0 {
+			duplicate[j] = true
+			pairs = append(pairs, DuplicateStepPair{KeepIndex: best, DuplicateIndex: j, Similarity: bestSimilarity})
+		}
+	}
+	return pairs
+}
+
+// mergeDuplicateSteps folds each duplicate into the step it duplicates and drops it from the plan.
+// Dependencies on a dropped step's context output are redirected to the kept step's output.
+func mergeDuplicateSteps(steps []TodoStep, pairs []DuplicateStepPair) []TodoStep {
+	merged := make([]TodoStep, len(steps))
+	copy(merged, steps)
+
+	dropped := make(map[int]bool)
+	redirects := make(map[string]string)
+	for _, pair := range pairs {
+		keep := &merged[pair.KeepIndex]
+		dup := steps[pair.DuplicateIndex]
+
+		if dup.SuccessCriteria != "" && !strings.Contains(keep.SuccessCriteria, dup.SuccessCriteria) {
+			keep.SuccessCriteria = strings.TrimSpace(keep.SuccessCriteria + "\n" + dup.SuccessCriteria)
+		}
+		keep.ContextDependencies = appendUnique(keep.ContextDependencies, dup.ContextDependencies...)
+		keep.SuccessPatterns = appendUnique(keep.SuccessPatterns, dup.SuccessPatterns...)
+		keep.FailurePatterns = appendUnique(keep.FailurePatterns, dup.FailurePatterns...)
+		if dup.SuccessThreshold > keep.SuccessThreshold {
+			keep.SuccessThreshold = dup.SuccessThreshold
+		}
+		if dup.ContextOutput != "" && dup.ContextOutput != keep.ContextOutput {
+			redirects[dup.ContextOutput] = keep.ContextOutput
+		}
+		dropped[pair.DuplicateIndex] = true
+	}
+
+	result := make([]TodoStep, 0, len(steps)-len(dropped))
+	for i, step := range merged {
+		if dropped[i] {
+			continue
+		}
+		var deps []string
+		for _, dep := range step.ContextDependencies {
+			if target, ok := redirects[dep]; ok {
+				dep = target
+			}
+			// A merged step must not depend on its own output
+			if dep != "" && dep == step.ContextOutput {
+				continue
+			}
+			deps = appendUnique(deps, dep)
+		}
+		step.ContextDependencies = deps
+		result = append(result, step)
+	}
+	return result
+}
+
+// stepWords returns the normalized word set of a step's title and description
+func stepWords(step TodoStep) map[string]bool {
+	fields := strings.FieldsFunc(strings.ToLower(step.Title+" "+step.Description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := make(map[string]bool, len(fields))
+	for _, word := range fields {
+		if !dedupStopWords[word] {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// lexicalSimilarity returns the Jaccard similarity of two word sets. Synonyms and
+// rephrasings count as different words, so it only measures repeated wording.
+func lexicalSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// appendUnique appends values that are not already present
+func appendUnique(existing []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, e := range existing {
+			if e == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, value)
+		}
+	}
+	return existing
+}
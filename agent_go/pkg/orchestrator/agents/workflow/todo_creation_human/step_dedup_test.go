@@ -0,0 +1,70 @@
+package todo_creation_human
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFindLexicalDuplicateStepsFlagsNearIdenticalWording(t *testing.T) {
+	steps := []TodoStep{
+		{Title: "Fetch open GitHub issues", Description: "List all open issues in the repository using the GitHub tools", ContextOutput: "issues.md"},
+		{Title: "Summarize issues by label", Description: "Group the fetched issues by label and write a summary", ContextDependencies: []string{"issues.md"}, ContextOutput: "summary.md"},
+		{Title: "Fetch the open GitHub issues", Description: "List open issues in the repository with the GitHub tools", ContextOutput: "open_issues.md"},
+		{Title: "Post summary to Slack", Description: "Send the summary to the team channel", ContextDependencies: []string{"summary.md", "open_issues.md"}},
+	}
+
+	pairs := findLexicalDuplicateSteps(steps, 0.7)
+	if len(pairs) != 1 {
+		t.Fatalf("expected exactly one duplicate pair, got %+v", pairs)
+	}
+	if pairs[0].KeepIndex != 0 || pairs[0].DuplicateIndex != 2 {
+		t.Fatalf("expected step 3 flagged as duplicate of step 1, got %+v", pairs[0])
+	}
+	if pairs[0].Similarity < 0.7 {
+		t.Fatalf("expected similarity above threshold, got %v", pairs[0].Similarity)
+	}
+
+	merged := mergeDuplicateSteps(steps, pairs)
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 steps after merge, got %d", len(merged))
+	}
+	// Dependencies on the dropped step's output now point at the kept step
+	if !reflect.DeepEqual(merged[2].ContextDependencies, []string{"summary.md", "issues.md"}) {
+		t.Fatalf("expected redirected dependencies, got %v", merged[2].ContextDependencies)
+	}
+}
+
+func TestFindLexicalDuplicateStepsIgnoresDistinctSteps(t *testing.T) {
+	steps := []TodoStep{
+		{Title: "Fetch open GitHub issues", Description: "List all open issues"},
+		{Title: "Post summary to Slack", Description: "Send the summary to the team channel"},
+	}
+	if pairs := findLexicalDuplicateSteps(steps, 0.7); len(pairs) != 0 {
+		t.Fatalf("expected no duplicates, got %+v", pairs)
+	}
+}
+
+func TestDedupPlanStepsDisabledByDefault(t *testing.T) {
+	hcpo := &HumanControlledTodoPlannerOrchestrator{}
+	steps := []TodoStep{{Title: "Same step"}, {Title: "Same step"}}
+	if got := hcpo.dedupPlanSteps(context.Background(), steps); len(got) != 2 {
+		t.Fatalf("expected steps untouched when dedup is disabled, got %d", len(got))
+	}
+}
+
+func TestStepDedupThresholdRaisedToMinimum(t *testing.T) {
+	hcpo := &HumanControlledTodoPlannerOrchestrator{}
+	hcpo.SetStepDedupThreshold(0.2)
+	if hcpo.stepDedupThreshold != MinStepDedupThreshold {
+		t.Fatalf("expected a low threshold raised to %v, got %v", MinStepDedupThreshold, hcpo.stepDedupThreshold)
+	}
+	hcpo.SetStepDedupThreshold(0.85)
+	if hcpo.stepDedupThreshold != 0.85 {
+		t.Fatalf("expected the configured threshold kept, got %v", hcpo.stepDedupThreshold)
+	}
+	hcpo.SetStepDedupThreshold(0)
+	if hcpo.stepDedupThreshold != 0 {
+		t.Fatalf("expected 0 to keep the pass disabled, got %v", hcpo.stepDedupThreshold)
+	}
+}
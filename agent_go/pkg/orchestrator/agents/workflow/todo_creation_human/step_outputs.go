@@ -0,0 +1,77 @@
+package todo_creation_human
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// maxDependencyOutputChars caps how much of a single dependency's recorded output is injected into a prompt
+const maxDependencyOutputChars = 8000
+
+// recordedStepOutput holds the final execution output of a completed step
+type recordedStepOutput struct {
+	StepNumber int
+	Title      string
+	Output     string
+}
+
+// SetInjectDependencyOutputs enables or disables automatic injection of dependency step outputs
+// into execution agent prompts (enabled by default)
+func (hcpo *HumanControlledTodoPlannerOrchestrator) SetInjectDependencyOutputs(enabled bool) {
+	hcpo.injectDependencyOutputs = enabled
+}
+
+// recordStepOutput stores a step's execution output keyed by its context output file
+// so that later steps declaring it as a context dependency can receive it directly
+func (hcpo *HumanControlledTodoPlannerOrchestrator) recordStepOutput(stepNumber int, step TodoStep, output string) {
+	contextOutput := strings.TrimSpace(hcpo.resolveVariables(step.ContextOutput))
+	if contextOutput == "" || strings.TrimSpace(output) == "" {
+		return
+	}
+
+	hcpo.stepOutputsMu.Lock()
+	defer hcpo.stepOutputsMu.Unlock()
+	if hcpo.stepOutputs == nil {
+		hcpo.stepOutputs = make(map[string]recordedStepOutput)
+	}
+	hcpo.stepOutputs[dependencyKey(contextOutput)] = recordedStepOutput{
+		StepNumber: stepNumber,
+		Title:      hcpo.resolveVariables(step.Title),
+		Output:     output,
+	}
+}
+
+// formatDependencyOutputs returns the recorded outputs of the steps this step depends on,
+// formatted for the execution agent prompt. Dependencies without a recorded output are skipped.
+func (hcpo *HumanControlledTodoPlannerOrchestrator) formatDependencyOutputs(step TodoStep) string {
+	hcpo.stepOutputsMu.Lock()
+	defer hcpo.stepOutputsMu.Unlock()
+	if !hcpo.injectDependencyOutputs || len(hcpo.stepOutputs) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	for _, dep := range step.ContextDependencies {
+		dep = strings.TrimSpace(hcpo.resolveVariables(dep))
+		recorded, ok := hcpo.stepOutputs[dependencyKey(dep)]
+		if !ok {
+			continue
+		}
+
+		output := recorded.Output
+		if runes := []rune(output); len(runes) > maxDependencyOutputChars {
+			output = string(runes[:maxDependencyOutputChars]) + "\n... (truncated, read the context file for full content)"
+		}
+
+		builder.WriteString(fmt.Sprintf("### %s (from Step %d: %s)\n%s\n\n", dep, recorded.StepNumber, recorded.Title, output))
+	}
+
+	return strings.TrimSpace(builder.String())
+}
+
+// dependencyKey normalizes a context file reference so "step_1_results.md" and
+// "execution/step_1_results.md" refer to the same recorded output
+func dependencyKey(path string) string {
+	return strings.ToLower(filepath.Base(strings.TrimSpace(path)))
+}
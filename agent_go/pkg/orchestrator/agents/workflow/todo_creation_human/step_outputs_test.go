@@ -0,0 +1,56 @@
+package todo_creation_human
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDependencyOutputInjectedIntoExecutionPrompt(t *testing.T) {
+	hcpo := &HumanControlledTodoPlannerOrchestrator{injectDependencyOutputs: true}
+
+	step1 := TodoStep{Title: "Collect URLs", ContextOutput: "step_1_results.md"}
+	hcpo.recordStepOutput(1, step1, "Found 3 MongoDB URLs: db1, db2, db3")
+
+	step2 := TodoStep{Title: "Check URLs", ContextDependencies: []string{"execution/step_1_results.md"}}
+	dependencyOutputs := hcpo.formatDependencyOutputs(step2)
+	if !strings.Contains(dependencyOutputs, "Found 3 MongoDB URLs") {
+		t.Fatalf("expected dependency output to be injected, got %q", dependencyOutputs)
+	}
+
+	agent := &HumanControlledTodoPlannerExecutionAgent{}
+	prompt := agent.humanControlledExecutionInputProcessor(map[string]string{
+		"StepNumber":        "2",
+		"TotalSteps":        "2",
+		"StepTitle":         step2.Title,
+		"DependencyOutputs": dependencyOutputs,
+	})
+	if !strings.Contains(prompt, "OUTPUTS FROM DEPENDENCY STEPS") || !strings.Contains(prompt, "db1, db2, db3") {
+		t.Errorf("expected execution prompt to contain dependency output")
+	}
+}
+
+func TestDependencyOutputInjectionDisabled(t *testing.T) {
+	hcpo := &HumanControlledTodoPlannerOrchestrator{injectDependencyOutputs: true}
+	hcpo.recordStepOutput(1, TodoStep{ContextOutput: "step_1_results.md"}, "output")
+	hcpo.SetInjectDependencyOutputs(false)
+
+	step2 := TodoStep{ContextDependencies: []string{"step_1_results.md"}}
+	if got := hcpo.formatDependencyOutputs(step2); got != "" {
+		t.Errorf("expected no dependency output when disabled, got %q", got)
+	}
+}
+
+func TestDependencyOutputTruncatedOnRuneBoundary(t *testing.T) {
+	hcpo := &HumanControlledTodoPlannerOrchestrator{injectDependencyOutputs: true}
+	// A leading ASCII byte puts every 3-byte rune across the byte cap
+	hcpo.recordStepOutput(1, TodoStep{ContextOutput: "step_1_results.md"}, "x"+strings.Repeat("€", maxDependencyOutputChars))
+
+	got := hcpo.formatDependencyOutputs(TodoStep{ContextDependencies: []string{"step_1_results.md"}})
+	if !utf8.ValidString(got) {
+		t.Fatal("expected truncated dependency output to be valid UTF-8")
+	}
+	if !strings.Contains(got, "(truncated, read the context file for full content)") {
+		t.Errorf("expected the truncation note, got %d bytes without it", len(got))
+	}
+}
@@ -0,0 +1,56 @@
+package todo_creation_human
+
+import "testing"
+
+func progressTestSteps() []TodoStep {
+	return []TodoStep{
+		{Title: "Collect URLs", Description: "List every public URL"},
+		{Title: "Check URLs", Description: "Request each URL and record the status"},
+	}
+}
+
+func TestProgressMatchesUnchangedPlan(t *testing.T) {
+	steps := progressTestSteps()
+	progress := &StepProgress{CompletedStepIndices: []int{0}, TotalSteps: 2, PlanChecksum: planChecksum(steps)}
+
+	if drift := progressPlanDrift(progress, progressTestSteps()); drift != "" {
+		t.Errorf("expected progress of the same plan to be valid, got drift %q", drift)
+	}
+}
+
+func TestProgressInvalidatedWhenStepBodyChanges(t *testing.T) {
+	progress := &StepProgress{CompletedStepIndices: []int{0}, TotalSteps: 2, PlanChecksum: planChecksum(progressTestSteps())}
+
+	// Same number of steps, different work in step 2
+	edited := progressTestSteps()
+	edited[1].Description = "Request each URL and take a screenshot"
+	if drift := progressPlanDrift(progress, edited); drift == "" {
+		t.Fatal("expected progress to be invalidated by an edited step description")
+	}
+
+	// Swapping steps changes the plan too
+	reordered := progressTestSteps()
+	reordered[0], reordered[1] = reordered[1], reordered[0]
+	if drift := progressPlanDrift(progress, reordered); drift == "" {
+		t.Error("expected progress to be invalidated by reordered steps")
+	}
+
+	// Title and description boundaries are part of the hash
+	shifted := progressTestSteps()
+	shifted[0].Title, shifted[0].Description = "Collect URLsList", " every public URL"
+	if planChecksum(shifted) == planChecksum(progressTestSteps()) {
+		t.Error("expected moving text between title and description to change the checksum")
+	}
+}
+
+func TestProgressStepCountAndLegacyProgress(t *testing.T) {
+	steps := progressTestSteps()
+	if drift := progressPlanDrift(&StepProgress{TotalSteps: 3, PlanChecksum: planChecksum(steps)}, steps); drift == "" {
+		t.Error("expected a different step count to invalidate progress")
+	}
+
+	// Progress files written before checksums existed are still matched by step count
+	if drift := progressPlanDrift(&StepProgress{CompletedStepIndices: []int{0}, TotalSteps: 2}, steps); drift != "" {
+		t.Errorf("expected legacy progress without a checksum to be kept, got drift %q", drift)
+	}
+}
@@ -37,11 +37,31 @@ type ValidationFeedback struct {
 // ValidationResponse represents the structured response from validation analysis
 type ValidationResponse struct {
 	IsSuccessCriteriaMet bool                 `json:"is_success_criteria_met"`
+	Score                *float64             `json:"score"`            // Confidence (0.0-1.0) that the success criteria was met; nil when the model omitted it
 	ExecutionStatus      string               `json:"execution_status"` // COMPLETED/PARTIAL/FAILED/INCOMPLETE
 	Reasoning            string               `json:"reasoning"`
 	Feedback             []ValidationFeedback `json:"feedback"`
 }
 
+// Passes reports whether the validation result is good enough for a step with the given threshold.
+// A threshold of 0 (or less) falls back to the binary is_success_criteria_met decision.
+// A result without a score never passes a threshold.
+func (vr *ValidationResponse) Passes(threshold float64) bool {
+	if threshold <= 0 {
+		return vr.IsSuccessCriteriaMet
+	}
+	return vr.Score != nil && *vr.Score >= threshold
+}
+
+// checkScore rejects a validation result whose score is missing when a threshold needs it,
+// rather than reading it as 0. Without a threshold the score is optional.
+func (vr *ValidationResponse) checkScore(threshold float64) error {
+	if threshold > 0 && vr.Score == nil {
+		return fmt.Errorf("validation response has no score")
+	}
+	return nil
+}
+
 // HumanControlledTodoPlannerValidationAgent validates if tasks were completed properly
 type HumanControlledTodoPlannerValidationAgent struct {
 	*agents.BaseOrchestratorAgent
@@ -108,16 +128,28 @@ func (hctpva *HumanControlledTodoPlannerValidationAgent) Execute(ctx context.Con
 	return hctpva.ExecuteWithTemplateValidation(ctx, validationTemplateVars, hctpva.humanControlledValidationInputProcessor, conversationHistory, templateData)
 }
 
-// ExecuteStructured executes the validation agent and returns structured output
-func (hctpva *HumanControlledTodoPlannerValidationAgent) ExecuteStructured(ctx context.Context, templateVars map[string]string, conversationHistory []llmtypes.MessageContent) (*ValidationResponse, error) {
+// ExecuteStructured executes the validation agent and returns structured output.
+// The score is required only when the step has a success threshold (threshold > 0).
+func (hctpva *HumanControlledTodoPlannerValidationAgent) ExecuteStructured(ctx context.Context, templateVars map[string]string, conversationHistory []llmtypes.MessageContent, threshold float64) (*ValidationResponse, error) {
+	required := `"is_success_criteria_met", "execution_status", "reasoning"`
+	if threshold > 0 {
+		required = `"is_success_criteria_met", "score", "execution_status", "reasoning"`
+	}
+
 	// Define the JSON schema for validation analysis
-	schema := `{
+	schema := fmt.Sprintf(`{
 		"type": "object",
 		"properties": {
 			"is_success_criteria_met": {
 				"type": "boolean",
 				"description": "Whether the success criteria was met based on execution evidence"
 			},
+			"score": {
+				"type": "number",
+				"minimum": 0,
+				"maximum": 1,
+				"description": "Confidence from 0.0 to 1.0 that the success criteria was met (graded success)"
+			},
 			"execution_status": {
 				"type": "string",
 				"enum": ["COMPLETED", "PARTIAL", "FAILED", "INCOMPLETE"],
@@ -150,14 +182,17 @@ func (hctpva *HumanControlledTodoPlannerValidationAgent) ExecuteStructured(ctx c
 				}
 			}
 		},
-		"required": ["is_success_criteria_met", "execution_status", "reasoning"]
-	}`
+		"required": [%s]
+	}`, required)
 
 	// Use the base orchestrator agent's ExecuteStructured method
 	result, err := agents.ExecuteStructuredWithInputProcessor[ValidationResponse](hctpva.BaseOrchestratorAgent, ctx, templateVars, hctpva.humanControlledValidationInputProcessor, conversationHistory, schema)
 	if err != nil {
 		return nil, err
 	}
+	if err := result.checkScore(threshold); err != nil {
+		return nil, err
+	}
 
 	return &result, nil
 }
@@ -263,6 +298,7 @@ Analyze the execution conversation history and validate if step {{.StepNumber}}
 
 The response should be a JSON object with:
 - is_success_criteria_met: boolean - Whether the success criteria was met based on execution evidence
+- score: number - Confidence from 0.0 to 1.0 that the success criteria was met (1.0 = fully met, 0.5 = partially met, 0.0 = not met)
 - execution_status: string - Overall status (COMPLETED/PARTIAL/FAILED/INCOMPLETE)
 - reasoning: string - Detailed reasoning for the validation decision
 - feedback: array of objects with type, description, and severity (HIGH/MEDIUM/LOW)
@@ -271,6 +307,7 @@ Example JSON structure:
 ` + "```json" + `
 {
   "is_success_criteria_met": true,
+  "score": 0.9,
   "execution_status": "COMPLETED",
   "reasoning": "The execution conversation shows clear evidence that the success criteria was met. The agent successfully used MCP tools to accomplish the step objective and provided detailed results.",
   "feedback": [
@@ -0,0 +1,72 @@
+package todo_creation_human
+
+import "testing"
+
+func TestValidationResponsePassesThreshold(t *testing.T) {
+	hcpo := &HumanControlledTodoPlannerOrchestrator{}
+	hcpo.SetSuccessThreshold(0.5)
+
+	step := TodoStep{Title: "graded step", SuccessThreshold: 0.7}
+	threshold := hcpo.GetStepSuccessThreshold(step)
+	if threshold != 0.7 {
+		t.Fatalf("expected step threshold 0.7, got %v", threshold)
+	}
+
+	above := &ValidationResponse{IsSuccessCriteriaMet: false, Score: score(0.8), ExecutionStatus: "PARTIAL"}
+	if !above.Passes(threshold) {
+		t.Errorf("score %.2f above threshold %.2f should pass", *above.Score, threshold)
+	}
+
+	below := &ValidationResponse{IsSuccessCriteriaMet: true, Score: score(0.6), ExecutionStatus: "PARTIAL"}
+	if below.Passes(threshold) {
+		t.Errorf("score %.2f below threshold %.2f should retry", *below.Score, threshold)
+	}
+}
+
+func TestValidationResponsePassesDefaultThreshold(t *testing.T) {
+	hcpo := &HumanControlledTodoPlannerOrchestrator{}
+
+	// No step or orchestrator threshold: keep the binary decision
+	threshold := hcpo.GetStepSuccessThreshold(TodoStep{})
+	if threshold != 0 {
+		t.Fatalf("expected no threshold, got %v", threshold)
+	}
+	if !(&ValidationResponse{IsSuccessCriteriaMet: true}).Passes(threshold) {
+		t.Error("met criteria should pass without a threshold")
+	}
+	if (&ValidationResponse{IsSuccessCriteriaMet: false, Score: score(1)}).Passes(threshold) {
+		t.Error("unmet criteria should retry without a threshold")
+	}
+
+	// Orchestrator default applies when the step doesn't set one
+	hcpo.SetSuccessThreshold(0.6)
+	if got := hcpo.GetStepSuccessThreshold(TodoStep{}); got != 0.6 {
+		t.Fatalf("expected default threshold 0.6, got %v", got)
+	}
+}
+
+func TestValidationResponseWithoutScore(t *testing.T) {
+	missing := &ValidationResponse{IsSuccessCriteriaMet: true, ExecutionStatus: "COMPLETED"}
+	if err := missing.checkScore(0.5); err == nil {
+		t.Fatal("expected a missing score to be an error under a threshold")
+	}
+	// Without a threshold the score is optional and the binary decision stands
+	if err := missing.checkScore(0); err != nil {
+		t.Fatalf("expected a missing score to be accepted without a threshold, got %v", err)
+	}
+	if !missing.Passes(0) {
+		t.Error("met criteria without a score should pass when no threshold is set")
+	}
+	if missing.Passes(0.5) {
+		t.Error("a result without a score should not pass a threshold")
+	}
+
+	zero := &ValidationResponse{Score: score(0)}
+	if err := zero.checkScore(0.5); err != nil {
+		t.Fatalf("expected an explicit 0 score to be accepted, got %v", err)
+	}
+}
+
+func score(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,77 @@
+package todo_execution
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"mcp-agent/agent_go/pkg/orchestrator"
+)
+
+// consensusValidationPrompt asks a model to judge a step's execution and reply with a ValidationResponse as JSON
+func consensusValidationPrompt(step TodoStep, stepNumber, totalSteps int, executionOutput string) string {
+	return fmt.Sprintf(`You are validating step %d of %d of a workflow.
+
+## Step
+Title: %s
+Description: %s
+Success criteria: %s
+
+## Execution output
+%s
+
+Decide whether the execution met the step's success criteria. Reply with only a JSON object:
+{"is_objective_success_criteria_met": true or false, "feedback": "why, and what to fix if not met"}`,
+		stepNumber, totalSteps, step.Title, step.Description, step.SuccessCriteria, executionOutput)
+}
+
+// parseConsensusValidation decodes the JSON ValidationResponse in a model's output
+func parseConsensusValidation(output string) (*ValidationResponse, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON object in output")
+	}
+	var response struct {
+		Met      *bool  `json:"is_objective_success_criteria_met"`
+		Feedback string `json:"feedback"`
+	}
+	if err := json.Unmarshal([]byte(output[start:end+1]), &response); err != nil {
+		return nil, fmt.Errorf("invalid validation JSON: %w", err)
+	}
+	if response.Met == nil {
+		return nil, fmt.Errorf("validation JSON has no is_objective_success_criteria_met")
+	}
+	return &ValidationResponse{IsObjectiveSuccessCriteriaMet: *response.Met, Feedback: response.Feedback}, nil
+}
+
+// validationVerdict is the answer the consensus models must agree on
+func validationVerdict(output string) (string, error) {
+	response, err := parseConsensusValidation(output)
+	if err != nil {
+		return "", err
+	}
+	if response.IsObjectiveSuccessCriteriaMet {
+		return "met", nil
+	}
+	return "not_met", nil
+}
+
+// runConsensusValidation validates a step designated for consensus (see orchestrator.ConsensusConfig):
+// the consensus models judge the same execution output and the agreed (or judged) verdict is used.
+// Without consensus the step is treated as not met, so it is retried rather than accepted on a split vote.
+func (teo *TodoExecutionOrchestrator) runConsensusValidation(ctx context.Context, step TodoStep, stepNumber, totalSteps int, executionOutput string) (*ValidationResponse, error) {
+	prompt := consensusValidationPrompt(step, stepNumber, totalSteps, executionOutput)
+	result, err := teo.RunConsensus(ctx, step.Title, prompt, validationVerdict)
+	if err != nil {
+		return nil, fmt.Errorf("step %d consensus validation failed: %w", stepNumber, err)
+	}
+
+	if result.Method == orchestrator.ConsensusMethodNoConsensus {
+		return &ValidationResponse{
+			IsObjectiveSuccessCriteriaMet: false,
+			Feedback:                      "The validation models did not agree whether this step met its success criteria; re-run the step and make its result unambiguous.",
+		}, nil
+	}
+	return parseConsensusValidation(result.Output)
+}
@@ -38,6 +38,12 @@ func (e *TodoStepsExtractedEvent) GetEventType() events.EventType {
 type TodoExecutionOrchestrator struct {
 	// Base orchestrator for common functionality
 	*orchestrator.BaseOrchestrator
+
+	// Per-step decisions from partial workflow approval (see StepApproval); nil runs every step
+	stepApprovals map[int]StepApproval
+
+	// stepRunner replaces step execution and validation (tests)
+	stepRunner func(ctx context.Context, step TodoStep, stepNumber, totalSteps int)
 }
 
 // NewTodoExecutionOrchestrator creates a new multi-agent todo execution orchestrator
@@ -172,6 +178,11 @@ func (teo *TodoExecutionOrchestrator) ExecuteTodos(ctx context.Context, objectiv
 		// Emit todo steps extracted event (so frontend can display the extracted steps)
 		teo.emitTodoStepsExtractedEvent(ctx, steps, "todo_final_md")
 
+		// Partial approval was already given per step through the workflow's selected options
+		if len(teo.stepApprovals) > 0 {
+			break
+		}
+
 		// Request human approval for the extracted steps
 		approved, feedback, err := teo.requestStepsApproval(ctx, steps, revisionAttempt)
 		if err != nil {
@@ -192,61 +203,63 @@ func (teo *TodoExecutionOrchestrator) ExecuteTodos(ctx context.Context, objectiv
 		}
 	}
 
-	// Execute each step individually with validation feedback loop
+	// Execute each step individually with validation feedback loop; under partial approval,
+	// steps not approved are held for revision
+	heldSteps := teo.runApprovedSteps(ctx, steps, teo.stepApprovals, selectedRunFolder, runOption)
 
-	for i, step := range steps {
-		teo.GetLogger().Infof("🔄 Executing step %d/%d: %s", i+1, len(steps), step.Title)
+	duration := time.Since(teo.GetStartTime())
+	teo.GetLogger().Infof("✅ Multi-agent todo execution completed in %v", duration)
 
-		var executionResult string
-		var validationResult string
-		maxAttempts := 3
-		attempt := 1
+	if len(heldSteps) > 0 {
+		return fmt.Sprintf("Execution Completed for approved steps. %d steps held for revision:\n%s", len(heldSteps), formatHeldSteps(heldSteps)), nil
+	}
+	return "Execution Completed", nil
+}
 
-		for attempt <= maxAttempts {
-			teo.GetLogger().Infof("🔄 Attempt %d/%d for step %d", attempt, maxAttempts, i+1)
+// executeStepWithValidation executes one step and validates it, retrying with the validation feedback
+func (teo *TodoExecutionOrchestrator) executeStepWithValidation(ctx context.Context, step TodoStep, stepNumber, totalSteps int, selectedRunFolder, runOption string) {
+	var executionResult string
+	var validationResult string
+	maxAttempts := 3
+	attempt := 1
 
-			// Execute this specific step
-			var err error
-			var conversationHistory []llmtypes.MessageContent
-			executionResult, conversationHistory, err = teo.runStepExecutionPhase(ctx, step, i+1, len(steps), selectedRunFolder, runOption, validationResult)
-			if err != nil {
-				teo.GetLogger().Warnf("⚠️ Step %d execution failed (attempt %d): %v", i+1, attempt, err)
-				executionResult = fmt.Sprintf("Step %d execution failed (attempt %d): %v", i+1, attempt, err)
-				conversationHistory = nil
-			}
+	for attempt <= maxAttempts {
+		teo.GetLogger().Infof("🔄 Attempt %d/%d for step %d", attempt, maxAttempts, stepNumber)
 
-			// Validate this specific step
-			validationResponse, err := teo.runStepValidationPhase(ctx, step, i+1, len(steps), executionResult, conversationHistory)
-			if err != nil {
-				teo.GetLogger().Warnf("⚠️ Step %d validation failed (attempt %d): %v", i+1, attempt, err)
-				break
-			}
+		// Execute this specific step
+		var err error
+		var conversationHistory []llmtypes.MessageContent
+		executionResult, conversationHistory, err = teo.runStepExecutionPhase(ctx, step, stepNumber, totalSteps, selectedRunFolder, runOption, validationResult)
+		if err != nil {
+			teo.GetLogger().Warnf("⚠️ Step %d execution failed (attempt %d): %v", stepNumber, attempt, err)
+			executionResult = fmt.Sprintf("Step %d execution failed (attempt %d): %v", stepNumber, attempt, err)
+			conversationHistory = nil
+		}
 
-			// Check if validation passed
-			if validationResponse.IsObjectiveSuccessCriteriaMet {
-				teo.GetLogger().Infof("✅ Step %d completed successfully on attempt %d: %s", i+1, attempt, validationResponse.Feedback)
-				break
-			} else {
-				teo.GetLogger().Infof("⚠️ Step %d validation failed on attempt %d: %s", i+1, attempt, validationResponse.Feedback)
-				validationResult = validationResponse.Feedback
+		// Validate this specific step
+		validationResponse, err := teo.runStepValidationPhase(ctx, step, stepNumber, totalSteps, executionResult, conversationHistory)
+		if err != nil {
+			teo.GetLogger().Warnf("⚠️ Step %d validation failed (attempt %d): %v", stepNumber, attempt, err)
+			break
+		}
 
-				if attempt < maxAttempts {
-					teo.GetLogger().Infof("🔄 Retrying step %d with feedback: %s", i+1, validationResponse.Feedback)
-				} else {
-					teo.GetLogger().Warnf("❌ Step %d failed after %d attempts. Final feedback: %s", i+1, maxAttempts, validationResponse.Feedback)
-				}
-			}
+		// Check if validation passed
+		if validationResponse.IsObjectiveSuccessCriteriaMet {
+			teo.GetLogger().Infof("✅ Step %d completed successfully on attempt %d: %s", stepNumber, attempt, validationResponse.Feedback)
+			break
+		} else {
+			teo.GetLogger().Infof("⚠️ Step %d validation failed on attempt %d: %s", stepNumber, attempt, validationResponse.Feedback)
+			validationResult = validationResponse.Feedback
 
-			attempt++
+			if attempt < maxAttempts {
+				teo.GetLogger().Infof("🔄 Retrying step %d with feedback: %s", stepNumber, validationResponse.Feedback)
+			} else {
+				teo.GetLogger().Warnf("❌ Step %d failed after %d attempts. Final feedback: %s", stepNumber, maxAttempts, validationResponse.Feedback)
+			}
 		}
 
-		// Results are logged and used for validation within the loop; no aggregation needed
+		attempt++
 	}
-
-	duration := time.Since(teo.GetStartTime())
-	teo.GetLogger().Infof("✅ Multi-agent todo execution completed in %v", duration)
-
-	return "Execution Completed", nil
 }
 
 // runStepExecutionPhase executes a single step using the execution agent
@@ -286,6 +299,15 @@ func (teo *TodoExecutionOrchestrator) runStepExecutionPhase(ctx context.Context,
 
 // runStepValidationPhase validates a single step's execution using the validation agent
 func (teo *TodoExecutionOrchestrator) runStepValidationPhase(ctx context.Context, step TodoStep, stepNumber, totalSteps int, executionResult string, conversationHistory []llmtypes.MessageContent) (*ValidationResponse, error) {
+	// Critical steps are judged by several models (see orchestrator.ConsensusConfig)
+	if teo.ConsensusEnabled(step.Title) {
+		executionOutput := shared.FormatConversationHistory(conversationHistory)
+		if executionOutput == "" {
+			executionOutput = executionResult
+		}
+		return teo.runConsensusValidation(ctx, step, stepNumber, totalSteps, executionOutput)
+	}
+
 	validationAgent, err := teo.createValidationAgent(ctx, step.Title, stepNumber, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create validation agent: %w", err)
@@ -415,6 +437,11 @@ func (teo *TodoExecutionOrchestrator) Execute(ctx context.Context, objective str
 		runOption = ro
 	}
 
+	// Extract per-step decisions for partial approval
+	if approvals, ok := options["StepApprovals"].(map[int]StepApproval); ok && len(approvals) > 0 {
+		teo.stepApprovals = approvals
+	}
+
 	// Call the existing ExecuteTodos method
 	return teo.ExecuteTodos(ctx, objective, workspacePath, runOption)
 }
@@ -0,0 +1,126 @@
+package todo_execution
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// StepApproval is a reviewer's decision on one plan step, passed to Execute keyed by 1-based
+// step number in the "StepApprovals" option. When present, only approved steps execute.
+type StepApproval struct {
+	Approved bool
+	Feedback string // Requested changes when not approved
+}
+
+// heldStepsForApproval returns the steps that must not execute under partial approval, keyed by
+// step number: steps not approved, and approved steps depending on the output of a held step.
+// No approvals means the whole plan was approved.
+func heldStepsForApproval(steps []TodoStep, approvals map[int]StepApproval) map[int]events.HeldTodoStep {
+	held := make(map[int]events.HeldTodoStep)
+	if len(approvals) == 0 {
+		return held
+	}
+
+	heldOutputs := make(map[string]int) // Context output of a held step -> its step number
+	for i, step := range steps {
+		stepNumber := i + 1
+		reason := ""
+		approval, decided := approvals[stepNumber]
+		switch {
+		case !decided:
+			reason = "not approved"
+		case !approval.Approved && approval.Feedback != "":
+			reason = "changes requested: " + approval.Feedback
+		case !approval.Approved:
+			reason = "changes requested"
+		default:
+			for _, dep := range step.ContextDependencies {
+				if producer, isHeld := heldOutputs[outputKey(dep)]; isHeld {
+					reason = fmt.Sprintf("depends on step %d, which is held for revision", producer)
+					break
+				}
+			}
+		}
+		if reason == "" {
+			continue
+		}
+
+		held[stepNumber] = events.HeldTodoStep{StepNumber: stepNumber, Title: step.Title, Reason: reason}
+		if output := outputKey(step.ContextOutput); output != "" {
+			heldOutputs[output] = stepNumber
+		}
+	}
+	return held
+}
+
+// outputKey normalizes a context file reference so "step_1_results.md" and
+// "execution/step_1_results.md" refer to the same file
+func outputKey(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return ""
+	}
+	return strings.ToLower(filepath.Base(path))
+}
+
+// runApprovedSteps executes the steps not held for revision in order and returns the held ones
+func (teo *TodoExecutionOrchestrator) runApprovedSteps(ctx context.Context, steps []TodoStep, approvals map[int]StepApproval, selectedRunFolder, runOption string) []events.HeldTodoStep {
+	held := heldStepsForApproval(steps, approvals)
+
+	var executed []int
+	var heldSteps []events.HeldTodoStep
+	for i, step := range steps {
+		if heldStep, isHeld := held[i+1]; isHeld {
+			teo.GetLogger().Infof("⏸️ Holding step %d/%d for revision (%s): %s", i+1, len(steps), heldStep.Reason, step.Title)
+			heldSteps = append(heldSteps, heldStep)
+			continue
+		}
+		teo.GetLogger().Infof("🔄 Executing step %d/%d: %s", i+1, len(steps), step.Title)
+		if teo.stepRunner != nil {
+			teo.stepRunner(ctx, step, i+1, len(steps))
+		} else {
+			teo.executeStepWithValidation(ctx, step, i+1, len(steps), selectedRunFolder, runOption)
+		}
+		executed = append(executed, i+1)
+	}
+
+	if len(heldSteps) > 0 {
+		teo.emitStepsHeldForRevisionEvent(ctx, executed, heldSteps)
+	}
+	return heldSteps
+}
+
+// emitStepsHeldForRevisionEvent reports the steps a partially approved plan did not execute
+func (teo *TodoExecutionOrchestrator) emitStepsHeldForRevisionEvent(ctx context.Context, executed []int, held []events.HeldTodoStep) {
+	bridge := teo.GetContextAwareBridge()
+	if bridge == nil {
+		return
+	}
+
+	event := &events.AgentEvent{
+		Type:      events.TodoStepsHeldForRevision,
+		Timestamp: time.Now(),
+		Data: &events.TodoStepsHeldForRevisionEvent{
+			BaseEventData: events.BaseEventData{Timestamp: time.Now()},
+			ExecutedSteps: executed,
+			HeldSteps:     held,
+		},
+	}
+	if err := bridge.HandleEvent(ctx, event); err != nil {
+		teo.GetLogger().Warnf("⚠️ Failed to emit steps held for revision event: %v", err)
+	}
+}
+
+// formatHeldSteps summarizes held steps for the execution result
+func formatHeldSteps(held []events.HeldTodoStep) string {
+	lines := make([]string, 0, len(held))
+	for _, step := range held {
+		lines = append(lines, fmt.Sprintf("- Step %d (%s): %s", step.StepNumber, step.Title, step.Reason))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,96 @@
+package todo_execution
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+// heldStepsListener collects steps held for revision events
+type heldStepsListener struct {
+	mu     sync.Mutex
+	events []*events.TodoStepsHeldForRevisionEvent
+}
+
+func (l *heldStepsListener) HandleEvent(ctx context.Context, event *events.AgentEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if data, ok := event.Data.(*events.TodoStepsHeldForRevisionEvent); ok {
+		l.events = append(l.events, data)
+	}
+	return nil
+}
+
+func (l *heldStepsListener) Name() string {
+	return "held-steps-listener"
+}
+
+func TestPartiallyApprovedStepsExecuteAndHoldTheRest(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+	listener := &heldStepsListener{}
+	teo, err := NewTodoExecutionOrchestrator("openai", "test-model", 0, "simple", nil, nil, "", nil, 5, testLogger, nil, listener, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	var ran []int
+	teo.stepRunner = func(ctx context.Context, step TodoStep, stepNumber, totalSteps int) {
+		ran = append(ran, stepNumber)
+	}
+
+	steps := []TodoStep{
+		{Title: "Collect URLs", ContextOutput: "step_1_results.md"},
+		{Title: "Check URLs", ContextDependencies: []string{"step_1_results.md"}, ContextOutput: "step_2_results.md"},
+		{Title: "Write report", ContextDependencies: []string{"execution/step_2_results.md"}},
+		{Title: "Archive URLs", ContextDependencies: []string{"step_1_results.md"}},
+		{Title: "Notify team"},
+	}
+	approvals := map[int]StepApproval{
+		1: {Approved: true},
+		2: {Approved: false, Feedback: "also check redirects"},
+		3: {Approved: true},
+		4: {Approved: true},
+		// Step 5 has no decision
+	}
+
+	held := teo.runApprovedSteps(context.Background(), steps, approvals, "", "")
+
+	if len(ran) != 2 || ran[0] != 1 || ran[1] != 4 {
+		t.Fatalf("expected only approved steps 1 and 4 to run, got %v", ran)
+	}
+	wantReasons := map[int]string{
+		2: "changes requested: also check redirects",
+		3: "depends on step 2, which is held for revision",
+		5: "not approved",
+	}
+	if len(held) != len(wantReasons) {
+		t.Fatalf("expected %d held steps, got %+v", len(wantReasons), held)
+	}
+	for _, step := range held {
+		if step.Reason != wantReasons[step.StepNumber] {
+			t.Errorf("step %d: expected reason %q, got %q", step.StepNumber, wantReasons[step.StepNumber], step.Reason)
+		}
+	}
+	if summary := formatHeldSteps(held); !strings.Contains(summary, "- Step 3 (Write report): depends on step 2") {
+		t.Errorf("unexpected held steps summary:\n%s", summary)
+	}
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	if len(listener.events) != 1 {
+		t.Fatalf("expected one held for revision event, got %d", len(listener.events))
+	}
+	if event := listener.events[0]; len(event.ExecutedSteps) != 2 || len(event.HeldSteps) != 3 {
+		t.Fatalf("unexpected held for revision event: %+v", event)
+	}
+}
+
+func TestNoStepApprovalsRunsWholePlan(t *testing.T) {
+	steps := []TodoStep{{Title: "One"}, {Title: "Two"}}
+	if held := heldStepsForApproval(steps, nil); len(held) != 0 {
+		t.Fatalf("expected no held steps without approvals, got %+v", held)
+	}
+}
@@ -5,9 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
-	virtualtools "mcp-agent/agent_go/cmd/server/virtual-tools"
 	"mcp-agent/agent_go/internal/observability"
 	"mcp-agent/agent_go/internal/utils"
 	"mcp-agent/agent_go/pkg/events"
@@ -32,6 +32,9 @@ type LLMConfig struct {
 	ModelID               string                        `json:"model_id"`
 	FallbackModels        []string                      `json:"fallback_models"`
 	CrossProviderFallback *agents.CrossProviderFallback `json:"cross_provider_fallback,omitempty"`
+	// Providers and models this request must never fall back to (e.g. data residency)
+	ExcludeProviders []string `json:"exclude_providers,omitempty"`
+	ExcludeModels    []string `json:"exclude_models,omitempty"`
 }
 
 // OrchestratorType represents the type of orchestrator
@@ -69,9 +72,33 @@ type BaseOrchestrator struct {
 	llmConfig       *LLMConfig // LLM configuration
 	maxTurns        int        // Maximum turns for the orchestrator
 
+	// Per-request LLM of the agents' structured output extraction (see SetStructuredOutputLLM)
+	structuredOutputLLM *agents.StructuredOutputLLMConfig
+
 	// Optional simple state (for workflow orchestrators)
 	objective     string
 	workspacePath string
+
+	// Named checkpoints (see CreateNamedCheckpoint); workspaceRoot locates the workspace on disk
+	workspaceRoot      string
+	checkpoints        map[string]*NamedCheckpoint
+	checkpointMaxBytes int64
+	checkpointMu       sync.Mutex
+
+	// Dollar budget for the run's estimated LLM cost (see SetCostBudget)
+	costBudget costBudget
+
+	// Token usage of the run's agents, rolled up when the run ends
+	usage *events.CostSummary
+
+	// Multi-model consensus on designated steps (see SetConsensus)
+	consensus consensusState
+
+	// How long feedback requests wait for a response (see SetHumanFeedbackTimeout); 0 uses the env default
+	feedbackTimeout time.Duration
+
+	// Agents and sub-orchestrators owning MCP connections, terminated by Shutdown
+	owned owned
 }
 
 // NewBaseOrchestrator creates a new unified base orchestrator
@@ -95,7 +122,7 @@ func NewBaseOrchestrator(
 	// Create context-aware event bridge that wraps the main event bridge
 	contextAwareBridge := NewContextAwareEventBridge(eventBridge, logger)
 
-	return &BaseOrchestrator{
+	bo := &BaseOrchestrator{
 		contextAwareBridge:     contextAwareBridge,
 		logger:                 logger,
 		WorkspaceTools:         customTools,
@@ -112,7 +139,15 @@ func NewBaseOrchestrator(
 		selectedTools:   selectedTools, // NEW field
 		llmConfig:       llmConfig,
 		maxTurns:        maxTurns,
-	}, nil
+		usage:           events.NewCostSummary(),
+	}
+	// Every event of the run passes the bridge, so it meters LLM cost against the budget
+	// and collects the agents' cost summaries
+	contextAwareBridge.observer = func(ctx context.Context, event *events.AgentEvent) {
+		bo.recordCost(ctx, event)
+		bo.recordUsage(event)
+	}
+	return bo, nil
 }
 
 // GetLogger returns the orchestrator's logger
@@ -122,6 +157,11 @@ func (bo *BaseOrchestrator) GetLogger() utils.ExtendedLogger {
 
 // emitEvent emits an event through the event bridge
 func (bo *BaseOrchestrator) emitEvent(ctx context.Context, eventType events.EventType, data events.EventData) {
+	// Attach actionable remediation hints to error events with a known cause
+	events.EnrichWithRemediation(data)
+	// Tag the event with the request's tags (e.g. its experiment variant)
+	events.ApplyEventTags(ctx, data)
+
 	// Create agent event
 	agentEvent := &events.AgentEvent{
 		Type:      eventType,
@@ -171,6 +211,7 @@ func (bo *BaseOrchestrator) EmitOrchestratorEnd(ctx context.Context, objective,
 	}
 
 	bo.emitEvent(ctx, events.OrchestratorEnd, eventData)
+	bo.emitCostSummary(ctx)
 }
 
 // EmitUnifiedCompletionEvent emits a unified completion event
@@ -230,6 +271,12 @@ func (bo *BaseOrchestrator) SetWorkspacePath(workspacePath string) {
 	bo.workspacePath = workspacePath
 }
 
+// SetStructuredOutputLLM makes the agents extract structured output with another provider/model than
+// their execution LLM; nil keeps the execution LLM
+func (bo *BaseOrchestrator) SetStructuredOutputLLM(config *agents.StructuredOutputLLMConfig) {
+	bo.structuredOutputLLM = config
+}
+
 // GetContextAwareBridge returns the context-aware event bridge
 func (bo *BaseOrchestrator) GetContextAwareBridge() mcpagent.AgentEventListener {
 	return bo.contextAwareBridge
@@ -345,11 +392,35 @@ func (bo *BaseOrchestrator) createAgentConfigWithLLM(agentName string, maxTurns
 	if llmConfig != nil {
 		config.FallbackModels = llmConfig.FallbackModels
 		config.CrossProviderFallback = llmConfig.CrossProviderFallback
+		config.ExcludeProviders = llmConfig.ExcludeProviders
+		config.ExcludeModels = llmConfig.ExcludeModels
 	}
+	config.StructuredOutputLLM = bo.structuredOutputLLM
 
 	return config
 }
 
+// connectAgentBridge connects an agent to its own context-aware bridge, derived from the orchestrator's,
+// so its events carry its phase, step, iteration and name even while other agents run concurrently.
+// The shared bridge's context still follows the latest agent for orchestrator-level events.
+func (bo *BaseOrchestrator) connectAgentBridge(agent agents.OrchestratorAgent, mcpAgent *mcpagent.Agent, phase string, step, iteration int, agentName string) error {
+	cab, ok := bo.GetContextAwareBridge().(*ContextAwareEventBridge)
+	if !ok {
+		return fmt.Errorf("context-aware bridge type mismatch")
+	}
+	cab.SetOrchestratorContext(phase, step, iteration, agentName)
+
+	agentBridge := cab.WithOrchestratorContext(phase, step, iteration, agentName)
+	mcpAgent.AddEventListener(agentBridge)
+	// The orchestrator agent's own lifecycle events go through the same bridge
+	if settable, ok := agent.(interface {
+		SetEventBridge(bridge mcpagent.AgentEventListener)
+	}); ok {
+		settable.SetEventBridge(agentBridge)
+	}
+	return nil
+}
+
 // CreateAndSetupStandardAgent creates and sets up an agent with standardized configuration
 func (bo *BaseOrchestrator) CreateAndSetupStandardAgent(
 	ctx context.Context,
@@ -372,6 +443,8 @@ func (bo *BaseOrchestrator) CreateAndSetupStandardAgent(
 	if err := agent.Initialize(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize %s: %w", agentName, err)
 	}
+	// Owned until Shutdown, which terminates its MCP connections
+	bo.trackAgent(agent)
 
 	// Validate essentials and connect event bridge
 	eventBridge := bo.GetContextAwareBridge()
@@ -392,14 +465,11 @@ func (bo *BaseOrchestrator) CreateAndSetupStandardAgent(
 
 	// 🔗 Connect agent to orchestrator's main event bridge using existing bridge (reuse)
 	baseAgentName := baseAgent.GetName()
-	if cab, ok := eventBridge.(*ContextAwareEventBridge); ok {
-		cab.SetOrchestratorContext(phase, step, iteration, baseAgentName)
-		mcpAgent.AddEventListener(cab)
-		bo.GetLogger().Infof("🔗 Reused context-aware bridge connected to %s (step %d, iteration %d, agent %s)", phase, step+1, iteration+1, baseAgentName)
-		bo.GetLogger().Infof("ℹ️ Skipping StartAgentSession for %s - handled at orchestrator level", phase)
-	} else {
-		return nil, fmt.Errorf("context-aware bridge type mismatch for %s", agentName)
+	if err := bo.connectAgentBridge(agent, mcpAgent, phase, step, iteration, baseAgentName); err != nil {
+		return nil, fmt.Errorf("%w for %s", err, agentName)
 	}
+	bo.GetLogger().Infof("🔗 Context-aware bridge connected to %s (step %d, iteration %d, agent %s)", phase, step+1, iteration+1, baseAgentName)
+	bo.GetLogger().Infof("ℹ️ Skipping StartAgentSession for %s - handled at orchestrator level", phase)
 
 	// Register custom tools
 	if customTools != nil && customToolExecutors != nil {
@@ -464,6 +534,8 @@ func (bo *BaseOrchestrator) CreateAndSetupStandardAgentWithCustomServers(
 	if err := agent.Initialize(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize %s: %w", agentName, err)
 	}
+	// Owned until Shutdown, which terminates its MCP connections
+	bo.trackAgent(agent)
 
 	// Validate essentials and connect event bridge
 	eventBridge := bo.GetContextAwareBridge()
@@ -484,16 +556,11 @@ func (bo *BaseOrchestrator) CreateAndSetupStandardAgentWithCustomServers(
 
 	// 🔗 Connect agent to orchestrator's main event bridge using existing bridge (reuse)
 	baseAgentName := baseAgent.GetName()
-	if cab, ok := eventBridge.(interface {
-		SetOrchestratorContext(phase string, step, iteration int, agentName string)
-	}); ok {
-		cab.SetOrchestratorContext(phase, step, iteration, baseAgentName)
-		mcpAgent.AddEventListener(eventBridge)
-		bo.GetLogger().Infof("🔗 Reused context-aware bridge connected to %s (step %d, iteration %d, agent %s)", phase, step+1, iteration+1, baseAgentName)
-		bo.GetLogger().Infof("ℹ️ Skipping StartAgentSession for %s - handled at orchestrator level", phase)
-	} else {
-		return nil, fmt.Errorf("context-aware bridge type mismatch for %s", agentName)
+	if err := bo.connectAgentBridge(agent, mcpAgent, phase, step, iteration, baseAgentName); err != nil {
+		return nil, fmt.Errorf("%w for %s", err, agentName)
 	}
+	bo.GetLogger().Infof("🔗 Context-aware bridge connected to %s (step %d, iteration %d, agent %s)", phase, step+1, iteration+1, baseAgentName)
+	bo.GetLogger().Infof("ℹ️ Skipping StartAgentSession for %s - handled at orchestrator level", phase)
 
 	// Register custom tools
 	if customTools != nil && customToolExecutors != nil {
@@ -559,6 +626,8 @@ func (bo *BaseOrchestrator) CreateAndSetupStandardAgentWithSystemPrompt(
 	if err := agent.Initialize(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize %s: %w", agentName, err)
 	}
+	// Owned until Shutdown, which terminates its MCP connections
+	bo.trackAgent(agent)
 
 	// Set system prompt and user message processors if provided
 	// Since agents embed *BaseOrchestratorAgent, methods are promoted
@@ -598,14 +667,11 @@ func (bo *BaseOrchestrator) CreateAndSetupStandardAgentWithSystemPrompt(
 
 	// 🔗 Connect agent to orchestrator's main event bridge using existing bridge (reuse)
 	baseAgentName := baseAgent.GetName()
-	if cab, ok := eventBridge.(*ContextAwareEventBridge); ok {
-		cab.SetOrchestratorContext(phase, step, iteration, baseAgentName)
-		mcpAgent.AddEventListener(cab)
-		bo.GetLogger().Infof("🔗 Reused context-aware bridge connected to %s (step %d, iteration %d, agent %s)", phase, step+1, iteration+1, baseAgentName)
-		bo.GetLogger().Infof("ℹ️ Skipping StartAgentSession for %s - handled at orchestrator level", phase)
-	} else {
-		return nil, fmt.Errorf("context-aware bridge type mismatch for %s", agentName)
+	if err := bo.connectAgentBridge(agent, mcpAgent, phase, step, iteration, baseAgentName); err != nil {
+		return nil, fmt.Errorf("%w for %s", err, agentName)
 	}
+	bo.GetLogger().Infof("🔗 Context-aware bridge connected to %s (step %d, iteration %d, agent %s)", phase, step+1, iteration+1, baseAgentName)
+	bo.GetLogger().Infof("ℹ️ Skipping StartAgentSession for %s - handled at orchestrator level", phase)
 
 	// Register custom tools
 	if customTools != nil && customToolExecutors != nil {
@@ -672,6 +738,8 @@ func (bo *BaseOrchestrator) CreateAndSetupStandardAgentWithCustomServersAndSyste
 	if err := agent.Initialize(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize %s: %w", agentName, err)
 	}
+	// Owned until Shutdown, which terminates its MCP connections
+	bo.trackAgent(agent)
 
 	// Set system prompt and user message processors if provided
 	// Since agents embed *BaseOrchestratorAgent, methods are promoted
@@ -711,16 +779,11 @@ func (bo *BaseOrchestrator) CreateAndSetupStandardAgentWithCustomServersAndSyste
 
 	// 🔗 Connect agent to orchestrator's main event bridge using existing bridge (reuse)
 	baseAgentName := baseAgent.GetName()
-	if cab, ok := eventBridge.(interface {
-		SetOrchestratorContext(phase string, step, iteration int, agentName string)
-	}); ok {
-		cab.SetOrchestratorContext(phase, step, iteration, baseAgentName)
-		mcpAgent.AddEventListener(eventBridge)
-		bo.GetLogger().Infof("🔗 Reused context-aware bridge connected to %s (step %d, iteration %d, agent %s)", phase, step+1, iteration+1, baseAgentName)
-		bo.GetLogger().Infof("ℹ️ Skipping StartAgentSession for %s - handled at orchestrator level", phase)
-	} else {
-		return nil, fmt.Errorf("context-aware bridge type mismatch for %s", agentName)
+	if err := bo.connectAgentBridge(agent, mcpAgent, phase, step, iteration, baseAgentName); err != nil {
+		return nil, fmt.Errorf("%w for %s", err, agentName)
 	}
+	bo.GetLogger().Infof("🔗 Context-aware bridge connected to %s (step %d, iteration %d, agent %s)", phase, step+1, iteration+1, baseAgentName)
+	bo.GetLogger().Infof("ℹ️ Skipping StartAgentSession for %s - handled at orchestrator level", phase)
 
 	// Register custom tools
 	if customTools != nil && customToolExecutors != nil {
@@ -941,7 +1004,7 @@ func (bo *BaseOrchestrator) CheckWorkspaceFileExists(ctx context.Context, filePa
 }
 
 // RequestHumanFeedback is a common function for requesting human feedback with blocking behavior
-// Returns: (approved bool, feedback string, error)
+// Returns: (approved bool, feedback string, error); the error wraps ErrFeedbackTimeout when nobody answers in time
 func (bo *BaseOrchestrator) RequestHumanFeedback(
 	ctx context.Context,
 	requestID string,
@@ -949,6 +1012,7 @@ func (bo *BaseOrchestrator) RequestHumanFeedback(
 	context string,
 	sessionID string,
 	workflowID string,
+	opts ...FeedbackOption,
 ) (bool, string, error) {
 	bo.GetLogger().Infof("🤔 Requesting human feedback: %s", question)
 
@@ -978,33 +1042,23 @@ func (bo *BaseOrchestrator) RequestHumanFeedback(
 	}
 
 	// Use HumanFeedbackStore to wait for response
-	feedbackStore := virtualtools.GetHumanFeedbackStore()
-
-	// Create feedback request (this registers it in the store)
-	if err := feedbackStore.CreateRequest(requestID, question); err != nil {
-		return false, "", fmt.Errorf("failed to create feedback request: %w", err)
-	}
-
-	bo.GetLogger().Infof("⏸️ Orchestrator paused, waiting for human response (timeout: 10 minutes)...")
-
-	// BLOCKING CALL - waits here until response or timeout
-	response, err := feedbackStore.WaitForResponse(requestID, 10*time.Minute)
-	if err != nil {
-		return false, "", fmt.Errorf("timeout waiting for human feedback: %w", err)
+	response, answered, err := bo.awaitHumanResponse(requestID, question, opts)
+	if !answered && response == "" {
+		return false, "", err
 	}
 
 	bo.GetLogger().Infof("▶️ Orchestrator resumed with human response: %s", response)
 
-	// Parse response
+	// Parse response (a timed out request's timeout answer is parsed the same way)
 	// Expected format: "Approve" or feedback text for revision
 	if strings.TrimSpace(response) == "Approve" {
 		bo.GetLogger().Infof("✅ User approved via button, continuing")
-		return true, "", nil
+		return true, "", err
 	}
 
 	// Default: treat as feedback for revision
 	bo.GetLogger().Infof("🔄 User provided feedback: %s", response)
-	return false, response, nil
+	return false, response, err
 }
 
 // RequestYesNoFeedback requests simple yes/no feedback from user with Approve/Reject buttons
@@ -1018,6 +1072,7 @@ func (bo *BaseOrchestrator) RequestYesNoFeedback(
 	context string,
 	sessionID string,
 	workflowID string,
+	opts ...FeedbackOption,
 ) (bool, error) {
 	bo.GetLogger().Infof("🤔 Requesting yes/no feedback: %s", question)
 
@@ -1057,16 +1112,9 @@ func (bo *BaseOrchestrator) RequestYesNoFeedback(
 	}
 
 	// Wait for response
-	feedbackStore := virtualtools.GetHumanFeedbackStore()
-	if err := feedbackStore.CreateRequest(requestID, question); err != nil {
-		return false, fmt.Errorf("failed to create feedback request: %w", err)
-	}
-
-	bo.GetLogger().Infof("⏸️ Orchestrator paused, waiting for yes/no response...")
-
-	response, err := feedbackStore.WaitForResponse(requestID, 10*time.Minute)
-	if err != nil {
-		return false, fmt.Errorf("timeout waiting for feedback: %w", err)
+	response, answered, err := bo.awaitHumanResponse(requestID, question, opts)
+	if !answered && response == "" {
+		return false, err
 	}
 
 	bo.GetLogger().Infof("▶️ Orchestrator resumed with response: %s", response)
@@ -1074,11 +1122,11 @@ func (bo *BaseOrchestrator) RequestYesNoFeedback(
 	// Parse response: "Approve" means Yes, anything else means No
 	if strings.TrimSpace(response) == "Approve" {
 		bo.GetLogger().Infof("✅ User selected Yes (Approve)")
-		return true, nil
+		return true, err
 	}
 
 	bo.GetLogger().Infof("❌ User selected No (Reject)")
-	return false, nil
+	return false, err
 }
 
 // RequestThreeChoiceFeedback requests three-choice feedback from user
@@ -1093,6 +1141,7 @@ func (bo *BaseOrchestrator) RequestThreeChoiceFeedback(
 	context string,
 	sessionID string,
 	workflowID string,
+	opts ...FeedbackOption,
 ) (string, error) {
 	bo.GetLogger().Infof("🤔 Requesting three-choice feedback: %s", question)
 
@@ -1136,16 +1185,9 @@ func (bo *BaseOrchestrator) RequestThreeChoiceFeedback(
 	}
 
 	// Wait for response
-	feedbackStore := virtualtools.GetHumanFeedbackStore()
-	if err := feedbackStore.CreateRequest(requestID, question); err != nil {
-		return "", fmt.Errorf("failed to create feedback request: %w", err)
-	}
-
-	bo.GetLogger().Infof("⏸️ Orchestrator paused, waiting for three-choice response...")
-
-	response, err := feedbackStore.WaitForResponse(requestID, 10*time.Minute)
-	if err != nil {
-		return "", fmt.Errorf("timeout waiting for feedback: %w", err)
+	response, answered, err := bo.awaitHumanResponse(requestID, question, opts)
+	if !answered && response == "" {
+		return "", err
 	}
 
 	bo.GetLogger().Infof("▶️ Orchestrator resumed with response: %s", response)
@@ -1154,12 +1196,12 @@ func (bo *BaseOrchestrator) RequestThreeChoiceFeedback(
 	response = strings.TrimSpace(response)
 	if response == "option1" || response == "option2" || response == "option3" {
 		bo.GetLogger().Infof("✅ User selected: %s", response)
-		return response, nil
+		return response, err
 	}
 
 	// Default to option1 if response is unclear
 	bo.GetLogger().Warnf("⚠️ Unexpected response format: %s, defaulting to option1", response)
-	return "option1", nil
+	return "option1", err
 }
 
 // WriteWorkspaceFile writes content to a file in the workspace using MCP tools
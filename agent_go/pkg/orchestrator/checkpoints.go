@@ -0,0 +1,199 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultCheckpointMaxBytes caps the workspace content captured by one named checkpoint
+const DefaultCheckpointMaxBytes int64 = 64 << 20
+
+// ErrCheckpointNotFound is returned when restoring a checkpoint that was never created
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// NamedCheckpoint is a user-created snapshot of the orchestrator state and its workspace files
+type NamedCheckpoint struct {
+	Name          string    `json:"name"`
+	CreatedAt     time.Time `json:"created_at"`
+	Objective     string    `json:"objective"`
+	WorkspacePath string    `json:"workspace_path"`
+	FileCount     int       `json:"file_count"`
+	Bytes         int64     `json:"bytes"`
+
+	// Workspace files keyed by slash-separated path relative to the workspace
+	files map[string][]byte
+}
+
+// SetWorkspaceRoot sets the directory the workspace path is relative to on disk.
+// Named checkpoints snapshot and restore the files under it.
+func (bo *BaseOrchestrator) SetWorkspaceRoot(workspaceRoot string) {
+	bo.checkpointMu.Lock()
+	defer bo.checkpointMu.Unlock()
+	bo.workspaceRoot = workspaceRoot
+}
+
+// SetCheckpointMaxBytes caps the workspace content a named checkpoint may capture (<= 0 restores the default)
+func (bo *BaseOrchestrator) SetCheckpointMaxBytes(maxBytes int64) {
+	bo.checkpointMu.Lock()
+	defer bo.checkpointMu.Unlock()
+	bo.checkpointMaxBytes = maxBytes
+}
+
+// CreateNamedCheckpoint snapshots the orchestrator state and workspace files under name,
+// replacing an earlier checkpoint with the same name
+func (bo *BaseOrchestrator) CreateNamedCheckpoint(name string) (*NamedCheckpoint, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("checkpoint name is required")
+	}
+
+	bo.checkpointMu.Lock()
+	defer bo.checkpointMu.Unlock()
+
+	checkpoint := &NamedCheckpoint{
+		Name:          name,
+		CreatedAt:     time.Now(),
+		Objective:     bo.objective,
+		WorkspacePath: bo.workspacePath,
+		files:         map[string][]byte{},
+	}
+
+	if dir := bo.checkpointWorkspaceDir(bo.workspacePath); dir != "" {
+		maxBytes := bo.checkpointMaxBytes
+		if maxBytes <= 0 {
+			maxBytes = DefaultCheckpointMaxBytes
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == dir {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			checkpoint.Bytes += int64(len(content))
+			if checkpoint.Bytes > maxBytes {
+				return fmt.Errorf("workspace exceeds the checkpoint limit of %d bytes", maxBytes)
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			checkpoint.files[filepath.ToSlash(rel)] = content
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot workspace for checkpoint %q: %w", name, err)
+		}
+	}
+	checkpoint.FileCount = len(checkpoint.files)
+
+	if bo.checkpoints == nil {
+		bo.checkpoints = make(map[string]*NamedCheckpoint)
+	}
+	bo.checkpoints[name] = checkpoint
+	bo.GetLogger().Infof("📌 Created checkpoint %q (%d files, %d bytes)", name, checkpoint.FileCount, checkpoint.Bytes)
+	return checkpoint, nil
+}
+
+// RestoreCheckpoint returns the orchestrator state and workspace files to the named checkpoint.
+// Files created after the checkpoint are removed; files it captured are rewritten.
+func (bo *BaseOrchestrator) RestoreCheckpoint(name string) (*NamedCheckpoint, error) {
+	bo.checkpointMu.Lock()
+	defer bo.checkpointMu.Unlock()
+
+	checkpoint, exists := bo.checkpoints[strings.TrimSpace(name)]
+	if !exists {
+		return nil, fmt.Errorf("%w: %q", ErrCheckpointNotFound, name)
+	}
+
+	if dir := bo.checkpointWorkspaceDir(checkpoint.WorkspacePath); dir != "" {
+		if err := restoreWorkspaceFiles(dir, checkpoint.files); err != nil {
+			return nil, fmt.Errorf("failed to restore workspace for checkpoint %q: %w", name, err)
+		}
+	}
+
+	bo.objective = checkpoint.Objective
+	bo.workspacePath = checkpoint.WorkspacePath
+	bo.GetLogger().Infof("⏪ Restored checkpoint %q (%d files)", checkpoint.Name, checkpoint.FileCount)
+	return checkpoint, nil
+}
+
+// ListCheckpoints returns the named checkpoints, oldest first
+func (bo *BaseOrchestrator) ListCheckpoints() []*NamedCheckpoint {
+	bo.checkpointMu.Lock()
+	defer bo.checkpointMu.Unlock()
+
+	checkpoints := make([]*NamedCheckpoint, 0, len(bo.checkpoints))
+	for _, checkpoint := range bo.checkpoints {
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].CreatedAt.Before(checkpoints[j].CreatedAt)
+	})
+	return checkpoints
+}
+
+// checkpointWorkspaceDir returns the on-disk workspace directory, or "" when checkpoints
+// only cover orchestrator state. Must be called with checkpointMu held.
+func (bo *BaseOrchestrator) checkpointWorkspaceDir(workspacePath string) string {
+	if bo.workspaceRoot == "" || workspacePath == "" {
+		return ""
+	}
+	return filepath.Join(bo.workspaceRoot, filepath.Clean("/"+workspacePath))
+}
+
+// restoreWorkspaceFiles makes dir contain exactly files
+func restoreWorkspaceFiles(dir string, files map[string][]byte) error {
+	var stale []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if _, kept := files[filepath.ToSlash(rel)]; !kept {
+			stale = append(stale, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
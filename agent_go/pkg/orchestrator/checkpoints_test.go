@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+func newCheckpointTestOrchestrator(t *testing.T) (*BaseOrchestrator, string) {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+	bo, err := NewBaseOrchestrator(testLogger, nil, OrchestratorTypeWorkflow, "openai", "gpt-4.1", "", 0.2, "workflow", nil, nil, nil, 5, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	root := t.TempDir()
+	bo.SetWorkspaceRoot(root)
+	bo.SetObjective("collect prices")
+	bo.SetWorkspacePath("Workflow/Prices")
+	return bo, filepath.Join(root, "Workflow", "Prices")
+}
+
+func writeWorkspaceFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRestoreCheckpointReturnsStateAndWorkspace(t *testing.T) {
+	bo, workspace := newCheckpointTestOrchestrator(t)
+	writeWorkspaceFile(t, filepath.Join(workspace, "todo_final.md"), "1. Collect prices")
+
+	checkpoint, err := bo.CreateNamedCheckpoint("before-execution")
+	if err != nil {
+		t.Fatalf("failed to create checkpoint: %v", err)
+	}
+	if checkpoint.FileCount != 1 {
+		t.Fatalf("expected one file in the checkpoint, got %d", checkpoint.FileCount)
+	}
+
+	// Advance the run: edit the plan, add results and switch objective
+	writeWorkspaceFile(t, filepath.Join(workspace, "todo_final.md"), "1. Collect prices\n2. Compare them")
+	writeWorkspaceFile(t, filepath.Join(workspace, "runs", "2026-10-16", "results.md"), "prices")
+	bo.SetObjective("compare prices")
+
+	if _, err := bo.RestoreCheckpoint("before-execution"); err != nil {
+		t.Fatalf("failed to restore checkpoint: %v", err)
+	}
+
+	if bo.GetObjective() != "collect prices" || bo.GetWorkspacePath() != "Workflow/Prices" {
+		t.Fatalf("expected the checkpointed state, got objective %q and workspace %q", bo.GetObjective(), bo.GetWorkspacePath())
+	}
+	content, err := os.ReadFile(filepath.Join(workspace, "todo_final.md"))
+	if err != nil || string(content) != "1. Collect prices" {
+		t.Fatalf("expected the checkpointed plan, got %q (%v)", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "runs", "2026-10-16", "results.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected files created after the checkpoint to be removed, got %v", err)
+	}
+}
+
+func TestRestoreUnknownCheckpoint(t *testing.T) {
+	bo, _ := newCheckpointTestOrchestrator(t)
+	if _, err := bo.RestoreCheckpoint("missing"); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Fatalf("expected ErrCheckpointNotFound, got %v", err)
+	}
+}
+
+func TestCheckpointRespectsMaxBytes(t *testing.T) {
+	bo, workspace := newCheckpointTestOrchestrator(t)
+	bo.SetCheckpointMaxBytes(4)
+	writeWorkspaceFile(t, filepath.Join(workspace, "todo_final.md"), "too large")
+
+	if _, err := bo.CreateNamedCheckpoint("big"); err == nil {
+		t.Fatal("expected the checkpoint to be rejected over the size limit")
+	}
+	if len(bo.ListCheckpoints()) != 0 {
+		t.Fatal("expected no checkpoint stored after a failed snapshot")
+	}
+}
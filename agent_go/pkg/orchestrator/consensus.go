@@ -0,0 +1,264 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"mcp-agent/agent_go/internal/llm"
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// Consensus resolution methods reported in ConsensusResolvedEvent
+const (
+	ConsensusMethodAgreement   = "agreement"    // enough models gave the same answer
+	ConsensusMethodJudge       = "judge"        // the judge model picked among disagreeing outputs
+	ConsensusMethodNoConsensus = "no_consensus" // models disagreed and no judge resolved it
+)
+
+// ConsensusModel is one model queried for a consensus step
+type ConsensusModel struct {
+	Provider string `json:"provider"`
+	ModelID  string `json:"model_id"`
+}
+
+func (m ConsensusModel) String() string {
+	return m.Provider + "/" + m.ModelID
+}
+
+// ConsensusConfig designates critical steps whose decision is made by several models instead of one
+type ConsensusConfig struct {
+	Models []ConsensusModel `json:"models"`          // Queried with the same prompt; at least 2
+	Steps  []string         `json:"steps"`           // Step titles (case-insensitive) using consensus; "*" designates every step
+	Judge  *ConsensusModel  `json:"judge,omitempty"` // Picks the result when the models disagree
+	// Models that must give the same answer; 0 requires a strict majority
+	MinAgreement int `json:"min_agreement,omitempty"`
+}
+
+// Validate checks the config names at least two complete models and a reachable agreement threshold
+func (c ConsensusConfig) Validate() error {
+	if len(c.Models) < 2 {
+		return fmt.Errorf("consensus requires at least 2 models, got %d", len(c.Models))
+	}
+	models := c.Models
+	if c.Judge != nil {
+		models = append(append([]ConsensusModel(nil), models...), *c.Judge)
+	}
+	for _, model := range models {
+		if model.Provider == "" || model.ModelID == "" {
+			return fmt.Errorf("consensus model %q requires a provider and a model_id", model.String())
+		}
+	}
+	if c.MinAgreement < 0 || c.MinAgreement > len(c.Models) {
+		return fmt.Errorf("consensus min_agreement must be between 0 and %d, got %d", len(c.Models), c.MinAgreement)
+	}
+	return nil
+}
+
+// requiredVotes is the number of matching answers that settle the consensus
+func (c ConsensusConfig) requiredVotes() int {
+	if c.MinAgreement > 0 {
+		return c.MinAgreement
+	}
+	return len(c.Models)/2 + 1
+}
+
+// ConsensusQuerier sends a prompt to one model and returns its text answer
+type ConsensusQuerier func(ctx context.Context, model ConsensusModel, prompt string) (string, error)
+
+// ConsensusResult is the output selected for a consensus step
+type ConsensusResult struct {
+	Output   string         // Selected model output; empty when Method is ConsensusMethodNoConsensus
+	Answer   string         // Comparable answer extracted from Output
+	Model    ConsensusModel // Model whose output was selected
+	Method   string         // ConsensusMethodAgreement, ConsensusMethodJudge or ConsensusMethodNoConsensus
+	Votes    int            // Models that gave Answer
+	Resolved bool
+}
+
+// consensusState holds the orchestrator's consensus configuration
+type consensusState struct {
+	mu      sync.Mutex
+	config  *ConsensusConfig // nil disables consensus
+	querier ConsensusQuerier // nil queries models through the LLM providers
+}
+
+// SetConsensus designates the steps whose decision is made by several models (nil disables consensus)
+func (bo *BaseOrchestrator) SetConsensus(config *ConsensusConfig) error {
+	if config != nil {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+	}
+	bo.consensus.mu.Lock()
+	defer bo.consensus.mu.Unlock()
+	bo.consensus.config = config
+	return nil
+}
+
+// GetConsensus returns the consensus configuration, nil when consensus is disabled
+func (bo *BaseOrchestrator) GetConsensus() *ConsensusConfig {
+	bo.consensus.mu.Lock()
+	defer bo.consensus.mu.Unlock()
+	return bo.consensus.config
+}
+
+// SetConsensusQuerier replaces how consensus models are queried
+func (bo *BaseOrchestrator) SetConsensusQuerier(querier ConsensusQuerier) {
+	bo.consensus.mu.Lock()
+	defer bo.consensus.mu.Unlock()
+	bo.consensus.querier = querier
+}
+
+// ConsensusEnabled reports whether the step with this title is designated for consensus
+func (bo *BaseOrchestrator) ConsensusEnabled(stepTitle string) bool {
+	config := bo.GetConsensus()
+	if config == nil {
+		return false
+	}
+	for _, step := range config.Steps {
+		if step == "*" || strings.EqualFold(strings.TrimSpace(step), strings.TrimSpace(stepTitle)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunConsensus sends prompt to every consensus model and selects an output: the answer (extracted
+// with answerOf, whose errors disqualify an output) given by at least the required number of models
+// wins; otherwise the judge model picks among the outputs. Each model's output is emitted as a
+// ConsensusModelOutputEvent and the resolution as a ConsensusResolvedEvent.
+func (bo *BaseOrchestrator) RunConsensus(ctx context.Context, step, prompt string, answerOf func(output string) (string, error)) (ConsensusResult, error) {
+	bo.consensus.mu.Lock()
+	config, querier := bo.consensus.config, bo.consensus.querier
+	bo.consensus.mu.Unlock()
+	if config == nil {
+		return ConsensusResult{}, fmt.Errorf("consensus is not configured")
+	}
+	if querier == nil {
+		querier = bo.queryConsensusModel
+	}
+
+	type modelOutput struct {
+		output, answer string
+		err            error
+	}
+	outputs := make([]modelOutput, len(config.Models))
+	var wg sync.WaitGroup
+	for i, model := range config.Models {
+		wg.Add(1)
+		go func(i int, model ConsensusModel) {
+			defer wg.Done()
+			output, err := querier(ctx, model, prompt)
+			answer := ""
+			if err == nil {
+				answer, err = answerOf(output)
+			}
+			outputs[i] = modelOutput{output: output, answer: answer, err: err}
+		}(i, model)
+	}
+	wg.Wait()
+
+	votes := make(map[string]int)
+	var candidates []int // indexes of the models with a usable answer
+	for i, out := range outputs {
+		event := events.NewConsensusModelOutputEvent(step, config.Models[i].String(), out.output, out.answer)
+		if out.err != nil {
+			event.Error = out.err.Error()
+			bo.GetLogger().Warnf("⚖️ Consensus model %s failed for step %q: %v", config.Models[i], step, out.err)
+		} else {
+			votes[out.answer]++
+			candidates = append(candidates, i)
+		}
+		bo.emitEvent(ctx, events.ConsensusModelOutput, event)
+	}
+	if len(candidates) == 0 {
+		return ConsensusResult{}, fmt.Errorf("no consensus model answered step %q", step)
+	}
+
+	result := ConsensusResult{Method: ConsensusMethodNoConsensus}
+	for _, i := range candidates {
+		answer := outputs[i].answer
+		if votes[answer] >= config.requiredVotes() && votes[answer] > result.Votes {
+			result = ConsensusResult{Output: outputs[i].output, Answer: answer, Model: config.Models[i], Method: ConsensusMethodAgreement, Votes: votes[answer], Resolved: true}
+		}
+	}
+
+	if !result.Resolved && config.Judge != nil {
+		texts := make([]string, len(candidates))
+		for n, i := range candidates {
+			texts[n] = outputs[i].output
+		}
+		choice, err := bo.judgeConsensus(ctx, querier, *config.Judge, prompt, texts)
+		if err != nil {
+			bo.GetLogger().Warnf("⚖️ Consensus judge %s failed for step %q: %v", config.Judge, step, err)
+		} else {
+			i := candidates[choice]
+			result = ConsensusResult{Output: outputs[i].output, Answer: outputs[i].answer, Model: config.Models[i], Method: ConsensusMethodJudge, Votes: votes[outputs[i].answer], Resolved: true}
+		}
+	}
+
+	resolved := events.NewConsensusResolvedEvent(step, result.Method, len(config.Models), result.Votes)
+	if result.Resolved {
+		resolved.SelectedModel = result.Model.String()
+		resolved.Answer = result.Answer
+	}
+	bo.emitEvent(ctx, events.ConsensusResolved, resolved)
+	bo.GetLogger().Infof("⚖️ Consensus for step %q: %s (answer %q, %d/%d votes)", step, result.Method, result.Answer, result.Votes, len(config.Models))
+	return result, nil
+}
+
+var judgeChoicePattern = regexp.MustCompile(`\d+`)
+
+// judgeConsensus asks the judge model which candidate output answers the prompt best; returns its index
+func (bo *BaseOrchestrator) judgeConsensus(ctx context.Context, querier ConsensusQuerier, judge ConsensusModel, prompt string, candidates []string) (int, error) {
+	var judgePrompt strings.Builder
+	judgePrompt.WriteString("Several models answered the same task and disagree. Pick the answer that is best supported and most accurate.\n\n")
+	judgePrompt.WriteString("## Task\n")
+	judgePrompt.WriteString(prompt)
+	for i, candidate := range candidates {
+		fmt.Fprintf(&judgePrompt, "\n\n## Answer %d\n%s", i+1, candidate)
+	}
+	fmt.Fprintf(&judgePrompt, "\n\nReply with only the number (1-%d) of the best answer.", len(candidates))
+
+	reply, err := querier(ctx, judge, judgePrompt.String())
+	if err != nil {
+		return 0, err
+	}
+	choice, err := strconv.Atoi(judgeChoicePattern.FindString(reply))
+	if err != nil || choice < 1 || choice > len(candidates) {
+		return 0, fmt.Errorf("judge reply %q does not name an answer between 1 and %d", reply, len(candidates))
+	}
+	return choice - 1, nil
+}
+
+// queryConsensusModel sends prompt to a model through its LLM provider
+func (bo *BaseOrchestrator) queryConsensusModel(ctx context.Context, model ConsensusModel, prompt string) (string, error) {
+	provider, err := llm.ValidateProvider(model.Provider)
+	if err != nil {
+		return "", err
+	}
+	consensusLLM, err := llm.InitializeLLM(llm.Config{
+		Provider:    provider,
+		ModelID:     model.ModelID,
+		Temperature: bo.GetTemperature(),
+		Logger:      bo.GetLogger(),
+		Context:     ctx,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize %s: %w", model, err)
+	}
+
+	response, err := consensusLLM.GenerateContent(ctx, []llmtypes.MessageContent{llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, prompt)})
+	if err != nil {
+		return "", err
+	}
+	if response == nil || len(response.Choices) == 0 {
+		return "", fmt.Errorf("%s returned no choices", model)
+	}
+	return response.Choices[0].Content, nil
+}
@@ -0,0 +1,214 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+// consensusListener collects consensus events
+type consensusListener struct {
+	mu       sync.Mutex
+	outputs  []*events.ConsensusModelOutputEvent
+	resolved []*events.ConsensusResolvedEvent
+}
+
+func (l *consensusListener) HandleEvent(ctx context.Context, event *events.AgentEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch data := event.Data.(type) {
+	case *events.ConsensusModelOutputEvent:
+		l.outputs = append(l.outputs, data)
+	case *events.ConsensusResolvedEvent:
+		l.resolved = append(l.resolved, data)
+	}
+	return nil
+}
+
+func (l *consensusListener) Name() string {
+	return "consensus-listener"
+}
+
+var (
+	modelA = ConsensusModel{Provider: "openai", ModelID: "gpt-4.1"}
+	modelB = ConsensusModel{Provider: "anthropic", ModelID: "claude-sonnet-4"}
+	judge  = ConsensusModel{Provider: "openai", ModelID: "o3"}
+)
+
+// mockModels answers each model with a fixed reply and records the judge's prompt
+type mockModels struct {
+	mu          sync.Mutex
+	replies     map[string]string
+	errors      map[string]error
+	judgePrompt string
+}
+
+func (m *mockModels) query(ctx context.Context, model ConsensusModel, prompt string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if model == judge {
+		m.judgePrompt = prompt
+	}
+	if err := m.errors[model.String()]; err != nil {
+		return "", err
+	}
+	return m.replies[model.String()], nil
+}
+
+// firstWord is the comparable answer of the mock replies, without punctuation
+func firstWord(output string) (string, error) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", errors.New("empty output")
+	}
+	return strings.ToLower(strings.Trim(fields[0], ",.:;-")), nil
+}
+
+func newConsensusTestOrchestrator(t *testing.T, config *ConsensusConfig, models *mockModels) (*BaseOrchestrator, *consensusListener) {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+	listener := &consensusListener{}
+	bo, err := NewBaseOrchestrator(testLogger, listener, OrchestratorTypeWorkflow, "openai", "gpt-4.1", "", 0.2, "workflow", nil, nil, nil, 5, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := bo.SetConsensus(config); err != nil {
+		t.Fatalf("SetConsensus: %v", err)
+	}
+	bo.SetConsensusQuerier(models.query)
+	return bo, listener
+}
+
+func TestConsensusModelsAgree(t *testing.T) {
+	models := &mockModels{replies: map[string]string{
+		modelA.String(): "YES - the bucket exists",
+		modelB.String(): "yes, created in us-east-1",
+	}}
+	bo, listener := newConsensusTestOrchestrator(t, &ConsensusConfig{Models: []ConsensusModel{modelA, modelB}, Steps: []string{"Create bucket"}, Judge: &judge}, models)
+
+	result, err := bo.RunConsensus(context.Background(), "Create bucket", "Was the bucket created?", firstWord)
+	if err != nil {
+		t.Fatalf("RunConsensus: %v", err)
+	}
+	if !result.Resolved || result.Method != ConsensusMethodAgreement || result.Answer != "yes" || result.Votes != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if models.judgePrompt != "" {
+		t.Error("the judge was consulted although the models agreed")
+	}
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	if len(listener.outputs) != 2 {
+		t.Fatalf("expected an output event per model, got %d", len(listener.outputs))
+	}
+	if len(listener.resolved) != 1 || listener.resolved[0].Method != ConsensusMethodAgreement || listener.resolved[0].Votes != 2 || listener.resolved[0].Models != 2 {
+		t.Fatalf("unexpected resolution events: %+v", listener.resolved)
+	}
+}
+
+func TestConsensusModelsDisagreeJudgePicks(t *testing.T) {
+	models := &mockModels{replies: map[string]string{
+		modelA.String(): "yes - the bucket exists",
+		modelB.String(): "no - the bucket policy was never applied",
+		judge.String():  "Answer 2 is better supported.",
+	}}
+	bo, listener := newConsensusTestOrchestrator(t, &ConsensusConfig{Models: []ConsensusModel{modelA, modelB}, Steps: []string{"*"}, Judge: &judge}, models)
+
+	result, err := bo.RunConsensus(context.Background(), "Create bucket", "Was the bucket created?", firstWord)
+	if err != nil {
+		t.Fatalf("RunConsensus: %v", err)
+	}
+	if !result.Resolved || result.Method != ConsensusMethodJudge || result.Answer != "no" || result.Model != modelB {
+		t.Fatalf("expected the judge to pick the second model's answer, got %+v", result)
+	}
+	if !strings.Contains(models.judgePrompt, "Was the bucket created?") || !strings.Contains(models.judgePrompt, "bucket policy was never applied") {
+		t.Errorf("the judge did not get the task and candidate answers: %q", models.judgePrompt)
+	}
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	if len(listener.resolved) != 1 || listener.resolved[0].Method != ConsensusMethodJudge || listener.resolved[0].SelectedModel != modelB.String() {
+		t.Fatalf("unexpected resolution events: %+v", listener.resolved)
+	}
+}
+
+func TestConsensusModelsDisagreeWithoutJudge(t *testing.T) {
+	models := &mockModels{replies: map[string]string{
+		modelA.String(): "yes",
+		modelB.String(): "no",
+	}}
+	bo, listener := newConsensusTestOrchestrator(t, &ConsensusConfig{Models: []ConsensusModel{modelA, modelB}, Steps: []string{"Create bucket"}}, models)
+
+	result, err := bo.RunConsensus(context.Background(), "Create bucket", "Was the bucket created?", firstWord)
+	if err != nil {
+		t.Fatalf("RunConsensus: %v", err)
+	}
+	if result.Resolved || result.Method != ConsensusMethodNoConsensus || result.Output != "" {
+		t.Fatalf("expected no consensus, got %+v", result)
+	}
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	if len(listener.resolved) != 1 || listener.resolved[0].Method != ConsensusMethodNoConsensus {
+		t.Fatalf("unexpected resolution events: %+v", listener.resolved)
+	}
+}
+
+func TestConsensusFailedModelDoesNotVote(t *testing.T) {
+	modelC := ConsensusModel{Provider: "bedrock", ModelID: "claude-3-5-haiku"}
+	models := &mockModels{
+		replies: map[string]string{modelA.String(): "yes", modelB.String(): "yes"},
+		errors:  map[string]error{modelC.String(): errors.New("throttled")},
+	}
+	// Two of three votes are a strict majority even though one model failed
+	bo, listener := newConsensusTestOrchestrator(t, &ConsensusConfig{Models: []ConsensusModel{modelA, modelB, modelC}, Steps: []string{"*"}}, models)
+
+	result, err := bo.RunConsensus(context.Background(), "Create bucket", "Was the bucket created?", firstWord)
+	if err != nil {
+		t.Fatalf("RunConsensus: %v", err)
+	}
+	if result.Method != ConsensusMethodAgreement || result.Votes != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	failed := 0
+	for _, output := range listener.outputs {
+		if output.Error != "" {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("expected the failed model reported in its output event, got %+v", listener.outputs)
+	}
+}
+
+func TestConsensusConfig(t *testing.T) {
+	bo, _ := newConsensusTestOrchestrator(t, &ConsensusConfig{Models: []ConsensusModel{modelA, modelB}, Steps: []string{" create bucket "}}, &mockModels{})
+	if !bo.ConsensusEnabled("Create Bucket") || bo.ConsensusEnabled("Delete bucket") {
+		t.Error("steps should be designated by case-insensitive title")
+	}
+
+	invalid := []*ConsensusConfig{
+		{Models: []ConsensusModel{modelA}},
+		{Models: []ConsensusModel{modelA, {Provider: "openai"}}},
+		{Models: []ConsensusModel{modelA, modelB}, MinAgreement: 3},
+		{Models: []ConsensusModel{modelA, modelB}, Judge: &ConsensusModel{ModelID: "o3"}},
+	}
+	for _, config := range invalid {
+		if err := bo.SetConsensus(config); err == nil {
+			t.Errorf("expected %+v to be rejected", config)
+		}
+	}
+
+	if err := bo.SetConsensus(nil); err != nil || bo.ConsensusEnabled("Create bucket") {
+		t.Errorf("SetConsensus(nil) should disable consensus (err %v)", err)
+	}
+}
@@ -17,6 +17,9 @@ type ContextAwareEventBridge struct {
 	currentAgentName string
 	mu               sync.RWMutex
 	logger           utils.ExtendedLogger
+
+	// Sees every event before it is forwarded, e.g. to meter the run's cost; nil disables
+	observer func(ctx context.Context, event *events.AgentEvent)
 }
 
 // Name implements the EventBridge interface
@@ -45,6 +48,21 @@ func (c *ContextAwareEventBridge) SetOrchestratorContext(phase string, step, ite
 	c.logger.Infof("🎯 Set orchestrator context: %s (step %d, iteration %d)", phase, step+1, iteration+1)
 }
 
+// WithOrchestratorContext returns a bridge to the same destination whose events always carry the
+// given context. Each agent gets its own, so agents running concurrently (parallel steps) don't
+// tag their events with whichever agent set the shared context last.
+func (c *ContextAwareEventBridge) WithOrchestratorContext(phase string, step, iteration int, agentName string) *ContextAwareEventBridge {
+	return &ContextAwareEventBridge{
+		underlyingBridge: c.underlyingBridge,
+		currentPhase:     phase,
+		currentStep:      step,
+		currentIteration: iteration,
+		currentAgentName: agentName,
+		logger:           c.logger,
+		observer:         c.observer,
+	}
+}
+
 // ClearOrchestratorContext clears the orchestrator context
 func (c *ContextAwareEventBridge) ClearOrchestratorContext() {
 	c.mu.Lock()
@@ -61,6 +79,9 @@ func (c *ContextAwareEventBridge) ClearOrchestratorContext() {
 // HandleEvent implements AgentEventListener interface
 func (c *ContextAwareEventBridge) HandleEvent(ctx context.Context, event *events.AgentEvent) error {
 	c.logger.Debugf("🔍 ContextAwareBridge: Received event %s", event.Type)
+	if c.observer != nil {
+		c.observer(ctx, event)
+	}
 
 	// Copy orchestrator context while holding read lock
 	c.mu.RLock()
@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+	"mcp-agent/agent_go/pkg/mcpagent"
+)
+
+// stepTagListener records the orchestrator step each tool event was tagged with, by tool name
+type stepTagListener struct {
+	mu    sync.Mutex
+	steps map[string][]any
+}
+
+func (l *stepTagListener) HandleEvent(ctx context.Context, event *events.AgentEvent) error {
+	if data, ok := event.Data.(*events.ToolCallStartEvent); ok {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.steps[data.ToolName] = append(l.steps[data.ToolName], data.Metadata["orchestrator_step"])
+	}
+	return nil
+}
+
+func (l *stepTagListener) Name() string {
+	return "step-tag-listener"
+}
+
+func TestConcurrentStepAgentsTagTheirOwnStep(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+	listener := &stepTagListener{steps: make(map[string][]any)}
+	bo, err := NewBaseOrchestrator(testLogger, listener, OrchestratorTypeWorkflow, "openai", "gpt-4.1", "", 0.2, "workflow", nil, nil, nil, 5, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+
+	// Both steps connect their agents before either runs, as parallel steps do
+	tools := []string{"step_0_tool", "step_1_tool"}
+	var connected, done sync.WaitGroup
+	connected.Add(len(tools))
+	for step, tool := range tools {
+		done.Add(1)
+		go func(step int, tool string) {
+			defer done.Done()
+			agent := &mcpagent.Agent{Logger: testLogger, TraceID: "trace-1"}
+			if err := bo.connectAgentBridge(nil, agent, "execution", step, 0, tool); err != nil {
+				t.Errorf("connect step %d: %v", step, err)
+			}
+			connected.Done()
+			connected.Wait()
+			for i := 0; i < 3; i++ {
+				agent.EmitTypedEvent(context.Background(), events.NewToolCallStartEvent(i+1, tool, events.ToolParams{}, "server", ""))
+			}
+		}(step, tool)
+	}
+	done.Wait()
+
+	for step, tool := range tools {
+		tagged := listener.steps[tool]
+		if len(tagged) != 3 {
+			t.Fatalf("expected 3 events from step %d, got %d", step, len(tagged))
+		}
+		for _, got := range tagged {
+			if got != step {
+				t.Fatalf("expected step %d's events tagged with its own step, got %v", step, tagged)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/pricing"
+)
+
+// ErrCostBudgetExceeded is returned when a run is stopped because its estimated cost crossed the budget
+var ErrCostBudgetExceeded = errors.New("orchestrator cost budget exceeded")
+
+// CostBudgetExceededContext is the OrchestratorErrorEvent context of a run stopped by its cost budget
+const CostBudgetExceededContext = "cost_budget_exceeded"
+
+// ModelPrice is a model's price in USD per 1K prompt and completion tokens
+type ModelPrice = pricing.ModelPrice
+
+// CostEstimator estimates the USD cost of one LLM generation
+type CostEstimator func(modelID string, usage events.UsageMetrics) float64
+
+// EstimateCost prices usage with the process-wide price table (see pricing.SetDefault); unknown models cost 0
+func EstimateCost(modelID string, usage events.UsageMetrics) float64 {
+	return pricing.EstimateCost(modelID, usage)
+}
+
+// costBudget tracks the estimated cost of a run against its dollar budget
+type costBudget struct {
+	mu        sync.Mutex
+	maxUSD    float64 // 0 disables the budget
+	estimator CostEstimator
+	spentUSD  float64
+	exceeded  bool
+	cancel    context.CancelFunc // stops the run; set by WithCostBudget
+}
+
+// SetCostBudget stops the run once its estimated cumulative LLM cost crosses maxUSD (0 disables).
+// Costs are estimated per LLM generation with the orchestrator's model (see SetCostEstimator).
+func (bo *BaseOrchestrator) SetCostBudget(maxUSD float64) {
+	bo.costBudget.mu.Lock()
+	defer bo.costBudget.mu.Unlock()
+	bo.costBudget.maxUSD = maxUSD
+}
+
+// SetCostEstimator replaces the price table (pricing.EstimateCost) used by the cost budget and cost summary
+func (bo *BaseOrchestrator) SetCostEstimator(estimator CostEstimator) {
+	bo.costBudget.mu.Lock()
+	defer bo.costBudget.mu.Unlock()
+	bo.costBudget.estimator = estimator
+}
+
+// EstimatedCost returns the estimated USD cost of the run so far
+func (bo *BaseOrchestrator) EstimatedCost() float64 {
+	bo.costBudget.mu.Lock()
+	defer bo.costBudget.mu.Unlock()
+	return bo.costBudget.spentUSD
+}
+
+// CostBudgetExceeded reports whether the run was stopped by its cost budget
+func (bo *BaseOrchestrator) CostBudgetExceeded() bool {
+	bo.costBudget.mu.Lock()
+	defer bo.costBudget.mu.Unlock()
+	return bo.costBudget.exceeded
+}
+
+// WithCostBudget returns a context that is cancelled when the run's estimated cost crosses the budget.
+// Orchestrators run their flow under it and report ErrCostBudgetExceeded (see CostBudgetError).
+func (bo *BaseOrchestrator) WithCostBudget(ctx context.Context) (context.Context, context.CancelFunc) {
+	runCtx, cancel := context.WithCancel(ctx)
+	bo.costBudget.mu.Lock()
+	bo.costBudget.cancel = cancel
+	bo.costBudget.spentUSD = 0
+	bo.costBudget.exceeded = false
+	bo.costBudget.mu.Unlock()
+	return runCtx, cancel
+}
+
+// CostBudgetError returns ErrCostBudgetExceeded in place of the run's result error when the budget stopped the run
+func (bo *BaseOrchestrator) CostBudgetError(err error) error {
+	bo.costBudget.mu.Lock()
+	defer bo.costBudget.mu.Unlock()
+	if !bo.costBudget.exceeded {
+		return err
+	}
+	return fmt.Errorf("%w: estimated cost $%.4f, budget $%.4f", ErrCostBudgetExceeded, bo.costBudget.spentUSD, bo.costBudget.maxUSD)
+}
+
+// recordCost charges LLM generations to the cost budget and stops the run once it is crossed
+func (bo *BaseOrchestrator) recordCost(ctx context.Context, event *events.AgentEvent) {
+	end, ok := event.Data.(*events.LLMGenerationEndEvent)
+	if !ok || end.TurnSummary {
+		return
+	}
+
+	budget := &bo.costBudget
+	budget.mu.Lock()
+	if budget.maxUSD <= 0 || budget.exceeded {
+		budget.mu.Unlock()
+		return
+	}
+	estimator := budget.estimator
+	if estimator == nil {
+		estimator = EstimateCost
+	}
+	budget.spentUSD += estimator(bo.costModelID(), end.UsageMetrics)
+	if budget.spentUSD <= budget.maxUSD {
+		budget.mu.Unlock()
+		return
+	}
+	budget.exceeded = true
+	spent, maxUSD, cancel := budget.spentUSD, budget.maxUSD, budget.cancel
+	budget.mu.Unlock()
+
+	bo.GetLogger().Warnf("💸 Estimated cost $%.4f crossed the budget of $%.4f, stopping the run", spent, maxUSD)
+	bo.emitEvent(ctx, events.OrchestratorError, &events.OrchestratorErrorEvent{
+		BaseEventData: events.BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Context:          CostBudgetExceededContext,
+		Error:            fmt.Sprintf("estimated cost $%.4f exceeded the budget of $%.4f", spent, maxUSD),
+		Duration:         time.Since(bo.startTime),
+		OrchestratorType: string(bo.orchestratorType),
+	})
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// costModelID returns the model the run's generations are priced at
+func (bo *BaseOrchestrator) costModelID() string {
+	if bo.llmConfig != nil && bo.llmConfig.ModelID != "" {
+		return bo.llmConfig.ModelID
+	}
+	return bo.model
+}
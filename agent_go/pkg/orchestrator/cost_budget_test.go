@@ -0,0 +1,155 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+	"mcp-agent/agent_go/pkg/mcpagent"
+)
+
+// orchestratorErrorListener collects orchestrator error events
+type orchestratorErrorListener struct {
+	mu     sync.Mutex
+	errors []*events.OrchestratorErrorEvent
+}
+
+func (l *orchestratorErrorListener) HandleEvent(ctx context.Context, event *events.AgentEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if data, ok := event.Data.(*events.OrchestratorErrorEvent); ok {
+		l.errors = append(l.errors, data)
+	}
+	return nil
+}
+
+func (l *orchestratorErrorListener) Name() string {
+	return "orchestrator-error-listener"
+}
+
+func newCostBudgetTestOrchestrator(t *testing.T) (*BaseOrchestrator, *orchestratorErrorListener) {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+	listener := &orchestratorErrorListener{}
+	bo, err := NewBaseOrchestrator(testLogger, listener, OrchestratorTypeWorkflow, "openai", "gpt-4.1", "", 0.2, "workflow", nil, nil, nil, 5, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	return bo, listener
+}
+
+// runGenerations emits LLM generations until the run context is cancelled or max is reached
+func runGenerations(ctx context.Context, bo *BaseOrchestrator, max int) int {
+	generations := 0
+	for generations < max && ctx.Err() == nil {
+		bo.GetContextAwareBridge().HandleEvent(ctx, events.NewAgentEvent(&events.LLMGenerationEndEvent{
+			// gpt-4.1 costs $0.002 + $0.008 = $0.01 per generation
+			UsageMetrics: events.UsageMetrics{PromptTokens: 1000, CompletionTokens: 1000, TotalTokens: 2000},
+		}))
+		generations++
+	}
+	return generations
+}
+
+func TestCostBudgetStopsRunAfterThreshold(t *testing.T) {
+	bo, listener := newCostBudgetTestOrchestrator(t)
+	bo.SetCostBudget(0.025)
+
+	ctx, cancel := bo.WithCostBudget(context.Background())
+	defer cancel()
+
+	if generations := runGenerations(ctx, bo, 10); generations != 3 {
+		t.Fatalf("expected the run to stop on the generation crossing the budget (3), ran %d", generations)
+	}
+	if !bo.CostBudgetExceeded() {
+		t.Fatal("expected the budget to be reported as exceeded")
+	}
+	if cost := bo.EstimatedCost(); cost < 0.0299 || cost > 0.0301 {
+		t.Fatalf("expected an estimated cost of $0.03, got $%.4f", cost)
+	}
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	if len(listener.errors) != 1 || listener.errors[0].Context != CostBudgetExceededContext {
+		t.Fatalf("expected one cost budget orchestrator error, got %+v", listener.errors)
+	}
+
+	if err := bo.CostBudgetError(context.Canceled); !errors.Is(err, ErrCostBudgetExceeded) {
+		t.Fatalf("expected the run error to be ErrCostBudgetExceeded, got %v", err)
+	}
+}
+
+func TestCostBudgetDisabledByDefault(t *testing.T) {
+	bo, listener := newCostBudgetTestOrchestrator(t)
+
+	ctx, cancel := bo.WithCostBudget(context.Background())
+	defer cancel()
+
+	if generations := runGenerations(ctx, bo, 10); generations != 10 {
+		t.Fatalf("expected an unbudgeted run to continue, stopped after %d", generations)
+	}
+	if bo.CostBudgetExceeded() || len(listener.errors) != 0 {
+		t.Fatal("expected no cost budget stop without a budget")
+	}
+	if err := bo.CostBudgetError(nil); err != nil {
+		t.Fatalf("expected the run error to pass through, got %v", err)
+	}
+}
+
+func TestEstimateCostUsesLongestModelMatch(t *testing.T) {
+	usage := events.UsageMetrics{PromptTokens: 1000, CompletionTokens: 1000}
+	if cost := EstimateCost("openai/gpt-4o-mini-2024-07-18", usage); cost < 0.00074 || cost > 0.00076 {
+		t.Fatalf("expected gpt-4o-mini prices, got $%.5f", cost)
+	}
+	if cost := EstimateCost("unknown-model", usage); cost != 0 {
+		t.Fatalf("expected unknown models to cost nothing, got $%.5f", cost)
+	}
+}
+
+// answerLLM answers every call with fixed usage
+type answerLLM struct {
+	calls int
+}
+
+func (l *answerLLM) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	l.calls++
+	input, output, total := 1000, 1000, 2000
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{
+		Content:        "done",
+		GenerationInfo: &llmtypes.GenerationInfo{InputTokens: &input, OutputTokens: &output, TotalTokens: &total},
+	}}}, nil
+}
+
+func TestCostBudgetChargesEachLLMCallOnce(t *testing.T) {
+	bo, _ := newCostBudgetTestOrchestrator(t)
+	bo.SetCostBudget(1)
+
+	testLogger := logger.CreateDiscardLogger("error")
+	llm := &answerLLM{}
+	agent := &mcpagent.Agent{
+		LLM:       llm,
+		ModelID:   "gpt-4.1",
+		TraceID:   "trace-1",
+		Logger:    testLogger,
+		AgentMode: mcpagent.SimpleAgent,
+		MaxTurns:  2,
+	}
+	agent.AddEventListener(bo.GetContextAwareBridge())
+
+	ctx, cancel := bo.WithCostBudget(context.Background())
+	defer cancel()
+	if _, err := agent.Ask(ctx, "finish the step"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if llm.calls != 1 {
+		t.Fatalf("expected one LLM call, got %d", llm.calls)
+	}
+	// gpt-4.1 costs $0.002 + $0.008 = $0.01 for the call's 1000 prompt and 1000 completion tokens
+	if cost := bo.EstimatedCost(); cost < 0.0099 || cost > 0.0101 {
+		t.Fatalf("expected the call charged once ($0.01), got $%.4f", cost)
+	}
+}
@@ -0,0 +1,27 @@
+package orchestrator
+
+import (
+	"context"
+
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// recordUsage adds the cost summary of each agent conversation to the run's usage
+func (bo *BaseOrchestrator) recordUsage(event *events.AgentEvent) {
+	summary, ok := event.Data.(*events.ConversationCostSummaryEvent)
+	if !ok || summary.Scope != "conversation" {
+		return
+	}
+	bo.usage.Merge(summary)
+}
+
+// emitCostSummary emits the token and cost roll-up of the run, priced like the cost budget
+func (bo *BaseOrchestrator) emitCostSummary(ctx context.Context) {
+	bo.costBudget.mu.Lock()
+	estimator := bo.costBudget.estimator
+	bo.costBudget.mu.Unlock()
+	if estimator == nil {
+		estimator = EstimateCost
+	}
+	bo.emitEvent(ctx, events.ConversationCostSummary, bo.usage.Event("orchestrator", estimator))
+}
@@ -0,0 +1,101 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	virtualtools "mcp-agent/agent_go/cmd/server/virtual-tools"
+)
+
+// ErrFeedbackTimeout is returned (wrapped) by the RequestXFeedback methods when nobody answers
+// before the request times out, so an abandoned session no longer blocks the orchestrator
+var ErrFeedbackTimeout = virtualtools.ErrFeedbackTimeout
+
+// DefaultHumanFeedbackTimeout is how long a feedback request waits unless HUMAN_FEEDBACK_TIMEOUT
+// or SetHumanFeedbackTimeout says otherwise
+const DefaultHumanFeedbackTimeout = 10 * time.Minute
+
+// FeedbackOption configures a single human feedback request
+type FeedbackOption func(*feedbackOptions)
+
+type feedbackOptions struct {
+	timeout       time.Duration
+	timeoutAnswer *string
+}
+
+// WithFeedbackTimeout overrides the orchestrator's feedback timeout for one request
+func WithFeedbackTimeout(timeout time.Duration) FeedbackOption {
+	return func(o *feedbackOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithTimeoutAnswer makes a timed out request return answer as if the user had submitted it
+// ("Approve", "option2", feedback text, ...) alongside ErrFeedbackTimeout. Without it a timed out
+// request returns zero values.
+func WithTimeoutAnswer(answer string) FeedbackOption {
+	return func(o *feedbackOptions) {
+		o.timeoutAnswer = &answer
+	}
+}
+
+// SetHumanFeedbackTimeout sets how long feedback requests wait for a response; <= 0 restores the default
+func (bo *BaseOrchestrator) SetHumanFeedbackTimeout(timeout time.Duration) {
+	bo.feedbackTimeout = timeout
+}
+
+// HumanFeedbackTimeout returns how long feedback requests wait for a response
+func (bo *BaseOrchestrator) HumanFeedbackTimeout() time.Duration {
+	if bo.feedbackTimeout > 0 {
+		return bo.feedbackTimeout
+	}
+	return humanFeedbackTimeoutFromEnv()
+}
+
+// humanFeedbackTimeoutFromEnv reads HUMAN_FEEDBACK_TIMEOUT as a duration ("90s", "30m")
+func humanFeedbackTimeoutFromEnv() time.Duration {
+	if value := os.Getenv("HUMAN_FEEDBACK_TIMEOUT"); value != "" {
+		if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+	return DefaultHumanFeedbackTimeout
+}
+
+// awaitHumanResponse registers the feedback request and blocks until it is answered or times out.
+// On timeout it returns the request's timeout answer (if any) and answered=false with an error
+// wrapping ErrFeedbackTimeout.
+func (bo *BaseOrchestrator) awaitHumanResponse(requestID string, question string, opts []FeedbackOption) (response string, answered bool, err error) {
+	options := feedbackOptions{timeout: bo.HumanFeedbackTimeout()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.timeout <= 0 {
+		options.timeout = bo.HumanFeedbackTimeout()
+	}
+
+	feedbackStore := virtualtools.GetHumanFeedbackStore()
+
+	// Create feedback request (this registers it in the store)
+	if err := feedbackStore.CreateRequest(requestID, question); err != nil {
+		return "", false, fmt.Errorf("failed to create feedback request: %w", err)
+	}
+
+	bo.GetLogger().Infof("⏸️ Orchestrator paused, waiting for human response (timeout: %s)...", options.timeout)
+
+	// BLOCKING CALL - waits here until response or timeout
+	response, err = feedbackStore.WaitForResponse(requestID, options.timeout)
+	if errors.Is(err, ErrFeedbackTimeout) {
+		bo.GetLogger().Warnf("⌛ No human response to %s within %s", requestID, options.timeout)
+		if options.timeoutAnswer != nil {
+			return *options.timeoutAnswer, false, err
+		}
+		return "", false, err
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed waiting for human feedback: %w", err)
+	}
+	return response, true, nil
+}
@@ -0,0 +1,99 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	virtualtools "mcp-agent/agent_go/cmd/server/virtual-tools"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+func newFeedbackTestOrchestrator(t *testing.T) *BaseOrchestrator {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+	bo, err := NewBaseOrchestrator(testLogger, &consensusListener{}, OrchestratorTypeWorkflow, "openai", "gpt-4.1", "", 0.2, "workflow", nil, nil, nil, 5, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	bo.SetHumanFeedbackTimeout(20 * time.Millisecond)
+	return bo
+}
+
+func assertNotPending(t *testing.T, requestID string) {
+	t.Helper()
+	for _, pending := range virtualtools.GetHumanFeedbackStore().Pending() {
+		if pending.UniqueID == requestID {
+			t.Errorf("expected timed out request %s to be removed from the pending requests", requestID)
+		}
+	}
+}
+
+func TestFeedbackRequestsTimeOutWithoutResponse(t *testing.T) {
+	bo := newFeedbackTestOrchestrator(t)
+	ctx := context.Background()
+
+	start := time.Now()
+	approved, feedback, err := bo.RequestHumanFeedback(ctx, "timeout_feedback", "Approve?", "", "s1", "w1")
+	if !errors.Is(err, ErrFeedbackTimeout) || approved || feedback != "" {
+		t.Fatalf("expected a timeout with zero values, got approved=%v feedback=%q err=%v", approved, feedback, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the request to time out after 20ms, took %s", elapsed)
+	}
+	assertNotPending(t, "timeout_feedback")
+
+	yes, err := bo.RequestYesNoFeedback(ctx, "timeout_yes_no", "Continue?", "", "", "", "s1", "w1")
+	if !errors.Is(err, ErrFeedbackTimeout) || yes {
+		t.Fatalf("expected a timeout with No, got %v (%v)", yes, err)
+	}
+
+	choice, err := bo.RequestThreeChoiceFeedback(ctx, "timeout_choice", "Which?", "A", "B", "C", "", "s1", "w1")
+	if !errors.Is(err, ErrFeedbackTimeout) || choice != "" {
+		t.Fatalf("expected a timeout without a choice, got %q (%v)", choice, err)
+	}
+}
+
+func TestFeedbackTimeoutAnswerAndPerRequestTimeout(t *testing.T) {
+	bo := newFeedbackTestOrchestrator(t)
+	bo.SetHumanFeedbackTimeout(time.Hour)
+	ctx := context.Background()
+
+	// The per-request timeout overrides the orchestrator's and the timeout answer is returned as the choice
+	choice, err := bo.RequestThreeChoiceFeedback(ctx, "timeout_default_choice", "Which?", "A", "B", "C", "", "s1", "w1",
+		WithFeedbackTimeout(20*time.Millisecond), WithTimeoutAnswer("option2"))
+	if !errors.Is(err, ErrFeedbackTimeout) || choice != "option2" {
+		t.Fatalf("expected the timeout answer option2, got %q (%v)", choice, err)
+	}
+
+	approved, _, err := bo.RequestHumanFeedback(ctx, "timeout_default_approve", "Approve?", "", "s1", "w1",
+		WithFeedbackTimeout(20*time.Millisecond), WithTimeoutAnswer("Approve"))
+	if !errors.Is(err, ErrFeedbackTimeout) || !approved {
+		t.Fatalf("expected the timeout answer to approve, got %v (%v)", approved, err)
+	}
+
+	// A response before the timeout is returned without an error
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		virtualtools.GetHumanFeedbackStore().Submit("answered_yes_no", "Approve")
+	}()
+	yes, err := bo.RequestYesNoFeedback(ctx, "answered_yes_no", "Continue?", "", "", "", "s1", "w1", WithFeedbackTimeout(5*time.Second))
+	if err != nil || !yes {
+		t.Fatalf("expected the submitted Yes, got %v (%v)", yes, err)
+	}
+}
+
+func TestHumanFeedbackTimeoutFromEnv(t *testing.T) {
+	bo := newFeedbackTestOrchestrator(t)
+	bo.SetHumanFeedbackTimeout(0)
+
+	t.Setenv("HUMAN_FEEDBACK_TIMEOUT", "90s")
+	if timeout := bo.HumanFeedbackTimeout(); timeout != 90*time.Second {
+		t.Errorf("expected the env timeout of 90s, got %s", timeout)
+	}
+	t.Setenv("HUMAN_FEEDBACK_TIMEOUT", "soon")
+	if timeout := bo.HumanFeedbackTimeout(); timeout != DefaultHumanFeedbackTimeout {
+		t.Errorf("expected an invalid env timeout to use the default, got %s", timeout)
+	}
+}
@@ -103,6 +103,9 @@ func CreateLLMInstance(
 		logger.Infof("🔧 Added default cross-provider fallback models for %s LLM: %v", llmType, crossProviderFallbacks)
 	}
 
+	// Drop fallbacks this request must never use
+	fallbackModels = config.FallbackExclusions().Filter("", fallbackModels)
+
 	// Create LLM configuration
 	llmConfig := llm.Config{
 		Provider:       llm.Provider(config.Provider),
@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Shutdowner is implemented by components that own MCP connections (orchestrators, agent wrappers)
+// and can terminate them on demand, e.g. when their session is stopped
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// closer is an agent owning MCP connections
+type closer interface {
+	Close() error
+}
+
+// owned holds what an orchestrator has to shut down
+type owned struct {
+	mu       sync.Mutex
+	agents   []closer
+	children []Shutdowner
+}
+
+// trackAgent records an initialized agent so Shutdown closes its MCP connections
+func (bo *BaseOrchestrator) trackAgent(agent closer) {
+	bo.owned.mu.Lock()
+	defer bo.owned.mu.Unlock()
+	bo.owned.agents = append(bo.owned.agents, agent)
+}
+
+// AddChild makes Shutdown also shut down a sub-orchestrator created by this orchestrator
+func (bo *BaseOrchestrator) AddChild(child Shutdowner) {
+	if child == nil {
+		return
+	}
+	bo.owned.mu.Lock()
+	defer bo.owned.mu.Unlock()
+	bo.owned.children = append(bo.owned.children, child)
+}
+
+// Shutdown closes the MCP connections of every agent created so far, including those of
+// sub-orchestrators. The orchestrator stays usable: agents created afterwards are tracked again.
+func (bo *BaseOrchestrator) Shutdown(ctx context.Context) error {
+	bo.owned.mu.Lock()
+	agents, children := bo.owned.agents, bo.owned.children
+	bo.owned.agents, bo.owned.children = nil, nil
+	bo.owned.mu.Unlock()
+
+	var errs []error
+	for _, child := range children {
+		if err := child.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, agent := range agents {
+		if err := agent.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(agents) > 0 || len(children) > 0 {
+		bo.GetLogger().Infof("🔌 Shut down %d agents and %d sub-orchestrators", len(agents), len(children))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,51 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"mcp-agent/agent_go/internal/llm"
+	"mcp-agent/agent_go/pkg/logger"
+	"mcp-agent/agent_go/pkg/orchestrator/agents"
+)
+
+func newStructuredOutputTestOrchestrator(t *testing.T, llmConfig *LLMConfig) *BaseOrchestrator {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+	bo, err := NewBaseOrchestrator(testLogger, &consensusListener{}, OrchestratorTypeWorkflow, "openai", "gpt-4.1", "", 0.2, "workflow", nil, nil, llmConfig, 5, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	return bo
+}
+
+func TestStructuredOutputLLMDefaultsToExecutionLLM(t *testing.T) {
+	bo := newStructuredOutputTestOrchestrator(t, nil)
+
+	config := bo.CreateStandardAgentConfig("plan-breakdown", 5, agents.OutputFormatStructured)
+	provider, modelID, temperature, overridden := config.StructuredOutputModel()
+	if overridden || provider != "openai" || modelID != "gpt-4.1" || temperature != 0.2 {
+		t.Fatalf("expected the execution LLM without an override, got %s/%s at %v (overridden=%v)", provider, modelID, temperature, overridden)
+	}
+}
+
+func TestStructuredOutputLLMOverridesAgentModel(t *testing.T) {
+	bo := newStructuredOutputTestOrchestrator(t, &LLMConfig{Provider: "bedrock", ModelID: "us.anthropic.claude-sonnet-4-20250514-v1:0"})
+	zero := 0.0
+	bo.SetStructuredOutputLLM(&agents.StructuredOutputLLMConfig{ModelID: "us.anthropic.claude-3-5-haiku-20241022-v1:0", Temperature: &zero})
+
+	config := bo.CreateStandardAgentConfig("plan-breakdown", 5, agents.OutputFormatStructured)
+	if config.Model != "us.anthropic.claude-sonnet-4-20250514-v1:0" {
+		t.Fatalf("expected the execution model to stay unchanged, got %s", config.Model)
+	}
+	provider, modelID, temperature, overridden := config.StructuredOutputModel()
+	if !overridden || provider != "bedrock" || modelID != "us.anthropic.claude-3-5-haiku-20241022-v1:0" || temperature != 0 {
+		t.Fatalf("expected the overridden structured output model, got %s/%s at %v (overridden=%v)", provider, modelID, temperature, overridden)
+	}
+
+	// Another provider without a model uses that provider's default model
+	bo.SetStructuredOutputLLM(&agents.StructuredOutputLLMConfig{Provider: "openai"})
+	provider, modelID, temperature, _ = bo.CreateStandardAgentConfig("plan-breakdown", 5, agents.OutputFormatStructured).StructuredOutputModel()
+	if provider != "openai" || modelID != llm.GetDefaultModel(llm.ProviderOpenAI) || temperature != 0.2 {
+		t.Fatalf("expected the openai default model at the execution temperature, got %s/%s at %v", provider, modelID, temperature)
+	}
+}
@@ -0,0 +1,136 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/orchestrator/agents"
+)
+
+// dependencyAnalysisGroup is the selected options group controlling dependency analysis capture.
+// Capture is on by default; select OptionID "disabled" in this group to turn it off.
+const dependencyAnalysisGroup = "dependency_analysis"
+
+// DependencyEdge is a dependency between two steps: From must complete before To
+type DependencyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PlanDependencyAnalysis is the dependency graph produced by the plan breakdown agent
+type PlanDependencyAnalysis struct {
+	Steps []ParallelStep   `json:"steps"`
+	Edges []DependencyEdge `json:"edges"`
+	// Steps the breakdown agent marked independent
+	IndependentStepIDs []string `json:"independent_step_ids"`
+	// Dependencies on step IDs that are not part of the breakdown
+	UnknownDependencies []DependencyEdge `json:"unknown_dependencies,omitempty"`
+	// Why each step was judged independent or not, keyed by step ID
+	Reasoning map[string]string `json:"reasoning,omitempty"`
+}
+
+// PlanDependencyAnalysisEvent carries the plan breakdown's dependency graph so the
+// parallelization decisions can be verified before the selected steps run
+type PlanDependencyAnalysisEvent struct {
+	events.BaseEventData
+	PlanDependencyAnalysis
+	ExecutionMode string `json:"execution_mode"`
+}
+
+// GetEventType returns the event type for PlanDependencyAnalysisEvent
+func (e *PlanDependencyAnalysisEvent) GetEventType() events.EventType {
+	return events.PlanDependencyAnalysis
+}
+
+// buildDependencyAnalysis builds the dependency graph from the breakdown agent's steps
+func buildDependencyAnalysis(breakdown *agents.BreakdownResponse) *PlanDependencyAnalysis {
+	analysis := &PlanDependencyAnalysis{
+		Steps:              []ParallelStep{},
+		Edges:              []DependencyEdge{},
+		IndependentStepIDs: []string{},
+		Reasoning:          map[string]string{},
+	}
+
+	known := make(map[string]bool, len(breakdown.Steps))
+	for _, step := range breakdown.Steps {
+		known[step.ID] = true
+	}
+
+	for _, step := range breakdown.Steps {
+		analysis.Steps = append(analysis.Steps, ParallelStep{
+			ID:            step.ID,
+			Description:   step.Description,
+			Dependencies:  step.Dependencies,
+			IsIndependent: step.IsIndependent,
+		})
+		if step.IsIndependent {
+			analysis.IndependentStepIDs = append(analysis.IndependentStepIDs, step.ID)
+		}
+		if step.Reasoning != "" {
+			analysis.Reasoning[step.ID] = step.Reasoning
+		}
+		for _, dependency := range step.Dependencies {
+			edge := DependencyEdge{From: dependency, To: step.ID}
+			if known[dependency] {
+				analysis.Edges = append(analysis.Edges, edge)
+			} else {
+				analysis.UnknownDependencies = append(analysis.UnknownDependencies, edge)
+			}
+		}
+	}
+	return analysis
+}
+
+// IsDependencyAnalysisCaptureEnabled reports whether the breakdown's dependency graph is captured
+func (po *PlannerOrchestrator) IsDependencyAnalysisCaptureEnabled() bool {
+	if po.selectedOptions != nil {
+		for _, selection := range po.selectedOptions.Selections {
+			if selection.Group == dependencyAnalysisGroup {
+				return selection.OptionID != "disabled"
+			}
+		}
+	}
+	return true
+}
+
+// GetDependencyAnalysis returns the dependency graph captured from the last plan breakdown,
+// or nil when none was captured
+func (po *PlannerOrchestrator) GetDependencyAnalysis() *PlanDependencyAnalysis {
+	po.dependencyAnalysisMu.RLock()
+	defer po.dependencyAnalysisMu.RUnlock()
+	return po.dependencyAnalysis
+}
+
+// captureDependencyAnalysis stores the breakdown's dependency graph and emits it as an event
+func (po *PlannerOrchestrator) captureDependencyAnalysis(ctx context.Context, breakdown *agents.BreakdownResponse) {
+	if !po.IsDependencyAnalysisCaptureEnabled() {
+		return
+	}
+
+	analysis := buildDependencyAnalysis(breakdown)
+	po.dependencyAnalysisMu.Lock()
+	po.dependencyAnalysis = analysis
+	po.dependencyAnalysisMu.Unlock()
+
+	bridge := po.GetContextAwareBridge()
+	if bridge == nil {
+		return
+	}
+	unifiedEvent := &events.AgentEvent{
+		Type:      events.PlanDependencyAnalysis,
+		Timestamp: time.Now(),
+		Data: &PlanDependencyAnalysisEvent{
+			BaseEventData: events.BaseEventData{
+				Timestamp: time.Now(),
+			},
+			PlanDependencyAnalysis: *analysis,
+			ExecutionMode:          po.GetExecutionMode().String(),
+		},
+	}
+	if err := bridge.HandleEvent(ctx, unifiedEvent); err != nil {
+		po.GetLogger().Warnf("⚠️ Failed to emit plan dependency analysis event: %v", err)
+	} else {
+		po.GetLogger().Infof("✅ Emitted plan dependency analysis event: %d steps, %d edges", len(analysis.Steps), len(analysis.Edges))
+	}
+}
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+	"mcp-agent/agent_go/pkg/orchestrator/agents"
+)
+
+// dependencyAnalysisListener collects plan dependency analysis events
+type dependencyAnalysisListener struct {
+	mu     sync.Mutex
+	events []*PlanDependencyAnalysisEvent
+}
+
+func (l *dependencyAnalysisListener) HandleEvent(ctx context.Context, event *events.AgentEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if data, ok := event.Data.(*PlanDependencyAnalysisEvent); ok {
+		l.events = append(l.events, data)
+	}
+	return nil
+}
+
+func (l *dependencyAnalysisListener) Name() string {
+	return "dependency-analysis-listener"
+}
+
+func newDependencyAnalysisPlanner(t *testing.T, selectedOptions *PlannerSelectedOptions) (*PlannerOrchestrator, *dependencyAnalysisListener) {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+	listener := &dependencyAnalysisListener{}
+	po, err := NewPlannerOrchestrator("openai", "gpt-4.1", "", 0.2, "simple", t.TempDir(), testLogger, listener, nil, nil, selectedOptions, nil, nil, nil, nil, 5)
+	if err != nil {
+		t.Fatalf("failed to create planner orchestrator: %v", err)
+	}
+	return po, listener
+}
+
+var breakdownWithDependencies = &agents.BreakdownResponse{Steps: []agents.BreakdownStep{
+	{ID: "fetch", Description: "Fetch the data", Dependencies: []string{}, IsIndependent: true, Reasoning: "needs nothing"},
+	{ID: "clean", Description: "Clean the data", Dependencies: []string{"fetch"}, IsIndependent: false, Reasoning: "needs fetched data"},
+	{ID: "report", Description: "Write the report", Dependencies: []string{"fetch", "clean", "approve"}, IsIndependent: false},
+}}
+
+func TestDependencyAnalysisEmittedWithBreakdownEdges(t *testing.T) {
+	po, listener := newDependencyAnalysisPlanner(t, nil)
+
+	po.captureDependencyAnalysis(context.Background(), breakdownWithDependencies)
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	if len(listener.events) != 1 {
+		t.Fatalf("expected one dependency analysis event, got %d", len(listener.events))
+	}
+	event := listener.events[0]
+	wantEdges := []DependencyEdge{{From: "fetch", To: "clean"}, {From: "fetch", To: "report"}, {From: "clean", To: "report"}}
+	if !reflect.DeepEqual(event.Edges, wantEdges) {
+		t.Fatalf("expected edges %v, got %v", wantEdges, event.Edges)
+	}
+	if !reflect.DeepEqual(event.UnknownDependencies, []DependencyEdge{{From: "approve", To: "report"}}) {
+		t.Fatalf("expected the dependency on a missing step to be reported, got %v", event.UnknownDependencies)
+	}
+	if !reflect.DeepEqual(event.IndependentStepIDs, []string{"fetch"}) || event.Reasoning["clean"] != "needs fetched data" || len(event.Steps) != 3 {
+		t.Fatalf("unexpected analysis: %+v", event.PlanDependencyAnalysis)
+	}
+
+	if captured := po.GetDependencyAnalysis(); captured == nil || !reflect.DeepEqual(captured.Edges, wantEdges) {
+		t.Fatalf("expected the analysis to be retrievable from the orchestrator, got %+v", captured)
+	}
+}
+
+func TestDependencyAnalysisCaptureCanBeDisabled(t *testing.T) {
+	po, listener := newDependencyAnalysisPlanner(t, &PlannerSelectedOptions{Selections: []PlannerSelectedOption{
+		{OptionID: string(ParallelExecution), Group: "execution_strategy"},
+		{OptionID: "disabled", Group: "dependency_analysis"},
+	}})
+
+	po.captureDependencyAnalysis(context.Background(), breakdownWithDependencies)
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	if len(listener.events) != 0 || po.GetDependencyAnalysis() != nil {
+		t.Fatalf("expected no capture when disabled, got %d events", len(listener.events))
+	}
+}
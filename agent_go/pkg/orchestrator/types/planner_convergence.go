@@ -0,0 +1,71 @@
+package types
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+
+	"mcp-agent/agent_go/pkg/orchestrator/agents"
+)
+
+// DefaultPlannerMaxIterations caps the sequential planner loop when SetMaxIterations is not used
+const DefaultPlannerMaxIterations = 10
+
+// Status of the sequential planner run reported in the orchestrator end event
+const (
+	PlannerStatusCompleted     = "completed"      // The planning agent found no incomplete steps
+	PlannerStatusStalled       = "stalled"        // Consecutive iterations made no progress
+	PlannerStatusMaxIterations = "max_iterations" // The iteration cap was reached
+)
+
+// Planner phases, passed to the phase agent factory
+const (
+	plannerPhasePlanning     = "planning"
+	plannerPhaseExecution    = "execution"
+	plannerPhaseValidation   = "validation"
+	plannerPhaseOrganization = "organization"
+	plannerPhaseReport       = "report"
+)
+
+// phaseAgentFactory creates the agent for a planner phase in place of the standard agents
+type phaseAgentFactory func(ctx context.Context, phase string, stepIndex, iteration int) (agents.OrchestratorAgent, error)
+
+// SetMaxIterations caps the planning/execution/validation iterations of the sequential flow;
+// maxIterations <= 0 uses DefaultPlannerMaxIterations
+func (po *PlannerOrchestrator) SetMaxIterations(maxIterations int) {
+	po.maxIterations = maxIterations
+}
+
+// GetMaxIterations returns the iteration cap of the sequential flow
+func (po *PlannerOrchestrator) GetMaxIterations() int {
+	if po.maxIterations <= 0 {
+		return DefaultPlannerMaxIterations
+	}
+	return po.maxIterations
+}
+
+// SetStallDetection enables or disables stopping the sequential flow when two consecutive iterations
+// end with the same plan and validation result (enabled by default)
+func (po *PlannerOrchestrator) SetStallDetection(enabled bool) {
+	po.stallDetectionDisabled = !enabled
+}
+
+// plannerConvergence detects iterations that make no progress: the same pending plan and the same
+// validation outcome as the previous iteration
+type plannerConvergence struct {
+	previous [sha256.Size]byte
+	observed bool
+}
+
+// observe records an iteration's plan and validation result and reports whether it repeats the previous one
+func (c *plannerConvergence) observe(planningResult, validationResult string) bool {
+	state := sha256.Sum256([]byte(normalizeIterationState(planningResult) + "\x00" + normalizeIterationState(validationResult)))
+	stalled := c.observed && state == c.previous
+	c.previous, c.observed = state, true
+	return stalled
+}
+
+// normalizeIterationState ignores case and whitespace differences between iteration outputs
+func normalizeIterationState(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
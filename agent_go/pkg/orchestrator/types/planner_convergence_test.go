@@ -0,0 +1,134 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/events"
+	"mcp-agent/agent_go/pkg/logger"
+	"mcp-agent/agent_go/pkg/orchestrator/agents"
+)
+
+// scriptedPhaseAgent answers every Execute with respond(call number)
+type scriptedPhaseAgent struct {
+	phase   string
+	calls   *int
+	respond func(call int) string
+}
+
+func (a *scriptedPhaseAgent) Execute(ctx context.Context, templateVars map[string]string, conversationHistory []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error) {
+	*a.calls++
+	return a.respond(*a.calls), conversationHistory, nil
+}
+
+func (a *scriptedPhaseAgent) GetType() string                            { return a.phase }
+func (a *scriptedPhaseAgent) GetConfig() *agents.OrchestratorAgentConfig { return nil }
+func (a *scriptedPhaseAgent) Initialize(ctx context.Context) error       { return nil }
+func (a *scriptedPhaseAgent) Close() error                               { return nil }
+func (a *scriptedPhaseAgent) GetBaseAgent() *agents.BaseAgent            { return nil }
+
+// orchestratorEndListener collects orchestrator end events
+type orchestratorEndListener struct {
+	mu   sync.Mutex
+	ends []*events.OrchestratorEndEvent
+}
+
+func (l *orchestratorEndListener) HandleEvent(ctx context.Context, event *events.AgentEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if data, ok := event.Data.(*events.OrchestratorEndEvent); ok {
+		l.ends = append(l.ends, data)
+	}
+	return nil
+}
+
+func (l *orchestratorEndListener) Name() string {
+	return "orchestrator-end-listener"
+}
+
+// newConvergenceTestPlanner returns a planner whose validation agent never passes. planning
+// answers the planning agent per iteration; the plan always has incomplete steps.
+func newConvergenceTestPlanner(t *testing.T, planning func(iteration int) string) (*PlannerOrchestrator, map[string]*int, *orchestratorEndListener) {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+	listener := &orchestratorEndListener{}
+	po, err := NewPlannerOrchestrator("openai", "gpt-4.1", "", 0.2, "simple", t.TempDir(), testLogger, listener, nil, nil, nil, nil, nil, nil, nil, 5)
+	if err != nil {
+		t.Fatalf("failed to create planner orchestrator: %v", err)
+	}
+
+	calls := map[string]*int{}
+	responses := map[string]func(call int) string{
+		plannerPhasePlanning:     planning,
+		plannerPhaseExecution:    func(call int) string { return fmt.Sprintf("ran step, attempt %d", call) },
+		plannerPhaseValidation:   func(call int) string { return "FAILED: the deployment is still unhealthy" },
+		plannerPhaseOrganization: func(call int) string { return "organized" },
+		plannerPhaseReport:       func(call int) string { return "report" },
+	}
+	for phase := range responses {
+		calls[phase] = new(int)
+	}
+	po.phaseAgents = func(ctx context.Context, phase string, stepIndex, iteration int) (agents.OrchestratorAgent, error) {
+		return &scriptedPhaseAgent{phase: phase, calls: calls[phase], respond: responses[phase]}, nil
+	}
+	po.continueDecider = func(ctx context.Context, planningResult string) bool { return true }
+	return po, calls, listener
+}
+
+func TestPlannerStopsAtMaxIterations(t *testing.T) {
+	// Every iteration plans a different fix, so the run makes progress until the cap
+	po, calls, listener := newConvergenceTestPlanner(t, func(call int) string {
+		return fmt.Sprintf("Pending: fix deployment, approach %d", call)
+	})
+	po.SetMaxIterations(3)
+
+	if _, err := po.executeSequential(context.Background(), "fix the deployment"); err != nil {
+		t.Fatalf("executeSequential: %v", err)
+	}
+	if *calls[plannerPhaseValidation] != 3 {
+		t.Fatalf("expected 3 validations at the iteration cap, got %d", *calls[plannerPhaseValidation])
+	}
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	if len(listener.ends) != 1 || listener.ends[0].Status != PlannerStatusMaxIterations {
+		t.Fatalf("expected one orchestrator end event with status %q, got %+v", PlannerStatusMaxIterations, listener.ends)
+	}
+}
+
+func TestPlannerStopsWhenStalled(t *testing.T) {
+	// The same pending step and the same validation failure every iteration
+	po, calls, listener := newConvergenceTestPlanner(t, func(call int) string {
+		return "Pending:   Fix the deployment"
+	})
+	po.SetMaxIterations(10)
+
+	if _, err := po.executeSequential(context.Background(), "fix the deployment"); err != nil {
+		t.Fatalf("executeSequential: %v", err)
+	}
+	if *calls[plannerPhaseValidation] != 2 {
+		t.Fatalf("expected the run to stop after two iterations without progress, got %d validations", *calls[plannerPhaseValidation])
+	}
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	if len(listener.ends) != 1 || listener.ends[0].Status != PlannerStatusStalled {
+		t.Fatalf("expected one orchestrator end event with status %q, got %+v", PlannerStatusStalled, listener.ends)
+	}
+}
+
+func TestPlannerStallDetectionCanBeDisabled(t *testing.T) {
+	po, calls, _ := newConvergenceTestPlanner(t, func(call int) string {
+		return "Pending: fix the deployment"
+	})
+	po.SetMaxIterations(4)
+	po.SetStallDetection(false)
+
+	if _, err := po.executeSequential(context.Background(), "fix the deployment"); err != nil {
+		t.Fatalf("executeSequential: %v", err)
+	}
+	if *calls[plannerPhaseValidation] != 4 {
+		t.Fatalf("expected the run to continue to the cap, got %d validations", *calls[plannerPhaseValidation])
+	}
+}
@@ -133,6 +133,23 @@ type PlannerOrchestrator struct {
 
 	// Conversation history for context
 	conversationHistory []llmtypes.MessageContent
+
+	// Dependency graph captured from the last plan breakdown
+	dependencyAnalysis   *PlanDependencyAnalysis
+	dependencyAnalysisMu sync.RWMutex
+
+	// Structured final report (see ExecuteFlowStructured); nil uses the report agent
+	structuredReportGenerator StructuredReportGenerator
+	// Runs the planner flow; nil uses executeFlow
+	flowRunner func(ctx context.Context, objective string, conversationHistory []llmtypes.MessageContent) (string, error)
+
+	// Sequential loop limits (see SetMaxIterations, SetStallDetection)
+	maxIterations          int
+	stallDetectionDisabled bool
+
+	// Phase agents and continue decision in place of the standard agents and conditional LLM; nil uses those
+	phaseAgents     phaseAgentFactory
+	continueDecider func(ctx context.Context, planningResult string) bool
 }
 
 // NewPlannerOrchestrator creates a new planner orchestrator with full configuration
@@ -175,6 +192,7 @@ func NewPlannerOrchestrator(
 	if err != nil {
 		return nil, fmt.Errorf("failed to create base orchestrator: %w", err)
 	}
+	baseOrchestrator.SetWorkspaceRoot(workspaceRoot)
 
 	// Create planner orchestrator instance
 	po := &PlannerOrchestrator{
@@ -226,8 +244,10 @@ func (po *PlannerOrchestrator) executeSequential(ctx context.Context, objective
 	organizationResults := make([]string, 0)
 	reportResults := make([]string, 0)
 
-	// Main iterative loop - simplified stateless execution
-	maxIterations := 10 // Fixed max iterations for stateless execution
+	// Main iterative loop - simplified stateless execution, stopped at the iteration cap or on a stall
+	maxIterations := po.GetMaxIterations()
+	status := PlannerStatusMaxIterations
+	convergence := &plannerConvergence{}
 	for iteration := 0; iteration < maxIterations; iteration++ {
 
 		// ✅ PLANNING PHASE - Determine next step or workflow completion
@@ -301,6 +321,7 @@ func (po *PlannerOrchestrator) executeSequential(ctx context.Context, objective
 		// Check if we should continue - BREAK if planning says no
 		if !shouldContinue {
 			po.GetLogger().Infof("✅ Workflow completion confirmed by planning agent")
+			status = PlannerStatusCompleted
 			break
 		}
 
@@ -431,6 +452,13 @@ func (po *PlannerOrchestrator) executeSequential(ctx context.Context, objective
 
 		// Move to next step
 		currentStepIndex++
+
+		// Stop when this iteration left the plan and validation outcome unchanged
+		if !po.stallDetectionDisabled && convergence.observe(planningResult, stepValidationResult) {
+			po.GetLogger().Warnf("⚠️ Planner stalled: iteration %d made no progress over the previous one, stopping", iteration+1)
+			status = PlannerStatusStalled
+			break
+		}
 	}
 
 	// Prepare final result with iteration-by-iteration breakdown
@@ -495,12 +523,12 @@ func (po *PlannerOrchestrator) executeSequential(ctx context.Context, objective
 		finalResult += fmt.Sprintf("Raw Response: %s\n", lastPlanningResult)
 	}
 
-	po.GetLogger().Infof("🎉 Sequential Planner Orchestrator Flow completed successfully after %d iterations!", len(planningResults))
+	po.GetLogger().Infof("🎉 Sequential Planner Orchestrator Flow finished (%s) after %d iterations!", status, len(planningResults))
 
 	// Emit orchestrator completion events
 	executionMode := po.GetExecutionMode().String()
-	po.EmitOrchestratorEnd(ctx, objective, finalResult, "completed", "", executionMode)
-	po.EmitUnifiedCompletionEvent(ctx, "planner", "planner", objective, finalResult, "completed", len(planningResults))
+	po.EmitOrchestratorEnd(ctx, objective, finalResult, status, "", executionMode)
+	po.EmitUnifiedCompletionEvent(ctx, "planner", "planner", objective, finalResult, status, len(planningResults))
 
 	return finalResult, nil
 }
@@ -643,6 +671,9 @@ func (po *PlannerOrchestrator) analyzeDependenciesWithStructuredOutput(ctx conte
 		return nil, fmt.Errorf("plan breakdown structured execution failed: %w", err)
 	}
 
+	// Expose the dependency graph so the parallelization decisions can be verified
+	po.captureDependencyAnalysis(ctx, breakdownResponse)
+
 	// Convert structured response to ParallelStep format
 	var parallelSteps []ParallelStep
 	for _, step := range breakdownResponse.Steps {
@@ -904,6 +935,9 @@ func (po *PlannerOrchestrator) validateSingleStep(ctx context.Context, step Para
 
 // createDedicatedExecutionAgent creates a dedicated execution agent based on execution mode
 func (po *PlannerOrchestrator) createDedicatedExecutionAgent(ctx context.Context, stepIndex, iteration int) (agents.OrchestratorAgent, error) {
+	if po.phaseAgents != nil {
+		return po.phaseAgents(ctx, plannerPhaseExecution, stepIndex, iteration)
+	}
 	// Check execution mode to determine which agent to create
 	if po.IsParallelMode() {
 		// Use parallel execution agent for parallel mode
@@ -954,6 +988,9 @@ func (po *PlannerOrchestrator) createDedicatedExecutionAgent(ctx context.Context
 
 // createDedicatedValidationAgent creates a dedicated validation agent for parallel step validation
 func (po *PlannerOrchestrator) createDedicatedValidationAgent(ctx context.Context, stepIndex int) (agents.OrchestratorAgent, error) {
+	if po.phaseAgents != nil {
+		return po.phaseAgents(ctx, plannerPhaseValidation, stepIndex, 0)
+	}
 	agentName := fmt.Sprintf("validation-agent-step-%d", stepIndex+1)
 
 	// Use standardized agent creation and setup
@@ -980,6 +1017,9 @@ func (po *PlannerOrchestrator) createDedicatedValidationAgent(ctx context.Contex
 
 // createPlanningAgent creates a planning agent on-demand
 func (po *PlannerOrchestrator) createPlanningAgent(ctx context.Context, stepIndex, iteration int) (agents.OrchestratorAgent, error) {
+	if po.phaseAgents != nil {
+		return po.phaseAgents(ctx, plannerPhasePlanning, stepIndex, iteration)
+	}
 	// Use standardized agent creation and setup
 	agent, err := po.CreateAndSetupStandardAgent(
 		ctx,
@@ -1028,6 +1068,9 @@ func (po *PlannerOrchestrator) createPlanBreakdownAgent(ctx context.Context, ste
 
 // createOrganizerAgent creates an organizer agent on-demand
 func (po *PlannerOrchestrator) createOrganizerAgent(ctx context.Context, stepIndex, iteration int) (agents.OrchestratorAgent, error) {
+	if po.phaseAgents != nil {
+		return po.phaseAgents(ctx, plannerPhaseOrganization, stepIndex, iteration)
+	}
 	// Use standardized agent creation and setup
 	agent, err := po.CreateAndSetupStandardAgent(
 		ctx,
@@ -1052,6 +1095,9 @@ func (po *PlannerOrchestrator) createOrganizerAgent(ctx context.Context, stepInd
 
 // createReportAgent creates a report agent on-demand
 func (po *PlannerOrchestrator) createReportAgent(ctx context.Context, stepIndex, iteration int) (agents.OrchestratorAgent, error) {
+	if po.phaseAgents != nil {
+		return po.phaseAgents(ctx, plannerPhaseReport, stepIndex, iteration)
+	}
 	// Use standardized agent creation and setup
 	agent, err := po.CreateAndSetupStandardAgent(
 		ctx,
@@ -1174,6 +1220,10 @@ func (po *PlannerOrchestrator) createConditionalLLM() (*llm.ConditionalLLM, erro
 
 // extractShouldContinue uses the conditional LLM to determine if the plan is executable and will achieve the objective
 func (po *PlannerOrchestrator) extractShouldContinue(ctx context.Context, rawResponse string) bool {
+	if po.continueDecider != nil {
+		return po.continueDecider(ctx, rawResponse)
+	}
+
 	// Create conditional LLM on-demand
 	conditionalLLM, err := po.createConditionalLLM()
 	if err != nil {
@@ -1218,6 +1268,7 @@ func (po *PlannerOrchestrator) Execute(ctx context.Context, objective string, wo
 
 	// Validate options if provided
 	var selectedOptions *PlannerSelectedOptions
+	conversationHistory := []llmtypes.MessageContent{}
 	if options != nil {
 		// Validate selectedOptions if provided
 		if selectedOptsVal, exists := options["selectedOptions"]; exists {
@@ -1246,11 +1297,20 @@ func (po *PlannerOrchestrator) Execute(ctx context.Context, objective string, wo
 			}
 		}
 
+		// Conversation from earlier turns of the session, e.g. after switching from simple mode
+		if historyVal, exists := options["conversationHistory"]; exists && historyVal != nil {
+			history, ok := historyVal.([]llmtypes.MessageContent)
+			if !ok {
+				return "", fmt.Errorf("invalid conversationHistory: expected []llmtypes.MessageContent, got %T", historyVal)
+			}
+			conversationHistory = history
+		}
+
 		// Check for any other unexpected options
-		validOptionKeys := map[string]bool{"selectedOptions": true}
+		validOptionKeys := map[string]bool{"selectedOptions": true, "conversationHistory": true}
 		for key := range options {
 			if !validOptionKeys[key] {
-				return "", fmt.Errorf("unexpected option: %s, planner orchestrator only accepts: selectedOptions", key)
+				return "", fmt.Errorf("unexpected option: %s, planner orchestrator only accepts: selectedOptions, conversationHistory", key)
 			}
 		}
 	}
@@ -1267,8 +1327,16 @@ func (po *PlannerOrchestrator) Execute(ctx context.Context, objective string, wo
 	executionMode := po.GetExecutionMode()
 	po.GetLogger().Infof("🎯 Execution mode: %s", executionMode.String())
 
-	// Call executeFlow with empty conversation history and nil event bridge
-	return po.executeFlow(ctx, objective, []llmtypes.MessageContent{}, nil)
+	// Stop the run once its estimated cost crosses the budget (see SetCostBudget)
+	ctx, cancel := po.WithCostBudget(ctx)
+	defer cancel()
+
+	// Call executeFlow with the provided conversation history and nil event bridge
+	result, err := po.executeFlow(ctx, objective, conversationHistory, nil)
+	if err = po.CostBudgetError(err); err != nil {
+		return "", err
+	}
+	return result, nil
 }
 
 // executeFlow executes the orchestrator flow with conversation history and event bridge
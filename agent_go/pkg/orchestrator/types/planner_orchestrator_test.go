@@ -0,0 +1,24 @@
+package types
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+func TestPlannerExecuteValidatesConversationHistoryOption(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+	po, err := NewPlannerOrchestrator("openai", "gpt-4.1", "", 0.2, "simple", t.TempDir(), testLogger, nil, nil, nil, nil, nil, nil, nil, nil, 5)
+	if err != nil {
+		t.Fatalf("failed to create planner orchestrator: %v", err)
+	}
+
+	_, err = po.Execute(context.Background(), "objective", t.TempDir(), map[string]interface{}{
+		"conversationHistory": []string{"list my buckets"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid conversationHistory") {
+		t.Fatalf("expected invalid conversation history to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/mcpagent"
+	"mcp-agent/agent_go/pkg/orchestrator/agents"
+)
+
+// StructuredReportGenerator produces the planner's final report as JSON matching schema, from the
+// report template variables (Objective, ExecutionResults holding the flow's result, WorkspacePath)
+type StructuredReportGenerator func(ctx context.Context, templateVars map[string]string, conversationHistory []llmtypes.MessageContent, schema string) (json.RawMessage, error)
+
+// SetStructuredReportGenerator replaces the report agent used by ExecuteFlowStructured
+func (po *PlannerOrchestrator) SetStructuredReportGenerator(generator StructuredReportGenerator) {
+	po.structuredReportGenerator = generator
+}
+
+// ExecuteFlowStructured runs the planner flow like Execute, then has the report agent return the final
+// report as JSON matching schema instead of prose. The flow and report agent emit their usual events.
+// The returned JSON has been validated against the schema.
+func (po *PlannerOrchestrator) ExecuteFlowStructured(ctx context.Context, objective string, conversationHistory []llmtypes.MessageContent, schema string) (json.RawMessage, error) {
+	var decoded interface{}
+	if err := po.executeFlowStructured(ctx, objective, conversationHistory, schema, &decoded); err != nil {
+		return nil, err
+	}
+	report, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode structured report: %w", err)
+	}
+	return report, nil
+}
+
+// ExecuteFlowStructuredAs is ExecuteFlowStructured decoding the report into T, like mcpagent.AskStructured
+func ExecuteFlowStructuredAs[T any](po *PlannerOrchestrator, ctx context.Context, objective string, conversationHistory []llmtypes.MessageContent, schema string) (T, error) {
+	var report T
+	if err := po.executeFlowStructured(ctx, objective, conversationHistory, schema, &report); err != nil {
+		var zero T
+		return zero, err
+	}
+	return report, nil
+}
+
+// executeFlowStructured runs the flow, generates the structured report and decodes it into target
+func (po *PlannerOrchestrator) executeFlowStructured(ctx context.Context, objective string, conversationHistory []llmtypes.MessageContent, schema string, target interface{}) error {
+	if objective == "" {
+		return fmt.Errorf("objective cannot be empty")
+	}
+	if strings.TrimSpace(schema) == "" || !json.Valid([]byte(schema)) {
+		return fmt.Errorf("schema must be a valid JSON schema")
+	}
+
+	// Stop the run once its estimated cost crosses the budget (see SetCostBudget)
+	ctx, cancel := po.WithCostBudget(ctx)
+	defer cancel()
+
+	runFlow := po.flowRunner
+	if runFlow == nil {
+		runFlow = func(ctx context.Context, objective string, conversationHistory []llmtypes.MessageContent) (string, error) {
+			return po.executeFlow(ctx, objective, conversationHistory, nil)
+		}
+	}
+	result, err := runFlow(ctx, objective, conversationHistory)
+	if err = po.CostBudgetError(err); err != nil {
+		return err
+	}
+
+	templateVars := map[string]string{
+		"Objective":           objective,
+		"PlanningResults":     "See the execution results",
+		"ExecutionResults":    result,
+		"ValidationResults":   "See the execution results",
+		"OrganizationResults": "",
+		"WorkspacePath":       po.GetWorkspacePath(),
+	}
+	generate := po.structuredReportGenerator
+	if generate == nil {
+		generate = po.generateStructuredReport
+	}
+	report, err := generate(ctx, templateVars, conversationHistory, schema)
+	if err = po.CostBudgetError(err); err != nil {
+		return fmt.Errorf("structured report generation failed: %w", err)
+	}
+	if err := mcpagent.ValidateStructuredOutput(string(report), schema, target); err != nil {
+		return fmt.Errorf("structured report is invalid: %w", err)
+	}
+
+	po.GetLogger().Infof("✅ Structured planner report generated (%d bytes)", len(report))
+	return nil
+}
+
+// generateStructuredReport asks the report agent for the final report as JSON matching schema
+func (po *PlannerOrchestrator) generateStructuredReport(ctx context.Context, templateVars map[string]string, conversationHistory []llmtypes.MessageContent, schema string) (json.RawMessage, error) {
+	reportAgent, err := po.createReportAgent(ctx, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report agent: %w", err)
+	}
+	structuredAgent, ok := reportAgent.(*agents.OrchestratorReportAgent)
+	if !ok {
+		return nil, fmt.Errorf("report agent %T does not support structured output", reportAgent)
+	}
+	return structuredAgent.ExecuteStructured(ctx, templateVars, conversationHistory, schema)
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/internal/llmtypes"
+	"mcp-agent/agent_go/pkg/logger"
+	"mcp-agent/agent_go/pkg/mcpagent"
+)
+
+type bucketReport struct {
+	Answer     string   `json:"answer"`
+	Buckets    []string `json:"buckets"`
+	Confidence string   `json:"confidence"`
+}
+
+const bucketReportSchema = `{
+  "type": "object",
+  "properties": {
+    "answer": {"type": "string"},
+    "buckets": {"type": "array", "items": {"type": "string"}},
+    "confidence": {"type": "string", "enum": ["high", "medium", "low"]}
+  },
+  "required": ["answer", "buckets", "confidence"]
+}`
+
+// newStructuredReportTestPlanner returns a planner whose flow and report agent are replaced by fakes
+func newStructuredReportTestPlanner(t *testing.T, report string) (*PlannerOrchestrator, *map[string]string) {
+	t.Helper()
+	testLogger := logger.CreateDiscardLogger("error")
+	po, err := NewPlannerOrchestrator("openai", "gpt-4.1", "", 0.2, "simple", t.TempDir(), testLogger, nil, nil, nil, nil, nil, nil, nil, nil, 5)
+	if err != nil {
+		t.Fatalf("failed to create planner orchestrator: %v", err)
+	}
+	po.flowRunner = func(ctx context.Context, objective string, conversationHistory []llmtypes.MessageContent) (string, error) {
+		return "Found 2 buckets: logs, backups", nil
+	}
+	reportVars := &map[string]string{}
+	po.SetStructuredReportGenerator(func(ctx context.Context, templateVars map[string]string, conversationHistory []llmtypes.MessageContent, schema string) (json.RawMessage, error) {
+		*reportVars = templateVars
+		if schema != bucketReportSchema {
+			t.Errorf("report generator got schema %q", schema)
+		}
+		return json.RawMessage(report), nil
+	})
+	return po, reportVars
+}
+
+func TestExecuteFlowStructuredReturnsValidatedReport(t *testing.T) {
+	po, reportVars := newStructuredReportTestPlanner(t, `{"answer": "2 buckets", "buckets": ["logs", "backups"], "confidence": "high"}`)
+
+	report, err := ExecuteFlowStructuredAs[bucketReport](po, context.Background(), "list my buckets", nil, bucketReportSchema)
+	if err != nil {
+		t.Fatalf("ExecuteFlowStructuredAs: %v", err)
+	}
+	if report.Answer != "2 buckets" || len(report.Buckets) != 2 || report.Confidence != "high" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if (*reportVars)["Objective"] != "list my buckets" || (*reportVars)["ExecutionResults"] != "Found 2 buckets: logs, backups" {
+		t.Fatalf("report generator did not get the flow result: %v", *reportVars)
+	}
+
+	raw, err := po.ExecuteFlowStructured(context.Background(), "list my buckets", nil, bucketReportSchema)
+	if err != nil {
+		t.Fatalf("ExecuteFlowStructured: %v", err)
+	}
+	var decoded bucketReport
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.Buckets[1] != "backups" {
+		t.Fatalf("raw report %s does not unmarshal: %v", raw, err)
+	}
+}
+
+func TestExecuteFlowStructuredRejectsInvalidReport(t *testing.T) {
+	po, _ := newStructuredReportTestPlanner(t, `{"answer": "2 buckets", "confidence": "certain"}`)
+
+	_, err := po.ExecuteFlowStructured(context.Background(), "list my buckets", nil, bucketReportSchema)
+	var validationErr *mcpagent.StructuredOutputValidationError
+	if err == nil || !errors.As(err, &validationErr) {
+		t.Fatalf("expected a structured output validation error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "buckets") || !strings.Contains(err.Error(), "confidence") {
+		t.Fatalf("expected the missing and invalid fields reported, got %v", err)
+	}
+
+	if _, err := po.ExecuteFlowStructured(context.Background(), "list my buckets", nil, "not a schema"); err == nil {
+		t.Fatal("expected an invalid schema to be rejected")
+	}
+}
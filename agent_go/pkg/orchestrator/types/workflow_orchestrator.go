@@ -33,6 +33,8 @@ type WorkflowPhase struct {
 	Title       string                `json:"title"`
 	Description string                `json:"description"`
 	Options     []WorkflowPhaseOption `json:"options,omitempty"`
+	// OptionGroups lists the valid values per option group, derived from Options
+	OptionGroups []WorkflowOptionGroup `json:"option_groups,omitempty"`
 }
 
 // WorkflowStatus represents a workflow status
@@ -49,7 +51,7 @@ type WorkflowConstants struct {
 
 // GetWorkflowConstants returns the current workflow constants
 func GetWorkflowConstants() WorkflowConstants {
-	return WorkflowConstants{
+	constants := WorkflowConstants{
 		Phases: []WorkflowPhase{
 			{
 				ID:          database.WorkflowStatusPreVerification,
@@ -103,6 +105,11 @@ func GetWorkflowConstants() WorkflowConstants {
 			},
 		},
 	}
+
+	for i := range constants.Phases {
+		constants.Phases[i].OptionGroups = buildOptionGroups(constants.Phases[i].Options)
+	}
+	return constants
 }
 
 // GetWorkflowPhaseByID returns a workflow phase by its ID
@@ -141,6 +148,27 @@ func HandleWorkflowConstants(w http.ResponseWriter, r *http.Request) {
 type WorkflowOrchestrator struct {
 	// Base orchestrator for common functionality
 	*orchestrator.BaseOrchestrator
+
+	// Per-run policy: escalate a step to human feedback only after this many automated failures (0 = always ask)
+	humanEscalationAfterFailures int
+
+	// Per-run default validation score a step must reach, for steps without their own threshold (0 = disabled)
+	successThreshold float64
+
+	// Per-run word-overlap threshold for merging plan steps that repeat an earlier step (0 = disabled)
+	stepDedupThreshold float64
+
+	// Per-run limit of identical plan feedback before asking the human to change approach (0 = default, negative disables)
+	repeatedFeedbackLimit int
+
+	// Per-run number of workers executing independent plan steps concurrently (0 or 1 = one by one)
+	parallelStepWorkers int
+
+	// Per-run opt-out of injecting dependency step outputs into execution prompts (injection is on by default)
+	disableDependencyOutputs bool
+
+	// Per-run plan-only mode: plan and extract the steps, then return the plan without executing it
+	dryRun bool
 }
 
 // Human verification types
@@ -231,7 +259,7 @@ func (wo *WorkflowOrchestrator) executeFlow(
 	objective string,
 	workspacePath string,
 	workflowStatus string,
-	selectedOptions *database.WorkflowSelectedOptions,
+	selectedOptions *WorkflowOptions,
 ) (string, error) {
 	// Set workspace path from parameter
 	wo.SetWorkspacePath(workspacePath)
@@ -239,6 +267,12 @@ func (wo *WorkflowOrchestrator) executeFlow(
 		return "", fmt.Errorf("workspace path is required")
 	}
 
+	// A dry run only plans, whatever the workflow status
+	if wo.dryRun {
+		wo.GetLogger().Infof("🧪 Dry run: planning only, no step will be executed")
+		return wo.runPlanning(ctx, objective, selectedOptions)
+	}
+
 	// Check workflow status and execute appropriate flow
 	switch workflowStatus {
 	case database.WorkflowStatusPostVerification:
@@ -255,7 +289,7 @@ func (wo *WorkflowOrchestrator) executeFlow(
 	}
 }
 
-func (wo *WorkflowOrchestrator) runPlanning(ctx context.Context, objective string, selectedOptions *database.WorkflowSelectedOptions) (string, error) {
+func (wo *WorkflowOrchestrator) runPlanning(ctx context.Context, objective string, selectedOptions *WorkflowOptions) (string, error) {
 	wo.GetLogger().Infof("👤 Starting Planning Phase")
 	return wo.runHumanControlledPlanning(ctx, objective)
 }
@@ -285,6 +319,14 @@ func (wo *WorkflowOrchestrator) runHumanControlledPlanning(ctx context.Context,
 	if err != nil {
 		return "", fmt.Errorf("failed to create human controlled planner orchestrator: %w", err)
 	}
+	wo.AddChild(todoPlannerAgent)
+	todoPlannerAgent.SetHumanEscalationAfterFailures(wo.humanEscalationAfterFailures)
+	todoPlannerAgent.SetSuccessThreshold(wo.successThreshold)
+	todoPlannerAgent.SetStepDedupThreshold(wo.stepDedupThreshold)
+	todoPlannerAgent.SetRepeatedFeedbackLimit(wo.repeatedFeedbackLimit)
+	todoPlannerAgent.SetParallelStepWorkers(wo.parallelStepWorkers)
+	todoPlannerAgent.SetInjectDependencyOutputs(!wo.disableDependencyOutputs)
+	todoPlannerAgent.SetDryRun(wo.dryRun)
 
 	// Generate todo list using Execute method
 	todoListMarkdown, err := todoPlannerAgent.Execute(ctx, objective, wo.GetWorkspacePath(), nil)
@@ -292,6 +334,13 @@ func (wo *WorkflowOrchestrator) runHumanControlledPlanning(ctx context.Context,
 		return "", fmt.Errorf("failed to create/update todo list: %w", err)
 	}
 
+	// The dry run result is the plan itself; there is nothing to approve for execution
+	if wo.dryRun {
+		wo.EmitOrchestratorEnd(ctx, objective, todoListMarkdown, "completed", "", "workflow_execution")
+		wo.EmitUnifiedCompletionEvent(ctx, "workflow", "workflow", objective, todoListMarkdown, "completed", 1)
+		return todoListMarkdown, nil
+	}
+
 	// Emit request_human_feedback event
 	if err := wo.emitRequestHumanFeedback(ctx, objective, todoListMarkdown,
 		"planning_verification",
@@ -312,7 +361,7 @@ func (wo *WorkflowOrchestrator) runHumanControlledPlanning(ctx context.Context,
 }
 
 // runExecution runs the execution phase of the workflow
-func (wo *WorkflowOrchestrator) runExecution(ctx context.Context, objective string, selectedOptions *database.WorkflowSelectedOptions) (string, error) {
+func (wo *WorkflowOrchestrator) runExecution(ctx context.Context, objective string, selectedOptions *WorkflowOptions) (string, error) {
 	// Create TodoExecutionOrchestrator
 	todoExecutionOrchestrator, err := wo.createTodoExecutionOrchestrator()
 	if err != nil {
@@ -324,7 +373,15 @@ func (wo *WorkflowOrchestrator) runExecution(ctx context.Context, objective stri
 
 	// Delegate to TodoExecutionOrchestrator using Execute method
 	executionOptions := map[string]interface{}{
-		"runOption": runOption,
+		"RunOption": runOption,
+	}
+	if selectedOptions != nil && len(selectedOptions.StepApprovals) > 0 {
+		approvals := make(map[int]todo_execution.StepApproval, len(selectedOptions.StepApprovals))
+		for _, approval := range selectedOptions.StepApprovals {
+			approvals[approval.StepNumber] = todo_execution.StepApproval{Approved: approval.Approved, Feedback: approval.Feedback}
+		}
+		executionOptions["StepApprovals"] = approvals
+		wo.GetLogger().Infof("✅ Partial approval: %d step decisions, unapproved steps are held for revision", len(approvals))
 	}
 	executionResult, err := todoExecutionOrchestrator.Execute(ctx, objective, wo.GetWorkspacePath(), executionOptions)
 	if err != nil {
@@ -363,25 +420,23 @@ func (wo *WorkflowOrchestrator) createTodoExecutionOrchestrator() (orchestrator.
 	if err != nil {
 		return nil, fmt.Errorf("failed to create todo execution orchestrator: %w", err)
 	}
+	if err := agent.SetConsensus(wo.GetConsensus()); err != nil {
+		return nil, fmt.Errorf("invalid consensus configuration: %w", err)
+	}
 
 	// Set workspace tools if available
 	// Note: WorkspaceTools and WorkspaceToolExecutors are already available from BaseOrchestrator
 
+	wo.AddChild(agent)
 	return agent, nil
 }
 
-// getRunOption extracts the run option from selected options
-func (wo *WorkflowOrchestrator) getRunOption(selectedOptions *database.WorkflowSelectedOptions) string {
-	runOption := "create_new_runs_always" // default
-	if selectedOptions != nil && selectedOptions.PhaseID == database.WorkflowStatusPostVerification {
-		for _, selection := range selectedOptions.Selections {
-			if selection.Group == "run_management" {
-				runOption = selection.OptionID
-				break
-			}
-		}
+// getRunOption returns the run management option from validated selected options
+func (wo *WorkflowOrchestrator) getRunOption(selectedOptions *WorkflowOptions) string {
+	if selectedOptions == nil || selectedOptions.PhaseID != database.WorkflowStatusPostVerification {
+		selectedOptions = defaultWorkflowOptions(database.WorkflowStatusPostVerification)
 	}
-	return runOption
+	return selectedOptions.RunManagement
 }
 
 // emitRequestHumanFeedback emits a request human feedback event
@@ -469,9 +524,13 @@ func (wo *WorkflowOrchestrator) Execute(ctx context.Context, objective string, w
 		if selectedOptsVal, exists := options["selectedOptions"]; exists {
 			wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - selectedOptions found: %+v (type: %T)", selectedOptsVal, selectedOptsVal)
 			if selectedOptsVal != nil {
-				if _, ok := selectedOptsVal.(*database.WorkflowSelectedOptions); !ok {
+				so, ok := selectedOptsVal.(*database.WorkflowSelectedOptions)
+				if !ok {
 					return "", fmt.Errorf("invalid selectedOptions: expected *database.WorkflowSelectedOptions, got %T", selectedOptsVal)
 				}
+				if _, err := ValidateSelectedOptions(so); err != nil {
+					return "", err
+				}
 			}
 		} else {
 			wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - selectedOptions not found in options")
@@ -490,20 +549,59 @@ func (wo *WorkflowOrchestrator) Execute(ctx context.Context, objective string, w
 		wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - using default workflowStatus: %s", workflowStatus)
 	}
 
-	var selectedOptions *database.WorkflowSelectedOptions
+	var selectedOptions *WorkflowOptions
 	if opts, ok := options["selectedOptions"]; ok && opts != nil {
 		if so, ok := opts.(*database.WorkflowSelectedOptions); ok {
-			selectedOptions = so
+			// Already validated above
+			selectedOptions, _ = ValidateSelectedOptions(so)
 			wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - extracted selectedOptions: %+v", selectedOptions)
-			if selectedOptions != nil {
-				wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - selectedOptions.PhaseID: %s", selectedOptions.PhaseID)
-				wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - selectedOptions.Selections count: %d", len(selectedOptions.Selections))
-			}
 		}
 	} else {
 		wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - no selectedOptions extracted")
 	}
 
+	// Per-run human escalation policy
+	if failures, ok := options["humanEscalationAfterFailures"].(int); ok && failures > 0 {
+		wo.humanEscalationAfterFailures = failures
+		wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - human escalation after %d automated failures", failures)
+	}
+
+	// Per-run default validation score threshold
+	if threshold, ok := options["stepSuccessThreshold"].(float64); ok && threshold > 0 {
+		wo.successThreshold = threshold
+		wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - steps pass validation at score %.2f unless they set their own threshold", threshold)
+	}
+
+	// Per-run lexical duplicate step merging
+	if threshold, ok := options["stepDedupThreshold"].(float64); ok && threshold > 0 {
+		wo.stepDedupThreshold = threshold
+		wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - merging steps at word-overlap similarity %.2f", threshold)
+	}
+
+	// Per-run repeated feedback detection
+	if limit, ok := options["repeatedFeedbackLimit"].(int); ok && limit != 0 {
+		wo.repeatedFeedbackLimit = limit
+		wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - repeated plan feedback limit %d", limit)
+	}
+
+	// Per-run parallel execution of independent steps
+	if workers, ok := options["parallelStepWorkers"].(int); ok && workers > 1 {
+		wo.parallelStepWorkers = workers
+		wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - executing independent steps with %d parallel workers", workers)
+	}
+
+	// Per-run dependency output injection
+	if disabled, ok := options["disableDependencyOutputs"].(bool); ok {
+		wo.disableDependencyOutputs = disabled
+		wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - dependency output injection disabled: %v", disabled)
+	}
+
+	// Per-run plan-only mode
+	if dryRun, ok := options["dryRun"].(bool); ok {
+		wo.dryRun = dryRun
+		wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - dry run: %v", dryRun)
+	}
+
 	// Validate workspace path is provided
 	if workspacePath == "" {
 		return "", fmt.Errorf("workspace path is required")
@@ -517,9 +615,13 @@ func (wo *WorkflowOrchestrator) Execute(ctx context.Context, objective string, w
 	wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - About to call executeFlow with workflowStatus: %s", workflowStatus)
 	wo.GetLogger().Infof("🚀 WORKFLOW EXECUTION DEBUG - selectedOptions for executeFlow: %+v", selectedOptions)
 
+	// Stop the run once its estimated cost crosses the budget (see SetCostBudget)
+	ctx, cancel := wo.WithCostBudget(ctx)
+	defer cancel()
+
 	// Call the existing executeFlow method with the extracted parameters
 	result, err := wo.executeFlow(ctx, objective, workspacePath, workflowStatus, selectedOptions)
-	if err != nil {
+	if err = wo.CostBudgetError(err); err != nil {
 		wo.GetLogger().Errorf("🚀 WORKFLOW EXECUTION ERROR - executeFlow failed: %w", err)
 		return "", err
 	}
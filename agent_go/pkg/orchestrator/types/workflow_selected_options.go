@@ -0,0 +1,164 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"mcp-agent/agent_go/pkg/database"
+)
+
+// Known workflow option groups
+const (
+	OptionGroupRunManagement     = "run_management"
+	OptionGroupExecutionStrategy = "execution_strategy"
+)
+
+// WorkflowOptionGroup describes one group of mutually exclusive options for a phase
+type WorkflowOptionGroup struct {
+	ID      string   `json:"id"`
+	Values  []string `json:"values"`  // Valid option IDs in this group
+	Default string   `json:"default"` // Option ID used when nothing is selected
+}
+
+// WorkflowOptions is the typed, validated form of database.WorkflowSelectedOptions
+type WorkflowOptions struct {
+	PhaseID           string        `json:"phase_id"`
+	RunManagement     string        `json:"run_management"`
+	ExecutionStrategy ExecutionMode `json:"execution_strategy"`
+
+	// Partial approval: only approved steps execute, the rest are held for revision
+	StepApprovals []database.WorkflowStepApproval `json:"step_approvals,omitempty"`
+}
+
+// buildOptionGroups derives the option groups of a phase from its options, in first-seen order
+func buildOptionGroups(options []WorkflowPhaseOption) []WorkflowOptionGroup {
+	groups := []WorkflowOptionGroup{}
+	index := map[string]int{}
+	for _, option := range options {
+		i, exists := index[option.Group]
+		if !exists {
+			i = len(groups)
+			index[option.Group] = i
+			groups = append(groups, WorkflowOptionGroup{ID: option.Group})
+		}
+		groups[i].Values = append(groups[i].Values, option.ID)
+		if option.Default {
+			groups[i].Default = option.ID
+		}
+	}
+	return groups
+}
+
+// defaultWorkflowOptions returns the defaults of every option group for the phase
+func defaultWorkflowOptions(phaseID string) *WorkflowOptions {
+	resolved := &WorkflowOptions{
+		PhaseID:           phaseID,
+		RunManagement:     "create_new_runs_always",
+		ExecutionStrategy: SequentialExecution,
+	}
+	if phase := GetWorkflowPhaseByID(phaseID); phase != nil {
+		for _, group := range phase.OptionGroups {
+			resolved.apply(group.ID, group.Default)
+		}
+	}
+	return resolved
+}
+
+func (o *WorkflowOptions) apply(group, value string) {
+	switch group {
+	case OptionGroupRunManagement:
+		o.RunManagement = value
+	case OptionGroupExecutionStrategy:
+		o.ExecutionStrategy = ExecutionMode(value)
+	}
+}
+
+// ValidateSelectedOptions checks selected options against the workflow constants and returns
+// them in typed form, with unselected groups set to their defaults. Unknown phases, groups and
+// values, more than one selection per group, and invalid or duplicate step approvals are rejected
+// with an error listing every problem.
+func ValidateSelectedOptions(selected *database.WorkflowSelectedOptions) (*WorkflowOptions, error) {
+	if selected == nil {
+		return nil, nil
+	}
+
+	phase := GetWorkflowPhaseByID(selected.PhaseID)
+	if phase == nil {
+		validPhases := []string{}
+		for _, p := range GetWorkflowConstants().Phases {
+			validPhases = append(validPhases, p.ID)
+		}
+		return nil, fmt.Errorf("invalid selected options: unknown phase %q (valid phases: %s)", selected.PhaseID, strings.Join(validPhases, ", "))
+	}
+
+	groups := map[string]WorkflowOptionGroup{}
+	validGroups := []string{}
+	for _, group := range phase.OptionGroups {
+		groups[group.ID] = group
+		validGroups = append(validGroups, group.ID)
+	}
+	sort.Strings(validGroups)
+
+	resolved := defaultWorkflowOptions(phase.ID)
+	seen := map[string]bool{}
+	var problems []string
+	for i, selection := range selected.Selections {
+		if selection.PhaseID != "" && selection.PhaseID != phase.ID {
+			problems = append(problems, fmt.Sprintf("selection %d belongs to phase %q, not %q", i, selection.PhaseID, phase.ID))
+			continue
+		}
+		group, ok := groups[selection.Group]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown group %q for phase %q (valid groups: %s)", selection.Group, phase.ID, strings.Join(validGroups, ", ")))
+			continue
+		}
+		if seen[group.ID] {
+			problems = append(problems, fmt.Sprintf("group %q has more than one selection", group.ID))
+			continue
+		}
+		seen[group.ID] = true
+
+		value := selection.OptionID
+		if value == "" {
+			value = selection.OptionValue
+		}
+		if !containsString(group.Values, value) {
+			problems = append(problems, fmt.Sprintf("unknown value %q for group %q (valid values: %s)", value, group.ID, strings.Join(group.Values, ", ")))
+			continue
+		}
+		resolved.apply(group.ID, value)
+	}
+
+	// Partial approval only makes sense once there is a plan to execute
+	if len(selected.StepApprovals) > 0 && phase.ID != database.WorkflowStatusPostVerification {
+		problems = append(problems, fmt.Sprintf("step approvals apply to phase %q, not %q", database.WorkflowStatusPostVerification, phase.ID))
+	} else {
+		seenSteps := map[int]bool{}
+		for _, approval := range selected.StepApprovals {
+			switch {
+			case approval.StepNumber < 1:
+				problems = append(problems, fmt.Sprintf("invalid step number %d in step approvals", approval.StepNumber))
+			case seenSteps[approval.StepNumber]:
+				problems = append(problems, fmt.Sprintf("step %d has more than one approval decision", approval.StepNumber))
+			default:
+				seenSteps[approval.StepNumber] = true
+				resolved.StepApprovals = append(resolved.StepApprovals, approval)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid selected options: %s", strings.Join(problems, "; "))
+	}
+	return resolved, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"mcp-agent/agent_go/pkg/database"
+	"mcp-agent/agent_go/pkg/logger"
+)
+
+func postVerificationOptions(selections ...database.WorkflowSelectedOption) *database.WorkflowSelectedOptions {
+	return &database.WorkflowSelectedOptions{PhaseID: database.WorkflowStatusPostVerification, Selections: selections}
+}
+
+func TestValidateSelectedOptionsRejectsInvalid(t *testing.T) {
+	cases := map[string]struct {
+		options *database.WorkflowSelectedOptions
+		want    string
+	}{
+		"unknown phase": {
+			options: &database.WorkflowSelectedOptions{PhaseID: "review"},
+			want:    `unknown phase "review"`,
+		},
+		"unknown group": {
+			options: postVerificationOptions(database.WorkflowSelectedOption{Group: "retry_policy", OptionID: "aggressive"}),
+			want:    `unknown group "retry_policy"`,
+		},
+		"unknown value": {
+			options: postVerificationOptions(database.WorkflowSelectedOption{Group: OptionGroupRunManagement, OptionID: "reuse_yesterday"}),
+			want:    `unknown value "reuse_yesterday" for group "run_management"`,
+		},
+		"duplicate group": {
+			options: postVerificationOptions(
+				database.WorkflowSelectedOption{Group: OptionGroupRunManagement, OptionID: "use_same_run"},
+				database.WorkflowSelectedOption{Group: OptionGroupRunManagement, OptionID: "create_new_runs_always"},
+			),
+			want: `group "run_management" has more than one selection`,
+		},
+		"option for another phase": {
+			options: &database.WorkflowSelectedOptions{PhaseID: database.WorkflowStatusPreVerification, Selections: []database.WorkflowSelectedOption{
+				{Group: OptionGroupRunManagement, OptionID: "use_same_run"},
+			}},
+			want: `unknown group "run_management" for phase "pre-verification"`,
+		},
+		"step approvals for another phase": {
+			options: &database.WorkflowSelectedOptions{PhaseID: database.WorkflowStatusPreVerification, StepApprovals: []database.WorkflowStepApproval{
+				{StepNumber: 1, Approved: true},
+			}},
+			want: `step approvals apply to phase "post-verification", not "pre-verification"`,
+		},
+		"duplicate step approval": {
+			options: &database.WorkflowSelectedOptions{PhaseID: database.WorkflowStatusPostVerification, StepApprovals: []database.WorkflowStepApproval{
+				{StepNumber: 2, Approved: true},
+				{StepNumber: 2, Approved: false, Feedback: "split it"},
+			}},
+			want: `step 2 has more than one approval decision`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolved, err := ValidateSelectedOptions(tc.options)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v (resolved %+v)", tc.want, err, resolved)
+			}
+		})
+	}
+}
+
+func TestValidateSelectedOptionsAppliesValid(t *testing.T) {
+	resolved, err := ValidateSelectedOptions(postVerificationOptions(
+		database.WorkflowSelectedOption{Group: OptionGroupRunManagement, OptionID: "use_same_run"},
+	))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.RunManagement != "use_same_run" || resolved.ExecutionStrategy != SequentialExecution {
+		t.Fatalf("expected selection applied and default strategy kept, got %+v", resolved)
+	}
+
+	wo := &WorkflowOrchestrator{}
+	if runOption := wo.getRunOption(resolved); runOption != "use_same_run" {
+		t.Fatalf("expected run option use_same_run, got %s", runOption)
+	}
+	if runOption := wo.getRunOption(nil); runOption != "create_new_runs_always" {
+		t.Fatalf("expected default run option, got %s", runOption)
+	}
+}
+
+func TestWorkflowConstantsExposeOptionGroups(t *testing.T) {
+	phase := GetWorkflowPhaseByID(database.WorkflowStatusPostVerification)
+	if phase == nil || len(phase.OptionGroups) != 2 {
+		t.Fatalf("expected two option groups for post-verification, got %+v", phase)
+	}
+	runManagement := phase.OptionGroups[0]
+	if runManagement.ID != OptionGroupRunManagement || runManagement.Default != "create_new_runs_always" || len(runManagement.Values) != 3 {
+		t.Fatalf("unexpected run management group: %+v", runManagement)
+	}
+}
+
+func TestWorkflowExecuteRejectsInvalidSelectedOptions(t *testing.T) {
+	testLogger := logger.CreateDiscardLogger("error")
+	wo, err := NewWorkflowOrchestrator("openai", "gpt-4.1", "", 0.2, "simple", testLogger, nil, nil, nil, nil, nil, nil, nil, 5)
+	if err != nil {
+		t.Fatalf("failed to create workflow orchestrator: %v", err)
+	}
+
+	_, err = wo.Execute(context.Background(), "objective", t.TempDir(), map[string]interface{}{
+		"workflowStatus":  database.WorkflowStatusPostVerification,
+		"selectedOptions": postVerificationOptions(database.WorkflowSelectedOption{Group: "bogus", OptionID: "x"}),
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown group "bogus"`) {
+		t.Fatalf("expected invalid selected options to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Package pricing estimates the USD cost of LLM usage from per-model token prices.
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"mcp-agent/agent_go/internal/utils"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// ModelPrice is a model's price in USD per 1K prompt and completion tokens
+type ModelPrice struct {
+	InputPer1K  float64 `json:"input_per_1k"`
+	OutputPer1K float64 `json:"output_per_1k"`
+}
+
+// defaultPrices keyed by a model ID fragment, covering common Bedrock and OpenAI models
+var defaultPrices = map[string]ModelPrice{
+	"gpt-4o":            {InputPer1K: 0.0025, OutputPer1K: 0.01},
+	"gpt-4o-mini":       {InputPer1K: 0.00015, OutputPer1K: 0.0006},
+	"gpt-4.1":           {InputPer1K: 0.002, OutputPer1K: 0.008},
+	"gpt-4.1-mini":      {InputPer1K: 0.0004, OutputPer1K: 0.0016},
+	"gpt-5":             {InputPer1K: 0.00125, OutputPer1K: 0.01},
+	"gpt-5-mini":        {InputPer1K: 0.00025, OutputPer1K: 0.002},
+	"o3":                {InputPer1K: 0.002, OutputPer1K: 0.008},
+	"o4-mini":           {InputPer1K: 0.0011, OutputPer1K: 0.0044},
+	"claude-3-haiku":    {InputPer1K: 0.00025, OutputPer1K: 0.00125},
+	"claude-3-5-haiku":  {InputPer1K: 0.0008, OutputPer1K: 0.004},
+	"claude-3-5-sonnet": {InputPer1K: 0.003, OutputPer1K: 0.015},
+	"claude-3-7-sonnet": {InputPer1K: 0.003, OutputPer1K: 0.015},
+	"claude-sonnet-4":   {InputPer1K: 0.003, OutputPer1K: 0.015},
+	"claude-opus-4":     {InputPer1K: 0.015, OutputPer1K: 0.075},
+	"nova-lite":         {InputPer1K: 0.00006, OutputPer1K: 0.00024},
+	"nova-pro":          {InputPer1K: 0.0008, OutputPer1K: 0.0032},
+	"gemini-2.5-pro":    {InputPer1K: 0.00125, OutputPer1K: 0.01},
+	"gemini-2.5-flash":  {InputPer1K: 0.0003, OutputPer1K: 0.0025},
+}
+
+// PriceTable maps model ID fragments to prices; the longest fragment contained in a
+// (lowercased) model ID wins, so "us.anthropic.claude-sonnet-4-20250514-v1:0" is priced
+// as "claude-sonnet-4". It is safe for concurrent use.
+type PriceTable struct {
+	prices map[string]ModelPrice
+	logger utils.ExtendedLogger
+	warned sync.Map // unknown model IDs already warned about
+}
+
+// NewPriceTable creates a price table from prices keyed by model ID fragment
+func NewPriceTable(prices map[string]ModelPrice) *PriceTable {
+	table := &PriceTable{prices: make(map[string]ModelPrice, len(prices))}
+	for fragment, price := range prices {
+		table.prices[strings.ToLower(fragment)] = price
+	}
+	return table
+}
+
+// DefaultPriceTable returns a price table with the built-in prices
+func DefaultPriceTable() *PriceTable {
+	return NewPriceTable(defaultPrices)
+}
+
+// LoadPriceTable reads a JSON object of model ID fragment -> {input_per_1k, output_per_1k}.
+// Its entries override and extend the built-in prices.
+func LoadPriceTable(path string) (*PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing config: %w", err)
+	}
+	var custom map[string]ModelPrice
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing config %s: %w", path, err)
+	}
+
+	prices := make(map[string]ModelPrice, len(defaultPrices)+len(custom))
+	for fragment, price := range defaultPrices {
+		prices[fragment] = price
+	}
+	for fragment, price := range custom {
+		if price.InputPer1K < 0 || price.OutputPer1K < 0 {
+			return nil, fmt.Errorf("pricing config %s: negative price for %q", path, fragment)
+		}
+		prices[strings.ToLower(fragment)] = price
+	}
+	return NewPriceTable(prices), nil
+}
+
+// SetLogger sets the logger unknown models are reported to (the standard logger by default)
+func (t *PriceTable) SetLogger(logger utils.ExtendedLogger) {
+	t.logger = logger
+}
+
+// Price returns the price of modelID and whether the table knows the model
+func (t *PriceTable) Price(modelID string) (ModelPrice, bool) {
+	modelID = strings.ToLower(modelID)
+	bestMatch, price := "", ModelPrice{}
+	for fragment, fragmentPrice := range t.prices {
+		if len(fragment) > len(bestMatch) && strings.Contains(modelID, fragment) {
+			bestMatch, price = fragment, fragmentPrice
+		}
+	}
+	return price, bestMatch != ""
+}
+
+// EstimateCost prices usage by modelID; unknown models cost 0 and are warned about once
+func (t *PriceTable) EstimateCost(modelID string, usage events.UsageMetrics) float64 {
+	price, ok := t.Price(modelID)
+	if !ok {
+		if _, warned := t.warned.LoadOrStore(modelID, true); !warned {
+			t.warnf("⚠️ No price configured for model %q, estimating its cost as $0", modelID)
+		}
+		return 0
+	}
+	return float64(usage.PromptTokens)/1000*price.InputPer1K + float64(usage.CompletionTokens)/1000*price.OutputPer1K
+}
+
+func (t *PriceTable) warnf(format string, args ...interface{}) {
+	if t.logger != nil {
+		t.logger.Warnf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+var defaultTable atomic.Pointer[PriceTable]
+
+func init() {
+	defaultTable.Store(DefaultPriceTable())
+}
+
+// Default returns the process-wide price table (the built-in prices unless SetDefault was called)
+func Default() *PriceTable {
+	return defaultTable.Load()
+}
+
+// SetDefault replaces the process-wide price table, e.g. with one loaded from --pricing-config
+func SetDefault(table *PriceTable) {
+	defaultTable.Store(table)
+}
+
+// EstimateCost prices usage by modelID with the process-wide price table
+func EstimateCost(modelID string, usage events.UsageMetrics) float64 {
+	return Default().EstimateCost(modelID, usage)
+}
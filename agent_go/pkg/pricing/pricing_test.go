@@ -0,0 +1,89 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mcp-agent/agent_go/internal/utils"
+	"mcp-agent/agent_go/pkg/events"
+)
+
+// warnLogger records warnings
+type warnLogger struct {
+	utils.ExtendedLogger
+	warnings []string
+}
+
+func (l *warnLogger) Warnf(format string, args ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEstimateCostForKnownModels(t *testing.T) {
+	table := DefaultPriceTable()
+	usage := events.UsageMetrics{PromptTokens: 2000, CompletionTokens: 1000}
+
+	cases := map[string]float64{
+		"us.anthropic.claude-sonnet-4-20250514-v1:0": 2*0.003 + 0.015,
+		"gpt-4o-mini-2024-07-18":                     2*0.00015 + 0.0006,
+		"gpt-4o":                                     2*0.0025 + 0.01,
+		"amazon.nova-pro-v1:0":                       2*0.0008 + 0.0032,
+	}
+	for modelID, want := range cases {
+		if cost := table.EstimateCost(modelID, usage); !closeTo(cost, want) {
+			t.Errorf("%s: expected $%.5f, got $%.5f", modelID, want, cost)
+		}
+	}
+}
+
+func TestEstimateCostWarnsOnceForUnknownModel(t *testing.T) {
+	table := DefaultPriceTable()
+	logger := &warnLogger{}
+	table.SetLogger(logger)
+	usage := events.UsageMetrics{PromptTokens: 1000, CompletionTokens: 1000}
+
+	for i := 0; i < 2; i++ {
+		if cost := table.EstimateCost("mystery-model", usage); cost != 0 {
+			t.Fatalf("expected unknown models to cost nothing, got $%.5f", cost)
+		}
+	}
+	if len(logger.warnings) != 1 {
+		t.Fatalf("expected one warning for the unknown model, got %v", logger.warnings)
+	}
+}
+
+func TestLoadPriceTableOverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.json")
+	config := `{"gpt-4o": {"input_per_1k": 0.001, "output_per_1k": 0.002}, "My-Model": {"input_per_1k": 0.01, "output_per_1k": 0.02}}`
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	table, err := LoadPriceTable(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	usage := events.UsageMetrics{PromptTokens: 1000, CompletionTokens: 1000}
+
+	if cost := table.EstimateCost("gpt-4o-2024-08-06", usage); !closeTo(cost, 0.003) {
+		t.Fatalf("expected the configured gpt-4o price, got $%.5f", cost)
+	}
+	if cost := table.EstimateCost("my-model-v2", usage); !closeTo(cost, 0.03) {
+		t.Fatalf("expected the added model's price, got $%.5f", cost)
+	}
+	if cost := table.EstimateCost("claude-opus-4-1", usage); !closeTo(cost, 0.09) {
+		t.Fatalf("expected the built-in claude-opus-4 price, got $%.5f", cost)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"gpt-4o": {"input_per_1k": -1}}`), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadPriceTable(path); err == nil {
+		t.Fatal("expected negative prices to be rejected")
+	}
+}
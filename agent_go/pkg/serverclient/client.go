@@ -0,0 +1,160 @@
+// Package serverclient provides a Go client for the agent server polling API.
+package serverclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a polled server event. Data is kept raw so callers can decode
+// the event types they care about.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	SessionID string          `json:"session_id,omitempty"`
+}
+
+// EventsPage is a single poll response
+type EventsPage struct {
+	Events         []Event `json:"events"`
+	LastEventIndex int     `json:"last_event_index"`
+	NextCursor     int     `json:"next_cursor"`
+	HasMore        bool    `json:"has_more"`
+	ObserverID     string  `json:"observer_id"`
+}
+
+// RetryConfig controls automatic retry of failed polls
+type RetryConfig struct {
+	MaxRetries     int           // Retries after the first failed attempt (0 = no retry)
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound for the exponential backoff
+}
+
+// DefaultRetryConfig returns the retry configuration used by NewClient
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:     5,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+	}
+}
+
+// Client talks to the agent server's observer polling API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retry      RetryConfig
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetryConfig sets the retry behaviour for failed polls
+func WithRetryConfig(retry RetryConfig) Option {
+	return func(c *Client) {
+		c.retry = retry
+	}
+}
+
+// NewClient creates a client for the server at baseURL (e.g. http://localhost:8000)
+func NewClient(baseURL string, options ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		retry:      DefaultRetryConfig(),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// RegisterObserver registers a new observer and returns its ID
+func (c *Client) RegisterObserver(ctx context.Context, sessionID string) (string, error) {
+	body, err := json.Marshal(map[string]string{"session_id": sessionID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode register request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/observer/register", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create register request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var response struct {
+		ObserverID string `json:"observer_id"`
+	}
+	if err := c.do(req, &response); err != nil {
+		return "", fmt.Errorf("failed to register observer: %w", err)
+	}
+	return response.ObserverID, nil
+}
+
+// GetEvents fetches events after sinceIndex in a single attempt
+func (c *Client) GetEvents(ctx context.Context, observerID string, sinceIndex int) (*EventsPage, error) {
+	endpoint := fmt.Sprintf("%s/api/observer/%s/events?since=%s", c.baseURL, url.PathEscape(observerID), strconv.Itoa(sinceIndex))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create poll request: %w", err)
+	}
+
+	var page EventsPage
+	if err := c.do(req, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// StatusError is returned when the server responds with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("server returned %d: %s", e.StatusCode, e.Body)
+}
+
+// retryable reports whether a failed request may succeed if retried
+func retryable(err error) bool {
+	if statusErr, ok := err.(*StatusError); ok {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+func (c *Client) do(req *http.Request, out interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
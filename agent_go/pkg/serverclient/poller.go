@@ -0,0 +1,96 @@
+package serverclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Poller polls events for one observer, retrying transient failures with
+// backoff. The cursor only advances after a successful poll, so a poll that
+// fails and is retried resumes from the same position without losing events.
+type Poller struct {
+	client     *Client
+	observerID string
+
+	mu     sync.Mutex
+	cursor int
+}
+
+// NewPoller creates a poller that starts before the first event
+func (c *Client) NewPoller(observerID string) *Poller {
+	return &Poller{client: c, observerID: observerID, cursor: -1}
+}
+
+// Cursor returns the index of the last event delivered by the poller
+func (p *Poller) Cursor() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cursor
+}
+
+// Poll fetches the events published since the last successful poll
+func (p *Poller) Poll(ctx context.Context) ([]Event, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	retry := p.client.retry
+	backoff := retry.InitialBackoff
+	var lastErr error
+
+	for attempt := 0; attempt <= retry.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if retry.MaxBackoff > 0 && backoff > retry.MaxBackoff {
+				backoff = retry.MaxBackoff
+			}
+		}
+
+		page, err := p.client.GetEvents(ctx, p.observerID, p.cursor)
+		if err == nil {
+			if len(page.Events) > 0 {
+				p.cursor = page.LastEventIndex
+			}
+			return page.Events, nil
+		}
+
+		lastErr = err
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !retryable(err) {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("failed to poll events for observer %s: %w", p.observerID, lastErr)
+}
+
+// Run polls every interval and passes new events to handler until ctx is done
+// or a poll fails after exhausting its retries
+func (p *Poller) Run(ctx context.Context, interval time.Duration, handler func([]Event)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := p.Poll(ctx)
+		if err != nil {
+			return err
+		}
+		if len(events) > 0 {
+			handler(events)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
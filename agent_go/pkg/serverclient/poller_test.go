@@ -0,0 +1,139 @@
+package serverclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mcp-agent/agent_go/internal/events"
+)
+
+// flakyEventServer serves events from a real EventStore and drops the
+// connection for the polls listed in failOn (1-based)
+func flakyEventServer(t *testing.T, store *events.EventStore, failOn map[int32]bool) (*httptest.Server, *int32) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&polls, 1)
+		if failOn[n] {
+			hijacker, ok := w.(http.Hijacker)
+			if !ok {
+				t.Fatalf("response writer does not support hijacking")
+			}
+			conn, _, err := hijacker.Hijack()
+			if err != nil {
+				t.Fatalf("hijack failed: %v", err)
+			}
+			conn.Close()
+			return
+		}
+
+		since, _ := strconv.Atoi(r.URL.Query().Get("since"))
+		evts, lastIndex, _ := store.GetEvents("obs", since)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"events":           evts,
+			"last_event_index": lastIndex,
+			"has_more":         len(evts) > 0,
+			"observer_id":      "obs",
+		})
+	}))
+	return server, &polls
+}
+
+func addEvents(store *events.EventStore, from, to int) {
+	for i := from; i < to; i++ {
+		store.AddEvent("obs", events.Event{ID: fmt.Sprintf("evt-%d", i), Type: "test", Timestamp: time.Now()})
+	}
+}
+
+func TestPollerRecoversFromTransientFailure(t *testing.T) {
+	store := events.NewEventStore(100)
+	defer store.Stop()
+	store.InitializeObserver("obs")
+
+	server, polls := flakyEventServer(t, store, map[int32]bool{2: true, 3: true})
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryConfig(RetryConfig{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}))
+	poller := client.NewPoller("obs")
+
+	var received []string
+	collect := func(evts []Event) {
+		for _, e := range evts {
+			received = append(received, e.ID)
+		}
+	}
+
+	addEvents(store, 0, 3)
+	evts, err := poller.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("first poll failed: %v", err)
+	}
+	collect(evts)
+
+	// Events published while the network is down must be delivered once it recovers
+	addEvents(store, 3, 6)
+	evts, err = poller.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("poll did not recover from transient failure: %v", err)
+	}
+	collect(evts)
+
+	if got := atomic.LoadInt32(polls); got != 4 {
+		t.Fatalf("expected 4 HTTP polls (2 failed), got %d", got)
+	}
+	if len(received) != 6 {
+		t.Fatalf("expected 6 events without loss or duplication, got %v", received)
+	}
+	for i, id := range received {
+		if id != fmt.Sprintf("evt-%d", i) {
+			t.Fatalf("unexpected event order: %v", received)
+		}
+	}
+	if poller.Cursor() != 5 {
+		t.Fatalf("expected cursor 5, got %d", poller.Cursor())
+	}
+}
+
+func TestPollerGivesUpAfterMaxRetries(t *testing.T) {
+	store := events.NewEventStore(100)
+	defer store.Stop()
+	store.InitializeObserver("obs")
+
+	server, _ := flakyEventServer(t, store, map[int32]bool{1: true, 2: true})
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryConfig(RetryConfig{MaxRetries: 1, InitialBackoff: time.Millisecond}))
+	poller := client.NewPoller("obs")
+
+	if _, err := poller.Poll(context.Background()); err == nil {
+		t.Fatalf("expected poll to fail after exhausting retries")
+	}
+	if poller.Cursor() != -1 {
+		t.Fatalf("cursor must not advance on failure, got %d", poller.Cursor())
+	}
+}
+
+func TestEventStoreGraceRetention(t *testing.T) {
+	store := events.NewEventStore(3)
+	defer store.Stop()
+	store.InitializeObserver("obs")
+
+	// Recently-active observer keeps up to maxEvents*DefaultGraceMultiplier events
+	addEvents(store, 0, 6)
+	if total, _ := store.GetObserverStatus("obs"); total != 6 {
+		t.Fatalf("expected grace retention to keep 6 events, got %d", total)
+	}
+
+	// Without grace the buffer is trimmed to maxEvents
+	store.SetRetentionGrace(0, 1)
+	addEvents(store, 6, 7)
+	if total, _ := store.GetObserverStatus("obs"); total != 3 {
+		t.Fatalf("expected buffer trimmed to 3 events, got %d", total)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"mcp-agent/agent_go/pkg/external"
+)
+
+func main() {
+	// Load environment variables from the agent_go .env file
+	if err := godotenv.Load("../../agent_go/.env"); err != nil {
+		fmt.Println("No .env file found, using system environment variables")
+	}
+
+	prompt := "Explain in three short paragraphs how TCP congestion control works."
+	if len(os.Args) > 1 {
+		prompt = os.Args[1]
+	}
+
+	fmt.Println("🚀 Starting External Agent Streaming Example")
+	fmt.Println("=============================================")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Batch tokens into chunks of at least 32 bytes to limit per-chunk overhead
+	agent, err := external.NewAgentBuilder().
+		WithAgentMode(external.SimpleAgent).
+		WithLLM("anthropic", "claude-sonnet-4-20250514", 0.3).
+		WithMaxTurns(5).
+		WithObservability("console", "").
+		WithStreamChunkSize(32).
+		Build(ctx)
+	if err != nil {
+		log.Fatalf("❌ Failed to create external agent: %v", err)
+	}
+	defer agent.Close()
+
+	stream, err := agent.InvokeStream(ctx, prompt)
+	if err != nil {
+		log.Fatalf("❌ Failed to start streaming: %v", err)
+	}
+
+	chunks := 0
+	for chunk := range stream {
+		// The last chunk of a failed run carries the error instead of response text
+		if streamErr := external.StreamError(chunk); streamErr != nil {
+			fmt.Printf("\n❌ Run failed: %v\n", streamErr)
+			os.Exit(1)
+		}
+		chunks++
+		fmt.Print(chunk)
+	}
+
+	fmt.Printf("\n\n✅ Streaming completed in %d chunks\n", chunks)
+}
@@ -26,6 +26,7 @@ export interface UnifiedEventsCompleteSchema {
   user_message?: UserMessageEvent;
   large_tool_output_detected?: LargeToolOutputDetectedEvent;
   large_tool_output_file_written?: LargeToolOutputFileWrittenEvent;
+  large_tool_output_handled?: LargeToolOutputHandledEvent;
   fallback_model_used?: FallbackModelUsedEvent;
   throttling_detected?: ThrottlingDetectedEvent;
   token_limit_exceeded?: TokenLimitExceededEvent;
@@ -46,6 +47,18 @@ export interface UnifiedEventsCompleteSchema {
   llm_generation_with_retry?: LLMGenerationWithRetryEvent;
   smart_routing_start?: SmartRoutingStartEvent;
   smart_routing_end?: SmartRoutingEndEvent;
+  agent_mode_selected?: AgentModeSelectedEvent;
+  memory_pressure?: MemoryPressureEvent;
+  tool_transaction_begin?: ToolTransactionEvent;
+  tool_transaction_commit?: ToolTransactionEvent;
+  tool_transaction_rollback?: ToolTransactionEvent;
+  tool_alternate_used?: ToolAlternateUsedEvent;
+  tool_permission_denied?: ToolPermissionDeniedEvent;
+  tool_call_deduplicated?: ToolCallDeduplicatedEvent;
+  structured_output_attempt?: StructuredOutputAttemptEvent;
+  credential_refresh?: CredentialRefreshEvent;
+  history_compacted?: HistoryCompactedEvent;
+  conversation_cost_summary?: ConversationCostSummaryEvent;
   orchestrator_start?: OrchestratorStartEvent;
   orchestrator_end?: OrchestratorEndEvent;
   orchestrator_error?: OrchestratorErrorEvent;
@@ -115,6 +128,8 @@ export interface ToolCallErrorEvent {
   error?: string;
   server_name?: string;
   duration?: number;
+  reason?: string;
+  timeout?: number;
 }
 export interface LLMGenerationStartEvent {
   timestamp?: string;
@@ -155,6 +170,7 @@ export interface LLMGenerationEndEvent {
   tool_calls?: number;
   duration?: number;
   usage_metrics?: UsageMetrics;
+  turn_summary?: boolean;
 }
 export interface UsageMetrics {
   prompt_tokens?: number;
@@ -453,6 +469,7 @@ export interface LargeToolOutputDetectedEvent {
   threshold?: number;
   output_folder?: string;
   server_available?: boolean;
+  policy?: string;
 }
 export interface LargeToolOutputFileWrittenEvent {
   timestamp?: string;
@@ -475,6 +492,28 @@ export interface LargeToolOutputFileWrittenEvent {
   output_folder?: string;
   preview?: string;
 }
+export interface LargeToolOutputHandledEvent {
+  timestamp?: string;
+  trace_id?: string;
+  span_id?: string;
+  event_id?: string;
+  parent_id?: string;
+  is_end_event?: boolean;
+  correlation_id?: string;
+  hierarchy_level?: number;
+  session_id?: string;
+  component?: string;
+  metadata?: {
+    [k: string]: unknown;
+  };
+  tool_name?: string;
+  policy?: string;
+  output_size?: number;
+  result_size?: number;
+  file_path?: string;
+  summary?: string;
+  error?: string;
+}
 export interface FallbackModelUsedEvent {
   timestamp?: string;
   trace_id?: string;
@@ -516,6 +555,8 @@ export interface ThrottlingDetectedEvent {
   attempt?: number;
   max_attempts?: number;
   duration?: string;
+  error_type?: string;
+  retry_delay?: string;
 }
 export interface TokenLimitExceededEvent {
   timestamp?: string;
@@ -920,6 +961,7 @@ export interface SmartRoutingStartEvent {
   llm_provider?: string;
   llm_temperature?: number;
   llm_max_tokens?: number;
+  cache_hit?: boolean;
 }
 export interface SmartRoutingEndEvent {
   timestamp?: string;
@@ -952,6 +994,229 @@ export interface SmartRoutingEndEvent {
   llm_provider?: string;
   llm_temperature?: number;
   llm_max_tokens?: number;
+  cache_hit?: boolean;
+  confidence?: number;
+}
+export interface AgentModeSelectedEvent {
+  timestamp?: string;
+  trace_id?: string;
+  span_id?: string;
+  event_id?: string;
+  parent_id?: string;
+  is_end_event?: boolean;
+  correlation_id?: string;
+  hierarchy_level?: number;
+  session_id?: string;
+  component?: string;
+  metadata?: {
+    [k: string]: unknown;
+  };
+  requested_mode?: string;
+  selected_mode?: string;
+  query_class?: string;
+  reasoning?: string;
+  fallback?: boolean;
+  error?: string;
+  duration?: number;
+}
+export interface MemoryPressureEvent {
+  timestamp?: string;
+  trace_id?: string;
+  span_id?: string;
+  event_id?: string;
+  parent_id?: string;
+  is_end_event?: boolean;
+  correlation_id?: string;
+  hierarchy_level?: number;
+  session_id?: string;
+  component?: string;
+  metadata?: {
+    [k: string]: unknown;
+  };
+  under_pressure?: boolean;
+  usage_bytes?: number;
+  limit_bytes?: number;
+  evicted_sessions?: number;
+}
+export interface ToolTransactionEvent {
+  timestamp?: string;
+  trace_id?: string;
+  span_id?: string;
+  event_id?: string;
+  parent_id?: string;
+  is_end_event?: boolean;
+  correlation_id?: string;
+  hierarchy_level?: number;
+  session_id?: string;
+  component?: string;
+  metadata?: {
+    [k: string]: unknown;
+  };
+  transaction_id?: string;
+  name?: string;
+  status?: string;
+  steps?: string[];
+  reason?: string;
+  compensated?: string[];
+  compensation_errors?: string[];
+}
+export interface ToolAlternateUsedEvent {
+  timestamp?: string;
+  trace_id?: string;
+  span_id?: string;
+  event_id?: string;
+  parent_id?: string;
+  is_end_event?: boolean;
+  correlation_id?: string;
+  hierarchy_level?: number;
+  session_id?: string;
+  component?: string;
+  metadata?: {
+    [k: string]: unknown;
+  };
+  turn?: number;
+  tool_name?: string;
+  alternate_tool?: string;
+  failures?: number;
+  error?: string;
+  succeeded?: boolean;
+  alternate_error?: string;
+}
+export interface ToolPermissionDeniedEvent {
+  timestamp?: string;
+  trace_id?: string;
+  span_id?: string;
+  event_id?: string;
+  parent_id?: string;
+  is_end_event?: boolean;
+  correlation_id?: string;
+  hierarchy_level?: number;
+  session_id?: string;
+  component?: string;
+  metadata?: {
+    [k: string]: unknown;
+  };
+  turn?: number;
+  tool_name?: string;
+  error?: string;
+  fallback_tool?: string;
+  succeeded?: boolean;
+  fallback_error?: string;
+}
+export interface ToolCallDeduplicatedEvent {
+  timestamp?: string;
+  trace_id?: string;
+  span_id?: string;
+  event_id?: string;
+  parent_id?: string;
+  is_end_event?: boolean;
+  correlation_id?: string;
+  hierarchy_level?: number;
+  session_id?: string;
+  component?: string;
+  metadata?: {
+    [k: string]: unknown;
+  };
+  turn?: number;
+  tool_name?: string;
+  server_name?: string;
+  tool_call_id?: string;
+  original_tool_call_id?: string;
+}
+export interface StructuredOutputAttemptEvent {
+  timestamp?: string;
+  trace_id?: string;
+  span_id?: string;
+  event_id?: string;
+  parent_id?: string;
+  is_end_event?: boolean;
+  correlation_id?: string;
+  hierarchy_level?: number;
+  session_id?: string;
+  component?: string;
+  metadata?: {
+    [k: string]: unknown;
+  };
+  attempt?: number;
+  max_attempts?: number;
+  valid?: boolean;
+  validation_errors?: string[];
+  output?: string;
+  will_retry?: boolean;
+}
+export interface CredentialRefreshEvent {
+  timestamp?: string;
+  trace_id?: string;
+  span_id?: string;
+  event_id?: string;
+  parent_id?: string;
+  is_end_event?: boolean;
+  correlation_id?: string;
+  hierarchy_level?: number;
+  session_id?: string;
+  component?: string;
+  metadata?: {
+    [k: string]: unknown;
+  };
+  turn?: number;
+  model_id?: string;
+  provider?: string;
+  error?: string;
+  refreshed?: boolean;
+  refresh_error?: string;
+}
+export interface HistoryCompactedEvent {
+  timestamp?: string;
+  trace_id?: string;
+  span_id?: string;
+  event_id?: string;
+  parent_id?: string;
+  is_end_event?: boolean;
+  correlation_id?: string;
+  hierarchy_level?: number;
+  session_id?: string;
+  component?: string;
+  metadata?: {
+    [k: string]: unknown;
+  };
+  turn?: number;
+  model_id?: string;
+  context_window?: number;
+  token_limit?: number;
+  tokens_before?: number;
+  tokens_after?: number;
+  messages_dropped?: number;
+  messages_kept?: number;
+}
+export interface ConversationCostSummaryEvent {
+  timestamp?: string;
+  trace_id?: string;
+  span_id?: string;
+  event_id?: string;
+  parent_id?: string;
+  is_end_event?: boolean;
+  correlation_id?: string;
+  hierarchy_level?: number;
+  session_id?: string;
+  component?: string;
+  metadata?: {
+    [k: string]: unknown;
+  };
+  scope?: string;
+  models?: ModelCostSummary[];
+  prompt_tokens?: number;
+  completion_tokens?: number;
+  total_tokens?: number;
+  fallback_switches?: number;
+  estimated_cost_usd?: number;
+}
+export interface ModelCostSummary {
+  model_id?: string;
+  generations?: number;
+  prompt_tokens?: number;
+  completion_tokens?: number;
+  total_tokens?: number;
+  estimated_cost_usd?: number;
 }
 export interface OrchestratorStartEvent {
   timestamp?: string;
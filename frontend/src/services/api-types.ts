@@ -153,6 +153,7 @@ export type PollingEvent = PollingEventSchema & {
 export interface GetEventsResponse {
   events: PollingEvent[]
   last_event_index: number
+  next_cursor?: number // Pass as since on the next poll; -1 while there are no events
   has_more: boolean
   observer_id: string
 }